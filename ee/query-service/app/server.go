@@ -38,6 +38,7 @@ import (
 	baseexplorer "go.signoz.io/signoz/pkg/query-service/app/explorer"
 	"go.signoz.io/signoz/pkg/query-service/app/integrations"
 	"go.signoz.io/signoz/pkg/query-service/app/logparsingpipeline"
+	basemacros "go.signoz.io/signoz/pkg/query-service/app/macros"
 	"go.signoz.io/signoz/pkg/query-service/app/opamp"
 	opAmpModel "go.signoz.io/signoz/pkg/query-service/app/opamp/model"
 	"go.signoz.io/signoz/pkg/query-service/app/preferences"
@@ -95,6 +96,11 @@ type Server struct {
 
 	opampServer *opamp.Server
 
+	reportScheduler *baseapp.DashboardReportScheduler
+	provisioner     *dashboards.DashboardProvisioner
+	purger          *dashboards.DashboardPurger
+	thumbnails      *dashboards.ThumbnailRenderer
+
 	unavailableChannel chan healthcheck.Status
 }
 
@@ -113,6 +119,7 @@ func NewServer(serverOptions *ServerOptions) (*Server, error) {
 	if err := baseexplorer.InitWithDSN(serverOptions.SigNoz.SQLStore.SQLxDB()); err != nil {
 		return nil, err
 	}
+	basemacros.InitDB(serverOptions.SigNoz.SQLStore.SQLxDB())
 
 	if err := preferences.InitDB(serverOptions.SigNoz.SQLStore.SQLxDB()); err != nil {
 		return nil, err
@@ -279,6 +286,10 @@ func NewServer(serverOptions *ServerOptions) (*Server, error) {
 		serverOptions:      serverOptions,
 		unavailableChannel: make(chan healthcheck.Status),
 		usageManager:       usageManager,
+		reportScheduler:    baseapp.NewDashboardReportScheduler(&apiHandler.APIHandler),
+		provisioner:        dashboards.NewDashboardProvisioner(baseconst.GetOrDefaultEnv("DASHBOARDS_PATH", "./config/dashboards"), baseconst.GetDashboardsProvisioningInterval(), lm),
+		purger:             dashboards.NewDashboardPurger(baseconst.GetDashboardsPurgeInterval()),
+		thumbnails:         dashboards.NewThumbnailRenderer(baseconst.GetDashboardsThumbnailInterval()),
 	}
 
 	httpServer, err := s.createPublicServer(apiHandler, serverOptions.SigNoz.Web)
@@ -364,6 +375,25 @@ func (s *Server) createPublicServer(apiHandler *api.APIHandler, web web.Web) (*h
 	).Wrap)
 	r.Use(middleware.NewAnalytics(zap.L()).Wrap)
 	r.Use(middleware.NewLogging(zap.L(), s.serverOptions.Config.APIServer.Logging.ExcludedRoutes).Wrap)
+	r.Use(middleware.NewAdmission(zap.L(),
+		s.serverOptions.Config.APIServer.Admission.Routes,
+		// the queries of the personal access tokens are limited as the ones of
+		// their users
+		func(ctx context.Context) (string, string, bool) {
+			if _, ok := authtypes.UUIDFromContext(ctx); !ok {
+				return "", "", false
+			}
+			user, err := getUserFromRequest(ctx)
+			if err != nil {
+				return "", "", false
+			}
+			return user.User.Email, user.User.OrgId, true
+		},
+		s.serverOptions.Config.APIServer.Admission.MaxConcurrentPerUser,
+		s.serverOptions.Config.APIServer.Admission.MaxConcurrentPerOrg,
+		s.serverOptions.Config.APIServer.Admission.MaxQueued,
+		s.serverOptions.Config.APIServer.Admission.QueueTimeout,
+	).Wrap)
 
 	apiHandler.RegisterRoutes(r, am)
 	apiHandler.RegisterLogsRoutes(r, am)
@@ -439,6 +469,11 @@ func (s *Server) Start() error {
 		zap.L().Info("msg: Rules disabled as rules.disable is set to TRUE")
 	}
 
+	s.reportScheduler.Start()
+	s.provisioner.Start()
+	s.purger.Start()
+	s.thumbnails.Start()
+
 	err := s.initListeners()
 	if err != nil {
 		return err
@@ -521,6 +556,22 @@ func (s *Server) Stop() error {
 		s.ruleManager.Stop()
 	}
 
+	if s.reportScheduler != nil {
+		s.reportScheduler.Stop()
+	}
+
+	if s.provisioner != nil {
+		s.provisioner.Stop()
+	}
+
+	if s.purger != nil {
+		s.purger.Stop()
+	}
+
+	if s.thumbnails != nil {
+		s.thumbnails.Stop()
+	}
+
 	// stop usage manager
 	s.usageManager.Stop()
 
@@ -565,6 +616,7 @@ func makeRulesManager(
 		Reader:       ch,
 		Cache:        cache,
 		EvalDelay:    baseconst.GetEvalDelay(),
+		EvalJitter:   baseconst.GetEvalJitter(),
 
 		PrepareTaskFunc:     rules.PrepareTaskFunc,
 		UseLogsNewSchema:    useLogsNewSchema,
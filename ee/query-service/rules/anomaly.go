@@ -61,6 +61,13 @@ func NewAnomalyRule(
 
 	zap.L().Info("creating new AnomalyRule", zap.String("id", id), zap.Any("opts", opts))
 
+	// the sensitivity presets the z-score for the rules without a threshold
+	if p.RuleCondition.Target == nil {
+		if target, ok := p.RuleCondition.Sensitivity.ZScore(); ok {
+			p.RuleCondition.Target = &target
+		}
+	}
+
 	if p.RuleCondition.CompareOp == baserules.ValueIsBelow {
 		target := -1 * *p.RuleCondition.Target
 		p.RuleCondition.Target = &target
@@ -293,6 +300,8 @@ func (r *AnomalyRule) Eval(ctx context.Context, ts time.Time) (interface{}, erro
 			GeneratorURL:      r.GeneratorURL(),
 			Receivers:         r.PreferredChannels(),
 			Missing:           smpl.IsMissing,
+
+			ResolvedAnnotations: r.ResolvedNotification().ExpandAnnotations(expand),
 		}
 	}
 
@@ -306,6 +315,7 @@ func (r *AnomalyRule) Eval(ctx context.Context, ts time.Time) (interface{}, erro
 
 			alert.Value = a.Value
 			alert.Annotations = a.Annotations
+			alert.ResolvedAnnotations = a.ResolvedAnnotations
 			alert.Receivers = r.PreferredChannels()
 			continue
 		}
@@ -361,6 +371,18 @@ func (r *AnomalyRule) Eval(ctx context.Context, ts time.Time) (interface{}, erro
 				Fingerprint:  a.QueryResultLables.Hash(),
 				Value:        a.Value,
 			})
+		} else if a.State == model.StatePending && a.ActiveAt.Equal(ts) {
+			// the alert is held pending before it fires
+			itemsToAdd = append(itemsToAdd, model.RuleStateHistory{
+				RuleID:       r.ID(),
+				RuleName:     r.Name(),
+				State:        model.StatePending,
+				StateChanged: true,
+				UnixMilli:    ts.UnixMilli(),
+				Labels:       model.LabelsString(labelsJSON),
+				Fingerprint:  a.QueryResultLables.Hash(),
+				Value:        a.Value,
+			})
 		}
 	}
 
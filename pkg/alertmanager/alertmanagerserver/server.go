@@ -229,7 +229,7 @@ func (server *Server) SetConfig(ctx context.Context, alertmanagerConfig *alertma
 	// Build the map of receiver to integrations.
 	receivers := make(map[string][]notify.Integration, len(activeReceivers))
 	var integrationsNum int
-	for _, rcv := range config.Receivers {
+	for _, rcv := range alertmanagerConfig.Receivers() {
 		if _, found := activeReceivers[rcv.Name]; !found {
 			// No need to build a receiver if no route is using it.
 			server.logger.InfoContext(ctx, "skipping creation of receiver not referenced by any route", "receiver", rcv.Name)
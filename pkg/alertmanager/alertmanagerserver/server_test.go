@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -63,11 +64,13 @@ func TestServerTestReceiverTypeWebhook(t *testing.T) {
 	require.NoError(t, err)
 
 	err = server.TestReceiver(context.Background(), alertmanagertypes.Receiver{
-		Name: "test-receiver",
-		WebhookConfigs: []*config.WebhookConfig{
+		Receiver: config.Receiver{Name: "test-receiver"},
+		WebhookConfigs: []*alertmanagertypes.WebhookConfig{
 			{
-				HTTPConfig: &commoncfg.HTTPClientConfig{},
-				URL:        &config.SecretURL{URL: webhookURL},
+				WebhookConfig: config.WebhookConfig{
+					HTTPConfig: &commoncfg.HTTPClientConfig{},
+					URL:        &config.SecretURL{URL: webhookURL},
+				},
 			},
 		},
 	})
@@ -77,6 +80,105 @@ func TestServerTestReceiverTypeWebhook(t *testing.T) {
 	assert.Contains(t, requestBody.String(), "firing")
 }
 
+func TestServerTestReceiverTypeSignedWebhook(t *testing.T) {
+	server, err := New(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)), prometheus.NewRegistry(), NewConfig(), "1", alertmanagertypestest.NewStateStore())
+	require.NoError(t, err)
+
+	amConfig, err := alertmanagertypes.NewDefaultConfig(alertmanagertypes.GlobalConfig{}, alertmanagertypes.RouteConfig{}, "1")
+	require.NoError(t, err)
+
+	webhookListener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	requestBody := new(bytes.Buffer)
+	var requestHeader http.Header
+	webhookServer := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := requestBody.ReadFrom(r.Body)
+			require.NoError(t, err)
+			requestHeader = r.Header.Clone()
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	go func() {
+		require.NoError(t, webhookServer.Serve(webhookListener))
+	}()
+
+	require.NoError(t, server.SetConfig(context.Background(), amConfig))
+	defer require.NoError(t, server.Stop(context.Background()))
+
+	webhookURL, err := url.Parse("http://" + webhookListener.Addr().String() + "/webhook")
+	require.NoError(t, err)
+
+	err = server.TestReceiver(context.Background(), alertmanagertypes.Receiver{
+		Receiver: config.Receiver{Name: "test-receiver"},
+		WebhookConfigs: []*alertmanagertypes.WebhookConfig{
+			{
+				WebhookConfig: config.WebhookConfig{
+					HTTPConfig: &commoncfg.HTTPClientConfig{},
+					URL:        &config.SecretURL{URL: webhookURL},
+				},
+				SigningSecret: "s3cret",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	timestamp, err := strconv.ParseInt(requestHeader.Get(alertmanagertypes.WebhookTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, alertmanagertypes.SignWebhook([]byte("s3cret"), timestamp, requestBody.Bytes()), requestHeader.Get(alertmanagertypes.WebhookSignatureHeader))
+	assert.Contains(t, requestBody.String(), "test-receiver")
+}
+
+func TestServerTestReceiverTypeTemplatedWebhook(t *testing.T) {
+	server, err := New(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)), prometheus.NewRegistry(), NewConfig(), "1", alertmanagertypestest.NewStateStore())
+	require.NoError(t, err)
+
+	amConfig, err := alertmanagertypes.NewDefaultConfig(alertmanagertypes.GlobalConfig{}, alertmanagertypes.RouteConfig{}, "1")
+	require.NoError(t, err)
+
+	webhookListener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	requestBody := new(bytes.Buffer)
+	var requestHeader http.Header
+	webhookServer := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := requestBody.ReadFrom(r.Body)
+			require.NoError(t, err)
+			requestHeader = r.Header.Clone()
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	go func() {
+		require.NoError(t, webhookServer.Serve(webhookListener))
+	}()
+
+	require.NoError(t, server.SetConfig(context.Background(), amConfig))
+	defer require.NoError(t, server.Stop(context.Background()))
+
+	webhookURL, err := url.Parse("http://" + webhookListener.Addr().String() + "/webhook")
+	require.NoError(t, err)
+
+	err = server.TestReceiver(context.Background(), alertmanagertypes.Receiver{
+		Receiver: config.Receiver{Name: "test-receiver"},
+		WebhookConfigs: []*alertmanagertypes.WebhookConfig{
+			{
+				WebhookConfig: config.WebhookConfig{
+					URL: &config.SecretURL{URL: webhookURL},
+				},
+				PayloadTemplate: `{"status": "{{ .Status }}", "receiver": "{{ .Receiver }}"}`,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"status": "firing", "receiver": "test-receiver"}`, requestBody.String())
+	assert.Empty(t, requestHeader.Get(alertmanagertypes.WebhookSignatureHeader))
+}
+
 func TestServerPutAlerts(t *testing.T) {
 	stateStore := alertmanagertypestest.NewStateStore()
 	srvCfg := NewConfig()
@@ -88,11 +190,13 @@ func TestServerPutAlerts(t *testing.T) {
 	require.NoError(t, err)
 
 	require.NoError(t, amConfig.CreateReceiver(&config.Route{Receiver: "test-receiver", Continue: true}, alertmanagertypes.Receiver{
-		Name: "test-receiver",
-		WebhookConfigs: []*config.WebhookConfig{
+		Receiver: config.Receiver{Name: "test-receiver"},
+		WebhookConfigs: []*alertmanagertypes.WebhookConfig{
 			{
-				HTTPConfig: &commoncfg.HTTPClientConfig{},
-				URL:        &config.SecretURL{URL: &url.URL{Host: "localhost", Path: "/test-receiver"}},
+				WebhookConfig: config.WebhookConfig{
+					HTTPConfig: &commoncfg.HTTPClientConfig{},
+					URL:        &config.SecretURL{URL: &url.URL{Host: "localhost", Path: "/test-receiver"}},
+				},
 			},
 		},
 	}))
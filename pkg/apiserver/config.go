@@ -1,6 +1,7 @@
 package apiserver
 
 import (
+	"fmt"
 	"time"
 
 	"go.signoz.io/signoz/pkg/factory"
@@ -8,8 +9,9 @@ import (
 
 // Config holds the configuration for config.
 type Config struct {
-	Timeout Timeout `mapstructure:"timeout"`
-	Logging Logging `mapstructure:"logging"`
+	Timeout   Timeout   `mapstructure:"timeout"`
+	Logging   Logging   `mapstructure:"logging"`
+	Admission Admission `mapstructure:"admission"`
 }
 
 type Timeout struct {
@@ -26,6 +28,20 @@ type Logging struct {
 	ExcludedRoutes []string `mapstructure:"excluded_routes"`
 }
 
+type Admission struct {
+	// The maximum number of concurrent queries of a user, 0 means no limit
+	MaxConcurrentPerUser int `mapstructure:"max_concurrent_per_user"`
+	// The maximum number of concurrent queries of an org, 0 means no limit
+	MaxConcurrentPerOrg int `mapstructure:"max_concurrent_per_org"`
+	// The maximum number of queries of a user waiting to run
+	MaxQueued int `mapstructure:"max_queued"`
+	// The maximum time a query waits to run
+	QueueTimeout time.Duration `mapstructure:"queue_timeout"`
+	// The list of routes running queries that are limited, as the templates
+	// of the routes with variables
+	Routes []string `mapstructure:"routes"`
+}
+
 func NewConfigFactory() factory.ConfigFactory {
 	return factory.NewConfigFactory(factory.MustNewName("apiserver"), newConfig)
 }
@@ -45,9 +61,26 @@ func newConfig() factory.Config {
 				"/api/v1/health",
 			},
 		},
+		Admission: Admission{
+			MaxConcurrentPerUser: 16,
+			MaxConcurrentPerOrg:  64,
+			MaxQueued:            128,
+			QueueTimeout:         30 * time.Second,
+			Routes: []string{
+				"/api/v1/query",
+				"/api/v1/query_range",
+				"/api/v3/query_range",
+				"/api/v4/query_range",
+				"/loki/api/v1/query_range",
+				"/api/v1/public/dashboards/{token}/widgets/{widgetId}",
+			},
+		},
 	}
 }
 
 func (c Config) Validate() error {
+	if c.Admission.MaxConcurrentPerUser < 0 || c.Admission.MaxConcurrentPerOrg < 0 || c.Admission.MaxQueued < 0 {
+		return fmt.Errorf("the admission limits can't be negative")
+	}
 	return nil
 }
@@ -17,6 +17,11 @@ func TestNewWithEnvProvider(t *testing.T) {
 	t.Setenv("SIGNOZ_APISERVER_TIMEOUT_MAX", "700s")
 	t.Setenv("SIGNOZ_APISERVER_TIMEOUT_EXCLUDED__ROUTES", "/excluded1,/excluded2")
 	t.Setenv("SIGNOZ_APISERVER_LOGGING_EXCLUDED__ROUTES", "/api/v1/health1")
+	t.Setenv("SIGNOZ_APISERVER_ADMISSION_MAX__CONCURRENT__PER__USER", "4")
+	t.Setenv("SIGNOZ_APISERVER_ADMISSION_MAX__CONCURRENT__PER__ORG", "20")
+	t.Setenv("SIGNOZ_APISERVER_ADMISSION_MAX__QUEUED", "8")
+	t.Setenv("SIGNOZ_APISERVER_ADMISSION_QUEUE__TIMEOUT", "10s")
+	t.Setenv("SIGNOZ_APISERVER_ADMISSION_ROUTES", "/api/v4/query_range")
 
 	conf, err := config.New(
 		context.Background(),
@@ -51,6 +56,15 @@ func TestNewWithEnvProvider(t *testing.T) {
 				"/api/v1/health1",
 			},
 		},
+		Admission: Admission{
+			MaxConcurrentPerUser: 4,
+			MaxConcurrentPerOrg:  20,
+			MaxQueued:            8,
+			QueueTimeout:         10 * time.Second,
+			Routes: []string{
+				"/api/v4/query_range",
+			},
+		},
 	}
 
 	assert.Equal(t, expected, actual)
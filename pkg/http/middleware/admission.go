@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+const (
+	// The header with the time the admitted requests waited in the queue
+	queueWaitHeaderName string = "X-SigNoz-Query-Queue-Wait"
+)
+
+// Admission limits the number of concurrent requests running queries of each
+// user and each org. The requests over the limits wait in a bounded queue of
+// their user, in order, and are rejected with 429 Too Many Requests when the
+// queue is full or they waited too long, so that the queries of one user
+// can't starve the queries of the others.
+type Admission struct {
+	logger *zap.Logger
+	routes map[string]struct{}
+	// Resolves the user of the requests authenticated without claims
+	identify Identify
+	// The max concurrent requests of a user, 0 means no limit
+	maxPerUser int
+	// The max concurrent requests of an org, 0 means no limit
+	maxPerOrg int
+	// The max requests of a user waiting in the queue
+	maxQueued int
+	// The max time a request waits in the queue
+	queueTimeout time.Duration
+
+	mu      sync.Mutex
+	running map[string]int
+	queues  map[string][]*admissionWaiter
+	// The users with queued requests, in the order they started waiting, the
+	// queues are dispatched in this order
+	order []string
+}
+
+// Identify returns the email and the org id of the user of the request
+// authenticated without claims, like the ones with a personal access token
+type Identify func(ctx context.Context) (string, string, bool)
+
+type admissionWaiter struct {
+	user     string
+	org      string
+	admitted chan struct{}
+}
+
+// admissionStatus is the data of the rejected requests
+type admissionStatus struct {
+	Running       int `json:"running"`
+	Queued        int `json:"queued"`
+	QueuePosition int `json:"queuePosition,omitempty"`
+	MaxConcurrent int `json:"maxConcurrent"`
+	MaxQueued     int `json:"maxQueued"`
+}
+
+func NewAdmission(logger *zap.Logger, routes []string, identify Identify, maxPerUser int, maxPerOrg int, maxQueued int, queueTimeout time.Duration) *Admission {
+	if logger == nil {
+		panic("cannot build admission, logger is empty")
+	}
+
+	routesMap := make(map[string]struct{}, len(routes))
+	for _, route := range routes {
+		routesMap[route] = struct{}{}
+	}
+
+	if queueTimeout == 0 {
+		queueTimeout = 30 * time.Second
+	}
+
+	return &Admission{
+		logger:       logger.Named(pkgname),
+		routes:       routesMap,
+		identify:     identify,
+		maxPerUser:   maxPerUser,
+		maxPerOrg:    maxPerOrg,
+		maxQueued:    maxQueued,
+		queueTimeout: queueTimeout,
+		running:      map[string]int{},
+		queues:       map[string][]*admissionWaiter{},
+	}
+}
+
+func (middleware *Admission) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		path := req.URL.Path
+		if route := mux.CurrentRoute(req); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				path = template
+			}
+		}
+		if _, ok := middleware.routes[path]; !ok || (middleware.maxPerUser == 0 && middleware.maxPerOrg == 0) {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		user, org := middleware.identity(req)
+		start := time.Now()
+		if status, ok := middleware.admit(req, user, org); !ok {
+			if req.Context().Err() == nil {
+				middleware.logger.Info("rejected the request over the query limits", zap.String("user", user), zap.String("org", org), zap.String("path", path), zap.Any("status", status))
+				middleware.reject(rw, status)
+			}
+			return
+		}
+		defer middleware.release(user, org)
+
+		rw.Header().Set(queueWaitHeaderName, time.Since(start).String())
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// identity returns the keys of the user and the org the request is limited
+// by. The requests of the shared dashboards are limited by their token, and
+// all together as the ones of one org. The unauthenticated requests are
+// limited all together as the ones of one user, they don't bypass the limits
+// even though the handlers reject them.
+func (middleware *Admission) identity(req *http.Request) (string, string) {
+	if claims, ok := authtypes.ClaimsFromContext(req.Context()); ok {
+		return "user:" + claims.Email, "org:" + claims.OrgID
+	}
+	if middleware.identify != nil {
+		if email, orgID, ok := middleware.identify(req.Context()); ok {
+			return "user:" + email, "org:" + orgID
+		}
+	}
+	if token := mux.Vars(req)["token"]; token != "" {
+		return "share:" + token, "shares"
+	}
+	return "anonymous", "org:anonymous"
+}
+
+// admit waits until the request of the user can run, it returns the status
+// of the queue of the user when it can't
+func (middleware *Admission) admit(req *http.Request, user string, org string) (admissionStatus, bool) {
+	middleware.mu.Lock()
+	if len(middleware.queues[user]) == 0 && middleware.canRun(user, org) {
+		middleware.running[user]++
+		middleware.running[org]++
+		middleware.mu.Unlock()
+		return admissionStatus{}, true
+	}
+	if len(middleware.queues[user]) >= middleware.maxQueued {
+		status := middleware.status(user, 0)
+		middleware.mu.Unlock()
+		return status, false
+	}
+	waiter := &admissionWaiter{user: user, org: org, admitted: make(chan struct{})}
+	if len(middleware.queues[user]) == 0 {
+		middleware.order = append(middleware.order, user)
+	}
+	middleware.queues[user] = append(middleware.queues[user], waiter)
+	middleware.mu.Unlock()
+
+	timer := time.NewTimer(middleware.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-waiter.admitted:
+		return admissionStatus{}, true
+	case <-timer.C:
+	case <-req.Context().Done():
+	}
+
+	middleware.mu.Lock()
+	defer middleware.mu.Unlock()
+	for i, queued := range middleware.queues[user] {
+		if queued == waiter {
+			middleware.queues[user] = append(middleware.queues[user][:i], middleware.queues[user][i+1:]...)
+			status := middleware.status(user, i+1)
+			middleware.dispatch()
+			return status, false
+		}
+	}
+	// the request was admitted while timing out, it is released by the caller
+	return admissionStatus{}, true
+}
+
+func (middleware *Admission) release(user string, org string) {
+	middleware.mu.Lock()
+	defer middleware.mu.Unlock()
+	middleware.running[user]--
+	middleware.running[org]--
+	if middleware.running[user] == 0 {
+		delete(middleware.running, user)
+	}
+	if middleware.running[org] == 0 {
+		delete(middleware.running, org)
+	}
+	middleware.dispatch()
+}
+
+// dispatch admits the first requests of the queues that can run, the users
+// who have waited the longest first. It must be called with the lock held.
+func (middleware *Admission) dispatch() {
+	order := middleware.order[:0]
+	for _, user := range middleware.order {
+		queue := middleware.queues[user]
+		for len(queue) > 0 && middleware.canRun(user, queue[0].org) {
+			waiter := queue[0]
+			queue = queue[1:]
+			middleware.running[waiter.user]++
+			middleware.running[waiter.org]++
+			close(waiter.admitted)
+		}
+		if len(queue) == 0 {
+			delete(middleware.queues, user)
+			continue
+		}
+		middleware.queues[user] = queue
+		order = append(order, user)
+	}
+	middleware.order = order
+}
+
+func (middleware *Admission) canRun(user string, org string) bool {
+	return (middleware.maxPerUser == 0 || middleware.running[user] < middleware.maxPerUser) &&
+		(middleware.maxPerOrg == 0 || middleware.running[org] < middleware.maxPerOrg)
+}
+
+func (middleware *Admission) status(user string, position int) admissionStatus {
+	return admissionStatus{
+		Running:       middleware.running[user],
+		Queued:        len(middleware.queues[user]),
+		QueuePosition: position,
+		MaxConcurrent: middleware.maxPerUser,
+		MaxQueued:     middleware.maxQueued,
+	}
+}
+
+func (middleware *Admission) reject(rw http.ResponseWriter, status admissionStatus) {
+	message := "too many queries are queued, retry once the running queries complete"
+	if status.QueuePosition > 0 {
+		message = "the query waited too long in the queue, retry once the running queries complete"
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"status":    "error",
+		"errorType": "too_many_requests",
+		"error":     message,
+		"data":      status,
+	})
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Retry-After", strconv.Itoa(int(middleware.queueTimeout.Seconds())))
+	rw.WriteHeader(http.StatusTooManyRequests)
+	_, _ = rw.Write(body)
+}
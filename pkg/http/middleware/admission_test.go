@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+func TestAdmission(t *testing.T) {
+	t.Parallel()
+
+	m := NewAdmission(zap.NewNop(), []string{"/query"}, nil, 1, 2, 1, 200*time.Millisecond)
+
+	started := make(chan string, 10)
+	releases := map[string]chan struct{}{}
+	for _, id := range []string{"a1", "a2", "a3", "b1", "c1", "other"} {
+		releases[id] = make(chan struct{})
+	}
+	handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		started <- id
+		<-releases[id]
+		w.WriteHeader(204)
+	}))
+
+	do := func(path string, email string, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path+"?id="+id, nil)
+		req = req.WithContext(authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: email, OrgID: "org"}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	async := func(path string, email string, id string) chan *httptest.ResponseRecorder {
+		ch := make(chan *httptest.ResponseRecorder, 1)
+		go func() { ch <- do(path, email, id) }()
+		return ch
+	}
+	status := func(rec *httptest.ResponseRecorder) admissionStatus {
+		var body struct {
+			Data admissionStatus `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body.Data
+	}
+
+	a1 := async("/query", "a@signoz.io", "a1")
+	require.Equal(t, "a1", <-started)
+
+	// the second query of the user waits for the first one
+	a2 := async("/query", "a@signoz.io", "a2")
+	require.Eventually(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.queues["user:a@signoz.io"]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// the queue of the user is full
+	rec := do("/query", "a@signoz.io", "a3")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, admissionStatus{Running: 1, Queued: 1, MaxConcurrent: 1, MaxQueued: 1}, status(rec))
+
+	b1 := async("/query", "b@signoz.io", "b1")
+	require.Equal(t, "b1", <-started)
+
+	close(releases["a1"])
+	assert.Equal(t, 204, (<-a1).Code)
+	require.Equal(t, "a2", <-started)
+
+	// the org runs as many queries as it can, the query times out in the queue
+	rec = do("/query", "c@signoz.io", "c1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, admissionStatus{Running: 0, Queued: 0, QueuePosition: 1, MaxConcurrent: 1, MaxQueued: 1}, status(rec))
+
+	// the other routes are not limited
+	other := async("/other", "a@signoz.io", "other")
+	require.Equal(t, "other", <-started)
+
+	for _, id := range []string{"a2", "b1", "other"} {
+		close(releases[id])
+	}
+	rec = <-a2
+	assert.Equal(t, 204, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(queueWaitHeaderName))
+	assert.Equal(t, 204, (<-b1).Code)
+	assert.Equal(t, 204, (<-other).Code)
+}
+
+func TestAdmissionDispatchOrder(t *testing.T) {
+	t.Parallel()
+
+	m := NewAdmission(zap.NewNop(), []string{"/query"}, nil, 1, 1, 1, 5*time.Second)
+
+	started := make(chan string, 10)
+	releases := map[string]chan struct{}{}
+	for _, email := range []string{"a", "b", "c", "d"} {
+		releases[email] = make(chan struct{})
+	}
+	handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := authtypes.ClaimsFromContext(r.Context())
+		started <- claims.Email
+		<-releases[claims.Email]
+		w.WriteHeader(204)
+	}))
+
+	do := func(email string) chan int {
+		ch := make(chan int, 1)
+		go func() {
+			req := httptest.NewRequest("GET", "/query", nil)
+			req = req.WithContext(authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: email, OrgID: "org"}))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			ch <- rec.Code
+		}()
+		return ch
+	}
+
+	a := do("a")
+	require.Equal(t, "a", <-started)
+
+	// the users wait for the org in the order they came
+	codes := []chan int{a}
+	for i, email := range []string{"b", "c", "d"} {
+		codes = append(codes, do(email))
+		require.Eventually(t, func() bool {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			return len(m.order) == i+1
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	users := []string{"a", "b", "c", "d"}
+	for i, email := range users[:3] {
+		close(releases[email])
+		require.Equal(t, users[i+1], <-started)
+	}
+	close(releases["d"])
+
+	for _, code := range codes {
+		assert.Equal(t, 204, <-code)
+	}
+}
+
+func TestAdmissionSharedDashboards(t *testing.T) {
+	t.Parallel()
+
+	m := NewAdmission(zap.NewNop(), []string{"/public/{token}"}, nil, 1, 2, 0, 100*time.Millisecond)
+
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	router := mux.NewRouter()
+	router.Use(m.Wrap)
+	router.HandleFunc("/public/{token}", func(w http.ResponseWriter, r *http.Request) {
+		started <- mux.Vars(r)["token"] + r.URL.Query().Get("id")
+		<-release
+		w.WriteHeader(204)
+	})
+
+	do := func(path string) chan int {
+		ch := make(chan int, 1)
+		go func() {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+			ch <- rec.Code
+		}()
+		return ch
+	}
+
+	// the anonymous requests are limited by their token
+	a1 := do("/public/a?id=1")
+	require.Equal(t, "a1", <-started)
+	assert.Equal(t, http.StatusTooManyRequests, <-do("/public/a?id=2"))
+
+	// and all together by the limit of an org
+	b1 := do("/public/b?id=1")
+	require.Equal(t, "b1", <-started)
+	assert.Equal(t, http.StatusTooManyRequests, <-do("/public/c?id=1"))
+
+	close(release)
+	assert.Equal(t, 204, <-a1)
+	assert.Equal(t, 204, <-b1)
+}
+
+func TestAdmissionIdentify(t *testing.T) {
+	t.Parallel()
+
+	identify := func(ctx context.Context) (string, string, bool) {
+		token, ok := authtypes.UUIDFromContext(ctx)
+		if !ok || token != "pat" {
+			return "", "", false
+		}
+		return "a@signoz.io", "org", true
+	}
+	m := NewAdmission(zap.NewNop(), []string{"/query"}, identify, 1, 0, 0, 100*time.Millisecond)
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(204)
+	}))
+
+	do := func(ctx context.Context) chan int {
+		ch := make(chan int, 1)
+		go func() {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/query", nil).WithContext(ctx))
+			ch <- rec.Code
+		}()
+		return ch
+	}
+
+	jwt := do(authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "a@signoz.io", OrgID: "org"}))
+	<-started
+
+	// the queries of the personal access tokens are limited as the ones of their users
+	assert.Equal(t, http.StatusTooManyRequests, <-do(authtypes.NewContextWithUUID(context.Background(), "pat")))
+
+	// the unauthenticated queries are limited all together
+	anonymous := do(authtypes.NewContextWithUUID(context.Background(), "invalid"))
+	<-started
+	assert.Equal(t, http.StatusTooManyRequests, <-do(context.Background()))
+
+	close(release)
+	assert.Equal(t, 204, <-jwt)
+	assert.Equal(t, 204, <-anonymous)
+}
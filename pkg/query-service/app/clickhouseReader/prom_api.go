@@ -0,0 +1,155 @@
+package clickhouseReader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.uber.org/zap"
+)
+
+// promMetadataQuery returns the table and the conditions selecting the time
+// series of the params, with the args of the conditions
+func promMetadataQuery(params *model.PromMetadataParams) (string, string, []interface{}, error) {
+	start, end, tableName, _ := utils.WhichTSTableToUse(params.Start.UnixMilli(), params.End.UnixMilli())
+
+	args := []interface{}{start, end}
+	conditions := []string{"unix_milli >= $1", "unix_milli < $2"}
+
+	selectors := []string{}
+	for _, matchers := range params.Matchers {
+		selector := []string{}
+		for _, matcher := range matchers {
+			condition, err := promMatcherCondition(matcher, &args)
+			if err != nil {
+				return "", "", nil, err
+			}
+			selector = append(selector, condition)
+		}
+		if len(selector) > 0 {
+			selectors = append(selectors, "("+strings.Join(selector, " AND ")+")")
+		}
+	}
+	if len(selectors) > 0 {
+		conditions = append(conditions, "("+strings.Join(selectors, " OR ")+")")
+	}
+
+	return fmt.Sprintf("%s.%s", signozMetricDBName, tableName), strings.Join(conditions, " AND "), args, nil
+}
+
+// promMatcherCondition returns the condition on the time series for the
+// label matcher, the regular expressions are anchored as in Prometheus
+func promMatcherCondition(matcher *labels.Matcher, args *[]interface{}) (string, error) {
+	column := "metric_name"
+	if matcher.Name != labels.MetricName {
+		*args = append(*args, matcher.Name)
+		column = fmt.Sprintf("JSONExtractString(labels, $%d)", len(*args))
+	}
+
+	switch matcher.Type {
+	case labels.MatchEqual:
+		*args = append(*args, matcher.Value)
+		return fmt.Sprintf("%s = $%d", column, len(*args)), nil
+	case labels.MatchNotEqual:
+		*args = append(*args, matcher.Value)
+		return fmt.Sprintf("%s != $%d", column, len(*args)), nil
+	case labels.MatchRegexp:
+		*args = append(*args, "^(?:"+matcher.Value+")$")
+		return fmt.Sprintf("match(%s, $%d)", column, len(*args)), nil
+	case labels.MatchNotRegexp:
+		*args = append(*args, "^(?:"+matcher.Value+")$")
+		return fmt.Sprintf("NOT match(%s, $%d)", column, len(*args)), nil
+	default:
+		return "", fmt.Errorf("unsupported matcher type %s", matcher.Type)
+	}
+}
+
+func promLimit(limit uint64) string {
+	if limit == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" LIMIT %d", limit)
+}
+
+// GetPromSeries returns the label sets of the time series matching the
+// params
+func (r *ClickHouseReader) GetPromSeries(ctx context.Context, params *model.PromMetadataParams) ([]map[string]string, *model.ApiError) {
+	table, conditions, args, err := promMetadataQuery(params)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+	query := fmt.Sprintf("SELECT DISTINCT metric_name, labels FROM %s WHERE %s ORDER BY metric_name, labels%s", table, conditions, promLimit(params.Limit))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		zap.L().Error("error while getting series", zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	defer rows.Close()
+
+	series := []map[string]string{}
+	for rows.Next() {
+		var metricName, labelsJSON string
+		if err := rows.Scan(&metricName, &labelsJSON); err != nil {
+			return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+		}
+		labelSet := map[string]string{}
+		if err := json.Unmarshal([]byte(labelsJSON), &labelSet); err != nil {
+			return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+		}
+		labelSet[labels.MetricName] = metricName
+		series = append(series, labelSet)
+	}
+	return series, nil
+}
+
+// GetPromLabelNames returns the names of the labels of the time series
+// matching the params
+func (r *ClickHouseReader) GetPromLabelNames(ctx context.Context, params *model.PromMetadataParams) ([]string, *model.ApiError) {
+	table, conditions, args, err := promMetadataQuery(params)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+	query := fmt.Sprintf("SELECT DISTINCT arrayJoin(JSONExtractKeys(labels)) AS name FROM %s WHERE %s ORDER BY name%s", table, conditions, promLimit(params.Limit))
+
+	names := []string{}
+	if err := r.db.Select(ctx, &names, query, args...); err != nil {
+		zap.L().Error("error while getting label names", zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	// the metric name is a column, it may not be in the labels
+	if len(names) > 0 && !slices.Contains(names, labels.MetricName) {
+		names = append(names, labels.MetricName)
+		sort.Strings(names)
+	}
+	return names, nil
+}
+
+// GetPromLabelValues returns the values of the label of the time series
+// matching the params
+func (r *ClickHouseReader) GetPromLabelValues(ctx context.Context, name string, params *model.PromMetadataParams) ([]string, *model.ApiError) {
+	table, conditions, args, err := promMetadataQuery(params)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+	column := "metric_name"
+	if name != labels.MetricName {
+		args = append(args, name)
+		column = fmt.Sprintf("JSONExtractString(labels, $%d)", len(args))
+		conditions += fmt.Sprintf(" AND has(JSONExtractKeys(labels), $%d)", len(args))
+	}
+	query := fmt.Sprintf("SELECT DISTINCT %s AS value FROM %s WHERE %s ORDER BY value%s", column, table, conditions, promLimit(params.Limit))
+
+	values := []string{}
+	if err := r.db.Select(ctx, &values, query, args...); err != nil {
+		zap.L().Error("error while getting label values", zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return values, nil
+}
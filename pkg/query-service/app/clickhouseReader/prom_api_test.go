@@ -0,0 +1,45 @@
+package clickhouseReader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+func TestPromMetadataQuery(t *testing.T) {
+	end := time.UnixMilli(1700003600000)
+	params := &model.PromMetadataParams{
+		Matchers: [][]*labels.Matcher{
+			{
+				labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "http_requests_total"),
+				labels.MustNewMatcher(labels.MatchRegexp, "job", "api|web"),
+			},
+			{
+				labels.MustNewMatcher(labels.MatchNotEqual, "env", "dev"),
+				labels.MustNewMatcher(labels.MatchNotRegexp, labels.MetricName, "signoz_.*"),
+			},
+		},
+		Start: end.Add(-time.Hour),
+		End:   end,
+	}
+
+	table, conditions, args, err := promMetadataQuery(params)
+	require.NoError(t, err)
+	assert.Equal(t, "signoz_metrics.distributed_time_series_v4", table)
+	assert.Equal(t, "unix_milli >= $1 AND unix_milli < $2 AND ((metric_name = $3 AND match(JSONExtractString(labels, $4), $5)) OR "+
+		"(JSONExtractString(labels, $6) != $7 AND NOT match(metric_name, $8)))", conditions)
+	// the start is rounded to the hour of the time series table
+	assert.Equal(t, []interface{}{int64(1699999200000), int64(1700003600000), "http_requests_total", "job", "^(?:api|web)$", "env", "dev", "^(?:signoz_.*)$"}, args)
+
+	// the range of more than a day is read from the daily time series
+	params.Start = end.Add(-48 * time.Hour)
+	params.Matchers = nil
+	table, conditions, _, err = promMetadataQuery(params)
+	require.NoError(t, err)
+	assert.Equal(t, "signoz_metrics.distributed_time_series_v4_1day", table)
+	assert.Equal(t, "unix_milli >= $1 AND unix_milli < $2", conditions)
+}
@@ -3891,6 +3891,29 @@ func (r *ClickHouseReader) GetCountOfThings(ctx context.Context, query string) (
 	return count, nil
 }
 
+// EstimateQuery returns the estimate of the parts, rows and marks the query
+// reads from each table, without running it
+func (r *ClickHouseReader) EstimateQuery(ctx context.Context, query string) ([]v3.QueryEstimate, error) {
+	var estimates []v3.QueryEstimate
+	if err := r.db.Select(ctx, &estimates, "EXPLAIN ESTIMATE "+query); err != nil {
+		return nil, err
+	}
+
+	for i, estimate := range estimates {
+		var bytes, rows uint64
+		err := r.db.QueryRow(ctx, "SELECT sum(data_compressed_bytes), sum(rows) FROM system.parts WHERE active AND database = $1 AND table = $2",
+			estimate.Database, estimate.Table).Scan(&bytes, &rows)
+		if err != nil {
+			return nil, err
+		}
+		estimates[i].TotalRows = rows
+		if rows > 0 {
+			estimates[i].Bytes = uint64(float64(estimate.Rows) * float64(bytes) / float64(rows))
+		}
+	}
+	return estimates, nil
+}
+
 func (r *ClickHouseReader) GetLatestReceivedMetric(
 	ctx context.Context, metricNames []string, labelValues map[string]string,
 ) (*model.MetricStatus, *model.ApiError) {
@@ -5355,10 +5378,11 @@ ORDER BY firing_time ASC;`
 		}
 	}
 
-	// fetch the most recent overall_state from the table
+	// fetch the most recent overall_state from the table, the alerts still
+	// pending haven't changed it
 	var state model.AlertState
-	stateQuery := fmt.Sprintf("SELECT state FROM %s.%s WHERE rule_id = '%s' AND unix_milli <= %d ORDER BY unix_milli DESC LIMIT 1",
-		signozHistoryDBName, ruleStateHistoryTableName, ruleID, params.End)
+	stateQuery := fmt.Sprintf("SELECT state FROM %s.%s WHERE rule_id = '%s' AND state != '%s' AND unix_milli <= %d ORDER BY unix_milli DESC LIMIT 1",
+		signozHistoryDBName, ruleStateHistoryTableName, ruleID, model.StatePending.String(), params.End)
 	if err := r.db.QueryRow(ctx, stateQuery).Scan(&state); err != nil {
 		if err != sql.ErrNoRows {
 			return nil, err
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	smtpservice "go.signoz.io/signoz/pkg/query-service/utils/smtpService"
+	"go.uber.org/zap"
+)
+
+// DashboardReportScheduler periodically emails the dashboard reports which
+// are due
+type DashboardReportScheduler struct {
+	aH       *APIHandler
+	interval time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewDashboardReportScheduler(aH *APIHandler) *DashboardReportScheduler {
+	return &DashboardReportScheduler{
+		aH:       aH,
+		interval: time.Minute,
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *DashboardReportScheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.done:
+				return
+			case now := <-ticker.C:
+				s.runDueReports(context.Background(), now)
+			}
+		}
+	}()
+}
+
+func (s *DashboardReportScheduler) Stop() {
+	close(s.done)
+	s.wg.Wait()
+}
+
+func (s *DashboardReportScheduler) runDueReports(ctx context.Context, now time.Time) {
+	schedules, apiErr := dashboards.GetDueReportSchedules(ctx, now)
+	if apiErr != nil {
+		zap.L().Error("failed to get due dashboard reports", zap.Error(apiErr.Err))
+		return
+	}
+
+	for i := range schedules {
+		schedule := &schedules[i]
+		err := s.sendReport(ctx, schedule, now)
+		if err != nil {
+			zap.L().Error("failed to send dashboard report", zap.String("schedule", schedule.Id), zap.Error(err))
+		}
+		if apiErr := dashboards.MarkReportScheduleRun(ctx, schedule, now, err); apiErr != nil {
+			zap.L().Error("failed to update dashboard report schedule", zap.String("schedule", schedule.Id), zap.Error(apiErr.Err))
+		}
+	}
+}
+
+func (s *DashboardReportScheduler) sendReport(ctx context.Context, schedule *dashboards.ReportSchedule, now time.Time) error {
+	runQuery := func(data dashboards.Data, widgetId string, start, end int64) ([]*v3.Result, error) {
+		result, apiErr, _ := s.aH.runWidgetQuery(ctx, data, widgetId, start, end)
+		if apiErr != nil {
+			return nil, apiErr.Err
+		}
+		return result, nil
+	}
+
+	subject, body, apiErr := dashboards.RenderReport(ctx, schedule, now, runQuery)
+	if apiErr != nil {
+		return apiErr.Err
+	}
+
+	return smtpservice.GetInstance().SendEmail(schedule.Recipients, subject, body)
+}
@@ -0,0 +1,1411 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	logsv4 "go.signoz.io/signoz/pkg/query-service/app/logs/v4"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/dao"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/rules"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.uber.org/zap"
+)
+
+func (aH *APIHandler) getDashboardFolders(w http.ResponseWriter, r *http.Request) {
+	folders, apiErr := dashboards.GetFolders(r.Context())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, folders)
+}
+
+func (aH *APIHandler) getDashboardFolder(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	folder, apiErr := dashboards.GetFolder(r.Context(), id)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, folder)
+}
+
+func (aH *APIHandler) createDashboardFolder(w http.ResponseWriter, r *http.Request) {
+	var postData dashboards.FolderPostData
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	folder, apiErr := dashboards.CreateFolder(r.Context(), postData)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, folder)
+}
+
+func (aH *APIHandler) updateDashboardFolder(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var postData dashboards.FolderPostData
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	folder, apiErr := dashboards.UpdateFolder(r.Context(), id, postData)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, folder)
+}
+
+func (aH *APIHandler) deleteDashboardFolder(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if apiErr := dashboards.DeleteFolder(r.Context(), id); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+func (aH *APIHandler) moveDashboard(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	var postData struct {
+		FolderId *string `json:"folder_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	if apiErr := dashboards.MoveDashboard(r.Context(), uuid, postData.FolderId); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+// patchDashboard applies a JSON merge patch to the dashboard, so clients
+// changing a single field don't have to send the whole dashboard
+func (aH *APIHandler) patchDashboard(w http.ResponseWriter, r *http.Request) {
+	var patch map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+	if len(patch) == 0 {
+		RespondError(w, model.BadRequest(fmt.Errorf("patch must be a non-empty object")), nil)
+		return
+	}
+
+	force := false
+	if value := r.URL.Query().Get("force"); value != "" {
+		var err error
+		if force, err = strconv.ParseBool(value); err != nil {
+			RespondError(w, model.BadRequest(fmt.Errorf("invalid force: %s", value)), nil)
+			return
+		}
+	}
+
+	expectedVersion, err := expectedDashboardVersion(r)
+	if err != nil {
+		RespondError(w, model.BadRequest(err), nil)
+		return
+	}
+
+	dashboard, apiErr := dashboards.PatchDashboard(r.Context(), mux.Vars(r)["uuid"], patch, force, expectedVersion, aH.featureFlags)
+	if apiErr != nil {
+		RespondError(w, apiErr, dashboardErrorData(apiErr))
+		return
+	}
+
+	setDashboardETag(w, dashboard)
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+func parseListDashboardsParams(r *http.Request) (*dashboards.ListDashboardsParams, *model.ApiError) {
+	query := r.URL.Query()
+	params := &dashboards.ListDashboardsParams{
+		OrderBy: query.Get("orderBy"),
+		Order:   query.Get("order"),
+		Title:   query.Get("title"),
+	}
+	for _, tag := range query["tags"] {
+		if tag != "" {
+			params.Tags = append(params.Tags, tag)
+		}
+	}
+
+	var err error
+	if limit := query.Get("limit"); limit != "" {
+		if params.Limit, err = strconv.Atoi(limit); err != nil {
+			return nil, model.BadRequest(fmt.Errorf("invalid limit %q", limit))
+		}
+	}
+	if offset := query.Get("offset"); offset != "" {
+		if params.Offset, err = strconv.Atoi(offset); err != nil {
+			return nil, model.BadRequest(fmt.Errorf("invalid offset %q", offset))
+		}
+	}
+	if favorites := query.Get("favorites"); favorites != "" {
+		if params.Favorites, err = strconv.ParseBool(favorites); err != nil {
+			return nil, model.BadRequest(fmt.Errorf("invalid favorites %q", favorites))
+		}
+	}
+	if summary := query.Get("summary"); summary != "" {
+		if params.Summary, err = strconv.ParseBool(summary); err != nil {
+			return nil, model.BadRequest(fmt.Errorf("invalid summary %q", summary))
+		}
+	}
+	if archived := query.Get("archived"); archived != "" {
+		if params.Archived, err = strconv.ParseBool(archived); err != nil {
+			return nil, model.BadRequest(fmt.Errorf("invalid archived %q", archived))
+		}
+	}
+
+	if err := params.Validate(); err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	return params, nil
+}
+
+func (aH *APIHandler) getDeletedDashboards(w http.ResponseWriter, r *http.Request) {
+	deleted, apiErr := dashboards.GetDeletedDashboards(r.Context())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, deleted)
+}
+
+func (aH *APIHandler) restoreDeletedDashboard(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	dashboard, apiErr := dashboards.RestoreDashboard(r.Context(), uuid)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+func (aH *APIHandler) getDashboardTrashSettings(w http.ResponseWriter, r *http.Request) {
+	settings, apiErr := dashboards.GetTrashSettings(r.Context())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, settings)
+}
+
+func (aH *APIHandler) setDashboardTrashSettings(w http.ResponseWriter, r *http.Request) {
+	var postData struct {
+		RetentionDays int `json:"retention_days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	settings, apiErr := dashboards.SetTrashSettings(r.Context(), postData.RetentionDays)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, settings)
+}
+
+func (aH *APIHandler) archiveDashboard(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	dashboard, apiErr := dashboards.ArchiveDashboard(r.Context(), uuid)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+func (aH *APIHandler) unarchiveDashboard(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	dashboard, apiErr := dashboards.UnarchiveDashboard(r.Context(), uuid)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+func (aH *APIHandler) duplicateDashboard(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	dashboard, apiErr := dashboards.DuplicateDashboard(r.Context(), uuid, aH.featureFlags)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+// importGrafanaDashboard converts a grafana dashboard json and creates it, the
+// response lists the panels and variables which could not be converted
+func (aH *APIHandler) importGrafanaDashboard(w http.ResponseWriter, r *http.Request) {
+	var grafanaJSON model.GrafanaJSON
+	if err := json.NewDecoder(r.Body).Decode(&grafanaJSON); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	data, report := dashboards.ConvertGrafanaDashboard(grafanaJSON)
+	if err := dashboards.IsPostDataSane((*map[string]interface{})(&data)); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	dashboard, apiErr := dashboards.CreateDashboard(r.Context(), data, aH.featureFlags)
+	if apiErr != nil {
+		RespondError(w, apiErr, dashboardErrorData(apiErr))
+		return
+	}
+
+	aH.Respond(w, map[string]interface{}{
+		"dashboard": dashboard,
+		"report":    report,
+	})
+}
+
+// exportDashboard returns the dashboard in the requested format, grafana
+// exports come with a report of the panels which could not be exported
+func (aH *APIHandler) exportDashboard(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	dashboard, apiErr := dashboards.GetDashboard(r.Context(), uuid)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "signoz":
+		aH.Respond(w, dashboard.Data)
+	case "grafana":
+		grafanaJSON, report := dashboards.ExportGrafanaDashboard(dashboard)
+		aH.Respond(w, map[string]interface{}{
+			"dashboard": grafanaJSON,
+			"report":    report,
+		})
+	default:
+		RespondError(w, model.BadRequest(fmt.Errorf("unsupported export format: %s", format)), nil)
+	}
+}
+
+func (aH *APIHandler) restoreDashboardBackup(w http.ResponseWriter, r *http.Request) {
+	var backup map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&backup); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	expectedVersion, err := expectedDashboardVersion(r)
+	if err != nil {
+		RespondError(w, model.BadRequest(err), nil)
+		return
+	}
+
+	dashboard, apiErr := dashboards.RestoreDashboardBackup(r.Context(), mux.Vars(r)["uuid"], backup, expectedVersion, aH.featureFlags)
+	if apiErr != nil {
+		RespondError(w, apiErr, dashboardErrorData(apiErr))
+		return
+	}
+
+	setDashboardETag(w, dashboard)
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+func (aH *APIHandler) getDashboardTemplates(w http.ResponseWriter, r *http.Request) {
+	aH.Respond(w, dashboards.GetDashboardTemplates(r.Context()))
+}
+
+func (aH *APIHandler) getDashboardTemplate(w http.ResponseWriter, r *http.Request) {
+	template, apiErr := dashboards.GetDashboardTemplate(r.Context(), mux.Vars(r)["id"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, template)
+}
+
+// getDashboardTemplateThumbnail returns the preview of the template for the
+// template gallery, templates are few and small so it is not cached
+func (aH *APIHandler) getDashboardTemplateThumbnail(w http.ResponseWriter, r *http.Request) {
+	template, apiErr := dashboards.GetDashboardTemplate(r.Context(), mux.Vars(r)["id"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	writeThumbnail(w, dashboards.RenderThumbnail(template.Dashboard))
+}
+
+type instantiateDashboardTemplateRequest struct {
+	Params map[string]string `json:"params"`
+}
+
+func (aH *APIHandler) instantiateDashboardTemplate(w http.ResponseWriter, r *http.Request) {
+	var req instantiateDashboardTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	dashboard, apiErr := dashboards.InstantiateDashboardTemplate(r.Context(), mux.Vars(r)["id"], req.Params, aH.featureFlags)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+func (aH *APIHandler) searchDashboards(w http.ResponseWriter, r *http.Request) {
+	result, apiErr := dashboards.SearchDashboards(r.Context(), r.URL.Query().Get("q"))
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, result)
+}
+
+// shareSecret is kept apart from the secret of the user tokens so the share
+// tokens cannot be used to sign in and can be rotated on their own
+func (aH *APIHandler) shareSecret() string {
+	return constants.GetDashboardsShareSecret()
+}
+
+type createDashboardShareRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (aH *APIHandler) createDashboardShare(w http.ResponseWriter, r *http.Request) {
+	var req createDashboardShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	share, apiErr := dashboards.CreateDashboardShare(r.Context(), mux.Vars(r)["uuid"], req.ExpiresAt, aH.shareSecret())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, share)
+}
+
+func (aH *APIHandler) getDashboardShares(w http.ResponseWriter, r *http.Request) {
+	shares, apiErr := dashboards.GetDashboardShares(r.Context(), mux.Vars(r)["uuid"], aH.shareSecret())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, shares)
+}
+
+func (aH *APIHandler) revokeDashboardShare(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if apiErr := dashboards.RevokeDashboardShare(r.Context(), vars["uuid"], vars["id"]); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+// getPublicDashboard serves a shared dashboard to callers without an account,
+// only the dashboard data is returned
+func (aH *APIHandler) getPublicDashboard(w http.ResponseWriter, r *http.Request) {
+	dashboard, apiErr := dashboards.GetSharedDashboard(r.Context(), mux.Vars(r)["token"], aH.shareSecret())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, map[string]interface{}{
+		"uuid": dashboard.Uuid,
+		"data": dashboard.Data,
+	})
+}
+
+// queryPublicDashboardWidget runs the queries of a widget of a shared
+// dashboard. Only the queries stored in the dashboard can be run, for the
+// time range given by the start and end params in milliseconds, clamped to
+// the past and to the max range of the shared dashboards.
+func (aH *APIHandler) queryPublicDashboardWidget(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	dashboard, apiErr := dashboards.GetSharedDashboard(r.Context(), vars["token"], aH.shareSecret())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.respondWidgetQuery(w, r, dashboard, constants.GetDashboardsPublicMaxRange())
+}
+
+// queryDashboardWidget runs the queries of a widget of the dashboard, for the
+// time range given by the start and end params in milliseconds, along with the
+// annotations of the dashboard in the time range
+func (aH *APIHandler) queryDashboardWidget(w http.ResponseWriter, r *http.Request) {
+	dashboard, apiErr := dashboards.GetDashboard(r.Context(), mux.Vars(r)["uuid"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.respondWidgetQuery(w, r, dashboard, 0)
+}
+
+// widgetQueryResponse is the query range response of a widget with the
+// events to overlay on it
+type widgetQueryResponse struct {
+	v3.QueryRangeResponse
+	Annotations []dashboards.PanelAnnotation `json:"annotations"`
+}
+
+// respondWidgetQuery runs the queries of the widget, the time range is clamped
+// to the past and to the max range unless it is 0
+func (aH *APIHandler) respondWidgetQuery(w http.ResponseWriter, r *http.Request, dashboard *dashboards.Dashboard, maxRange time.Duration) {
+	now := time.Now().UnixMilli()
+	end := now
+	start := end - time.Hour.Milliseconds()
+	var err error
+	if value := r.URL.Query().Get("start"); value != "" {
+		if start, err = strconv.ParseInt(value, 10, 64); err != nil {
+			RespondError(w, model.BadRequest(fmt.Errorf("invalid start: %s", value)), nil)
+			return
+		}
+	}
+	if value := r.URL.Query().Get("end"); value != "" {
+		if end, err = strconv.ParseInt(value, 10, 64); err != nil {
+			RespondError(w, model.BadRequest(fmt.Errorf("invalid end: %s", value)), nil)
+			return
+		}
+	}
+	if maxRange > 0 {
+		end = min(end, now)
+		start = max(start, end-maxRange.Milliseconds())
+	}
+	if start >= end {
+		RespondError(w, model.BadRequest(fmt.Errorf("start must be before end")), nil)
+		return
+	}
+
+	result, apiErr, errData := aH.runWidgetQuery(r.Context(), dashboard.Data, mux.Vars(r)["widgetId"], start, end)
+	if apiErr != nil {
+		RespondError(w, apiErr, errData)
+		return
+	}
+
+	aH.Respond(w, widgetQueryResponse{
+		QueryRangeResponse: v3.QueryRangeResponse{Result: result},
+		Annotations:        dashboards.ResolveAnnotations(r.Context(), dashboard, start, end, aH.annotationFetchers()),
+	})
+}
+
+// annotationFetchers look up the alert firings and kubernetes events shown on
+// the panels
+func (aH *APIHandler) annotationFetchers() dashboards.AnnotationFetchers {
+	return dashboards.AnnotationFetchers{
+		AlertFirings: func(ctx context.Context, ruleIds []string, start, end int64) ([]dashboards.PanelAnnotation, error) {
+			annotations := []dashboards.PanelAnnotation{}
+			for _, ruleId := range ruleIds {
+				timeline, err := aH.reader.ReadRuleStateHistoryByRuleID(ctx, ruleId, &model.QueryRuleStateHistory{
+					Start: start,
+					End:   end,
+					State: model.StateFiring.String(),
+					Order: "asc",
+					Limit: 500,
+				})
+				if err != nil {
+					return nil, err
+				}
+				for _, item := range timeline.Items {
+					annotations = append(annotations, dashboards.PanelAnnotation{
+						Time:    item.UnixMilli,
+						EndTime: item.UnixMilli,
+						Title:   fmt.Sprintf("%s is firing", item.RuleName),
+						Text:    string(item.Labels),
+					})
+				}
+			}
+			return annotations, nil
+		},
+		K8sEvents: func(ctx context.Context, namespace string, start, end int64) ([]dashboards.PanelAnnotation, error) {
+			conditions := []string{
+				fmt.Sprintf("timestamp >= %d AND timestamp <= %d", start*1000000, end*1000000),
+				fmt.Sprintf("ts_bucket_start >= %d AND ts_bucket_start <= %d", start/1000-1800, end/1000),
+				"mapContains(attributes_string, 'k8s.event.reason')",
+			}
+			if namespace != "" {
+				conditions = append(conditions, fmt.Sprintf("resources_string['k8s.namespace.name'] = %s", utils.ClickHouseFormattedValue(namespace)))
+			}
+			query := fmt.Sprintf(`SELECT timestamp, attributes_string['k8s.event.reason'] AS reason,
+				attributes_string['k8s.object.kind'] AS kind, attributes_string['k8s.object.name'] AS name, body
+				FROM %s.%s WHERE %s ORDER BY timestamp LIMIT 500`,
+				logsv4.DB_NAME, logsv4.DISTRIBUTED_LOGS_V2, strings.Join(conditions, " AND "))
+
+			rows, err := aH.reader.GetListResultV3(ctx, query)
+			if err != nil {
+				return nil, err
+			}
+			annotations := []dashboards.PanelAnnotation{}
+			for _, row := range rows {
+				value := func(key string) string {
+					if v, ok := row.Data[key].(*string); ok && v != nil {
+						return *v
+					}
+					return ""
+				}
+				annotations = append(annotations, dashboards.PanelAnnotation{
+					Time:    row.Timestamp.UnixMilli(),
+					EndTime: row.Timestamp.UnixMilli(),
+					Title:   strings.TrimSpace(fmt.Sprintf("%s %s/%s", value("reason"), value("kind"), value("name"))),
+					Text:    value("body"),
+				})
+			}
+			return annotations, nil
+		},
+	}
+}
+
+func (aH *APIHandler) createAnnotation(w http.ResponseWriter, r *http.Request) {
+	var req dashboards.AnnotationPostData
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	annotation, apiErr := dashboards.CreateAnnotation(r.Context(), req)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, annotation)
+}
+
+// getAnnotations lists the annotations overlapping the time range given by
+// the start and end params in milliseconds, with all the tag params
+func (aH *APIHandler) getAnnotations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	end := time.Now().UnixMilli()
+	start := end - time.Hour.Milliseconds()
+	var err error
+	if value := query.Get("start"); value != "" {
+		if start, err = strconv.ParseInt(value, 10, 64); err != nil {
+			RespondError(w, model.BadRequest(fmt.Errorf("invalid start: %s", value)), nil)
+			return
+		}
+	}
+	if value := query.Get("end"); value != "" {
+		if end, err = strconv.ParseInt(value, 10, 64); err != nil {
+			RespondError(w, model.BadRequest(fmt.Errorf("invalid end: %s", value)), nil)
+			return
+		}
+	}
+
+	annotations, apiErr := dashboards.GetAnnotations(r.Context(), start, end, query["tag"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, annotations)
+}
+
+func (aH *APIHandler) deleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	if apiErr := dashboards.DeleteAnnotation(r.Context(), mux.Vars(r)["id"]); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+// runWidgetQuery runs the queries of the dashboard widget the same way the
+// query range api runs them for the frontend
+func (aH *APIHandler) runWidgetQuery(ctx context.Context, data dashboards.Data, widgetId string, start, end int64) ([]*v3.Result, *model.ApiError, interface{}) {
+	params, err := dashboards.WidgetQueryRangeParams(data, widgetId, start, end)
+	if err != nil {
+		return nil, model.BadRequest(err), nil
+	}
+
+	params, apiErr := PrepareQueryRangeParams(ctx, params)
+	if apiErr != nil {
+		return nil, apiErr, nil
+	}
+	params.Version = "v4"
+
+	if err := aH.PopulateTemporality(ctx, params); err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil
+	}
+
+	return aH.execQueryRangeV4(ctx, params)
+}
+
+type createDashboardSnapshotRequest struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// createDashboardSnapshot runs every widget of the dashboard for the time range
+// in milliseconds and stores the results
+func (aH *APIHandler) createDashboardSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req createDashboardSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	runQuery := func(data dashboards.Data, widgetId string) (interface{}, error) {
+		result, apiErr, _ := aH.runWidgetQuery(r.Context(), data, widgetId, req.Start, req.End)
+		if apiErr != nil {
+			return nil, apiErr.Err
+		}
+		return result, nil
+	}
+
+	snapshot, apiErr := dashboards.CreateDashboardSnapshot(r.Context(), mux.Vars(r)["uuid"], req.Start, req.End, runQuery)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, snapshot)
+}
+
+func (aH *APIHandler) getDashboardSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapshots, apiErr := dashboards.GetDashboardSnapshots(r.Context(), mux.Vars(r)["uuid"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, snapshots)
+}
+
+func (aH *APIHandler) getDashboardSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, apiErr := dashboards.GetDashboardSnapshot(r.Context(), mux.Vars(r)["id"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, snapshot)
+}
+
+func (aH *APIHandler) deleteDashboardSnapshot(w http.ResponseWriter, r *http.Request) {
+	if apiErr := dashboards.DeleteDashboardSnapshot(r.Context(), mux.Vars(r)["id"]); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+func (aH *APIHandler) getReportSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, apiErr := dashboards.GetReportSchedules(r.Context(), mux.Vars(r)["uuid"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, schedules)
+}
+
+func (aH *APIHandler) createReportSchedule(w http.ResponseWriter, r *http.Request) {
+	var postData dashboards.ReportSchedulePostData
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	schedule, apiErr := dashboards.CreateReportSchedule(r.Context(), mux.Vars(r)["uuid"], postData)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, schedule)
+}
+
+func (aH *APIHandler) updateReportSchedule(w http.ResponseWriter, r *http.Request) {
+	var postData dashboards.ReportSchedulePostData
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	schedule, apiErr := dashboards.UpdateReportSchedule(r.Context(), mux.Vars(r)["id"], postData)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, schedule)
+}
+
+func (aH *APIHandler) deleteReportSchedule(w http.ResponseWriter, r *http.Request) {
+	if apiErr := dashboards.DeleteReportSchedule(r.Context(), mux.Vars(r)["id"]); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+// bulkUpdateDashboards applies an operation to many dashboards, the response
+// reports the outcome for each of them
+func (aH *APIHandler) bulkUpdateDashboards(w http.ResponseWriter, r *http.Request) {
+	var req dashboards.BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	results, apiErr := dashboards.BulkUpdateDashboards(r.Context(), req)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, results)
+}
+
+// maxDashboardImportSize is the largest body accepted by the import, zipped
+// or not
+const maxDashboardImportSize = 64 << 20
+
+// importDashboards imports a json array of dashboards, or a zip archive of
+// dashboard json files when sent as application/zip. The conflict query
+// parameter tells what to do with dashboards whose uuid is taken.
+func (aH *APIHandler) importDashboards(w http.ResponseWriter, r *http.Request) {
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDashboardImportSize))
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	var items []dashboards.ImportItem
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/zip") {
+		items, err = dashboards.ParseImportZip(content)
+	} else {
+		items, err = dashboards.ParseImportJSON(content)
+	}
+	if err != nil {
+		RespondError(w, model.BadRequest(err), nil)
+		return
+	}
+
+	strategy := dashboards.ImportConflictStrategy(r.URL.Query().Get("conflict"))
+	results, apiErr := dashboards.ImportDashboards(r.Context(), items, strategy)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, results)
+}
+
+// lintDashboard checks a dashboard without saving it, for dashboards kept as
+// code to be checked in CI
+func (aH *APIHandler) lintDashboard(w http.ResponseWriter, r *http.Request) {
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	metricsExist := func(ctx context.Context, metricNames []string) (map[string]bool, error) {
+		temporality, err := aH.reader.FetchTemporality(ctx, metricNames)
+		if err != nil {
+			return nil, err
+		}
+		exist := map[string]bool{}
+		for name := range temporality {
+			exist[name] = true
+		}
+		return exist, nil
+	}
+
+	aH.Respond(w, dashboards.LintDashboard(r.Context(), data, metricsExist))
+}
+
+// renameDashboardMetric renames a metric in the panels of all dashboards, with
+// dry_run set it only lists the panels which would be changed
+func (aH *APIHandler) renameDashboardMetric(w http.ResponseWriter, r *http.Request) {
+	var req dashboards.MetricRenameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	panels, apiErr := dashboards.RenameMetric(r.Context(), req)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, panels)
+}
+
+// getDashboardAudit returns who changed the dashboard and how, newest first.
+// The log is kept after the dashboard is deleted.
+func (aH *APIHandler) getDashboardAudit(w http.ResponseWriter, r *http.Request) {
+	entries, apiErr := dashboards.GetDashboardAudit(r.Context(), mux.Vars(r)["uuid"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, entries)
+}
+
+func (aH *APIHandler) getDashboardVersions(w http.ResponseWriter, r *http.Request) {
+	versions, apiErr := dashboards.GetDashboardVersions(r.Context(), mux.Vars(r)["uuid"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, versions)
+}
+
+func (aH *APIHandler) getServiceDashboards(w http.ResponseWriter, r *http.Request) {
+	serviceDashboards, apiErr := dashboards.GetServiceDashboards(r.Context(), mux.Vars(r)["name"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, serviceDashboards)
+}
+
+func (aH *APIHandler) getDashboardServices(w http.ResponseWriter, r *http.Request) {
+	services, apiErr := dashboards.GetDashboardServices(r.Context(), mux.Vars(r)["uuid"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, services)
+}
+
+func (aH *APIHandler) addDashboardService(w http.ResponseWriter, r *http.Request) {
+	var postData struct {
+		Service string `json:"service"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	mapping, apiErr := dashboards.AddDashboardService(r.Context(), mux.Vars(r)["uuid"], postData.Service)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, mapping)
+}
+
+func (aH *APIHandler) getDashboardReferences(w http.ResponseWriter, r *http.Request) {
+	references, apiErr := dashboards.GetDashboardReferences(r.Context(), mux.Vars(r)["uuid"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, references)
+}
+
+func (aH *APIHandler) removeDashboardService(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if apiErr := dashboards.RemoveDashboardService(r.Context(), vars["uuid"], vars["name"]); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+func (aH *APIHandler) getHomeDashboard(w http.ResponseWriter, r *http.Request) {
+	dashboard, apiErr := dashboards.ResolveHomeDashboard(r.Context())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	setDashboardETag(w, dashboard)
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+func (aH *APIHandler) getHomeDashboards(w http.ResponseWriter, r *http.Request) {
+	homes, apiErr := dashboards.GetHomeDashboards(r.Context())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, homes)
+}
+
+func (aH *APIHandler) setHomeDashboard(w http.ResponseWriter, r *http.Request) {
+	var postData struct {
+		DashboardUuid string `json:"dashboard_uuid"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	home, apiErr := dashboards.SetHomeDashboard(r.Context(), mux.Vars(r)["role"], postData.DashboardUuid)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, home)
+}
+
+func (aH *APIHandler) removeHomeDashboard(w http.ResponseWriter, r *http.Request) {
+	if apiErr := dashboards.RemoveHomeDashboard(r.Context(), mux.Vars(r)["role"]); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+// resolveDashboardUsers sets the users who created and last updated the
+// dashboards, which are stored by id
+func resolveDashboardUsers(ctx context.Context, list ...*dashboards.Dashboard) {
+	users, apiErr := dao.DB().GetUsers(ctx)
+	if apiErr != nil {
+		zap.L().Error("failed to get the users of the dashboards", zap.Error(apiErr.Err))
+		return
+	}
+	usersById := map[string]model.UserPayload{}
+	for _, user := range users {
+		usersById[user.Id] = user
+	}
+
+	resolve := func(id *string) *dashboards.DashboardUser {
+		if id == nil || *id == "" {
+			return nil
+		}
+		if user, ok := usersById[*id]; ok {
+			return &dashboards.DashboardUser{Id: user.Id, Name: user.Name, Email: user.Email}
+		}
+		// dashboards saved before users were stored by id hold their email
+		if strings.Contains(*id, "@") {
+			return &dashboards.DashboardUser{Email: *id, NotFound: true}
+		}
+		return &dashboards.DashboardUser{Id: *id, NotFound: true}
+	}
+	for _, dashboard := range list {
+		dashboard.CreatedByUser = resolve(dashboard.CreateBy)
+		dashboard.UpdatedByUser = resolve(dashboard.UpdateBy)
+	}
+}
+
+// dashboardErrorData returns the details of a rejected dashboard change, the
+// invalid fields or the panels an update would remove, for the frontend to
+// show to the user
+func dashboardErrorData(apiErr *model.ApiError) interface{} {
+	var validationErr *dashboards.SchemaValidationError
+	if errors.As(apiErr.Err, &validationErr) {
+		return validationErr.Errors
+	}
+	var deletionErr *dashboards.PanelDeletionError
+	if errors.As(apiErr.Err, &deletionErr) {
+		return map[string]interface{}{"removed_widgets": deletionErr.WidgetIds}
+	}
+	var referencedErr *dashboards.DashboardReferencedError
+	if errors.As(apiErr.Err, &referencedErr) {
+		return map[string]interface{}{"references": referencedErr.References}
+	}
+	var existsErr *dashboards.DashboardExistsError
+	if errors.As(apiErr.Err, &existsErr) {
+		return map[string]interface{}{"uuid": existsErr.Uuid, "in_trash": existsErr.InTrash}
+	}
+	return nil
+}
+
+// setDashboardETag sets the ETag of the dashboard, clients send it back in
+// If-Match to save only if nobody else saved in between
+func setDashboardETag(w http.ResponseWriter, dashboard *dashboards.Dashboard) {
+	w.Header().Set("ETag", dashboard.ETag())
+}
+
+// notModified reports whether the client already has the content of the ETag,
+// in which case a 304 is sent instead of the body. Dashboards are large and
+// are polled by the frontend.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// expectedDashboardVersion returns the version the client expects to update,
+// from the If-Match header or the version query param, or 0 if there is none
+func expectedDashboardVersion(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("version")
+	if etag := r.Header.Get("If-Match"); etag != "" {
+		// the etag is the version followed by the hash of the content
+		value = strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+		value, _, _ = strings.Cut(value, "-")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid dashboard version: %s", value)
+	}
+	return version, nil
+}
+
+func (aH *APIHandler) starDashboard(w http.ResponseWriter, r *http.Request) {
+	if apiErr := dashboards.StarDashboard(r.Context(), mux.Vars(r)["uuid"]); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+func (aH *APIHandler) unstarDashboard(w http.ResponseWriter, r *http.Request) {
+	if apiErr := dashboards.UnstarDashboard(r.Context(), mux.Vars(r)["uuid"]); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+// getDashboardPanelStats counts the panels of all dashboards by data source
+// and query type, with a breakdown per dashboard
+func (aH *APIHandler) getDashboardPanelStats(w http.ResponseWriter, r *http.Request) {
+	inventory, apiErr := dashboards.GetPanelInventory(r.Context())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, inventory)
+}
+
+func (aH *APIHandler) getDashboardWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, apiErr := dashboards.GetDashboardWebhooks(r.Context())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, webhooks)
+}
+
+func (aH *APIHandler) createDashboardWebhook(w http.ResponseWriter, r *http.Request) {
+	var postData dashboards.DashboardWebhookPostData
+	if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	webhook, apiErr := dashboards.CreateDashboardWebhook(r.Context(), postData)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, webhook)
+}
+
+func (aH *APIHandler) deleteDashboardWebhook(w http.ResponseWriter, r *http.Request) {
+	if apiErr := dashboards.DeleteDashboardWebhook(r.Context(), mux.Vars(r)["id"]); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+}
+
+// getDashboardThumbnail returns the svg preview of the dashboard for the
+// dashboards list
+func (aH *APIHandler) getDashboardThumbnail(w http.ResponseWriter, r *http.Request) {
+	thumbnail, apiErr := dashboards.GetDashboardThumbnail(r.Context(), mux.Vars(r)["uuid"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	if notModified(w, r, thumbnail.ETag()) {
+		return
+	}
+	writeThumbnail(w, []byte(thumbnail.Content))
+}
+
+func writeThumbnail(w http.ResponseWriter, content []byte) {
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+// getDashboardBySlug returns the dashboard with the human readable slug of
+// its title, for links which stay stable when the dashboard is recreated
+func (aH *APIHandler) getDashboardBySlug(w http.ResponseWriter, r *http.Request) {
+	dashboard, apiErr := dashboards.GetDashboardBySlug(r.Context(), mux.Vars(r)["slug"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	dashboards.RecordDashboardView(r.Context(), dashboard.Uuid)
+
+	if notModified(w, r, dashboard.ETag()) {
+		return
+	}
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+// resolveWidgetLinks resolves the drill down links of a panel for the series
+// the user clicked on
+func (aH *APIHandler) resolveWidgetLinks(w http.ResponseWriter, r *http.Request) {
+	var req dashboards.LinkResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	vars := mux.Vars(r)
+	links, apiErr := dashboards.ResolveWidgetLinks(r.Context(), vars["uuid"], vars["widgetId"], req)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, links)
+}
+
+func (aH *APIHandler) getWidgetThresholds(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	thresholds, apiErr := dashboards.GetWidgetThresholds(r.Context(), vars["uuid"], vars["widgetId"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, thresholds)
+}
+
+// setWidgetThresholds replaces the thresholds of a panel, the dashboard is
+// saved as a new version
+func (aH *APIHandler) setWidgetThresholds(w http.ResponseWriter, r *http.Request) {
+	var thresholds []dashboards.WidgetThreshold
+	if err := json.NewDecoder(r.Body).Decode(&thresholds); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	expectedVersion, err := expectedDashboardVersion(r)
+	if err != nil {
+		RespondError(w, model.BadRequest(err), nil)
+		return
+	}
+
+	vars := mux.Vars(r)
+	dashboard, apiErr := dashboards.SetWidgetThresholds(r.Context(), vars["uuid"], vars["widgetId"], thresholds, expectedVersion, aH.featureFlags)
+	if apiErr != nil {
+		RespondError(w, apiErr, dashboardErrorData(apiErr))
+		return
+	}
+
+	setDashboardETag(w, dashboard)
+	resolveDashboardUsers(r.Context(), dashboard)
+	aH.Respond(w, dashboard)
+}
+
+// createWidgetAlert creates an alert rule from the query and the thresholds
+// of a panel, resolving the dashboard variables to their selected values, and
+// links the rule and the panel both ways
+func (aH *APIHandler) createWidgetAlert(w http.ResponseWriter, r *http.Request) {
+	var params rules.PanelRuleParams
+	// the params are optional
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	vars := mux.Vars(r)
+	uuid, widgetId := vars["uuid"], vars["widgetId"]
+	dashboard, apiErr := dashboards.GetDashboard(r.Context(), uuid)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	widget, err := dashboards.GetWidget(dashboard.Data, widgetId)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: err}, nil)
+		return
+	}
+	thresholds, apiErr := dashboards.GetWidgetThresholds(r.Context(), uuid, widgetId)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	end := time.Now().UnixMilli()
+	queryParams, err := dashboards.WidgetQueryRangeParams(dashboard.Data, widgetId, end-time.Hour.Milliseconds(), end)
+	if err != nil {
+		RespondError(w, model.BadRequest(err), nil)
+		return
+	}
+	queryParams, apiErr = PrepareQueryRangeParams(r.Context(), queryParams)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	panel := rules.Panel{
+		RulePanel:      rules.RulePanel{DashboardUuid: uuid, WidgetId: widgetId},
+		CompositeQuery: queryParams.CompositeQuery,
+	}
+	panel.Title, _ = widget["title"].(string)
+	panel.Unit, _ = widget["yAxisUnit"].(string)
+	for _, threshold := range thresholds {
+		if threshold.Value == nil {
+			continue
+		}
+		panel.Thresholds = append(panel.Thresholds, rules.PanelThreshold{
+			Operator: threshold.Operator,
+			Value:    *threshold.Value,
+			Unit:     threshold.Unit,
+			Severity: threshold.Severity,
+		})
+	}
+
+	postableRule, err := panel.NewRule(params)
+	if err != nil {
+		RespondError(w, model.BadRequest(err), nil)
+		return
+	}
+	ruleStr, err := json.Marshal(postableRule)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	rule, err := aH.ruleManager.CreateRule(r.Context(), string(ruleStr))
+	if err != nil {
+		RespondError(w, ruleApiError(err, model.ErrorBadData), nil)
+		return
+	}
+
+	ruleId, err := strconv.Atoi(rule.Id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	if apiErr := dashboards.AddWidgetAlert(r.Context(), uuid, widgetId, ruleId); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, rule)
+}
+
+// getWidgetAlerts returns the alert rules created from a panel
+func (aH *APIHandler) getWidgetAlerts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	alerts, apiErr := dashboards.GetWidgetAlerts(r.Context(), vars["uuid"], vars["widgetId"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, alerts)
+}
+
+// evaluateDashboardVariables returns the values of all the variables of the
+// dashboard in one call, evaluating the variables used by the queries of
+// other variables first
+func (aH *APIHandler) evaluateDashboardVariables(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Selected map[string]interface{} `json:"selected"`
+	}
+	// the selection is optional
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, "Error reading request body")
+		return
+	}
+
+	query := func(ctx context.Context, query string) ([]interface{}, error) {
+		if err := checkDashboardVarsQuery(query); err != nil {
+			return nil, err
+		}
+		result, err := aH.reader.QueryDashboardVars(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return result.VariableValues, nil
+	}
+
+	values, apiErr := dashboards.EvaluateVariables(r.Context(), mux.Vars(r)["uuid"], req.Selected, query)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	aH.Respond(w, values)
+}
@@ -0,0 +1,361 @@
+package dashboards
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+const (
+	// AnnotationSourceDeploy shows the markers posted to the annotations api,
+	// e.g. by deploy pipelines
+	AnnotationSourceDeploy = "deploy"
+	// AnnotationSourceAlert shows the firings of alert rules
+	AnnotationSourceAlert = "alert"
+	// AnnotationSourceK8sEvent shows the kubernetes events collected as logs
+	AnnotationSourceK8sEvent = "k8s_event"
+)
+
+// maxAnnotations is the most annotations a source adds to a panel
+const maxAnnotations = 500
+
+var knownAnnotationSources = map[string]bool{
+	AnnotationSourceDeploy:   true,
+	AnnotationSourceAlert:    true,
+	AnnotationSourceK8sEvent: true,
+}
+
+// ruleIdPattern matches the ids of alert rules, they end up in clickhouse
+// queries
+var ruleIdPattern = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// AnnotationSource is a source of the events shown on the panels of the
+// dashboard. The filters apply to the sources of their type only.
+type AnnotationSource struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled,omitempty"`
+	Color    string `json:"color,omitempty"`
+
+	// Tags filters the deploy markers, the markers shown have all the tags
+	Tags []string `json:"tags,omitempty"`
+	// RuleIds are the alert rules whose firings are shown
+	RuleIds []string `json:"ruleIds,omitempty"`
+	// Namespace filters the kubernetes events, events of all namespaces are
+	// shown if it is empty
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (s *AnnotationSource) Validate() []FieldError {
+	errs := []FieldError{}
+	addErr := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(s.Name) == "" {
+		addErr("name", "name is required")
+	}
+	if !knownAnnotationSources[s.Type] {
+		addErr("type", "unknown annotation source %q", s.Type)
+	}
+	if s.Color != "" && !thresholdColor.MatchString(s.Color) {
+		addErr("color", "invalid color %q, expected a hex color or a color name", s.Color)
+	}
+	if len(s.Tags) > 0 && s.Type != AnnotationSourceDeploy {
+		addErr("tags", "tags only filter deploy markers")
+	}
+	if s.Namespace != "" && s.Type != AnnotationSourceK8sEvent {
+		addErr("namespace", "namespace only filters kubernetes events")
+	}
+	if s.Type == AnnotationSourceAlert && len(s.RuleIds) == 0 {
+		addErr("ruleIds", "at least one alert rule is required")
+	}
+	if len(s.RuleIds) > 0 && s.Type != AnnotationSourceAlert {
+		addErr("ruleIds", "rule ids only filter alert firings")
+	}
+	for i, id := range s.RuleIds {
+		if !ruleIdPattern.MatchString(id) {
+			addErr(fmt.Sprintf("ruleIds[%d]", i), "invalid rule id %q", id)
+		}
+	}
+	return errs
+}
+
+// AnnotationTags are stored as a json array
+type AnnotationTags []string
+
+func (t AnnotationTags) Value() (driver.Value, error) {
+	if t == nil {
+		t = AnnotationTags{}
+	}
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (t *AnnotationTags) Scan(src interface{}) error {
+	var data []byte
+	if b, ok := src.([]byte); ok {
+		data = b
+	} else if s, ok := src.(string); ok {
+		data = []byte(s)
+	} else {
+		*t = AnnotationTags{}
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
+
+// Annotation is a marker posted to the annotations api, the times are in
+// milliseconds. Markers of a single point in time end when they start.
+type Annotation struct {
+	Id        string         `json:"id" db:"id"`
+	OrgId     string         `json:"-" db:"org_id"`
+	Time      int64          `json:"time" db:"time"`
+	EndTime   int64          `json:"endTime" db:"end_time"`
+	Title     string         `json:"title" db:"title"`
+	Text      string         `json:"text" db:"text"`
+	Tags      AnnotationTags `json:"tags" db:"tags"`
+	CreatedBy string         `json:"createdBy" db:"created_by"`
+	CreatedAt time.Time      `json:"createdAt" db:"created_at"`
+}
+
+func (a *Annotation) hasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, t := range a.Tags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// AnnotationPostData is the payload to create an annotation, the time
+// defaults to now
+type AnnotationPostData struct {
+	Time    int64    `json:"time"`
+	EndTime int64    `json:"endTime"`
+	Title   string   `json:"title"`
+	Text    string   `json:"text"`
+	Tags    []string `json:"tags"`
+}
+
+func (p *AnnotationPostData) Validate() error {
+	if strings.TrimSpace(p.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if p.Time < 0 || p.EndTime < 0 {
+		return fmt.Errorf("time must not be negative")
+	}
+	if p.EndTime != 0 && p.EndTime < p.Time {
+		return fmt.Errorf("end time must not be before the time")
+	}
+	for _, tag := range p.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("tags must not be empty")
+		}
+	}
+	return nil
+}
+
+func CreateAnnotation(ctx context.Context, postData AnnotationPostData) (*Annotation, *model.ApiError) {
+	if err := postData.Validate(); err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	orgId, userEmail := homeOrg(ctx)
+	annotation := &Annotation{
+		Id:        uuid.New().String(),
+		OrgId:     orgId,
+		Time:      postData.Time,
+		EndTime:   postData.EndTime,
+		Title:     strings.TrimSpace(postData.Title),
+		Text:      postData.Text,
+		Tags:      AnnotationTags{},
+		CreatedBy: userEmail,
+		CreatedAt: time.Now(),
+	}
+	if annotation.Time == 0 {
+		annotation.Time = annotation.CreatedAt.UnixMilli()
+	}
+	if annotation.EndTime == 0 {
+		annotation.EndTime = annotation.Time
+	}
+	for _, tag := range postData.Tags {
+		annotation.Tags = append(annotation.Tags, strings.TrimSpace(tag))
+	}
+
+	_, err := db.NamedExec(`INSERT INTO annotations (id, org_id, time, end_time, title, text, tags, created_by, created_at)
+		VALUES (:id, :org_id, :time, :end_time, :title, :text, :tags, :created_by, :created_at)`, annotation)
+	if err != nil {
+		zap.L().Error("Error in creating annotation", zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return annotation, nil
+}
+
+// GetAnnotations returns the annotations of the org overlapping the time
+// range, with all the tags
+func GetAnnotations(ctx context.Context, start, end int64, tags []string) ([]Annotation, *model.ApiError) {
+	if end < start {
+		return nil, model.BadRequest(fmt.Errorf("end must not be before start"))
+	}
+
+	orgId, _ := homeOrg(ctx)
+	annotations, err := annotationsInRange(orgId, start, end, tags)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return annotations, nil
+}
+
+func annotationsInRange(orgId string, start, end int64, tags []string) ([]Annotation, error) {
+	rows := []Annotation{}
+	err := db.Select(&rows, `SELECT * FROM annotations WHERE org_id=? AND time <= ? AND end_time >= ? ORDER BY time`,
+		orgId, end, start)
+	if err != nil {
+		zap.L().Error("Error in getting annotations", zap.Error(err))
+		return nil, err
+	}
+
+	// the tags are json, they are matched here rather than in the query
+	annotations := []Annotation{}
+	for _, annotation := range rows {
+		if annotation.hasTags(tags) {
+			annotations = append(annotations, annotation)
+		}
+		if len(annotations) == maxAnnotations {
+			break
+		}
+	}
+	return annotations, nil
+}
+
+func DeleteAnnotation(ctx context.Context, id string) *model.ApiError {
+	orgId, _ := homeOrg(ctx)
+	result, err := db.Exec(`DELETE FROM annotations WHERE id=? AND org_id=?`, id, orgId)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if affectedRows == 0 {
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no annotation found with id: %s", id)}
+	}
+	return nil
+}
+
+// PanelAnnotation is an event shown on the panels, from one of the annotation
+// sources of the dashboard
+type PanelAnnotation struct {
+	Source  string   `json:"source"`
+	Type    string   `json:"type"`
+	Color   string   `json:"color,omitempty"`
+	Time    int64    `json:"time"`
+	EndTime int64    `json:"endTime"`
+	Title   string   `json:"title"`
+	Text    string   `json:"text,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// AnnotationFetchers look up the events of the sources which are not stored
+// with the dashboards, in milliseconds
+type AnnotationFetchers struct {
+	AlertFirings func(ctx context.Context, ruleIds []string, start, end int64) ([]PanelAnnotation, error)
+	K8sEvents    func(ctx context.Context, namespace string, start, end int64) ([]PanelAnnotation, error)
+}
+
+// ResolveAnnotations returns the events of the enabled annotation sources of
+// the dashboard in the time range, in the order of their time. A source which
+// fails is left out, so the panels still show their data.
+func ResolveAnnotations(ctx context.Context, dashboard *Dashboard, start, end int64, fetchers AnnotationFetchers) []PanelAnnotation {
+	annotations := []PanelAnnotation{}
+
+	schema, err := ParseDashboardSchema(dashboard.Data)
+	if err != nil || len(schema.Annotations) == 0 {
+		return annotations
+	}
+
+	orgId := ""
+	if dashboard.OrgId != nil {
+		orgId = *dashboard.OrgId
+	}
+
+	for _, source := range schema.Annotations {
+		if source.Disabled {
+			continue
+		}
+
+		var events []PanelAnnotation
+		var err error
+		switch source.Type {
+		case AnnotationSourceDeploy:
+			events, err = deployAnnotations(orgId, source.Tags, start, end)
+		case AnnotationSourceAlert:
+			if fetchers.AlertFirings != nil {
+				events, err = fetchers.AlertFirings(ctx, source.RuleIds, start, end)
+			}
+		case AnnotationSourceK8sEvent:
+			if fetchers.K8sEvents != nil {
+				events, err = fetchers.K8sEvents(ctx, source.Namespace, start, end)
+			}
+		}
+		if err != nil {
+			zap.L().Error("Error in resolving dashboard annotations", zap.String("uuid", dashboard.Uuid), zap.String("source", source.Id), zap.Error(err))
+			continue
+		}
+
+		if len(events) > maxAnnotations {
+			events = events[:maxAnnotations]
+		}
+		for _, event := range events {
+			event.Source = source.Id
+			event.Type = source.Type
+			event.Color = source.Color
+			annotations = append(annotations, event)
+		}
+	}
+
+	sort.SliceStable(annotations, func(i, j int) bool { return annotations[i].Time < annotations[j].Time })
+	return annotations
+}
+
+func deployAnnotations(orgId string, tags []string, start, end int64) ([]PanelAnnotation, error) {
+	stored, err := annotationsInRange(orgId, start, end, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []PanelAnnotation{}
+	for _, annotation := range stored {
+		events = append(events, PanelAnnotation{
+			Time:    annotation.Time,
+			EndTime: annotation.EndTime,
+			Title:   annotation.Title,
+			Text:    annotation.Text,
+			Tags:    annotation.Tags,
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,110 @@
+package dashboards_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestAnnotations(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	deploy, apiErr := dashboards.CreateAnnotation(ctx, dashboards.AnnotationPostData{
+		Time: 1000, Title: "deploy checkout v42", Tags: []string{"checkout", "prod"},
+	})
+	require.Nil(apiErr)
+	require.Equal(int64(1000), deploy.EndTime)
+
+	_, apiErr = dashboards.CreateAnnotation(ctx, dashboards.AnnotationPostData{
+		Time: 2000, EndTime: 5000, Title: "migrate payments db", Tags: []string{"payments", "prod"},
+	})
+	require.Nil(apiErr)
+
+	_, apiErr = dashboards.CreateAnnotation(ctx, dashboards.AnnotationPostData{Time: 3000, EndTime: 2000, Title: "backwards"})
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	annotations, apiErr := dashboards.GetAnnotations(ctx, 0, 10000, nil)
+	require.Nil(apiErr)
+	require.Len(annotations, 2)
+
+	annotations, apiErr = dashboards.GetAnnotations(ctx, 4000, 10000, nil)
+	require.Nil(apiErr)
+	require.Len(annotations, 1)
+	require.Equal("migrate payments db", annotations[0].Title)
+
+	annotations, apiErr = dashboards.GetAnnotations(ctx, 0, 10000, []string{"prod", "checkout"})
+	require.Nil(apiErr)
+	require.Len(annotations, 1)
+	require.Equal(deploy.Id, annotations[0].Id)
+	require.Equal(dashboards.AnnotationTags{"checkout", "prod"}, annotations[0].Tags)
+
+	require.Nil(dashboards.DeleteAnnotation(ctx, deploy.Id))
+	apiErr = dashboards.DeleteAnnotation(ctx, deploy.Id)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+}
+
+func TestResolveAnnotations(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	_, apiErr := dashboards.CreateAnnotation(ctx, dashboards.AnnotationPostData{Time: 3000, Title: "deploy checkout", Tags: []string{"checkout"}})
+	require.Nil(apiErr)
+	_, apiErr = dashboards.CreateAnnotation(ctx, dashboards.AnnotationPostData{Time: 4000, Title: "deploy payments", Tags: []string{"payments"}})
+	require.Nil(apiErr)
+
+	_, apiErr = dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "checkout",
+		"annotations": []interface{}{
+			map[string]interface{}{"id": "alerts", "name": "Alerts", "type": "alert", "tags": []interface{}{"checkout"}},
+		},
+	}, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "checkout",
+		"annotations": []interface{}{
+			map[string]interface{}{"id": "deploys", "name": "Deploys", "type": "deploy", "tags": []interface{}{"checkout"}, "color": "#00ff00"},
+			map[string]interface{}{"id": "alerts", "name": "Alerts", "type": "alert", "ruleIds": []interface{}{"7"}},
+			map[string]interface{}{"id": "events", "name": "Events", "type": "k8s_event", "disabled": true},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	fetchers := dashboards.AnnotationFetchers{
+		AlertFirings: func(ctx context.Context, ruleIds []string, start, end int64) ([]dashboards.PanelAnnotation, error) {
+			require.Equal([]string{"7"}, ruleIds)
+			return []dashboards.PanelAnnotation{{Time: 2000, EndTime: 2000, Title: "High latency is firing"}}, nil
+		},
+		K8sEvents: func(ctx context.Context, namespace string, start, end int64) ([]dashboards.PanelAnnotation, error) {
+			t.Fatal("disabled sources are not resolved")
+			return nil, nil
+		},
+	}
+
+	annotations := dashboards.ResolveAnnotations(ctx, dashboard, 0, 10000, fetchers)
+	require.Len(annotations, 2)
+	require.Equal("alerts", annotations[0].Source)
+	require.Equal(dashboards.AnnotationSourceAlert, annotations[0].Type)
+	require.Equal("deploys", annotations[1].Source)
+	require.Equal("deploy checkout", annotations[1].Title)
+	require.Equal("#00ff00", annotations[1].Color)
+
+	// a failing source leaves the others
+	fetchers.AlertFirings = func(ctx context.Context, ruleIds []string, start, end int64) ([]dashboards.PanelAnnotation, error) {
+		return nil, errors.New("clickhouse is down")
+	}
+	annotations = dashboards.ResolveAnnotations(ctx, dashboard, 0, 10000, fetchers)
+	require.Len(annotations, 1)
+	require.Equal("deploys", annotations[0].Source)
+}
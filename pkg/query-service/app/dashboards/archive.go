@@ -0,0 +1,55 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// ArchiveDashboard archives the dashboard. Archived dashboards are hidden from
+// the list and left out of the telemetry, but can still be opened by their link
+// and are brought back with UnarchiveDashboard.
+func ArchiveDashboard(ctx context.Context, uuid string) (*Dashboard, *model.ApiError) {
+	return setDashboardArchived(ctx, uuid, true)
+}
+
+func UnarchiveDashboard(ctx context.Context, uuid string) (*Dashboard, *model.ApiError) {
+	return setDashboardArchived(ctx, uuid, false)
+}
+
+func setDashboardArchived(ctx context.Context, uuid string, archive bool) (*Dashboard, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if (dashboard.ArchivedAt != nil) == archive {
+		state := "archived"
+		if !archive {
+			state = "not archived"
+		}
+		return nil, &model.ApiError{Typ: model.ErrorConflict, Err: fmt.Errorf("dashboard %s is already %s", uuid, state)}
+	}
+
+	action := AuditActionUnarchive
+	var archivedAt *time.Time
+	if archive {
+		now := time.Now()
+		archivedAt = &now
+		action = AuditActionArchive
+	}
+
+	_, err := db.Exec(`UPDATE dashboards SET archived_at=? WHERE uuid=?`, archivedAt, uuid)
+	if err != nil {
+		zap.L().Error("Error in archiving dashboard", zap.String("uuid", uuid), zap.Bool("archive", archive), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	dashboard.ArchivedAt = archivedAt
+	recordAudit(ctx, db, uuid, action, fmt.Sprintf("%sd dashboard", action))
+	notifyWebhooks(ctx, uuid, action, dashboard.Version)
+
+	return dashboard, nil
+}
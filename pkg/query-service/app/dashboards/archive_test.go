@@ -0,0 +1,64 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestArchiveDashboard(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	seasonal, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "black friday"}, nil)
+	require.Nil(apiErr)
+	_, apiErr = dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "services"}, nil)
+	require.Nil(apiErr)
+
+	archived, apiErr := dashboards.ArchiveDashboard(ctx, seasonal.Uuid)
+	require.Nil(apiErr)
+	require.NotNil(archived.ArchivedAt)
+
+	_, apiErr = dashboards.ArchiveDashboard(ctx, seasonal.Uuid)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorConflict, apiErr.Type())
+
+	active, apiErr := dashboards.GetDashboards(ctx)
+	require.Nil(apiErr)
+	require.Len(active, 1)
+	require.Equal("services", active[0].Title)
+
+	listed, apiErr := dashboards.ListDashboards(ctx, dashboards.ListDashboardsParams{Archived: true})
+	require.Nil(apiErr)
+	require.Len(listed, 1)
+	require.Equal(seasonal.Uuid, listed[0].Uuid)
+
+	// archived dashboards can still be opened by their link
+	_, apiErr = dashboards.GetDashboard(ctx, seasonal.Uuid)
+	require.Nil(apiErr)
+
+	info, err := dashboards.GetDashboardsInfo(ctx)
+	require.NoError(err)
+	require.Equal(1, info.TotalDashboards)
+
+	_, apiErr = dashboards.EvaluateVariables(ctx, seasonal.Uuid, nil, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	unarchived, apiErr := dashboards.UnarchiveDashboard(ctx, seasonal.Uuid)
+	require.Nil(apiErr)
+	require.Nil(unarchived.ArchivedAt)
+
+	active, apiErr = dashboards.GetDashboards(ctx)
+	require.Nil(apiErr)
+	require.Len(active, 2)
+
+	entries, apiErr := dashboards.GetDashboardAudit(ctx, seasonal.Uuid)
+	require.Nil(apiErr)
+	require.Equal(dashboards.AuditActionUnarchive, entries[0].Action)
+	require.Equal(dashboards.AuditActionArchive, entries[1].Action)
+}
@@ -0,0 +1,192 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate    AuditAction = "create"
+	AuditActionUpdate    AuditAction = "update"
+	AuditActionDelete    AuditAction = "delete"
+	AuditActionRestore   AuditAction = "restore"
+	AuditActionLock      AuditAction = "lock"
+	AuditActionUnlock    AuditAction = "unlock"
+	AuditActionMove      AuditAction = "move"
+	AuditActionArchive   AuditAction = "archive"
+	AuditActionUnarchive AuditAction = "unarchive"
+	AuditActionPurge     AuditAction = "purge"
+)
+
+// AuditEntry records a change made to a dashboard. The entries are kept when
+// the dashboard is purged so it can still be told who deleted it.
+type AuditEntry struct {
+	Id            string      `json:"id" db:"id"`
+	DashboardUuid string      `json:"dashboard_uuid" db:"dashboard_uuid"`
+	OrgId         *string     `json:"-" db:"org_id"`
+	Actor         string      `json:"actor" db:"actor"`
+	Action        AuditAction `json:"action" db:"action"`
+	Summary       string      `json:"summary" db:"summary"`
+	CreatedAt     time.Time   `json:"created_at" db:"created_at"`
+
+	// Version is the version of the dashboard a change of its data saved,
+	// with the message the author gave for the change
+	Version int    `json:"version,omitempty" db:"version"`
+	Message string `json:"message,omitempty" db:"message"`
+}
+
+// maxChangeMessageLength is the longest change message kept with an update
+const maxChangeMessageLength = 1000
+
+// recordAudit adds an entry to the audit log of the dashboard. Failing to
+// record it is logged and does not fail the change itself.
+func recordAudit(ctx context.Context, e Store, dashboardUuid string, action AuditAction, summary string) {
+	recordVersionAudit(ctx, e, dashboardUuid, action, summary, 0, "")
+}
+
+// recordVersionAudit adds an entry for a change which saved a new version of
+// the dashboard data, the entries make up the version history
+func recordVersionAudit(ctx context.Context, e Store, dashboardUuid string, action AuditAction, summary string, version int, message string) {
+	var actor string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		actor = user.Email
+	}
+
+	// the entry keeps the org of the dashboard to be found after a purge
+	_, err := e.Exec(`INSERT INTO dashboard_audit (id, dashboard_uuid, org_id, actor, action, summary, version, message, created_at) VALUES (?, ?, (SELECT org_id FROM dashboards WHERE uuid=?), ?, ?, ?, ?, ?, ?)`,
+		uuid.New().String(), dashboardUuid, dashboardUuid, actor, action, summary, version, message, time.Now())
+	if err != nil {
+		zap.L().Error("Error in recording dashboard audit entry", zap.String("uuid", dashboardUuid), zap.String("action", string(action)), zap.Error(err))
+	}
+}
+
+// GetDashboardAudit returns the audit log of the dashboard, newest first. It
+// is scoped on the entries rather than the dashboard as the log outlives it.
+func GetDashboardAudit(ctx context.Context, dashboardUuid string) ([]AuditEntry, *model.ApiError) {
+	entries := []AuditEntry{}
+	scope, args := orgScope(ctx)
+	query := `SELECT * FROM dashboard_audit WHERE dashboard_uuid=?` + scope + ` ORDER BY created_at DESC`
+	err := db.Select(&entries, query, append([]interface{}{dashboardUuid}, args...)...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if len(entries) == 0 {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no dashboard found with uuid: %s", dashboardUuid)}
+	}
+
+	return entries, nil
+}
+
+// GetDashboardVersions returns the versions of the dashboard data, newest
+// first, with who saved them, why and what changed
+func GetDashboardVersions(ctx context.Context, dashboardUuid string) ([]AuditEntry, *model.ApiError) {
+	if _, apiErr := GetDashboard(ctx, dashboardUuid); apiErr != nil {
+		return nil, apiErr
+	}
+
+	entries := []AuditEntry{}
+	err := db.Select(&entries, `SELECT * FROM dashboard_audit WHERE dashboard_uuid=? AND version > 0 ORDER BY version DESC`, dashboardUuid)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return entries, nil
+}
+
+// popChangeMessage removes the change message from the posted data, it is
+// kept in the version history rather than in the dashboard
+func popChangeMessage(data map[string]interface{}) (string, error) {
+	value, ok := data["message"]
+	if !ok {
+		return "", nil
+	}
+	delete(data, "message")
+
+	message, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("message must be a string")
+	}
+	message = strings.TrimSpace(message)
+	if len(message) > maxChangeMessageLength {
+		return "", fmt.Errorf("message is %d characters, the limit is %d", len(message), maxChangeMessageLength)
+	}
+	return message, nil
+}
+
+// auditDiffSummary describes in a few words what an update changed
+func auditDiffSummary(before, after Data) string {
+	changes := []string{}
+
+	if oldTitle, newTitle := extractDashboardName(before), extractDashboardName(after); oldTitle != newTitle {
+		changes = append(changes, fmt.Sprintf("renamed from %q to %q", oldTitle, newTitle))
+	}
+	for _, key := range []string{"description", "tags", "variables", "layout"} {
+		if !reflect.DeepEqual(before[key], after[key]) {
+			changes = append(changes, fmt.Sprintf("changed %s", key))
+		}
+	}
+
+	beforeIds, beforeWidgets := auditWidgets(before)
+	afterIds, afterWidgets := auditWidgets(after)
+	added, removed, modified := []string{}, []string{}, []string{}
+	for _, id := range afterIds {
+		widget, ok := beforeWidgets[id]
+		if !ok {
+			added = append(added, auditWidgetName(afterWidgets[id]))
+		} else if !reflect.DeepEqual(widget, afterWidgets[id]) {
+			modified = append(modified, auditWidgetName(afterWidgets[id]))
+		}
+	}
+	for _, id := range beforeIds {
+		if _, ok := afterWidgets[id]; !ok {
+			removed = append(removed, auditWidgetName(beforeWidgets[id]))
+		}
+	}
+	for _, panels := range []struct {
+		verb  string
+		names []string
+	}{{"added", added}, {"removed", removed}, {"modified", modified}} {
+		if len(panels.names) > 0 {
+			changes = append(changes, fmt.Sprintf("%s panels: %s", panels.verb, strings.Join(panels.names, ", ")))
+		}
+	}
+
+	if len(changes) == 0 {
+		return "no changes"
+	}
+	return strings.Join(changes, "; ")
+}
+
+// auditWidgets returns the ids of the widgets in the order of the dashboard
+// along with the widgets by id
+func auditWidgets(data Data) ([]string, map[string]map[string]interface{}) {
+	ids := []string{}
+	widgets := map[string]map[string]interface{}{}
+	list, _ := data["widgets"].([]interface{})
+	for _, w := range list {
+		if widget, ok := w.(map[string]interface{}); ok {
+			if id, ok := widget["id"].(string); ok {
+				ids = append(ids, id)
+				widgets[id] = widget
+			}
+		}
+	}
+	return ids, widgets
+}
+
+func auditWidgetName(widget map[string]interface{}) string {
+	if title, ok := widget["title"].(string); ok && title != "" {
+		return fmt.Sprintf("%q", title)
+	}
+	return fmt.Sprintf("%v", widget["id"])
+}
@@ -0,0 +1,91 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardAudit(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	aliceCtx := contextWithUser("alice@signoz.io")
+	bobCtx := contextWithUser("bob@signoz.io")
+
+	widget := func(id, title string) map[string]interface{} {
+		return map[string]interface{}{"id": id, "title": title, "query": map[string]interface{}{}}
+	}
+	dashboard, apiErr := dashboards.CreateDashboard(aliceCtx, map[string]interface{}{
+		"title":   "kafka",
+		"widgets": []interface{}{widget("a", "Lag"), widget("b", "Throughput")},
+	}, nil)
+	require.Nil(apiErr)
+
+	_, apiErr = dashboards.UpdateDashboard(bobCtx, dashboard.Uuid, map[string]interface{}{
+		"title":   "kafka consumers",
+		"widgets": []interface{}{widget("a", "Lag"), widget("c", "Rebalances")},
+	}, false, 0, nil)
+	require.Nil(apiErr)
+
+	require.Nil(dashboards.LockUnlockDashboard(aliceCtx, dashboard.Uuid, true))
+	require.Nil(dashboards.LockUnlockDashboard(aliceCtx, dashboard.Uuid, false))
+	require.Nil(dashboards.DeleteDashboard(bobCtx, dashboard.Uuid, false, nil))
+
+	entries, apiErr := dashboards.GetDashboardAudit(aliceCtx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Len(entries, 5)
+
+	// newest first
+	expected := []struct {
+		actor   string
+		action  dashboards.AuditAction
+		summary string
+	}{
+		{"bob@signoz.io", dashboards.AuditActionDelete, "moved to trash"},
+		{"alice@signoz.io", dashboards.AuditActionUnlock, "unlocked dashboard"},
+		{"alice@signoz.io", dashboards.AuditActionLock, "locked dashboard"},
+		{"bob@signoz.io", dashboards.AuditActionUpdate, `renamed from "kafka" to "kafka consumers"; added panels: "Rebalances"; removed panels: "Throughput"`},
+		{"alice@signoz.io", dashboards.AuditActionCreate, `created dashboard "kafka"`},
+	}
+	for i, e := range expected {
+		require.Equal(e.actor, entries[i].Actor)
+		require.Equal(e.action, entries[i].Action)
+		require.Equal(e.summary, entries[i].Summary)
+	}
+}
+
+func TestDashboardChangeMessage(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "kafka"}, nil)
+	require.Nil(apiErr)
+
+	updated, apiErr := dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{
+		"title":   "kafka consumers",
+		"message": "only the consumers are monitored here",
+	}, false, 0, nil)
+	require.Nil(apiErr)
+	require.NotContains(updated.Data, "message")
+
+	_, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{
+		"title":   "kafka",
+		"message": 42,
+	}, false, 0, nil)
+	require.NotNil(apiErr)
+
+	versions, apiErr := dashboards.GetDashboardVersions(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Len(versions, 2)
+	require.Equal(2, versions[0].Version)
+	require.Equal("only the consumers are monitored here", versions[0].Message)
+	require.Equal(1, versions[1].Version)
+	require.Equal(dashboards.AuditActionCreate, versions[1].Action)
+
+	entries, apiErr := dashboards.GetDashboardAudit(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal("only the consumers are monitored here", entries[0].Message)
+}
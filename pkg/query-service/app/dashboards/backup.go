@@ -0,0 +1,55 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+
+	"go.signoz.io/signoz/pkg/query-service/interfaces"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+// RestoreDashboardBackup replaces the data of the dashboard with a backup of
+// it, as returned by the export endpoint. Unlike importing, the dashboard
+// keeps its uuid, version history, owner, lock and shares, and the restore is
+// saved as a new version. Backups of other dashboards are rejected.
+func RestoreDashboardBackup(ctx context.Context, uuid string, backup map[string]interface{}, expectedVersion int, fm interfaces.FeatureLookup) (*Dashboard, *model.ApiError) {
+	// the export endpoint wraps the data in the api response
+	if wrapped, ok := backup["data"].(map[string]interface{}); ok && backup["status"] != nil {
+		backup = wrapped
+	}
+	if len(backup) == 0 {
+		return nil, model.BadRequest(fmt.Errorf("backup must be a non-empty object"))
+	}
+	if err := IsPostDataSane(&backup); err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if expectedVersion != 0 && expectedVersion != dashboard.Version {
+		return nil, versionConflict()
+	}
+
+	if value, ok := backup["uuid"]; ok && value != nil {
+		id, err := dashboardUuid(backup)
+		if err != nil {
+			return nil, model.BadRequest(err)
+		}
+		if id != uuid {
+			return nil, model.BadRequest(fmt.Errorf("backup is of dashboard %s, import it to create a new dashboard", id))
+		}
+		backup["uuid"] = id
+	}
+	if GetSchemaVersion(backup) == 0 {
+		SetSchemaVersion(backup, inferSchemaVersion(backup))
+	}
+	if _, ok := backup["message"]; !ok {
+		backup["message"] = "restored from backup"
+	}
+
+	// the backup replaces the whole dashboard, panels it does not have are
+	// removed without confirmation
+	return UpdateDashboard(ctx, uuid, backup, true, dashboard.Version, fm)
+}
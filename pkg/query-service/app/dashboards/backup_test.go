@@ -0,0 +1,57 @@
+package dashboards_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestRestoreDashboardBackup(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "checkout",
+		"widgets": []interface{}{
+			map[string]interface{}{"id": "calls"},
+			map[string]interface{}{"id": "errors"},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	// the backup is the export, wrapped in the api response
+	var backup map[string]interface{}
+	raw, err := json.Marshal(map[string]interface{}{"status": "success", "data": dashboard.Data})
+	require.NoError(err)
+	require.NoError(json.Unmarshal(raw, &backup))
+
+	_, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "broken"}, true, 0, nil)
+	require.Nil(apiErr)
+
+	_, apiErr = dashboards.RestoreDashboardBackup(ctx, dashboard.Uuid, backup, 1, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorConflict, apiErr.Type())
+
+	restored, apiErr := dashboards.RestoreDashboardBackup(ctx, dashboard.Uuid, backup, 0, nil)
+	require.Nil(apiErr)
+	require.Equal(dashboard.Uuid, restored.Uuid)
+	require.Equal(3, restored.Version)
+	require.Equal("checkout", restored.Data["title"])
+	require.Len(restored.Data["widgets"], 2)
+
+	versions, apiErr := dashboards.GetDashboardVersions(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Len(versions, 3)
+	require.Equal("restored from backup", versions[0].Message)
+
+	// backups of other dashboards are imported instead
+	other := map[string]interface{}{"title": "payments", "uuid": "5d0e8f3a-1c2b-4a6d-9e7f-0b1c2d3e4f5a"}
+	_, apiErr = dashboards.RestoreDashboardBackup(ctx, dashboard.Uuid, other, 0, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+}
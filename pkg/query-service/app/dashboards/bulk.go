@@ -0,0 +1,220 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+type BulkOperation string
+
+const (
+	BulkOperationDelete BulkOperation = "delete"
+	BulkOperationLock   BulkOperation = "lock"
+	BulkOperationUnlock BulkOperation = "unlock"
+	BulkOperationTag    BulkOperation = "tag"
+	BulkOperationMove   BulkOperation = "move"
+)
+
+const maxBulkDashboards = 500
+
+// bulkOperationActions are the audit actions of the bulk operations
+var bulkOperationActions = map[BulkOperation]AuditAction{
+	BulkOperationDelete: AuditActionDelete,
+	BulkOperationLock:   AuditActionLock,
+	BulkOperationUnlock: AuditActionUnlock,
+	BulkOperationTag:    AuditActionUpdate,
+	BulkOperationMove:   AuditActionMove,
+}
+
+// BulkRequest applies one operation to many dashboards. Tags are required for
+// the tag operation, a nil or empty folder id moves dashboards to the top level.
+// Force deletes dashboards used by alert rules or reports as well.
+type BulkRequest struct {
+	Operation BulkOperation `json:"operation"`
+	Uuids     []string      `json:"uuids"`
+	Tags      []string      `json:"tags,omitempty"`
+	FolderId  *string       `json:"folder_id,omitempty"`
+	Force     bool          `json:"force,omitempty"`
+}
+
+func (r *BulkRequest) Validate() error {
+	switch r.Operation {
+	case BulkOperationDelete, BulkOperationLock, BulkOperationUnlock, BulkOperationMove:
+	case BulkOperationTag:
+		if len(r.Tags) == 0 {
+			return fmt.Errorf("tags are required for the tag operation")
+		}
+	default:
+		return fmt.Errorf("unsupported bulk operation: %s", r.Operation)
+	}
+
+	if len(r.Uuids) == 0 {
+		return fmt.Errorf("uuids must not be empty")
+	}
+	if len(r.Uuids) > maxBulkDashboards {
+		return fmt.Errorf("at most %d dashboards can be updated at once", maxBulkDashboards)
+	}
+	return nil
+}
+
+// BulkItemResult is the outcome of the bulk operation for a single dashboard
+type BulkItemResult struct {
+	Uuid    string `json:"uuid"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkUpdateDashboards applies the operation to every dashboard in a single
+// transaction. Dashboards the operation fails for are reported and skipped,
+// the others are committed together.
+func BulkUpdateDashboards(ctx context.Context, req BulkRequest) ([]BulkItemResult, *model.ApiError) {
+	if err := req.Validate(); err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	if req.FolderId != nil && *req.FolderId == "" {
+		req.FolderId = nil
+	}
+	if req.Operation == BulkOperationMove && req.FolderId != nil {
+		if _, apiErr := GetFolder(ctx, *req.FolderId); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+
+	user := common.GetUserFromContext(ctx)
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	defer tx.Rollback()
+
+	results := []BulkItemResult{}
+	updated := []*Dashboard{}
+	for _, uuid := range req.Uuids {
+		dashboard, err := bulkUpdateDashboard(ctx, tx, req, uuid, user)
+		if err != nil {
+			results = append(results, BulkItemResult{Uuid: uuid, Error: err.Error()})
+			continue
+		}
+		results = append(results, BulkItemResult{Uuid: uuid, Success: true})
+		updated = append(updated, dashboard)
+	}
+
+	if err := tx.Commit(); err != nil {
+		zap.L().Error("Error in committing bulk dashboard update", zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	for _, dashboard := range updated {
+		notifyWebhooks(ctx, dashboard.Uuid, bulkOperationActions[req.Operation], dashboard.Version)
+	}
+
+	return results, nil
+}
+
+func bulkUpdateDashboard(ctx context.Context, tx *storeTx, req BulkRequest, uuid string, user *model.UserPayload) (*Dashboard, error) {
+	dashboard, apiErr := getDashboard(ctx, tx, uuid)
+	if apiErr != nil {
+		return nil, apiErr.Err
+	}
+	locked := dashboard.Locked != nil && *dashboard.Locked == 1
+	if dashboard.ProvisionedFrom != nil && (req.Operation == BulkOperationDelete || req.Operation == BulkOperationTag) {
+		return nil, errProvisioned(dashboard).Err
+	}
+
+	var userId, userEmail string
+	if user != nil {
+		userId, userEmail = user.Id, user.Email
+	}
+
+	var err error
+	var action AuditAction
+	var summary string
+	// version is set by the operations saving a new version of the data
+	var version int
+	switch req.Operation {
+	case BulkOperationDelete:
+		if locked {
+			return nil, errors.New("dashboard is locked, please unlock the dashboard to be able to delete it")
+		}
+		if user != nil && !canManageDashboard(dashboard, user) {
+			return nil, errors.New("only the owner of the dashboard or an admin can delete it")
+		}
+		if !req.Force {
+			if apiErr := checkDashboardReferences(tx, uuid); apiErr != nil {
+				return nil, apiErr.Err
+			}
+		}
+		_, err = tx.Exec(`UPDATE dashboards SET deleted_at=? WHERE uuid=?`, time.Now(), uuid)
+		action, summary = AuditActionDelete, "moved to trash"
+
+	case BulkOperationLock:
+		if user != nil && !canManageDashboard(dashboard, user) {
+			return nil, errors.New("only the owner of the dashboard or an admin can lock it")
+		}
+		_, err = tx.Exec(`UPDATE dashboards SET locked=1, locked_by=?, locked_at=? WHERE uuid=?`, userEmail, time.Now(), uuid)
+		action, summary = AuditActionLock, "locked dashboard"
+
+	case BulkOperationUnlock:
+		if user != nil && !canUnlockDashboard(dashboard, user) {
+			return nil, errors.New("you are not authorized to unlock this dashboard")
+		}
+		_, err = tx.Exec(`UPDATE dashboards SET locked=0, locked_by=NULL, locked_at=NULL WHERE uuid=?`, uuid)
+		action, summary = AuditActionUnlock, "unlocked dashboard"
+
+	case BulkOperationTag:
+		if locked {
+			return nil, errors.New("dashboard is locked, please unlock the dashboard to be able to edit it")
+		}
+		addDashboardTags(dashboard.Data, req.Tags)
+		data, mErr := dashboard.Data.Value()
+		if mErr != nil {
+			return nil, mErr
+		}
+		dashboard.UpdatedAt = time.Now()
+		dashboard.Tags = dashboardTags(dashboard.Data)
+		_, err = tx.Exec(`UPDATE dashboards SET data=?, tags=?, updated_at=?, updated_by=?, version=version+1 WHERE uuid=?`, data, dashboard.Tags, dashboard.UpdatedAt, userId, uuid)
+		if err == nil {
+			err = indexDashboard(tx, uuid, dashboard.Data)
+		}
+		dashboard.Version++
+		version = dashboard.Version
+		action, summary = AuditActionUpdate, fmt.Sprintf("added tags: %s", strings.Join(req.Tags, ", "))
+
+	case BulkOperationMove:
+		_, err = tx.Exec(`UPDATE dashboards SET folder_id=? WHERE uuid=?`, req.FolderId, uuid)
+		action, summary = AuditActionMove, moveSummary(req.FolderId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	recordVersionAudit(ctx, tx, uuid, action, summary, version, "")
+
+	return dashboard, nil
+}
+
+// addDashboardTags adds the tags the dashboard does not have yet
+func addDashboardTags(data Data, tags []string) {
+	existing, _ := data["tags"].([]interface{})
+	seen := map[string]bool{}
+	for _, tag := range existing {
+		if s, ok := tag.(string); ok {
+			seen[s] = true
+		}
+	}
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			existing = append(existing, tag)
+		}
+	}
+	data["tags"] = existing
+}
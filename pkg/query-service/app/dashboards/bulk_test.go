@@ -0,0 +1,68 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestBulkUpdateDashboards(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	uuids := []string{}
+	for _, title := range []string{"one", "two", "three"} {
+		dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": title, "tags": []interface{}{"old"}}, nil)
+		require.Nil(apiErr)
+		uuids = append(uuids, dashboard.Uuid)
+	}
+
+	_, apiErr := dashboards.BulkUpdateDashboards(ctx, dashboards.BulkRequest{Operation: "archive", Uuids: uuids})
+	require.Equal(model.ErrorBadData, apiErr.Type())
+	_, apiErr = dashboards.BulkUpdateDashboards(ctx, dashboards.BulkRequest{Operation: dashboards.BulkOperationTag, Uuids: uuids})
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	results, apiErr := dashboards.BulkUpdateDashboards(ctx, dashboards.BulkRequest{
+		Operation: dashboards.BulkOperationTag,
+		Uuids:     append(uuids, "missing"),
+		Tags:      []string{"old", "team-a"},
+	})
+	require.Nil(apiErr)
+	require.Len(results, 4)
+	require.True(results[0].Success)
+	require.False(results[3].Success)
+	dashboard, apiErr := dashboards.GetDashboard(ctx, uuids[0])
+	require.Nil(apiErr)
+	require.Equal([]interface{}{"old", "team-a"}, dashboard.Data["tags"])
+
+	folder, apiErr := dashboards.CreateFolder(ctx, dashboards.FolderPostData{Name: "archive"})
+	require.Nil(apiErr)
+	results, apiErr = dashboards.BulkUpdateDashboards(ctx, dashboards.BulkRequest{
+		Operation: dashboards.BulkOperationMove,
+		Uuids:     uuids,
+		FolderId:  &folder.Uuid,
+	})
+	require.Nil(apiErr)
+	for _, result := range results {
+		require.True(result.Success)
+	}
+
+	// locked dashboards are not deleted, the others are
+	results, apiErr = dashboards.BulkUpdateDashboards(ctx, dashboards.BulkRequest{Operation: dashboards.BulkOperationLock, Uuids: uuids[:1]})
+	require.Nil(apiErr)
+	require.True(results[0].Success)
+	results, apiErr = dashboards.BulkUpdateDashboards(ctx, dashboards.BulkRequest{Operation: dashboards.BulkOperationDelete, Uuids: uuids})
+	require.Nil(apiErr)
+	require.False(results[0].Success)
+	require.True(results[1].Success)
+	require.True(results[2].Success)
+
+	remaining, apiErr := dashboards.GetDashboards(ctx)
+	require.Nil(apiErr)
+	require.Len(remaining, 1)
+	require.Equal(folder.Uuid, *remaining[0].FolderId)
+}
@@ -0,0 +1,65 @@
+package dashboards
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+
+	"go.uber.org/zap"
+)
+
+func compressData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressData returns gzipped data uncompressed and any other data as is
+func decompressData(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// CompressDashboardData compresses the data of the dashboards stored as plain
+// json before the data was compressed. Data is only compressed in SQLite.
+func CompressDashboardData(ctx context.Context) error {
+	if !db.Dialect().compressesData() {
+		return nil
+	}
+
+	type dashboardRow struct {
+		Uuid string `db:"uuid"`
+		Data Data   `db:"data"`
+	}
+
+	var dashboards []dashboardRow
+	err := db.Select(&dashboards, `SELECT uuid, data FROM dashboards WHERE hex(substr(data, 1, 2)) != '1F8B'`)
+	if err != nil {
+		return err
+	}
+
+	for _, dashboard := range dashboards {
+		_, err = db.Exec(`UPDATE dashboards SET data=? WHERE uuid=?`, dashboard.Data, dashboard.Uuid)
+		if err != nil {
+			zap.L().Error("Error in compressing dashboard data", zap.String("uuid", dashboard.Uuid), zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
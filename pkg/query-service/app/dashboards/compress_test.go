@@ -0,0 +1,26 @@
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataValueScan(t *testing.T) {
+	data := Data{"title": "hosts", "widgets": []interface{}{map[string]interface{}{"id": "cpu"}}}
+
+	value, err := data.Value()
+	require.NoError(t, err)
+	stored, ok := value.([]byte)
+	require.True(t, ok)
+	require.Equal(t, []byte{0x1f, 0x8b}, stored[:2])
+
+	var scanned Data
+	require.NoError(t, scanned.Scan(stored))
+	require.Equal(t, data, scanned)
+
+	// data stored as plain json before it was compressed is still read
+	var legacy Data
+	require.NoError(t, legacy.Scan(`{"title":"hosts"}`))
+	require.Equal(t, Data{"title": "hosts"}, legacy)
+}
@@ -0,0 +1,36 @@
+package dashboards
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+// ETag identifies the content of the dashboard, it changes whenever the data
+// or the update time does. Stored dashboards prefix it with their version so
+// it can be sent back in If-Match to update that version.
+func (d *Dashboard) ETag() string {
+	hash := sha256.New()
+	// the data was decoded from json, encoding it again can not fail
+	data, _ := json.Marshal(d.Data)
+	hash.Write(data)
+	hash.Write([]byte(strconv.FormatInt(d.UpdatedAt.UnixNano(), 10)))
+	sum := hex.EncodeToString(hash.Sum(nil))[:16]
+
+	if d.Version > 0 {
+		return strconv.Quote(strconv.Itoa(d.Version) + "-" + sum)
+	}
+	return strconv.Quote(sum)
+}
+
+// ListETag identifies the content of a list of dashboards, including their
+// order
+func ListETag(dashboards []Dashboard) string {
+	hash := sha256.New()
+	for i := range dashboards {
+		hash.Write([]byte(dashboards[i].Uuid))
+		hash.Write([]byte(dashboards[i].ETag()))
+	}
+	return strconv.Quote(hex.EncodeToString(hash.Sum(nil))[:16])
+}
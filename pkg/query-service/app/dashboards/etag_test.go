@@ -0,0 +1,37 @@
+package dashboards
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardETag(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	dashboard := Dashboard{Uuid: "a", Version: 3, UpdatedAt: updatedAt, Data: Data{"title": "hosts"}}
+
+	etag := dashboard.ETag()
+	require.Regexp(t, `^"3-[0-9a-f]{16}"$`, etag)
+
+	same := dashboard
+	same.Data = Data{"title": "hosts"}
+	require.Equal(t, etag, same.ETag())
+
+	changed := dashboard
+	changed.Data = Data{"title": "nodes"}
+	require.NotEqual(t, etag, changed.ETag())
+
+	touched := dashboard
+	touched.UpdatedAt = updatedAt.Add(time.Second)
+	require.NotEqual(t, etag, touched.ETag())
+
+	// integration dashboards have no version
+	integration := Dashboard{Uuid: "integration--nginx", Data: Data{"title": "nginx"}}
+	require.Regexp(t, `^"[0-9a-f]{16}"$`, integration.ETag())
+
+	list := []Dashboard{dashboard, integration}
+	require.Equal(t, ListETag(list), ListETag([]Dashboard{dashboard, integration}))
+	require.NotEqual(t, ListETag(list), ListETag([]Dashboard{integration, dashboard}))
+	require.NotEqual(t, ListETag(list), ListETag([]Dashboard{changed, integration}))
+}
@@ -0,0 +1,63 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+func favoritesUserId(ctx context.Context) (string, *model.ApiError) {
+	user := common.GetUserFromContext(ctx)
+	if user == nil {
+		return "", &model.ApiError{Typ: model.ErrorUnauthorized, Err: errors.New("favorites require a logged in user")}
+	}
+	return user.Id, nil
+}
+
+// StarDashboard adds the dashboard to the favorites of the user, starring it
+// again is a no-op
+func StarDashboard(ctx context.Context, uuid string) *model.ApiError {
+	userId, apiErr := favoritesUserId(ctx)
+	if apiErr != nil {
+		return apiErr
+	}
+	if _, apiErr := GetDashboard(ctx, uuid); apiErr != nil {
+		return apiErr
+	}
+
+	_, err := db.Exec(`INSERT INTO dashboard_favorites (user_id, dashboard_uuid, created_at) VALUES (?, ?, ?) ON CONFLICT(user_id, dashboard_uuid) DO NOTHING`,
+		userId, uuid, time.Now())
+	if err != nil {
+		zap.L().Error("Error in starring dashboard", zap.String("uuid", uuid), zap.Error(err))
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return nil
+}
+
+// UnstarDashboard removes the dashboard from the favorites of the user
+func UnstarDashboard(ctx context.Context, uuid string) *model.ApiError {
+	userId, apiErr := favoritesUserId(ctx)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	_, err := db.Exec(`DELETE FROM dashboard_favorites WHERE user_id=? AND dashboard_uuid=?`, userId, uuid)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return nil
+}
+
+// pruneFavorites removes the purged dashboards from the favorites
+func pruneFavorites() {
+	_, err := db.Exec(`DELETE FROM dashboard_favorites WHERE dashboard_uuid NOT IN (SELECT uuid FROM dashboards)`)
+	if err != nil {
+		zap.L().Error("Error in removing purged dashboards from the favorites", zap.Error(err))
+	}
+}
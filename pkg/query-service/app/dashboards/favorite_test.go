@@ -0,0 +1,48 @@
+package dashboards_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardFavorites(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	aliceCtx := contextWithUser("alice@signoz.io")
+	bobCtx := contextWithUser("bob@signoz.io")
+
+	kafka, apiErr := dashboards.CreateDashboard(aliceCtx, map[string]interface{}{"title": "kafka"}, nil)
+	require.Nil(apiErr)
+	postgres, apiErr := dashboards.CreateDashboard(aliceCtx, map[string]interface{}{"title": "postgres"}, nil)
+	require.Nil(apiErr)
+
+	require.Nil(dashboards.StarDashboard(aliceCtx, kafka.Uuid))
+	// starring twice is fine
+	require.Nil(dashboards.StarDashboard(aliceCtx, kafka.Uuid))
+	require.Nil(dashboards.StarDashboard(bobCtx, postgres.Uuid))
+
+	apiErr = dashboards.StarDashboard(aliceCtx, "missing")
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Typ)
+	require.NotNil(dashboards.StarDashboard(context.Background(), kafka.Uuid))
+
+	favorites, apiErr := dashboards.ListDashboards(aliceCtx, dashboards.ListDashboardsParams{Favorites: true})
+	require.Nil(apiErr)
+	require.Len(favorites, 1)
+	require.Equal(kafka.Uuid, favorites[0].Uuid)
+
+	require.Nil(dashboards.UnstarDashboard(aliceCtx, kafka.Uuid))
+	favorites, apiErr = dashboards.ListDashboards(aliceCtx, dashboards.ListDashboardsParams{Favorites: true})
+	require.Nil(apiErr)
+	require.Empty(favorites)
+
+	favorites, apiErr = dashboards.ListDashboards(bobCtx, dashboards.ListDashboardsParams{Favorites: true})
+	require.Nil(apiErr)
+	require.Len(favorites, 1)
+	require.Equal(postgres.Uuid, favorites[0].Uuid)
+}
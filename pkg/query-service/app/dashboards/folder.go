@@ -0,0 +1,213 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+type Folder struct {
+	Id        int       `json:"id" db:"id"`
+	Uuid      string    `json:"uuid" db:"uuid"`
+	Name      string    `json:"name" db:"name"`
+	ParentId  *string   `json:"parent_id" db:"parent_id"`
+	OrgId     *string   `json:"org_id" db:"org_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	CreateBy  *string   `json:"created_by" db:"created_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	UpdateBy  *string   `json:"updated_by" db:"updated_by"`
+}
+
+// FolderPostData is the payload to create, rename or move a folder
+type FolderPostData struct {
+	Name     string  `json:"name"`
+	ParentId *string `json:"parent_id"`
+}
+
+func (f *FolderPostData) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return fmt.Errorf("folder name is required")
+	}
+	if f.ParentId != nil && *f.ParentId == "" {
+		f.ParentId = nil
+	}
+	return nil
+}
+
+// CreateFolder creates a new folder, nested under the parent folder if given
+func CreateFolder(ctx context.Context, postData FolderPostData) (*Folder, *model.ApiError) {
+	if err := postData.Validate(); err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	if postData.ParentId != nil {
+		if _, apiErr := GetFolder(ctx, *postData.ParentId); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+
+	folder := &Folder{
+		Uuid:      uuid.New().String(),
+		Name:      postData.Name,
+		ParentId:  postData.ParentId,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	var userEmail string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		userEmail = user.Email
+		if user.OrgId != "" {
+			folder.OrgId = &user.OrgId
+		}
+	}
+	folder.CreateBy = &userEmail
+	folder.UpdateBy = &userEmail
+
+	err := db.Get(&folder.Id, `INSERT INTO folders (uuid, name, parent_id, org_id, created_at, created_by, updated_at, updated_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		folder.Uuid, folder.Name, folder.ParentId, folder.OrgId, folder.CreatedAt, userEmail, folder.UpdatedAt, userEmail)
+	if err != nil {
+		zap.L().Error("Error in inserting folder", zap.Any("folder", folder), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return folder, nil
+}
+
+// GetFolders returns the folders of the org of the user
+func GetFolders(ctx context.Context) ([]Folder, *model.ApiError) {
+
+	folders := []Folder{}
+	scope, args := orgScope(ctx)
+	query := `SELECT * FROM folders WHERE 1=1` + scope
+
+	err := db.Select(&folders, query, args...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return folders, nil
+}
+
+// GetFolder returns the folder if it belongs to the org of the user, the
+// changes to folders and the moves of dashboards into them all go through it
+func GetFolder(ctx context.Context, uuid string) (*Folder, *model.ApiError) {
+
+	folder := Folder{}
+	scope, args := orgScope(ctx)
+	query := `SELECT * FROM folders WHERE uuid=?` + scope
+
+	err := db.Get(&folder, query, append([]interface{}{uuid}, args...)...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no folder found with uuid: %s", uuid)}
+	}
+
+	return &folder, nil
+}
+
+// UpdateFolder renames the folder and moves it under the given parent
+func UpdateFolder(ctx context.Context, uuid string, postData FolderPostData) (*Folder, *model.ApiError) {
+	if err := postData.Validate(); err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	folder, apiErr := GetFolder(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	// walk up from the new parent to make sure the folder is not moved into itself
+	for parentId := postData.ParentId; parentId != nil; {
+		if *parentId == uuid {
+			return nil, model.BadRequest(fmt.Errorf("a folder cannot be moved into itself or one of its subfolders"))
+		}
+		parent, apiErr := GetFolder(ctx, *parentId)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		parentId = parent.ParentId
+	}
+
+	var userEmail string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		userEmail = user.Email
+	}
+
+	folder.Name = postData.Name
+	folder.ParentId = postData.ParentId
+	folder.UpdatedAt = time.Now()
+	folder.UpdateBy = &userEmail
+
+	_, err := db.Exec(`UPDATE folders SET name=?, parent_id=?, updated_at=?, updated_by=? WHERE uuid=?`,
+		folder.Name, folder.ParentId, folder.UpdatedAt, userEmail, folder.Uuid)
+	if err != nil {
+		zap.L().Error("Error in updating folder", zap.String("uuid", uuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return folder, nil
+}
+
+// DeleteFolder deletes an empty folder
+func DeleteFolder(ctx context.Context, uuid string) *model.ApiError {
+	if _, apiErr := GetFolder(ctx, uuid); apiErr != nil {
+		return apiErr
+	}
+
+	var count int
+	err := db.Get(&count, `SELECT (SELECT COUNT(*) FROM dashboards WHERE folder_id=? AND deleted_at IS NULL) + (SELECT COUNT(*) FROM folders WHERE parent_id=?)`, uuid, uuid)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if count > 0 {
+		return model.BadRequest(fmt.Errorf("folder is not empty, please move or delete its dashboards and subfolders first"))
+	}
+
+	_, err = db.Exec(`DELETE FROM folders WHERE uuid=?`, uuid)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return nil
+}
+
+// MoveDashboard moves the dashboard into the given folder, or to the top level if
+// the folder is nil
+func MoveDashboard(ctx context.Context, uuid string, folderId *string) *model.ApiError {
+	if folderId != nil && *folderId == "" {
+		folderId = nil
+	}
+
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if folderId != nil {
+		if _, apiErr := GetFolder(ctx, *folderId); apiErr != nil {
+			return apiErr
+		}
+	}
+
+	_, err := db.Exec(`UPDATE dashboards SET folder_id=? WHERE uuid=?`, folderId, uuid)
+	if err != nil {
+		zap.L().Error("Error in moving dashboard", zap.String("uuid", uuid), zap.Error(err))
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	recordAudit(ctx, db, uuid, AuditActionMove, moveSummary(folderId))
+	notifyWebhooks(ctx, uuid, AuditActionMove, dashboard.Version)
+
+	return nil
+}
+
+func moveSummary(folderId *string) string {
+	if folderId == nil {
+		return "moved to the top level"
+	}
+	return fmt.Sprintf("moved to folder %s", *folderId)
+}
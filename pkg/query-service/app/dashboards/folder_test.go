@@ -0,0 +1,46 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestFolders(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	parent, apiErr := dashboards.CreateFolder(ctx, dashboards.FolderPostData{Name: "platform"})
+	require.Nil(apiErr)
+	child, apiErr := dashboards.CreateFolder(ctx, dashboards.FolderPostData{Name: "databases", ParentId: &parent.Uuid})
+	require.Nil(apiErr)
+
+	// a folder cannot be moved below one of its own subfolders
+	_, apiErr = dashboards.UpdateFolder(ctx, parent.Uuid, dashboards.FolderPostData{Name: "platform", ParentId: &child.Uuid})
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "postgres"}, nil)
+	require.Nil(apiErr)
+	require.Nil(dashboards.MoveDashboard(ctx, dashboard.Uuid, &child.Uuid))
+
+	moved, apiErr := dashboards.GetDashboard(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal(child.Uuid, *moved.FolderId)
+
+	// non empty folders cannot be deleted
+	apiErr = dashboards.DeleteFolder(ctx, child.Uuid)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	require.Nil(dashboards.MoveDashboard(ctx, dashboard.Uuid, nil))
+	require.Nil(dashboards.DeleteFolder(ctx, child.Uuid))
+
+	folders, apiErr := dashboards.GetFolders(ctx)
+	require.Nil(apiErr)
+	require.Len(folders, 1)
+}
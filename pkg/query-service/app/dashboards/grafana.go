@@ -0,0 +1,460 @@
+package dashboards
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+// User for mapping job,instance from grafana
+var (
+	instanceEQRE = regexp.MustCompile("instance(?s)=(?s)\\\"{{.instance}}\\\"")
+	nodeEQRE     = regexp.MustCompile("instance(?s)=(?s)\\\"{{.node}}\\\"")
+	jobEQRE      = regexp.MustCompile("job(?s)=(?s)\\\"{{.job}}\\\"")
+	instanceRERE = regexp.MustCompile("instance(?s)=~(?s)\\\"{{.instance}}\\\"")
+	nodeRERE     = regexp.MustCompile("instance(?s)=~(?s)\\\"{{.node}}\\\"")
+	jobRERE      = regexp.MustCompile("job(?s)=~(?s)\\\"{{.job}}\\\"")
+)
+
+var (
+	// $var, ${var}, ${var:format} and [[var]]
+	grafanaVariableRE = regexp.MustCompile(`\$\{(\w+)(?::\w+)?\}|\[\[(\w+)\]\]|\$(\w+)`)
+	// label_values(metric, label) and label_values(label)
+	grafanaLabelValuesRE = regexp.MustCompile(`^\s*label_values\(\s*(?:([^,]+?)\s*,\s*)?(\w+)\s*\)\s*$`)
+)
+
+// grafana lays panels out on a 24 column grid, signoz on a 12 column one
+const (
+	grafanaGridColumns = 24
+	signozGridColumns  = 12
+)
+
+// grafanaBuiltinVariables are the grafana global variables replaced with fixed values
+var grafanaBuiltinVariables = map[string]string{
+	"__rate_interval": "5m",
+	"__interval":      "1m",
+	"__range":         "1h",
+}
+
+var grafanaPanelTypes = map[string]string{
+	"graph":      "graph",
+	"timeseries": "graph",
+	"stat":       "value",
+	"singlestat": "value",
+	"gauge":      "value",
+	"table":      "table",
+	"table-old":  "table",
+	"barchart":   "bar",
+	"bargauge":   "bar",
+	"piechart":   "pie",
+	"histogram":  "histogram",
+}
+
+var grafanaUnits = map[string]string{
+	"short":       "none",
+	"none":        "none",
+	"percent":     "percent",
+	"percentunit": "percentunit",
+	"bytes":       "bytes",
+	"decbytes":    "decbytes",
+	"bits":        "bits",
+	"Bps":         "Bps",
+	"bps":         "bps",
+	"s":           "s",
+	"ms":          "ms",
+	"µs":          "µs",
+	"ns":          "ns",
+	"reqps":       "reqps",
+	"ops":         "ops",
+}
+
+// UnconvertiblePanel is a grafana panel which could not be converted
+type UnconvertiblePanel struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// SkippedVariable is a grafana templating variable which could not be converted
+type SkippedVariable struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// GrafanaConversionReport describes the outcome of a grafana dashboard conversion
+type GrafanaConversionReport struct {
+	ConvertedPanels     int                  `json:"convertedPanels"`
+	UnconvertiblePanels []UnconvertiblePanel `json:"unconvertiblePanels"`
+	SkippedVariables    []SkippedVariable    `json:"skippedVariables"`
+}
+
+type grafanaConverter struct {
+	report    *GrafanaConversionReport
+	skipped   map[string]bool
+	widgets   []interface{}
+	layout    []interface{}
+	panelMap  map[string]interface{}
+	variables map[string]interface{}
+}
+
+// ConvertGrafanaDashboard converts a grafana dashboard into signoz dashboard data.
+// Panels, rows, templating variables and prometheus targets are mapped where
+// possible and everything else is listed in the returned report.
+func ConvertGrafanaDashboard(grafanaJSON model.GrafanaJSON) (Data, *GrafanaConversionReport) {
+	c := &grafanaConverter{
+		report: &GrafanaConversionReport{
+			UnconvertiblePanels: []UnconvertiblePanel{},
+			SkippedVariables:    []SkippedVariable{},
+		},
+		skipped:   map[string]bool{},
+		widgets:   []interface{}{},
+		layout:    []interface{}{},
+		panelMap:  map[string]interface{}{},
+		variables: map[string]interface{}{},
+	}
+
+	for idx, template := range grafanaJSON.Templating.List {
+		c.convertVariable(idx, template.Name, template.Type, template.Label, template.Query, template.Current.Value, template.Multi, template.IncludeAll, template.Sort)
+	}
+
+	// panels of an expanded row follow it at the top level, those of a collapsed
+	// row are nested in it
+	var currentRow string
+	for _, panel := range grafanaJSON.Panels {
+		if panel.Type != "row" {
+			c.convertPanel(panel, currentRow, false)
+			continue
+		}
+
+		currentRow = c.convertRow(panel)
+		if panel.Collapsed {
+			for _, child := range panel.Panels {
+				c.convertPanel(child, currentRow, true)
+			}
+			currentRow = ""
+		}
+	}
+
+	c.compactLayout()
+
+	tags := []interface{}{}
+	for _, tag := range grafanaJSON.Tags {
+		tags = append(tags, tag)
+	}
+
+	data := Data{
+		"title":       grafanaJSON.Title,
+		"description": fmt.Sprintf("Imported from grafana dashboard %s", grafanaJSON.UID),
+		"tags":        tags,
+		"widgets":     c.widgets,
+		"layout":      c.layout,
+		"panelMap":    c.panelMap,
+		"variables":   c.variables,
+	}
+
+	return data, c.report
+}
+
+func (c *grafanaConverter) skipVariable(name, typ, reason string) {
+	c.skipped[name] = true
+	c.report.SkippedVariables = append(c.report.SkippedVariables, SkippedVariable{Name: name, Type: typ, Reason: reason})
+}
+
+func (c *grafanaConverter) convertVariable(order int, name, typ, label string, query, current interface{}, multi, includeAll bool, sort int) {
+	id := uuid.New().String()
+	variable := map[string]interface{}{
+		"id":               id,
+		"key":              id,
+		"name":             name,
+		"description":      label,
+		"order":            order,
+		"sort":             grafanaSort(sort),
+		"multiSelect":      multi,
+		"showALLOption":    includeAll && multi,
+		"allSelected":      false,
+		"modificationUUID": uuid.New().String(),
+		"queryValue":       "",
+		"customValue":      "",
+		"textboxValue":     "",
+	}
+
+	queryStr := grafanaQueryString(query)
+	switch typ {
+	case "query":
+		matches := grafanaLabelValuesRE.FindStringSubmatch(queryStr)
+		if matches == nil {
+			c.skipVariable(name, typ, fmt.Sprintf("unsupported variable query %q, only label_values is supported", queryStr))
+			return
+		}
+		variable["type"] = "QUERY"
+		variable["queryValue"] = labelValuesToClickHouse(matches[1], matches[2])
+	case "custom":
+		variable["type"] = "CUSTOM"
+		variable["customValue"] = queryStr
+	case "interval":
+		variable["type"] = "INTERVAL"
+		variable["intervalValue"] = queryStr
+	case "textbox":
+		variable["type"] = "TEXTBOX"
+		variable["textboxValue"] = queryStr
+	case "constant":
+		variable["type"] = "CONSTANT"
+		variable["constantValue"] = queryStr
+		variable["multiSelect"] = false
+		variable["showALLOption"] = false
+	default:
+		c.skipVariable(name, typ, fmt.Sprintf("unsupported variable type %q", typ))
+		return
+	}
+
+	if isGrafanaAllValue(current) {
+		variable["allSelected"] = variable["showALLOption"]
+	} else if current != nil {
+		variable["selectedValue"] = current
+	}
+	c.variables[id] = variable
+}
+
+// isGrafanaAllValue reports whether the current value of a variable is the
+// All option of grafana
+func isGrafanaAllValue(current interface{}) bool {
+	if values, ok := current.([]interface{}); ok && len(values) == 1 {
+		current = values[0]
+	}
+	return current == "$__all"
+}
+
+func (c *grafanaConverter) convertRow(panel model.Panels) string {
+	id := uuid.New().String()
+	c.widgets = append(c.widgets, map[string]interface{}{
+		"id":          id,
+		"panelTypes":  "row",
+		"title":       panel.Title,
+		"description": panel.Description,
+	})
+	c.layout = append(c.layout, map[string]interface{}{
+		"i":      id,
+		"x":      0,
+		"y":      grafanaToSignozHeight(panel.GridPos.Y),
+		"w":      signozGridColumns,
+		"h":      1,
+		"moved":  false,
+		"static": false,
+	})
+	c.panelMap[id] = map[string]interface{}{
+		"widgets":   []interface{}{},
+		"collapsed": panel.Collapsed,
+	}
+	return id
+}
+
+func (c *grafanaConverter) convertPanel(panel model.Panels, row string, collapsed bool) {
+	unconvertible := func(reason string) {
+		c.report.UnconvertiblePanels = append(c.report.UnconvertiblePanels, UnconvertiblePanel{
+			ID:     panel.ID,
+			Title:  panel.Title,
+			Type:   panel.Type,
+			Reason: reason,
+		})
+	}
+
+	panelType, ok := grafanaPanelTypes[panel.Type]
+	if !ok {
+		unconvertible(fmt.Sprintf("unsupported panel type %q", panel.Type))
+		return
+	}
+
+	promQueries := []interface{}{}
+	for idx, target := range panel.Targets {
+		datasource := target.Datasource
+		if datasource == nil {
+			datasource = panel.Datasource
+		}
+		if !isPrometheusDatasource(datasource) {
+			unconvertible("only prometheus queries are supported")
+			return
+		}
+		if strings.TrimSpace(target.Expr) == "" {
+			continue
+		}
+
+		name := target.RefID
+		if name == "" {
+			name = string(rune('A' + idx))
+		}
+		promQueries = append(promQueries, map[string]interface{}{
+			"name":     name,
+			"query":    c.convertPromQL(target.Expr),
+			"legend":   target.LegendFormat,
+			"disabled": target.Hide,
+		})
+	}
+	if len(promQueries) == 0 {
+		unconvertible("panel has no prometheus queries")
+		return
+	}
+
+	unit, ok := grafanaUnits[panel.FieldConfig.Defaults.Unit]
+	if !ok {
+		unit = "none"
+	}
+
+	id := uuid.New().String()
+	c.widgets = append(c.widgets, map[string]interface{}{
+		"id":             id,
+		"title":          panel.Title,
+		"description":    panel.Description,
+		"panelTypes":     panelType,
+		"yAxisUnit":      unit,
+		"isStacked":      false,
+		"opacity":        "1",
+		"nullZeroValues": "zero",
+		"timePreferance": "GLOBAL_TIME",
+		"softMin":        nil,
+		"softMax":        nil,
+		"thresholds":     []interface{}{},
+		"query": map[string]interface{}{
+			"id":        uuid.New().String(),
+			"queryType": "promql",
+			"promql":    promQueries,
+			"builder": map[string]interface{}{
+				"queryData":     []interface{}{},
+				"queryFormulas": []interface{}{},
+			},
+			"clickhouse_sql": []interface{}{
+				map[string]interface{}{"name": "A", "query": "", "legend": "", "disabled": false},
+			},
+		},
+	})
+
+	item := map[string]interface{}{
+		"i":      id,
+		"x":      panel.GridPos.X * signozGridColumns / grafanaGridColumns,
+		"y":      grafanaToSignozHeight(panel.GridPos.Y),
+		"w":      int(math.Max(1, float64(panel.GridPos.W*signozGridColumns/grafanaGridColumns))),
+		"h":      int(math.Max(2, float64(grafanaToSignozHeight(panel.GridPos.H)))),
+		"moved":  false,
+		"static": false,
+	}
+	// the panels of collapsed rows are only laid out when the row is expanded
+	if !collapsed {
+		c.layout = append(c.layout, item)
+	}
+	if row != "" {
+		rowPanels := c.panelMap[row].(map[string]interface{})
+		rowPanels["widgets"] = append(rowPanels["widgets"].([]interface{}), item)
+	}
+
+	c.report.ConvertedPanels++
+}
+
+// convertPromQL rewrites grafana variable references into signoz template
+// variables. Matchers on job/instance variables which could not be converted
+// are relaxed to match any value.
+func (c *grafanaConverter) convertPromQL(query string) string {
+	query = grafanaVariableRE.ReplaceAllStringFunc(query, func(match string) string {
+		groups := grafanaVariableRE.FindStringSubmatch(match)
+		name := groups[1] + groups[2] + groups[3]
+		if value, ok := grafanaBuiltinVariables[name]; ok {
+			return value
+		}
+		return fmt.Sprintf("{{.%s}}", name)
+	})
+
+	if c.skipped["instance"] {
+		query = instanceEQRE.ReplaceAllString(query, "instance!=\"\"")
+		query = instanceRERE.ReplaceAllString(query, "instance!=\"\"")
+	}
+	if c.skipped["node"] {
+		query = nodeEQRE.ReplaceAllString(query, "instance!=\"\"")
+		query = nodeRERE.ReplaceAllString(query, "instance!=\"\"")
+	}
+	if c.skipped["job"] {
+		query = jobEQRE.ReplaceAllString(query, "job!=\"\"")
+		query = jobRERE.ReplaceAllString(query, "job!=\"\"")
+	}
+	return query
+}
+
+func labelValuesToClickHouse(metric, label string) string {
+	query := fmt.Sprintf("SELECT JSONExtractString(labels, '%s') AS %s\nFROM signoz_metrics.distributed_time_series_v4_1day", label, label)
+	// label_values(metric{...}, label) only keeps the metric name
+	if metric = strings.TrimSpace(metric); metric != "" {
+		if idx := strings.Index(metric, "{"); idx >= 0 {
+			metric = metric[:idx]
+		}
+		query += fmt.Sprintf("\nWHERE metric_name = '%s'", metric)
+	}
+	return query + fmt.Sprintf("\nGROUP BY %s", label)
+}
+
+func isPrometheusDatasource(datasource interface{}) bool {
+	switch ds := datasource.(type) {
+	case nil:
+		// the default datasource
+		return true
+	case string:
+		// a datasource variable such as ${DS_PROMETHEUS}
+		return strings.HasPrefix(ds, "$") || strings.Contains(strings.ToLower(ds), "prometheus")
+	case map[string]interface{}:
+		typ, _ := ds["type"].(string)
+		return typ == "" || typ == "prometheus"
+	}
+	return false
+}
+
+func grafanaQueryString(query interface{}) string {
+	switch q := query.(type) {
+	case string:
+		return q
+	case map[string]interface{}:
+		// newer grafana versions wrap the query in an object
+		if s, ok := q["query"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func grafanaSort(sort int) string {
+	switch sort {
+	case 1, 3, 5:
+		return "ASC"
+	case 2, 4, 6:
+		return "DESC"
+	}
+	return "DISABLED"
+}
+
+// compactLayout moves the panels down until they no longer overlap the ones
+// above them, rounding to the signoz grid can make neighbouring panels overlap
+func (c *grafanaConverter) compactLayout() {
+	layoutItem := func(item map[string]interface{}) LayoutItem {
+		return LayoutItem{X: item["x"].(int), Y: item["y"].(int), W: item["w"].(int), H: item["h"].(int)}
+	}
+
+	placed := []map[string]interface{}{}
+	for _, entry := range c.layout {
+		item := entry.(map[string]interface{})
+		for moved := true; moved; {
+			moved = false
+			for _, other := range placed {
+				if layoutItem(item).overlaps(layoutItem(other)) {
+					item["y"] = other["y"].(int) + other["h"].(int)
+					moved = true
+				}
+			}
+		}
+		placed = append(placed, item)
+	}
+}
+
+func grafanaToSignozHeight(h int) int {
+	return int(math.Round(float64(h) * 3 / 8))
+}
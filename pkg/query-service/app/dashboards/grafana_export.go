@@ -0,0 +1,434 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+var (
+	// {{.var}} and {{ .var }}
+	signozVariableRE = regexp.MustCompile(`\{\{\s*\.(\w+)\s*\}\}`)
+	// signoz reserved time variables and their grafana equivalents
+	signozTimeVariables = map[string]string{
+		"start_timestamp":      "${__from:date:seconds}",
+		"end_timestamp":        "${__to:date:seconds}",
+		"start_timestamp_ms":   "${__from}",
+		"end_timestamp_ms":     "${__to}",
+		"SIGNOZ_START_TIME":    "${__from}",
+		"SIGNOZ_END_TIME":      "${__to}",
+		"start_timestamp_nano": "${__from}000000",
+		"end_timestamp_nano":   "${__to}000000",
+		"start_datetime":       "toDateTime(${__from:date:seconds})",
+		"end_datetime":         "toDateTime(${__to:date:seconds})",
+	}
+)
+
+var signozPanelTypes = map[string]string{
+	"graph":     "timeseries",
+	"value":     "stat",
+	"table":     "table",
+	"bar":       "barchart",
+	"pie":       "piechart",
+	"histogram": "histogram",
+}
+
+// UnexportablePanel is a signoz panel which could not be exported
+type UnexportablePanel struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Reason string `json:"reason"`
+}
+
+// GrafanaExportReport describes the outcome of a grafana dashboard export
+type GrafanaExportReport struct {
+	ExportedPanels     int                 `json:"exportedPanels"`
+	UnexportablePanels []UnexportablePanel `json:"unexportablePanels"`
+}
+
+// ExportGrafanaDashboard converts a signoz dashboard into grafana dashboard json.
+// PromQL panels are exported as is, metrics builder panels are translated to
+// PromQL and ClickHouse panels target the grafana clickhouse datasource. Panels
+// which cannot be expressed in grafana are listed in the returned report.
+func ExportGrafanaDashboard(dashboard *Dashboard) (map[string]interface{}, *GrafanaExportReport) {
+	report := &GrafanaExportReport{UnexportablePanels: []UnexportablePanel{}}
+
+	layouts := map[string]map[string]interface{}{}
+	if items, ok := dashboard.Data["layout"].([]interface{}); ok {
+		for _, item := range items {
+			if layout, ok := item.(map[string]interface{}); ok {
+				if id, ok := layout["i"].(string); ok {
+					layouts[id] = layout
+				}
+			}
+		}
+	}
+
+	panels := []interface{}{}
+	widgets, _ := dashboard.Data["widgets"].([]interface{})
+	for idx, w := range widgets {
+		widget, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := widget["id"].(string)
+		title, _ := widget["title"].(string)
+		panelType, _ := widget["panelTypes"].(string)
+
+		panel := map[string]interface{}{
+			"id":          idx + 1,
+			"title":       title,
+			"description": widget["description"],
+			"gridPos":     signozToGrafanaGridPos(layouts[id]),
+		}
+
+		if panelType == "row" {
+			panel["type"] = "row"
+			panel["collapsed"] = false
+			panel["panels"] = []interface{}{}
+			panels = append(panels, panel)
+			continue
+		}
+
+		grafanaType, ok := signozPanelTypes[panelType]
+		if !ok {
+			report.UnexportablePanels = append(report.UnexportablePanels, UnexportablePanel{ID: id, Title: title, Reason: fmt.Sprintf("unsupported panel type %q", panelType)})
+			continue
+		}
+
+		datasource, targets, err := widgetToGrafanaTargets(widget)
+		if err != nil {
+			report.UnexportablePanels = append(report.UnexportablePanels, UnexportablePanel{ID: id, Title: title, Reason: err.Error()})
+			continue
+		}
+
+		unit, _ := widget["yAxisUnit"].(string)
+		if unit == "" {
+			unit = "short"
+		}
+
+		panel["type"] = grafanaType
+		panel["datasource"] = datasource
+		panel["targets"] = targets
+		panel["fieldConfig"] = map[string]interface{}{
+			"defaults":  map[string]interface{}{"unit": unit},
+			"overrides": []interface{}{},
+		}
+		panels = append(panels, panel)
+		report.ExportedPanels++
+	}
+
+	tags := []interface{}{}
+	if t, ok := dashboard.Data["tags"].([]interface{}); ok {
+		tags = t
+	}
+
+	return map[string]interface{}{
+		"uid":           dashboard.Uuid,
+		"title":         extractDashboardName(dashboard.Data),
+		"description":   dashboard.Data["description"],
+		"tags":          tags,
+		"editable":      true,
+		"schemaVersion": 39,
+		"time":          map[string]interface{}{"from": "now-6h", "to": "now"},
+		"panels":        panels,
+		"templating":    map[string]interface{}{"list": variablesToGrafanaTemplating(dashboard.Data)},
+	}, report
+}
+
+func widgetToGrafanaTargets(widget map[string]interface{}) (map[string]interface{}, []interface{}, error) {
+	query, ok := widget["query"].(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("panel has no query")
+	}
+
+	targets := []interface{}{}
+	switch query["queryType"] {
+	case "promql":
+		queries, _ := query["promql"].([]interface{})
+		for _, q := range queries {
+			promQuery, ok := q.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			expr, _ := promQuery["query"].(string)
+			if disabled, _ := promQuery["disabled"].(bool); disabled || strings.TrimSpace(expr) == "" {
+				continue
+			}
+			targets = append(targets, map[string]interface{}{
+				"refId":        promQuery["name"],
+				"expr":         signozToGrafanaVariables(expr),
+				"legendFormat": promQuery["legend"],
+			})
+		}
+		return map[string]interface{}{"type": "prometheus"}, targets, nil
+
+	case "clickhouse_sql":
+		queries, _ := query["clickhouse_sql"].([]interface{})
+		for _, q := range queries {
+			chQuery, ok := q.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rawSql, _ := chQuery["query"].(string)
+			if disabled, _ := chQuery["disabled"].(bool); disabled || strings.TrimSpace(rawSql) == "" {
+				continue
+			}
+			targets = append(targets, map[string]interface{}{
+				"refId":     chQuery["name"],
+				"rawSql":    signozToGrafanaVariables(rawSql),
+				"format":    "time_series",
+				"queryType": "sql",
+			})
+		}
+		return map[string]interface{}{"type": "grafana-clickhouse-datasource"}, targets, nil
+
+	case "builder":
+		builder, _ := query["builder"].(map[string]interface{})
+		if formulas, _ := builder["queryFormulas"].([]interface{}); len(formulas) > 0 {
+			return nil, nil, fmt.Errorf("builder formulas cannot be exported")
+		}
+		queries, _ := builder["queryData"].([]interface{})
+		for _, q := range queries {
+			raw, err := json.Marshal(q)
+			if err != nil {
+				return nil, nil, err
+			}
+			var builderQuery v3.BuilderQuery
+			if err := json.Unmarshal(raw, &builderQuery); err != nil {
+				return nil, nil, fmt.Errorf("invalid builder query: %w", err)
+			}
+			if builderQuery.Disabled {
+				continue
+			}
+			expr, err := builderQueryToPromQL(&builderQuery)
+			if err != nil {
+				return nil, nil, err
+			}
+			targets = append(targets, map[string]interface{}{
+				"refId":        builderQuery.QueryName,
+				"expr":         expr,
+				"legendFormat": builderQuery.Legend,
+			})
+		}
+		return map[string]interface{}{"type": "prometheus"}, targets, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported query type %v", query["queryType"])
+}
+
+// builderQueryToPromQL translates a metrics builder query into the equivalent
+// PromQL expression
+func builderQueryToPromQL(q *v3.BuilderQuery) (string, error) {
+	if q.DataSource != v3.DataSourceMetrics {
+		return "", fmt.Errorf("only metrics builder queries can be exported, found %s", q.DataSource)
+	}
+	if len(q.Functions) > 0 || len(q.Having) > 0 {
+		return "", fmt.Errorf("builder functions and having clauses cannot be exported")
+	}
+
+	matchers := []string{}
+	if q.Filters != nil {
+		for _, item := range q.Filters.Items {
+			matcher, err := filterItemToPromQLMatcher(item)
+			if err != nil {
+				return "", err
+			}
+			matchers = append(matchers, matcher)
+		}
+	}
+	selector := q.AggregateAttribute.Key
+	if len(matchers) > 0 {
+		selector = fmt.Sprintf("%s{%s}", selector, strings.Join(matchers, ","))
+	}
+
+	timeAggregation, spaceAggregation := q.TimeAggregation, q.SpaceAggregation
+	if timeAggregation == v3.TimeAggregationUnspecified && spaceAggregation == v3.SpaceAggregationUnspecified {
+		var ok bool
+		timeAggregation, spaceAggregation, ok = splitAggregateOperator(q.AggregateOperator)
+		if !ok {
+			return "", fmt.Errorf("aggregate operator %q cannot be exported", q.AggregateOperator)
+		}
+	}
+
+	var expr string
+	switch timeAggregation {
+	case v3.TimeAggregationUnspecified, v3.TimeAggregationAnyLast:
+		expr = selector
+	case v3.TimeAggregationRate, v3.TimeAggregationIncrease:
+		expr = fmt.Sprintf("%s(%s[$__rate_interval])", timeAggregation, selector)
+	case v3.TimeAggregationSum, v3.TimeAggregationAvg, v3.TimeAggregationMin, v3.TimeAggregationMax, v3.TimeAggregationCount:
+		expr = fmt.Sprintf("%s_over_time(%s[$__interval])", timeAggregation, selector)
+	default:
+		return "", fmt.Errorf("time aggregation %q cannot be exported", timeAggregation)
+	}
+
+	switch spaceAggregation {
+	case v3.SpaceAggregationUnspecified:
+	case v3.SpaceAggregationSum, v3.SpaceAggregationAvg, v3.SpaceAggregationMin, v3.SpaceAggregationMax, v3.SpaceAggregationCount:
+		groupBy := []string{}
+		for _, key := range q.GroupBy {
+			groupBy = append(groupBy, key.Key)
+		}
+		if len(groupBy) == 0 {
+			expr = fmt.Sprintf("%s(%s)", spaceAggregation, expr)
+		} else {
+			expr = fmt.Sprintf("%s by (%s) (%s)", spaceAggregation, strings.Join(groupBy, ", "), expr)
+		}
+	default:
+		return "", fmt.Errorf("space aggregation %q cannot be exported", spaceAggregation)
+	}
+
+	return expr, nil
+}
+
+// splitAggregateOperator maps the legacy aggregate operators to their time and
+// space aggregations
+func splitAggregateOperator(op v3.AggregateOperator) (v3.TimeAggregation, v3.SpaceAggregation, bool) {
+	switch op {
+	case v3.AggregateOperatorNoOp, "":
+		return v3.TimeAggregationUnspecified, v3.SpaceAggregationUnspecified, true
+	case v3.AggregateOperatorRate:
+		return v3.TimeAggregationRate, v3.SpaceAggregationUnspecified, true
+	case v3.AggregateOperatorSumRate:
+		return v3.TimeAggregationRate, v3.SpaceAggregationSum, true
+	case v3.AggregateOperatorAvgRate:
+		return v3.TimeAggregationRate, v3.SpaceAggregationAvg, true
+	case v3.AggregateOperatorMinRate:
+		return v3.TimeAggregationRate, v3.SpaceAggregationMin, true
+	case v3.AggregateOperatorMaxRate:
+		return v3.TimeAggregationRate, v3.SpaceAggregationMax, true
+	case v3.AggregateOperatorSum:
+		return v3.TimeAggregationUnspecified, v3.SpaceAggregationSum, true
+	case v3.AggregateOperatorAvg:
+		return v3.TimeAggregationUnspecified, v3.SpaceAggregationAvg, true
+	case v3.AggregateOperatorMin:
+		return v3.TimeAggregationUnspecified, v3.SpaceAggregationMin, true
+	case v3.AggregateOperatorMax:
+		return v3.TimeAggregationUnspecified, v3.SpaceAggregationMax, true
+	case v3.AggregateOperatorCount:
+		return v3.TimeAggregationUnspecified, v3.SpaceAggregationCount, true
+	}
+	return v3.TimeAggregationUnspecified, v3.SpaceAggregationUnspecified, false
+}
+
+func filterItemToPromQLMatcher(item v3.FilterItem) (string, error) {
+	values := []string{}
+	switch v := item.Value.(type) {
+	case []interface{}:
+		for _, value := range v {
+			values = append(values, fmt.Sprintf("%v", value))
+		}
+	default:
+		values = append(values, fmt.Sprintf("%v", v))
+	}
+	for i := range values {
+		values[i] = signozToGrafanaVariables(values[i])
+	}
+
+	var op, value string
+	switch item.Operator {
+	case v3.FilterOperatorEqual:
+		op, value = "=", values[0]
+	case v3.FilterOperatorNotEqual:
+		op, value = "!=", values[0]
+	case v3.FilterOperatorRegex:
+		op, value = "=~", values[0]
+	case v3.FilterOperatorNotRegex:
+		op, value = "!~", values[0]
+	case v3.FilterOperatorIn:
+		op, value = "=~", strings.Join(values, "|")
+	case v3.FilterOperatorNotIn:
+		op, value = "!~", strings.Join(values, "|")
+	default:
+		return "", fmt.Errorf("filter operator %q cannot be exported", item.Operator)
+	}
+
+	return fmt.Sprintf("%s%s%q", item.Key.Key, op, value), nil
+}
+
+// signozToGrafanaVariables rewrites signoz template variables into grafana ones
+func signozToGrafanaVariables(query string) string {
+	return signozVariableRE.ReplaceAllStringFunc(query, func(match string) string {
+		name := signozVariableRE.FindStringSubmatch(match)[1]
+		if value, ok := signozTimeVariables[name]; ok {
+			return value
+		}
+		return fmt.Sprintf("${%s}", name)
+	})
+}
+
+func variablesToGrafanaTemplating(data Data) []interface{} {
+	variables, _ := data["variables"].(map[string]interface{})
+
+	list := []map[string]interface{}{}
+	for _, v := range variables {
+		variable, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := variable["name"].(string)
+		multi, _ := variable["multiSelect"].(bool)
+		includeAll, _ := variable["showALLOption"].(bool)
+		order, _ := variable["order"].(float64)
+
+		template := map[string]interface{}{
+			"name":        name,
+			"label":       variable["description"],
+			"multi":       multi,
+			"includeAll":  includeAll,
+			"hide":        0,
+			"skipUrlSync": false,
+			"order":       order,
+		}
+		switch variable["type"] {
+		case "CUSTOM":
+			template["type"] = "custom"
+			template["query"] = variable["customValue"]
+		case "TEXTBOX":
+			template["type"] = "textbox"
+			template["query"] = variable["textboxValue"]
+		case "INTERVAL":
+			template["type"] = "interval"
+			template["query"] = variable["intervalValue"]
+		case "CONSTANT":
+			template["type"] = "constant"
+			template["query"] = variable["constantValue"]
+		default:
+			// query variables run ClickHouse queries which grafana can only
+			// run against the clickhouse datasource
+			template["type"] = "query"
+			template["datasource"] = map[string]interface{}{"type": "grafana-clickhouse-datasource"}
+			template["query"] = signozToGrafanaVariables(fmt.Sprintf("%v", variable["queryValue"]))
+		}
+		list = append(list, template)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i]["order"].(float64) < list[j]["order"].(float64)
+	})
+
+	result := []interface{}{}
+	for _, template := range list {
+		delete(template, "order")
+		result = append(result, template)
+	}
+	return result
+}
+
+func signozToGrafanaGridPos(layout map[string]interface{}) map[string]interface{} {
+	value := func(key string) int {
+		v, _ := layout[key].(float64)
+		return int(v)
+	}
+	return map[string]interface{}{
+		"x": value("x") * grafanaGridColumns / signozGridColumns,
+		"y": value("y") * 8 / 3,
+		"w": value("w") * grafanaGridColumns / signozGridColumns,
+		"h": value("h") * 8 / 3,
+	}
+}
@@ -0,0 +1,143 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+const signozDashboardJSON = `{
+	"title": "Hosts",
+	"tags": ["infra"],
+	"variables": {
+		"a": {"name": "host", "type": "CUSTOM", "customValue": "a,b", "multiSelect": true, "order": 1},
+		"b": {"name": "env", "type": "TEXTBOX", "textboxValue": "prod", "order": 0}
+	},
+	"layout": [
+		{"i": "cpu", "x": 0, "y": 0, "w": 6, "h": 3},
+		{"i": "logs", "x": 6, "y": 0, "w": 6, "h": 3}
+	],
+	"widgets": [
+		{
+			"id": "cpu", "title": "CPU", "panelTypes": "graph", "yAxisUnit": "percent",
+			"query": {"queryType": "builder", "builder": {"queryFormulas": [], "queryData": [{
+				"queryName": "A", "dataSource": "metrics", "legend": "{{host}}",
+				"aggregateAttribute": {"key": "system_cpu_time"},
+				"timeAggregation": "rate", "spaceAggregation": "sum",
+				"filters": {"op": "AND", "items": [{"key": {"key": "host"}, "op": "in", "value": ["{{.host}}", "b"]}]},
+				"groupBy": [{"key": "host"}]
+			}]}}
+		},
+		{
+			"id": "logs", "title": "Logs", "panelTypes": "table",
+			"query": {"queryType": "builder", "builder": {"queryFormulas": [], "queryData": [{
+				"queryName": "A", "dataSource": "logs", "aggregateOperator": "count"
+			}]}}
+		},
+		{
+			"id": "ch", "title": "Requests", "panelTypes": "value",
+			"query": {"queryType": "clickhouse_sql", "clickhouse_sql": [{
+				"name": "A", "query": "SELECT count() FROM t WHERE ts BETWEEN {{.start_timestamp_ms}} AND {{.end_timestamp_ms}} AND env = {{.env}}"
+			}]}
+		}
+	]
+}`
+
+func TestExportGrafanaDashboard(t *testing.T) {
+	require := require.New(t)
+
+	dashboard := &Dashboard{Uuid: "abc"}
+	require.NoError(json.Unmarshal([]byte(signozDashboardJSON), &dashboard.Data))
+
+	grafanaJSON, report := ExportGrafanaDashboard(dashboard)
+	require.Equal("Hosts", grafanaJSON["title"])
+	require.Equal("abc", grafanaJSON["uid"])
+
+	require.Equal(2, report.ExportedPanels)
+	require.Len(report.UnexportablePanels, 1)
+	require.Equal("logs", report.UnexportablePanels[0].ID)
+
+	panels := grafanaJSON["panels"].([]interface{})
+	require.Len(panels, 2)
+
+	cpu := panels[0].(map[string]interface{})
+	require.Equal("timeseries", cpu["type"])
+	require.Equal(map[string]interface{}{"x": 0, "y": 0, "w": 12, "h": 8}, cpu["gridPos"])
+	target := cpu["targets"].([]interface{})[0].(map[string]interface{})
+	require.Equal(`sum by (host) (rate(system_cpu_time{host=~"${host}|b"}[$__rate_interval]))`, target["expr"])
+
+	ch := panels[1].(map[string]interface{})
+	require.Equal("stat", ch["type"])
+	target = ch["targets"].([]interface{})[0].(map[string]interface{})
+	require.Equal("SELECT count() FROM t WHERE ts BETWEEN ${__from} AND ${__to} AND env = ${env}", target["rawSql"])
+
+	templating := grafanaJSON["templating"].(map[string]interface{})["list"].([]interface{})
+	require.Len(templating, 2)
+	require.Equal("env", templating[0].(map[string]interface{})["name"])
+	require.Equal("custom", templating[1].(map[string]interface{})["type"])
+}
+
+func TestBuilderQueryToPromQL(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   v3.BuilderQuery
+		expr    string
+		wantErr bool
+	}{
+		{
+			name: "legacy sum rate",
+			query: v3.BuilderQuery{
+				DataSource:         v3.DataSourceMetrics,
+				AggregateAttribute: v3.AttributeKey{Key: "http_requests_total"},
+				AggregateOperator:  v3.AggregateOperatorSumRate,
+			},
+			expr: "sum(rate(http_requests_total[$__rate_interval]))",
+		},
+		{
+			name: "avg over time",
+			query: v3.BuilderQuery{
+				DataSource:         v3.DataSourceMetrics,
+				AggregateAttribute: v3.AttributeKey{Key: "memory"},
+				TimeAggregation:    v3.TimeAggregationAvg,
+				Filters: &v3.FilterSet{Items: []v3.FilterItem{
+					{Key: v3.AttributeKey{Key: "env"}, Operator: v3.FilterOperatorNotEqual, Value: "dev"},
+				}},
+			},
+			expr: `avg_over_time(memory{env!="dev"}[$__interval])`,
+		},
+		{
+			name: "unsupported filter",
+			query: v3.BuilderQuery{
+				DataSource:         v3.DataSourceMetrics,
+				AggregateAttribute: v3.AttributeKey{Key: "memory"},
+				Filters: &v3.FilterSet{Items: []v3.FilterItem{
+					{Key: v3.AttributeKey{Key: "env"}, Operator: v3.FilterOperatorContains, Value: "dev"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "percentile",
+			query: v3.BuilderQuery{
+				DataSource:         v3.DataSourceMetrics,
+				AggregateAttribute: v3.AttributeKey{Key: "latency"},
+				AggregateOperator:  v3.AggregateOperatorP99,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := builderQueryToPromQL(&c.query)
+			if c.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expr, expr)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+const grafanaDashboardJSON = `{
+	"title": "Node Exporter",
+	"uid": "rYdddlPWk",
+	"tags": ["linux"],
+	"templating": {
+		"list": [
+			{"name": "job", "type": "query", "query": {"query": "label_values(node_uname_info, job)"}, "sort": 1},
+			{"name": "instance", "type": "query", "query": "query_result(up)"},
+			{"name": "ds", "type": "datasource", "query": "prometheus"},
+			{"name": "quantile", "type": "custom", "query": "0.5,0.9,0.99", "multi": true}
+		]
+	},
+	"panels": [
+		{"id": 1, "type": "row", "title": "CPU", "collapsed": false, "gridPos": {"x": 0, "y": 0, "w": 24, "h": 1}},
+		{
+			"id": 2, "type": "timeseries", "title": "CPU busy", "datasource": {"type": "prometheus", "uid": "abc"},
+			"gridPos": {"x": 0, "y": 1, "w": 12, "h": 8},
+			"fieldConfig": {"defaults": {"unit": "percent"}},
+			"targets": [{"refId": "A", "legendFormat": "{{cpu}}", "expr": "rate(node_cpu_seconds_total{job=\"$job\",instance=\"$instance\"}[$__rate_interval])"}]
+		},
+		{"id": 3, "type": "text", "title": "Notes", "gridPos": {"x": 12, "y": 1, "w": 12, "h": 8}},
+		{
+			"id": 4, "type": "row", "title": "Logs", "collapsed": true, "gridPos": {"x": 0, "y": 9, "w": 24, "h": 1},
+			"panels": [
+				{"id": 5, "type": "timeseries", "title": "Log volume", "datasource": {"type": "loki"}, "targets": [{"refId": "A", "expr": "count_over_time({job=\"x\"}[1m])"}]}
+			]
+		}
+	]
+}`
+
+func TestConvertGrafanaDashboard(t *testing.T) {
+	require := require.New(t)
+
+	var grafanaJSON model.GrafanaJSON
+	require.NoError(json.Unmarshal([]byte(grafanaDashboardJSON), &grafanaJSON))
+
+	data, report := ConvertGrafanaDashboard(grafanaJSON)
+	require.Equal("Node Exporter", data["title"])
+
+	require.Equal(1, report.ConvertedPanels)
+	require.Len(report.UnconvertiblePanels, 2)
+	require.Equal(3, report.UnconvertiblePanels[0].ID)
+	require.Equal(5, report.UnconvertiblePanels[1].ID)
+
+	require.Len(report.SkippedVariables, 2)
+	require.Equal("instance", report.SkippedVariables[0].Name)
+	require.Equal("ds", report.SkippedVariables[1].Name)
+	require.Len(data["variables"], 2)
+
+	// two rows and the converted panel
+	widgets := data["widgets"].([]interface{})
+	require.Len(widgets, 3)
+	require.Equal("row", widgets[0].(map[string]interface{})["panelTypes"])
+
+	panel := widgets[1].(map[string]interface{})
+	require.Equal("graph", panel["panelTypes"])
+	require.Equal("percent", panel["yAxisUnit"])
+	promql := panel["query"].(map[string]interface{})["promql"].([]interface{})
+	require.Equal(
+		`rate(node_cpu_seconds_total{job="{{.job}}",instance!=""}[5m])`,
+		promql[0].(map[string]interface{})["query"],
+	)
+
+	layout := data["layout"].([]interface{})
+	require.Len(layout, 3)
+	item := layout[1].(map[string]interface{})
+	require.Equal(6, item["w"])
+	require.Equal(3, item["h"])
+	// moved down from the row it would overlap once rounded to the signoz grid
+	require.Equal(1, item["y"])
+	require.NoError(ValidateDashboardData(data))
+}
+
+func TestLabelValuesToClickHouse(t *testing.T) {
+	require.Equal(t,
+		"SELECT JSONExtractString(labels, 'job') AS job\nFROM signoz_metrics.distributed_time_series_v4_1day\nWHERE metric_name = 'node_uname_info'\nGROUP BY job",
+		labelValuesToClickHouse(`node_uname_info{env="prod"}`, "job"),
+	)
+	require.Equal(t,
+		"SELECT JSONExtractString(labels, 'job') AS job\nFROM signoz_metrics.distributed_time_series_v4_1day\nGROUP BY job",
+		labelValuesToClickHouse("", "job"),
+	)
+}
@@ -0,0 +1,148 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// HomeRoleAll is the role of the home dashboard of the users whose role has
+// no home dashboard of its own
+const HomeRoleAll = "ALL"
+
+var homeRoles = map[string]bool{
+	constants.AdminGroup:  true,
+	constants.EditorGroup: true,
+	constants.ViewerGroup: true,
+	HomeRoleAll:           true,
+}
+
+// HomeDashboard is the dashboard the users of a role land on
+type HomeDashboard struct {
+	Role          string    `json:"role" db:"role"`
+	DashboardUuid string    `json:"dashboard_uuid" db:"dashboard_uuid"`
+	Title         string    `json:"title" db:"title"`
+	UpdatedBy     string    `json:"updated_by" db:"updated_by"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// homeOrg returns the org the home dashboards are set for and the email of
+// the user
+func homeOrg(ctx context.Context) (string, string) {
+	user := common.GetUserFromContext(ctx)
+	if user == nil {
+		return "", ""
+	}
+	return user.OrgId, user.Email
+}
+
+// GetHomeDashboards returns the home dashboards of the roles of the org
+func GetHomeDashboards(ctx context.Context) ([]HomeDashboard, *model.ApiError) {
+	orgId, _ := homeOrg(ctx)
+
+	homes := []HomeDashboard{}
+	err := db.Select(&homes, `SELECT h.role, h.dashboard_uuid, d.title, h.updated_by, h.updated_at
+		FROM home_dashboards h JOIN dashboards d ON d.uuid = h.dashboard_uuid
+		WHERE h.org_id=? ORDER BY h.role`, orgId)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return homes, nil
+}
+
+// SetHomeDashboard makes the dashboard the home dashboard of the role,
+// replacing the one set before
+func SetHomeDashboard(ctx context.Context, role string, uuid string) (*HomeDashboard, *model.ApiError) {
+	if !homeRoles[role] {
+		return nil, model.BadRequest(fmt.Errorf("invalid role %q, expected one of %s, %s, %s or %s", role, constants.AdminGroup, constants.EditorGroup, constants.ViewerGroup, HomeRoleAll))
+	}
+
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if dashboard.ArchivedAt != nil {
+		return nil, model.BadRequest(fmt.Errorf("dashboard %s is archived, unarchive it to make it a home dashboard", uuid))
+	}
+
+	orgId, userEmail := homeOrg(ctx)
+	home := &HomeDashboard{
+		Role:          role,
+		DashboardUuid: uuid,
+		Title:         dashboard.Title,
+		UpdatedBy:     userEmail,
+		UpdatedAt:     time.Now(),
+	}
+	_, err := db.Exec(`INSERT INTO home_dashboards (org_id, role, dashboard_uuid, updated_by, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(org_id, role) DO UPDATE SET dashboard_uuid=excluded.dashboard_uuid, updated_by=excluded.updated_by, updated_at=excluded.updated_at`,
+		orgId, home.Role, home.DashboardUuid, home.UpdatedBy, home.UpdatedAt)
+	if err != nil {
+		zap.L().Error("Error in setting home dashboard", zap.String("role", role), zap.String("uuid", uuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return home, nil
+}
+
+// RemoveHomeDashboard removes the home dashboard of the role
+func RemoveHomeDashboard(ctx context.Context, role string) *model.ApiError {
+	orgId, _ := homeOrg(ctx)
+
+	result, err := db.Exec(`DELETE FROM home_dashboards WHERE org_id=? AND role=?`, orgId, role)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if affectedRows == 0 {
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no home dashboard set for role %s", role)}
+	}
+	return nil
+}
+
+// ResolveHomeDashboard returns the home dashboard of the user, the one of
+// their role or else the one of all the roles. Home dashboards which have
+// since been deleted or archived are skipped.
+func ResolveHomeDashboard(ctx context.Context) (*Dashboard, *model.ApiError) {
+	user := common.GetUserFromContext(ctx)
+	if user == nil {
+		return nil, &model.ApiError{Typ: model.ErrorUnauthorized, Err: errors.New("the home dashboard requires a logged in user")}
+	}
+
+	uuids := []string{}
+	err := db.Select(&uuids, `SELECT dashboard_uuid FROM home_dashboards WHERE org_id=? AND role IN (?, ?) ORDER BY role = ?`,
+		user.OrgId, user.Role, HomeRoleAll, HomeRoleAll)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	for _, uuid := range uuids {
+		dashboard, apiErr := GetDashboard(ctx, uuid)
+		if apiErr != nil {
+			if apiErr.Type() == model.ErrorNotFound {
+				continue
+			}
+			return nil, apiErr
+		}
+		if dashboard.ArchivedAt == nil {
+			return dashboard, nil
+		}
+	}
+	return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no home dashboard set for role %s", user.Role)}
+}
+
+// pruneHomeDashboards removes the purged dashboards from the home dashboards
+func pruneHomeDashboards() {
+	_, err := db.Exec(`DELETE FROM home_dashboards WHERE dashboard_uuid NOT IN (SELECT uuid FROM dashboards)`)
+	if err != nil {
+		zap.L().Error("Error in removing purged dashboards from the home dashboards", zap.Error(err))
+	}
+}
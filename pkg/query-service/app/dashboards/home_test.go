@@ -0,0 +1,72 @@
+package dashboards_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestHomeDashboards(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	adminCtx := contextWithUser("alice@signoz.io")
+	viewerCtx := context.WithValue(context.Background(), constants.ContextUserKey, &model.UserPayload{
+		User: model.User{Id: "bob@signoz.io", Email: "bob@signoz.io"},
+		Role: constants.ViewerGroup,
+	})
+
+	_, apiErr := dashboards.ResolveHomeDashboard(viewerCtx)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+
+	overview, apiErr := dashboards.CreateDashboard(adminCtx, map[string]interface{}{"title": "overview"}, nil)
+	require.Nil(apiErr)
+	onCall, apiErr := dashboards.CreateDashboard(adminCtx, map[string]interface{}{"title": "on-call"}, nil)
+	require.Nil(apiErr)
+
+	_, apiErr = dashboards.SetHomeDashboard(adminCtx, "OWNER", overview.Uuid)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	_, apiErr = dashboards.SetHomeDashboard(adminCtx, dashboards.HomeRoleAll, overview.Uuid)
+	require.Nil(apiErr)
+	_, apiErr = dashboards.SetHomeDashboard(adminCtx, constants.ViewerGroup, overview.Uuid)
+	require.Nil(apiErr)
+	// setting the home dashboard of a role again replaces it
+	_, apiErr = dashboards.SetHomeDashboard(adminCtx, constants.ViewerGroup, onCall.Uuid)
+	require.Nil(apiErr)
+
+	homes, apiErr := dashboards.GetHomeDashboards(adminCtx)
+	require.Nil(apiErr)
+	require.Len(homes, 2)
+	require.Equal(constants.ViewerGroup, homes[1].Role)
+	require.Equal("on-call", homes[1].Title)
+
+	// the home dashboard of the role comes first, else the one of all roles
+	home, apiErr := dashboards.ResolveHomeDashboard(viewerCtx)
+	require.Nil(apiErr)
+	require.Equal(onCall.Uuid, home.Uuid)
+	home, apiErr = dashboards.ResolveHomeDashboard(adminCtx)
+	require.Nil(apiErr)
+	require.Equal(overview.Uuid, home.Uuid)
+
+	// archived home dashboards are skipped
+	_, apiErr = dashboards.ArchiveDashboard(adminCtx, onCall.Uuid)
+	require.Nil(apiErr)
+	home, apiErr = dashboards.ResolveHomeDashboard(viewerCtx)
+	require.Nil(apiErr)
+	require.Equal(overview.Uuid, home.Uuid)
+
+	require.Nil(dashboards.RemoveHomeDashboard(adminCtx, dashboards.HomeRoleAll))
+	apiErr = dashboards.RemoveHomeDashboard(adminCtx, dashboards.HomeRoleAll)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+	_, apiErr = dashboards.ResolveHomeDashboard(viewerCtx)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+}
@@ -0,0 +1,231 @@
+package dashboards
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// ImportConflictStrategy is what importing a dashboard whose uuid is taken does
+type ImportConflictStrategy string
+
+const (
+	// ImportConflictSkip leaves the existing dashboard as it is
+	ImportConflictSkip ImportConflictStrategy = "skip"
+	// ImportConflictOverwrite replaces the data of the existing dashboard
+	ImportConflictOverwrite ImportConflictStrategy = "overwrite"
+	// ImportConflictDuplicate imports the dashboard with a new uuid
+	ImportConflictDuplicate ImportConflictStrategy = "duplicate"
+)
+
+const maxImportDashboards = 500
+
+type ImportStatus string
+
+const (
+	ImportStatusCreated ImportStatus = "created"
+	ImportStatusUpdated ImportStatus = "updated"
+	ImportStatusSkipped ImportStatus = "skipped"
+	ImportStatusFailed  ImportStatus = "failed"
+)
+
+// ImportItem is a dashboard to import, the name is the file it was read from
+// in a zip archive
+type ImportItem struct {
+	Name string
+	Data map[string]interface{}
+}
+
+// ImportItemResult is the outcome of the import of a single dashboard
+type ImportItemResult struct {
+	Index  int          `json:"index"`
+	Name   string       `json:"name,omitempty"`
+	Title  string       `json:"title"`
+	Uuid   string       `json:"uuid,omitempty"`
+	Status ImportStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// ParseImportJSON reads the dashboards of a json array
+func ParseImportJSON(content []byte) ([]ImportItem, error) {
+	var list []map[string]interface{}
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, fmt.Errorf("expected an array of dashboards: %w", err)
+	}
+
+	items := []ImportItem{}
+	for _, data := range list {
+		items = append(items, ImportItem{Data: data})
+	}
+	return items, nil
+}
+
+// ParseImportZip reads the dashboards of the json files of a zip archive, in
+// the order of their names
+func ParseImportZip(content []byte) ([]ImportItem, error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	files := []*zip.File{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || !strings.EqualFold(path.Ext(file.Name), ".json") || strings.HasPrefix(path.Base(file.Name), ".") {
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) > maxImportDashboards {
+		return nil, fmt.Errorf("at most %d dashboards can be imported at once", maxImportDashboards)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	items := []ImportItem{}
+	for _, file := range files {
+		f, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		// the size of every dashboard is checked on import, reading more than
+		// the limit is pointless
+		fileContent, err := io.ReadAll(io.LimitReader(f, int64(constants.GetDashboardsMaxDataSize())+1))
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+
+		item := ImportItem{Name: file.Name}
+		if err := json.Unmarshal(fileContent, &item.Data); err != nil {
+			// reported as a failure of the item rather than of the import
+			item.Data = nil
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ImportDashboards imports the dashboards in a single transaction. Dashboards
+// whose import fails are reported and rolled back, the others are committed
+// together. Dashboards with the uuid of an existing dashboard are skipped,
+// overwritten or given a new uuid depending on the strategy.
+func ImportDashboards(ctx context.Context, items []ImportItem, strategy ImportConflictStrategy) ([]ImportItemResult, *model.ApiError) {
+	switch strategy {
+	case "":
+		strategy = ImportConflictSkip
+	case ImportConflictSkip, ImportConflictOverwrite, ImportConflictDuplicate:
+	default:
+		return nil, model.BadRequest(fmt.Errorf("unsupported conflict strategy: %s", strategy))
+	}
+	if len(items) == 0 {
+		return nil, model.BadRequest(fmt.Errorf("no dashboards to import"))
+	}
+	if len(items) > maxImportDashboards {
+		return nil, model.BadRequest(fmt.Errorf("at most %d dashboards can be imported at once", maxImportDashboards))
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	defer tx.Rollback()
+
+	results := []ImportItemResult{}
+	saved := []*Dashboard{}
+	for i, item := range items {
+		result := ImportItemResult{Index: i, Name: item.Name, Title: extractDashboardName(item.Data)}
+
+		// every dashboard is imported in a savepoint, so a failure rolls back
+		// its partial changes only
+		if _, err := tx.Exec(`SAVEPOINT import_dashboard`); err != nil {
+			return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+		}
+		dashboard, status, err := importDashboard(ctx, tx, item.Data, strategy)
+		if err != nil {
+			if _, rollbackErr := tx.Exec(`ROLLBACK TO SAVEPOINT import_dashboard`); rollbackErr != nil {
+				return nil, &model.ApiError{Typ: model.ErrorExec, Err: rollbackErr}
+			}
+			status = ImportStatusFailed
+			result.Error = err.Error()
+		}
+		if _, err := tx.Exec(`RELEASE SAVEPOINT import_dashboard`); err != nil {
+			return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+		}
+
+		result.Status = status
+		if dashboard != nil {
+			result.Uuid = dashboard.Uuid
+			if status != ImportStatusSkipped {
+				saved = append(saved, dashboard)
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		zap.L().Error("Error in committing dashboard import", zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	for _, dashboard := range saved {
+		action := AuditActionCreate
+		if dashboard.Version > 1 {
+			action = AuditActionUpdate
+		}
+		notifyWebhooks(ctx, dashboard.Uuid, action, dashboard.Version)
+	}
+
+	return results, nil
+}
+
+func importDashboard(ctx context.Context, tx *storeTx, data map[string]interface{}, strategy ImportConflictStrategy) (*Dashboard, ImportStatus, error) {
+	if data == nil {
+		return nil, "", fmt.Errorf("not a dashboard json object")
+	}
+	if err := IsPostDataSane(&data); err != nil {
+		return nil, "", err
+	}
+
+	if data["uuid"] != nil {
+		id, err := dashboardUuid(data)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if apiErr := checkUuidAvailable(tx, id); apiErr != nil {
+			if apiErr.Type() != model.ErrorConflict {
+				return nil, "", apiErr.Err
+			}
+
+			switch strategy {
+			case ImportConflictSkip:
+				return &Dashboard{Uuid: id}, ImportStatusSkipped, nil
+			case ImportConflictOverwrite:
+				// the imported data is the source of truth, panels it does
+				// not have are removed without confirmation
+				dashboard, apiErr := updateDashboard(ctx, tx, id, data, true, 0)
+				if apiErr != nil {
+					return nil, "", apiErr.Err
+				}
+				return dashboard, ImportStatusUpdated, nil
+			case ImportConflictDuplicate:
+				delete(data, "uuid")
+			}
+		}
+	}
+
+	dashboard, apiErr := createDashboard(ctx, tx, data)
+	if apiErr != nil {
+		return nil, "", apiErr.Err
+	}
+	return dashboard, ImportStatusCreated, nil
+}
@@ -0,0 +1,94 @@
+package dashboards_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestImportDashboards(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	existing, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "checkout", "uuid": "5d0e8f3a-1c2b-4a6d-9e7f-0b1c2d3e4f5a",
+	}, nil)
+	require.Nil(apiErr)
+
+	items := func() []dashboards.ImportItem {
+		items, err := dashboards.ParseImportJSON([]byte(`[
+			{"title": "checkout v2", "uuid": "5d0e8f3a-1c2b-4a6d-9e7f-0b1c2d3e4f5a"},
+			{"title": "payments"},
+			{"uuid": "not-a-uuid"}
+		]`))
+		require.NoError(err)
+		return items
+	}
+
+	_, apiErr = dashboards.ImportDashboards(ctx, items(), "merge")
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	results, apiErr := dashboards.ImportDashboards(ctx, items(), dashboards.ImportConflictSkip)
+	require.Nil(apiErr)
+	require.Len(results, 3)
+	require.Equal(dashboards.ImportStatusSkipped, results[0].Status)
+	require.Equal(dashboards.ImportStatusCreated, results[1].Status)
+	require.Equal(dashboards.ImportStatusFailed, results[2].Status)
+	require.NotEmpty(results[2].Error)
+
+	results, apiErr = dashboards.ImportDashboards(ctx, items()[:1], dashboards.ImportConflictOverwrite)
+	require.Nil(apiErr)
+	require.Equal(dashboards.ImportStatusUpdated, results[0].Status)
+	updated, apiErr := dashboards.GetDashboard(ctx, existing.Uuid)
+	require.Nil(apiErr)
+	require.Equal("checkout v2", updated.Title)
+
+	results, apiErr = dashboards.ImportDashboards(ctx, items()[:1], dashboards.ImportConflictDuplicate)
+	require.Nil(apiErr)
+	require.Equal(dashboards.ImportStatusCreated, results[0].Status)
+	require.NotEqual(existing.Uuid, results[0].Uuid)
+
+	all, apiErr := dashboards.GetDashboards(ctx)
+	require.Nil(apiErr)
+	require.Len(all, 3)
+}
+
+func TestParseImportZip(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"b/payments.json":  `{"title": "payments"}`,
+		"a/checkout.json":  `{"title": "checkout"}`,
+		"a/broken.json":    `{"title":`,
+		"README.md":        `# dashboards`,
+		"a/.hidden.json":   `{}`,
+		"a/nested/ok.JSON": `{"title": "nested"}`,
+	} {
+		f, err := archive.Create(name)
+		require.NoError(err)
+		_, err = f.Write([]byte(content))
+		require.NoError(err)
+	}
+	require.NoError(archive.Close())
+
+	items, err := dashboards.ParseImportZip(buf.Bytes())
+	require.NoError(err)
+	require.Len(items, 4)
+	require.Equal("a/broken.json", items[0].Name)
+	require.Nil(items[0].Data)
+	require.Equal("checkout", items[1].Data["title"])
+	require.Equal("a/nested/ok.JSON", items[2].Name)
+	require.Equal("b/payments.json", items[3].Name)
+
+	_, err = dashboards.ParseImportZip([]byte("not a zip"))
+	require.Error(err)
+}
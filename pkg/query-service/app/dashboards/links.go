@@ -0,0 +1,104 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+// linkPlaceholder matches {{label}} and {{$variable}} in link templates
+var linkPlaceholder = regexp.MustCompile(`\{\{\s*(\$?[^{}\s]+)\s*\}\}`)
+
+func validateLinkTemplate(template string) error {
+	rest := linkPlaceholder.ReplaceAllString(template, "")
+	if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		return fmt.Errorf("invalid placeholder in %q", template)
+	}
+	return nil
+}
+
+// renderLinkTemplate replaces the placeholders with the labels of the series
+// and the values of the dashboard variables, unknown ones are left empty
+func renderLinkTemplate(template string, series, variables map[string]string) string {
+	return linkPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := linkPlaceholder.FindStringSubmatch(placeholder)[1]
+		if strings.HasPrefix(name, "$") {
+			return variables[strings.TrimPrefix(name, "$")]
+		}
+		return series[name]
+	})
+}
+
+// LinkResolveRequest is the context of a click on a panel, the labels of the
+// clicked series and the current values of the dashboard variables
+type LinkResolveRequest struct {
+	Series    map[string]string `json:"series"`
+	Variables map[string]string `json:"variables"`
+}
+
+// ResolvedLink is a link of a widget with its templates resolved
+type ResolvedLink struct {
+	Title           string            `json:"title"`
+	TargetDashboard string            `json:"targetDashboard,omitempty"`
+	TargetExplorer  string            `json:"targetExplorer,omitempty"`
+	Variables       map[string]string `json:"variables,omitempty"`
+	Filters         map[string]string `json:"filters,omitempty"`
+}
+
+// ResolveWidgetLinks resolves the links of the widget for a click on a series.
+// Links to dashboards which no longer exist are left out.
+func ResolveWidgetLinks(ctx context.Context, uuid string, widgetId string, req LinkResolveRequest) ([]ResolvedLink, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	schema, err := ParseDashboardSchema(dashboard.Data)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+
+	var widget *Widget
+	for i := range schema.Widgets {
+		if schema.Widgets[i].Id == widgetId {
+			widget = &schema.Widgets[i]
+			break
+		}
+	}
+	if widget == nil {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no widget found with id: %s", widgetId)}
+	}
+
+	links := []ResolvedLink{}
+	for _, link := range widget.Links {
+		if link.TargetDashboard != "" {
+			if _, apiErr := GetDashboard(ctx, link.TargetDashboard); apiErr != nil {
+				continue
+			}
+		}
+
+		resolved := ResolvedLink{
+			Title:           link.Title,
+			TargetDashboard: link.TargetDashboard,
+			TargetExplorer:  link.TargetExplorer,
+		}
+		if len(link.Variables) > 0 {
+			resolved.Variables = map[string]string{}
+			for name, template := range link.Variables {
+				resolved.Variables[name] = renderLinkTemplate(template, req.Series, req.Variables)
+			}
+		}
+		if len(link.Filters) > 0 {
+			resolved.Filters = map[string]string{}
+			for key, template := range link.Filters {
+				resolved.Filters[key] = renderLinkTemplate(template, req.Series, req.Variables)
+			}
+		}
+		links = append(links, resolved)
+	}
+
+	return links, nil
+}
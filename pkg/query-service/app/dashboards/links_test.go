@@ -0,0 +1,76 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestResolveWidgetLinks(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	target, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "service"}, nil)
+	require.Nil(apiErr)
+
+	links := []interface{}{
+		map[string]interface{}{
+			"title":           "Service details",
+			"targetDashboard": target.Uuid,
+			"variables":       map[string]interface{}{"service": "{{service.name}}", "env": "{{$env}}"},
+		},
+		map[string]interface{}{
+			"title":          "Traces",
+			"targetExplorer": "traces",
+			"filters":        map[string]interface{}{"serviceName": "{{ service.name }}", "deployment.environment": "{{$env}}"},
+		},
+		map[string]interface{}{
+			"title":           "Deleted",
+			"targetDashboard": "does-not-exist",
+		},
+	}
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title":   "services",
+		"widgets": []interface{}{map[string]interface{}{"id": "latency", "links": links}},
+	}, nil)
+	require.Nil(apiErr)
+
+	resolved, apiErr := dashboards.ResolveWidgetLinks(ctx, dashboard.Uuid, "latency", dashboards.LinkResolveRequest{
+		Series:    map[string]string{"service.name": "frontend"},
+		Variables: map[string]string{"env": "prod"},
+	})
+	require.Nil(apiErr)
+	require.Equal([]dashboards.ResolvedLink{
+		{
+			Title:           "Service details",
+			TargetDashboard: target.Uuid,
+			Variables:       map[string]string{"service": "frontend", "env": "prod"},
+		},
+		{
+			Title:          "Traces",
+			TargetExplorer: "traces",
+			Filters:        map[string]string{"serviceName": "frontend", "deployment.environment": "prod"},
+		},
+	}, resolved)
+
+	_, apiErr = dashboards.ResolveWidgetLinks(ctx, dashboard.Uuid, "errors", dashboards.LinkResolveRequest{})
+	require.NotNil(apiErr)
+
+	// invalid links are rejected when the dashboard is saved
+	for _, link := range []map[string]interface{}{
+		{"title": "no target"},
+		{"title": "both", "targetDashboard": target.Uuid, "targetExplorer": "logs"},
+		{"title": "unknown", "targetExplorer": "profiles"},
+		{"title": "variables", "targetExplorer": "logs", "variables": map[string]interface{}{"env": "prod"}},
+		{"title": "template", "targetExplorer": "logs", "filters": map[string]interface{}{"service": "{{service"}},
+	} {
+		_, apiErr = dashboards.CreateDashboard(ctx, map[string]interface{}{
+			"title":   "invalid",
+			"widgets": []interface{}{map[string]interface{}{"id": "a", "links": []interface{}{link}}},
+		}, nil)
+		require.NotNil(apiErr, link["title"])
+	}
+}
@@ -0,0 +1,201 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// MetricsExistFunc returns which of the metrics have data
+type MetricsExistFunc func(ctx context.Context, metricNames []string) (map[string]bool, error)
+
+const (
+	LintRuleUnknownMetric     = "unknown-metric"
+	LintRuleDeprecatedTable   = "deprecated-table"
+	LintRuleAttributeContains = "attribute-contains"
+)
+
+// LintWarning is an issue found in the queries of a widget, which does not
+// keep the dashboard from being saved
+type LintWarning struct {
+	WidgetId    string `json:"widget_id"`
+	WidgetTitle string `json:"widget_title"`
+	Query       string `json:"query,omitempty"`
+	Rule        string `json:"rule"`
+	Message     string `json:"message"`
+}
+
+// LintReport holds the schema errors, which keep the dashboard from being
+// saved, and the warnings about its panel queries
+type LintReport struct {
+	Errors   []FieldError  `json:"errors"`
+	Warnings []LintWarning `json:"warnings"`
+}
+
+// deprecatedTable matches a ClickHouse table which has been replaced
+type deprecatedTable struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+var deprecatedTables = []deprecatedTable{
+	{regexp.MustCompile(`\b(distributed_)?signoz_index_v2\b`), "signoz_traces.distributed_signoz_index_v3"},
+	{regexp.MustCompile(`\b(distributed_)?signoz_spans\b`), "signoz_traces.distributed_signoz_index_v3"},
+	{regexp.MustCompile(`\b(distributed_)?signoz_error_index_v2\b`), "signoz_traces.distributed_signoz_index_v3"},
+	{regexp.MustCompile(`\bsignoz_logs\.(distributed_)?logs\b`), "signoz_logs.distributed_logs_v2"},
+	{regexp.MustCompile(`\b(distributed_)?time_series_v2\b`), "signoz_metrics.distributed_time_series_v4"},
+}
+
+// containsOperators are the filter operators which match a substring, they
+// scan every value instead of using the index of the attribute
+var containsOperators = map[string]bool{"contains": true, "ncontains": true, "like": true, "nlike": true}
+
+// LintDashboard checks the dashboard data against the schema and looks for
+// known issues in the queries of its widgets: metrics without data,
+// deprecated ClickHouse tables and substring filters on log attributes. The
+// metrics are only checked if metricsExist is given.
+func LintDashboard(ctx context.Context, data map[string]interface{}, metricsExist MetricsExistFunc) *LintReport {
+	report := &LintReport{Errors: []FieldError{}, Warnings: []LintWarning{}}
+
+	var validationErr *SchemaValidationError
+	if err := ValidateDashboardData(data); errors.As(err, &validationErr) {
+		report.Errors = validationErr.Errors
+	} else if err != nil {
+		report.Errors = append(report.Errors, FieldError{Field: "", Message: err.Error()})
+	}
+
+	widgets, _ := data["widgets"].([]interface{})
+	for _, w := range widgets {
+		widget, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		widgetId, _ := widget["id"].(string)
+		widgetTitle, _ := widget["title"].(string)
+		query, _ := widget["query"].(map[string]interface{})
+
+		report.Warnings = append(report.Warnings, lintClickHouseQueries(widgetId, widgetTitle, query)...)
+		report.Warnings = append(report.Warnings, lintBuilderFilters(widgetId, widgetTitle, query)...)
+	}
+
+	if metricsExist != nil {
+		rows := dashboardMetrics("", data)
+		names := []string{}
+		seen := map[string]bool{}
+		for _, row := range rows {
+			if !seen[row.MetricName] {
+				seen[row.MetricName] = true
+				names = append(names, row.MetricName)
+			}
+		}
+
+		if len(names) > 0 {
+			exist, err := metricsExist(ctx, names)
+			if err != nil {
+				zap.L().Error("Error in checking the metrics of the dashboard", zap.Error(err))
+			} else {
+				for _, row := range rows {
+					if exist[row.MetricName] {
+						continue
+					}
+					report.Warnings = append(report.Warnings, LintWarning{
+						WidgetId:    row.WidgetId,
+						WidgetTitle: row.WidgetTitle,
+						Rule:        LintRuleUnknownMetric,
+						Message:     fmt.Sprintf("no data has been received for metric %q", row.MetricName),
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(report.Warnings, func(i, j int) bool {
+		return report.Warnings[i].WidgetId < report.Warnings[j].WidgetId
+	})
+	return report
+}
+
+func lintClickHouseQueries(widgetId, widgetTitle string, query map[string]interface{}) []LintWarning {
+	warnings := []LintWarning{}
+	if query["queryType"] != "clickhouse_sql" {
+		return warnings
+	}
+
+	queries, _ := query["clickhouse_sql"].([]interface{})
+	for _, q := range queries {
+		chQuery, ok := q.(map[string]interface{})
+		if !ok || chQuery["disabled"] == true {
+			continue
+		}
+		sql, _ := chQuery["query"].(string)
+		name, _ := chQuery["name"].(string)
+		for _, table := range deprecatedTables {
+			for _, match := range uniqueMatches(table.pattern, sql) {
+				warnings = append(warnings, LintWarning{
+					WidgetId:    widgetId,
+					WidgetTitle: widgetTitle,
+					Query:       name,
+					Rule:        LintRuleDeprecatedTable,
+					Message:     fmt.Sprintf("table %s is deprecated, query %s instead", match, table.replacement),
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+func uniqueMatches(pattern *regexp.Regexp, s string) []string {
+	matches := []string{}
+	seen := map[string]bool{}
+	for _, match := range pattern.FindAllString(s, -1) {
+		if !seen[match] {
+			seen[match] = true
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+func lintBuilderFilters(widgetId, widgetTitle string, query map[string]interface{}) []LintWarning {
+	warnings := []LintWarning{}
+	if query["queryType"] != "builder" {
+		return warnings
+	}
+
+	builder, _ := query["builder"].(map[string]interface{})
+	queryData, _ := builder["queryData"].([]interface{})
+	for _, qd := range queryData {
+		data, ok := qd.(map[string]interface{})
+		if !ok || data["dataSource"] != "logs" || data["disabled"] == true {
+			continue
+		}
+		name, _ := data["queryName"].(string)
+		filters, _ := data["filters"].(map[string]interface{})
+		items, _ := filters["items"].([]interface{})
+		for _, i := range items {
+			item, ok := i.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op, _ := item["op"].(string)
+			key, _ := item["key"].(map[string]interface{})
+			attribute, _ := key["key"].(string)
+			if !containsOperators[strings.ToLower(op)] || attribute == "" || attribute == "body" {
+				continue
+			}
+			warnings = append(warnings, LintWarning{
+				WidgetId:    widgetId,
+				WidgetTitle: widgetTitle,
+				Query:       name,
+				Rule:        LintRuleAttributeContains,
+				Message:     fmt.Sprintf("%s on attribute %q scans every log, filter with = or in instead", op, attribute),
+			})
+		}
+	}
+	return warnings
+}
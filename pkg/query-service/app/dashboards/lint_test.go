@@ -0,0 +1,93 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintDashboard(t *testing.T) {
+	require := require.New(t)
+
+	data := map[string]interface{}{
+		"title": "services",
+		"widgets": []interface{}{
+			map[string]interface{}{
+				"id":    "latency",
+				"title": "Latency",
+				"query": map[string]interface{}{
+					"queryType": "clickhouse_sql",
+					"clickhouse_sql": []interface{}{
+						map[string]interface{}{"name": "A", "query": "SELECT count() FROM signoz_traces.distributed_signoz_index_v2 JOIN signoz_traces.distributed_signoz_index_v2"},
+						map[string]interface{}{"name": "B", "query": "SELECT count() FROM signoz_logs.logs", "disabled": true},
+						map[string]interface{}{"name": "C", "query": "SELECT count() FROM signoz_traces.distributed_signoz_index_v3"},
+					},
+				},
+			},
+			map[string]interface{}{
+				"id":    "errors",
+				"title": "Errors",
+				"query": map[string]interface{}{
+					"queryType": "builder",
+					"builder": map[string]interface{}{
+						"queryData": []interface{}{
+							map[string]interface{}{
+								"queryName":  "A",
+								"dataSource": "logs",
+								"filters": map[string]interface{}{
+									"items": []interface{}{
+										map[string]interface{}{"op": "contains", "key": map[string]interface{}{"key": "service.name"}},
+										map[string]interface{}{"op": "contains", "key": map[string]interface{}{"key": "body"}},
+										map[string]interface{}{"op": "=", "key": map[string]interface{}{"key": "severity_text"}},
+									},
+								},
+							},
+							map[string]interface{}{
+								"queryName":          "B",
+								"dataSource":         "metrics",
+								"aggregateAttribute": map[string]interface{}{"key": "signoz_calls_total"},
+							},
+							map[string]interface{}{
+								"queryName":          "C",
+								"dataSource":         "metrics",
+								"aggregateAttribute": map[string]interface{}{"key": "http_requests_total"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	metricsExist := func(ctx context.Context, metricNames []string) (map[string]bool, error) {
+		require.ElementsMatch([]string{"signoz_calls_total", "http_requests_total"}, metricNames)
+		return map[string]bool{"signoz_calls_total": true}, nil
+	}
+
+	report := LintDashboard(context.Background(), data, metricsExist)
+	require.Empty(report.Errors)
+	require.Len(report.Warnings, 3)
+
+	require.Equal("errors", report.Warnings[0].WidgetId)
+	require.Equal(LintRuleAttributeContains, report.Warnings[0].Rule)
+	require.Equal("A", report.Warnings[0].Query)
+	require.Equal("errors", report.Warnings[1].WidgetId)
+	require.Equal(LintRuleUnknownMetric, report.Warnings[1].Rule)
+	require.Contains(report.Warnings[1].Message, "http_requests_total")
+	require.Equal("latency", report.Warnings[2].WidgetId)
+	require.Equal("Latency", report.Warnings[2].WidgetTitle)
+	require.Equal(LintRuleDeprecatedTable, report.Warnings[2].Rule)
+	require.Contains(report.Warnings[2].Message, "distributed_signoz_index_v2")
+
+	// the other rules still apply when the metrics can't be looked up
+	report = LintDashboard(context.Background(), data, func(ctx context.Context, metricNames []string) (map[string]bool, error) {
+		return nil, errors.New("clickhouse is down")
+	})
+	require.Len(report.Warnings, 2)
+
+	report = LintDashboard(context.Background(), map[string]interface{}{"title": 1}, nil)
+	require.NotEmpty(report.Errors)
+	require.Empty(report.Warnings)
+}
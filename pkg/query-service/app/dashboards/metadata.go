@@ -0,0 +1,130 @@
+package dashboards
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Tags are the tags of a dashboard, stored as a json array
+type Tags []string
+
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		t = Tags{}
+	}
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (t *Tags) Scan(src interface{}) error {
+	var data []byte
+	if b, ok := src.([]byte); ok {
+		data = b
+	} else if s, ok := src.(string); ok {
+		data = []byte(s)
+	} else {
+		*t = Tags{}
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
+
+// setMetadata copies the fields the dashboards are listed, filtered and
+// counted by out of the data
+func (d *Dashboard) setMetadata() {
+	d.Title = extractDashboardName(d.Data)
+	d.Description, _ = d.Data["description"].(string)
+	d.Tags = dashboardTags(d.Data)
+	panelCount := dashboardPanelCount(d.Data)
+	d.PanelCount = &panelCount
+	d.DefaultTimeRange, _ = d.Data["defaultTimeRange"].(string)
+	d.RefreshInterval, _ = d.Data["refreshInterval"].(string)
+}
+
+func dashboardTags(data Data) Tags {
+	tags := Tags{}
+	values, _ := data["tags"].([]interface{})
+	for _, value := range values {
+		if tag, ok := value.(string); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// dashboardPanelCount counts the widgets of the dashboard, leaving out the
+// widgets marking rows
+func dashboardPanelCount(data Data) int {
+	widgets, _ := data["widgets"].([]interface{})
+	count := 0
+	for _, widget := range widgets {
+		if w, ok := widget.(map[string]interface{}); ok && w["panelTypes"] == "row" {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// tagPattern is the LIKE pattern matching the tags column of the dashboards
+// with the tag
+func tagPattern(tag string) string {
+	quoted, _ := json.Marshal(tag)
+	return "%" + escapeLikePattern(string(quoted)) + "%"
+}
+
+// FilterDashboardsByTags returns the dashboards which have all the tags. It
+// mirrors the tags filter of ListDashboards for dashboards which are not
+// stored in the database.
+func FilterDashboardsByTags(dashboards []Dashboard, tags []string) []Dashboard {
+	if len(tags) == 0 {
+		return dashboards
+	}
+
+	filtered := []Dashboard{}
+	for _, dashboard := range dashboards {
+		has := map[string]bool{}
+		for _, tag := range dashboardTags(dashboard.Data) {
+			has[tag] = true
+		}
+
+		matches := true
+		for _, tag := range tags {
+			matches = matches && has[tag]
+		}
+		if matches {
+			filtered = append(filtered, dashboard)
+		}
+	}
+	return filtered
+}
+
+// BackfillDashboardMetadata copies the metadata and the usage out of the
+// data of the dashboards stored before they were kept in columns
+func BackfillDashboardMetadata(ctx context.Context) error {
+	var dashboards []Dashboard
+	err := db.Select(&dashboards, `SELECT uuid, data FROM dashboards WHERE panel_count IS NULL OR usage_counts IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	for _, dashboard := range dashboards {
+		dashboard.setMetadata()
+		dashboard.Usage = dashboardUsage(dashboard.Data)
+		_, err = db.Exec(`UPDATE dashboards SET description=?, tags=?, panel_count=?, usage_counts=? WHERE uuid=?`,
+			dashboard.Description, dashboard.Tags, dashboard.PanelCount, dashboard.Usage, dashboard.Uuid)
+		if err != nil {
+			zap.L().Error("Error in backfilling dashboard metadata", zap.String("uuid", dashboard.Uuid), zap.Error(err))
+			return fmt.Errorf("failed to backfill metadata of dashboard %s: %w", dashboard.Uuid, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardMetadataColumns(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	kafka, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title":       "kafka",
+		"description": "consumer lag",
+		"tags":        []interface{}{"kafka", "prod"},
+		"widgets":     []interface{}{map[string]interface{}{"id": "lag"}},
+	}, nil)
+	require.Nil(apiErr)
+	_, apiErr = dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "redis",
+		"tags":  []interface{}{"redis", "prod"},
+	}, nil)
+	require.Nil(apiErr)
+
+	stored, apiErr := dashboards.GetDashboard(ctx, kafka.Uuid)
+	require.Nil(apiErr)
+	require.Equal("consumer lag", stored.Description)
+	require.Equal(dashboards.Tags{"kafka", "prod"}, stored.Tags)
+	require.Equal(1, *stored.PanelCount)
+
+	prod, apiErr := dashboards.ListDashboards(ctx, dashboards.ListDashboardsParams{Tags: []string{"prod"}})
+	require.Nil(apiErr)
+	require.Len(prod, 2)
+
+	// a tag matches whole tags only
+	matched, apiErr := dashboards.ListDashboards(ctx, dashboards.ListDashboardsParams{Tags: []string{"prod", "kafka"}})
+	require.Nil(apiErr)
+	require.Len(matched, 1)
+	require.Equal(kafka.Uuid, matched[0].Uuid)
+
+	matched, apiErr = dashboards.ListDashboards(ctx, dashboards.ListDashboardsParams{Tags: []string{"pro"}})
+	require.Nil(apiErr)
+	require.Empty(matched)
+
+	updated, apiErr := dashboards.UpdateDashboard(ctx, kafka.Uuid, map[string]interface{}{
+		"title": "kafka",
+		"tags":  []interface{}{"kafka"},
+		"widgets": []interface{}{
+			map[string]interface{}{"id": "lag"},
+			map[string]interface{}{"id": "throughput"},
+		},
+	}, false, 0, nil)
+	require.Nil(apiErr)
+	require.Equal(2, *updated.PanelCount)
+
+	info, err := dashboards.GetDashboardsInfo(ctx)
+	require.NoError(err)
+	require.Equal(2, info.TotalDashboards)
+	require.Equal(1, info.TotalDashboardsWithPanelAndName)
+	require.ElementsMatch([]string{"kafka", "redis"}, info.DashboardNames)
+}
+
+func TestFilterDashboardsByTags(t *testing.T) {
+	all := []dashboards.Dashboard{
+		{Uuid: "a", Data: dashboards.Data{"tags": []interface{}{"aws", "prod"}}},
+		{Uuid: "b", Data: dashboards.Data{"tags": []interface{}{"aws"}}},
+		{Uuid: "c", Data: dashboards.Data{}},
+	}
+
+	require.Len(t, dashboards.FilterDashboardsByTags(all, nil), 3)
+	filtered := dashboards.FilterDashboardsByTags(all, []string{"aws", "prod"})
+	require.Len(t, filtered, 1)
+	require.Equal(t, "a", filtered[0].Uuid)
+}
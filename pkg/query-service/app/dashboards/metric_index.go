@@ -0,0 +1,122 @@
+package dashboards
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// metricIndexRow records that a widget of a dashboard queries a metric
+type metricIndexRow struct {
+	MetricName     string `db:"metric_name"`
+	DashboardUuid  string `db:"dashboard_uuid"`
+	DashboardTitle string `db:"dashboard_title"`
+	WidgetId       string `db:"widget_id"`
+	WidgetTitle    string `db:"widget_title"`
+}
+
+// dashboardMetrics returns the metrics queried by the builder queries of the
+// widgets of the dashboard
+func dashboardMetrics(uuid string, data Data) []metricIndexRow {
+	rows := []metricIndexRow{}
+	seen := map[[2]string]bool{}
+
+	dashTitle := extractDashboardName(data)
+	widgets, _ := data["widgets"].([]interface{})
+	for _, w := range widgets {
+		widget, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		widgetTitle, _ := widget["title"].(string)
+		widgetID, _ := widget["id"].(string)
+
+		query, _ := widget["query"].(map[string]interface{})
+		builder, _ := query["builder"].(map[string]interface{})
+		queryData, _ := builder["queryData"].([]interface{})
+		for _, qd := range queryData {
+			data, ok := qd.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if dataSource, ok := data["dataSource"].(string); !ok || dataSource != "metrics" {
+				continue
+			}
+			aggregateAttr, _ := data["aggregateAttribute"].(map[string]interface{})
+			key, ok := aggregateAttr["key"].(string)
+			if !ok || strings.TrimSpace(key) == "" {
+				continue
+			}
+
+			metricName := strings.TrimSpace(key)
+			if seen[[2]string{metricName, widgetID}] {
+				continue
+			}
+			seen[[2]string{metricName, widgetID}] = true
+			rows = append(rows, metricIndexRow{
+				MetricName:     metricName,
+				DashboardUuid:  uuid,
+				DashboardTitle: dashTitle,
+				WidgetId:       widgetID,
+				WidgetTitle:    widgetTitle,
+			})
+		}
+	}
+
+	return rows
+}
+
+// indexDashboardMetrics replaces the metrics of the dashboard in the index
+func indexDashboardMetrics(tx *storeTx, uuid string, data Data) error {
+	if _, err := tx.Exec(`DELETE FROM dashboard_metric_index WHERE dashboard_uuid=?`, uuid); err != nil {
+		return err
+	}
+	for _, row := range dashboardMetrics(uuid, data) {
+		_, err := tx.NamedExec(`INSERT INTO dashboard_metric_index (metric_name, dashboard_uuid, dashboard_title, widget_id, widget_title)
+			VALUES (:metric_name, :dashboard_uuid, :dashboard_title, :widget_id, :widget_title)`, row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IndexDashboardMetrics builds the metric index from all dashboards if it is
+// empty, such as when it was just created
+func IndexDashboardMetrics(ctx context.Context) error {
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM dashboard_metric_index`); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	dashboards := []Dashboard{}
+	if err := db.Select(&dashboards, `SELECT * FROM dashboards`); err != nil {
+		return err
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, dashboard := range dashboards {
+		if err := indexDashboardMetrics(tx, dashboard.Uuid, dashboard.Data); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// pruneMetricIndex removes the purged dashboards from the metric index
+func pruneMetricIndex() {
+	_, err := db.Exec(`DELETE FROM dashboard_metric_index WHERE dashboard_uuid NOT IN (SELECT uuid FROM dashboards)`)
+	if err != nil {
+		zap.L().Error("Error in removing purged dashboards from the metric index", zap.Error(err))
+	}
+}
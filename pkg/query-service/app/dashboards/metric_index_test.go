@@ -0,0 +1,68 @@
+package dashboards_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func metricWidget(id, title string, metricNames ...string) map[string]interface{} {
+	queryData := []interface{}{}
+	for _, name := range metricNames {
+		queryData = append(queryData, map[string]interface{}{
+			"dataSource":         "metrics",
+			"aggregateAttribute": map[string]interface{}{"key": name},
+		})
+	}
+	return map[string]interface{}{
+		"id":    id,
+		"title": title,
+		"query": map[string]interface{}{
+			"queryType": "builder",
+			"builder":   map[string]interface{}{"queryData": queryData},
+		},
+	}
+}
+
+func TestGetDashboardsWithMetricNames(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "hosts",
+		"widgets": []interface{}{
+			metricWidget("cpu", "CPU", "system_cpu_time", "system_cpu_time"),
+			metricWidget("mem", "Memory", "system_memory_usage"),
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	result, apiErr := dashboards.GetDashboardsWithMetricNames(ctx, []string{"system_cpu_time", "system_disk_io"})
+	require.Nil(apiErr)
+	require.Equal(map[string][]map[string]string{
+		"system_cpu_time": {{"dashboard_id": dashboard.Uuid, "dashboard_title": "hosts", "widget_id": "cpu", "widget_title": "CPU"}},
+	}, result)
+
+	// the index follows the updates of the dashboard
+	_, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{
+		"title":   "host metrics",
+		"widgets": []interface{}{metricWidget("cpu", "CPU", "system_memory_usage")},
+	}, false, 0, nil)
+	require.Nil(apiErr)
+
+	result, apiErr = dashboards.GetDashboardsWithMetricNames(ctx, []string{"system_cpu_time", "system_memory_usage"})
+	require.Nil(apiErr)
+	require.Equal(map[string][]map[string]string{
+		"system_memory_usage": {{"dashboard_id": dashboard.Uuid, "dashboard_title": "host metrics", "widget_id": "cpu", "widget_title": "CPU"}},
+	}, result)
+
+	// deleted dashboards are left out
+	require.Nil(dashboards.DeleteDashboard(context.Background(), dashboard.Uuid, false, nil))
+	result, apiErr = dashboards.GetDashboardsWithMetricNames(ctx, []string{"system_memory_usage"})
+	require.Nil(apiErr)
+	require.Empty(result)
+}
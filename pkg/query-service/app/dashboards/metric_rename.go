@@ -0,0 +1,211 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// MetricRenameRequest renames the metric From to To in the queries of every
+// dashboard. With DryRun set only the affected panels are returned.
+type MetricRenameRequest struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	DryRun bool   `json:"dry_run"`
+}
+
+func (r *MetricRenameRequest) Validate() error {
+	r.From, r.To = strings.TrimSpace(r.From), strings.TrimSpace(r.To)
+	if r.From == "" || r.To == "" {
+		return fmt.Errorf("from and to are required")
+	}
+	if r.From == r.To {
+		return fmt.Errorf("from and to must be different")
+	}
+	if strings.ContainsAny(r.To, " \t\n'\"`") {
+		return fmt.Errorf("invalid metric name: %s", r.To)
+	}
+	return nil
+}
+
+// RenamedPanel is a panel whose queries use the renamed metric
+type RenamedPanel struct {
+	DashboardUuid  string `json:"dashboard_uuid"`
+	DashboardTitle string `json:"dashboard_title"`
+	WidgetId       string `json:"widget_id"`
+	WidgetTitle    string `json:"widget_title"`
+}
+
+// RenameMetric rewrites the metric name in the builder queries and in the
+// PromQL and ClickHouse query text of the panels of all dashboards, and
+// returns the panels which use it. All dashboards are updated together.
+func RenameMetric(ctx context.Context, req MetricRenameRequest) ([]RenamedPanel, *model.ApiError) {
+	if err := req.Validate(); err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	dashboards := []Dashboard{}
+	scope, args := orgScope(ctx)
+	if err := db.Select(&dashboards, `SELECT * FROM dashboards WHERE deleted_at IS NULL`+scope, args...); err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	panels := []RenamedPanel{}
+	renamed := []*Dashboard{}
+	for i := range dashboards {
+		dashboard := &dashboards[i]
+		widgets, _ := dashboard.Data["widgets"].([]interface{})
+		changed := false
+		for _, w := range widgets {
+			widget, ok := w.(map[string]interface{})
+			if !ok || !renameMetricInWidget(widget, req.From, req.To) {
+				continue
+			}
+			changed = true
+			widgetId, _ := widget["id"].(string)
+			widgetTitle, _ := widget["title"].(string)
+			panels = append(panels, RenamedPanel{
+				DashboardUuid:  dashboard.Uuid,
+				DashboardTitle: extractDashboardName(dashboard.Data),
+				WidgetId:       widgetId,
+				WidgetTitle:    widgetTitle,
+			})
+		}
+		if changed {
+			renamed = append(renamed, dashboard)
+		}
+	}
+
+	if req.DryRun || len(renamed) == 0 {
+		return panels, nil
+	}
+
+	if err := saveRenamedDashboards(ctx, renamed, req); err != nil {
+		zap.L().Error("Error in renaming metric in dashboards", zap.String("from", req.From), zap.String("to", req.To), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	for _, dashboard := range renamed {
+		notifyWebhooks(ctx, dashboard.Uuid, AuditActionUpdate, dashboard.Version)
+	}
+
+	return panels, nil
+}
+
+func saveRenamedDashboards(ctx context.Context, dashboards []*Dashboard, req MetricRenameRequest) error {
+	var userId string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		userId = user.Id
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, dashboard := range dashboards {
+		if err := saveRenamedDashboard(ctx, tx, dashboard, userId, req); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func saveRenamedDashboard(ctx context.Context, tx *storeTx, dashboard *Dashboard, userId string, req MetricRenameRequest) error {
+	data, err := dashboard.Data.Value()
+	if err != nil {
+		return err
+	}
+
+	dashboard.UpdatedAt = time.Now()
+	_, err = tx.Exec(`UPDATE dashboards SET data=?, updated_at=?, updated_by=?, version=version+1 WHERE uuid=?`, data, dashboard.UpdatedAt, userId, dashboard.Uuid)
+	if err != nil {
+		return err
+	}
+	dashboard.Version++
+	recordVersionAudit(ctx, tx, dashboard.Uuid, AuditActionUpdate, fmt.Sprintf("renamed metric %q to %q", req.From, req.To), dashboard.Version, "")
+
+	return indexDashboardData(tx, dashboard.Uuid, dashboard.Data)
+}
+
+// renameMetricInWidget renames the metric in the queries of the widget and
+// reports whether any query used it
+func renameMetricInWidget(widget map[string]interface{}, from, to string) bool {
+	query, ok := widget["query"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	changed := false
+	if builder, ok := query["builder"].(map[string]interface{}); ok {
+		queryData, _ := builder["queryData"].([]interface{})
+		for _, qd := range queryData {
+			data, ok := qd.(map[string]interface{})
+			if !ok || data["dataSource"] != "metrics" {
+				continue
+			}
+			attr, ok := data["aggregateAttribute"].(map[string]interface{})
+			if !ok || attr["key"] != from {
+				continue
+			}
+			attr["key"] = to
+			// the id is made of the key, the type and the data type
+			if id, ok := attr["id"].(string); ok && strings.HasPrefix(id, from+"--") {
+				attr["id"] = to + strings.TrimPrefix(id, from)
+			}
+			changed = true
+		}
+	}
+
+	for _, key := range []string{"promql", "clickhouse_sql"} {
+		queries, _ := query[key].([]interface{})
+		for _, q := range queries {
+			data, ok := q.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			text, ok := data["query"].(string)
+			if !ok {
+				continue
+			}
+			if renamed := replaceMetricName(text, from, to); renamed != text {
+				data["query"] = renamed
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// replaceMetricName replaces the whole occurrences of the metric name in the
+// query text, so renaming a metric leaves the metrics it is a prefix of alone
+func replaceMetricName(text, from, to string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(text, from)
+		if i < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		end := i + len(from)
+		if (i == 0 || !isMetricNameChar(text[i-1])) && (end == len(text) || !isMetricNameChar(text[end])) {
+			b.WriteString(text[:i])
+			b.WriteString(to)
+		} else {
+			b.WriteString(text[:end])
+		}
+		text = text[end:]
+	}
+}
+
+func isMetricNameChar(c byte) bool {
+	return c == '_' || c == ':' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,95 @@
+package dashboards_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestRenameMetric(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "http",
+		"widgets": []interface{}{
+			map[string]interface{}{
+				"id":    "builder",
+				"title": "Requests",
+				"query": map[string]interface{}{
+					"queryType": "builder",
+					"builder": map[string]interface{}{
+						"queryData": []interface{}{
+							map[string]interface{}{
+								"dataSource": "metrics",
+								"aggregateAttribute": map[string]interface{}{
+									"key": "http_requests",
+									"id":  "http_requests--float64--Sum--true",
+								},
+							},
+						},
+					},
+				},
+			},
+			map[string]interface{}{
+				"id":    "promql",
+				"title": "Errors",
+				"query": map[string]interface{}{
+					"queryType": "promql",
+					"promql": []interface{}{
+						map[string]interface{}{"query": "sum(rate(http_requests{code=~\"5..\"}[5m])) / sum(rate(http_requests_total[5m]))"},
+					},
+				},
+			},
+			map[string]interface{}{
+				"id":    "other",
+				"title": "Latency",
+				"query": map[string]interface{}{
+					"queryType": "clickhouse_sql",
+					"clickhouse_sql": []interface{}{
+						map[string]interface{}{"query": "SELECT * FROM samples WHERE metric_name = 'http_requests_duration'"},
+					},
+				},
+			},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	_, apiErr = dashboards.RenameMetric(ctx, dashboards.MetricRenameRequest{From: "http_requests", To: "http_requests"})
+	require.NotNil(apiErr)
+
+	// a dry run lists the panels without changing them
+	panels, apiErr := dashboards.RenameMetric(ctx, dashboards.MetricRenameRequest{From: "http_requests", To: "http.server.requests", DryRun: true})
+	require.Nil(apiErr)
+	require.Equal([]dashboards.RenamedPanel{
+		{DashboardUuid: dashboard.Uuid, DashboardTitle: "http", WidgetId: "builder", WidgetTitle: "Requests"},
+		{DashboardUuid: dashboard.Uuid, DashboardTitle: "http", WidgetId: "promql", WidgetTitle: "Errors"},
+	}, panels)
+
+	stored, apiErr := dashboards.GetDashboard(context.Background(), dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal(1, stored.Version)
+
+	panels, apiErr = dashboards.RenameMetric(ctx, dashboards.MetricRenameRequest{From: "http_requests", To: "http.server.requests"})
+	require.Nil(apiErr)
+	require.Len(panels, 2)
+
+	stored, apiErr = dashboards.GetDashboard(context.Background(), dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal(2, stored.Version)
+	widgets := stored.Data["widgets"].([]interface{})
+
+	attr := widgets[0].(map[string]interface{})["query"].(map[string]interface{})["builder"].(map[string]interface{})["queryData"].([]interface{})[0].(map[string]interface{})["aggregateAttribute"].(map[string]interface{})
+	require.Equal("http.server.requests", attr["key"])
+	require.Equal("http.server.requests--float64--Sum--true", attr["id"])
+
+	promql := widgets[1].(map[string]interface{})["query"].(map[string]interface{})["promql"].([]interface{})[0].(map[string]interface{})
+	require.Equal("sum(rate(http.server.requests{code=~\"5..\"}[5m])) / sum(rate(http_requests_total[5m]))", promql["query"])
+
+	clickhouse := widgets[2].(map[string]interface{})["query"].(map[string]interface{})["clickhouse_sql"].([]interface{})[0].(map[string]interface{})
+	require.Equal("SELECT * FROM samples WHERE metric_name = 'http_requests_duration'", clickhouse["query"])
+}
@@ -2,15 +2,15 @@ package dashboards
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"regexp"
+	"math"
 	"slices"
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/gosimple/slug"
 	"github.com/jmoiron/sqlx"
 	"go.signoz.io/signoz/pkg/query-service/common"
@@ -22,52 +22,130 @@ import (
 )
 
 // This time the global variable is unexported.
-var db *sqlx.DB
-
-// User for mapping job,instance from grafana
-var (
-	instanceEQRE = regexp.MustCompile("instance(?s)=(?s)\\\"{{.instance}}\\\"")
-	nodeEQRE     = regexp.MustCompile("instance(?s)=(?s)\\\"{{.node}}\\\"")
-	jobEQRE      = regexp.MustCompile("job(?s)=(?s)\\\"{{.job}}\\\"")
-	instanceRERE = regexp.MustCompile("instance(?s)=~(?s)\\\"{{.instance}}\\\"")
-	nodeRERE     = regexp.MustCompile("instance(?s)=~(?s)\\\"{{.node}}\\\"")
-	jobRERE      = regexp.MustCompile("job(?s)=~(?s)\\\"{{.job}}\\\"")
-)
+var db *storeDB
 
 // InitDB sets up setting up the connection pool global variable.
 func InitDB(inputDB *sqlx.DB) error {
-	db = inputDB
+	db = newStoreDB(inputDB)
 	telemetry.GetInstance().SetDashboardsInfoCallback(GetDashboardsInfo)
 
+	if err := BackfillSchemaVersions(context.Background()); err != nil {
+		zap.L().Error("Error in backfilling dashboard schema versions", zap.Error(err))
+	}
+
+	if err := BackfillDashboardSlugs(context.Background()); err != nil {
+		zap.L().Error("Error in backfilling dashboard slugs", zap.Error(err))
+	}
+
+	if err := BackfillDashboardMetadata(context.Background()); err != nil {
+		zap.L().Error("Error in backfilling dashboard metadata", zap.Error(err))
+	}
+
+	if err := CompressDashboardData(context.Background()); err != nil {
+		zap.L().Error("Error in compressing dashboard data", zap.Error(err))
+	}
+
+	if err := IndexDashboards(context.Background()); err != nil {
+		zap.L().Error("Error in indexing dashboards for search", zap.Error(err))
+	}
+
+	if err := IndexDashboardMetrics(context.Background()); err != nil {
+		zap.L().Error("Error in indexing dashboard metrics", zap.Error(err))
+	}
+
+	if err := IndexDashboardServices(context.Background()); err != nil {
+		zap.L().Error("Error in inferring dashboard services", zap.Error(err))
+	}
+
 	return nil
 }
 
 type Dashboard struct {
-	Id        int       `json:"id" db:"id"`
-	Uuid      string    `json:"uuid" db:"uuid"`
-	Slug      string    `json:"-" db:"-"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	CreateBy  *string   `json:"created_by" db:"created_by"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-	UpdateBy  *string   `json:"updated_by" db:"updated_by"`
-	Title     string    `json:"-" db:"-"`
-	Data      Data      `json:"data" db:"data"`
-	Locked    *int      `json:"isLocked" db:"locked"`
+	Id        int        `json:"id" db:"id"`
+	Uuid      string     `json:"uuid" db:"uuid"`
+	Slug      string     `json:"slug" db:"slug"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	CreateBy  *string    `json:"created_by" db:"created_by"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	UpdateBy  *string    `json:"updated_by" db:"updated_by"`
+	Owner     *string    `json:"owner" db:"owner"`
+	OrgId     *string    `json:"org_id" db:"org_id"`
+	FolderId  *string    `json:"folder_id" db:"folder_id"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	Title     string     `json:"-" db:"title"`
+	Data      Data       `json:"data" db:"data"`
+	Locked    *int       `json:"isLocked" db:"locked"`
+	Version   int        `json:"version" db:"version"`
+
+	// LockedBy and LockedAt are who locked the dashboard and when, only they
+	// and admins can unlock it
+	LockedBy *string    `json:"locked_by" db:"locked_by"`
+	LockedAt *time.Time `json:"locked_at" db:"locked_at"`
+
+	// ArchivedAt is set while the dashboard is archived, archived dashboards
+	// are hidden from the list but can be unarchived
+	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+
+	// Description, Tags and PanelCount are copied from the data on write, so
+	// dashboards can be filtered and counted without decoding their data
+	Description string `json:"-" db:"description"`
+	Tags        Tags   `json:"-" db:"tags"`
+	PanelCount  *int   `json:"-" db:"panel_count"`
+
+	// DefaultTimeRange and RefreshInterval are copied from the data on write
+	// as well, so the dashboards list can show them
+	DefaultTimeRange string `json:"-" db:"default_time_range"`
+	RefreshInterval  string `json:"-" db:"refresh_interval"`
+
+	// Usage is counted in the data on write for the telemetry
+	Usage *DashboardUsage `json:"-" db:"usage_counts"`
+
+	// SchemaVersion is copied from the data on write, so the dashboards can be
+	// found by the version of their schema
+	SchemaVersion int `json:"-" db:"schema_version"`
+
+	// ProvisionedFrom is the file the dashboard is provisioned from, such
+	// dashboards can only be changed through their file
+	ProvisionedFrom *string `json:"provisioned_from,omitempty" db:"provisioned_from"`
+
+	ViewCount    int        `json:"view_count" db:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at" db:"last_viewed_at"`
+
+	// RemovedWidgets are the ids of the widgets an update removed
+	RemovedWidgets []string `json:"removed_widgets,omitempty" db:"-"`
+
+	// CreatedByUser and UpdatedByUser are the users CreateBy and UpdateBy
+	// refer to by id, resolved by the API when the dashboard is read
+	CreatedByUser *DashboardUser `json:"created_by_user,omitempty" db:"-"`
+	UpdatedByUser *DashboardUser `json:"updated_by_user,omitempty" db:"-"`
+}
+
+// DashboardUser is a user who created or changed a dashboard. Dashboards
+// saved by users who were since deleted only have their id, or their email if
+// they were saved before users were stored by id.
+type DashboardUser struct {
+	Id       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	NotFound bool   `json:"notFound,omitempty"`
 }
 
 type Data map[string]interface{}
 
-// func (c *Data) Value() (driver.Value, error) {
-// 	if c != nil {
-// 		b, err := json.Marshal(c)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		return string(b), nil
-// 	}
-// 	return nil, nil
-// }
+// Value stores the data as gzipped json, large dashboards would otherwise
+// bloat the database
+func (c Data) Value() (driver.Value, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	if db != nil && !db.Dialect().compressesData() {
+		return string(b), nil
+	}
+	return compressData(b)
+}
 
+// Scan reads the data, stored compressed or as plain json by older versions
 func (c *Data) Scan(src interface{}) error {
 	var data []byte
 	if b, ok := src.([]byte); ok {
@@ -75,56 +153,229 @@ func (c *Data) Scan(src interface{}) error {
 	} else if s, ok := src.(string); ok {
 		data = []byte(s)
 	}
+	data, err := decompressData(data)
+	if err != nil {
+		return err
+	}
 	return json.Unmarshal(data, c)
 }
 
 // CreateDashboard creates a new dashboard
 func CreateDashboard(ctx context.Context, data map[string]interface{}, fm interfaces.FeatureLookup) (*Dashboard, *model.ApiError) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	defer tx.Rollback()
+
+	dash, apiErr := createDashboard(ctx, tx, data)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if err := tx.Commit(); err != nil {
+		zap.L().Error("Error in committing dashboard", zap.String("uuid", dash.Uuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	notifyWebhooks(ctx, dash.Uuid, AuditActionCreate, dash.Version)
+
+	return dash, nil
+}
+
+// createDashboard inserts the dashboard along with its audit entry and
+// indexes in the transaction
+func createDashboard(ctx context.Context, tx *storeTx, data map[string]interface{}) (*Dashboard, *model.ApiError) {
+	if err := ValidateDashboardData(data); err != nil {
+		return nil, model.BadRequest(err)
+	}
+	if apiErr := checkDashboardSize(data); apiErr != nil {
+		return nil, apiErr
+	}
+	id, err := dashboardUuid(data)
+	if err != nil {
+		return nil, model.BadRequest(err)
+	}
+
 	dash := &Dashboard{
+		Uuid: id,
 		Data: data,
 	}
-	var userEmail string
+	var userId, userEmail string
 	if user := common.GetUserFromContext(ctx); user != nil {
-		userEmail = user.Email
+		userId, userEmail = user.Id, user.Email
+		if user.OrgId != "" {
+			dash.OrgId = &user.OrgId
+		}
 	}
 	dash.CreatedAt = time.Now()
-	dash.CreateBy = &userEmail
+	dash.CreateBy = &userId
 	dash.UpdatedAt = time.Now()
-	dash.UpdateBy = &userEmail
+	dash.UpdateBy = &userId
+	dash.Owner = &userEmail
+	dash.Version = 1
 	dash.UpdateSlug()
-	dash.Uuid = uuid.New().String()
 	if data["uuid"] != nil {
-		dash.Uuid = data["uuid"].(string)
+		data["uuid"] = dash.Uuid
+	}
+	if GetSchemaVersion(data) == 0 {
+		SetSchemaVersion(data, inferSchemaVersion(data))
 	}
 
-	mapData, err := json.Marshal(dash.Data)
+	if apiErr := checkUuidAvailable(tx, dash.Uuid); apiErr != nil {
+		return nil, apiErr
+	}
+	slug, err := uniqueSlug(tx, dash.Slug, dash.Uuid)
 	if err != nil {
-		zap.L().Error("Error in marshalling data field in dashboard: ", zap.Any("dashboard", dash), zap.Error(err))
 		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
 	}
+	dash.Slug = slug
+	dash.setMetadata()
+	dash.Usage = dashboardUsage(dash.Data)
 
-	result, err := db.Exec("INSERT INTO dashboards (uuid, created_at, created_by, updated_at, updated_by, data) VALUES ($1, $2, $3, $4, $5, $6)",
-		dash.Uuid, dash.CreatedAt, userEmail, dash.UpdatedAt, userEmail, mapData)
+	mapData, err := dash.Data.Value()
+	if err != nil {
+		zap.L().Error("Error in marshalling data field in dashboard: ", zap.Any("dashboard", dash), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
 
+	// RETURNING rather than LastInsertId, which PostgreSQL does not support
+	err = tx.Get(&dash.Id, `INSERT INTO dashboards (uuid, created_at, created_by, updated_at, updated_by, owner, org_id, slug, title, description, tags, panel_count, default_time_range, refresh_interval, usage_counts, schema_version, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		dash.Uuid, dash.CreatedAt, userId, dash.UpdatedAt, userId, userEmail, dash.OrgId, dash.Slug, dash.Title, dash.Description, dash.Tags, dash.PanelCount, dash.DefaultTimeRange, dash.RefreshInterval, dash.Usage, GetSchemaVersion(data), mapData)
 	if err != nil {
 		zap.L().Error("Error in inserting dashboard data: ", zap.Any("dashboard", dash), zap.Error(err))
 		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
 	}
-	lastInsertId, err := result.LastInsertId()
-	if err != nil {
+	recordVersionAudit(ctx, tx, dash.Uuid, AuditActionCreate, fmt.Sprintf("created dashboard %q", extractDashboardName(data)), dash.Version, "")
+
+	if err := indexDashboardData(tx, dash.Uuid, dash.Data); err != nil {
+		zap.L().Error("Error in indexing dashboard", zap.String("uuid", dash.Uuid), zap.Error(err))
 		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
 	}
-	dash.Id = int(lastInsertId)
 
 	return dash, nil
 }
 
+// indexDashboardData updates the search, metric and service indexes of the
+// dashboard in the transaction saving it, so they change along with the data
+func indexDashboardData(tx *storeTx, uuid string, data Data) error {
+	if err := indexDashboard(tx, uuid, data); err != nil {
+		return fmt.Errorf("failed to index dashboard for search: %w", err)
+	}
+	if err := indexDashboardMetrics(tx, uuid, data); err != nil {
+		return fmt.Errorf("failed to index dashboard metrics: %w", err)
+	}
+	if err := indexDashboardServices(tx, uuid, data); err != nil {
+		return fmt.Errorf("failed to infer dashboard services: %w", err)
+	}
+	return nil
+}
+
+// dashboardSortColumns maps the supported sort keys to their sql expressions
+var dashboardSortColumns = map[string]string{
+	"title":          "title",
+	"updated_at":     "updated_at",
+	"created_by":     "created_by",
+	"view_count":     "view_count",
+	"last_viewed_at": "last_viewed_at",
+}
+
+// ListDashboardsParams holds the pagination, sorting and filtering options
+// for listing dashboards. The zero value lists every dashboard.
+type ListDashboardsParams struct {
+	Limit   int
+	Offset  int
+	OrderBy string
+	Order   string
+	Title   string
+	// Favorites lists only the dashboards starred by the user of the context
+	Favorites bool
+	// Archived lists the archived dashboards instead of the active ones
+	Archived bool
+	// Tags lists only the dashboards which have all the tags
+	Tags []string
+	// Summary leaves out the data of the dashboards
+	Summary bool
+}
+
+func (p *ListDashboardsParams) Validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("limit must be a non-negative integer")
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("offset must be a non-negative integer")
+	}
+	if _, ok := dashboardSortColumns[p.OrderBy]; p.OrderBy != "" && !ok {
+		return fmt.Errorf("invalid orderBy %q, must be one of title, updated_at, created_by, view_count, last_viewed_at", p.OrderBy)
+	}
+	p.Order = strings.ToLower(p.Order)
+	if p.Order != "" && p.Order != "asc" && p.Order != "desc" {
+		return fmt.Errorf("invalid order %q, must be one of asc, desc", p.Order)
+	}
+	return nil
+}
+
 func GetDashboards(ctx context.Context) ([]Dashboard, *model.ApiError) {
+	return ListDashboards(ctx, ListDashboardsParams{})
+}
+
+// ListDashboards returns the dashboards matching the params, filtered, sorted
+// and paginated in the database
+func ListDashboards(ctx context.Context, params ListDashboardsParams) ([]Dashboard, *model.ApiError) {
+	if err := params.Validate(); err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	columns := "*"
+	if params.Summary {
+		columns = summaryColumns
+	}
 
 	dashboards := []Dashboard{}
-	query := `SELECT * FROM dashboards`
+	scope, args := orgScope(ctx)
+	query := `SELECT ` + columns + ` FROM dashboards WHERE deleted_at IS NULL` + scope
+
+	if params.Archived {
+		query += ` AND archived_at IS NOT NULL`
+	} else {
+		query += ` AND archived_at IS NULL`
+	}
+
+	if params.Title != "" {
+		query += ` AND LOWER(title) LIKE ?`
+		args = append(args, "%"+strings.ToLower(params.Title)+"%")
+	}
+
+	for _, tag := range params.Tags {
+		query += ` AND LOWER(tags) LIKE ? ESCAPE '\'`
+		args = append(args, strings.ToLower(tagPattern(tag)))
+	}
+
+	if params.Favorites {
+		userId, apiErr := favoritesUserId(ctx)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		query += ` AND uuid IN (SELECT dashboard_uuid FROM dashboard_favorites WHERE user_id=?)`
+		args = append(args, userId)
+	}
+
+	if params.OrderBy != "" {
+		order := "ASC"
+		if params.Order == "desc" {
+			order = "DESC"
+		}
+		query += fmt.Sprintf(` ORDER BY %s %s, id ASC`, dashboardSortColumns[params.OrderBy], order)
+	}
 
-	err := db.Select(&dashboards, query)
+	if params.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, params.Limit, params.Offset)
+	} else if params.Offset > 0 {
+		// neither dialect has an offset without a limit that the other accepts
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, int64(math.MaxInt64), params.Offset)
+	}
+
+	err := db.Select(&dashboards, query, args...)
 	if err != nil {
 		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
 	}
@@ -132,7 +383,53 @@ func GetDashboards(ctx context.Context) ([]Dashboard, *model.ApiError) {
 	return dashboards, nil
 }
 
-func DeleteDashboard(ctx context.Context, uuid string, fm interfaces.FeatureLookup) *model.ApiError {
+// FilterDashboardsByTitle returns the dashboards whose title contains the given
+// text, ignoring case. It mirrors the title filter of ListDashboards for
+// dashboards which are not stored in the database.
+func FilterDashboardsByTitle(dashboards []Dashboard, title string) []Dashboard {
+	if title == "" {
+		return dashboards
+	}
+
+	filtered := []Dashboard{}
+	for _, dashboard := range dashboards {
+		if strings.Contains(strings.ToLower(extractDashboardName(dashboard.Data)), strings.ToLower(title)) {
+			filtered = append(filtered, dashboard)
+		}
+	}
+	return filtered
+}
+
+// GetDashboardsByCreator returns the dashboards created or owned by the given
+// user. The creator is stored by id and the owner by email.
+func GetDashboardsByCreator(ctx context.Context, userId string, email string) ([]Dashboard, *model.ApiError) {
+
+	dashboards := []Dashboard{}
+	scope, args := orgScope(ctx)
+	query := `SELECT * FROM dashboards WHERE deleted_at IS NULL AND (created_by=? OR owner=?)` + scope
+
+	err := db.Select(&dashboards, query, append([]interface{}{userId, email}, args...)...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return dashboards, nil
+}
+
+// GetMyDashboards returns the dashboards created or owned by the user in the context
+func GetMyDashboards(ctx context.Context) ([]Dashboard, *model.ApiError) {
+	user := common.GetUserFromContext(ctx)
+	if user == nil {
+		return nil, model.UnauthorizedError(fmt.Errorf("no user found in context"))
+	}
+
+	return GetDashboardsByCreator(ctx, user.Id, user.Email)
+}
+
+// DeleteDashboard moves the dashboard to the trash. Dashboards used by alert
+// rules or scheduled reports are only deleted when force is set, otherwise a
+// conflict with a *DashboardReferencedError is returned.
+func DeleteDashboard(ctx context.Context, uuid string, force bool, fm interfaces.FeatureLookup) *model.ApiError {
 
 	dashboard, dErr := GetDashboard(ctx, uuid)
 	if dErr != nil {
@@ -144,11 +441,24 @@ func DeleteDashboard(ctx context.Context, uuid string, fm interfaces.FeatureLook
 		if dashboard.Locked != nil && *dashboard.Locked == 1 {
 			return model.BadRequest(fmt.Errorf("dashboard is locked, please unlock the dashboard to be able to delete it"))
 		}
+		if dashboard.ProvisionedFrom != nil {
+			return errProvisioned(dashboard)
+		}
+		if !canManageDashboard(dashboard, user) {
+			return model.ForbiddenError(fmt.Errorf("only the owner of the dashboard or an admin can delete it"))
+		}
+	}
+	if !force {
+		if apiErr := checkDashboardReferences(db, uuid); apiErr != nil {
+			return apiErr
+		}
 	}
 
-	query := `DELETE FROM dashboards WHERE uuid=?`
+	// dashboards are moved to the trash, they are purged once the retention is over
+	scope, args := orgScope(ctx)
+	query := `UPDATE dashboards SET deleted_at=? WHERE uuid=? AND deleted_at IS NULL` + scope
 
-	result, err := db.Exec(query, uuid)
+	result, err := db.Exec(query, append([]interface{}{time.Now(), uuid}, args...)...)
 	if err != nil {
 		return &model.ApiError{Typ: model.ErrorExec, Err: err}
 	}
@@ -160,16 +470,38 @@ func DeleteDashboard(ctx context.Context, uuid string, fm interfaces.FeatureLook
 	if affectedRows == 0 {
 		return &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no dashboard found with uuid: %s", uuid)}
 	}
+	recordAudit(ctx, db, uuid, AuditActionDelete, "moved to trash")
+	notifyWebhooks(ctx, uuid, AuditActionDelete, dashboard.Version)
 
 	return nil
 }
 
+// RecordDashboardView counts a view of the dashboard by the user of the
+// context. Views without a user, e.g. of shared dashboards, are not counted.
+func RecordDashboardView(ctx context.Context, uuid string) {
+	if user := common.GetUserFromContext(ctx); user == nil {
+		return
+	}
+
+	scope, args := orgScope(ctx)
+	_, err := db.Exec(`UPDATE dashboards SET view_count=view_count+1, last_viewed_at=? WHERE uuid=? AND deleted_at IS NULL`+scope, append([]interface{}{time.Now(), uuid}, args...)...)
+	if err != nil {
+		zap.L().Error("Error in recording dashboard view", zap.String("uuid", uuid), zap.Error(err))
+	}
+}
+
 func GetDashboard(ctx context.Context, uuid string) (*Dashboard, *model.ApiError) {
+	return getDashboard(ctx, db, uuid)
+}
 
+// getDashboard reads the dashboard with q, either the database or the
+// transaction changing the dashboard
+func getDashboard(ctx context.Context, q Store, uuid string) (*Dashboard, *model.ApiError) {
 	dashboard := Dashboard{}
-	query := `SELECT * FROM dashboards WHERE uuid=?`
+	scope, args := orgScope(ctx)
+	query := `SELECT * FROM dashboards WHERE uuid=? AND deleted_at IS NULL` + scope
 
-	err := db.Get(&dashboard, query, uuid)
+	err := q.Get(&dashboard, query, append([]interface{}{uuid}, args...)...)
 	if err != nil {
 		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no dashboard found with uuid: %s", uuid)}
 	}
@@ -177,66 +509,263 @@ func GetDashboard(ctx context.Context, uuid string) (*Dashboard, *model.ApiError
 	return &dashboard, nil
 }
 
-func UpdateDashboard(ctx context.Context, uuid string, data map[string]interface{}, fm interfaces.FeatureLookup) (*Dashboard, *model.ApiError) {
+// orgScope returns the condition restricting a query to the dashboards of the
+// org of the user of the context, along with its args. Dashboards without an
+// org are not shared, they are seen by no user. Internal callers without a
+// user are not restricted.
+func orgScope(ctx context.Context) (string, []interface{}) {
+	user := common.GetUserFromContext(ctx)
+	if user == nil || user.OrgId == "" {
+		return "", []interface{}{}
+	}
+	return ` AND org_id=?`, []interface{}{user.OrgId}
+}
 
-	mapData, err := json.Marshal(data)
+// UpdateDashboard replaces the data of the dashboard. Removing more than one
+// widget at once is rejected with a *PanelDeletionError unless force is set.
+// If expectedVersion is not 0 the update fails with a conflict when the
+// dashboard has been saved since the client read that version. An optional
+// message field explaining the change is stored with the new version.
+func UpdateDashboard(ctx context.Context, uuid string, data map[string]interface{}, force bool, expectedVersion int, fm interfaces.FeatureLookup) (*Dashboard, *model.ApiError) {
+	// the dashboard is read, checked and saved along with its audit entry and
+	// indexes in a single transaction
+	tx, err := db.Beginx()
 	if err != nil {
-		zap.L().Error("Error in marshalling data field in dashboard: ", zap.Any("data", data), zap.Error(err))
-		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
 	}
+	defer tx.Rollback()
 
-	dashboard, apiErr := GetDashboard(ctx, uuid)
+	dashboard, apiErr := updateDashboard(ctx, tx, uuid, data, force, expectedVersion)
 	if apiErr != nil {
 		return nil, apiErr
 	}
+	if err := tx.Commit(); err != nil {
+		zap.L().Error("Error in committing dashboard update", zap.String("uuid", dashboard.Uuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	notifyWebhooks(ctx, dashboard.Uuid, AuditActionUpdate, dashboard.Version)
+
+	return dashboard, nil
+}
+
+// updateDashboard replaces the data of the dashboard along with its audit
+// entry and indexes in the transaction
+func updateDashboard(ctx context.Context, tx *storeTx, uuid string, data map[string]interface{}, force bool, expectedVersion int) (*Dashboard, *model.ApiError) {
+	message, err := popChangeMessage(data)
+	if err != nil {
+		return nil, model.BadRequest(err)
+	}
+	if err := ValidateDashboardData(data); err != nil {
+		return nil, model.BadRequest(err)
+	}
+	if apiErr := checkDashboardSize(data); apiErr != nil {
+		return nil, apiErr
+	}
 
-	var userEmail string
+	dashboard, apiErr := getDashboard(ctx, tx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if expectedVersion != 0 && expectedVersion != dashboard.Version {
+		return nil, versionConflict()
+	}
+
+	// clients unaware of the schema version must not drop it
+	if GetSchemaVersion(data) == 0 {
+		version := GetSchemaVersion(dashboard.Data)
+		if version == 0 {
+			version = inferSchemaVersion(data)
+		}
+		SetSchemaVersion(data, version)
+	}
+
+	mapData, err := Data(data).Value()
+	if err != nil {
+		zap.L().Error("Error in marshalling data field in dashboard: ", zap.Any("data", data), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+
+	var userId string
 	if user := common.GetUserFromContext(ctx); user != nil {
-		userEmail = user.Email
+		userId = user.Id
 		if dashboard.Locked != nil && *dashboard.Locked == 1 {
 			return nil, model.BadRequest(fmt.Errorf("dashboard is locked, please unlock the dashboard to be able to edit it"))
 		}
+		if dashboard.ProvisionedFrom != nil {
+			return nil, errProvisioned(dashboard)
+		}
 	}
 
 	// if the total count of panels has reduced by more than 1,
-	// return error
+	// the client has to confirm the deletion
 	existingIds := getWidgetIds(dashboard.Data)
 	newIds := getWidgetIds(data)
 
 	differenceIds := getIdDifference(existingIds, newIds)
 
-	if len(differenceIds) > 1 {
-		return nil, model.BadRequest(fmt.Errorf("deleting more than one panel is not supported"))
+	if len(differenceIds) > 1 && !force {
+		return nil, model.BadRequest(&PanelDeletionError{WidgetIds: differenceIds})
 	}
 
+	summary := auditDiffSummary(dashboard.Data, data)
 	dashboard.UpdatedAt = time.Now()
-	dashboard.UpdateBy = &userEmail
+	dashboard.UpdateBy = &userId
 	dashboard.Data = data
+	dashboard.setMetadata()
+	dashboard.Usage = dashboardUsage(dashboard.Data)
+	dashboard.RemovedWidgets = differenceIds
 
-	_, err = db.Exec("UPDATE dashboards SET updated_at=$1, updated_by=$2, data=$3 WHERE uuid=$4;",
-		dashboard.UpdatedAt, userEmail, mapData, dashboard.Uuid)
+	// the version check guards against a concurrent update since the
+	// dashboard was read above, in the org of the user
+	scope, args := orgScope(ctx)
+	result, err := tx.Exec("UPDATE dashboards SET updated_at=?, updated_by=?, title=?, description=?, tags=?, panel_count=?, default_time_range=?, refresh_interval=?, usage_counts=?, schema_version=?, data=?, version=version+1 WHERE uuid=? AND version=?"+scope+";",
+		append([]interface{}{dashboard.UpdatedAt, userId, dashboard.Title, dashboard.Description, dashboard.Tags, dashboard.PanelCount, dashboard.DefaultTimeRange, dashboard.RefreshInterval, dashboard.Usage, GetSchemaVersion(data), mapData, dashboard.Uuid, dashboard.Version}, args...)...)
 
 	if err != nil {
 		zap.L().Error("Error in inserting dashboard data", zap.Any("data", data), zap.Error(err))
 		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
 	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if affectedRows == 0 {
+		return nil, versionConflict()
+	}
+	dashboard.Version++
+	recordVersionAudit(ctx, tx, dashboard.Uuid, AuditActionUpdate, summary, dashboard.Version, message)
+
+	if err := indexDashboardData(tx, dashboard.Uuid, dashboard.Data); err != nil {
+		zap.L().Error("Error in indexing dashboard", zap.String("uuid", dashboard.Uuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
 	return dashboard, nil
 }
 
+// DuplicateDashboard creates a copy of the dashboard with a new uuid, in the same folder
+func DuplicateDashboard(ctx context.Context, uuid string, fm interfaces.FeatureLookup) (*Dashboard, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	// round trip through json to deep copy the data
+	var data map[string]interface{}
+	rawData, err := json.Marshal(dashboard.Data)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if err := json.Unmarshal(rawData, &data); err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	delete(data, "uuid")
+	data["title"] = fmt.Sprintf("%s (copy)", extractDashboardName(data))
+
+	dash, apiErr := CreateDashboard(ctx, data, fm)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if dashboard.FolderId != nil {
+		if apiErr := MoveDashboard(ctx, dash.Uuid, dashboard.FolderId); apiErr != nil {
+			return nil, apiErr
+		}
+		dash.FolderId = dashboard.FolderId
+	}
+
+	return dash, nil
+}
+
+// LockUnlockDashboard locks the dashboard in the name of the user of the
+// context, or unlocks it if the user is the one who locked it or an admin
 func LockUnlockDashboard(ctx context.Context, uuid string, lock bool) *model.ApiError {
-	var query string
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if user := common.GetUserFromContext(ctx); user != nil {
+		if lock && !canManageDashboard(dashboard, user) {
+			return model.ForbiddenError(fmt.Errorf("only the owner of the dashboard or an admin can lock it"))
+		}
+		if !lock && !canUnlockDashboard(dashboard, user) {
+			return model.ForbiddenError(fmt.Errorf("dashboard is locked by %s, only they or an admin can unlock it", lockedBy(dashboard)))
+		}
+	}
+
+	action := AuditActionUnlock
 	if lock {
-		query = `UPDATE dashboards SET locked=1 WHERE uuid=?;`
-	} else {
-		query = `UPDATE dashboards SET locked=0 WHERE uuid=?;`
+		action = AuditActionLock
+	}
+	return setDashboardLock(ctx, dashboard, lock, fmt.Sprintf("%sed dashboard", action))
+}
+
+// ForceUnlockDashboard unlocks the dashboard whoever locked it, for admins to
+// unlock dashboards of users who are unavailable
+func ForceUnlockDashboard(ctx context.Context, uuid string) *model.ApiError {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	summary := "force unlocked dashboard"
+	if dashboard.LockedBy != nil {
+		summary = fmt.Sprintf("force unlocked dashboard locked by %s", *dashboard.LockedBy)
+	}
+	return setDashboardLock(ctx, dashboard, false, summary)
+}
+
+// canManageDashboard reports whether the user can delete or lock the
+// dashboard, which is left to its owner and admins. Editors can still change
+// its data. Dashboards created without a user have no owner.
+func canManageDashboard(dashboard *Dashboard, user *model.UserPayload) bool {
+	if user.Role == "ADMIN" || dashboard.Owner == nil || *dashboard.Owner == "" {
+		return true
+	}
+	return *dashboard.Owner == user.Email
+}
+
+// canUnlockDashboard reports whether the user can unlock the dashboard. Locks
+// taken before the locker was recorded can be removed by the owner.
+func canUnlockDashboard(dashboard *Dashboard, user *model.UserPayload) bool {
+	if user.Role == "ADMIN" {
+		return true
 	}
+	if dashboard.LockedBy != nil {
+		return *dashboard.LockedBy == user.Email
+	}
+	return canManageDashboard(dashboard, user)
+}
+
+func lockedBy(dashboard *Dashboard) string {
+	if dashboard.LockedBy == nil {
+		return "its owner"
+	}
+	return *dashboard.LockedBy
+}
 
-	_, err := db.Exec(query, uuid)
+func setDashboardLock(ctx context.Context, dashboard *Dashboard, lock bool, summary string) *model.ApiError {
+	var err error
+	action := AuditActionUnlock
+	if lock {
+		var lockedBy string
+		if user := common.GetUserFromContext(ctx); user != nil {
+			lockedBy = user.Email
+		}
+		_, err = db.Exec(`UPDATE dashboards SET locked=1, locked_by=?, locked_at=? WHERE uuid=?;`, lockedBy, time.Now(), dashboard.Uuid)
+		action = AuditActionLock
+	} else {
+		_, err = db.Exec(`UPDATE dashboards SET locked=0, locked_by=NULL, locked_at=NULL WHERE uuid=?;`, dashboard.Uuid)
+	}
 
 	if err != nil {
-		zap.L().Error("Error in updating dashboard", zap.String("uuid", uuid), zap.Error(err))
+		zap.L().Error("Error in updating dashboard", zap.String("uuid", dashboard.Uuid), zap.Error(err))
 		return &model.ApiError{Typ: model.ErrorExec, Err: err}
 	}
+	recordAudit(ctx, db, dashboard.Uuid, action, summary)
+	notifyWebhooks(ctx, dashboard.Uuid, action, dashboard.Version)
 
 	return nil
 }
@@ -252,6 +781,27 @@ func (d *Dashboard) UpdateSlug() {
 	d.Slug = SlugifyTitle(title)
 }
 
+func errProvisioned(dashboard *Dashboard) *model.ApiError {
+	return model.BadRequest(fmt.Errorf("dashboard is provisioned from the file %s, change the file to change the dashboard", *dashboard.ProvisionedFrom))
+}
+
+func versionConflict() *model.ApiError {
+	return &model.ApiError{
+		Typ: model.ErrorConflict,
+		Err: fmt.Errorf("dashboard has been updated by someone else, reload the dashboard and try again"),
+	}
+}
+
+// PanelDeletionError is returned when an update removes more than one widget
+// without being forced
+type PanelDeletionError struct {
+	WidgetIds []string
+}
+
+func (e *PanelDeletionError) Error() string {
+	return fmt.Sprintf("deleting more than one panel requires confirmation, the update removes %d panels", len(e.WidgetIds))
+}
+
 func IsPostDataSane(data *map[string]interface{}) error {
 	val, ok := (*data)["title"]
 	if !ok || val == nil {
@@ -325,13 +875,16 @@ func getIdDifference(existingIds []string, newIds []string) []string {
 	return difference
 }
 
-// GetDashboardsInfo returns analytics data for dashboards
+// GetDashboardsInfo returns analytics data for dashboards. The counts are
+// added up from the metadata and usage columns kept up to date on write, so
+// the data of the dashboards is not decoded.
 func GetDashboardsInfo(ctx context.Context) (*model.DashboardsInfo, error) {
 	dashboardsInfo := model.DashboardsInfo{}
 	// fetch dashboards from dashboard db
-	query := "SELECT data FROM dashboards"
-	var dashboardsData []Dashboard
-	err := db.Select(&dashboardsData, query)
+	scope, args := orgScope(ctx)
+	query := "SELECT title, panel_count, usage_counts FROM dashboards WHERE deleted_at IS NULL AND archived_at IS NULL" + scope
+	var dashboardsMetadata []Dashboard
+	err := db.Select(&dashboardsMetadata, query, args...)
 	if err != nil {
 		zap.L().Error("Error in processing sql query", zap.Error(err))
 		return &dashboardsInfo, err
@@ -340,38 +893,42 @@ func GetDashboardsInfo(ctx context.Context) (*model.DashboardsInfo, error) {
 	var dashboardNames []string
 	count := 0
 	queriesWithTagAttrs := 0
-	for _, dashboard := range dashboardsData {
-		if isDashboardWithPanelAndName(dashboard.Data) {
+	for _, dashboard := range dashboardsMetadata {
+		hasPanels := dashboard.PanelCount != nil && *dashboard.PanelCount > 0
+		if hasPanels && dashboard.Title != "" && dashboard.Title != "Sample Title" {
 			totalDashboardsWithPanelAndName = totalDashboardsWithPanelAndName + 1
 		}
-		dashboardName := extractDashboardName(dashboard.Data)
-		if dashboardName != "" {
-			dashboardNames = append(dashboardNames, dashboardName)
+		if dashboard.Title != "" {
+			dashboardNames = append(dashboardNames, dashboard.Title)
 		}
-		dashboardInfo := countPanelsInDashboard(dashboard.Data)
-		dashboardsInfo.LogsBasedPanels += dashboardInfo.LogsBasedPanels
-		dashboardsInfo.TracesBasedPanels += dashboardInfo.TracesBasedPanels
-		dashboardsInfo.MetricBasedPanels += dashboardInfo.MetricBasedPanels
-		dashboardsInfo.LogsPanelsWithAttrContainsOp += dashboardInfo.LogsPanelsWithAttrContainsOp
-		dashboardsInfo.DashboardsWithLogsChQuery += dashboardInfo.DashboardsWithLogsChQuery
-		dashboardsInfo.DashboardsWithTraceChQuery += dashboardInfo.DashboardsWithTraceChQuery
-		if isDashboardWithTSV2(dashboard.Data) {
+
+		// the usage of dashboards stored before it was counted is backfilled
+		// on start
+		if dashboard.Usage == nil {
+			continue
+		}
+		usage := dashboard.Usage
+		dashboardsInfo.LogsBasedPanels += usage.LogsPanels
+		dashboardsInfo.TracesBasedPanels += usage.TracesPanels
+		dashboardsInfo.MetricBasedPanels += usage.MetricsPanels
+		dashboardsInfo.LogsPanelsWithAttrContainsOp += usage.LogsPanelsWithAttrContains
+		if usage.LogsChQuery {
+			dashboardsInfo.DashboardsWithLogsChQuery++
+		}
+		if usage.TracesChQuery {
+			dashboardsInfo.DashboardsWithTraceChQuery++
+			dashboardsInfo.DashboardNamesWithTraceChQuery = append(dashboardsInfo.DashboardNamesWithTraceChQuery, dashboard.Title)
+		}
+		if usage.TSV2 {
 			count = count + 1
 		}
-
-		if isDashboardWithTagAttrs(dashboard.Data) {
+		if usage.TagAttrs {
 			queriesWithTagAttrs += 1
 		}
-
-		if dashboardInfo.DashboardsWithTraceChQuery > 0 {
-			dashboardsInfo.DashboardNamesWithTraceChQuery = append(dashboardsInfo.DashboardNamesWithTraceChQuery, dashboardName)
-		}
-
-		// check if dashboard is a has a log operator with contains
 	}
 
 	dashboardsInfo.DashboardNames = dashboardNames
-	dashboardsInfo.TotalDashboards = len(dashboardsData)
+	dashboardsInfo.TotalDashboards = len(dashboardsMetadata)
 	dashboardsInfo.TotalDashboardsWithPanelAndName = totalDashboardsWithPanelAndName
 	dashboardsInfo.QueriesWithTSV2 = count
 	dashboardsInfo.QueriesWithTagAttrs = queriesWithTagAttrs
@@ -419,26 +976,6 @@ func isDashboardWithTracesClickhouseQuery(data map[string]interface{}) bool {
 	return result
 }
 
-func isDashboardWithPanelAndName(data map[string]interface{}) bool {
-	isDashboardName := false
-	isDashboardWithPanelAndName := false
-	if data != nil && data["title"] != nil && data["widgets"] != nil {
-		title, ok := data["title"].(string)
-		if ok && title != "Sample Title" {
-			isDashboardName = true
-		}
-		widgets, ok := data["widgets"]
-		if ok && isDashboardName {
-			data, ok := widgets.([]interface{})
-			if ok && len(data) > 0 {
-				isDashboardWithPanelAndName = true
-			}
-		}
-	}
-
-	return isDashboardWithPanelAndName
-}
-
 func extractDashboardName(data map[string]interface{}) string {
 
 	if data != nil && data["title"] != nil {
@@ -539,94 +1076,34 @@ func countPanelsInDashboard(inputData map[string]interface{}) model.DashboardsIn
 }
 
 func GetDashboardsWithMetricNames(ctx context.Context, metricNames []string) (map[string][]map[string]string, *model.ApiError) {
-	// Get all dashboards first
-	query := `SELECT uuid, data FROM dashboards`
+	result := make(map[string][]map[string]string)
+	if len(metricNames) == 0 {
+		return result, nil
+	}
 
-	type dashboardRow struct {
-		Uuid string          `db:"uuid"`
-		Data json.RawMessage `db:"data"`
+	// the metric index is kept up to date on every change of a dashboard
+	scope, scopeArgs := orgScope(ctx)
+	query, args, err := sqlx.In(`SELECT i.* FROM dashboard_metric_index i JOIN dashboards d ON d.uuid = i.dashboard_uuid
+		WHERE d.deleted_at IS NULL AND i.metric_name IN (?)`+scope, metricNames)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
 	}
+	args = append(args, scopeArgs...)
 
-	var dashboards []dashboardRow
-	err := db.Select(&dashboards, query)
+	var rows []metricIndexRow
+	err = db.Select(&rows, query, args...)
 	if err != nil {
 		zap.L().Error("Error in getting dashboards", zap.Error(err))
 		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
 	}
 
-	// Initialize result map for each metric
-	result := make(map[string][]map[string]string)
-	// for _, metricName := range metricNames {
-	// 	result[metricName] = []map[string]string{}
-	// }
-
-	// Process the JSON data in Go
-	for _, dashboard := range dashboards {
-		var dashData map[string]interface{}
-		if err := json.Unmarshal(dashboard.Data, &dashData); err != nil {
-			continue
-		}
-
-		dashTitle, _ := dashData["title"].(string)
-		widgets, ok := dashData["widgets"].([]interface{})
-		if !ok {
-			continue
-		}
-
-		for _, w := range widgets {
-			widget, ok := w.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			widgetTitle, _ := widget["title"].(string)
-			widgetID, _ := widget["id"].(string)
-
-			query, ok := widget["query"].(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			builder, ok := query["builder"].(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			queryData, ok := builder["queryData"].([]interface{})
-			if !ok {
-				continue
-			}
-
-			for _, qd := range queryData {
-				data, ok := qd.(map[string]interface{})
-				if !ok {
-					continue
-				}
-
-				if dataSource, ok := data["dataSource"].(string); !ok || dataSource != "metrics" {
-					continue
-				}
-
-				aggregateAttr, ok := data["aggregateAttribute"].(map[string]interface{})
-				if !ok {
-					continue
-				}
-
-				if key, ok := aggregateAttr["key"].(string); ok {
-					// Check if this metric is in our list of interest
-					for _, metricName := range metricNames {
-						if strings.TrimSpace(key) == metricName {
-							result[metricName] = append(result[metricName], map[string]string{
-								"dashboard_id":    dashboard.Uuid,
-								"widget_title":    widgetTitle,
-								"widget_id":       widgetID,
-								"dashboard_title": dashTitle,
-							})
-						}
-					}
-				}
-			}
-		}
+	for _, row := range rows {
+		result[row.MetricName] = append(result[row.MetricName], map[string]string{
+			"dashboard_id":    row.DashboardUuid,
+			"widget_title":    row.WidgetTitle,
+			"widget_id":       row.WidgetId,
+			"dashboard_title": row.DashboardTitle,
+		})
 	}
 
 	return result, nil
@@ -0,0 +1,456 @@
+package dashboards_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func contextWithUser(email string) context.Context {
+	return context.WithValue(context.Background(), constants.ContextUserKey, &model.UserPayload{
+		User: model.User{Id: email, Email: email},
+		Role: "ADMIN",
+	})
+}
+
+func TestGetMyDashboards(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+
+	aliceCtx := contextWithUser("alice@signoz.io")
+	bobCtx := contextWithUser("bob@signoz.io")
+
+	_, apiErr := dashboards.CreateDashboard(aliceCtx, map[string]interface{}{"title": "alice's dashboard"}, nil)
+	require.Nil(apiErr)
+	_, apiErr = dashboards.CreateDashboard(bobCtx, map[string]interface{}{"title": "bob's dashboard"}, nil)
+	require.Nil(apiErr)
+
+	mine, apiErr := dashboards.GetMyDashboards(aliceCtx)
+	require.Nil(apiErr)
+	require.Len(mine, 1)
+	require.Equal("alice's dashboard", mine[0].Data["title"])
+	require.Equal("alice@signoz.io", *mine[0].Owner)
+
+	_, apiErr = dashboards.GetMyDashboards(context.Background())
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorUnauthorized, apiErr.Type())
+}
+
+func TestDashboardUsersStoredById(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := context.WithValue(context.Background(), constants.ContextUserKey, &model.UserPayload{
+		User: model.User{Id: "5f1c0c1e", Email: "alice@signoz.io"},
+		Role: "ADMIN",
+	})
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "kafka"}, nil)
+	require.Nil(apiErr)
+	require.Equal("5f1c0c1e", *dashboard.CreateBy)
+	require.Equal("alice@signoz.io", *dashboard.Owner)
+
+	updated, apiErr := dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "kafka lag"}, false, 0, nil)
+	require.Nil(apiErr)
+	require.Equal("5f1c0c1e", *updated.UpdateBy)
+
+	mine, apiErr := dashboards.GetMyDashboards(ctx)
+	require.Nil(apiErr)
+	require.Len(mine, 1)
+}
+
+func TestListDashboards(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	for _, title := range []string{"Kafka lag", "Postgres", "kafka brokers"} {
+		_, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": title}, nil)
+		require.Nil(apiErr)
+	}
+
+	titles := func(list []dashboards.Dashboard) []string {
+		result := []string{}
+		for _, dashboard := range list {
+			result = append(result, dashboard.Data["title"].(string))
+		}
+		return result
+	}
+
+	list, apiErr := dashboards.ListDashboards(ctx, dashboards.ListDashboardsParams{Title: "kafka", OrderBy: "title", Order: "desc"})
+	require.Nil(apiErr)
+	require.Equal([]string{"kafka brokers", "Kafka lag"}, titles(list))
+
+	list, apiErr = dashboards.ListDashboards(ctx, dashboards.ListDashboardsParams{OrderBy: "title", Limit: 2, Offset: 1})
+	require.Nil(apiErr)
+	require.Equal([]string{"Postgres", "kafka brokers"}, titles(list))
+
+	_, apiErr = dashboards.ListDashboards(ctx, dashboards.ListDashboardsParams{OrderBy: "data"})
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+}
+
+func TestDuplicateDashboard(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+
+	original, apiErr := dashboards.CreateDashboard(contextWithUser("alice@signoz.io"), map[string]interface{}{
+		"uuid":    "2cb1b4d4-59b0-4e4c-9b6b-1a2e6f8a1c3d",
+		"title":   "kafka",
+		"widgets": []interface{}{map[string]interface{}{"id": "a", "title": "lag"}},
+	}, nil)
+	require.Nil(apiErr)
+
+	copied, apiErr := dashboards.DuplicateDashboard(contextWithUser("bob@signoz.io"), original.Uuid, nil)
+	require.Nil(apiErr)
+	require.NotEqual(original.Uuid, copied.Uuid)
+	require.Equal("kafka (copy)", copied.Data["title"])
+	require.Equal("bob@signoz.io", *copied.CreateBy)
+	require.Equal("bob@signoz.io", *copied.UpdateBy)
+	require.Equal(original.Data["widgets"], copied.Data["widgets"])
+
+	// the original is left untouched
+	stored, apiErr := dashboards.GetDashboard(context.Background(), original.Uuid)
+	require.Nil(apiErr)
+	require.Equal("kafka", stored.Data["title"])
+}
+
+func TestUpdateDashboardRemovingPanels(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	widget := func(id string) map[string]interface{} {
+		return map[string]interface{}{"id": id, "query": map[string]interface{}{}}
+	}
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title":   "kafka",
+		"widgets": []interface{}{widget("a"), widget("b"), widget("c")},
+	}, nil)
+	require.Nil(apiErr)
+
+	updated, apiErr := dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{
+		"title":   "kafka",
+		"widgets": []interface{}{widget("a"), widget("b")},
+	}, false, 0, nil)
+	require.Nil(apiErr)
+	require.Equal([]string{"c"}, updated.RemovedWidgets)
+
+	_, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "kafka"}, false, 0, nil)
+	require.NotNil(apiErr)
+	var deletionErr *dashboards.PanelDeletionError
+	require.True(errors.As(apiErr.Err, &deletionErr))
+	require.Equal([]string{"a", "b"}, deletionErr.WidgetIds)
+
+	updated, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "kafka"}, true, 0, nil)
+	require.Nil(apiErr)
+	require.Equal([]string{"a", "b"}, updated.RemovedWidgets)
+}
+
+func TestUpdateDashboardVersionConflict(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "kafka"}, nil)
+	require.Nil(apiErr)
+	require.Equal(1, dashboard.Version)
+
+	// alice and bob both start editing version 1, bob saves first
+	updated, apiErr := dashboards.UpdateDashboard(contextWithUser("bob@signoz.io"), dashboard.Uuid, map[string]interface{}{"title": "bob's kafka"}, false, 1, nil)
+	require.Nil(apiErr)
+	require.Equal(2, updated.Version)
+
+	_, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "alice's kafka"}, false, 1, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorConflict, apiErr.Typ)
+
+	stored, apiErr := dashboards.GetDashboard(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal("bob's kafka", stored.Data["title"])
+	require.Equal(2, stored.Version)
+
+	// without an expected version the update always applies
+	updated, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "alice's kafka"}, false, 0, nil)
+	require.Nil(apiErr)
+	require.Equal(3, updated.Version)
+}
+
+func TestUpdateDashboardIsAtomic(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "kafka"}, nil)
+	require.Nil(apiErr)
+
+	// failing to index the dashboard rolls back the whole update
+	_, err := sqlStore.SQLxDB().Exec(`DROP TABLE dashboard_services`)
+	require.NoError(err)
+	_, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "kafka lag"}, false, 0, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorExec, apiErr.Type())
+
+	stored, apiErr := dashboards.GetDashboard(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal("kafka", stored.Data["title"])
+	require.Equal(1, stored.Version)
+
+	entries, apiErr := dashboards.GetDashboardAudit(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Len(entries, 1)
+	require.Equal(dashboards.AuditActionCreate, entries[0].Action)
+}
+
+func TestRecordDashboardView(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	viewed, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "viewed"}, nil)
+	require.Nil(apiErr)
+	unused, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "unused"}, nil)
+	require.Nil(apiErr)
+	require.Equal(0, viewed.ViewCount)
+	require.Nil(viewed.LastViewedAt)
+
+	dashboards.RecordDashboardView(ctx, viewed.Uuid)
+	dashboards.RecordDashboardView(ctx, viewed.Uuid)
+	// views without a user are not counted
+	dashboards.RecordDashboardView(context.Background(), viewed.Uuid)
+
+	list, apiErr := dashboards.ListDashboards(ctx, dashboards.ListDashboardsParams{OrderBy: "view_count", Order: "desc"})
+	require.Nil(apiErr)
+	require.Len(list, 2)
+	require.Equal(viewed.Uuid, list[0].Uuid)
+	require.Equal(2, list[0].ViewCount)
+	require.NotNil(list[0].LastViewedAt)
+	require.Equal(unused.Uuid, list[1].Uuid)
+	require.Equal(0, list[1].ViewCount)
+	require.Nil(list[1].LastViewedAt)
+}
+
+func TestLockOwnership(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+
+	aliceCtx := contextWithUser("alice@signoz.io")
+	bobCtx := context.WithValue(context.Background(), constants.ContextUserKey, &model.UserPayload{
+		User: model.User{Id: "bob@signoz.io", Email: "bob@signoz.io"},
+		Role: "EDITOR",
+	})
+
+	dashboard, apiErr := dashboards.CreateDashboard(bobCtx, map[string]interface{}{"title": "kafka"}, nil)
+	require.Nil(apiErr)
+
+	require.Nil(dashboards.LockUnlockDashboard(aliceCtx, dashboard.Uuid, true))
+	locked, apiErr := dashboards.GetDashboard(aliceCtx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal("alice@signoz.io", *locked.LockedBy)
+	require.NotNil(locked.LockedAt)
+
+	// the author cannot unlock a dashboard somebody else locked
+	apiErr = dashboards.LockUnlockDashboard(bobCtx, dashboard.Uuid, false)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorForbidden, apiErr.Type())
+
+	require.Nil(dashboards.ForceUnlockDashboard(contextWithUser("admin@signoz.io"), dashboard.Uuid))
+	unlocked, apiErr := dashboards.GetDashboard(aliceCtx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal(0, *unlocked.Locked)
+	require.Nil(unlocked.LockedBy)
+	require.Nil(unlocked.LockedAt)
+
+	entries, apiErr := dashboards.GetDashboardAudit(aliceCtx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal("force unlocked dashboard locked by alice@signoz.io", entries[0].Summary)
+}
+
+func TestDestructiveOperationsRestrictedToOwner(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+
+	editorCtx := func(email string) context.Context {
+		return context.WithValue(context.Background(), constants.ContextUserKey, &model.UserPayload{
+			User: model.User{Id: email, Email: email},
+			Role: "EDITOR",
+		})
+	}
+	aliceCtx, bobCtx := editorCtx("alice@signoz.io"), editorCtx("bob@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(aliceCtx, map[string]interface{}{"title": "kafka"}, nil)
+	require.Nil(apiErr)
+	require.Equal("alice@signoz.io", *dashboard.Owner)
+
+	// editors who do not own the dashboard can change it but not delete or lock it
+	_, apiErr = dashboards.UpdateDashboard(bobCtx, dashboard.Uuid, map[string]interface{}{"title": "kafka lag"}, false, 0, nil)
+	require.Nil(apiErr)
+
+	apiErr = dashboards.LockUnlockDashboard(bobCtx, dashboard.Uuid, true)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorForbidden, apiErr.Type())
+
+	apiErr = dashboards.DeleteDashboard(bobCtx, dashboard.Uuid, false, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorForbidden, apiErr.Type())
+
+	require.Nil(dashboards.DeleteDashboard(contextWithUser("admin@signoz.io"), dashboard.Uuid, false, nil))
+
+	owned, apiErr := dashboards.CreateDashboard(aliceCtx, map[string]interface{}{"title": "redis"}, nil)
+	require.Nil(apiErr)
+	require.Nil(dashboards.LockUnlockDashboard(aliceCtx, owned.Uuid, true))
+	require.Nil(dashboards.LockUnlockDashboard(aliceCtx, owned.Uuid, false))
+	require.Nil(dashboards.DeleteDashboard(aliceCtx, owned.Uuid, false, nil))
+}
+
+func TestOrgScopedDashboards(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+
+	orgCtx := func(email, orgId string) context.Context {
+		return context.WithValue(context.Background(), constants.ContextUserKey, &model.UserPayload{
+			User: model.User{Id: email, Email: email, OrgId: orgId},
+			Role: "ADMIN",
+		})
+	}
+	aliceCtx := orgCtx("alice@acme.io", "acme")
+	bobCtx := orgCtx("bob@globex.io", "globex")
+
+	acme, apiErr := dashboards.CreateDashboard(aliceCtx, map[string]interface{}{"title": "acme"}, nil)
+	require.Nil(apiErr)
+	require.Equal("acme", *acme.OrgId)
+	_, apiErr = dashboards.CreateDashboard(bobCtx, map[string]interface{}{"title": "globex"}, nil)
+	require.Nil(apiErr)
+	// dashboards created without a user have no org and are seen by no org
+	unassigned, apiErr := dashboards.CreateDashboard(context.Background(), map[string]interface{}{"title": "shared"}, nil)
+	require.Nil(apiErr)
+
+	list, apiErr := dashboards.GetDashboards(aliceCtx)
+	require.Nil(apiErr)
+	titles := []string{}
+	for _, dashboard := range list {
+		titles = append(titles, dashboard.Data["title"].(string))
+	}
+	require.ElementsMatch([]string{"acme"}, titles)
+
+	_, apiErr = dashboards.GetDashboard(bobCtx, acme.Uuid)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+	_, apiErr = dashboards.UpdateDashboard(bobCtx, acme.Uuid, map[string]interface{}{"title": "mine"}, false, 0, nil)
+	require.NotNil(apiErr)
+	require.NotNil(dashboards.DeleteDashboard(bobCtx, acme.Uuid, false, nil))
+
+	_, apiErr = dashboards.GetDashboard(bobCtx, unassigned.Uuid)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+
+	// internal callers see the dashboards of all orgs
+	all, apiErr := dashboards.GetDashboards(context.Background())
+	require.Nil(apiErr)
+	require.Len(all, 3)
+}
+
+func TestOrgScopedDashboardSideTables(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+
+	orgCtx := func(email, orgId string) context.Context {
+		return context.WithValue(context.Background(), constants.ContextUserKey, &model.UserPayload{
+			User: model.User{Id: email, Email: email, OrgId: orgId},
+			Role: "ADMIN",
+		})
+	}
+	aliceCtx := orgCtx("alice@acme.io", "acme")
+	bobCtx := orgCtx("bob@globex.io", "globex")
+
+	acme, apiErr := dashboards.CreateDashboard(aliceCtx, map[string]interface{}{"title": "acme"}, nil)
+	require.Nil(apiErr)
+
+	share, apiErr := dashboards.CreateDashboardShare(aliceCtx, acme.Uuid, nil, "secret")
+	require.Nil(apiErr)
+	snapshot, apiErr := dashboards.CreateDashboardSnapshot(aliceCtx, acme.Uuid, 1000, 2000, func(dashboards.Data, string) (interface{}, error) {
+		return nil, nil
+	})
+	require.Nil(apiErr)
+	schedule, apiErr := dashboards.CreateReportSchedule(aliceCtx, acme.Uuid, dashboards.ReportSchedulePostData{
+		Cron: "0 9 * * 1", Recipients: []string{"a@acme.io"}, TimeRange: "24h",
+	})
+	require.Nil(apiErr)
+
+	notFound := func(apiErr *model.ApiError) {
+		require.NotNil(apiErr)
+		require.Equal(model.ErrorNotFound, apiErr.Type())
+	}
+
+	_, apiErr = dashboards.GetDashboardShares(bobCtx, acme.Uuid, "secret")
+	notFound(apiErr)
+	notFound(dashboards.RevokeDashboardShare(bobCtx, acme.Uuid, share.Id))
+	_, apiErr = dashboards.GetDashboardSnapshots(bobCtx, acme.Uuid)
+	notFound(apiErr)
+	_, apiErr = dashboards.GetDashboardSnapshot(bobCtx, snapshot.Id)
+	notFound(apiErr)
+	notFound(dashboards.DeleteDashboardSnapshot(bobCtx, snapshot.Id))
+	_, apiErr = dashboards.GetReportSchedules(bobCtx, acme.Uuid)
+	notFound(apiErr)
+	_, apiErr = dashboards.GetReportSchedule(bobCtx, schedule.Id)
+	notFound(apiErr)
+	notFound(dashboards.DeleteReportSchedule(bobCtx, schedule.Id))
+	_, apiErr = dashboards.GetDashboardAudit(bobCtx, acme.Uuid)
+	notFound(apiErr)
+
+	// the audit log outlives the dashboard and stays with its org
+	require.Nil(dashboards.DeleteDashboard(aliceCtx, acme.Uuid, true, nil))
+	entries, apiErr := dashboards.GetDashboardAudit(aliceCtx, acme.Uuid)
+	require.Nil(apiErr)
+	require.NotEmpty(entries)
+	_, apiErr = dashboards.GetDashboardAudit(bobCtx, acme.Uuid)
+	notFound(apiErr)
+}
+
+func TestOrgScopedFoldersAndWebhooks(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+
+	orgCtx := func(email, orgId string) context.Context {
+		return context.WithValue(context.Background(), constants.ContextUserKey, &model.UserPayload{
+			User: model.User{Id: email, Email: email, OrgId: orgId},
+			Role: "ADMIN",
+		})
+	}
+	aliceCtx := orgCtx("alice@acme.io", "acme")
+	bobCtx := orgCtx("bob@globex.io", "globex")
+
+	folder, apiErr := dashboards.CreateFolder(aliceCtx, dashboards.FolderPostData{Name: "infra"})
+	require.Nil(apiErr)
+	require.Equal("acme", *folder.OrgId)
+	globex, apiErr := dashboards.CreateDashboard(bobCtx, map[string]interface{}{"title": "globex"}, nil)
+	require.Nil(apiErr)
+
+	folders, apiErr := dashboards.GetFolders(bobCtx)
+	require.Nil(apiErr)
+	require.Empty(folders)
+	_, apiErr = dashboards.GetFolder(bobCtx, folder.Uuid)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+	_, apiErr = dashboards.UpdateFolder(bobCtx, folder.Uuid, dashboards.FolderPostData{Name: "mine"})
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+	require.Equal(model.ErrorNotFound, dashboards.DeleteFolder(bobCtx, folder.Uuid).Type())
+	// a dashboard cannot be moved into the folder of another org
+	require.Equal(model.ErrorNotFound, dashboards.MoveDashboard(bobCtx, globex.Uuid, &folder.Uuid).Type())
+
+	folders, apiErr = dashboards.GetFolders(aliceCtx)
+	require.Nil(apiErr)
+	require.Len(folders, 1)
+
+	webhook, apiErr := dashboards.CreateDashboardWebhook(aliceCtx, dashboards.DashboardWebhookPostData{Url: "https://hooks.acme.io"})
+	require.Nil(apiErr)
+	webhooks, apiErr := dashboards.GetDashboardWebhooks(bobCtx)
+	require.Nil(apiErr)
+	require.Empty(webhooks)
+	require.Equal(model.ErrorNotFound, dashboards.DeleteDashboardWebhook(bobCtx, webhook.Id).Type())
+	require.Nil(dashboards.DeleteDashboardWebhook(aliceCtx, webhook.Id))
+}
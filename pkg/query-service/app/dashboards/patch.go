@@ -0,0 +1,47 @@
+package dashboards
+
+import (
+	"context"
+
+	"go.signoz.io/signoz/pkg/query-service/interfaces"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+// PatchDashboard applies a JSON merge patch (RFC 7386) to the data of the
+// dashboard and saves the result like UpdateDashboard does. Fields set to null
+// in the patch are removed, objects are merged and any other value replaces
+// the stored one.
+func PatchDashboard(ctx context.Context, uuid string, patch map[string]interface{}, force bool, expectedVersion int, fm interfaces.FeatureLookup) (*Dashboard, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if expectedVersion != 0 && expectedVersion != dashboard.Version {
+		return nil, versionConflict()
+	}
+
+	data := mergePatch(map[string]interface{}(dashboard.Data), patch).(map[string]interface{})
+
+	// the patch applies to the version read above, so the update conflicts if
+	// the dashboard is saved in between
+	return UpdateDashboard(ctx, uuid, data, force, dashboard.Version, fm)
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
@@ -0,0 +1,58 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestPatchDashboard(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title":       "Kafka",
+		"description": "brokers and consumers",
+		"widgets": []interface{}{
+			map[string]interface{}{"id": "lag", "title": "Consumer lag", "thresholds": []interface{}{
+				map[string]interface{}{"thresholdOperator": ">", "thresholdValue": 100.0, "thresholdUnit": "ms"},
+			}},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	patched, apiErr := dashboards.PatchDashboard(ctx, dashboard.Uuid, map[string]interface{}{
+		"title":       "Kafka lag",
+		"description": nil,
+		"message":     "rename",
+	}, false, 0, nil)
+	require.Nil(apiErr)
+	require.Equal(2, patched.Version)
+	require.Equal("Kafka lag", patched.Data["title"])
+	require.NotContains(patched.Data, "description")
+	require.NotContains(patched.Data, "message")
+	require.Len(patched.Data["widgets"], 1)
+
+	stored, apiErr := dashboards.GetDashboard(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal(patched.Data["title"], stored.Data["title"])
+	require.Equal(patched.Data["widgets"], stored.Data["widgets"])
+
+	versions, apiErr := dashboards.GetDashboardVersions(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal("rename", versions[0].Message)
+
+	// the patched data is validated like a full update
+	_, apiErr = dashboards.PatchDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": 1}, false, 0, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	// patching an outdated version conflicts
+	_, apiErr = dashboards.PatchDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "Kafka"}, false, 1, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorConflict, apiErr.Type())
+}
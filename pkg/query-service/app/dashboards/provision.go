@@ -5,81 +5,221 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 
-	"go.signoz.io/signoz/pkg/query-service/constants"
 	"go.signoz.io/signoz/pkg/query-service/interfaces"
 	"go.signoz.io/signoz/pkg/query-service/model"
 )
 
-func readCurrentDir(dir string, fm interfaces.FeatureLookup) error {
-	file, err := os.Open(dir)
+// DashboardProvisioner provisions the dashboards defined in the json and yaml
+// files of a directory when it starts and whenever a file changes. Dashboards
+// kept in git can be provisioned by syncing the repository into the directory,
+// e.g. with git-sync. Provisioned dashboards are read only in the api.
+type DashboardProvisioner struct {
+	dir      string
+	interval time.Duration
+	fm       interfaces.FeatureLookup
+	modTimes map[string]time.Time
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewDashboardProvisioner returns a provisioner checking the directory for
+// changes every interval, or only once on start if the interval is 0
+func NewDashboardProvisioner(dir string, interval time.Duration, fm interfaces.FeatureLookup) *DashboardProvisioner {
+	return &DashboardProvisioner{
+		dir:      dir,
+		interval: interval,
+		fm:       fm,
+		modTimes: map[string]time.Time{},
+		done:     make(chan struct{}),
+	}
+}
+
+func (p *DashboardProvisioner) Start() {
+	p.provisionChangedFiles()
+	if p.interval <= 0 {
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				p.provisionChangedFiles()
+			}
+		}
+	}()
+}
+
+func (p *DashboardProvisioner) Stop() {
+	close(p.done)
+	p.wg.Wait()
+}
+
+// provisionChangedFiles provisions the files modified since they were last
+// provisioned
+func (p *DashboardProvisioner) provisionChangedFiles() {
+	defer assignProvisionedDashboards()
+
+	entries, err := os.ReadDir(p.dir)
 	if err != nil {
 		zap.L().Warn("failed opening directory", zap.Error(err))
-		return nil
+		return
 	}
-	defer file.Close()
 
-	list, _ := file.Readdirnames(0) // 0 to read all files and folders
-	for _, filename := range list {
-		if strings.ToLower(filepath.Ext(filename)) != ".json" {
-			zap.L().Debug("Skipping non-json file", zap.String("filename", filename))
+	for _, entry := range entries {
+		filename := entry.Name()
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".json", ".yaml", ".yml":
+		default:
+			zap.L().Debug("Skipping non-dashboard file", zap.String("filename", filename))
 			continue
 		}
-		zap.L().Info("Provisioning dashboard: ", zap.String("filename", filename))
 
-		// using filepath.Join for platform specific path creation
-		// which is equivalent to "dir+/+filename" (on unix based systems) but cleaner
-		plan, err := os.ReadFile(filepath.Join(dir, filename))
+		info, err := entry.Info()
 		if err != nil {
-			zap.L().Error("Creating Dashboards: Error in reading json fron file", zap.String("filename", filename), zap.Error(err))
 			continue
 		}
-		var data map[string]interface{}
-		err = json.Unmarshal(plan, &data)
-		if err != nil {
-			zap.L().Error("Creating Dashboards: Error in unmarshalling json from file", zap.String("filename", filename), zap.Error(err))
-			continue
-		}
-		err = IsPostDataSane(&data)
-		if err != nil {
-			zap.L().Info("Creating Dashboards: Error in file", zap.String("filename", filename), zap.Error(err))
+		if modTime, ok := p.modTimes[filename]; ok && modTime.Equal(info.ModTime()) {
 			continue
 		}
+		p.modTimes[filename] = info.ModTime()
 
-		id := data["uuid"]
-		if id == nil {
-			_, apiErr := CreateDashboard(context.Background(), data, fm)
-			if apiErr != nil {
-				zap.L().Error("Creating Dashboards: Error in file", zap.String("filename", filename), zap.Error(apiErr.Err))
-			}
-			continue
+		zap.L().Info("Provisioning dashboard: ", zap.String("filename", filename))
+		provisionFile(p.dir, filename, p.fm)
+	}
+}
+
+func provisionFile(dir string, filename string, fm interfaces.FeatureLookup) {
+	// using filepath.Join for platform specific path creation
+	// which is equivalent to "dir+/+filename" (on unix based systems) but cleaner
+	plan, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		zap.L().Error("Creating Dashboards: Error in reading file", zap.String("filename", filename), zap.Error(err))
+		return
+	}
+
+	data, err := parseDashboardFile(filename, plan)
+	if err != nil {
+		zap.L().Error("Creating Dashboards: Error in unmarshalling file", zap.String("filename", filename), zap.Error(err))
+		return
+	}
+	err = IsPostDataSane(&data)
+	if err != nil {
+		zap.L().Info("Creating Dashboards: Error in file", zap.String("filename", filename), zap.Error(err))
+		return
+	}
+
+	// dashboards without a uuid are matched with the file they were provisioned from
+	uuid, _ := data["uuid"].(string)
+	if uuid == "" {
+		if err := db.Get(&uuid, `SELECT uuid FROM dashboards WHERE provisioned_from=? AND deleted_at IS NULL`, filename); err != nil {
+			uuid = ""
 		}
+	}
 
-		apiErr := upsertDashboard(id.(string), data, filename, fm)
-		if apiErr != nil {
-			zap.L().Error("Creating Dashboards: Error upserting dashboard", zap.String("filename", filename), zap.Error(apiErr.Err))
+	apiErr := upsertDashboard(uuid, data, filename, fm)
+	if apiErr != nil {
+		zap.L().Error("Creating Dashboards: Error upserting dashboard", zap.String("filename", filename), zap.Error(apiErr.Err))
+	}
+}
+
+// parseDashboardFile decodes a json or yaml dashboard, yaml values are
+// converted to their json types
+func parseDashboardFile(filename string, content []byte) (map[string]interface{}, error) {
+	if ext := strings.ToLower(filepath.Ext(filename)); ext == ".yaml" || ext == ".yml" {
+		var yamlData map[string]interface{}
+		if err := yaml.Unmarshal(content, &yamlData); err != nil {
+			return nil, err
+		}
+		var err error
+		if content, err = json.Marshal(yamlData); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
 }
 
 func upsertDashboard(uuid string, data map[string]interface{}, filename string, fm interfaces.FeatureLookup) *model.ApiError {
-	_, apiErr := GetDashboard(context.Background(), uuid)
-	if apiErr == nil {
-		zap.S().Infof("Creating Dashboards: Already exists: %s\t%s", filename, "Dashboard already present in database, Updating dashboard")
-		_, apiErr := UpdateDashboard(context.Background(), uuid, data, fm)
-		return apiErr
+	if uuid != "" {
+		existing, apiErr := GetDashboard(context.Background(), uuid)
+		if apiErr == nil && existing.ProvisionedFrom != nil && *existing.ProvisionedFrom == filename && sameDashboardData(existing.Data, data) {
+			return nil
+		}
+
+		if _, apiErr := getDeletedDashboard(context.Background(), uuid); apiErr == nil {
+			zap.S().Infof("Creating Dashboards: Skipping: %s\t%s", filename, "Dashboard is in the trash, restore it to provision it again")
+			return nil
+		}
+
+		// the file is the source of truth, panels removed from it are removed
+		dashboard, created, apiErr := UpsertDashboard(context.Background(), uuid, data, fm)
+		if apiErr != nil {
+			return apiErr
+		}
+		if !created {
+			zap.S().Infof("Creating Dashboards: Already exists: %s\t%s", filename, "Dashboard already present in database, Updated dashboard")
+		}
+		return markProvisioned(dashboard.Uuid, filename)
 	}
 
 	zap.S().Infof("Creating Dashboards: UUID not found: %s\t%s", filename, "Dashboard not present in database, Creating dashboard")
-	_, apiErr = CreateDashboard(context.Background(), data, fm)
-	return apiErr
+	dashboard, apiErr := CreateDashboard(context.Background(), data, fm)
+	if apiErr != nil {
+		return apiErr
+	}
+	return markProvisioned(dashboard.Uuid, filename)
+}
+
+// assignProvisionedDashboards gives the provisioned dashboards without an org
+// to the only org, as they may be provisioned before the first user signs up.
+// The files name no org, with several orgs the dashboards stay unassigned.
+func assignProvisionedDashboards() {
+	_, err := db.Exec(`UPDATE dashboards SET org_id = (SELECT id FROM organizations) WHERE org_id IS NULL AND provisioned_from IS NOT NULL AND (SELECT COUNT(*) FROM organizations) = 1`)
+	if err != nil {
+		zap.L().Error("Error in assigning provisioned dashboards to the org", zap.Error(err))
+	}
 }
 
-func LoadDashboardFiles(fm interfaces.FeatureLookup) error {
-	dashboardsPath := constants.GetOrDefaultEnv("DASHBOARDS_PATH", "./config/dashboards")
-	return readCurrentDir(dashboardsPath, fm)
+func markProvisioned(uuid string, filename string) *model.ApiError {
+	_, err := db.Exec(`UPDATE dashboards SET provisioned_from=? WHERE uuid=?`, filename, uuid)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return nil
+}
+
+// sameDashboardData reports whether provisioning the data would leave the
+// dashboard unchanged, so restarts do not rewrite every provisioned dashboard
+func sameDashboardData(existing Data, data map[string]interface{}) bool {
+	candidate := map[string]interface{}{}
+	for k, v := range data {
+		candidate[k] = v
+	}
+	if GetSchemaVersion(candidate) == 0 {
+		if version, ok := existing[schemaVersionKey]; ok {
+			candidate[schemaVersionKey] = version
+		}
+	}
+	return reflect.DeepEqual(map[string]interface{}(existing), candidate)
 }
@@ -0,0 +1,59 @@
+package dashboards_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardProvisioner(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	dir := t.TempDir()
+
+	provision := func(content string) []dashboards.Dashboard {
+		require.NoError(os.WriteFile(filepath.Join(dir, "hosts.yaml"), []byte(content), 0o644))
+		provisioner := dashboards.NewDashboardProvisioner(dir, 0, nil)
+		provisioner.Start()
+		provisioner.Stop()
+
+		list, apiErr := dashboards.GetDashboards(context.Background())
+		require.Nil(apiErr)
+		return list
+	}
+
+	list := provision("title: hosts\nwidgets:\n  - id: cpu\n    panelTypes: graph\n")
+	require.Len(list, 1)
+	require.Equal("hosts", list[0].Data["title"])
+	require.Equal("hosts.yaml", *list[0].ProvisionedFrom)
+
+	// an unchanged file leaves the dashboard alone
+	list = provision("title: hosts\nwidgets:\n  - id: cpu\n    panelTypes: graph\n")
+	require.Len(list, 1)
+	require.Equal(1, list[0].Version)
+
+	// the dashboard is matched with its file and updated in place
+	list = provision("title: host metrics\nwidgets:\n  - id: cpu\n    panelTypes: graph\n")
+	require.Len(list, 1)
+	require.Equal("host metrics", list[0].Data["title"])
+	require.Equal(2, list[0].Version)
+
+	// provisioned dashboards are read only for users
+	ctx := contextWithUser("alice@signoz.io")
+	_, apiErr := dashboards.UpdateDashboard(ctx, list[0].Uuid, map[string]interface{}{"title": "edited"}, false, 0, nil)
+	require.NotNil(apiErr)
+	require.NotNil(dashboards.DeleteDashboard(ctx, list[0].Uuid, false, nil))
+
+	// the dashboards provisioned before the first user signs up are given to
+	// the org once it exists
+	require.Nil(list[0].OrgId)
+	_, err := sqlStore.SQLxDB().Exec(`INSERT INTO organizations (id, name, created_at) VALUES (?, ?, ?)`, "acme", "acme", 0)
+	require.NoError(err)
+	list = provision("title: host metrics\nwidgets:\n  - id: cpu\n    panelTypes: graph\n")
+	require.Equal("acme", *list[0].OrgId)
+}
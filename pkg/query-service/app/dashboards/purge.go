@@ -0,0 +1,244 @@
+package dashboards
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/telemetry"
+	"go.uber.org/zap"
+)
+
+const maxTrashRetentionDays = 3650
+
+// TrashSettings is how long the deleted dashboards of an org are kept in the
+// trash before being purged. Orgs without settings of their own use the
+// retention of the DASHBOARDS_TRASH_RETENTION env var.
+type TrashSettings struct {
+	RetentionDays int        `json:"retention_days" db:"retention_days"`
+	UpdatedBy     string     `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+func defaultTrashRetentionDays() int {
+	days := int(constants.GetDashboardsTrashRetention() / (24 * time.Hour))
+	if days < 1 {
+		return 1
+	}
+	return days
+}
+
+// GetTrashSettings returns the trash settings of the org of the user
+func GetTrashSettings(ctx context.Context) (*TrashSettings, *model.ApiError) {
+	orgId, _ := homeOrg(ctx)
+
+	settings := TrashSettings{}
+	err := db.Get(&settings, `SELECT retention_days, updated_by, updated_at FROM dashboard_trash_settings WHERE org_id=?`, orgId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &TrashSettings{RetentionDays: defaultTrashRetentionDays()}, nil
+	}
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return &settings, nil
+}
+
+// SetTrashSettings sets how many days the deleted dashboards of the org of
+// the user are kept, the next purge applies it to the dashboards already in
+// the trash
+func SetTrashSettings(ctx context.Context, retentionDays int) (*TrashSettings, *model.ApiError) {
+	if retentionDays < 1 || retentionDays > maxTrashRetentionDays {
+		return nil, model.BadRequest(fmt.Errorf("retention_days must be between 1 and %d", maxTrashRetentionDays))
+	}
+
+	orgId, userEmail := homeOrg(ctx)
+	now := time.Now()
+	settings := &TrashSettings{RetentionDays: retentionDays, UpdatedBy: userEmail, UpdatedAt: &now}
+	_, err := db.Exec(`INSERT INTO dashboard_trash_settings (org_id, retention_days, updated_by, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(org_id) DO UPDATE SET retention_days=excluded.retention_days, updated_by=excluded.updated_by, updated_at=excluded.updated_at`,
+		orgId, settings.RetentionDays, settings.UpdatedBy, now)
+	if err != nil {
+		zap.L().Error("Error in setting dashboard trash settings", zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return settings, nil
+}
+
+// PurgeExpiredDashboards permanently deletes the dashboards which have been
+// in the trash for longer than the retention of their org and returns how
+// many were purged
+func PurgeExpiredDashboards(ctx context.Context, now time.Time) (int64, *model.ApiError) {
+	settings := []struct {
+		OrgId         string `db:"org_id"`
+		RetentionDays int    `db:"retention_days"`
+	}{}
+	if err := db.Select(&settings, `SELECT org_id, retention_days FROM dashboard_trash_settings`); err != nil {
+		return 0, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	retentionDays := map[string]int{}
+	for _, setting := range settings {
+		retentionDays[setting.OrgId] = setting.RetentionDays
+	}
+
+	deleted := []struct {
+		Uuid      string    `db:"uuid"`
+		OrgId     *string   `db:"org_id"`
+		DeletedAt time.Time `db:"deleted_at"`
+	}{}
+	if err := db.Select(&deleted, `SELECT uuid, org_id, deleted_at FROM dashboards WHERE deleted_at IS NOT NULL`); err != nil {
+		return 0, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	expired := map[string]int{}
+	for _, dashboard := range deleted {
+		var orgId string
+		if dashboard.OrgId != nil {
+			orgId = *dashboard.OrgId
+		}
+		days, ok := retentionDays[orgId]
+		if !ok {
+			days = defaultTrashRetentionDays()
+		}
+		if dashboard.DeletedAt.Before(now.AddDate(0, 0, -days)) {
+			expired[dashboard.Uuid] = days
+		}
+	}
+
+	return purgeDashboards(ctx, expired)
+}
+
+// PurgeDeletedDashboards permanently deletes the dashboards which have been in
+// the trash for longer than the retention and returns how many were purged
+func PurgeDeletedDashboards(ctx context.Context, retention time.Duration) (int64, *model.ApiError) {
+	uuids := []string{}
+	err := db.Select(&uuids, `SELECT uuid FROM dashboards WHERE deleted_at IS NOT NULL AND deleted_at < ?`, time.Now().Add(-retention))
+	if err != nil {
+		return 0, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	expired := map[string]int{}
+	for _, uuid := range uuids {
+		expired[uuid] = int(retention / (24 * time.Hour))
+	}
+	return purgeDashboards(ctx, expired)
+}
+
+// purgeDashboards deletes the dashboards, given with the days they were kept
+// in the trash, and records the purge in their audit log
+func purgeDashboards(ctx context.Context, expired map[string]int) (int64, *model.ApiError) {
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	defer tx.Rollback()
+
+	var purged int64
+	for uuid, days := range expired {
+		// the dashboard may have been restored since it was selected
+		var count int
+		if err := tx.Get(&count, `SELECT COUNT(*) FROM dashboards WHERE uuid=? AND deleted_at IS NOT NULL`, uuid); err != nil {
+			return 0, &model.ApiError{Typ: model.ErrorExec, Err: err}
+		}
+		if count == 0 {
+			continue
+		}
+
+		// recorded first for the entry to keep the org of the dashboard
+		recordAudit(ctx, tx, uuid, AuditActionPurge, fmt.Sprintf("purged after %d days in the trash", days))
+		if _, err := tx.Exec(`DELETE FROM dashboards WHERE uuid=?`, uuid); err != nil {
+			return 0, &model.ApiError{Typ: model.ErrorExec, Err: err}
+		}
+		purged++
+	}
+
+	if err := tx.Commit(); err != nil {
+		zap.L().Error("Error in committing dashboard purge", zap.Error(err))
+		return 0, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	if purged > 0 {
+		pruneSearchIndex()
+		pruneFavorites()
+		pruneMetricIndex()
+		pruneServiceIndex()
+		pruneHomeDashboards()
+		pruneThumbnails()
+		pruneWidgetAlerts()
+
+		var userEmail string
+		if user := common.GetUserFromContext(ctx); user != nil {
+			userEmail = user.Email
+		}
+		telemetry.GetInstance().SendEvent(telemetry.TELEMETRY_EVENT_DASHBOARDS_PURGED, map[string]interface{}{"purged": purged}, userEmail, true, false)
+	}
+
+	return purged, nil
+}
+
+// DashboardPurger periodically purges the dashboards which have been in the
+// trash for longer than the retention of their org
+type DashboardPurger struct {
+	interval time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewDashboardPurger returns a purger running every interval, or only once on
+// start if the interval is 0
+func NewDashboardPurger(interval time.Duration) *DashboardPurger {
+	return &DashboardPurger{
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+func (p *DashboardPurger) Start() {
+	p.purge(time.Now())
+	if p.interval <= 0 {
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.done:
+				return
+			case now := <-ticker.C:
+				p.purge(now)
+			}
+		}
+	}()
+}
+
+func (p *DashboardPurger) Stop() {
+	close(p.done)
+	p.wg.Wait()
+}
+
+func (p *DashboardPurger) purge(now time.Time) {
+	purged, apiErr := PurgeExpiredDashboards(context.Background(), now)
+	if apiErr != nil {
+		zap.L().Error("Error in purging deleted dashboards", zap.Error(apiErr.Err))
+		return
+	}
+	if purged > 0 {
+		zap.L().Info("Purged deleted dashboards", zap.Int64("count", purged))
+	}
+}
@@ -0,0 +1,66 @@
+package dashboards_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestPurgeExpiredDashboards(t *testing.T) {
+	require := require.New(t)
+	store := utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	settings, apiErr := dashboards.GetTrashSettings(ctx)
+	require.Nil(apiErr)
+	require.Equal(30, settings.RetentionDays)
+
+	_, apiErr = dashboards.SetTrashSettings(ctx, 0)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+	_, apiErr = dashboards.SetTrashSettings(ctx, 7)
+	require.Nil(apiErr)
+	settings, apiErr = dashboards.GetTrashSettings(ctx)
+	require.Nil(apiErr)
+	require.Equal(7, settings.RetentionDays)
+	require.Equal("alice@signoz.io", settings.UpdatedBy)
+
+	old, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "old"}, nil)
+	require.Nil(apiErr)
+	recent, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "recent"}, nil)
+	require.Nil(apiErr)
+	require.Nil(dashboards.DeleteDashboard(ctx, old.Uuid, false, nil))
+	require.Nil(dashboards.DeleteDashboard(ctx, recent.Uuid, false, nil))
+
+	now := time.Now()
+	_, err := store.SQLxDB().Exec(`UPDATE dashboards SET deleted_at=? WHERE uuid=?`, now.AddDate(0, 0, -10), old.Uuid)
+	require.NoError(err)
+	_, err = store.SQLxDB().Exec(`UPDATE dashboards SET deleted_at=? WHERE uuid=?`, now.AddDate(0, 0, -3), recent.Uuid)
+	require.NoError(err)
+
+	// only the dashboards kept longer than the retention of the org are purged
+	purged, apiErr := dashboards.PurgeExpiredDashboards(ctx, now)
+	require.Nil(apiErr)
+	require.Equal(int64(1), purged)
+
+	deleted, apiErr := dashboards.GetDeletedDashboards(ctx)
+	require.Nil(apiErr)
+	require.Len(deleted, 1)
+	require.Equal(recent.Uuid, deleted[0].Uuid)
+
+	entries, apiErr := dashboards.GetDashboardAudit(ctx, old.Uuid)
+	require.Nil(apiErr)
+	require.Equal(dashboards.AuditActionPurge, entries[0].Action)
+	require.Equal("purged after 7 days in the trash", entries[0].Summary)
+
+	// lowering the retention applies to the dashboards already in the trash
+	_, apiErr = dashboards.SetTrashSettings(ctx, 1)
+	require.Nil(apiErr)
+	purged, apiErr = dashboards.PurgeExpiredDashboards(ctx, now)
+	require.Nil(apiErr)
+	require.Equal(int64(1), purged)
+}
@@ -0,0 +1,95 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+const (
+	ReferenceTypeAlert  = "alert"
+	ReferenceTypeReport = "report"
+)
+
+// DashboardReference is an alert rule or a scheduled report using a
+// dashboard, which deleting the dashboard would leave orphaned
+type DashboardReference struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// DashboardReferencedError is returned when deleting a dashboard which is
+// still referenced without forcing it
+type DashboardReferencedError struct {
+	References []DashboardReference
+}
+
+func (e *DashboardReferencedError) Error() string {
+	return fmt.Sprintf("dashboard is referenced by %d alert rules or reports, remove them first or force the deletion", len(e.References))
+}
+
+// GetDashboardReferences returns the alert rules and scheduled reports using
+// the dashboard
+func GetDashboardReferences(ctx context.Context, uuid string) ([]DashboardReference, *model.ApiError) {
+	if _, apiErr := GetDashboard(ctx, uuid); apiErr != nil {
+		return nil, apiErr
+	}
+
+	references, err := dashboardReferences(db, uuid)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return references, nil
+}
+
+// dashboardReferences finds the alert rules created from the panels of the
+// dashboard, whose source links back to it, and its scheduled reports
+func dashboardReferences(q Store, uuid string) ([]DashboardReference, error) {
+	references := []DashboardReference{}
+
+	rules := []struct {
+		Id   int    `db:"id"`
+		Data string `db:"data"`
+	}{}
+	err := q.Select(&rules, `SELECT id, data FROM rules WHERE data LIKE ? ESCAPE '\' ORDER BY id`, "%"+escapeLikePattern(uuid)+"%")
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		var data struct {
+			Alert string `json:"alert"`
+		}
+		// the name is only informative, rules which can't be parsed are
+		// still listed
+		_ = json.Unmarshal([]byte(rule.Data), &data)
+		references = append(references, DashboardReference{Type: ReferenceTypeAlert, Id: strconv.Itoa(rule.Id), Name: data.Alert})
+	}
+
+	reports := []ReportSchedule{}
+	err = q.Select(&reports, `SELECT * FROM dashboard_report_schedules WHERE dashboard_uuid=? ORDER BY created_at`, uuid)
+	if err != nil {
+		return nil, err
+	}
+	for _, report := range reports {
+		references = append(references, DashboardReference{Type: ReferenceTypeReport, Id: report.Id, Name: fmt.Sprintf("%s to %s", report.Cron, report.Recipients)})
+	}
+
+	return references, nil
+}
+
+// checkDashboardReferences fails with a conflict listing the references of
+// the dashboard, if it has any
+func checkDashboardReferences(q Store, uuid string) *model.ApiError {
+	references, err := dashboardReferences(q, uuid)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if len(references) > 0 {
+		return &model.ApiError{Typ: model.ErrorConflict, Err: &DashboardReferencedError{References: references}}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package dashboards_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDeleteReferencedDashboard(t *testing.T) {
+	require := require.New(t)
+	store := utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "checkout"}, nil)
+	require.Nil(apiErr)
+	other, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "payments"}, nil)
+	require.Nil(apiErr)
+
+	references, apiErr := dashboards.GetDashboardReferences(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Empty(references)
+
+	ruleData := fmt.Sprintf(`{"alert":"checkout latency","source":"http://localhost:3301/dashboard/%s?panelTypes=graph"}`, dashboard.Uuid)
+	_, err := store.SQLxDB().Exec(`INSERT INTO rules (created_at, created_by, updated_at, updated_by, data) VALUES (?, ?, ?, ?, ?)`,
+		time.Now(), "alice@signoz.io", time.Now(), "alice@signoz.io", ruleData)
+	require.NoError(err)
+	_, apiErr = dashboards.CreateReportSchedule(ctx, dashboard.Uuid, dashboards.ReportSchedulePostData{
+		Cron: "0 9 * * 1", Recipients: []string{"a@signoz.io"}, TimeRange: "168h",
+	})
+	require.Nil(apiErr)
+
+	references, apiErr = dashboards.GetDashboardReferences(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Len(references, 2)
+	require.Equal(dashboards.ReferenceTypeAlert, references[0].Type)
+	require.Equal("checkout latency", references[0].Name)
+	require.Equal(dashboards.ReferenceTypeReport, references[1].Type)
+
+	apiErr = dashboards.DeleteDashboard(ctx, dashboard.Uuid, false, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorConflict, apiErr.Type())
+	var referencedErr *dashboards.DashboardReferencedError
+	require.True(errors.As(apiErr.Err, &referencedErr))
+	require.Len(referencedErr.References, 2)
+
+	results, apiErr := dashboards.BulkUpdateDashboards(ctx, dashboards.BulkRequest{
+		Operation: dashboards.BulkOperationDelete,
+		Uuids:     []string{dashboard.Uuid, other.Uuid},
+	})
+	require.Nil(apiErr)
+	require.False(results[0].Success)
+	require.True(results[1].Success)
+
+	require.Nil(dashboards.DeleteDashboard(ctx, dashboard.Uuid, true, nil))
+	_, apiErr = dashboards.GetDashboard(ctx, dashboard.Uuid)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+}
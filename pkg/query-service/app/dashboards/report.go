@@ -0,0 +1,337 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"math"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.uber.org/zap"
+)
+
+// ReportSchedule emails a digest of a dashboard to the recipients on a cron
+// schedule. Each digest covers the time range preceding the run.
+type ReportSchedule struct {
+	Id            string     `json:"id" db:"id"`
+	DashboardUuid string     `json:"dashboard_uuid" db:"dashboard_uuid"`
+	Cron          string     `json:"cron" db:"cron"`
+	Recipients    string     `json:"recipients" db:"recipients"`
+	TimeRange     string     `json:"time_range" db:"time_range"`
+	NextRunAt     time.Time  `json:"next_run_at" db:"next_run_at"`
+	LastRunAt     *time.Time `json:"last_run_at" db:"last_run_at"`
+	LastError     string     `json:"last_error" db:"last_error"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	CreatedBy     string     `json:"created_by" db:"created_by"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	UpdatedBy     string     `json:"updated_by" db:"updated_by"`
+}
+
+// ReportSchedulePostData is the payload to create or update a report schedule
+type ReportSchedulePostData struct {
+	Cron       string   `json:"cron"`
+	Recipients []string `json:"recipients"`
+	// TimeRange is a duration such as 24h or 168h
+	TimeRange string `json:"time_range"`
+}
+
+func (p *ReportSchedulePostData) Validate() (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(p.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", p.Cron, err)
+	}
+	if len(p.Recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+	for _, recipient := range p.Recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return nil, fmt.Errorf("invalid recipient %q", recipient)
+		}
+	}
+	timeRange, err := time.ParseDuration(p.TimeRange)
+	if err != nil || timeRange <= 0 {
+		return nil, fmt.Errorf("invalid time range %q", p.TimeRange)
+	}
+	return schedule, nil
+}
+
+func CreateReportSchedule(ctx context.Context, dashboardUuid string, postData ReportSchedulePostData) (*ReportSchedule, *model.ApiError) {
+	cronSchedule, err := postData.Validate()
+	if err != nil {
+		return nil, model.BadRequest(err)
+	}
+	if _, apiErr := GetDashboard(ctx, dashboardUuid); apiErr != nil {
+		return nil, apiErr
+	}
+
+	var userEmail string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		userEmail = user.Email
+	}
+
+	now := time.Now()
+	schedule := &ReportSchedule{
+		Id:            uuid.New().String(),
+		DashboardUuid: dashboardUuid,
+		Cron:          postData.Cron,
+		Recipients:    strings.Join(postData.Recipients, ","),
+		TimeRange:     postData.TimeRange,
+		NextRunAt:     cronSchedule.Next(now),
+		CreatedAt:     now,
+		CreatedBy:     userEmail,
+		UpdatedAt:     now,
+		UpdatedBy:     userEmail,
+	}
+
+	_, err = db.Exec(`INSERT INTO dashboard_report_schedules (id, dashboard_uuid, cron, recipients, time_range, next_run_at, last_error, created_at, created_by, updated_at, updated_by) VALUES (?, ?, ?, ?, ?, ?, '', ?, ?, ?, ?)`,
+		schedule.Id, schedule.DashboardUuid, schedule.Cron, schedule.Recipients, schedule.TimeRange, schedule.NextRunAt, schedule.CreatedAt, schedule.CreatedBy, schedule.UpdatedAt, schedule.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in creating report schedule", zap.String("uuid", dashboardUuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return schedule, nil
+}
+
+func GetReportSchedules(ctx context.Context, dashboardUuid string) ([]ReportSchedule, *model.ApiError) {
+	if _, apiErr := GetDashboard(ctx, dashboardUuid); apiErr != nil {
+		return nil, apiErr
+	}
+
+	schedules := []ReportSchedule{}
+	err := db.Select(&schedules, `SELECT * FROM dashboard_report_schedules WHERE dashboard_uuid=? ORDER BY created_at`, dashboardUuid)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return schedules, nil
+}
+
+// GetReportSchedule returns the report schedule of a dashboard of the org of
+// the user
+func GetReportSchedule(ctx context.Context, id string) (*ReportSchedule, *model.ApiError) {
+	schedule := ReportSchedule{}
+	scope, args := orgScope(ctx)
+	query := `SELECT s.* FROM dashboard_report_schedules s JOIN dashboards d ON d.uuid = s.dashboard_uuid WHERE s.id=?` + scope
+	err := db.Get(&schedule, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no report schedule found with id: %s", id)}
+	}
+	return &schedule, nil
+}
+
+func UpdateReportSchedule(ctx context.Context, id string, postData ReportSchedulePostData) (*ReportSchedule, *model.ApiError) {
+	cronSchedule, err := postData.Validate()
+	if err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	schedule, apiErr := GetReportSchedule(ctx, id)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var userEmail string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		userEmail = user.Email
+	}
+
+	schedule.Cron = postData.Cron
+	schedule.Recipients = strings.Join(postData.Recipients, ",")
+	schedule.TimeRange = postData.TimeRange
+	schedule.NextRunAt = cronSchedule.Next(time.Now())
+	schedule.UpdatedAt = time.Now()
+	schedule.UpdatedBy = userEmail
+
+	_, err = db.Exec(`UPDATE dashboard_report_schedules SET cron=?, recipients=?, time_range=?, next_run_at=?, updated_at=?, updated_by=? WHERE id=?`,
+		schedule.Cron, schedule.Recipients, schedule.TimeRange, schedule.NextRunAt, schedule.UpdatedAt, schedule.UpdatedBy, id)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return schedule, nil
+}
+
+func DeleteReportSchedule(ctx context.Context, id string) *model.ApiError {
+	if _, apiErr := GetReportSchedule(ctx, id); apiErr != nil {
+		return apiErr
+	}
+
+	result, err := db.Exec(`DELETE FROM dashboard_report_schedules WHERE id=?`, id)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if affectedRows == 0 {
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no report schedule found with id: %s", id)}
+	}
+
+	return nil
+}
+
+// GetDueReportSchedules returns the schedules whose next run is at or before now
+func GetDueReportSchedules(ctx context.Context, now time.Time) ([]ReportSchedule, *model.ApiError) {
+	schedules := []ReportSchedule{}
+	err := db.Select(&schedules, `SELECT * FROM dashboard_report_schedules WHERE next_run_at <= ? ORDER BY next_run_at`, now)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return schedules, nil
+}
+
+// MarkReportScheduleRun records the outcome of a run and moves the schedule to
+// its next run after now
+func MarkReportScheduleRun(ctx context.Context, schedule *ReportSchedule, now time.Time, runErr error) *model.ApiError {
+	cronSchedule, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+
+	lastError := ""
+	if runErr != nil {
+		lastError = runErr.Error()
+	}
+
+	_, err = db.Exec(`UPDATE dashboard_report_schedules SET last_run_at=?, last_error=?, next_run_at=? WHERE id=?`,
+		now, lastError, cronSchedule.Next(now), schedule.Id)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return nil
+}
+
+// ReportSeries summarises a series of a panel over the report time range
+type ReportSeries struct {
+	Name string
+	Last float64
+	Min  float64
+	Max  float64
+	Avg  float64
+}
+
+// ReportPanel is a widget of the dashboard as rendered in the report
+type ReportPanel struct {
+	Title  string
+	Error  string
+	Series []ReportSeries
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"format": func(v float64) string { return fmt.Sprintf("%.4g", v) },
+}).Parse(`<html>
+<body style="font-family: sans-serif">
+<h2>{{ .Title }}</h2>
+<p>{{ .Start.Format "2006-01-02 15:04 MST" }} - {{ .End.Format "2006-01-02 15:04 MST" }}</p>
+{{ range .Panels }}
+<h3>{{ .Title }}</h3>
+{{ if .Error }}<p style="color: #c00">{{ .Error }}</p>
+{{ else if not .Series }}<p>No data</p>
+{{ else }}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Series</th><th>Last</th><th>Min</th><th>Max</th><th>Avg</th></tr>
+{{ range .Series }}<tr><td>{{ .Name }}</td><td>{{ format .Last }}</td><td>{{ format .Min }}</td><td>{{ format .Max }}</td><td>{{ format .Avg }}</td></tr>
+{{ end }}</table>
+{{ end }}{{ end }}
+</body>
+</html>`))
+
+// RenderReport runs the queries of every widget of the scheduled dashboard
+// with runQuery and renders the results as an html email
+func RenderReport(
+	ctx context.Context,
+	schedule *ReportSchedule,
+	now time.Time,
+	runQuery func(data Data, widgetId string, start, end int64) ([]*v3.Result, error),
+) (string, string, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, schedule.DashboardUuid)
+	if apiErr != nil {
+		return "", "", apiErr
+	}
+
+	timeRange, err := time.ParseDuration(schedule.TimeRange)
+	if err != nil {
+		return "", "", &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	start, end := now.Add(-timeRange), now
+
+	panels := []ReportPanel{}
+	for _, widgetId := range getWidgetIds(dashboard.Data) {
+		widget, err := GetWidget(dashboard.Data, widgetId)
+		if err != nil {
+			continue
+		}
+		title, _ := widget["title"].(string)
+
+		results, err := runQuery(dashboard.Data, widgetId, start.UnixMilli(), end.UnixMilli())
+		if err != nil {
+			panels = append(panels, ReportPanel{Title: title, Error: err.Error()})
+			continue
+		}
+		panels = append(panels, ReportPanel{Title: title, Series: summarizeResults(results)})
+	}
+
+	title := extractDashboardName(dashboard.Data)
+	var body bytes.Buffer
+	err = reportTemplate.Execute(&body, map[string]interface{}{
+		"Title":  title,
+		"Start":  start,
+		"End":    end,
+		"Panels": panels,
+	})
+	if err != nil {
+		return "", "", &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+
+	return fmt.Sprintf("SigNoz dashboard report: %s", title), body.String(), nil
+}
+
+func summarizeResults(results []*v3.Result) []ReportSeries {
+	summaries := []ReportSeries{}
+	for _, result := range results {
+		for _, series := range result.Series {
+			if len(series.Points) == 0 {
+				continue
+			}
+			series.SortPoints()
+
+			summary := ReportSeries{
+				Name: seriesName(result.QueryName, series.Labels),
+				Last: series.Points[len(series.Points)-1].Value,
+				Min:  math.Inf(1),
+				Max:  math.Inf(-1),
+			}
+			sum := 0.0
+			for _, point := range series.Points {
+				summary.Min = math.Min(summary.Min, point.Value)
+				summary.Max = math.Max(summary.Max, point.Value)
+				sum += point.Value
+			}
+			summary.Avg = sum / float64(len(series.Points))
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
+
+func seriesName(queryName string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return queryName
+	}
+	pairs := []string{}
+	for key, value := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(pairs)
+	return fmt.Sprintf("%s{%s}", queryName, strings.Join(pairs, ", "))
+}
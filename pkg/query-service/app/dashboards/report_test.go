@@ -0,0 +1,82 @@
+package dashboards_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestReportSchedules(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "weekly",
+		"widgets": []interface{}{
+			map[string]interface{}{"id": "calls", "title": "Calls", "query": map[string]interface{}{}},
+			map[string]interface{}{"id": "errors", "title": "Errors", "query": map[string]interface{}{}},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	for _, postData := range []dashboards.ReportSchedulePostData{
+		{Cron: "not a cron", Recipients: []string{"a@signoz.io"}, TimeRange: "24h"},
+		{Cron: "0 9 * * 1", Recipients: []string{}, TimeRange: "24h"},
+		{Cron: "0 9 * * 1", Recipients: []string{"not an email"}, TimeRange: "24h"},
+		{Cron: "0 9 * * 1", Recipients: []string{"a@signoz.io"}, TimeRange: "a week"},
+	} {
+		_, apiErr := dashboards.CreateReportSchedule(ctx, dashboard.Uuid, postData)
+		require.Equal(model.ErrorBadData, apiErr.Type())
+	}
+
+	schedule, apiErr := dashboards.CreateReportSchedule(ctx, dashboard.Uuid, dashboards.ReportSchedulePostData{
+		Cron: "0 9 * * 1", Recipients: []string{"a@signoz.io", "b@signoz.io"}, TimeRange: "168h",
+	})
+	require.Nil(apiErr)
+	require.Equal("a@signoz.io,b@signoz.io", schedule.Recipients)
+	require.Equal(time.Monday, schedule.NextRunAt.Weekday())
+
+	due, apiErr := dashboards.GetDueReportSchedules(ctx, time.Now())
+	require.Nil(apiErr)
+	require.Empty(due)
+	due, apiErr = dashboards.GetDueReportSchedules(ctx, schedule.NextRunAt)
+	require.Nil(apiErr)
+	require.Len(due, 1)
+
+	runQuery := func(data dashboards.Data, widgetId string, start, end int64) ([]*v3.Result, error) {
+		if widgetId == "errors" {
+			return nil, errors.New("query failed")
+		}
+		return []*v3.Result{{
+			QueryName: "A",
+			Series: []*v3.Series{{
+				Labels: map[string]string{"service": "frontend"},
+				Points: []v3.Point{{Timestamp: 2, Value: 4}, {Timestamp: 1, Value: 2}},
+			}},
+		}}, nil
+	}
+	subject, body, apiErr := dashboards.RenderReport(ctx, &due[0], schedule.NextRunAt, runQuery)
+	require.Nil(apiErr)
+	require.Equal("SigNoz dashboard report: weekly", subject)
+	require.Contains(body, "A{service=frontend}")
+	require.Contains(body, "<td>4</td><td>2</td><td>4</td><td>3</td>")
+	require.Contains(body, "query failed")
+
+	require.Nil(dashboards.MarkReportScheduleRun(ctx, &due[0], schedule.NextRunAt, errors.New("smtp down")))
+	updated, apiErr := dashboards.GetReportSchedule(ctx, schedule.Id)
+	require.Nil(apiErr)
+	require.Equal("smtp down", updated.LastError)
+	require.True(updated.NextRunAt.After(schedule.NextRunAt))
+
+	require.Nil(dashboards.DeleteReportSchedule(ctx, schedule.Id))
+	schedules, apiErr := dashboards.GetReportSchedules(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Empty(schedules)
+}
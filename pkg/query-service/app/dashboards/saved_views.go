@@ -0,0 +1,57 @@
+package dashboards
+
+import (
+	"context"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+// widgetSavedViewKey is the key of the widgets running the query of a saved
+// view by reference instead of their own
+const widgetSavedViewKey = "savedViewId"
+
+// SavedViewUsage is a dashboard with widgets referencing a saved view
+type SavedViewUsage struct {
+	DashboardUuid string   `json:"dashboardUuid"`
+	Title         string   `json:"title"`
+	WidgetIds     []string `json:"widgetIds"`
+}
+
+// GetSavedViewUsage returns the dashboards with widgets referencing the saved
+// view
+func GetSavedViewUsage(ctx context.Context, viewId string) ([]SavedViewUsage, *model.ApiError) {
+	dashboards, apiErr := GetDashboards(ctx)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	usage := []SavedViewUsage{}
+	for _, dashboard := range dashboards {
+		widgetIds := savedViewWidgets(dashboard.Data, viewId)
+		if len(widgetIds) > 0 {
+			usage = append(usage, SavedViewUsage{DashboardUuid: dashboard.Uuid, Title: dashboard.Title, WidgetIds: widgetIds})
+		}
+	}
+	return usage, nil
+}
+
+// savedViewWidgets returns the ids of the widgets of the dashboard data
+// referencing the saved view
+func savedViewWidgets(data Data, viewId string) []string {
+	widgetIds := []string{}
+	widgets, _ := data["widgets"].([]interface{})
+	for _, w := range widgets {
+		widget, ok := w.(map[string]interface{})
+		if !ok || widgetSavedView(widget) != viewId {
+			continue
+		}
+		id, _ := widget["id"].(string)
+		widgetIds = append(widgetIds, id)
+	}
+	return widgetIds
+}
+
+func widgetSavedView(widget map[string]interface{}) string {
+	viewId, _ := widget[widgetSavedViewKey].(string)
+	return viewId
+}
@@ -0,0 +1,94 @@
+package dashboards_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/app/explorer"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func TestSavedViewReferences(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	explorer.InitWithDB(sqlStore.SQLxDB())
+
+	editorCtx := func(email string) context.Context {
+		ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: email})
+		return context.WithValue(ctx, constants.ContextUserKey, &model.UserPayload{
+			User: model.User{Id: email, Email: email},
+			Role: "EDITOR",
+		})
+	}
+	aliceCtx := editorCtx("alice@signoz.io")
+	bobCtx := editorCtx("bob@signoz.io")
+
+	view := v3.SavedView{
+		Name:       "checkout errors",
+		SourcePage: "logs",
+		Tags:       []string{"checkout", "errors"},
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType: v3.QueryTypeClickHouseSQL,
+			PanelType: v3.PanelTypeList,
+			ClickHouseQueries: map[string]*v3.ClickHouseQuery{
+				"A": {Query: "SELECT count() FROM signoz_logs.distributed_logs_v2"},
+			},
+		},
+	}
+	require.NoError(view.Validate())
+	require.Equal(v3.SavedViewVisibilityOrg, view.Visibility)
+	viewId, err := explorer.CreateView(aliceCtx, view)
+	require.NoError(err)
+
+	draft := view
+	draft.UUID = ""
+	draft.Name = "checkout draft"
+	draft.Visibility = v3.SavedViewVisibilityPrivate
+	require.NoError(draft.Validate())
+	draftId, err := explorer.CreateView(aliceCtx, draft)
+	require.NoError(err)
+
+	// the private views are only listed and returned to their owner
+	views, err := explorer.GetViewsForFilters(bobCtx, explorer.ViewFilters{Tags: []string{"checkout"}})
+	require.NoError(err)
+	require.Len(views, 1)
+	views, err = explorer.GetViewsForFilters(aliceCtx, explorer.ViewFilters{Tags: []string{"checkout", "errors"}})
+	require.NoError(err)
+	require.Len(views, 2)
+	views, err = explorer.GetViewsForFilters(aliceCtx, explorer.ViewFilters{Tags: []string{"payments"}})
+	require.NoError(err)
+	require.Empty(views)
+	_, err = explorer.GetView(bobCtx, draftId)
+	require.ErrorIs(err, explorer.ErrViewNotFound)
+
+	// only the owner and the admins change the views
+	require.ErrorIs(explorer.UpdateView(bobCtx, viewId, view), explorer.ErrViewForbidden)
+	require.ErrorIs(explorer.DeleteView(bobCtx, draftId), explorer.ErrViewNotFound)
+
+	// the widgets referencing the view run its query
+	dashboard, apiErr := dashboards.CreateDashboard(contextWithUser("alice@signoz.io"), map[string]interface{}{
+		"title": "checkout",
+		"widgets": []interface{}{
+			map[string]interface{}{"id": "errors", "panelTypes": "value", "savedViewId": viewId},
+			map[string]interface{}{"id": "draft", "panelTypes": "graph", "savedViewId": draftId},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	params, err := dashboards.WidgetQueryRangeParams(dashboard.Data, "errors", 1000, 2000)
+	require.NoError(err)
+	require.Equal(v3.PanelTypeValue, params.CompositeQuery.PanelType)
+	require.Equal("SELECT count() FROM signoz_logs.distributed_logs_v2", params.CompositeQuery.ClickHouseQueries["A"].Query)
+	_, err = dashboards.WidgetQueryRangeParams(dashboard.Data, "draft", 1000, 2000)
+	require.Error(err)
+
+	usage, apiErr := dashboards.GetSavedViewUsage(aliceCtx, viewId)
+	require.Nil(apiErr)
+	require.Equal([]dashboards.SavedViewUsage{{DashboardUuid: dashboard.Uuid, Title: "checkout", WidgetIds: []string{"errors"}}}, usage)
+}
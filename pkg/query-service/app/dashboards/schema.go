@@ -0,0 +1,532 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	promModel "github.com/prometheus/common/model"
+)
+
+// DashboardSchema is the typed view of the dashboard data. The data is still
+// stored as it was posted so fields the frontend adds later are not lost, the
+// schema only covers the fields the backend relies on.
+type DashboardSchema struct {
+	SchemaVersion int                          `json:"schemaVersion,omitempty"`
+	Title         string                       `json:"title"`
+	Description   string                       `json:"description,omitempty"`
+	Tags          []string                     `json:"tags,omitempty"`
+	Layout        []LayoutItem                 `json:"layout,omitempty"`
+	Widgets       []Widget                     `json:"widgets,omitempty"`
+	Variables     map[string]DashboardVariable `json:"variables,omitempty"`
+	PanelMap      map[string]PanelMapEntry     `json:"panelMap,omitempty"`
+	Sections      []Section                    `json:"sections,omitempty"`
+	Annotations   []AnnotationSource           `json:"annotations,omitempty"`
+
+	// DefaultTimeRange and RefreshInterval are durations such as 15m or 30d
+	// the dashboard opens with, unless the user picks others
+	DefaultTimeRange string `json:"defaultTimeRange,omitempty"`
+	RefreshInterval  string `json:"refreshInterval,omitempty"`
+}
+
+// LayoutItem is the position of a widget on the grid, I is the widget id
+type LayoutItem struct {
+	I string `json:"i"`
+	X int    `json:"x"`
+	Y int    `json:"y"`
+	W int    `json:"w"`
+	H int    `json:"h"`
+}
+
+func (l LayoutItem) overlaps(other LayoutItem) bool {
+	return l.X < other.X+other.W && other.X < l.X+l.W && l.Y < other.Y+other.H && other.Y < l.Y+l.H
+}
+
+// PanelMapEntry holds the layout of the widgets of a row, the widgets of a
+// collapsed row are taken out of the dashboard layout
+type PanelMapEntry struct {
+	Widgets   []LayoutItem `json:"widgets"`
+	Collapsed bool         `json:"collapsed"`
+}
+
+// Section is a collapsible group of widgets, replacing the row marker widgets.
+// A section with a repeat variable is rendered once for every selected value
+// of the variable. The widgets of a collapsed section are not in the layout.
+type Section struct {
+	Id        string   `json:"id"`
+	Title     string   `json:"title"`
+	Collapsed bool     `json:"collapsed,omitempty"`
+	Repeat    string   `json:"repeat,omitempty"`
+	Widgets   []string `json:"widgets"`
+}
+
+type Widget struct {
+	Id          string            `json:"id"`
+	PanelTypes  string            `json:"panelTypes,omitempty"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Query       *WidgetQuery      `json:"query,omitempty"`
+	Links       []WidgetLink      `json:"links,omitempty"`
+	Thresholds  []WidgetThreshold `json:"thresholds,omitempty"`
+}
+
+type WidgetQuery struct {
+	QueryType     string          `json:"queryType,omitempty"`
+	Builder       json.RawMessage `json:"builder,omitempty"`
+	ClickHouseSQL json.RawMessage `json:"clickhouse_sql,omitempty"`
+	PromQL        json.RawMessage `json:"promql,omitempty"`
+}
+
+// WidgetLink opens another dashboard or an explorer from a panel. The values
+// of the variables and filters are templates resolved with the labels of the
+// clicked series, as in {{service.name}}, and the values of the dashboard
+// variables, as in {{$env}}.
+type WidgetLink struct {
+	Title           string            `json:"title"`
+	TargetDashboard string            `json:"targetDashboard,omitempty"`
+	TargetExplorer  string            `json:"targetExplorer,omitempty"`
+	Variables       map[string]string `json:"variables,omitempty"`
+	Filters         map[string]string `json:"filters,omitempty"`
+}
+
+type DashboardVariable struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Sort string `json:"sort,omitempty"`
+
+	// QueryValue is the query listing the values of QUERY variables, it can
+	// use the values of other variables as in {{.service}}
+	QueryValue    string      `json:"queryValue,omitempty"`
+	CustomValue   string      `json:"customValue,omitempty"`
+	TextboxValue  string      `json:"textboxValue,omitempty"`
+	SelectedValue interface{} `json:"selectedValue,omitempty"`
+
+	// IntervalValue lists the intervals of INTERVAL variables, as in 1m,5m,1h
+	IntervalValue string `json:"intervalValue,omitempty"`
+	ConstantValue string `json:"constantValue,omitempty"`
+
+	// MultiSelect variables take several values, ShowALLOption lets them
+	// select all their values at once, which AllSelected records
+	MultiSelect   bool `json:"multiSelect,omitempty"`
+	ShowALLOption bool `json:"showALLOption,omitempty"`
+	AllSelected   bool `json:"allSelected,omitempty"`
+}
+
+// options returns the values of the variables whose values are part of the
+// dashboard, the values of QUERY and TEXTBOX variables are not known up front
+func (v *DashboardVariable) options() []string {
+	switch v.Type {
+	case "CUSTOM":
+		return splitVariableValues(v.CustomValue)
+	case "INTERVAL":
+		return splitVariableValues(v.IntervalValue)
+	case "CONSTANT":
+		return []string{v.ConstantValue}
+	}
+	return nil
+}
+
+func splitVariableValues(s string) []string {
+	values := []string{}
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// knownPanelTypes are the widget panel types, rows are widgets too
+// minRefreshInterval is the shortest refresh interval of a dashboard, every
+// refresh runs all the queries of the dashboard
+const minRefreshInterval = 5 * time.Second
+
+var knownPanelTypes = map[string]bool{
+	"graph":        true,
+	"bar":          true,
+	"histogram":    true,
+	"value":        true,
+	"table":        true,
+	"pie":          true,
+	"list":         true,
+	"trace":        true,
+	"row":          true,
+	"EMPTY_WIDGET": true,
+}
+
+var knownQueryTypes = map[string]bool{
+	"builder":        true,
+	"clickhouse_sql": true,
+	"promql":         true,
+}
+
+var knownExplorers = map[string]bool{
+	"traces":  true,
+	"logs":    true,
+	"metrics": true,
+}
+
+var knownVariableTypes = map[string]bool{
+	"QUERY":    true,
+	"TEXTBOX":  true,
+	"CUSTOM":   true,
+	"INTERVAL": true,
+	"CONSTANT": true,
+}
+
+var knownVariableSorts = map[string]bool{
+	"DISABLED": true,
+	"ASC":      true,
+	"DESC":     true,
+}
+
+// FieldError is a validation error of a single field of the dashboard data
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError holds all the field errors of the dashboard data
+type SchemaValidationError struct {
+	Errors []FieldError
+}
+
+func (e *SchemaValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", fe.Field, fe.Message))
+	}
+	return "invalid dashboard: " + strings.Join(msgs, "; ")
+}
+
+// ParseDashboardSchema decodes the dashboard data into the typed schema
+func ParseDashboardSchema(data map[string]interface{}) (*DashboardSchema, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &DashboardSchema{}
+	if err := json.Unmarshal(raw, schema); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, &SchemaValidationError{Errors: []FieldError{{
+				Field:   typeErr.Field,
+				Message: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+			}}}
+		}
+		return nil, err
+	}
+	return schema, nil
+}
+
+// ValidateDashboardData checks the dashboard data against the schema and
+// returns a *SchemaValidationError listing every invalid field
+func ValidateDashboardData(data map[string]interface{}) error {
+	schema, err := ParseDashboardSchema(data)
+	if err != nil {
+		return err
+	}
+
+	if errs := schema.Validate(); len(errs) > 0 {
+		return &SchemaValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (s *DashboardSchema) Validate() []FieldError {
+	errs := []FieldError{}
+	addErr := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(s.Title) == "" {
+		addErr("title", "title is required")
+	}
+	if s.DefaultTimeRange != "" {
+		if timeRange, err := promModel.ParseDuration(s.DefaultTimeRange); err != nil || timeRange <= 0 {
+			addErr("defaultTimeRange", "invalid time range %q, expected a duration such as 15m or 30d", s.DefaultTimeRange)
+		}
+	}
+	if s.RefreshInterval != "" {
+		if interval, err := promModel.ParseDuration(s.RefreshInterval); err != nil {
+			addErr("refreshInterval", "invalid refresh interval %q, expected a duration such as 30s or 5m", s.RefreshInterval)
+		} else if time.Duration(interval) < minRefreshInterval {
+			addErr("refreshInterval", "refresh interval must be at least %s", minRefreshInterval)
+		}
+	}
+
+	widgetIds := map[string]bool{}
+	rowIds := map[string]bool{}
+	for i, widget := range s.Widgets {
+		field := fmt.Sprintf("widgets[%d]", i)
+		if widget.Id == "" {
+			addErr(field+".id", "id is required")
+		} else if widgetIds[widget.Id] {
+			addErr(field+".id", "duplicate widget id %q", widget.Id)
+		}
+		widgetIds[widget.Id] = true
+		if widget.PanelTypes == "row" {
+			rowIds[widget.Id] = true
+		}
+
+		if widget.PanelTypes != "" && !knownPanelTypes[widget.PanelTypes] {
+			addErr(field+".panelTypes", "unknown panel type %q", widget.PanelTypes)
+		}
+		if widget.Query != nil && widget.Query.QueryType != "" && !knownQueryTypes[widget.Query.QueryType] {
+			addErr(field+".query.queryType", "unknown query type %q", widget.Query.QueryType)
+		}
+		for j, link := range widget.Links {
+			for _, fe := range link.Validate() {
+				addErr(fmt.Sprintf("%s.links[%d].%s", field, j, fe.Field), "%s", fe.Message)
+			}
+		}
+		for j, threshold := range widget.Thresholds {
+			for _, fe := range threshold.Validate() {
+				addErr(fmt.Sprintf("%s.thresholds[%d].%s", field, j, fe.Field), "%s", fe.Message)
+			}
+		}
+	}
+
+	laidOut := map[string]bool{}
+	placed := []LayoutItem{}
+	for i, item := range s.Layout {
+		field := fmt.Sprintf("layout[%d]", i)
+		if item.I == "" {
+			addErr(field+".i", "widget id is required")
+		} else if laidOut[item.I] {
+			addErr(field+".i", "duplicate layout item for widget %q", item.I)
+		} else if !widgetIds[item.I] {
+			addErr(field+".i", "no widget with id %q", item.I)
+		}
+		laidOut[item.I] = true
+
+		valid := true
+		if item.X < 0 || item.Y < 0 {
+			addErr(field, "position must not be negative")
+			valid = false
+		}
+		if item.W < 1 || item.H < 1 {
+			addErr(field, "width and height must be at least 1")
+			valid = false
+		}
+		if item.X+item.W > signozGridColumns {
+			addErr(field, "widget must fit in the %d grid columns", signozGridColumns)
+			valid = false
+		}
+		if !valid {
+			continue
+		}
+		for _, other := range placed {
+			if item.overlaps(other) {
+				addErr(field, "widget %q overlaps widget %q", item.I, other.I)
+			}
+		}
+		placed = append(placed, item)
+	}
+
+	// the widgets of collapsed rows and sections are not in the layout
+	for _, row := range s.PanelMap {
+		if row.Collapsed {
+			for _, item := range row.Widgets {
+				laidOut[item.I] = true
+			}
+		}
+	}
+	for _, section := range s.Sections {
+		if section.Collapsed {
+			for _, id := range section.Widgets {
+				laidOut[id] = true
+			}
+		}
+	}
+	if len(s.Layout) > 0 {
+		for i, widget := range s.Widgets {
+			if widget.Id != "" && !laidOut[widget.Id] {
+				addErr(fmt.Sprintf("widgets[%d]", i), "widget %q is not in the layout", widget.Id)
+			}
+		}
+	}
+
+	variableNames := map[string]bool{}
+	variableIds := make([]string, 0, len(s.Variables))
+	for id := range s.Variables {
+		variableIds = append(variableIds, id)
+	}
+	sort.Strings(variableIds)
+	for _, id := range variableIds {
+		variable := s.Variables[id]
+		field := fmt.Sprintf("variables[%s]", id)
+		if variable.Name == "" {
+			addErr(field+".name", "name is required")
+		} else if variableNames[variable.Name] {
+			addErr(field+".name", "duplicate variable name %q", variable.Name)
+		}
+		variableNames[variable.Name] = true
+
+		for _, fe := range variable.Validate() {
+			addErr(field+"."+fe.Field, "%s", fe.Message)
+		}
+	}
+	errs = append(errs, validateVariableDependencies(s.Variables)...)
+
+	annotationIds := map[string]bool{}
+	for i, source := range s.Annotations {
+		field := fmt.Sprintf("annotations[%d]", i)
+		if source.Id == "" {
+			addErr(field+".id", "id is required")
+		} else if annotationIds[source.Id] {
+			addErr(field+".id", "duplicate annotation source id %q", source.Id)
+		}
+		annotationIds[source.Id] = true
+
+		for _, fe := range source.Validate() {
+			addErr(field+"."+fe.Field, "%s", fe.Message)
+		}
+	}
+
+	sectionIds := map[string]bool{}
+	sectionOf := map[string]string{}
+	for i, section := range s.Sections {
+		field := fmt.Sprintf("sections[%d]", i)
+		if section.Id == "" {
+			addErr(field+".id", "id is required")
+		} else if sectionIds[section.Id] || widgetIds[section.Id] {
+			addErr(field+".id", "duplicate id %q", section.Id)
+		}
+		sectionIds[section.Id] = true
+
+		if strings.TrimSpace(section.Title) == "" {
+			addErr(field+".title", "title is required")
+		}
+		if section.Repeat != "" && !variableNames[section.Repeat] {
+			addErr(field+".repeat", "no variable named %q", section.Repeat)
+		}
+		for j, id := range section.Widgets {
+			widgetField := fmt.Sprintf("%s.widgets[%d]", field, j)
+			switch {
+			case !widgetIds[id]:
+				addErr(widgetField, "no widget with id %q", id)
+			case rowIds[id]:
+				addErr(widgetField, "row widget %q cannot be in a section", id)
+			case sectionOf[id] != "":
+				addErr(widgetField, "widget %q is already in section %q", id, sectionOf[id])
+			default:
+				sectionOf[id] = section.Id
+			}
+		}
+	}
+
+	return errs
+}
+
+// Validate checks the variable is consistent, so the variables saved can be
+// rendered, e.g. that the selected values are among the values of the variable
+func (v *DashboardVariable) Validate() []FieldError {
+	errs := []FieldError{}
+	addErr := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !knownVariableTypes[v.Type] {
+		addErr("type", "unknown variable type %q", v.Type)
+	}
+	if v.Sort != "" && !knownVariableSorts[v.Sort] {
+		addErr("sort", "unknown sort %q", v.Sort)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	options := v.options()
+	switch v.Type {
+	case "CUSTOM":
+		if len(options) == 0 {
+			addErr("customValue", "at least one value is required")
+		}
+	case "INTERVAL":
+		if len(options) == 0 {
+			addErr("intervalValue", "at least one interval is required")
+		}
+		for _, interval := range options {
+			if _, err := promModel.ParseDuration(interval); err != nil {
+				addErr("intervalValue", "invalid interval %q", interval)
+			}
+		}
+	case "CONSTANT":
+		if v.ConstantValue == "" {
+			addErr("constantValue", "value is required")
+		}
+	}
+
+	if v.MultiSelect && (v.Type == "TEXTBOX" || v.Type == "CONSTANT") {
+		addErr("multiSelect", "%s variables take a single value", strings.ToLower(v.Type))
+	}
+	if v.ShowALLOption && !v.MultiSelect {
+		addErr("showALLOption", "the ALL option is only available to multiSelect variables")
+	}
+	if v.AllSelected && !v.ShowALLOption {
+		addErr("allSelected", "all values can only be selected with the ALL option")
+	}
+
+	if v.SelectedValue == nil || v.AllSelected {
+		return errs
+	}
+	selected, isList := v.SelectedValue.([]interface{})
+	if !isList {
+		selected = []interface{}{v.SelectedValue}
+	} else if !v.MultiSelect {
+		addErr("selectedValue", "only multiSelect variables take several values")
+	}
+	if options == nil {
+		return errs
+	}
+	for _, value := range selected {
+		if !slices.Contains(options, fmt.Sprint(value)) {
+			addErr("selectedValue", "%v is not one of the values %s", value, strings.Join(options, ", "))
+		}
+	}
+
+	return errs
+}
+
+func (l *WidgetLink) Validate() []FieldError {
+	errs := []FieldError{}
+	addErr := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case l.TargetDashboard == "" && l.TargetExplorer == "":
+		addErr("targetDashboard", "a target dashboard or explorer is required")
+	case l.TargetDashboard != "" && l.TargetExplorer != "":
+		addErr("targetExplorer", "a link targets either a dashboard or an explorer")
+	case l.TargetExplorer != "" && !knownExplorers[l.TargetExplorer]:
+		addErr("targetExplorer", "unknown explorer %q", l.TargetExplorer)
+	}
+	if l.TargetDashboard == "" && len(l.Variables) > 0 {
+		addErr("variables", "variables can only be set for dashboard links")
+	}
+
+	for _, templates := range []struct {
+		field  string
+		values map[string]string
+	}{{"variables", l.Variables}, {"filters", l.Filters}} {
+		keys := make([]string, 0, len(templates.values))
+		for key := range templates.values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := validateLinkTemplate(templates.values[key]); err != nil {
+				addErr(fmt.Sprintf("%s[%s]", templates.field, key), "%s", err)
+			}
+		}
+	}
+
+	return errs
+}
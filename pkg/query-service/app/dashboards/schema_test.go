@@ -0,0 +1,205 @@
+package dashboards
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDashboardData(t *testing.T) {
+	valid := map[string]interface{}{
+		"title":            "hosts",
+		"tags":             []interface{}{"infra"},
+		"defaultTimeRange": "30d",
+		"refreshInterval":  "1m",
+		"layout": []interface{}{
+			map[string]interface{}{"i": "cpu", "x": 0, "y": 0, "w": 6, "h": 3, "moved": false},
+		},
+		"widgets": []interface{}{
+			map[string]interface{}{
+				"id":         "cpu",
+				"panelTypes": "graph",
+				"query":      map[string]interface{}{"queryType": "promql"},
+				"yAxisUnit":  "percent",
+			},
+		},
+		"variables": map[string]interface{}{
+			"a1": map[string]interface{}{"name": "host", "type": "QUERY", "sort": "ASC"},
+			"a2": map[string]interface{}{"name": "interval", "type": "INTERVAL", "intervalValue": "1m,5m,1h", "selectedValue": "5m"},
+			"a3": map[string]interface{}{"name": "region", "type": "CONSTANT", "constantValue": "us-east-1"},
+			"a4": map[string]interface{}{"name": "env", "type": "CUSTOM", "customValue": "prod, staging", "multiSelect": true, "showALLOption": true, "selectedValue": []interface{}{"prod", "staging"}},
+		},
+		"sections": []interface{}{
+			map[string]interface{}{"id": "per-host", "title": "Per host", "repeat": "host", "widgets": []interface{}{"cpu"}},
+		},
+	}
+	require.NoError(t, ValidateDashboardData(valid))
+
+	tests := []struct {
+		name   string
+		data   map[string]interface{}
+		fields []string
+	}{
+		{
+			name:   "missing title",
+			data:   map[string]interface{}{"title": " "},
+			fields: []string{"title"},
+		},
+		{
+			name:   "title of the wrong type",
+			data:   map[string]interface{}{"title": 42},
+			fields: []string{"title"},
+		},
+		{
+			name:   "invalid time settings",
+			data:   map[string]interface{}{"title": "hosts", "defaultTimeRange": "last week", "refreshInterval": "1s"},
+			fields: []string{"defaultTimeRange", "refreshInterval"},
+		},
+		{
+			name: "invalid widgets",
+			data: map[string]interface{}{
+				"title": "hosts",
+				"widgets": []interface{}{
+					map[string]interface{}{"panelTypes": "graph"},
+					map[string]interface{}{"id": "cpu", "panelTypes": "sparkline"},
+					map[string]interface{}{"id": "cpu", "query": map[string]interface{}{"queryType": "sql"}},
+				},
+			},
+			fields: []string{"widgets[0].id", "widgets[1].panelTypes", "widgets[2].id", "widgets[2].query.queryType"},
+		},
+		{
+			name: "invalid layout",
+			data: map[string]interface{}{
+				"title":   "hosts",
+				"widgets": []interface{}{map[string]interface{}{"id": "cpu"}, map[string]interface{}{"id": "mem"}},
+				"layout": []interface{}{
+					map[string]interface{}{"x": 0, "y": 0, "w": 6, "h": 3},
+					map[string]interface{}{"i": "cpu", "x": -1, "y": 0, "w": 0, "h": 3},
+					map[string]interface{}{"i": "mem", "x": 8, "y": 0, "w": 6, "h": 3},
+				},
+			},
+			fields: []string{"layout[0].i", "layout[1]", "layout[1]", "layout[2]"},
+		},
+		{
+			name: "layout not matching the widgets",
+			data: map[string]interface{}{
+				"title": "hosts",
+				"widgets": []interface{}{
+					map[string]interface{}{"id": "cpu"},
+					map[string]interface{}{"id": "mem"},
+					map[string]interface{}{"id": "disk"},
+					map[string]interface{}{"id": "net"},
+				},
+				"layout": []interface{}{
+					map[string]interface{}{"i": "cpu", "x": 0, "y": 0, "w": 6, "h": 3},
+					map[string]interface{}{"i": "mem", "x": 4, "y": 2, "w": 6, "h": 3},
+					map[string]interface{}{"i": "cpu", "x": 6, "y": 0, "w": 6, "h": 2},
+					map[string]interface{}{"i": "gpu", "x": 0, "y": 5, "w": 6, "h": 3},
+				},
+				"panelMap": map[string]interface{}{
+					"row": map[string]interface{}{"collapsed": true, "widgets": []interface{}{map[string]interface{}{"i": "net"}}},
+				},
+			},
+			fields: []string{"layout[1]", "layout[2].i", "layout[3].i", "widgets[2]"},
+		},
+		{
+			name: "invalid sections",
+			data: map[string]interface{}{
+				"title": "hosts",
+				"widgets": []interface{}{
+					map[string]interface{}{"id": "row", "panelTypes": "row"},
+					map[string]interface{}{"id": "cpu"},
+					map[string]interface{}{"id": "mem"},
+				},
+				"layout": []interface{}{
+					map[string]interface{}{"i": "row", "x": 0, "y": 0, "w": 12, "h": 1},
+					map[string]interface{}{"i": "cpu", "x": 0, "y": 1, "w": 6, "h": 3},
+				},
+				"sections": []interface{}{
+					map[string]interface{}{"id": "system", "title": "System", "repeat": "host", "widgets": []interface{}{"cpu", "row", "disk"}},
+					map[string]interface{}{"id": "cpu", "title": " ", "collapsed": true, "widgets": []interface{}{"mem", "cpu"}},
+				},
+			},
+			fields: []string{
+				"sections[0].repeat", "sections[0].widgets[1]", "sections[0].widgets[2]",
+				"sections[1].id", "sections[1].title", "sections[1].widgets[1]",
+			},
+		},
+		{
+			name: "invalid variables",
+			data: map[string]interface{}{
+				"title": "hosts",
+				"variables": map[string]interface{}{
+					"a": map[string]interface{}{"name": "host", "type": "QUERY"},
+					"b": map[string]interface{}{"name": "host", "type": "DROPDOWN", "sort": "RANDOM"},
+				},
+			},
+			fields: []string{"variables[b].name", "variables[b].type", "variables[b].sort"},
+		},
+		{
+			name: "inconsistent variables",
+			data: map[string]interface{}{
+				"title": "hosts",
+				"variables": map[string]interface{}{
+					"a": map[string]interface{}{"name": "env", "type": "CUSTOM", "customValue": "prod,staging", "selectedValue": "dev"},
+					"b": map[string]interface{}{"name": "interval", "type": "INTERVAL", "intervalValue": "1m,5 minutes"},
+					"c": map[string]interface{}{"name": "region", "type": "CONSTANT", "multiSelect": true},
+					"d": map[string]interface{}{"name": "host", "type": "QUERY", "showALLOption": true, "selectedValue": []interface{}{"a", "b"}},
+				},
+			},
+			fields: []string{
+				"variables[a].selectedValue",
+				"variables[b].intervalValue",
+				"variables[c].constantValue", "variables[c].multiSelect",
+				"variables[d].showALLOption", "variables[d].selectedValue",
+			},
+		},
+		{
+			name: "broken variable chains",
+			data: map[string]interface{}{
+				"title": "hosts",
+				"variables": map[string]interface{}{
+					"a": map[string]interface{}{"name": "service", "type": "QUERY", "queryValue": "SELECT s FROM t WHERE env = {{.env}} AND ts > {{.start_timestamp}}"},
+					"b": map[string]interface{}{"name": "x", "type": "QUERY", "queryValue": "SELECT x FROM t WHERE y = {{.y}}"},
+					"c": map[string]interface{}{"name": "y", "type": "QUERY", "queryValue": "SELECT y FROM t WHERE x = {{ .x }}"},
+					"d": map[string]interface{}{"name": "z", "type": "QUERY", "queryValue": "SELECT z FROM t WHERE z = {{.z}}"},
+				},
+			},
+			fields: []string{"variables[a].queryValue", "variables[c].queryValue", "variables[d].queryValue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDashboardData(tt.data)
+			var validationErr *SchemaValidationError
+			require.True(t, errors.As(err, &validationErr), "expected a validation error, got %v", err)
+
+			fields := []string{}
+			for _, fe := range validationErr.Errors {
+				fields = append(fields, fe.Field)
+			}
+			require.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestValidateVariableDependencies(t *testing.T) {
+	errs := validateVariableDependencies(map[string]DashboardVariable{
+		"1": {Name: "region", Type: "CUSTOM", CustomValue: "eu,us"},
+		"2": {Name: "cluster", Type: "QUERY", QueryValue: "SELECT c FROM t WHERE region = {{.region}} AND ns = {{.namespace}}"},
+		"3": {Name: "namespace", Type: "QUERY", QueryValue: "SELECT n FROM t WHERE pod = {{.pod}}"},
+		"4": {Name: "pod", Type: "QUERY", QueryValue: "SELECT p FROM t WHERE cluster = {{.cluster}}"},
+	})
+	require.Equal(t, []FieldError{
+		{Field: "variables[4].queryValue", Message: "variables depend on each other: cluster -> namespace -> pod -> cluster"},
+	}, errs)
+
+	errs = validateVariableDependencies(map[string]DashboardVariable{
+		"1": {Name: "service", Type: "QUERY", QueryValue: "SELECT s FROM t WHERE env = {{.env}} AND ts < {{.end_datetime}}"},
+	})
+	require.Equal(t, []FieldError{
+		{Field: "variables[1].queryValue", Message: `no variable named "env"`},
+	}, errs)
+}
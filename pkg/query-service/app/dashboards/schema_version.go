@@ -0,0 +1,101 @@
+package dashboards
+
+import (
+	"context"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+const (
+	// SchemaVersionLegacy is the version of dashboards which still query the
+	// legacy time_series_v2 metrics table
+	SchemaVersionLegacy = 1
+	// SchemaVersionCurrent is the version new dashboards are created with
+	SchemaVersionCurrent = 2
+)
+
+const schemaVersionKey = "schemaVersion"
+
+// GetSchemaVersion returns the schema version recorded in the dashboard data,
+// or 0 if none is recorded
+func GetSchemaVersion(data map[string]interface{}) int {
+	if data == nil {
+		return 0
+	}
+	// the version is a float64 when decoded from json
+	switch v := data[schemaVersionKey].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// SetSchemaVersion records the schema version in the dashboard data. Migrations
+// rewriting dashboards must bump the version once they are done.
+func SetSchemaVersion(data map[string]interface{}, version int) {
+	data[schemaVersionKey] = version
+}
+
+// inferSchemaVersion guesses the schema version of a dashboard from its shape
+func inferSchemaVersion(data map[string]interface{}) int {
+	if isDashboardWithTSV2(data) {
+		return SchemaVersionLegacy
+	}
+	return SchemaVersionCurrent
+}
+
+// GetDashboardsBySchemaVersion returns the dashboards of the org of the user
+// on the given schema version
+func GetDashboardsBySchemaVersion(ctx context.Context, version int) ([]Dashboard, *model.ApiError) {
+
+	dashboards := []Dashboard{}
+	scope, args := orgScope(ctx)
+	query := `SELECT * FROM dashboards WHERE schema_version=? AND deleted_at IS NULL` + scope
+
+	err := db.Select(&dashboards, query, append([]interface{}{version}, args...)...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return dashboards, nil
+}
+
+// BackfillSchemaVersions records an inferred schema version on the dashboards
+// which were created before the version was tracked
+func BackfillSchemaVersions(ctx context.Context) error {
+	type dashboardRow struct {
+		Uuid string `db:"uuid"`
+		Data Data   `db:"data"`
+	}
+
+	var dashboards []dashboardRow
+	err := db.Select(&dashboards, `SELECT uuid, data FROM dashboards WHERE schema_version=0`)
+	if err != nil {
+		return err
+	}
+
+	for _, dashboard := range dashboards {
+		// dashboards whose data has a version only need the column to be set
+		version := GetSchemaVersion(dashboard.Data)
+		if version == 0 {
+			version = inferSchemaVersion(dashboard.Data)
+			SetSchemaVersion(dashboard.Data, version)
+		}
+
+		mapData, err := dashboard.Data.Value()
+		if err != nil {
+			zap.L().Error("Error in marshalling data field in dashboard", zap.String("uuid", dashboard.Uuid), zap.Error(err))
+			continue
+		}
+
+		_, err = db.Exec(`UPDATE dashboards SET data=?, schema_version=? WHERE uuid=?`, mapData, version, dashboard.Uuid)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
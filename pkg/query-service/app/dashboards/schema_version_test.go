@@ -0,0 +1,69 @@
+package dashboards_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestGetDashboardsBySchemaVersion(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	current, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "current"}, nil)
+	require.Nil(apiErr)
+	require.Equal(dashboards.SchemaVersionCurrent, dashboards.GetSchemaVersion(current.Data))
+
+	legacyData := map[string]interface{}{
+		"title": "legacy",
+		"widgets": []interface{}{
+			map[string]interface{}{
+				"id": "a",
+				"query": map[string]interface{}{
+					"queryType": "clickhouse_sql",
+					"clickhouse_sql": []interface{}{
+						map[string]interface{}{"query": "SELECT * FROM signoz_metrics.time_series_v2"},
+					},
+				},
+			},
+		},
+	}
+	legacy, apiErr := dashboards.CreateDashboard(ctx, legacyData, nil)
+	require.Nil(apiErr)
+	require.Equal(dashboards.SchemaVersionLegacy, dashboards.GetSchemaVersion(legacy.Data))
+
+	// a dashboard stored before versions were tracked is backfilled from its shape
+	_, err := sqlStore.SQLxDB().Exec(
+		`INSERT INTO dashboards (uuid, created_at, created_by, updated_at, updated_by, data) VALUES (?, ?, ?, ?, ?, ?)`,
+		"unversioned", time.Now(), "bob@signoz.io", time.Now(), "bob@signoz.io", `{"title": "unversioned"}`,
+	)
+	require.NoError(err)
+	require.NoError(dashboards.BackfillSchemaVersions(context.Background()))
+
+	got, apiErr := dashboards.GetDashboardsBySchemaVersion(ctx, dashboards.SchemaVersionLegacy)
+	require.Nil(apiErr)
+	require.Len(got, 1)
+	require.Equal(legacy.Uuid, got[0].Uuid)
+
+	got, apiErr = dashboards.GetDashboardsBySchemaVersion(ctx, dashboards.SchemaVersionCurrent)
+	require.Nil(apiErr)
+	require.Len(got, 2)
+
+	// updates from clients unaware of the version keep the stored one
+	_, apiErr = dashboards.UpdateDashboard(ctx, legacy.Uuid, map[string]interface{}{"title": "legacy"}, false, 0, nil)
+	require.Nil(apiErr)
+	got, apiErr = dashboards.GetDashboardsBySchemaVersion(ctx, dashboards.SchemaVersionLegacy)
+	require.Nil(apiErr)
+	require.Len(got, 1)
+
+	// the dashboards in the trash are left out
+	require.Nil(dashboards.DeleteDashboard(ctx, legacy.Uuid, false, nil))
+	got, apiErr = dashboards.GetDashboardsBySchemaVersion(ctx, dashboards.SchemaVersionLegacy)
+	require.Nil(apiErr)
+	require.Empty(got)
+}
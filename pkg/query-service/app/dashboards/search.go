@@ -0,0 +1,136 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// searchContent extracts the searchable text of a dashboard: its title,
+// description, tags, widget titles and query text
+func searchContent(data Data) string {
+	parts := []string{}
+	add := func(value interface{}) {
+		if s, ok := value.(string); ok && strings.TrimSpace(s) != "" {
+			parts = append(parts, strings.ToLower(s))
+		}
+	}
+
+	add(data["title"])
+	add(data["description"])
+	if tags, ok := data["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			add(tag)
+		}
+	}
+
+	widgets, _ := data["widgets"].([]interface{})
+	for _, w := range widgets {
+		widget, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		add(widget["title"])
+		add(widget["description"])
+
+		query, ok := widget["query"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, queryType := range []string{"promql", "clickhouse_sql"} {
+			queries, _ := query[queryType].([]interface{})
+			for _, q := range queries {
+				if q, ok := q.(map[string]interface{}); ok {
+					add(q["query"])
+				}
+			}
+		}
+		builder, _ := query["builder"].(map[string]interface{})
+		queryData, _ := builder["queryData"].([]interface{})
+		for _, q := range queryData {
+			builderQuery, ok := q.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if attribute, ok := builderQuery["aggregateAttribute"].(map[string]interface{}); ok {
+				add(attribute["key"])
+			}
+			add(builderQuery["expression"])
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// indexDashboard replaces the content of the dashboard in the search index,
+// in the transaction saving the dashboard
+func indexDashboard(tx *storeTx, uuid string, data Data) error {
+	_, err := tx.Exec(`INSERT INTO dashboard_search_index (dashboard_uuid, content, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (dashboard_uuid) DO UPDATE SET content=excluded.content, updated_at=excluded.updated_at`,
+		uuid, searchContent(data), time.Now())
+	return err
+}
+
+// IndexDashboards adds the dashboards missing from the search index, such as
+// the ones created before the index existed
+func IndexDashboards(ctx context.Context) error {
+	dashboards := []Dashboard{}
+	err := db.Select(&dashboards, `SELECT * FROM dashboards WHERE uuid NOT IN (SELECT dashboard_uuid FROM dashboard_search_index)`)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, dashboard := range dashboards {
+		if err := indexDashboard(tx, dashboard.Uuid, dashboard.Data); err != nil {
+			return fmt.Errorf("failed to index dashboard %s: %w", dashboard.Uuid, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SearchDashboards returns the dashboards whose title, description, tags,
+// widget titles or queries contain every term of the query
+func SearchDashboards(ctx context.Context, q string) ([]Dashboard, *model.ApiError) {
+	terms := strings.Fields(strings.ToLower(q))
+	if len(terms) == 0 {
+		return nil, model.BadRequest(fmt.Errorf("search query must not be empty"))
+	}
+
+	scope, args := orgScope(ctx)
+	query := `SELECT d.* FROM dashboards d JOIN dashboard_search_index s ON s.dashboard_uuid = d.uuid WHERE d.deleted_at IS NULL AND d.archived_at IS NULL` + scope
+	for _, term := range terms {
+		query += ` AND s.content LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLikePattern(term)+"%")
+	}
+	query += ` ORDER BY d.updated_at DESC`
+
+	dashboards := []Dashboard{}
+	err := db.Select(&dashboards, query, args...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return dashboards, nil
+}
+
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func pruneSearchIndex() {
+	_, err := db.Exec(`DELETE FROM dashboard_search_index WHERE dashboard_uuid NOT IN (SELECT uuid FROM dashboards)`)
+	if err != nil {
+		zap.L().Error("Error in removing purged dashboards from the search index", zap.Error(err))
+	}
+}
@@ -0,0 +1,61 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestSearchDashboards(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	kafka, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title":       "Kafka",
+		"description": "Consumer lag of the checkout_service",
+		"widgets": []interface{}{
+			map[string]interface{}{
+				"id":    "lag",
+				"title": "Lag per partition",
+				"query": map[string]interface{}{
+					"promql": []interface{}{map[string]interface{}{"query": "sum(kafka_consumer_lag) by (partition)"}},
+				},
+			},
+		},
+	}, nil)
+	require.Nil(apiErr)
+	postgres, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "Postgres"}, nil)
+	require.Nil(apiErr)
+
+	uuids := func(q string) []string {
+		result, apiErr := dashboards.SearchDashboards(ctx, q)
+		require.Nil(apiErr)
+		list := []string{}
+		for _, dashboard := range result {
+			list = append(list, dashboard.Uuid)
+		}
+		return list
+	}
+
+	require.Equal([]string{kafka.Uuid}, uuids("PARTITION"))
+	require.Equal([]string{kafka.Uuid}, uuids("consumer_lag kafka"))
+	require.Equal([]string{kafka.Uuid}, uuids("checkout_service"))
+	require.Empty(uuids("checkout%"))
+	require.Empty(uuids("kafka postgres"))
+
+	// updates are reflected in the index
+	_, apiErr = dashboards.UpdateDashboard(ctx, postgres.Uuid, map[string]interface{}{"title": "Postgres replication"}, false, 0, nil)
+	require.Nil(apiErr)
+	require.Equal([]string{postgres.Uuid}, uuids("replication"))
+
+	// dashboards in the trash are not returned
+	require.Nil(dashboards.DeleteDashboard(ctx, postgres.Uuid, false, nil))
+	require.Empty(uuids("replication"))
+
+	_, apiErr = dashboards.SearchDashboards(ctx, "  ")
+	require.Equal(model.ErrorBadData, apiErr.Type())
+}
@@ -0,0 +1,259 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+const (
+	ServiceSourceManual   = "manual"
+	ServiceSourceInferred = "inferred"
+)
+
+// serviceAttributes are the attributes holding the service name in queries
+var serviceAttributes = map[string]bool{
+	"service.name": true,
+	"service_name": true,
+	"serviceName":  true,
+}
+
+// serviceMatcher matches the service in promql selectors and clickhouse
+// conditions, e.g. service_name="cart" or serviceName = 'cart'
+var serviceMatcher = regexp.MustCompile(`\b(?:service\.name|service_name|serviceName)['"]?\s*=\s*['"]([^'"{}$]+)['"]`)
+
+// ServiceDashboard is a dashboard associated with a service, either by hand
+// or because its panels query the service
+type ServiceDashboard struct {
+	ServiceName   string    `json:"service_name" db:"service_name"`
+	DashboardUuid string    `json:"dashboard_uuid" db:"dashboard_uuid"`
+	Title         string    `json:"title" db:"title"`
+	Source        string    `json:"source" db:"source"`
+	CreatedBy     string    `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// dashboardServices returns the services the panels of the dashboard are
+// filtered by. Filters on variables are left out, they depend on the selection.
+func dashboardServices(data Data) []string {
+	services := map[string]bool{}
+	add := func(value interface{}) {
+		if s, ok := value.(string); ok && s != "" && !strings.Contains(s, "{{") && !strings.HasPrefix(s, "$") {
+			services[s] = true
+		}
+	}
+
+	widgets, _ := data["widgets"].([]interface{})
+	for _, w := range widgets {
+		widget, _ := w.(map[string]interface{})
+		query, _ := widget["query"].(map[string]interface{})
+
+		builder, _ := query["builder"].(map[string]interface{})
+		queryData, _ := builder["queryData"].([]interface{})
+		for _, qd := range queryData {
+			q, _ := qd.(map[string]interface{})
+			filters, _ := q["filters"].(map[string]interface{})
+			items, _ := filters["items"].([]interface{})
+			for _, i := range items {
+				item, _ := i.(map[string]interface{})
+				key, _ := item["key"].(map[string]interface{})
+				name, _ := key["key"].(string)
+				op, _ := item["op"].(string)
+				if !serviceAttributes[name] || (op != "=" && op != "in") {
+					continue
+				}
+				if values, ok := item["value"].([]interface{}); ok {
+					for _, value := range values {
+						add(value)
+					}
+				} else {
+					add(item["value"])
+				}
+			}
+		}
+
+		for _, key := range []string{"promql", "clickhouse_sql"} {
+			queries, _ := query[key].([]interface{})
+			for _, q := range queries {
+				promql, _ := q.(map[string]interface{})
+				text, _ := promql["query"].(string)
+				for _, match := range serviceMatcher.FindAllStringSubmatch(text, -1) {
+					add(match[1])
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// indexDashboardServices replaces the services inferred from the panels of
+// the dashboard, the services associated by hand are kept
+func indexDashboardServices(tx *storeTx, uuid string, data Data) error {
+	if _, err := tx.Exec(`DELETE FROM dashboard_services WHERE dashboard_uuid=? AND source=?`, uuid, ServiceSourceInferred); err != nil {
+		return err
+	}
+	for _, service := range dashboardServices(data) {
+		_, err := tx.Exec(`INSERT INTO dashboard_services (service_name, dashboard_uuid, source, created_by, created_at) VALUES (?, ?, ?, ?, ?)`,
+			service, uuid, ServiceSourceInferred, "", time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IndexDashboardServices infers the services of all dashboards if none were
+// inferred yet, such as when the mapping was just created
+func IndexDashboardServices(ctx context.Context) error {
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM dashboard_services WHERE source=?`, ServiceSourceInferred); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	dashboards := []Dashboard{}
+	if err := db.Select(&dashboards, `SELECT uuid, data FROM dashboards`); err != nil {
+		return err
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, dashboard := range dashboards {
+		if err := indexDashboardServices(tx, dashboard.Uuid, dashboard.Data); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetServiceDashboards returns the dashboards associated with the service,
+// the ones associated by hand first
+func GetServiceDashboards(ctx context.Context, service string) ([]ServiceDashboard, *model.ApiError) {
+	scope, args := orgScope(ctx)
+	query := `SELECT s.service_name, s.dashboard_uuid, d.title, s.source, s.created_by, s.created_at
+		FROM dashboard_services s JOIN dashboards d ON d.uuid = s.dashboard_uuid
+		WHERE s.service_name=? AND d.deleted_at IS NULL AND d.archived_at IS NULL` + scope + `
+		ORDER BY s.source = 'manual' DESC, d.title`
+
+	services := []ServiceDashboard{}
+	err := db.Select(&services, query, append([]interface{}{service}, args...)...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	// a dashboard both associated by hand and inferred is listed once
+	dashboards := []ServiceDashboard{}
+	seen := map[string]bool{}
+	for _, s := range services {
+		if !seen[s.DashboardUuid] {
+			seen[s.DashboardUuid] = true
+			dashboards = append(dashboards, s)
+		}
+	}
+	return dashboards, nil
+}
+
+// GetDashboardServices returns the services associated with the dashboard
+func GetDashboardServices(ctx context.Context, uuid string) ([]ServiceDashboard, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	services := []ServiceDashboard{}
+	err := db.Select(&services, `SELECT * FROM dashboard_services WHERE dashboard_uuid=? ORDER BY service_name, source`, uuid)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	for i := range services {
+		services[i].Title = dashboard.Title
+	}
+	return services, nil
+}
+
+// AddDashboardService associates the dashboard with the service by hand
+func AddDashboardService(ctx context.Context, uuid string, service string) (*ServiceDashboard, *model.ApiError) {
+	service = strings.TrimSpace(service)
+	if service == "" {
+		return nil, model.BadRequest(fmt.Errorf("service is required"))
+	}
+
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var userEmail string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		userEmail = user.Email
+	}
+
+	mapping := &ServiceDashboard{
+		ServiceName:   service,
+		DashboardUuid: uuid,
+		Title:         dashboard.Title,
+		Source:        ServiceSourceManual,
+		CreatedBy:     userEmail,
+		CreatedAt:     time.Now(),
+	}
+	result, err := db.Exec(`INSERT INTO dashboard_services (service_name, dashboard_uuid, source, created_by, created_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT(service_name, dashboard_uuid, source) DO NOTHING`,
+		mapping.ServiceName, mapping.DashboardUuid, mapping.Source, mapping.CreatedBy, mapping.CreatedAt)
+	if err != nil {
+		zap.L().Error("Error in associating dashboard with service", zap.String("uuid", uuid), zap.String("service", service), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if affectedRows, err := result.RowsAffected(); err == nil && affectedRows == 0 {
+		return nil, &model.ApiError{Typ: model.ErrorConflict, Err: fmt.Errorf("dashboard %s is already associated with service %s", uuid, service)}
+	}
+
+	return mapping, nil
+}
+
+// RemoveDashboardService removes the association of the dashboard with the
+// service made by hand, inferred associations follow the panels
+func RemoveDashboardService(ctx context.Context, uuid string, service string) *model.ApiError {
+	if _, apiErr := GetDashboard(ctx, uuid); apiErr != nil {
+		return apiErr
+	}
+
+	result, err := db.Exec(`DELETE FROM dashboard_services WHERE dashboard_uuid=? AND service_name=? AND source=?`, uuid, service, ServiceSourceManual)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if affectedRows == 0 {
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("dashboard %s is not associated with service %s by hand", uuid, service)}
+	}
+	return nil
+}
+
+// pruneServiceIndex removes the purged dashboards from the service mapping
+func pruneServiceIndex() {
+	_, err := db.Exec(`DELETE FROM dashboard_services WHERE dashboard_uuid NOT IN (SELECT uuid FROM dashboards)`)
+	if err != nil {
+		zap.L().Error("Error in removing purged dashboards from the service mapping", zap.Error(err))
+	}
+}
@@ -0,0 +1,82 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func serviceWidget(id string, services ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"id": id,
+		"query": map[string]interface{}{
+			"queryType": "builder",
+			"builder": map[string]interface{}{
+				"queryData": []interface{}{map[string]interface{}{
+					"dataSource": "traces",
+					"filters": map[string]interface{}{"op": "AND", "items": []interface{}{
+						map[string]interface{}{"key": map[string]interface{}{"key": "service.name"}, "op": "in", "value": services},
+					}},
+				}},
+			},
+		},
+	}
+}
+
+func TestServiceDashboards(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	checkout, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "checkout",
+		"widgets": []interface{}{
+			serviceWidget("latency", "checkout", "{{.service}}"),
+			map[string]interface{}{
+				"id": "errors",
+				"query": map[string]interface{}{
+					"queryType": "promql",
+					"promql":    []interface{}{map[string]interface{}{"query": `sum(rate(signoz_calls_total{service_name="payment"}[5m]))`}},
+				},
+			},
+		},
+	}, nil)
+	require.Nil(apiErr)
+	overview, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "overview"}, nil)
+	require.Nil(apiErr)
+
+	services, apiErr := dashboards.GetDashboardServices(ctx, checkout.Uuid)
+	require.Nil(apiErr)
+	require.Len(services, 2)
+	require.Equal("checkout", services[0].ServiceName)
+	require.Equal("payment", services[1].ServiceName)
+	require.Equal(dashboards.ServiceSourceInferred, services[0].Source)
+
+	_, apiErr = dashboards.AddDashboardService(ctx, overview.Uuid, "checkout")
+	require.Nil(apiErr)
+	_, apiErr = dashboards.AddDashboardService(ctx, overview.Uuid, "checkout")
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorConflict, apiErr.Type())
+
+	serviceDashboards, apiErr := dashboards.GetServiceDashboards(ctx, "checkout")
+	require.Nil(apiErr)
+	require.Len(serviceDashboards, 2)
+	require.Equal(overview.Uuid, serviceDashboards[0].DashboardUuid)
+	require.Equal(dashboards.ServiceSourceManual, serviceDashboards[0].Source)
+	require.Equal("checkout", serviceDashboards[1].Title)
+
+	// the inferred services follow the panels
+	_, apiErr = dashboards.UpdateDashboard(ctx, checkout.Uuid, map[string]interface{}{"title": "checkout"}, true, 0, nil)
+	require.Nil(apiErr)
+	serviceDashboards, apiErr = dashboards.GetServiceDashboards(ctx, "payment")
+	require.Nil(apiErr)
+	require.Empty(serviceDashboards)
+
+	require.Nil(dashboards.RemoveDashboardService(ctx, overview.Uuid, "checkout"))
+	apiErr = dashboards.RemoveDashboardService(ctx, overview.Uuid, "checkout")
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+}
@@ -0,0 +1,162 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// errShareSecretNotSet is returned when sharing dashboards is not configured,
+// tokens signed with an empty secret could be forged by anyone
+var errShareSecretNotSet = &model.ApiError{Typ: model.ErrorUnavailable, Err: errors.New("dashboard sharing is disabled, the share secret is not set")}
+
+// DashboardShare grants read only access to a single dashboard to anyone
+// holding its token, until it expires or is revoked
+type DashboardShare struct {
+	Id            string     `json:"id" db:"id"`
+	DashboardUuid string     `json:"dashboard_uuid" db:"dashboard_uuid"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	CreatedBy     string     `json:"created_by" db:"created_by"`
+	ExpiresAt     *time.Time `json:"expires_at" db:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	Token         string     `json:"token,omitempty" db:"-"`
+}
+
+// sign sets the token of the share. The claims only hold the share id, the
+// dashboard and the expiry so the token can be handed out again when listing.
+func (s *DashboardShare) sign(secret string) error {
+	claims := jwt.RegisteredClaims{
+		ID:      s.Id,
+		Subject: s.DashboardUuid,
+	}
+	if s.ExpiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*s.ExpiresAt)
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return err
+	}
+	s.Token = token
+	return nil
+}
+
+// CreateDashboardShare creates a share for the dashboard, expiresAt is optional
+func CreateDashboardShare(ctx context.Context, dashboardUuid string, expiresAt *time.Time, secret string) (*DashboardShare, *model.ApiError) {
+	if secret == "" {
+		return nil, errShareSecretNotSet
+	}
+	if _, apiErr := GetDashboard(ctx, dashboardUuid); apiErr != nil {
+		return nil, apiErr
+	}
+	if expiresAt != nil && expiresAt.Before(time.Now()) {
+		return nil, model.BadRequest(fmt.Errorf("expiry must be in the future"))
+	}
+
+	var userEmail string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		userEmail = user.Email
+	}
+
+	share := &DashboardShare{
+		Id:            uuid.New().String(),
+		DashboardUuid: dashboardUuid,
+		CreatedAt:     time.Now(),
+		CreatedBy:     userEmail,
+		ExpiresAt:     expiresAt,
+	}
+
+	_, err := db.Exec(`INSERT INTO dashboard_shares (id, dashboard_uuid, created_at, created_by, expires_at) VALUES (?, ?, ?, ?, ?)`,
+		share.Id, share.DashboardUuid, share.CreatedAt, share.CreatedBy, share.ExpiresAt)
+	if err != nil {
+		zap.L().Error("Error in creating dashboard share", zap.String("uuid", dashboardUuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	if err := share.sign(secret); err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+
+	return share, nil
+}
+
+// GetDashboardShares returns the shares of the dashboard which have not been revoked
+func GetDashboardShares(ctx context.Context, dashboardUuid string, secret string) ([]DashboardShare, *model.ApiError) {
+	if secret == "" {
+		return nil, errShareSecretNotSet
+	}
+	if _, apiErr := GetDashboard(ctx, dashboardUuid); apiErr != nil {
+		return nil, apiErr
+	}
+
+	shares := []DashboardShare{}
+	err := db.Select(&shares, `SELECT * FROM dashboard_shares WHERE dashboard_uuid=? AND revoked_at IS NULL ORDER BY created_at DESC`, dashboardUuid)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	for i := range shares {
+		if err := shares[i].sign(secret); err != nil {
+			return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
+		}
+	}
+
+	return shares, nil
+}
+
+// RevokeDashboardShare revokes the share, its token stops working immediately
+func RevokeDashboardShare(ctx context.Context, dashboardUuid string, id string) *model.ApiError {
+	if _, apiErr := GetDashboard(ctx, dashboardUuid); apiErr != nil {
+		return apiErr
+	}
+
+	result, err := db.Exec(`UPDATE dashboard_shares SET revoked_at=? WHERE id=? AND dashboard_uuid=? AND revoked_at IS NULL`, time.Now(), id, dashboardUuid)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if affectedRows == 0 {
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no active share found with id: %s", id)}
+	}
+
+	return nil
+}
+
+// GetSharedDashboard returns the dashboard the token grants access to
+func GetSharedDashboard(ctx context.Context, token string, secret string) (*Dashboard, *model.ApiError) {
+	if secret == "" {
+		return nil, errShareSecretNotSet
+	}
+	claims := jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unknown signing algo: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorUnauthorized, Err: fmt.Errorf("invalid share token: %w", err)}
+	}
+
+	share := DashboardShare{}
+	err = db.Get(&share, `SELECT * FROM dashboard_shares WHERE id=? AND dashboard_uuid=? AND revoked_at IS NULL`, claims.ID, claims.Subject)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorUnauthorized, Err: errors.New("share has been revoked")}
+	}
+	if share.ExpiresAt != nil && share.ExpiresAt.Before(time.Now()) {
+		return nil, &model.ApiError{Typ: model.ErrorUnauthorized, Err: errors.New("share has expired")}
+	}
+
+	return GetDashboard(ctx, share.DashboardUuid)
+}
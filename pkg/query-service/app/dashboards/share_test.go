@@ -0,0 +1,67 @@
+package dashboards_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardShares(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+	secret := "secret"
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "status"}, nil)
+	require.Nil(apiErr)
+	other, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "other"}, nil)
+	require.Nil(apiErr)
+
+	share, apiErr := dashboards.CreateDashboardShare(ctx, dashboard.Uuid, nil, secret)
+	require.Nil(apiErr)
+	require.NotEmpty(share.Token)
+	require.Equal("alice@signoz.io", share.CreatedBy)
+
+	shared, apiErr := dashboards.GetSharedDashboard(ctx, share.Token, secret)
+	require.Nil(apiErr)
+	require.Equal(dashboard.Uuid, shared.Uuid)
+
+	// tokens signed with another secret are rejected
+	_, apiErr = dashboards.GetSharedDashboard(ctx, share.Token, "another secret")
+	require.Equal(model.ErrorUnauthorized, apiErr.Type())
+
+	// sharing is disabled without a secret
+	_, apiErr = dashboards.CreateDashboardShare(ctx, dashboard.Uuid, nil, "")
+	require.Equal(model.ErrorUnavailable, apiErr.Type())
+	_, apiErr = dashboards.GetDashboardShares(ctx, dashboard.Uuid, "")
+	require.Equal(model.ErrorUnavailable, apiErr.Type())
+	_, apiErr = dashboards.GetSharedDashboard(ctx, share.Token, "")
+	require.Equal(model.ErrorUnavailable, apiErr.Type())
+
+	shares, apiErr := dashboards.GetDashboardShares(ctx, dashboard.Uuid, secret)
+	require.Nil(apiErr)
+	require.Len(shares, 1)
+	require.Equal(share.Token, shares[0].Token)
+
+	// shares are scoped to their dashboard
+	require.Equal(model.ErrorNotFound, dashboards.RevokeDashboardShare(ctx, other.Uuid, share.Id).Type())
+
+	require.Nil(dashboards.RevokeDashboardShare(ctx, dashboard.Uuid, share.Id))
+	_, apiErr = dashboards.GetSharedDashboard(ctx, share.Token, secret)
+	require.Equal(model.ErrorUnauthorized, apiErr.Type())
+
+	past := time.Now().Add(-time.Hour)
+	_, apiErr = dashboards.CreateDashboardShare(ctx, dashboard.Uuid, &past, secret)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	// shares of deleted dashboards stop working
+	share, apiErr = dashboards.CreateDashboardShare(ctx, dashboard.Uuid, nil, secret)
+	require.Nil(apiErr)
+	require.Nil(dashboards.DeleteDashboard(ctx, dashboard.Uuid, false, nil))
+	_, apiErr = dashboards.GetSharedDashboard(ctx, share.Token, secret)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+}
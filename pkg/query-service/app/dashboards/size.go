@@ -0,0 +1,40 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+// checkDashboardSize rejects dashboards larger than the configured limits, a
+// pasted panel config of megabytes would slow down listing dashboards for
+// everyone
+func checkDashboardSize(data map[string]interface{}) *model.ApiError {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return model.BadRequest(err)
+	}
+	if maxSize := constants.GetDashboardsMaxDataSize(); len(raw) > maxSize {
+		return &model.ApiError{Typ: model.ErrorTooLarge, Err: fmt.Errorf("dashboard is %d bytes, the limit is %d bytes", len(raw), maxSize)}
+	}
+
+	maxQuerySize := constants.GetDashboardsMaxQuerySize()
+	widgets, _ := data["widgets"].([]interface{})
+	for _, w := range widgets {
+		widget, ok := w.(map[string]interface{})
+		if !ok || widget["query"] == nil {
+			continue
+		}
+		query, err := json.Marshal(widget["query"])
+		if err != nil {
+			return model.BadRequest(err)
+		}
+		if len(query) > maxQuerySize {
+			return &model.ApiError{Typ: model.ErrorTooLarge, Err: fmt.Errorf("query of widget %s is %d bytes, the limit is %d bytes", auditWidgetName(widget), len(query), maxQuerySize)}
+		}
+	}
+
+	return nil
+}
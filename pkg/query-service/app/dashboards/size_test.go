@@ -0,0 +1,45 @@
+package dashboards
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+func TestCheckDashboardSize(t *testing.T) {
+	t.Setenv("DASHBOARDS_MAX_DATA_SIZE", "2048")
+	t.Setenv("DASHBOARDS_MAX_QUERY_SIZE", "256")
+
+	widget := func(query string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":    "errors",
+			"title": "Errors",
+			"query": map[string]interface{}{
+				"queryType": "promql",
+				"promql":    []interface{}{map[string]interface{}{"query": query}},
+			},
+		}
+	}
+
+	require.Nil(t, checkDashboardSize(map[string]interface{}{
+		"title":   "services",
+		"widgets": []interface{}{widget("sum(rate(errors[5m]))")},
+	}))
+
+	apiErr := checkDashboardSize(map[string]interface{}{
+		"title":   "services",
+		"widgets": []interface{}{widget(strings.Repeat("x", 300))},
+	})
+	require.NotNil(t, apiErr)
+	require.Equal(t, model.ErrorTooLarge, apiErr.Type())
+	require.Contains(t, apiErr.Error(), `query of widget "Errors"`)
+
+	apiErr = checkDashboardSize(map[string]interface{}{
+		"title":       "services",
+		"description": strings.Repeat("x", 4096),
+	})
+	require.NotNil(t, apiErr)
+	require.Equal(t, model.ErrorTooLarge, apiErr.Type())
+}
@@ -0,0 +1,69 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// uniqueSlug returns the slug, suffixed with a number if another dashboard
+// outside the trash already has it
+func uniqueSlug(q Store, slug string, uuid string) (string, error) {
+	candidate := slug
+	for i := 2; ; i++ {
+		var count int
+		err := q.Get(&count, `SELECT COUNT(*) FROM dashboards WHERE slug=? AND uuid!=? AND deleted_at IS NULL`, candidate, uuid)
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+}
+
+// GetDashboardBySlug returns the dashboard with the slug. The slug is set when
+// the dashboard is created and kept when it is renamed, so links using it
+// stay valid.
+func GetDashboardBySlug(ctx context.Context, slug string) (*Dashboard, *model.ApiError) {
+	dashboard := Dashboard{}
+	scope, args := orgScope(ctx)
+	query := `SELECT * FROM dashboards WHERE slug=? AND deleted_at IS NULL` + scope
+
+	err := db.Get(&dashboard, query, append([]interface{}{slug}, args...)...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no dashboard found with slug: %s", slug)}
+	}
+
+	return &dashboard, nil
+}
+
+// BackfillDashboardSlugs sets the slug of the dashboards created before slugs
+// were stored, the oldest dashboard keeps the plain slug of a title
+func BackfillDashboardSlugs(ctx context.Context) error {
+	var dashboards []Dashboard
+	err := db.Select(&dashboards, `SELECT * FROM dashboards WHERE slug='' ORDER BY id`)
+	if err != nil {
+		return err
+	}
+
+	for i := range dashboards {
+		dashboard := &dashboards[i]
+		dashboard.UpdateSlug()
+		slug, err := uniqueSlug(db, dashboard.Slug, dashboard.Uuid)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec(`UPDATE dashboards SET slug=? WHERE uuid=?`, slug, dashboard.Uuid)
+		if err != nil {
+			zap.L().Error("Error in setting dashboard slug", zap.String("uuid", dashboard.Uuid), zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardSlugs(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	first, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "Kafka Overview"}, nil)
+	require.Nil(apiErr)
+	require.Equal("kafka-overview", first.Slug)
+
+	second, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "Kafka overview"}, nil)
+	require.Nil(apiErr)
+	require.Equal("kafka-overview-2", second.Slug)
+
+	found, apiErr := dashboards.GetDashboardBySlug(ctx, "kafka-overview-2")
+	require.Nil(apiErr)
+	require.Equal(second.Uuid, found.Uuid)
+
+	// the slug is kept when the dashboard is renamed
+	_, apiErr = dashboards.UpdateDashboard(ctx, first.Uuid, map[string]interface{}{"title": "Brokers"}, false, 0, nil)
+	require.Nil(apiErr)
+	found, apiErr = dashboards.GetDashboardBySlug(ctx, "kafka-overview")
+	require.Nil(apiErr)
+	require.Equal(first.Uuid, found.Uuid)
+
+	// a recreated dashboard takes over the slug of the deleted one
+	require.Nil(dashboards.DeleteDashboard(ctx, first.Uuid, false, nil))
+	recreated, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "Kafka Overview"}, nil)
+	require.Nil(apiErr)
+	require.Equal("kafka-overview", recreated.Slug)
+
+	restored, apiErr := dashboards.RestoreDashboard(ctx, first.Uuid)
+	require.Nil(apiErr)
+	require.Equal("kafka-overview-3", restored.Slug)
+
+	_, apiErr = dashboards.GetDashboardBySlug(ctx, "kafka")
+	require.NotNil(apiErr)
+}
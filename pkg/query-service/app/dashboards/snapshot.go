@@ -0,0 +1,163 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// WidgetSnapshot holds the frozen result of a widget's queries, or the error
+// the queries failed with
+type WidgetSnapshot struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// SnapshotResults maps the widget ids to their snapshots
+type SnapshotResults map[string]WidgetSnapshot
+
+func (r *SnapshotResults) Scan(src interface{}) error {
+	var data []byte
+	if b, ok := src.([]byte); ok {
+		data = b
+	} else if s, ok := src.(string); ok {
+		data = []byte(s)
+	}
+	return json.Unmarshal(data, r)
+}
+
+// DashboardSnapshot is a copy of a dashboard along with the results of its
+// queries for a fixed time range
+type DashboardSnapshot struct {
+	Id            string          `json:"id" db:"id"`
+	DashboardUuid string          `json:"dashboard_uuid" db:"dashboard_uuid"`
+	Title         string          `json:"title" db:"title"`
+	StartTime     int64           `json:"start" db:"start_time"`
+	EndTime       int64           `json:"end" db:"end_time"`
+	Data          Data            `json:"data,omitempty" db:"data"`
+	Results       SnapshotResults `json:"results,omitempty" db:"results"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	CreatedBy     string          `json:"created_by" db:"created_by"`
+}
+
+// CreateDashboardSnapshot runs the queries of every widget of the dashboard
+// for the time range with runQuery and stores the results. A failing widget
+// does not fail the snapshot, its error is stored instead.
+func CreateDashboardSnapshot(
+	ctx context.Context,
+	dashboardUuid string,
+	start, end int64,
+	runQuery func(data Data, widgetId string) (interface{}, error),
+) (*DashboardSnapshot, *model.ApiError) {
+	if start >= end {
+		return nil, model.BadRequest(fmt.Errorf("start must be before end"))
+	}
+
+	dashboard, apiErr := GetDashboard(ctx, dashboardUuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var userEmail string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		userEmail = user.Email
+	}
+
+	snapshot := &DashboardSnapshot{
+		Id:            uuid.New().String(),
+		DashboardUuid: dashboard.Uuid,
+		Title:         extractDashboardName(dashboard.Data),
+		StartTime:     start,
+		EndTime:       end,
+		Data:          dashboard.Data,
+		Results:       SnapshotResults{},
+		CreatedAt:     time.Now(),
+		CreatedBy:     userEmail,
+	}
+
+	for _, widgetId := range getWidgetIds(dashboard.Data) {
+		result, err := runQuery(dashboard.Data, widgetId)
+		if err != nil {
+			snapshot.Results[widgetId] = WidgetSnapshot{Error: err.Error()}
+			continue
+		}
+		snapshot.Results[widgetId] = WidgetSnapshot{Result: result}
+	}
+
+	data, err := json.Marshal(snapshot.Data)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	results, err := json.Marshal(snapshot.Results)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	_, err = db.Exec(`INSERT INTO dashboard_snapshots (id, dashboard_uuid, title, start_time, end_time, data, results, created_at, created_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snapshot.Id, snapshot.DashboardUuid, snapshot.Title, snapshot.StartTime, snapshot.EndTime, data, results, snapshot.CreatedAt, snapshot.CreatedBy)
+	if err != nil {
+		zap.L().Error("Error in creating dashboard snapshot", zap.String("uuid", dashboardUuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return snapshot, nil
+}
+
+// GetDashboardSnapshots lists the snapshots of a dashboard, newest first,
+// without their data and results
+func GetDashboardSnapshots(ctx context.Context, dashboardUuid string) ([]DashboardSnapshot, *model.ApiError) {
+	if _, apiErr := GetDashboard(ctx, dashboardUuid); apiErr != nil {
+		return nil, apiErr
+	}
+
+	snapshots := []DashboardSnapshot{}
+	query := `SELECT id, dashboard_uuid, title, start_time, end_time, created_at, created_by FROM dashboard_snapshots WHERE dashboard_uuid=? ORDER BY created_at DESC`
+
+	err := db.Select(&snapshots, query, dashboardUuid)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return snapshots, nil
+}
+
+// GetDashboardSnapshot returns the snapshot of a dashboard of the org of the
+// user
+func GetDashboardSnapshot(ctx context.Context, id string) (*DashboardSnapshot, *model.ApiError) {
+	snapshot := DashboardSnapshot{}
+	scope, args := orgScope(ctx)
+	query := `SELECT s.* FROM dashboard_snapshots s JOIN dashboards d ON d.uuid = s.dashboard_uuid WHERE s.id=?` + scope
+	err := db.Get(&snapshot, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no snapshot found with id: %s", id)}
+	}
+
+	return &snapshot, nil
+}
+
+func DeleteDashboardSnapshot(ctx context.Context, id string) *model.ApiError {
+	if _, apiErr := GetDashboardSnapshot(ctx, id); apiErr != nil {
+		return apiErr
+	}
+
+	result, err := db.Exec(`DELETE FROM dashboard_snapshots WHERE id=?`, id)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if affectedRows == 0 {
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no snapshot found with id: %s", id)}
+	}
+
+	return nil
+}
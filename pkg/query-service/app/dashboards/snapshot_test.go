@@ -0,0 +1,62 @@
+package dashboards_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardSnapshots(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "incident",
+		"widgets": []interface{}{
+			map[string]interface{}{"id": "ok", "query": map[string]interface{}{}},
+			map[string]interface{}{"id": "failing", "query": map[string]interface{}{}},
+			map[string]interface{}{"id": "row", "panelTypes": "row"},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	runQuery := func(data dashboards.Data, widgetId string) (interface{}, error) {
+		if widgetId == "failing" {
+			return nil, errors.New("query failed")
+		}
+		return []interface{}{"series"}, nil
+	}
+
+	_, apiErr = dashboards.CreateDashboardSnapshot(ctx, dashboard.Uuid, 2000, 1000, runQuery)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	snapshot, apiErr := dashboards.CreateDashboardSnapshot(ctx, dashboard.Uuid, 1000, 2000, runQuery)
+	require.Nil(apiErr)
+	require.Len(snapshot.Results, 2)
+
+	// later changes to the dashboard do not alter the snapshot
+	_, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "renamed", "widgets": dashboard.Data["widgets"]}, false, 0, nil)
+	require.Nil(apiErr)
+
+	stored, apiErr := dashboards.GetDashboardSnapshot(ctx, snapshot.Id)
+	require.Nil(apiErr)
+	require.Equal("incident", stored.Title)
+	require.Equal("incident", stored.Data["title"])
+	require.Equal(int64(1000), stored.StartTime)
+	require.Equal([]interface{}{"series"}, stored.Results["ok"].Result)
+	require.Equal("query failed", stored.Results["failing"].Error)
+
+	list, apiErr := dashboards.GetDashboardSnapshots(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Len(list, 1)
+	require.Nil(list[0].Data)
+
+	require.Nil(dashboards.DeleteDashboardSnapshot(ctx, snapshot.Id))
+	_, apiErr = dashboards.GetDashboardSnapshot(ctx, snapshot.Id)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+}
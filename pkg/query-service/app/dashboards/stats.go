@@ -0,0 +1,122 @@
+package dashboards
+
+import (
+	"context"
+	"sort"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// PanelStats counts the panels of dashboards by what they query. The counts
+// per data source are of the builder queries, as in the telemetry.
+type PanelStats struct {
+	Panels                     int `json:"panels"`
+	LogsPanels                 int `json:"logs_panels"`
+	TracesPanels               int `json:"traces_panels"`
+	MetricsPanels              int `json:"metrics_panels"`
+	ClickHouseSQLPanels        int `json:"clickhouse_sql_panels"`
+	PromQLPanels               int `json:"promql_panels"`
+	LogsPanelsWithAttrContains int `json:"logs_panels_with_attr_contains"`
+}
+
+func (s *PanelStats) add(other PanelStats) {
+	s.Panels += other.Panels
+	s.LogsPanels += other.LogsPanels
+	s.TracesPanels += other.TracesPanels
+	s.MetricsPanels += other.MetricsPanels
+	s.ClickHouseSQLPanels += other.ClickHouseSQLPanels
+	s.PromQLPanels += other.PromQLPanels
+	s.LogsPanelsWithAttrContains += other.LogsPanelsWithAttrContains
+}
+
+// DashboardPanelStats are the panel stats of a dashboard, along with the
+// legacy tables its ClickHouse queries read
+type DashboardPanelStats struct {
+	Uuid  string `json:"uuid"`
+	Title string `json:"title"`
+	PanelStats
+	LegacyTraceTables bool `json:"legacy_trace_tables"`
+	LogsChQuery       bool `json:"logs_ch_query"`
+	TimeSeriesV2      bool `json:"time_series_v2"`
+}
+
+// PanelInventory is the panel stats of all the dashboards of the org, for the
+// operators to plan migrations such as the one off the legacy trace tables
+type PanelInventory struct {
+	Totals                          PanelStats            `json:"totals"`
+	Dashboards                      []DashboardPanelStats `json:"dashboards"`
+	DashboardsWithLegacyTraceTables int                   `json:"dashboards_with_legacy_trace_tables"`
+	DashboardsWithLogsChQuery       int                   `json:"dashboards_with_logs_ch_query"`
+	DashboardsWithTimeSeriesV2      int                   `json:"dashboards_with_time_series_v2"`
+}
+
+// dashboardPanelStats counts the panels of the dashboard data
+func dashboardPanelStats(data Data) PanelStats {
+	info := countPanelsInDashboard(data)
+	stats := PanelStats{
+		Panels:                     dashboardPanelCount(data),
+		LogsPanels:                 info.LogsBasedPanels,
+		TracesPanels:               info.TracesBasedPanels,
+		MetricsPanels:              info.MetricBasedPanels,
+		LogsPanelsWithAttrContains: info.LogsPanelsWithAttrContainsOp,
+	}
+
+	widgets, _ := data["widgets"].([]interface{})
+	for _, w := range widgets {
+		widget, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		query, _ := widget["query"].(map[string]interface{})
+		switch query["queryType"] {
+		case "clickhouse_sql":
+			stats.ClickHouseSQLPanels++
+		case "promql":
+			stats.PromQLPanels++
+		}
+	}
+	return stats
+}
+
+// GetPanelInventory counts the panels of the dashboards outside the trash.
+// Unlike the telemetry, which adds up the usage counted on write, the panels
+// are counted in the current data of every dashboard.
+func GetPanelInventory(ctx context.Context) (*PanelInventory, *model.ApiError) {
+	scope, args := orgScope(ctx)
+	rows := []Dashboard{}
+	err := db.Select(&rows, `SELECT uuid, title, data FROM dashboards WHERE deleted_at IS NULL`+scope, args...)
+	if err != nil {
+		zap.L().Error("Error in getting dashboards for the panel inventory", zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	inventory := &PanelInventory{Dashboards: []DashboardPanelStats{}}
+	for _, row := range rows {
+		stats := DashboardPanelStats{
+			Uuid:              row.Uuid,
+			Title:             row.Title,
+			PanelStats:        dashboardPanelStats(row.Data),
+			LegacyTraceTables: isDashboardWithTracesClickhouseQuery(row.Data),
+			LogsChQuery:       isDashboardWithLogsClickhouseQuery(row.Data),
+			TimeSeriesV2:      isDashboardWithTSV2(row.Data),
+		}
+
+		inventory.Totals.add(stats.PanelStats)
+		if stats.LegacyTraceTables {
+			inventory.DashboardsWithLegacyTraceTables++
+		}
+		if stats.LogsChQuery {
+			inventory.DashboardsWithLogsChQuery++
+		}
+		if stats.TimeSeriesV2 {
+			inventory.DashboardsWithTimeSeriesV2++
+		}
+		inventory.Dashboards = append(inventory.Dashboards, stats)
+	}
+
+	sort.SliceStable(inventory.Dashboards, func(i, j int) bool {
+		return inventory.Dashboards[i].Title < inventory.Dashboards[j].Title
+	})
+	return inventory, nil
+}
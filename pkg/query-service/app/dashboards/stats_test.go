@@ -0,0 +1,62 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestGetPanelInventory(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	_, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "traces",
+		"widgets": []interface{}{
+			map[string]interface{}{
+				"id": "spans",
+				"query": map[string]interface{}{
+					"queryType": "clickhouse_sql",
+					"clickhouse_sql": []interface{}{
+						map[string]interface{}{"query": "SELECT count() FROM signoz_traces.distributed_signoz_index_v2"},
+					},
+				},
+			},
+			map[string]interface{}{
+				"id":    "errors",
+				"query": map[string]interface{}{"queryType": "promql", "promql": []interface{}{}},
+			},
+			map[string]interface{}{"id": "row", "panelTypes": "row"},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	_, apiErr = dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title":   "hosts",
+		"widgets": []interface{}{metricWidget("cpu", "CPU", "system_cpu_time")},
+	}, nil)
+	require.Nil(apiErr)
+
+	trashed, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title":   "trashed",
+		"widgets": []interface{}{metricWidget("memory", "Memory", "system_memory_usage")},
+	}, nil)
+	require.Nil(apiErr)
+	require.Nil(dashboards.DeleteDashboard(ctx, trashed.Uuid, false, nil))
+
+	inventory, apiErr := dashboards.GetPanelInventory(ctx)
+	require.Nil(apiErr)
+	require.Equal(dashboards.PanelStats{Panels: 3, MetricsPanels: 1, ClickHouseSQLPanels: 1, PromQLPanels: 1}, inventory.Totals)
+	require.Equal(1, inventory.DashboardsWithLegacyTraceTables)
+
+	require.Len(inventory.Dashboards, 2)
+	require.Equal("hosts", inventory.Dashboards[0].Title)
+	require.Equal(1, inventory.Dashboards[0].MetricsPanels)
+	require.False(inventory.Dashboards[0].LegacyTraceTables)
+	require.Equal("traces", inventory.Dashboards[1].Title)
+	require.Equal(2, inventory.Dashboards[1].Panels)
+	require.True(inventory.Dashboards[1].LegacyTraceTables)
+}
@@ -0,0 +1,119 @@
+package dashboards
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialect is the SQL dialect of the metadata database
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// dialectOf returns the dialect of the sqlx driver name
+func dialectOf(driverName string) Dialect {
+	if sqlx.BindType(driverName) == sqlx.DOLLAR {
+		return DialectPostgres
+	}
+	return DialectSQLite
+}
+
+// compressesData tells whether the dashboard data is stored gzipped. The data
+// column is text, which only SQLite lets hold binary data.
+func (d Dialect) compressesData() bool {
+	return d == DialectSQLite
+}
+
+// Store is the metadata database the dashboards are kept in, SQLite or, for
+// HA deployments, PostgreSQL. The queries of the package are written with ?
+// placeholders in the SQL both databases understand, the store binds them for
+// its dialect.
+type Store interface {
+	sqlx.Queryer
+	sqlx.Execer
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+	Dialect() Dialect
+}
+
+// sqlxExt is what *sqlx.DB and *sqlx.Tx have in common
+type sqlxExt interface {
+	sqlx.Ext
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+}
+
+type store struct {
+	ext     sqlxExt
+	dialect Dialect
+}
+
+func (s *store) Dialect() Dialect {
+	return s.dialect
+}
+
+func (s *store) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.ext.Exec(s.ext.Rebind(query), args...)
+}
+
+func (s *store) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.ext.Query(s.ext.Rebind(query), args...)
+}
+
+func (s *store) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	return s.ext.Queryx(s.ext.Rebind(query), args...)
+}
+
+func (s *store) QueryRowx(query string, args ...interface{}) *sqlx.Row {
+	return s.ext.QueryRowx(s.ext.Rebind(query), args...)
+}
+
+func (s *store) Get(dest interface{}, query string, args ...interface{}) error {
+	return s.ext.Get(dest, s.ext.Rebind(query), args...)
+}
+
+func (s *store) Select(dest interface{}, query string, args ...interface{}) error {
+	return s.ext.Select(dest, s.ext.Rebind(query), args...)
+}
+
+// NamedExec binds the named parameters for the dialect itself
+func (s *store) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return s.ext.NamedExec(query, arg)
+}
+
+// storeDB is the store of the package, transactions of it are stores too
+type storeDB struct {
+	store
+	db *sqlx.DB
+}
+
+func newStoreDB(db *sqlx.DB) *storeDB {
+	return &storeDB{store: store{ext: db, dialect: dialectOf(db.DriverName())}, db: db}
+}
+
+func (s *storeDB) Beginx() (*storeTx, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	return &storeTx{store: store{ext: tx, dialect: s.dialect}, tx: tx}, nil
+}
+
+type storeTx struct {
+	store
+	tx *sqlx.Tx
+}
+
+func (t *storeTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *storeTx) Rollback() error {
+	return t.tx.Rollback()
+}
@@ -0,0 +1,41 @@
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreDialect(t *testing.T) {
+	require := require.New(t)
+
+	sqlite := newStoreDB(sqlx.NewDb(nil, "sqlite3"))
+	require.Equal(DialectSQLite, sqlite.Dialect())
+	require.Equal(`SELECT * FROM dashboards WHERE uuid=? AND org_id=?`, sqlite.ext.Rebind(`SELECT * FROM dashboards WHERE uuid=? AND org_id=?`))
+
+	postgres := newStoreDB(sqlx.NewDb(nil, "postgres"))
+	require.Equal(DialectPostgres, postgres.Dialect())
+	require.Equal(`SELECT * FROM dashboards WHERE uuid=$1 AND org_id=$2`, postgres.ext.Rebind(`SELECT * FROM dashboards WHERE uuid=? AND org_id=?`))
+
+	// the data is stored as plain json in postgres, its data column is text
+	previous := db
+	t.Cleanup(func() { db = previous })
+	data := Data{"title": "checkout"}
+
+	db = postgres
+	value, err := data.Value()
+	require.NoError(err)
+	require.Equal(`{"title":"checkout"}`, value)
+
+	db = sqlite
+	value, err = data.Value()
+	require.NoError(err)
+	compressed, ok := value.([]byte)
+	require.True(ok)
+	require.Equal([]byte{0x1f, 0x8b}, compressed[:2])
+
+	var scanned Data
+	require.NoError(scanned.Scan(compressed))
+	require.Equal(data, scanned)
+}
@@ -0,0 +1,84 @@
+package dashboards
+
+import "time"
+
+// summaryColumns are the columns of the dashboards read for their summaries,
+// everything but the data
+const summaryColumns = `id, uuid, slug, created_at, created_by, updated_at, updated_by, owner, org_id, folder_id,
+	title, description, tags, panel_count, default_time_range, refresh_interval, locked, version, archived_at, view_count, last_viewed_at`
+
+// DashboardSummary is a dashboard without its widgets and variables, enough
+// to list the dashboards
+type DashboardSummary struct {
+	Id               int        `json:"id"`
+	Uuid             string     `json:"uuid"`
+	Slug             string     `json:"slug"`
+	Title            string     `json:"title"`
+	Description      string     `json:"description"`
+	Tags             Tags       `json:"tags"`
+	PanelCount       int        `json:"panel_count"`
+	DefaultTimeRange string     `json:"default_time_range,omitempty"`
+	RefreshInterval  string     `json:"refresh_interval,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	CreateBy         *string    `json:"created_by"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	UpdateBy         *string    `json:"updated_by"`
+	Owner            *string    `json:"owner"`
+	FolderId         *string    `json:"folder_id"`
+	Locked           *int       `json:"isLocked"`
+	Version          int        `json:"version"`
+	ArchivedAt       *time.Time `json:"archived_at,omitempty"`
+	ViewCount        int        `json:"view_count"`
+	LastViewedAt     *time.Time `json:"last_viewed_at"`
+
+	CreatedByUser *DashboardUser `json:"created_by_user,omitempty"`
+	UpdatedByUser *DashboardUser `json:"updated_by_user,omitempty"`
+}
+
+// Summary returns the summary of the dashboard. The metadata of dashboards
+// which are not stored, e.g. of integrations, is read from their data.
+func (d *Dashboard) Summary() DashboardSummary {
+	dashboard := *d
+	if dashboard.Data != nil {
+		dashboard.setMetadata()
+	}
+
+	summary := DashboardSummary{
+		Id:               dashboard.Id,
+		Uuid:             dashboard.Uuid,
+		Slug:             dashboard.Slug,
+		Title:            dashboard.Title,
+		Description:      dashboard.Description,
+		Tags:             dashboard.Tags,
+		DefaultTimeRange: dashboard.DefaultTimeRange,
+		RefreshInterval:  dashboard.RefreshInterval,
+		CreatedAt:        dashboard.CreatedAt,
+		CreateBy:         dashboard.CreateBy,
+		UpdatedAt:        dashboard.UpdatedAt,
+		UpdateBy:         dashboard.UpdateBy,
+		Owner:            dashboard.Owner,
+		FolderId:         dashboard.FolderId,
+		Locked:           dashboard.Locked,
+		Version:          dashboard.Version,
+		ArchivedAt:       dashboard.ArchivedAt,
+		ViewCount:        dashboard.ViewCount,
+		LastViewedAt:     dashboard.LastViewedAt,
+		CreatedByUser:    dashboard.CreatedByUser,
+		UpdatedByUser:    dashboard.UpdatedByUser,
+	}
+	if summary.Tags == nil {
+		summary.Tags = Tags{}
+	}
+	if dashboard.PanelCount != nil {
+		summary.PanelCount = *dashboard.PanelCount
+	}
+	return summary
+}
+
+func Summarize(dashboards []Dashboard) []DashboardSummary {
+	summaries := make([]DashboardSummary, 0, len(dashboards))
+	for i := range dashboards {
+		summaries = append(summaries, dashboards[i].Summary())
+	}
+	return summaries
+}
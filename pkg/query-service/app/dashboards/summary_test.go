@@ -0,0 +1,57 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestListDashboardSummaries(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	created, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title":            "kafka",
+		"tags":             []interface{}{"kafka"},
+		"widgets":          []interface{}{map[string]interface{}{"id": "lag"}, map[string]interface{}{"id": "throughput"}},
+		"defaultTimeRange": "15m",
+		"refreshInterval":  "30s",
+	}, nil)
+	require.Nil(apiErr)
+
+	listed, apiErr := dashboards.ListDashboards(ctx, dashboards.ListDashboardsParams{Summary: true})
+	require.Nil(apiErr)
+	require.Len(listed, 1)
+	require.Nil(listed[0].Data)
+
+	summaries := dashboards.Summarize(listed)
+	require.Equal(created.Uuid, summaries[0].Uuid)
+	require.Equal("kafka", summaries[0].Title)
+	require.Equal(dashboards.Tags{"kafka"}, summaries[0].Tags)
+	require.Equal(2, summaries[0].PanelCount)
+	require.Equal("15m", summaries[0].DefaultTimeRange)
+	require.Equal("30s", summaries[0].RefreshInterval)
+	require.Equal("alice@signoz.io", *summaries[0].CreateBy)
+}
+
+func TestSummaryOfDashboardNotStored(t *testing.T) {
+	integration := dashboards.Dashboard{
+		Uuid: "integration--nginx--overview",
+		Data: dashboards.Data{
+			"title": "NGINX Overview",
+			"tags":  []interface{}{"nginx"},
+			"widgets": []interface{}{
+				map[string]interface{}{"id": "http", "panelTypes": "row"},
+				map[string]interface{}{"id": "requests"},
+			},
+		},
+	}
+
+	summary := integration.Summary()
+	require.Equal(t, "NGINX Overview", summary.Title)
+	require.Equal(t, dashboards.Tags{"nginx"}, summary.Tags)
+	require.Equal(t, 1, summary.PanelCount)
+}
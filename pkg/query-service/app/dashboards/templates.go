@@ -0,0 +1,171 @@
+package dashboards
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"go.signoz.io/signoz/pkg/query-service/interfaces"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+// DashboardTemplateParam is a value substituted for [[name]] in a template
+// when it is instantiated. Params without a default are required.
+type DashboardTemplateParam struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Default     *string `json:"default,omitempty"`
+}
+
+// DashboardTemplate is a built-in dashboard users can create dashboards from
+type DashboardTemplate struct {
+	Id          string                   `json:"id"`
+	Title       string                   `json:"title"`
+	Description string                   `json:"description"`
+	Category    string                   `json:"category"`
+	Tags        []string                 `json:"tags"`
+	Params      []DashboardTemplateParam `json:"params"`
+	Dashboard   Data                     `json:"dashboard,omitempty"`
+}
+
+//go:embed templates/*.json
+var templateFiles embed.FS
+
+var dashboardTemplates map[string]DashboardTemplate
+
+func init() {
+	err := readDashboardTemplates()
+	if err != nil {
+		panic(fmt.Errorf("couldn't read dashboard templates: %w", err))
+	}
+}
+
+func readDashboardTemplates() error {
+	rootDirName := "templates"
+	files, err := fs.ReadDir(templateFiles, rootDirName)
+	if err != nil {
+		return fmt.Errorf("couldn't list dashboard templates: %w", err)
+	}
+
+	dashboardTemplates = map[string]DashboardTemplate{}
+	for _, f := range files {
+		contents, err := fs.ReadFile(templateFiles, path.Join(rootDirName, f.Name()))
+		if err != nil {
+			return fmt.Errorf("couldn't read %s: %w", f.Name(), err)
+		}
+
+		var template DashboardTemplate
+		if err := json.Unmarshal(contents, &template); err != nil {
+			return fmt.Errorf("couldn't parse %s: %w", f.Name(), err)
+		}
+		if template.Id == "" || template.Dashboard == nil {
+			return fmt.Errorf("template %s must have an id and a dashboard", f.Name())
+		}
+		if _, exists := dashboardTemplates[template.Id]; exists {
+			return fmt.Errorf("duplicate dashboard template id %s", template.Id)
+		}
+		dashboardTemplates[template.Id] = template
+	}
+
+	return nil
+}
+
+// GetDashboardTemplates lists the metadata of the built-in templates, without
+// their dashboards
+func GetDashboardTemplates(ctx context.Context) []DashboardTemplate {
+	templates := []DashboardTemplate{}
+	for _, template := range dashboardTemplates {
+		template.Dashboard = nil
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Id < templates[j].Id
+	})
+	return templates
+}
+
+func GetDashboardTemplate(ctx context.Context, id string) (*DashboardTemplate, *model.ApiError) {
+	template, ok := dashboardTemplates[id]
+	if !ok {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no dashboard template found with id: %s", id)}
+	}
+	return &template, nil
+}
+
+// InstantiateDashboardTemplate creates a dashboard from the template after
+// substituting the params
+func InstantiateDashboardTemplate(ctx context.Context, id string, params map[string]string, fm interfaces.FeatureLookup) (*Dashboard, *model.ApiError) {
+	template, apiErr := GetDashboardTemplate(ctx, id)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	data, err := template.render(params)
+	if err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	return CreateDashboard(ctx, data, fm)
+}
+
+func (t *DashboardTemplate) render(params map[string]string) (Data, error) {
+	known := map[string]bool{}
+	replacements := []string{}
+	for _, param := range t.Params {
+		known[param.Name] = true
+
+		value, ok := params[param.Name]
+		if !ok {
+			if param.Default == nil {
+				return nil, fmt.Errorf("param %s is required", param.Name)
+			}
+			value = *param.Default
+		}
+		if strings.ContainsAny(value, "\"\\\n") {
+			return nil, fmt.Errorf("param %s must not contain quotes, backslashes or newlines", param.Name)
+		}
+		replacements = append(replacements, fmt.Sprintf("[[%s]]", param.Name), value)
+	}
+	for name := range params {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown param %s for template %s", name, t.Id)
+		}
+	}
+
+	// marshalling deep copies the template so the substitution does not leak
+	// into the shared template
+	raw, err := json.Marshal(t.Dashboard)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	replacer := strings.NewReplacer(replacements...)
+	return substituteTemplateParams(data, replacer).(map[string]interface{}), nil
+}
+
+func substituteTemplateParams(value interface{}, replacer *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case string:
+		return replacer.Replace(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = substituteTemplateParams(item, replacer)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = substituteTemplateParams(item, replacer)
+		}
+		return v
+	}
+	return value
+}
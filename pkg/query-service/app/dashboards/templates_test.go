@@ -0,0 +1,50 @@
+package dashboards_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardTemplates(t *testing.T) {
+	require := require.New(t)
+
+	templates := dashboards.GetDashboardTemplates(context.Background())
+	require.NotEmpty(templates)
+	for _, template := range templates {
+		require.Nil(template.Dashboard)
+
+		full, apiErr := dashboards.GetDashboardTemplate(context.Background(), template.Id)
+		require.Nil(apiErr)
+		require.NotEmpty(full.Dashboard["title"], template.Id)
+	}
+
+	_, apiErr := dashboards.GetDashboardTemplate(context.Background(), "does-not-exist")
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+}
+
+func TestInstantiateDashboardTemplate(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	_, apiErr := dashboards.InstantiateDashboardTemplate(ctx, "jvm", nil, nil)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	_, apiErr = dashboards.InstantiateDashboardTemplate(ctx, "jvm", map[string]string{"service_name": "checkout", "unknown": "x"}, nil)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	dashboard, apiErr := dashboards.InstantiateDashboardTemplate(ctx, "jvm", map[string]string{"service_name": "checkout"}, nil)
+	require.Nil(apiErr)
+	require.Equal("JVM - checkout", dashboard.Data["title"])
+	require.Contains(dashboard.Slug, "jvm-checkout")
+
+	// the shared template is not modified by the substitution
+	template, apiErr := dashboards.GetDashboardTemplate(ctx, "jvm")
+	require.Nil(apiErr)
+	require.Equal("JVM - [[service_name]]", template.Dashboard["title"])
+}
@@ -0,0 +1,130 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"go.signoz.io/signoz/pkg/query-service/interfaces"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+// WidgetThreshold is a threshold of a panel. The json names are the ones the
+// frontend has always stored, so the thresholds of existing dashboards parse
+// as they are. Severity is the severity of the alert created from the panel
+// for the threshold.
+type WidgetThreshold struct {
+	Index    string   `json:"index,omitempty"`
+	Operator string   `json:"thresholdOperator"`
+	Value    *float64 `json:"thresholdValue"`
+	Unit     string   `json:"thresholdUnit,omitempty"`
+	Color    string   `json:"thresholdColor,omitempty"`
+	Format   string   `json:"thresholdFormat,omitempty"`
+	Label    string   `json:"thresholdLabel,omitempty"`
+	Severity string   `json:"thresholdSeverity,omitempty"`
+}
+
+var knownThresholdOperators = map[string]bool{
+	">":  true,
+	"<":  true,
+	">=": true,
+	"<=": true,
+	"=":  true,
+}
+
+var knownThresholdFormats = map[string]bool{
+	"Text":       true,
+	"Background": true,
+}
+
+// knownThresholdSeverities are the severities of alerts
+var knownThresholdSeverities = map[string]bool{
+	"critical": true,
+	"error":    true,
+	"warning":  true,
+	"info":     true,
+}
+
+// thresholdColor matches hex colors and color names such as Red
+var thresholdColor = regexp.MustCompile(`^(#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})|[a-zA-Z]+)$`)
+
+// Validate checks the threshold can be rendered and turned into an alert
+func (t *WidgetThreshold) Validate() []FieldError {
+	errs := []FieldError{}
+	addErr := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !knownThresholdOperators[t.Operator] {
+		addErr("thresholdOperator", "unknown operator %q", t.Operator)
+	}
+	if t.Value == nil {
+		addErr("thresholdValue", "value is required")
+	}
+	if t.Color != "" && !thresholdColor.MatchString(t.Color) {
+		addErr("thresholdColor", "invalid color %q, expected a hex color or a color name", t.Color)
+	}
+	if t.Format != "" && !knownThresholdFormats[t.Format] {
+		addErr("thresholdFormat", "unknown format %q", t.Format)
+	}
+	if t.Severity != "" && !knownThresholdSeverities[t.Severity] {
+		addErr("thresholdSeverity", "unknown severity %q", t.Severity)
+	}
+	return errs
+}
+
+// GetWidgetThresholds returns the thresholds of the widget
+func GetWidgetThresholds(ctx context.Context, uuid string, widgetId string) ([]WidgetThreshold, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	schema, err := ParseDashboardSchema(dashboard.Data)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	for _, widget := range schema.Widgets {
+		if widget.Id == widgetId {
+			if widget.Thresholds == nil {
+				return []WidgetThreshold{}, nil
+			}
+			return widget.Thresholds, nil
+		}
+	}
+	return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no widget found with id: %s", widgetId)}
+}
+
+// SetWidgetThresholds replaces the thresholds of the widget and saves the
+// dashboard like UpdateDashboard does
+func SetWidgetThresholds(ctx context.Context, uuid string, widgetId string, thresholds []WidgetThreshold, expectedVersion int, fm interfaces.FeatureLookup) (*Dashboard, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if expectedVersion != 0 && expectedVersion != dashboard.Version {
+		return nil, versionConflict()
+	}
+
+	widget, err := GetWidget(dashboard.Data, widgetId)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: err}
+	}
+
+	// the thresholds are stored as plain json like the rest of the data
+	raw, err := json.Marshal(thresholds)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	var value []interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	if value == nil {
+		value = []interface{}{}
+	}
+	widget["thresholds"] = value
+
+	return UpdateDashboard(ctx, uuid, dashboard.Data, false, dashboard.Version, fm)
+}
@@ -0,0 +1,76 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestWidgetThresholds(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	// thresholds as the frontend stores them
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "services",
+		"widgets": []interface{}{
+			map[string]interface{}{
+				"id": "latency",
+				"thresholds": []interface{}{
+					map[string]interface{}{
+						"index":             "0a1b",
+						"keyIndex":          0,
+						"thresholdOperator": ">",
+						"thresholdValue":    500,
+						"thresholdUnit":     "ms",
+						"thresholdColor":    "Red",
+						"thresholdFormat":   "Text",
+					},
+				},
+			},
+			map[string]interface{}{"id": "errors"},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	thresholds, apiErr := dashboards.GetWidgetThresholds(ctx, dashboard.Uuid, "latency")
+	require.Nil(apiErr)
+	require.Len(thresholds, 1)
+	require.Equal(">", thresholds[0].Operator)
+	require.Equal(500.0, *thresholds[0].Value)
+	require.Equal("Red", thresholds[0].Color)
+
+	thresholds, apiErr = dashboards.GetWidgetThresholds(ctx, dashboard.Uuid, "errors")
+	require.Nil(apiErr)
+	require.Empty(thresholds)
+
+	_, apiErr = dashboards.GetWidgetThresholds(ctx, dashboard.Uuid, "saturation")
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+
+	value := 5.0
+	updated, apiErr := dashboards.SetWidgetThresholds(ctx, dashboard.Uuid, "errors", []dashboards.WidgetThreshold{
+		{Operator: ">=", Value: &value, Color: "#ff0000", Severity: "critical"},
+	}, dashboard.Version, nil)
+	require.Nil(apiErr)
+	require.Equal(dashboard.Version+1, updated.Version)
+
+	thresholds, apiErr = dashboards.GetWidgetThresholds(ctx, dashboard.Uuid, "errors")
+	require.Nil(apiErr)
+	require.Len(thresholds, 1)
+	require.Equal("critical", thresholds[0].Severity)
+
+	_, apiErr = dashboards.SetWidgetThresholds(ctx, dashboard.Uuid, "errors", []dashboards.WidgetThreshold{
+		{Operator: "!=", Color: "red!", Severity: "fatal"},
+	}, 0, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	_, apiErr = dashboards.SetWidgetThresholds(ctx, dashboard.Uuid, "errors", nil, dashboard.Version, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorConflict, apiErr.Type())
+}
@@ -0,0 +1,278 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"sync"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+const (
+	thumbnailWidth  = 320
+	thumbnailHeight = 180
+	thumbnailHeader = 22
+	thumbnailMargin = 4
+	// thumbnailMinRows keeps the panels of short dashboards from being
+	// stretched to the whole height of the thumbnail
+	thumbnailMinRows = 12
+	// thumbnailBatchSize is the most thumbnails rendered in a single run
+	thumbnailBatchSize = 100
+)
+
+// DashboardThumbnail is the cached preview of a version of a dashboard, an
+// svg drawing of its layout
+type DashboardThumbnail struct {
+	DashboardUuid string    `json:"dashboard_uuid" db:"dashboard_uuid"`
+	Version       int       `json:"version" db:"version"`
+	Content       string    `json:"-" db:"content"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ETag changes with the version the thumbnail was rendered from
+func (t *DashboardThumbnail) ETag() string {
+	return fmt.Sprintf(`"%s-%d-thumbnail"`, t.DashboardUuid, t.Version)
+}
+
+// RenderThumbnail draws the layout of the dashboard as an svg, with a shape
+// hinting at the panel type of every widget. Widgets which are not in the
+// layout are laid out two per row.
+func RenderThumbnail(data Data) []byte {
+	schema, err := ParseDashboardSchema(data)
+	if err != nil {
+		schema = &DashboardSchema{Title: extractDashboardName(data)}
+	}
+
+	widgets := map[string]Widget{}
+	for _, widget := range schema.Widgets {
+		widgets[widget.Id] = widget
+	}
+
+	items := []LayoutItem{}
+	laidOut := map[string]bool{}
+	rows := 0
+	for _, item := range schema.Layout {
+		if item.W < 1 || item.H < 1 || item.X < 0 || item.Y < 0 {
+			continue
+		}
+		items = append(items, item)
+		laidOut[item.I] = true
+		rows = max(rows, item.Y+item.H)
+	}
+	next := 0
+	for _, widget := range schema.Widgets {
+		if laidOut[widget.Id] {
+			continue
+		}
+		item := LayoutItem{I: widget.Id, X: (next % 2) * signozGridColumns / 2, Y: rows + (next/2)*3, W: signozGridColumns / 2, H: 3}
+		items = append(items, item)
+		next++
+	}
+	if next > 0 {
+		rows += ((next + 1) / 2) * 3
+	}
+
+	columnWidth := float64(thumbnailWidth-2*thumbnailMargin) / signozGridColumns
+	rowHeight := float64(thumbnailHeight-thumbnailHeader-thumbnailMargin) / float64(max(rows, thumbnailMinRows))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, thumbnailWidth, thumbnailHeight, thumbnailWidth, thumbnailHeight)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#0b0c0e"/>`, thumbnailWidth, thumbnailHeight)
+	fmt.Fprintf(&buf, `<text x="%d" y="15" font-family="sans-serif" font-size="11" fill="#c0c1c3">%s</text>`, thumbnailMargin+2, html.EscapeString(truncate(schema.Title, 50)))
+
+	for _, item := range items {
+		x := thumbnailMargin + float64(min(item.X, signozGridColumns))*columnWidth
+		y := thumbnailHeader + float64(item.Y)*rowHeight
+		w := float64(min(item.W, signozGridColumns-min(item.X, signozGridColumns)))*columnWidth - 2
+		h := float64(item.H)*rowHeight - 2
+		if w <= 0 || h <= 0 {
+			continue
+		}
+
+		widget := widgets[item.I]
+		if widget.PanelTypes == "row" {
+			fmt.Fprintf(&buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#2c3140"/>`, x, y+h/2, x+w, y+h/2)
+			continue
+		}
+
+		fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="2" fill="#1d212d" stroke="#2c3140"/>`, x, y, w, h)
+		if h >= 14 && widget.Title != "" {
+			fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" font-family="sans-serif" font-size="6" fill="#9a9ca0">%s</text>`, x+3, y+8, html.EscapeString(truncate(widget.Title, int(w/3.5))))
+		}
+		renderPanelGlyph(&buf, widget.PanelTypes, x+3, y+11, w-6, h-14)
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+// renderPanelGlyph draws a shape hinting at the panel type in the area
+func renderPanelGlyph(buf *bytes.Buffer, panelType string, x, y, w, h float64) {
+	if w < 8 || h < 6 {
+		return
+	}
+
+	const color = "#4e74f8"
+	switch panelType {
+	case "graph", "":
+		fmt.Fprintf(buf, `<polyline points="%.1f,%.1f %.1f,%.1f %.1f,%.1f %.1f,%.1f %.1f,%.1f" fill="none" stroke="%s"/>`,
+			x, y+h*0.8, x+w*0.25, y+h*0.4, x+w*0.5, y+h*0.6, x+w*0.75, y+h*0.2, x+w, y+h*0.5, color)
+	case "bar", "histogram":
+		for i, height := range []float64{0.5, 0.8, 0.35, 0.65} {
+			barWidth := w / 4
+			fmt.Fprintf(buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`,
+				x+float64(i)*barWidth+barWidth*0.15, y+h*(1-height), barWidth*0.7, h*height, color)
+		}
+	case "value":
+		fmt.Fprintf(buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="1" fill="%s"/>`, x+w*0.3, y+h*0.35, w*0.4, h*0.3, color)
+	case "pie":
+		fmt.Fprintf(buf, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="none" stroke="%s" stroke-width="2"/>`, x+w/2, y+h/2, min(w, h)*0.4, color)
+	case "table", "list", "trace":
+		for i := 1; i <= 3; i++ {
+			lineY := y + h*float64(i)/4
+			fmt.Fprintf(buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`, x, lineY, x+w, lineY, color)
+		}
+	}
+}
+
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if length < 1 {
+		return ""
+	}
+	if len(runes) <= length {
+		return s
+	}
+	return string(runes[:length-1]) + "…"
+}
+
+// GetDashboardThumbnail returns the thumbnail of the current version of the
+// dashboard, rendering it if the renderer has not caught up with the change
+func GetDashboardThumbnail(ctx context.Context, uuid string) (*DashboardThumbnail, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	thumbnail := &DashboardThumbnail{}
+	err := db.Get(thumbnail, `SELECT * FROM dashboard_thumbnails WHERE dashboard_uuid=? AND version=?`, dashboard.Uuid, dashboard.Version)
+	if err == nil {
+		return thumbnail, nil
+	}
+
+	thumbnail, err = saveThumbnail(dashboard)
+	if err != nil {
+		zap.L().Error("Error in saving dashboard thumbnail", zap.String("uuid", uuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return thumbnail, nil
+}
+
+func saveThumbnail(dashboard *Dashboard) (*DashboardThumbnail, error) {
+	thumbnail := &DashboardThumbnail{
+		DashboardUuid: dashboard.Uuid,
+		Version:       dashboard.Version,
+		Content:       string(RenderThumbnail(dashboard.Data)),
+		UpdatedAt:     time.Now(),
+	}
+
+	_, err := db.Exec(`INSERT INTO dashboard_thumbnails (dashboard_uuid, version, content, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(dashboard_uuid) DO UPDATE SET version=excluded.version, content=excluded.content, updated_at=excluded.updated_at`,
+		thumbnail.DashboardUuid, thumbnail.Version, thumbnail.Content, thumbnail.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return thumbnail, nil
+}
+
+// RenderStaleThumbnails renders the thumbnails of the dashboards which have
+// changed since their thumbnail was rendered, in batches
+func RenderStaleThumbnails(ctx context.Context) (int, error) {
+	rendered := 0
+	for {
+		stale := []Dashboard{}
+		err := db.Select(&stale, `SELECT d.* FROM dashboards d LEFT JOIN dashboard_thumbnails t ON t.dashboard_uuid = d.uuid
+			WHERE d.deleted_at IS NULL AND (t.version IS NULL OR t.version != d.version) ORDER BY d.id LIMIT ?`, thumbnailBatchSize)
+		if err != nil {
+			return rendered, err
+		}
+
+		for i := range stale {
+			if _, err := saveThumbnail(&stale[i]); err != nil {
+				return rendered, err
+			}
+			rendered++
+		}
+		if len(stale) < thumbnailBatchSize {
+			return rendered, nil
+		}
+	}
+}
+
+func pruneThumbnails() {
+	_, err := db.Exec(`DELETE FROM dashboard_thumbnails WHERE dashboard_uuid NOT IN (SELECT uuid FROM dashboards)`)
+	if err != nil {
+		zap.L().Error("Error in removing purged dashboards from the thumbnails", zap.Error(err))
+	}
+}
+
+// ThumbnailRenderer periodically renders the thumbnails of the dashboards
+// changed since the last run, so listing the dashboards does not wait on it
+type ThumbnailRenderer struct {
+	interval time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewThumbnailRenderer returns a renderer running every interval, or only
+// once on start if the interval is 0
+func NewThumbnailRenderer(interval time.Duration) *ThumbnailRenderer {
+	return &ThumbnailRenderer{
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+func (r *ThumbnailRenderer) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		r.render()
+		if r.interval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+				r.render()
+			}
+		}
+	}()
+}
+
+func (r *ThumbnailRenderer) Stop() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+func (r *ThumbnailRenderer) render() {
+	rendered, err := RenderStaleThumbnails(context.Background())
+	if err != nil {
+		zap.L().Error("Error in rendering dashboard thumbnails", zap.Error(err))
+		return
+	}
+	if rendered > 0 {
+		zap.L().Debug("Rendered dashboard thumbnails", zap.Int("count", rendered))
+	}
+}
@@ -0,0 +1,68 @@
+package dashboards_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestRenderThumbnail(t *testing.T) {
+	require := require.New(t)
+
+	svg := string(dashboards.RenderThumbnail(dashboards.Data{
+		"title": "<checkout> & payments",
+		"widgets": []interface{}{
+			map[string]interface{}{"id": "latency", "title": "Latency", "panelTypes": "graph"},
+			map[string]interface{}{"id": "errors", "title": "Errors", "panelTypes": "value"},
+			map[string]interface{}{"id": "unplaced", "title": "Requests", "panelTypes": "table"},
+		},
+		"layout": []interface{}{
+			map[string]interface{}{"i": "latency", "x": 0, "y": 0, "w": 6, "h": 3},
+			map[string]interface{}{"i": "errors", "x": 6, "y": 0, "w": 6, "h": 3},
+		},
+	}))
+
+	require.True(strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	require.True(strings.HasSuffix(svg, `</svg>`))
+	require.Contains(svg, "&lt;checkout&gt; &amp; payments")
+	require.Contains(svg, ">Latency<")
+	require.Contains(svg, ">Errors<")
+	// the widget missing from the layout is drawn below the others
+	require.Contains(svg, ">Requests<")
+	require.Contains(svg, "<polyline")
+}
+
+func TestDashboardThumbnail(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "kafka"}, nil)
+	require.Nil(apiErr)
+
+	rendered, err := dashboards.RenderStaleThumbnails(context.Background())
+	require.NoError(err)
+	require.Equal(1, rendered)
+
+	rendered, err = dashboards.RenderStaleThumbnails(context.Background())
+	require.NoError(err)
+	require.Equal(0, rendered)
+
+	thumbnail, apiErr := dashboards.GetDashboardThumbnail(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal(1, thumbnail.Version)
+	require.Contains(thumbnail.Content, ">kafka<")
+
+	// a thumbnail older than the dashboard is rendered again on read
+	_, apiErr = dashboards.UpdateDashboard(ctx, dashboard.Uuid, map[string]interface{}{"title": "kafka consumers"}, false, 0, nil)
+	require.Nil(apiErr)
+
+	thumbnail, apiErr = dashboards.GetDashboardThumbnail(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Equal(2, thumbnail.Version)
+	require.Contains(thumbnail.Content, ">kafka consumers<")
+}
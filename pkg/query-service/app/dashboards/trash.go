@@ -0,0 +1,71 @@
+package dashboards
+
+import (
+	"context"
+	"fmt"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// GetDeletedDashboards returns the dashboards in the trash, most recently deleted first
+func GetDeletedDashboards(ctx context.Context) ([]Dashboard, *model.ApiError) {
+
+	dashboards := []Dashboard{}
+	scope, args := orgScope(ctx)
+	query := `SELECT * FROM dashboards WHERE deleted_at IS NOT NULL` + scope + ` ORDER BY deleted_at DESC`
+
+	err := db.Select(&dashboards, query, args...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return dashboards, nil
+}
+
+func getDeletedDashboard(ctx context.Context, uuid string) (*Dashboard, *model.ApiError) {
+
+	dashboard := Dashboard{}
+	scope, args := orgScope(ctx)
+	query := `SELECT * FROM dashboards WHERE uuid=? AND deleted_at IS NOT NULL` + scope
+
+	err := db.Get(&dashboard, query, append([]interface{}{uuid}, args...)...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no deleted dashboard found with uuid: %s", uuid)}
+	}
+
+	return &dashboard, nil
+}
+
+// RestoreDashboard moves a dashboard out of the trash. If its folder was deleted
+// in the meantime, the dashboard is restored to the top level.
+func RestoreDashboard(ctx context.Context, uuid string) (*Dashboard, *model.ApiError) {
+	dashboard, apiErr := getDeletedDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if dashboard.FolderId != nil {
+		if _, apiErr := GetFolder(ctx, *dashboard.FolderId); apiErr != nil {
+			dashboard.FolderId = nil
+		}
+	}
+
+	// a dashboard created since the deletion may have taken the slug
+	slug, err := uniqueSlug(db, dashboard.Slug, uuid)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	dashboard.Slug = slug
+
+	_, err = db.Exec(`UPDATE dashboards SET deleted_at=NULL, folder_id=?, slug=? WHERE uuid=?`, dashboard.FolderId, dashboard.Slug, uuid)
+	if err != nil {
+		zap.L().Error("Error in restoring dashboard", zap.String("uuid", uuid), zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	dashboard.DeletedAt = nil
+	recordAudit(ctx, db, uuid, AuditActionRestore, "restored from trash")
+	notifyWebhooks(ctx, uuid, AuditActionRestore, dashboard.Version)
+
+	return dashboard, nil
+}
@@ -0,0 +1,50 @@
+package dashboards_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardTrash(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "kafka"}, nil)
+	require.Nil(apiErr)
+	require.Nil(dashboards.DeleteDashboard(ctx, dashboard.Uuid, false, nil))
+
+	_, apiErr = dashboards.GetDashboard(ctx, dashboard.Uuid)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+
+	deleted, apiErr := dashboards.GetDeletedDashboards(ctx)
+	require.Nil(apiErr)
+	require.Len(deleted, 1)
+	require.NotNil(deleted[0].DeletedAt)
+
+	restored, apiErr := dashboards.RestoreDashboard(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+	require.Nil(restored.DeletedAt)
+	_, apiErr = dashboards.GetDashboard(ctx, dashboard.Uuid)
+	require.Nil(apiErr)
+
+	// only dashboards deleted before the retention window are purged
+	require.Nil(dashboards.DeleteDashboard(ctx, dashboard.Uuid, false, nil))
+	purged, apiErr := dashboards.PurgeDeletedDashboards(ctx, time.Hour)
+	require.Nil(apiErr)
+	require.Zero(purged)
+
+	purged, apiErr = dashboards.PurgeDeletedDashboards(ctx, -time.Hour)
+	require.Nil(apiErr)
+	require.Equal(int64(1), purged)
+
+	_, apiErr = dashboards.RestoreDashboard(ctx, dashboard.Uuid)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+}
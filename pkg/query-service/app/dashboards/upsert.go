@@ -0,0 +1,93 @@
+package dashboards
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.signoz.io/signoz/pkg/query-service/interfaces"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+// DashboardExistsError is returned when creating a dashboard with the uuid of
+// a dashboard which already exists, possibly in the trash
+type DashboardExistsError struct {
+	Uuid    string
+	InTrash bool
+}
+
+func (e *DashboardExistsError) Error() string {
+	if e.InTrash {
+		return fmt.Sprintf("a dashboard with uuid %s is in the trash, restore or purge it first", e.Uuid)
+	}
+	return fmt.Sprintf("a dashboard with uuid %s already exists", e.Uuid)
+}
+
+// dashboardUuid returns the uuid the client gave the dashboard, in its
+// canonical form, or a new one if it gave none
+func dashboardUuid(data map[string]interface{}) (string, error) {
+	value, ok := data["uuid"]
+	if !ok || value == nil {
+		return uuid.New().String(), nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("uuid must be a string, got %T", value)
+	}
+	id, err := uuid.Parse(str)
+	// uuid.Parse also accepts urn and braced forms, which would not match
+	// the uuid in urls
+	if err != nil || len(str) != 36 {
+		return "", fmt.Errorf("invalid uuid %q, expected the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", str)
+	}
+	return id.String(), nil
+}
+
+// checkUuidAvailable fails with a conflict if a dashboard with the uuid
+// exists, the uuids of dashboards in the trash are taken as well
+func checkUuidAvailable(q Store, uuid string) *model.ApiError {
+	var deletedAt sql.NullTime
+	err := q.Get(&deletedAt, `SELECT deleted_at FROM dashboards WHERE uuid=?`, uuid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return &model.ApiError{Typ: model.ErrorConflict, Err: &DashboardExistsError{Uuid: uuid, InTrash: deletedAt.Valid}}
+}
+
+// UpsertDashboard creates the dashboard with the uuid, or replaces the data
+// of the dashboard with the uuid if it exists. It is meant for provisioning,
+// where the source of the data is the truth, so removed panels are removed
+// without confirmation. A dashboard with the uuid in the trash is a conflict.
+// It returns whether the dashboard was created.
+func UpsertDashboard(ctx context.Context, uuid string, data map[string]interface{}, fm interfaces.FeatureLookup) (*Dashboard, bool, *model.ApiError) {
+	if uuid == "" {
+		var ok bool
+		if uuid, ok = data["uuid"].(string); !ok || uuid == "" {
+			return nil, false, model.BadRequest(fmt.Errorf("uuid is required to upsert a dashboard"))
+		}
+	}
+	id, err := dashboardUuid(map[string]interface{}{"uuid": uuid})
+	if err != nil {
+		return nil, false, model.BadRequest(err)
+	}
+
+	if _, apiErr := GetDashboard(ctx, id); apiErr == nil {
+		dashboard, apiErr := UpdateDashboard(ctx, id, data, true, 0, fm)
+		return dashboard, false, apiErr
+	} else if apiErr.Type() != model.ErrorNotFound {
+		return nil, false, apiErr
+	}
+
+	data["uuid"] = id
+	dashboard, apiErr := CreateDashboard(ctx, data, fm)
+	if apiErr != nil {
+		return nil, false, apiErr
+	}
+	return dashboard, true, nil
+}
@@ -0,0 +1,68 @@
+package dashboards_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestCreateDashboardWithUuid(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	for _, id := range []interface{}{42, "not-a-uuid", "{9f2c1a7e-3b4d-4e5f-8a6b-7c8d9e0f1a2b}"} {
+		_, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "checkout", "uuid": id}, nil)
+		require.NotNil(apiErr)
+		require.Equal(model.ErrorBadData, apiErr.Type())
+	}
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "checkout", "uuid": "9F2C1A7E-3B4D-4E5F-8A6B-7C8D9E0F1A2B"}, nil)
+	require.Nil(apiErr)
+	require.Equal("9f2c1a7e-3b4d-4e5f-8a6b-7c8d9e0f1a2b", dashboard.Uuid)
+
+	_, apiErr = dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "payments", "uuid": dashboard.Uuid}, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorConflict, apiErr.Type())
+	var existsErr *dashboards.DashboardExistsError
+	require.True(errors.As(apiErr.Err, &existsErr))
+	require.False(existsErr.InTrash)
+
+	require.Nil(dashboards.DeleteDashboard(ctx, dashboard.Uuid, false, nil))
+	_, apiErr = dashboards.CreateDashboard(ctx, map[string]interface{}{"title": "payments", "uuid": dashboard.Uuid}, nil)
+	require.NotNil(apiErr)
+	require.True(errors.As(apiErr.Err, &existsErr))
+	require.True(existsErr.InTrash)
+}
+
+func TestUpsertDashboard(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	_, _, apiErr := dashboards.UpsertDashboard(ctx, "", map[string]interface{}{"title": "checkout"}, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+
+	id := "5d0e8f3a-1c2b-4a6d-9e7f-0b1c2d3e4f5a"
+	dashboard, created, apiErr := dashboards.UpsertDashboard(ctx, "", map[string]interface{}{"title": "checkout", "uuid": id}, nil)
+	require.Nil(apiErr)
+	require.True(created)
+	require.Equal(id, dashboard.Uuid)
+
+	dashboard, created, apiErr = dashboards.UpsertDashboard(ctx, "", map[string]interface{}{"title": "checkout v2", "uuid": id}, nil)
+	require.Nil(apiErr)
+	require.False(created)
+	require.Equal(2, dashboard.Version)
+	require.Equal("checkout v2", dashboard.Data["title"])
+
+	// dashboards in the trash are not replaced
+	require.Nil(dashboards.DeleteDashboard(ctx, id, false, nil))
+	_, _, apiErr = dashboards.UpsertDashboard(ctx, id, map[string]interface{}{"title": "checkout v3"}, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorConflict, apiErr.Type())
+}
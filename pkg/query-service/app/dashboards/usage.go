@@ -0,0 +1,60 @@
+package dashboards
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// DashboardUsage counts what the panels of a dashboard query, for the
+// telemetry. It is counted in the data on write and stored as json, so the
+// telemetry adds up the counts instead of decoding every dashboard.
+type DashboardUsage struct {
+	LogsPanels                 int  `json:"logs_panels"`
+	TracesPanels               int  `json:"traces_panels"`
+	MetricsPanels              int  `json:"metrics_panels"`
+	LogsPanelsWithAttrContains int  `json:"logs_panels_with_attr_contains"`
+	LogsChQuery                bool `json:"logs_ch_query"`
+	TracesChQuery              bool `json:"traces_ch_query"`
+	TSV2                       bool `json:"tsv2"`
+	TagAttrs                   bool `json:"tag_attrs"`
+}
+
+func (u DashboardUsage) Value() (driver.Value, error) {
+	b, err := json.Marshal(u)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (u *DashboardUsage) Scan(src interface{}) error {
+	var data []byte
+	if b, ok := src.([]byte); ok {
+		data = b
+	} else if s, ok := src.(string); ok {
+		data = []byte(s)
+	} else {
+		*u = DashboardUsage{}
+		return nil
+	}
+	return json.Unmarshal(data, u)
+}
+
+// dashboardUsage counts the usage of the dashboard in its data
+func dashboardUsage(data Data) *DashboardUsage {
+	if dashboardPanelCount(data) == 0 {
+		return &DashboardUsage{}
+	}
+
+	info := countPanelsInDashboard(data)
+	return &DashboardUsage{
+		LogsPanels:                 info.LogsBasedPanels,
+		TracesPanels:               info.TracesBasedPanels,
+		MetricsPanels:              info.MetricBasedPanels,
+		LogsPanelsWithAttrContains: info.LogsPanelsWithAttrContainsOp,
+		LogsChQuery:                info.DashboardsWithLogsChQuery > 0,
+		TracesChQuery:              info.DashboardsWithTraceChQuery > 0,
+		TSV2:                       isDashboardWithTSV2(data),
+		TagAttrs:                   isDashboardWithTagAttrs(data),
+	}
+}
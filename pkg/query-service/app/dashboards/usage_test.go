@@ -0,0 +1,80 @@
+package dashboards_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDashboardUsageCounts(t *testing.T) {
+	require := require.New(t)
+	store := utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	builderWidget := func(id string, dataSources ...string) map[string]interface{} {
+		queryData := []interface{}{}
+		for _, dataSource := range dataSources {
+			queryData = append(queryData, map[string]interface{}{"dataSource": dataSource})
+		}
+		return map[string]interface{}{"id": id, "query": map[string]interface{}{
+			"queryType": "builder",
+			"builder":   map[string]interface{}{"queryData": queryData},
+		}}
+	}
+
+	checkout, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "checkout",
+		"widgets": []interface{}{
+			builderWidget("errors", "logs", "traces"),
+			builderWidget("latency", "metrics"),
+		},
+	}, nil)
+	require.Nil(apiErr)
+	require.Equal(1, checkout.Usage.LogsPanels)
+	require.Equal(1, checkout.Usage.TracesPanels)
+	require.Equal(1, checkout.Usage.MetricsPanels)
+
+	_, apiErr = dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "spans",
+		"widgets": []interface{}{map[string]interface{}{"id": "count", "query": map[string]interface{}{
+			"queryType":      "clickhouse_sql",
+			"clickhouse_sql": []interface{}{map[string]interface{}{"query": "SELECT count() FROM signoz_traces.distributed_signoz_index_v2"}},
+		}}},
+	}, nil)
+	require.Nil(apiErr)
+
+	info, err := dashboards.GetDashboardsInfo(ctx)
+	require.NoError(err)
+	require.Equal(2, info.TotalDashboards)
+	require.Equal(1, info.LogsBasedPanels)
+	require.Equal(1, info.TracesBasedPanels)
+	require.Equal(1, info.MetricBasedPanels)
+	require.Equal(1, info.DashboardsWithTraceChQuery)
+	require.Equal([]string{"spans"}, info.DashboardNamesWithTraceChQuery)
+
+	// the counts are kept up to date on update
+	_, apiErr = dashboards.UpdateDashboard(ctx, checkout.Uuid, map[string]interface{}{
+		"title":   "checkout",
+		"widgets": []interface{}{builderWidget("errors", "logs", "traces")},
+	}, false, 0, nil)
+	require.Nil(apiErr)
+	info, err = dashboards.GetDashboardsInfo(ctx)
+	require.NoError(err)
+	require.Zero(info.MetricBasedPanels)
+
+	// and backfilled for the dashboards stored before they were counted
+	_, err = store.SQLxDB().Exec(`UPDATE dashboards SET usage_counts=NULL`)
+	require.NoError(err)
+	info, err = dashboards.GetDashboardsInfo(ctx)
+	require.NoError(err)
+	require.Zero(info.LogsBasedPanels)
+
+	require.NoError(dashboards.BackfillDashboardMetadata(context.Background()))
+	info, err = dashboards.GetDashboardsInfo(ctx)
+	require.NoError(err)
+	require.Equal(1, info.LogsBasedPanels)
+	require.Equal(1, info.DashboardsWithTraceChQuery)
+}
@@ -0,0 +1,267 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	go_cache "github.com/patrickmn/go-cache"
+	"go.signoz.io/signoz/pkg/query-service/app/metrics"
+	"go.signoz.io/signoz/pkg/query-service/model"
+)
+
+const variableCacheTTL = 30 * time.Second
+
+// variableCache holds the values of the variable queries by query, dashboards
+// are often opened by several users at once
+var variableCache = go_cache.New(variableCacheTTL, time.Minute)
+
+// variableReference matches the use of another variable in a query
+var variableReference = regexp.MustCompile(`\{\{\s*\.(\w+)\s*\}\}`)
+
+// VariableQueryFunc runs the query of a QUERY variable and returns its values
+type VariableQueryFunc func(ctx context.Context, query string) ([]interface{}, error)
+
+// VariableValues are the values a variable can take and the value selected
+type VariableValues struct {
+	Values   []interface{} `json:"values"`
+	Selected interface{}   `json:"selected,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// EvaluateVariables returns the values of all the variables of the dashboard
+// by name. Variables are evaluated after the variables their query uses, with
+// the selected values, or else the saved selection or the first value. The
+// variables of archived dashboards are not evaluated.
+func EvaluateVariables(ctx context.Context, uuid string, selected map[string]interface{}, query VariableQueryFunc) (map[string]VariableValues, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if dashboard.ArchivedAt != nil {
+		return nil, model.BadRequest(fmt.Errorf("dashboard %s is archived, unarchive it to evaluate its variables", uuid))
+	}
+
+	schema, err := ParseDashboardSchema(dashboard.Data)
+	if err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	variables := map[string]DashboardVariable{}
+	for _, variable := range schema.Variables {
+		variables[variable.Name] = variable
+	}
+	order, err := sortVariables(variables)
+	if err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	current := map[string]interface{}{}
+	results := map[string]VariableValues{}
+	for _, name := range order {
+		variable := variables[name]
+		result := VariableValues{Values: []interface{}{}}
+
+		switch variable.Type {
+		case "TEXTBOX":
+			result.Values = append(result.Values, variable.TextboxValue)
+		case "QUERY":
+			values, err := evaluateVariableQuery(ctx, variable.QueryValue, current, query)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Values = values
+			}
+		default:
+			for _, value := range variable.options() {
+				result.Values = append(result.Values, value)
+			}
+		}
+
+		switch {
+		case selected[name] != nil:
+			result.Selected = selected[name]
+		case variable.AllSelected:
+			result.Selected = result.Values
+		case variable.SelectedValue != nil:
+			result.Selected = variable.SelectedValue
+		case len(result.Values) > 0 && variable.MultiSelect:
+			result.Selected = []interface{}{result.Values[0]}
+		case len(result.Values) > 0:
+			result.Selected = result.Values[0]
+		}
+		if result.Selected != nil {
+			current[name] = result.Selected
+		}
+		results[name] = result
+	}
+
+	return results, nil
+}
+
+func evaluateVariableQuery(ctx context.Context, queryValue string, current map[string]interface{}, query VariableQueryFunc) ([]interface{}, error) {
+	vars := map[string]string{}
+	for name, value := range current {
+		vars[name] = metrics.FormattedValue(value)
+	}
+
+	tmpl, err := template.New("dashboard-vars").Parse(queryValue)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return nil, err
+	}
+
+	rendered := buf.String()
+	if cached, ok := variableCache.Get(rendered); ok {
+		return cached.([]interface{}), nil
+	}
+
+	values, err := query(ctx, rendered)
+	if err != nil {
+		return nil, err
+	}
+	variableCache.Set(rendered, values, go_cache.DefaultExpiration)
+	return values, nil
+}
+
+// validateVariableDependencies checks the variables the queries of QUERY
+// variables use exist, besides the reserved time variables, and that no
+// variables depend on each other. The chain of a cycle is reported, as in
+// a -> b -> a.
+func validateVariableDependencies(variables map[string]DashboardVariable) []FieldError {
+	errs := []FieldError{}
+
+	ids := make([]string, 0, len(variables))
+	idOf := map[string]string{}
+	for id, variable := range variables {
+		ids = append(ids, id)
+		if variable.Name != "" {
+			idOf[variable.Name] = id
+		}
+	}
+	sort.Strings(ids)
+
+	dependencies := map[string][]string{}
+	for _, id := range ids {
+		variable := variables[id]
+		if variable.Type != "QUERY" || variable.Name == "" {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, match := range variableReference.FindAllStringSubmatch(variable.QueryValue, -1) {
+			dependency := match[1]
+			if seen[dependency] {
+				continue
+			}
+			seen[dependency] = true
+			if _, ok := idOf[dependency]; ok {
+				dependencies[variable.Name] = append(dependencies[variable.Name], dependency)
+			} else if _, reserved := signozTimeVariables[dependency]; !reserved {
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("variables[%s].queryValue", id),
+					Message: fmt.Sprintf("no variable named %q", dependency),
+				})
+			}
+		}
+	}
+
+	// depth first search, a variable met again while its dependencies are
+	// being visited closes a cycle
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	path := []string{}
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range dependencies[name] {
+			switch state[dependency] {
+			case visiting:
+				start := slices.Index(path, dependency)
+				chain := append(slices.Clone(path[start:]), dependency)
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("variables[%s].queryValue", idOf[name]),
+					Message: fmt.Sprintf("variables depend on each other: %s", strings.Join(chain, " -> ")),
+				})
+			case 0:
+				visit(dependency)
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+	}
+	for _, id := range ids {
+		if name := variables[id].Name; name != "" && state[name] == 0 {
+			visit(name)
+		}
+	}
+
+	return errs
+}
+
+// sortVariables orders the variables so every variable comes after the ones
+// its query uses, and fails if variables depend on each other
+func sortVariables(variables map[string]DashboardVariable) ([]string, error) {
+	dependents := map[string][]string{}
+	pending := map[string]int{}
+	for name, variable := range variables {
+		pending[name] = 0
+		if variable.Type != "QUERY" {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, match := range variableReference.FindAllStringSubmatch(variable.QueryValue, -1) {
+			dependency := match[1]
+			if _, ok := variables[dependency]; !ok || dependency == name || seen[dependency] {
+				continue
+			}
+			seen[dependency] = true
+			dependents[dependency] = append(dependents[dependency], name)
+			pending[name]++
+		}
+	}
+
+	ready := []string{}
+	for name, count := range pending {
+		if count == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	order := []string{}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		for _, dependent := range dependents[name] {
+			if pending[dependent]--; pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(variables) {
+		cyclic := []string{}
+		for name, count := range pending {
+			if count > 0 {
+				cyclic = append(cyclic, name)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("variables depend on each other: %s", strings.Join(cyclic, ", "))
+	}
+	return order, nil
+}
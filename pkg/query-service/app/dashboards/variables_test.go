@@ -0,0 +1,83 @@
+package dashboards_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestEvaluateVariables(t *testing.T) {
+	require := require.New(t)
+	utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "services",
+		"variables": map[string]interface{}{
+			"1": map[string]interface{}{"name": "service", "type": "QUERY", "queryValue": "SELECT service FROM services WHERE env = {{.env}}"},
+			"2": map[string]interface{}{"name": "env", "type": "CUSTOM", "customValue": "prod, staging"},
+			"3": map[string]interface{}{"name": "operation", "type": "QUERY", "queryValue": "SELECT op FROM operations WHERE service = {{.service}} AND env = {{.env}}"},
+			"4": map[string]interface{}{"name": "filter", "type": "TEXTBOX", "textboxValue": "http"},
+		},
+	}, nil)
+	require.Nil(apiErr)
+
+	queries := []string{}
+	query := func(ctx context.Context, query string) ([]interface{}, error) {
+		queries = append(queries, query)
+		switch query {
+		case "SELECT service FROM services WHERE env = 'staging'":
+			return []interface{}{"cart", "checkout"}, nil
+		case "SELECT op FROM operations WHERE service = 'checkout' AND env = 'staging'":
+			return []interface{}{"POST /pay"}, nil
+		}
+		return []interface{}{}, nil
+	}
+
+	values, apiErr := dashboards.EvaluateVariables(ctx, dashboard.Uuid, map[string]interface{}{"env": "staging", "service": "checkout"}, query)
+	require.Nil(apiErr)
+	require.Equal(map[string]dashboards.VariableValues{
+		"env":       {Values: []interface{}{"prod", "staging"}, Selected: "staging"},
+		"service":   {Values: []interface{}{"cart", "checkout"}, Selected: "checkout"},
+		"operation": {Values: []interface{}{"POST /pay"}, Selected: "POST /pay"},
+		"filter":    {Values: []interface{}{"http"}, Selected: "http"},
+	}, values)
+	require.Len(queries, 2)
+
+	// the query results are cached
+	_, apiErr = dashboards.EvaluateVariables(ctx, dashboard.Uuid, map[string]interface{}{"env": "staging", "service": "checkout"}, query)
+	require.Nil(apiErr)
+	require.Len(queries, 2)
+
+	typed, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "typed",
+		"variables": map[string]interface{}{
+			"1": map[string]interface{}{"name": "interval", "type": "INTERVAL", "intervalValue": "1m,5m"},
+			"2": map[string]interface{}{"name": "region", "type": "CONSTANT", "constantValue": "eu"},
+			"3": map[string]interface{}{"name": "env", "type": "CUSTOM", "customValue": "prod,staging", "multiSelect": true, "showALLOption": true, "allSelected": true},
+		},
+	}, nil)
+	require.Nil(apiErr)
+	values, apiErr = dashboards.EvaluateVariables(ctx, typed.Uuid, nil, query)
+	require.Nil(apiErr)
+	require.Equal(map[string]dashboards.VariableValues{
+		"interval": {Values: []interface{}{"1m", "5m"}, Selected: "1m"},
+		"region":   {Values: []interface{}{"eu"}, Selected: "eu"},
+		"env":      {Values: []interface{}{"prod", "staging"}, Selected: []interface{}{"prod", "staging"}},
+	}, values)
+
+	// variables depending on each other are rejected on save
+	_, apiErr = dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title": "cyclic",
+		"variables": map[string]interface{}{
+			"1": map[string]interface{}{"name": "a", "type": "QUERY", "queryValue": "SELECT a FROM t WHERE b = {{.b}}"},
+			"2": map[string]interface{}{"name": "b", "type": "QUERY", "queryValue": "SELECT b FROM t WHERE a = {{.a}}"},
+		},
+	}, nil)
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorBadData, apiErr.Type())
+}
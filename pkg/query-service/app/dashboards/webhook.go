@@ -0,0 +1,256 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/types/alertmanagertypes"
+	"go.uber.org/zap"
+)
+
+const (
+	webhookEventHeader = "X-Signoz-Event"
+	webhookMaxAttempts = 4
+)
+
+var (
+	// webhookBackoff is the delay before the first retry, it doubles with
+	// every attempt
+	webhookBackoff = 2 * time.Second
+	webhookClient  = &http.Client{Timeout: 10 * time.Second}
+)
+
+// webhookEvents are the events sent for the audited dashboard changes
+var webhookEvents = map[AuditAction]string{
+	AuditActionCreate:    "created",
+	AuditActionUpdate:    "updated",
+	AuditActionDelete:    "deleted",
+	AuditActionRestore:   "restored",
+	AuditActionLock:      "locked",
+	AuditActionUnlock:    "unlocked",
+	AuditActionMove:      "moved",
+	AuditActionArchive:   "archived",
+	AuditActionUnarchive: "unarchived",
+}
+
+// DashboardWebhook is an endpoint notified of dashboard changes. A webhook
+// without events is sent all of them.
+type DashboardWebhook struct {
+	Id        string    `json:"id" db:"id"`
+	Url       string    `json:"url" db:"url"`
+	Secret    string    `json:"-" db:"secret"`
+	Events    string    `json:"events" db:"events"`
+	OrgId     *string   `json:"org_id" db:"org_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	CreatedBy string    `json:"created_by" db:"created_by"`
+}
+
+func (w *DashboardWebhook) subscribes(event string) bool {
+	if w.Events == "" {
+		return true
+	}
+	for _, e := range strings.Split(w.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// DashboardWebhookPostData is the payload to create a webhook. When a secret
+// is set the payloads are signed with it.
+type DashboardWebhookPostData struct {
+	Url    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+func (p *DashboardWebhookPostData) Validate() error {
+	u, err := url.Parse(p.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url %q", p.Url)
+	}
+	for _, event := range p.Events {
+		if !isWebhookEvent(event) {
+			return fmt.Errorf("unsupported webhook event: %s", event)
+		}
+	}
+	return nil
+}
+
+func isWebhookEvent(event string) bool {
+	for _, e := range webhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// DashboardWebhookPayload is the body posted to the webhooks
+type DashboardWebhookPayload struct {
+	Event         string    `json:"event"`
+	DashboardUuid string    `json:"dashboard_uuid"`
+	Actor         string    `json:"actor"`
+	Version       int       `json:"version"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func CreateDashboardWebhook(ctx context.Context, postData DashboardWebhookPostData) (*DashboardWebhook, *model.ApiError) {
+	if err := postData.Validate(); err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	webhook := &DashboardWebhook{
+		Id:        uuid.New().String(),
+		Url:       postData.Url,
+		Secret:    postData.Secret,
+		Events:    strings.Join(postData.Events, ","),
+		CreatedAt: time.Now(),
+	}
+	if user := common.GetUserFromContext(ctx); user != nil {
+		webhook.CreatedBy = user.Email
+		if user.OrgId != "" {
+			webhook.OrgId = &user.OrgId
+		}
+	}
+
+	_, err := db.Exec(`INSERT INTO dashboard_webhooks (id, url, secret, events, org_id, created_at, created_by) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		webhook.Id, webhook.Url, webhook.Secret, webhook.Events, webhook.OrgId, webhook.CreatedAt, webhook.CreatedBy)
+	if err != nil {
+		zap.L().Error("Error in creating dashboard webhook", zap.Error(err))
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return webhook, nil
+}
+
+// GetDashboardWebhooks returns the webhooks of the org of the user
+func GetDashboardWebhooks(ctx context.Context) ([]DashboardWebhook, *model.ApiError) {
+	webhooks := []DashboardWebhook{}
+	scope, args := orgScope(ctx)
+	err := db.Select(&webhooks, `SELECT * FROM dashboard_webhooks WHERE 1=1`+scope+` ORDER BY created_at`, args...)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	return webhooks, nil
+}
+
+func DeleteDashboardWebhook(ctx context.Context, id string) *model.ApiError {
+	scope, args := orgScope(ctx)
+	result, err := db.Exec(`DELETE FROM dashboard_webhooks WHERE id=?`+scope, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+	if affectedRows == 0 {
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no webhook found with id: %s", id)}
+	}
+	return nil
+}
+
+// notifyWebhooks sends the event of the dashboard change to the webhooks of
+// its org subscribed to it. The webhooks are called in the background so a slow or
+// failing endpoint does not hold up the change.
+func notifyWebhooks(ctx context.Context, dashboardUuid string, action AuditAction, version int) {
+	event, ok := webhookEvents[action]
+	if !ok {
+		return
+	}
+
+	webhooks := []DashboardWebhook{}
+	query := `SELECT * FROM dashboard_webhooks WHERE org_id = (SELECT org_id FROM dashboards WHERE uuid=?)`
+	if err := db.Select(&webhooks, query, dashboardUuid); err != nil {
+		zap.L().Error("Error in getting dashboard webhooks", zap.Error(err))
+		return
+	}
+
+	var actor string
+	if user := common.GetUserFromContext(ctx); user != nil {
+		actor = user.Email
+	}
+	body, err := json.Marshal(DashboardWebhookPayload{
+		Event:         event,
+		DashboardUuid: dashboardUuid,
+		Actor:         actor,
+		Version:       version,
+		Timestamp:     time.Now(),
+	})
+	if err != nil {
+		zap.L().Error("Error in marshalling dashboard webhook payload", zap.Error(err))
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.subscribes(event) {
+			continue
+		}
+		go func(webhook DashboardWebhook) {
+			if err := deliverWebhook(webhook, event, body); err != nil {
+				zap.L().Error("Error in sending dashboard webhook", zap.String("webhook", webhook.Id), zap.String("event", event), zap.Error(err))
+			}
+		}(webhook)
+	}
+}
+
+// deliverWebhook posts the body to the webhook, retrying with an exponential
+// backoff until it is accepted. Client errors other than 429 are not retried.
+func deliverWebhook(webhook DashboardWebhook, event string, body []byte) error {
+	backoff := webhookBackoff
+	var err error
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		var retry bool
+		retry, err = postWebhook(webhook, event, body)
+		if err == nil || !retry {
+			return err
+		}
+		if attempt < webhookMaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", webhookMaxAttempts, err)
+}
+
+// postWebhook makes a single delivery attempt and reports whether a failed
+// attempt can be retried
+func postWebhook(webhook DashboardWebhook, event string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, event)
+	// signed as the alert notifications are, every attempt with its own
+	// timestamp for the receivers to reject the replayed ones
+	if webhook.Secret != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(alertmanagertypes.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(alertmanagertypes.WebhookSignatureHeader, alertmanagertypes.SignWebhook([]byte(webhook.Secret), timestamp, body))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+}
@@ -0,0 +1,76 @@
+package dashboards
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/types/alertmanagertypes"
+)
+
+func TestDeliverWebhook(t *testing.T) {
+	webhookBackoff = time.Millisecond
+	body := []byte(`{"event":"updated","dashboard_uuid":"a","actor":"alice@signoz.io","version":2}`)
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		received, _ := io.ReadAll(r.Body)
+		require.Equal(t, body, received)
+		require.Equal(t, "updated", r.Header.Get(webhookEventHeader))
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(alertmanagertypes.WebhookTimestampHeader), 10, 64)
+		require.NoError(t, err)
+		require.Equal(t, alertmanagertypes.SignWebhook([]byte("secret"), timestamp, received), r.Header.Get(alertmanagertypes.WebhookSignatureHeader))
+
+		// fail the first attempts to check they are retried
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := DashboardWebhook{Id: "hook", Url: server.URL, Secret: "secret"}
+	require.NoError(t, deliverWebhook(webhook, "updated", body))
+	require.Equal(t, 3, attempts)
+
+	// client errors are not retried
+	attempts = 0
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	webhook.Url = rejecting.URL
+	require.Error(t, deliverWebhook(webhook, "updated", body))
+	require.Equal(t, 1, attempts)
+}
+
+func TestDashboardWebhookSubscribes(t *testing.T) {
+	all := DashboardWebhook{}
+	require.True(t, all.subscribes("created"))
+
+	webhook := DashboardWebhook{Events: "created,deleted"}
+	require.True(t, webhook.subscribes("deleted"))
+	require.False(t, webhook.subscribes("updated"))
+}
+
+func TestDashboardWebhookPostDataValidate(t *testing.T) {
+	valid := DashboardWebhookPostData{Url: "https://hooks.example.com/signoz", Events: []string{"created", "locked"}}
+	require.NoError(t, valid.Validate())
+
+	for _, invalid := range []DashboardWebhookPostData{
+		{Url: "hooks.example.com"},
+		{Url: "ftp://hooks.example.com"},
+		{Url: "https://hooks.example.com", Events: []string{"viewed"}},
+	} {
+		require.Error(t, invalid.Validate(), invalid.Url)
+	}
+}
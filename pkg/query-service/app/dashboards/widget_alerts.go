@@ -0,0 +1,83 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+// WidgetAlert is an alert rule created from a panel, the rule links back to
+// the panel it was created from
+type WidgetAlert struct {
+	RuleId    int       `json:"rule_id" db:"rule_id"`
+	Name      string    `json:"name" db:"-"`
+	Disabled  bool      `json:"disabled" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	CreatedBy string    `json:"created_by" db:"created_by"`
+}
+
+// AddWidgetAlert links the alert rule to the panel it was created from
+func AddWidgetAlert(ctx context.Context, uuid string, widgetId string, ruleId int) *model.ApiError {
+	createdBy := ""
+	if user := common.GetUserFromContext(ctx); user != nil {
+		createdBy = user.Email
+	}
+
+	_, err := db.Exec(`INSERT INTO dashboard_widget_alerts (dashboard_uuid, widget_id, rule_id, created_at, created_by) VALUES (?, ?, ?, ?, ?) ON CONFLICT(dashboard_uuid, widget_id, rule_id) DO NOTHING`,
+		uuid, widgetId, ruleId, time.Now(), createdBy)
+	if err != nil {
+		zap.L().Error("Error in linking alert to widget", zap.String("uuid", uuid), zap.Error(err))
+		return &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	return nil
+}
+
+// GetWidgetAlerts returns the alert rules created from the panel
+func GetWidgetAlerts(ctx context.Context, uuid string, widgetId string) ([]WidgetAlert, *model.ApiError) {
+	dashboard, apiErr := GetDashboard(ctx, uuid)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if _, err := GetWidget(dashboard.Data, widgetId); err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: err}
+	}
+
+	rows := []struct {
+		WidgetAlert
+		Data string `db:"data"`
+	}{}
+	err := db.Select(&rows, `SELECT a.rule_id, a.created_at, a.created_by, r.data FROM dashboard_widget_alerts a JOIN rules r ON r.id = a.rule_id WHERE a.dashboard_uuid=? AND a.widget_id=? ORDER BY a.created_at`, uuid, widgetId)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorExec, Err: err}
+	}
+
+	alerts := make([]WidgetAlert, 0, len(rows))
+	for _, row := range rows {
+		var data struct {
+			Alert    string `json:"alert"`
+			Disabled bool   `json:"disabled"`
+		}
+		// the name is only informative, rules which can't be parsed are
+		// still listed
+		_ = json.Unmarshal([]byte(row.Data), &data)
+		alert := row.WidgetAlert
+		alert.Name = data.Alert
+		alert.Disabled = data.Disabled
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+// pruneWidgetAlerts removes the purged dashboards from the widget alerts, the
+// rules are kept and still link to the panel
+func pruneWidgetAlerts() {
+	_, err := db.Exec(`DELETE FROM dashboard_widget_alerts WHERE dashboard_uuid NOT IN (SELECT uuid FROM dashboards)`)
+	if err != nil {
+		zap.L().Error("Error in removing purged dashboards from the widget alerts", zap.Error(err))
+	}
+}
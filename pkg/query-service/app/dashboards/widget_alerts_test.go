@@ -0,0 +1,58 @@
+package dashboards_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/dashboards"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestWidgetAlerts(t *testing.T) {
+	require := require.New(t)
+	store := utils.NewQueryServiceDBForTests(t)
+	ctx := contextWithUser("alice@signoz.io")
+
+	dashboard, apiErr := dashboards.CreateDashboard(ctx, map[string]interface{}{
+		"title":   "checkout",
+		"widgets": []interface{}{map[string]interface{}{"id": "latency"}},
+	}, nil)
+	require.Nil(apiErr)
+
+	alerts, apiErr := dashboards.GetWidgetAlerts(ctx, dashboard.Uuid, "latency")
+	require.Nil(apiErr)
+	require.Empty(alerts)
+
+	ruleData := fmt.Sprintf(`{"alert":"checkout latency","disabled":true,"panel":{"dashboardUuid":"%s","widgetId":"latency"}}`, dashboard.Uuid)
+	result, err := store.SQLxDB().Exec(`INSERT INTO rules (created_at, created_by, updated_at, updated_by, data) VALUES (?, ?, ?, ?, ?)`,
+		time.Now(), "alice@signoz.io", time.Now(), "alice@signoz.io", ruleData)
+	require.NoError(err)
+	ruleId, err := result.LastInsertId()
+	require.NoError(err)
+
+	require.Nil(dashboards.AddWidgetAlert(ctx, dashboard.Uuid, "latency", int(ruleId)))
+	// linking the rule again is a no-op
+	require.Nil(dashboards.AddWidgetAlert(ctx, dashboard.Uuid, "latency", int(ruleId)))
+
+	alerts, apiErr = dashboards.GetWidgetAlerts(ctx, dashboard.Uuid, "latency")
+	require.Nil(apiErr)
+	require.Len(alerts, 1)
+	require.Equal(int(ruleId), alerts[0].RuleId)
+	require.Equal("checkout latency", alerts[0].Name)
+	require.True(alerts[0].Disabled)
+	require.Equal("alice@signoz.io", alerts[0].CreatedBy)
+
+	// the deleted rules are not listed
+	_, err = store.SQLxDB().Exec(`DELETE FROM rules WHERE id=?`, ruleId)
+	require.NoError(err)
+	alerts, apiErr = dashboards.GetWidgetAlerts(ctx, dashboard.Uuid, "latency")
+	require.Nil(apiErr)
+	require.Empty(alerts)
+
+	_, apiErr = dashboards.GetWidgetAlerts(ctx, dashboard.Uuid, "errors")
+	require.NotNil(apiErr)
+	require.Equal(model.ErrorNotFound, apiErr.Type())
+}
@@ -0,0 +1,156 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.signoz.io/signoz/pkg/query-service/app/explorer"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+// widgetPanelTypes maps the widget panel types to the panel types understood
+// by the query range api
+var widgetPanelTypes = map[string]v3.PanelType{
+	"graph":     v3.PanelTypeGraph,
+	"bar":       v3.PanelTypeGraph,
+	"histogram": v3.PanelTypeGraph,
+	"value":     v3.PanelTypeValue,
+	"table":     v3.PanelTypeTable,
+	"pie":       v3.PanelTypeTable,
+	"list":      v3.PanelTypeList,
+	"trace":     v3.PanelTypeTrace,
+}
+
+// GetWidget returns the widget with the given id from the dashboard data
+func GetWidget(data Data, widgetId string) (map[string]interface{}, error) {
+	widgets, _ := data["widgets"].([]interface{})
+	for _, w := range widgets {
+		if widget, ok := w.(map[string]interface{}); ok && widget["id"] == widgetId {
+			return widget, nil
+		}
+	}
+	return nil, fmt.Errorf("no widget found with id: %s", widgetId)
+}
+
+// WidgetQueryRangeParams builds the query range params the frontend would
+// send for the widget, using the selected values of the dashboard variables.
+// The widgets referencing a saved view run its query.
+func WidgetQueryRangeParams(data Data, widgetId string, start, end int64) (*v3.QueryRangeParamsV3, error) {
+	widget, err := GetWidget(data, widgetId)
+	if err != nil {
+		return nil, err
+	}
+
+	panelType, ok := widgetPanelTypes[fmt.Sprintf("%v", widget["panelTypes"])]
+	if !ok {
+		return nil, fmt.Errorf("widget %s of type %v has no query", widgetId, widget["panelTypes"])
+	}
+
+	if viewId := widgetSavedView(widget); viewId != "" {
+		view, err := explorer.GetSharedView(viewId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid saved view in widget %s: %w", widgetId, err)
+		}
+		compositeQuery := view.CompositeQuery
+		compositeQuery.PanelType = panelType
+		return widgetQueryRangeParams(data, compositeQuery, start, end), nil
+	}
+
+	query, ok := widget["query"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("widget %s has no query", widgetId)
+	}
+
+	compositeQuery := &v3.CompositeQuery{
+		PanelType: panelType,
+		QueryType: v3.QueryType(fmt.Sprintf("%v", query["queryType"])),
+	}
+	switch compositeQuery.QueryType {
+	case v3.QueryTypeBuilder:
+		builder, _ := query["builder"].(map[string]interface{})
+		compositeQuery.BuilderQueries = map[string]*v3.BuilderQuery{}
+		for _, key := range []string{"queryData", "queryFormulas"} {
+			queries := []*v3.BuilderQuery{}
+			if err := remarshal(builder[key], &queries); err != nil {
+				return nil, fmt.Errorf("invalid builder query in widget %s: %w", widgetId, err)
+			}
+			for _, q := range queries {
+				compositeQuery.BuilderQueries[q.QueryName] = q
+			}
+		}
+	case v3.QueryTypeClickHouseSQL:
+		queries := []struct {
+			Name string `json:"name"`
+			v3.ClickHouseQuery
+		}{}
+		if err := remarshal(query["clickhouse_sql"], &queries); err != nil {
+			return nil, fmt.Errorf("invalid clickhouse query in widget %s: %w", widgetId, err)
+		}
+		compositeQuery.ClickHouseQueries = map[string]*v3.ClickHouseQuery{}
+		for i := range queries {
+			compositeQuery.ClickHouseQueries[queries[i].Name] = &queries[i].ClickHouseQuery
+		}
+	case v3.QueryTypePromQL:
+		queries := []struct {
+			Name string `json:"name"`
+			v3.PromQuery
+		}{}
+		if err := remarshal(query["promql"], &queries); err != nil {
+			return nil, fmt.Errorf("invalid promql query in widget %s: %w", widgetId, err)
+		}
+		compositeQuery.PromQueries = map[string]*v3.PromQuery{}
+		for i := range queries {
+			compositeQuery.PromQueries[queries[i].Name] = &queries[i].PromQuery
+		}
+	default:
+		return nil, fmt.Errorf("unsupported query type %s in widget %s", compositeQuery.QueryType, widgetId)
+	}
+
+	return widgetQueryRangeParams(data, compositeQuery, start, end), nil
+}
+
+func widgetQueryRangeParams(data Data, compositeQuery *v3.CompositeQuery, start, end int64) *v3.QueryRangeParamsV3 {
+	// the step grows with the time range so that the series have a bounded
+	// number of points
+	step := common.MinAllowedStepInterval(start, end)
+	if step < 60 {
+		step = 60
+	}
+	return &v3.QueryRangeParamsV3{
+		Start:          start,
+		End:            end,
+		Step:           step,
+		CompositeQuery: compositeQuery,
+		Variables:      selectedVariableValues(data),
+		FormatForWeb:   compositeQuery.PanelType == v3.PanelTypeTable,
+	}
+}
+
+func selectedVariableValues(data Data) map[string]interface{} {
+	values := map[string]interface{}{}
+	variables, _ := data["variables"].(map[string]interface{})
+	for _, v := range variables {
+		variable, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := variable["name"].(string)
+		if name == "" || variable["selectedValue"] == nil {
+			continue
+		}
+		values[name] = variable["selectedValue"]
+	}
+	return values
+}
+
+func remarshal(from interface{}, to interface{}) error {
+	if from == nil {
+		return nil
+	}
+	raw, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, to)
+}
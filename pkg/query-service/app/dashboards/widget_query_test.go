@@ -0,0 +1,47 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func TestWidgetQueryRangeParams(t *testing.T) {
+	require := require.New(t)
+
+	var data Data
+	require.NoError(json.Unmarshal([]byte(`{
+		"variables": {"a": {"name": "service", "selectedValue": ["frontend"]}},
+		"widgets": [
+			{"id": "row", "panelTypes": "row"},
+			{"id": "latency", "panelTypes": "graph", "query": {"queryType": "builder", "builder": {
+				"queryData": [{"queryName": "A", "expression": "A", "dataSource": "metrics", "aggregateOperator": "sum_rate", "aggregateAttribute": {"key": "calls"}}],
+				"queryFormulas": [{"queryName": "F1", "expression": "A * 2"}]
+			}}},
+			{"id": "errors", "panelTypes": "value", "query": {"queryType": "clickhouse_sql", "clickhouse_sql": [
+				{"name": "A", "query": "SELECT 1", "disabled": false}
+			]}}
+		]
+	}`), &data))
+
+	params, err := WidgetQueryRangeParams(data, "latency", 1000, 2000)
+	require.NoError(err)
+	require.Equal(v3.QueryTypeBuilder, params.CompositeQuery.QueryType)
+	require.Equal(v3.PanelTypeGraph, params.CompositeQuery.PanelType)
+	require.Len(params.CompositeQuery.BuilderQueries, 2)
+	require.Equal("calls", params.CompositeQuery.BuilderQueries["A"].AggregateAttribute.Key)
+	require.Equal("A * 2", params.CompositeQuery.BuilderQueries["F1"].Expression)
+	require.Equal([]interface{}{"frontend"}, params.Variables["service"])
+
+	params, err = WidgetQueryRangeParams(data, "errors", 1000, 2000)
+	require.NoError(err)
+	require.Equal(v3.PanelTypeValue, params.CompositeQuery.PanelType)
+	require.Equal("SELECT 1", params.CompositeQuery.ClickHouseQueries["A"].Query)
+
+	_, err = WidgetQueryRangeParams(data, "row", 1000, 2000)
+	require.Error(err)
+	_, err = WidgetQueryRangeParams(data, "missing", 1000, 2000)
+	require.Error(err)
+}
@@ -2,7 +2,9 @@ package explorer
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -10,6 +12,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/constants"
 	"go.signoz.io/signoz/pkg/query-service/model"
 	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
 	"go.signoz.io/signoz/pkg/query-service/telemetry"
@@ -31,6 +35,28 @@ type SavedView struct {
 	Tags       string    `json:"tags" db:"tags"`
 	Data       string    `json:"data" db:"data"`
 	ExtraData  string    `json:"extra_data" db:"extra_data"`
+	Visibility string    `json:"visibility" db:"visibility"`
+}
+
+var (
+	// ErrViewNotFound is returned for the saved views which don't exist and
+	// for the private views of other users
+	ErrViewNotFound = errors.New("saved view not found")
+	// ErrViewForbidden is returned when changing a saved view of another
+	// user without being an admin
+	ErrViewForbidden = errors.New("only the owner of the saved view and the admins can change it")
+)
+
+// ViewFilters are the filters of the saved views library, the empty ones
+// match all the views
+type ViewFilters struct {
+	SourcePage string
+	Name       string
+	Category   string
+	Visibility string
+	CreatedBy  string
+	// Tags are the tags the views have all of
+	Tags []string
 }
 
 // InitWithDSN sets up setting up the connection pool global variable.
@@ -54,63 +80,69 @@ func GetViews() ([]*v3.SavedView, error) {
 
 	var savedViews []*v3.SavedView
 	for _, view := range views {
-		var compositeQuery v3.CompositeQuery
-		err = json.Unmarshal([]byte(view.Data), &compositeQuery)
+		savedView, err := view.toSavedView()
 		if err != nil {
-			return nil, fmt.Errorf("error in unmarshalling explorer query data: %s", err.Error())
+			return nil, err
 		}
-		savedViews = append(savedViews, &v3.SavedView{
-			UUID:           view.UUID,
-			Name:           view.Name,
-			Category:       view.Category,
-			CreatedAt:      view.CreatedAt,
-			CreatedBy:      view.CreatedBy,
-			UpdatedAt:      view.UpdatedAt,
-			UpdatedBy:      view.UpdatedBy,
-			Tags:           strings.Split(view.Tags, ","),
-			SourcePage:     view.SourcePage,
-			CompositeQuery: &compositeQuery,
-			ExtraData:      view.ExtraData,
-		})
+		savedViews = append(savedViews, savedView)
 	}
 	return savedViews, nil
 }
 
-func GetViewsForFilters(sourcePage string, name string, category string) ([]*v3.SavedView, error) {
-	var views []SavedView
-	var err error
-	if len(category) == 0 {
-		err = db.Select(&views, "SELECT * FROM saved_views WHERE source_page = ? AND name LIKE ?", sourcePage, "%"+name+"%")
-	} else {
-		err = db.Select(&views, "SELECT * FROM saved_views WHERE source_page = ? AND category LIKE ? AND name LIKE ?", sourcePage, "%"+category+"%", "%"+name+"%")
+// GetViewsForFilters returns the saved views matching the filters, out of the
+// org wide views and the private views of the user
+func GetViewsForFilters(ctx context.Context, filters ViewFilters) ([]*v3.SavedView, error) {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("error in getting email from context")
 	}
-	if err != nil {
+
+	query := "SELECT * FROM saved_views WHERE (visibility = ? OR created_by = ?) AND name LIKE ?"
+	args := []interface{}{v3.SavedViewVisibilityOrg, claims.Email, "%" + filters.Name + "%"}
+	if filters.SourcePage != "" {
+		query += " AND source_page = ?"
+		args = append(args, filters.SourcePage)
+	}
+	if filters.Category != "" {
+		query += " AND category LIKE ?"
+		args = append(args, "%"+filters.Category+"%")
+	}
+	if filters.Visibility != "" {
+		query += " AND visibility = ?"
+		args = append(args, filters.Visibility)
+	}
+	if filters.CreatedBy != "" {
+		query += " AND created_by = ?"
+		args = append(args, filters.CreatedBy)
+	}
+
+	var views []SavedView
+	if err := db.Select(&views, query+" ORDER BY name", args...); err != nil {
 		return nil, fmt.Errorf("error in getting saved views: %s", err.Error())
 	}
 
-	var savedViews []*v3.SavedView
+	savedViews := []*v3.SavedView{}
 	for _, view := range views {
-		var compositeQuery v3.CompositeQuery
-		err = json.Unmarshal([]byte(view.Data), &compositeQuery)
+		savedView, err := view.toSavedView()
 		if err != nil {
-			return nil, fmt.Errorf("error in unmarshalling explorer query data: %s", err.Error())
+			return nil, err
+		}
+		if hasTags(savedView.Tags, filters.Tags) {
+			savedViews = append(savedViews, savedView)
 		}
-		savedViews = append(savedViews, &v3.SavedView{
-			UUID:           view.UUID,
-			Name:           view.Name,
-			CreatedAt:      view.CreatedAt,
-			CreatedBy:      view.CreatedBy,
-			UpdatedAt:      view.UpdatedAt,
-			UpdatedBy:      view.UpdatedBy,
-			Tags:           strings.Split(view.Tags, ","),
-			SourcePage:     view.SourcePage,
-			CompositeQuery: &compositeQuery,
-			ExtraData:      view.ExtraData,
-		})
 	}
 	return savedViews, nil
 }
 
+func hasTags(tags []string, wanted []string) bool {
+	for _, tag := range wanted {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
 func CreateView(ctx context.Context, view v3.SavedView) (string, error) {
 	data, err := json.Marshal(view.CompositeQuery)
 	if err != nil {
@@ -134,7 +166,7 @@ func CreateView(ctx context.Context, view v3.SavedView) (string, error) {
 	updatedBy := claims.Email
 
 	_, err = db.Exec(
-		"INSERT INTO saved_views (uuid, name, category, created_at, created_by, updated_at, updated_by, source_page, tags, data, extra_data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		"INSERT INTO saved_views (uuid, name, category, created_at, created_by, updated_at, updated_by, source_page, tags, data, extra_data, visibility) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 		uuid_,
 		view.Name,
 		view.Category,
@@ -146,6 +178,7 @@ func CreateView(ctx context.Context, view v3.SavedView) (string, error) {
 		strings.Join(view.Tags, ","),
 		data,
 		view.ExtraData,
+		view.Visibility,
 	)
 	if err != nil {
 		return "", fmt.Errorf("error in creating saved view: %s", err.Error())
@@ -153,15 +186,71 @@ func CreateView(ctx context.Context, view v3.SavedView) (string, error) {
 	return uuid_, nil
 }
 
-func GetView(uuid_ string) (*v3.SavedView, error) {
+// GetView returns the saved view, the private views are only returned to
+// their owner
+func GetView(ctx context.Context, uuid_ string) (*v3.SavedView, error) {
+	view, err := getView(uuid_)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("error in getting email from context")
+	}
+	if view.Visibility == v3.SavedViewVisibilityPrivate && view.CreatedBy != claims.Email {
+		return nil, ErrViewNotFound
+	}
+	return view.toSavedView()
+}
+
+// GetSharedView returns the org wide saved view, for the dashboards
+// referencing it
+func GetSharedView(uuid_ string) (*v3.SavedView, error) {
+	view, err := getView(uuid_)
+	if err != nil {
+		return nil, err
+	}
+	if view.Visibility == v3.SavedViewVisibilityPrivate {
+		return nil, fmt.Errorf("saved view %s is private", uuid_)
+	}
+	return view.toSavedView()
+}
+
+func getView(uuid_ string) (*SavedView, error) {
 	var view SavedView
 	err := db.Get(&view, "SELECT * FROM saved_views WHERE uuid = ?", uuid_)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrViewNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error in getting saved view: %s", err.Error())
 	}
+	return &view, nil
+}
 
+// getManagedView returns the saved view if the user can change it, which is
+// left to its owner and admins. Views created without a user have no owner.
+func getManagedView(ctx context.Context, uuid_ string) (*SavedView, error) {
+	view, err := getView(uuid_)
+	if err != nil {
+		return nil, err
+	}
+	user := common.GetUserFromContext(ctx)
+	if user == nil {
+		return nil, fmt.Errorf("error in getting user from context")
+	}
+	if view.CreatedBy == "" || view.CreatedBy == user.Email || user.Role == constants.AdminGroup {
+		return view, nil
+	}
+	if view.Visibility == v3.SavedViewVisibilityPrivate {
+		return nil, ErrViewNotFound
+	}
+	return nil, ErrViewForbidden
+}
+
+func (view *SavedView) toSavedView() (*v3.SavedView, error) {
 	var compositeQuery v3.CompositeQuery
-	err = json.Unmarshal([]byte(view.Data), &compositeQuery)
+	err := json.Unmarshal([]byte(view.Data), &compositeQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error in unmarshalling explorer query data: %s", err.Error())
 	}
@@ -177,6 +266,7 @@ func GetView(uuid_ string) (*v3.SavedView, error) {
 		Tags:           strings.Split(view.Tags, ","),
 		CompositeQuery: &compositeQuery,
 		ExtraData:      view.ExtraData,
+		Visibility:     view.Visibility,
 	}, nil
 }
 
@@ -190,19 +280,25 @@ func UpdateView(ctx context.Context, uuid_ string, view v3.SavedView) error {
 	if !ok {
 		return fmt.Errorf("error in getting email from context")
 	}
+	if _, err := getManagedView(ctx, uuid_); err != nil {
+		return err
+	}
 
 	updatedAt := time.Now()
 	updatedBy := claims.Email
 
-	_, err = db.Exec("UPDATE saved_views SET updated_at = ?, updated_by = ?, name = ?, category = ?, source_page = ?, tags = ?, data = ?, extra_data = ? WHERE uuid = ?",
-		updatedAt, updatedBy, view.Name, view.Category, view.SourcePage, strings.Join(view.Tags, ","), data, view.ExtraData, uuid_)
+	_, err = db.Exec("UPDATE saved_views SET updated_at = ?, updated_by = ?, name = ?, category = ?, source_page = ?, tags = ?, data = ?, extra_data = ?, visibility = ? WHERE uuid = ?",
+		updatedAt, updatedBy, view.Name, view.Category, view.SourcePage, strings.Join(view.Tags, ","), data, view.ExtraData, view.Visibility, uuid_)
 	if err != nil {
 		return fmt.Errorf("error in updating saved view: %s", err.Error())
 	}
 	return nil
 }
 
-func DeleteView(uuid_ string) error {
+func DeleteView(ctx context.Context, uuid_ string) error {
+	if _, err := getManagedView(ctx, uuid_); err != nil {
+		return err
+	}
 	_, err := db.Exec("DELETE FROM saved_views WHERE uuid = ?", uuid_)
 	if err != nil {
 		return fmt.Errorf("error in deleting explorer query: %s", err.Error())
@@ -3,6 +3,7 @@ package app
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,6 +24,7 @@ import (
 	"github.com/gorilla/websocket"
 	jsoniter "github.com/json-iterator/go"
 	_ "github.com/mattn/go-sqlite3"
+	promModel "github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 
 	"go.signoz.io/signoz/pkg/query-service/agentConf"
@@ -54,6 +56,7 @@ import (
 	"go.signoz.io/signoz/pkg/types/authtypes"
 
 	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 
 	"go.signoz.io/signoz/pkg/query-service/app/integrations/messagingQueues/kafka"
 	"go.signoz.io/signoz/pkg/query-service/app/logparsingpipeline"
@@ -63,6 +66,7 @@ import (
 	"go.signoz.io/signoz/pkg/query-service/model"
 	"go.signoz.io/signoz/pkg/query-service/rules"
 	"go.signoz.io/signoz/pkg/query-service/telemetry"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
 	"go.signoz.io/signoz/pkg/query-service/version"
 )
 
@@ -269,11 +273,7 @@ func NewAPIHandler(opts APIHandlerOpts) (*APIHandler, error) {
 	}
 	aH.queryBuilder = queryBuilder.NewQueryBuilder(builderOpts, aH.featureFlags)
 
-	dashboards.LoadDashboardFiles(aH.featureFlags)
-	// if errReadingDashboards != nil {
-	// 	return nil, errReadingDashboards
-	// }
-
+	// dashboard files are provisioned by the server on start
 	// check if at least one user is created
 	hasUsers, err := aH.appDao.GetUsersWithOpts(context.Background(), 1)
 	if err.Error() != "" {
@@ -354,6 +354,8 @@ func RespondError(w http.ResponseWriter, apiErr model.BaseApiError, data interfa
 		code = http.StatusForbidden
 	case model.ErrorConflict:
 		code = http.StatusConflict
+	case model.ErrorTooLarge:
+		code = http.StatusRequestEntityTooLarge
 	default:
 		code = http.StatusInternalServerError
 	}
@@ -488,9 +490,30 @@ func (aH *APIHandler) RegisterPrivateRoutes(router *mux.Router) {
 
 // RegisterRoutes registers routes for this handler on the given router
 func (aH *APIHandler) RegisterRoutes(router *mux.Router, am *AuthMiddleware) {
-	router.HandleFunc("/api/v1/query_range", am.ViewAccess(aH.queryRangeMetrics)).Methods(http.MethodGet)
-	router.HandleFunc("/api/v1/query", am.ViewAccess(aH.queryMetrics)).Methods(http.MethodGet)
+	// the query, query range, series and labels endpoints are compatible with
+	// the Prometheus HTTP API, the Prometheus clients use both methods
+	router.HandleFunc("/api/v1/query_range", am.ViewAccess(aH.queryRangeMetrics)).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/api/v1/query_range/explain", am.ViewAccess(aH.explainQueryRange)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/query", am.ViewAccess(aH.queryMetrics)).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/api/v1/series", am.ViewAccess(aH.promSeries)).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/api/v1/labels", am.ViewAccess(aH.promLabelNames)).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/api/v1/label/{name}/values", am.ViewAccess(aH.promLabelValues)).Methods(http.MethodGet)
+	// the query range endpoint of the Loki API runs the LogQL queries on the logs
+	router.HandleFunc("/loki/api/v1/query_range", am.ViewAccess(aH.lokiQueryRange)).Methods(http.MethodGet, http.MethodPost)
 	router.HandleFunc("/api/v1/channels", am.ViewAccess(aH.listChannels)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/channels/preview", am.EditAccess(aH.previewChannel)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/channels/rate_limits", am.ViewAccess(aH.listChannelRateLimits)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/channels/{id}/rate_limit", am.AdminAccess(aH.setChannelRateLimit)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/channels/{id}/rate_limit", am.AdminAccess(aH.deleteChannelRateLimit)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/channels/resolved_notifications", am.ViewAccess(aH.listChannelResolvedNotifications)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/channels/{id}/resolved_notification", am.AdminAccess(aH.setChannelResolvedNotification)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/channels/{id}/resolved_notification", am.AdminAccess(aH.deleteChannelResolvedNotification)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/channels/templates", am.ViewAccess(aH.listChannelTemplates)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/channels/templates/{type}", am.AdminAccess(aH.setChannelTemplate)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/channels/templates/{type}", am.AdminAccess(aH.deleteChannelTemplate)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/notification_routing", am.ViewAccess(aH.getNotificationRouting)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/notification_routing", am.AdminAccess(aH.setNotificationRouting)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/notification_routing/route", am.ViewAccess(aH.routeNotification)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/channels/{id}", am.ViewAccess(aH.getChannel)).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/channels/{id}", am.AdminAccess(aH.editChannel)).Methods(http.MethodPut)
 	router.HandleFunc("/api/v1/channels/{id}", am.AdminAccess(aH.deleteChannel)).Methods(http.MethodDelete)
@@ -500,12 +523,25 @@ func (aH *APIHandler) RegisterRoutes(router *mux.Router, am *AuthMiddleware) {
 	router.HandleFunc("/api/v1/alerts", am.ViewAccess(aH.getAlerts)).Methods(http.MethodGet)
 
 	router.HandleFunc("/api/v1/rules", am.ViewAccess(aH.listRules)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/rules/export", am.ViewAccess(aH.exportRules)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/rules/import", am.EditAccess(aH.importRules)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/rules/{id}", am.ViewAccess(aH.getRule)).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/rules", am.EditAccess(aH.createRule)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/rules/{id}", am.EditAccess(aH.editRule)).Methods(http.MethodPut)
 	router.HandleFunc("/api/v1/rules/{id}", am.EditAccess(aH.deleteRule)).Methods(http.MethodDelete)
 	router.HandleFunc("/api/v1/rules/{id}", am.EditAccess(aH.patchRule)).Methods(http.MethodPatch)
+	router.HandleFunc("/api/v1/rules/{id}/versions", am.ViewAccess(aH.getRuleVersions)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/rules/{id}/versions/diff", am.ViewAccess(aH.diffRuleVersions)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/rules/{id}/versions/{version}", am.ViewAccess(aH.getRuleVersion)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/rules/{id}/versions/{version}/restore", am.EditAccess(aH.restoreRuleVersion)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/testRule", am.EditAccess(aH.testRule)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/rules/test", am.EditAccess(aH.backtestRule)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/rules/{id}/alerts/acknowledge", am.EditAccess(aH.acknowledgeRuleAlerts)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/rules/{id}/alerts/unacknowledge", am.EditAccess(aH.unacknowledgeRuleAlerts)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/rules/{id}/pause", am.EditAccess(aH.pauseRule)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/rules/{id}/resume", am.EditAccess(aH.resumeRule)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/rules/{id}/pauses", am.ViewAccess(aH.listRulePauses)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/rules/{id}/history", am.ViewAccess(aH.listRuleStateHistory)).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/rules/{id}/history/stats", am.ViewAccess(aH.getRuleStats)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/rules/{id}/history/timeline", am.ViewAccess(aH.getRuleStateHistory)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/rules/{id}/history/top_contributors", am.ViewAccess(aH.getRuleStateHistoryTopContributors)).Methods(http.MethodPost)
@@ -516,12 +552,116 @@ func (aH *APIHandler) RegisterRoutes(router *mux.Router, am *AuthMiddleware) {
 	router.HandleFunc("/api/v1/downtime_schedules", am.EditAccess(aH.createDowntimeSchedule)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/downtime_schedules/{id}", am.EditAccess(aH.editDowntimeSchedule)).Methods(http.MethodPut)
 	router.HandleFunc("/api/v1/downtime_schedules/{id}", am.EditAccess(aH.deleteDowntimeSchedule)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/downtime_schedules/{id}/suppressions", am.ViewAccess(aH.getDowntimeScheduleSuppressions)).Methods(http.MethodGet)
+
+	router.HandleFunc("/api/v1/escalation_policies", am.ViewAccess(aH.listEscalationPolicies)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/escalation_policies/{id}", am.ViewAccess(aH.getEscalationPolicy)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/escalation_policies", am.EditAccess(aH.createEscalationPolicy)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/escalation_policies/{id}", am.EditAccess(aH.editEscalationPolicy)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/escalation_policies/{id}", am.EditAccess(aH.deleteEscalationPolicy)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/api/v1/teams", am.ViewAccess(aH.listTeams)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/teams/{id}", am.ViewAccess(aH.getTeam)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/teams", am.AdminAccess(aH.createTeam)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/teams/{id}", am.AdminAccess(aH.editTeam)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/teams/{id}", am.AdminAccess(aH.deleteTeam)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/api/v1/alert_digests", am.ViewAccess(aH.listAlertDigests)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/alert_digests/{id}", am.ViewAccess(aH.getAlertDigest)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/alert_digests/{id}/preview", am.ViewAccess(aH.previewAlertDigest)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/alert_digests", am.EditAccess(aH.createAlertDigest)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/alert_digests/{id}", am.EditAccess(aH.editAlertDigest)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/alert_digests/{id}", am.EditAccess(aH.deleteAlertDigest)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/api/v1/oncall_schedules", am.ViewAccess(aH.listOnCallSchedules)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/oncall_schedules/{id}", am.ViewAccess(aH.getOnCallSchedule)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/oncall_schedules/{id}/oncall", am.ViewAccess(aH.getOnCallShift)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/oncall_schedules", am.EditAccess(aH.createOnCallSchedule)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/oncall_schedules/{id}", am.EditAccess(aH.editOnCallSchedule)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/oncall_schedules/{id}", am.EditAccess(aH.deleteOnCallSchedule)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/api/v1/slos", am.ViewAccess(aH.listSLOs)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/slos/{id}", am.ViewAccess(aH.getSLO)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/slos/{id}/status", am.ViewAccess(aH.getSLOStatus)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/slos", am.EditAccess(aH.createSLO)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/slos/{id}", am.EditAccess(aH.editSLO)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/slos/{id}", am.EditAccess(aH.deleteSLO)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/api/v1/admin/dashboards/stats", am.AdminAccess(aH.getDashboardPanelStats)).Methods(http.MethodGet)
+
+	router.HandleFunc("/api/v1/annotations", am.ViewAccess(aH.getAnnotations)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/annotations", am.EditAccess(aH.createAnnotation)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/annotations/{id}", am.EditAccess(aH.deleteAnnotation)).Methods(http.MethodDelete)
 
 	router.HandleFunc("/api/v1/dashboards", am.ViewAccess(aH.getDashboards)).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/dashboards", am.EditAccess(aH.createDashboards)).Methods(http.MethodPost)
+	// static paths below /api/v1/dashboards must be registered before /api/v1/dashboards/{uuid}
+	router.HandleFunc("/api/v1/dashboards/folders", am.ViewAccess(aH.getDashboardFolders)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/folders", am.EditAccess(aH.createDashboardFolder)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/folders/{id}", am.ViewAccess(aH.getDashboardFolder)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/folders/{id}", am.EditAccess(aH.updateDashboardFolder)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/dashboards/folders/{id}", am.EditAccess(aH.deleteDashboardFolder)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/dashboards/trash", am.ViewAccess(aH.getDeletedDashboards)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/trash/settings", am.AdminAccess(aH.getDashboardTrashSettings)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/trash/settings", am.AdminAccess(aH.setDashboardTrashSettings)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/dashboards/trash/{uuid}/restore", am.EditAccess(aH.restoreDeletedDashboard)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/grafana", am.EditAccess(aH.importGrafanaDashboard)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/search", am.ViewAccess(aH.searchDashboards)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/home", am.ViewAccess(aH.getHomeDashboard)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/home/roles", am.AdminAccess(aH.getHomeDashboards)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/home/roles/{role}", am.AdminAccess(aH.setHomeDashboard)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/dashboards/home/roles/{role}", am.AdminAccess(aH.removeHomeDashboard)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/dashboards/by-slug/{slug}", am.ViewAccess(aH.getDashboardBySlug)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/bulk", am.EditAccess(aH.bulkUpdateDashboards)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/import", am.EditAccess(aH.importDashboards)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/lint", am.ViewAccess(aH.lintDashboard)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/metrics/rename", am.AdminAccess(aH.renameDashboardMetric)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/webhooks", am.AdminAccess(aH.getDashboardWebhooks)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/webhooks", am.AdminAccess(aH.createDashboardWebhook)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/webhooks/{id}", am.AdminAccess(aH.deleteDashboardWebhook)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/dashboards/snapshots/{id}", am.ViewAccess(aH.getDashboardSnapshot)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/snapshots/{id}", am.EditAccess(aH.deleteDashboardSnapshot)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/dashboards/reports/{id}", am.EditAccess(aH.updateReportSchedule)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/dashboards/reports/{id}", am.EditAccess(aH.deleteReportSchedule)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/dashboards/templates", am.ViewAccess(aH.getDashboardTemplates)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/templates/{id}", am.ViewAccess(aH.getDashboardTemplate)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/templates/{id}", am.EditAccess(aH.instantiateDashboardTemplate)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/templates/{id}/thumbnail", am.ViewAccess(aH.getDashboardTemplateThumbnail)).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/dashboards/{uuid}", am.ViewAccess(aH.getDashboard)).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/dashboards/{uuid}", am.EditAccess(aH.updateDashboard)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/dashboards/{uuid}", am.EditAccess(aH.patchDashboard)).Methods(http.MethodPatch)
 	router.HandleFunc("/api/v1/dashboards/{uuid}", am.EditAccess(aH.deleteDashboard)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/folder", am.EditAccess(aH.moveDashboard)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/archive", am.EditAccess(aH.archiveDashboard)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/archive", am.EditAccess(aH.unarchiveDashboard)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/duplicate", am.EditAccess(aH.duplicateDashboard)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/export", am.ViewAccess(aH.exportDashboard)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/thumbnail", am.ViewAccess(aH.getDashboardThumbnail)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/restore", am.EditAccess(aH.restoreDashboardBackup)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/shares", am.EditAccess(aH.getDashboardShares)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/shares", am.EditAccess(aH.createDashboardShare)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/shares/{id}", am.EditAccess(aH.revokeDashboardShare)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/snapshot", am.EditAccess(aH.createDashboardSnapshot)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/snapshots", am.ViewAccess(aH.getDashboardSnapshots)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/reports", am.ViewAccess(aH.getReportSchedules)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/reports", am.EditAccess(aH.createReportSchedule)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/audit", am.ViewAccess(aH.getDashboardAudit)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/versions", am.ViewAccess(aH.getDashboardVersions)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/references", am.ViewAccess(aH.getDashboardReferences)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/services", am.ViewAccess(aH.getDashboardServices)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/services", am.EditAccess(aH.addDashboardService)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/services/{name}", am.EditAccess(aH.removeDashboardService)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/widgets/{widgetId}/links", am.ViewAccess(aH.resolveWidgetLinks)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/widgets/{widgetId}/thresholds", am.ViewAccess(aH.getWidgetThresholds)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/widgets/{widgetId}/thresholds", am.EditAccess(aH.setWidgetThresholds)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/widgets/{widgetId}/query", am.ViewAccess(aH.queryDashboardWidget)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/widgets/{widgetId}/alerts", am.ViewAccess(aH.getWidgetAlerts)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/widgets/{widgetId}/alerts", am.EditAccess(aH.createWidgetAlert)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/variables/evaluate", am.ViewAccess(aH.evaluateDashboardVariables)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/favorite", am.ViewAccess(aH.starDashboard)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/dashboards/{uuid}/favorite", am.ViewAccess(aH.unstarDashboard)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/public/dashboards/{token}", am.OpenAccess(aH.getPublicDashboard)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/public/dashboards/{token}/widgets/{widgetId}", am.OpenAccess(aH.queryPublicDashboardWidget)).Methods(http.MethodGet)
 	router.HandleFunc("/api/v2/variables/query", am.ViewAccess(aH.queryDashboardVarsV2)).Methods(http.MethodPost)
 
 	router.HandleFunc("/api/v1/explorer/views", am.ViewAccess(aH.getSavedViews)).Methods(http.MethodGet)
@@ -529,6 +669,14 @@ func (aH *APIHandler) RegisterRoutes(router *mux.Router, am *AuthMiddleware) {
 	router.HandleFunc("/api/v1/explorer/views/{viewId}", am.ViewAccess(aH.getSavedView)).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/explorer/views/{viewId}", am.EditAccess(aH.updateSavedView)).Methods(http.MethodPut)
 	router.HandleFunc("/api/v1/explorer/views/{viewId}", am.EditAccess(aH.deleteSavedView)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/v1/explorer/views/{viewId}/usage", am.ViewAccess(aH.getSavedViewUsage)).Methods(http.MethodGet)
+
+	router.HandleFunc("/api/v1/macros", am.ViewAccess(aH.listQueryMacros)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/macros", am.AdminAccess(aH.createQueryMacro)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/macros/preview", am.ViewAccess(aH.previewQueryMacros)).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/macros/{id}", am.ViewAccess(aH.getQueryMacro)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/macros/{id}", am.AdminAccess(aH.updateQueryMacro)).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/macros/{id}", am.AdminAccess(aH.deleteQueryMacro)).Methods(http.MethodDelete)
 
 	router.HandleFunc("/api/v1/feedback", am.OpenAccess(aH.submitFeedback)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/event", am.ViewAccess(aH.registerEvent)).Methods(http.MethodPost)
@@ -536,6 +684,7 @@ func (aH *APIHandler) RegisterRoutes(router *mux.Router, am *AuthMiddleware) {
 	// router.HandleFunc("/api/v1/get_percentiles", aH.getApplicationPercentiles).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/services", am.ViewAccess(aH.getServices)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/services/list", am.ViewAccess(aH.getServicesList)).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/services/{name}/dashboards", am.ViewAccess(aH.getServiceDashboards)).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/service/top_operations", am.ViewAccess(aH.getTopOperations)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/service/top_level_operations", am.ViewAccess(aH.getServicesTopLevelOps)).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/traces/{traceId}", am.ViewAccess(aH.SearchTraces)).Methods(http.MethodGet)
@@ -633,29 +782,93 @@ func (ah *APIHandler) MetricExplorerRoutes(router *mux.Router, am *AuthMiddlewar
 		Methods(http.MethodPost)
 }
 
-func Intersection(a, b []int) (c []int) {
-	m := make(map[int]bool)
+func (aH *APIHandler) getRule(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	ruleResponse, err := aH.ruleManager.GetRule(r.Context(), id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, ruleResponse)
+}
 
-	for _, item := range a {
-		m[item] = true
+// getRuleVersions returns the saved versions of the rule, newest first
+func (aH *APIHandler) getRuleVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := aH.ruleManager.RuleDB().GetRuleVersions(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
 	}
+	aH.Respond(w, versions)
+}
 
-	for _, item := range b {
-		if _, ok := m[item]; ok {
-			c = append(c, item)
-		}
+// getRuleVersion returns a saved version of the rule
+func (aH *APIHandler) getRuleVersion(w http.ResponseWriter, r *http.Request) {
+	ruleVersion, apiErr := aH.ruleVersion(r, mux.Vars(r)["version"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
 	}
-	return
+	aH.Respond(w, ruleVersion)
 }
 
-func (aH *APIHandler) getRule(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
-	ruleResponse, err := aH.ruleManager.GetRule(r.Context(), id)
+// diffRuleVersions returns the fields of the rule changed from the version
+// in the from param to the one in the to param
+func (aH *APIHandler) diffRuleVersions(w http.ResponseWriter, r *http.Request) {
+	from, apiErr := aH.ruleVersion(r, r.URL.Query().Get("from"))
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	to, apiErr := aH.ruleVersion(r, r.URL.Query().Get("to"))
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	changes, err := rules.DiffRuleVersions(from, to)
 	if err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-	aH.Respond(w, ruleResponse)
+	aH.Respond(w, changes)
+}
+
+// restoreRuleVersion edits the rule back to a saved version of it
+func (aH *APIHandler) restoreRuleVersion(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(mux.Vars(r)["version"])
+	if err != nil {
+		RespondError(w, model.BadRequest(fmt.Errorf("invalid version %q", mux.Vars(r)["version"])), nil)
+		return
+	}
+
+	rule, err := aH.ruleManager.RestoreRuleVersion(r.Context(), mux.Vars(r)["id"], version)
+	if errors.Is(err, sql.ErrNoRows) {
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no version %d found for rule %s", version, mux.Vars(r)["id"])}, nil)
+		return
+	}
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, rule)
+}
+
+func (aH *APIHandler) ruleVersion(r *http.Request, versionStr string) (*rules.RuleVersion, *model.ApiError) {
+	version, err := strconv.Atoi(versionStr)
+	if err != nil {
+		return nil, model.BadRequest(fmt.Errorf("invalid version %q", versionStr))
+	}
+
+	id := mux.Vars(r)["id"]
+	ruleVersion, err := aH.ruleManager.RuleDB().GetRuleVersion(r.Context(), id, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no version %d found for rule %s", version, id)}
+	}
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	return ruleVersion, nil
 }
 
 // populateTemporality adds the temporality to the query if it is not present
@@ -759,6 +972,18 @@ func (aH *APIHandler) getDowntimeSchedule(w http.ResponseWriter, r *http.Request
 	aH.Respond(w, schedule)
 }
 
+// getDowntimeScheduleSuppressions returns the counts of the alerts and the
+// evaluations the schedule suppressed, per rule
+func (aH *APIHandler) getDowntimeScheduleSuppressions(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	suppressions, err := aH.ruleManager.RuleDB().GetMaintenanceSuppressions(r.Context(), id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, suppressions)
+}
+
 func (aH *APIHandler) createDowntimeSchedule(w http.ResponseWriter, r *http.Request) {
 	var schedule rules.PlannedMaintenance
 	err := json.NewDecoder(r.Body).Decode(&schedule)
@@ -809,204 +1034,726 @@ func (aH *APIHandler) deleteDowntimeSchedule(w http.ResponseWriter, r *http.Requ
 	aH.Respond(w, nil)
 }
 
-func (aH *APIHandler) getRuleStats(w http.ResponseWriter, r *http.Request) {
-	ruleID := mux.Vars(r)["id"]
-	params := model.QueryRuleStateHistory{}
-	err := json.NewDecoder(r.Body).Decode(&params)
+func (aH *APIHandler) listEscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := aH.ruleManager.RuleDB().GetAllEscalationPolicies(r.Context())
 	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
+	aH.Respond(w, policies)
+}
 
-	totalCurrentTriggers, err := aH.reader.GetTotalTriggers(r.Context(), ruleID, &params)
+func (aH *APIHandler) getEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	policy, err := aH.ruleManager.RuleDB().GetEscalationPolicyByID(r.Context(), id)
 	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: err}, nil)
 		return
 	}
-	currentTriggersSeries, err := aH.reader.GetTriggersByInterval(r.Context(), ruleID, &params)
-	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+	aH.Respond(w, policy)
+}
+
+func (aH *APIHandler) createEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	var policy rules.EscalationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := policy.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
 
-	currentAvgResolutionTime, err := aH.reader.GetAvgResolutionTime(r.Context(), ruleID, &params)
+	id, err := aH.ruleManager.RuleDB().CreateEscalationPolicy(r.Context(), policy)
 	if err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-	currentAvgResolutionTimeSeries, err := aH.reader.GetAvgResolutionTimeByInterval(r.Context(), ruleID, &params)
-	if err != nil {
+	aH.Respond(w, map[string]int64{"id": id})
+}
+
+func (aH *APIHandler) editEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var policy rules.EscalationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := policy.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	if err := aH.ruleManager.RuleDB().EditEscalationPolicy(r.Context(), policy, id); err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
+	aH.Respond(w, nil)
+}
 
-	if params.End-params.Start >= 86400000 {
-		days := int64(math.Ceil(float64(params.End-params.Start) / 86400000))
-		params.Start -= days * 86400000
-		params.End -= days * 86400000
-	} else {
-		params.Start -= 86400000
-		params.End -= 86400000
+func (aH *APIHandler) deleteEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := aH.ruleManager.DeleteEscalationPolicy(r.Context(), id); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
 	}
+	aH.Respond(w, nil)
+}
 
-	totalPastTriggers, err := aH.reader.GetTotalTriggers(r.Context(), ruleID, &params)
+func (aH *APIHandler) listTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := aH.ruleManager.RuleDB().GetAllTeams(r.Context())
 	if err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-	pastTriggersSeries, err := aH.reader.GetTriggersByInterval(r.Context(), ruleID, &params)
+	aH.Respond(w, teams)
+}
+
+func (aH *APIHandler) getTeam(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	team, err := aH.ruleManager.RuleDB().GetTeamByID(r.Context(), id)
 	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: err}, nil)
 		return
 	}
+	aH.Respond(w, team)
+}
 
-	pastAvgResolutionTime, err := aH.reader.GetAvgResolutionTime(r.Context(), ruleID, &params)
-	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+func (aH *APIHandler) createTeam(w http.ResponseWriter, r *http.Request) {
+	var team rules.Team
+	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
-	pastAvgResolutionTimeSeries, err := aH.reader.GetAvgResolutionTimeByInterval(r.Context(), ruleID, &params)
+	if err := team.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	id, err := aH.ruleManager.RuleDB().CreateTeam(r.Context(), team)
 	if err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-	if math.IsNaN(currentAvgResolutionTime) || math.IsInf(currentAvgResolutionTime, 0) {
-		currentAvgResolutionTime = 0
+	aH.Respond(w, map[string]int64{"id": id})
+}
+
+func (aH *APIHandler) editTeam(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var team rules.Team
+	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
 	}
-	if math.IsNaN(pastAvgResolutionTime) || math.IsInf(pastAvgResolutionTime, 0) {
-		pastAvgResolutionTime = 0
+	if err := team.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
 	}
 
-	stats := model.Stats{
-		TotalCurrentTriggers:           totalCurrentTriggers,
-		TotalPastTriggers:              totalPastTriggers,
-		CurrentTriggersSeries:          currentTriggersSeries,
-		PastTriggersSeries:             pastTriggersSeries,
-		CurrentAvgResolutionTime:       strconv.FormatFloat(currentAvgResolutionTime, 'f', -1, 64),
-		PastAvgResolutionTime:          strconv.FormatFloat(pastAvgResolutionTime, 'f', -1, 64),
-		CurrentAvgResolutionTimeSeries: currentAvgResolutionTimeSeries,
-		PastAvgResolutionTimeSeries:    pastAvgResolutionTimeSeries,
+	if err := aH.ruleManager.RuleDB().EditTeam(r.Context(), team, id); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
 	}
-
-	aH.Respond(w, stats)
+	aH.Respond(w, nil)
 }
 
-func (aH *APIHandler) getOverallStateTransitions(w http.ResponseWriter, r *http.Request) {
-	ruleID := mux.Vars(r)["id"]
-	params := model.QueryRuleStateHistory{}
-	err := json.NewDecoder(r.Body).Decode(&params)
-	if err != nil {
+func (aH *APIHandler) deleteTeam(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := aH.ruleManager.DeleteTeam(r.Context(), id); err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
+	aH.Respond(w, nil)
+}
 
-	stateItems, err := aH.reader.GetOverallStateTransitions(r.Context(), ruleID, &params)
+func (aH *APIHandler) listAlertDigests(w http.ResponseWriter, r *http.Request) {
+	digests, err := aH.ruleManager.RuleDB().GetAllAlertDigests(r.Context())
 	if err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-
-	aH.Respond(w, stateItems)
+	aH.Respond(w, digests)
 }
 
-func (aH *APIHandler) metaForLinks(ctx context.Context, rule *rules.GettableRule) ([]v3.FilterItem, []v3.AttributeKey, map[string]v3.AttributeKey) {
-	filterItems := []v3.FilterItem{}
-	groupBy := []v3.AttributeKey{}
-	keys := make(map[string]v3.AttributeKey)
-
-	if rule.AlertType == rules.AlertTypeLogs {
-		logFields, err := aH.reader.GetLogFields(ctx)
-		if err == nil {
-			params := &v3.QueryRangeParamsV3{
-				CompositeQuery: rule.RuleCondition.CompositeQuery,
-			}
-			keys = model.GetLogFieldsV3(ctx, params, logFields)
-		} else {
-			zap.L().Error("failed to get log fields using empty keys; the link might not work as expected", zap.Error(err))
-		}
-	} else if rule.AlertType == rules.AlertTypeTraces {
-		traceFields, err := aH.reader.GetSpanAttributeKeys(ctx)
-		if err == nil {
-			keys = traceFields
-		} else {
-			zap.L().Error("failed to get span attributes using empty keys; the link might not work as expected", zap.Error(err))
-		}
+func (aH *APIHandler) getAlertDigest(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	digest, err := aH.ruleManager.RuleDB().GetAlertDigestByID(r.Context(), id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: err}, nil)
+		return
 	}
+	aH.Respond(w, digest)
+}
 
-	if rule.AlertType == rules.AlertTypeLogs || rule.AlertType == rules.AlertTypeTraces {
-		if rule.RuleCondition.CompositeQuery != nil {
-			if rule.RuleCondition.QueryType() == v3.QueryTypeBuilder {
-				selectedQuery := rule.RuleCondition.GetSelectedQueryName()
-				if rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery] != nil &&
-					rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery].Filters != nil {
-					filterItems = rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery].Filters.Items
-				}
-				if rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery] != nil &&
-					rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery].GroupBy != nil {
-					groupBy = rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery].GroupBy
-				}
-			}
-		}
+// previewAlertDigest returns the digest as it would be sent now
+func (aH *APIHandler) previewAlertDigest(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	digest, err := aH.ruleManager.RuleDB().GetAlertDigestByID(r.Context(), id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: err}, nil)
+		return
 	}
-	return filterItems, groupBy, keys
+	summary, err := aH.ruleManager.BuildDigest(r.Context(), digest, time.Now())
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, summary)
 }
 
-func (aH *APIHandler) getRuleStateHistory(w http.ResponseWriter, r *http.Request) {
-	ruleID := mux.Vars(r)["id"]
-	params := model.QueryRuleStateHistory{}
-	err := json.NewDecoder(r.Body).Decode(&params)
-	if err != nil {
+func (aH *APIHandler) createAlertDigest(w http.ResponseWriter, r *http.Request) {
+	var digest rules.AlertDigest
+	if err := json.NewDecoder(r.Body).Decode(&digest); err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
-	if err := params.Validate(); err != nil {
+	if _, err := digest.Validate(); err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
 
-	res, err := aH.reader.ReadRuleStateHistoryByRuleID(r.Context(), ruleID, &params)
+	id, err := aH.ruleManager.CreateAlertDigest(r.Context(), digest)
 	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		RespondError(w, ruleApiError(err, model.ErrorBadData), nil)
 		return
 	}
+	aH.Respond(w, map[string]int64{"id": id})
+}
 
-	rule, err := aH.ruleManager.GetRule(r.Context(), ruleID)
-	if err == nil {
-		for idx := range res.Items {
-			lbls := make(map[string]string)
-			err := json.Unmarshal([]byte(res.Items[idx].Labels), &lbls)
-			if err != nil {
-				continue
-			}
-			filterItems, groupBy, keys := aH.metaForLinks(r.Context(), rule)
-			newFilters := contextlinks.PrepareFilters(lbls, filterItems, groupBy, keys)
-			end := time.Unix(res.Items[idx].UnixMilli/1000, 0)
-			// why are we subtracting 3 minutes?
-			// the query range is calculated based on the rule's evalWindow and evalDelay
-			// alerts have 2 minutes delay built in, so we need to subtract that from the start time
-			// to get the correct query range
-			start := end.Add(-time.Duration(rule.EvalWindow)).Add(-3 * time.Minute)
-			if rule.AlertType == rules.AlertTypeLogs {
-				res.Items[idx].RelatedLogsLink = contextlinks.PrepareLinksToLogs(start, end, newFilters)
-			} else if rule.AlertType == rules.AlertTypeTraces {
-				res.Items[idx].RelatedTracesLink = contextlinks.PrepareLinksToTraces(start, end, newFilters)
-			}
-		}
+func (aH *APIHandler) editAlertDigest(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var digest rules.AlertDigest
+	if err := json.NewDecoder(r.Body).Decode(&digest); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if _, err := digest.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
 	}
 
-	aH.Respond(w, res)
+	if err := aH.ruleManager.EditAlertDigest(r.Context(), digest, id); err != nil {
+		RespondError(w, ruleApiError(err, model.ErrorBadData), nil)
+		return
+	}
+	aH.Respond(w, nil)
 }
 
-func (aH *APIHandler) getRuleStateHistoryTopContributors(w http.ResponseWriter, r *http.Request) {
-	ruleID := mux.Vars(r)["id"]
-	params := model.QueryRuleStateHistory{}
-	err := json.NewDecoder(r.Body).Decode(&params)
-	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+func (aH *APIHandler) deleteAlertDigest(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := aH.ruleManager.DeleteAlertDigest(r.Context(), id); err != nil {
+		RespondError(w, ruleApiError(err, model.ErrorBadData), nil)
 		return
 	}
+	aH.Respond(w, nil)
+}
 
-	res, err := aH.reader.ReadRuleStateHistoryTopContributorsByRuleID(r.Context(), ruleID, &params)
+func (aH *APIHandler) listOnCallSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := aH.ruleManager.RuleDB().GetAllOnCallSchedules(r.Context())
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, schedules)
+}
+
+func (aH *APIHandler) getOnCallSchedule(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	schedule, err := aH.ruleManager.RuleDB().GetOnCallScheduleByID(r.Context(), id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, schedule)
+}
+
+// getOnCallShift returns the shift on call in the schedule now, or at the
+// RFC 3339 time of the time parameter
+func (aH *APIHandler) getOnCallShift(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	ts := time.Now()
+	if param := r.URL.Query().Get("time"); param != "" {
+		parsed, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+			return
+		}
+		ts = parsed
+	}
+
+	schedule, err := aH.ruleManager.RuleDB().GetOnCallScheduleByID(r.Context(), id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: err}, nil)
+		return
+	}
+	shift, ok := schedule.OnCallAt(ts)
+	if !ok {
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("no one is on call at %s", ts.Format(time.RFC3339))}, nil)
+		return
+	}
+	aH.Respond(w, shift)
+}
+
+func (aH *APIHandler) createOnCallSchedule(w http.ResponseWriter, r *http.Request) {
+	var schedule rules.OnCallSchedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := schedule.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	id, err := aH.ruleManager.RuleDB().CreateOnCallSchedule(r.Context(), schedule)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, map[string]int64{"id": id})
+}
+
+func (aH *APIHandler) editOnCallSchedule(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var schedule rules.OnCallSchedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := schedule.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	if err := aH.ruleManager.RuleDB().EditOnCallSchedule(r.Context(), schedule, id); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, nil)
+}
+
+func (aH *APIHandler) deleteOnCallSchedule(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := aH.ruleManager.RuleDB().DeleteOnCallSchedule(r.Context(), id); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, nil)
+}
+
+func (aH *APIHandler) listSLOs(w http.ResponseWriter, r *http.Request) {
+	slos, err := aH.ruleManager.RuleDB().GetAllSLOs(r.Context())
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, slos)
+}
+
+func (aH *APIHandler) getSLO(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slo, err := aH.ruleManager.RuleDB().GetSLOByID(r.Context(), id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, slo)
+}
+
+// getSLOStatus returns the SLI and the error budget remaining of the SLO over
+// its window
+func (aH *APIHandler) getSLOStatus(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slo, err := aH.ruleManager.RuleDB().GetSLOByID(r.Context(), id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorNotFound, Err: err}, nil)
+		return
+	}
+
+	queryRangeParams, err := slo.StatusQueryRangeParams(time.Now())
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	if err := aH.PopulateTemporality(r.Context(), queryRangeParams); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+
+	results, errQueriesByName, err := aH.querierV2.QueryRange(r.Context(), queryRangeParams)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, errQueriesByName)
+		return
+	}
+	aH.Respond(w, rules.NewSLOStatus(slo, queryRangeParams, results))
+}
+
+func (aH *APIHandler) createSLO(w http.ResponseWriter, r *http.Request) {
+	var slo rules.SLO
+	if err := json.NewDecoder(r.Body).Decode(&slo); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := slo.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	id, err := aH.ruleManager.CreateSLO(r.Context(), slo)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, map[string]int64{"id": id})
+}
+
+func (aH *APIHandler) editSLO(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var slo rules.SLO
+	if err := json.NewDecoder(r.Body).Decode(&slo); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := slo.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	if err := aH.ruleManager.EditSLO(r.Context(), slo, id); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, nil)
+}
+
+func (aH *APIHandler) deleteSLO(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := aH.ruleManager.DeleteSLO(r.Context(), id); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, nil)
+}
+
+// acknowledgeRuleAlerts stops the escalation and the notifications of the
+// firing alerts of the rule with the labels in the body, or of all of them
+// for an empty body, with the note in the body
+func (aH *APIHandler) acknowledgeRuleAlerts(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	params := struct {
+		Labels map[string]string `json:"labels"`
+		Note   string            `json:"note"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && err != io.EOF {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	acknowledged, err := aH.ruleManager.AcknowledgeAlerts(r.Context(), id, params.Labels, params.Note)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, acknowledged)
+}
+
+// unacknowledgeRuleAlerts takes back the acknowledgement of the acknowledged
+// firing alerts of the rule with the labels in the body, or of all of them
+// for an empty body, with the note in the body
+func (aH *APIHandler) unacknowledgeRuleAlerts(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	params := struct {
+		Labels map[string]string `json:"labels"`
+		Note   string            `json:"note"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && err != io.EOF {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	unacknowledged, err := aH.ruleManager.UnacknowledgeAlerts(r.Context(), id, params.Labels, params.Note)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, unacknowledged)
+}
+
+// pauseRule pauses the rule for the reason in the body, until the RFC 3339
+// resume time when it is set
+func (aH *APIHandler) pauseRule(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	params := struct {
+		Reason   string     `json:"reason"`
+		ResumeAt *time.Time `json:"resumeAt"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	pause, err := aH.ruleManager.PauseRule(r.Context(), id, params.Reason, params.ResumeAt)
+	if err != nil {
+		RespondError(w, ruleApiError(err, model.ErrorBadData), nil)
+		return
+	}
+	aH.Respond(w, pause)
+}
+
+func (aH *APIHandler) resumeRule(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	pause, err := aH.ruleManager.ResumeRule(r.Context(), id)
+	if err != nil {
+		RespondError(w, ruleApiError(err, model.ErrorBadData), nil)
+		return
+	}
+	aH.Respond(w, pause)
+}
+
+// listRulePauses returns the pauses of the rule, newest first
+func (aH *APIHandler) listRulePauses(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	pauses, err := aH.ruleManager.RuleDB().GetRulePauses(r.Context(), id)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, pauses)
+}
+
+func (aH *APIHandler) getRuleStats(w http.ResponseWriter, r *http.Request) {
+	ruleID := mux.Vars(r)["id"]
+	params := model.QueryRuleStateHistory{}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	totalCurrentTriggers, err := aH.reader.GetTotalTriggers(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	currentTriggersSeries, err := aH.reader.GetTriggersByInterval(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+
+	currentAvgResolutionTime, err := aH.reader.GetAvgResolutionTime(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	currentAvgResolutionTimeSeries, err := aH.reader.GetAvgResolutionTimeByInterval(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+
+	if params.End-params.Start >= 86400000 {
+		days := int64(math.Ceil(float64(params.End-params.Start) / 86400000))
+		params.Start -= days * 86400000
+		params.End -= days * 86400000
+	} else {
+		params.Start -= 86400000
+		params.End -= 86400000
+	}
+
+	totalPastTriggers, err := aH.reader.GetTotalTriggers(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	pastTriggersSeries, err := aH.reader.GetTriggersByInterval(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+
+	pastAvgResolutionTime, err := aH.reader.GetAvgResolutionTime(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	pastAvgResolutionTimeSeries, err := aH.reader.GetAvgResolutionTimeByInterval(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	if math.IsNaN(currentAvgResolutionTime) || math.IsInf(currentAvgResolutionTime, 0) {
+		currentAvgResolutionTime = 0
+	}
+	if math.IsNaN(pastAvgResolutionTime) || math.IsInf(pastAvgResolutionTime, 0) {
+		pastAvgResolutionTime = 0
+	}
+
+	stats := model.Stats{
+		TotalCurrentTriggers:           totalCurrentTriggers,
+		TotalPastTriggers:              totalPastTriggers,
+		CurrentTriggersSeries:          currentTriggersSeries,
+		PastTriggersSeries:             pastTriggersSeries,
+		CurrentAvgResolutionTime:       strconv.FormatFloat(currentAvgResolutionTime, 'f', -1, 64),
+		PastAvgResolutionTime:          strconv.FormatFloat(pastAvgResolutionTime, 'f', -1, 64),
+		CurrentAvgResolutionTimeSeries: currentAvgResolutionTimeSeries,
+		PastAvgResolutionTimeSeries:    pastAvgResolutionTimeSeries,
+	}
+
+	aH.Respond(w, stats)
+}
+
+func (aH *APIHandler) getOverallStateTransitions(w http.ResponseWriter, r *http.Request) {
+	ruleID := mux.Vars(r)["id"]
+	params := model.QueryRuleStateHistory{}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	stateItems, err := aH.reader.GetOverallStateTransitions(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+
+	aH.Respond(w, stateItems)
+}
+
+func (aH *APIHandler) metaForLinks(ctx context.Context, rule *rules.GettableRule) ([]v3.FilterItem, []v3.AttributeKey, map[string]v3.AttributeKey) {
+	filterItems := []v3.FilterItem{}
+	groupBy := []v3.AttributeKey{}
+	keys := make(map[string]v3.AttributeKey)
+
+	if rule.AlertType == rules.AlertTypeLogs {
+		logFields, err := aH.reader.GetLogFields(ctx)
+		if err == nil {
+			params := &v3.QueryRangeParamsV3{
+				CompositeQuery: rule.RuleCondition.CompositeQuery,
+			}
+			keys = model.GetLogFieldsV3(ctx, params, logFields)
+		} else {
+			zap.L().Error("failed to get log fields using empty keys; the link might not work as expected", zap.Error(err))
+		}
+	} else if rule.AlertType == rules.AlertTypeTraces {
+		traceFields, err := aH.reader.GetSpanAttributeKeys(ctx)
+		if err == nil {
+			keys = traceFields
+		} else {
+			zap.L().Error("failed to get span attributes using empty keys; the link might not work as expected", zap.Error(err))
+		}
+	}
+
+	if rule.AlertType == rules.AlertTypeLogs || rule.AlertType == rules.AlertTypeTraces {
+		if rule.RuleCondition.CompositeQuery != nil {
+			if rule.RuleCondition.QueryType() == v3.QueryTypeBuilder {
+				selectedQuery := rule.RuleCondition.GetSelectedQueryName()
+				if rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery] != nil &&
+					rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery].Filters != nil {
+					filterItems = rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery].Filters.Items
+				}
+				if rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery] != nil &&
+					rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery].GroupBy != nil {
+					groupBy = rule.RuleCondition.CompositeQuery.BuilderQueries[selectedQuery].GroupBy
+				}
+			}
+		}
+	}
+	return filterItems, groupBy, keys
+}
+
+func (aH *APIHandler) getRuleStateHistory(w http.ResponseWriter, r *http.Request) {
+	ruleID := mux.Vars(r)["id"]
+	params := model.QueryRuleStateHistory{}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := params.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	res, err := aH.readRuleStateHistory(r.Context(), ruleID, &params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+
+	aH.Respond(w, res)
+}
+
+// listRuleStateHistory lists the state transitions of the alerts of the rule
+// in the time range of the query params
+func (aH *APIHandler) listRuleStateHistory(w http.ResponseWriter, r *http.Request) {
+	ruleID := mux.Vars(r)["id"]
+	params, err := parseRuleStateHistoryRequest(r)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	res, err := aH.readRuleStateHistory(r.Context(), ruleID, params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+
+	aH.Respond(w, res)
+}
+
+// readRuleStateHistory reads the state history of the rule with the links to
+// the logs and traces of each transition
+func (aH *APIHandler) readRuleStateHistory(ctx context.Context, ruleID string, params *model.QueryRuleStateHistory) (*model.RuleStateTimeline, error) {
+	res, err := aH.reader.ReadRuleStateHistoryByRuleID(ctx, ruleID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := aH.ruleManager.GetRule(ctx, ruleID)
+	if err == nil {
+		for idx := range res.Items {
+			lbls := make(map[string]string)
+			err := json.Unmarshal([]byte(res.Items[idx].Labels), &lbls)
+			if err != nil {
+				continue
+			}
+			filterItems, groupBy, keys := aH.metaForLinks(ctx, rule)
+			newFilters := contextlinks.PrepareFilters(lbls, filterItems, groupBy, keys)
+			end := time.Unix(res.Items[idx].UnixMilli/1000, 0)
+			// why are we subtracting 3 minutes?
+			// the query range is calculated based on the rule's evalWindow and evalDelay
+			// alerts have 2 minutes delay built in, so we need to subtract that from the start time
+			// to get the correct query range, the rules with a delay of their own are
+			// shifted back by it instead
+			evalDelay := 2 * time.Minute
+			if rule.EvalDelay > 0 {
+				evalDelay = time.Duration(rule.EvalDelay)
+			}
+			start := end.Add(-time.Duration(rule.EvalWindow)).Add(-evalDelay - time.Minute)
+			if rule.AlertType == rules.AlertTypeLogs {
+				res.Items[idx].RelatedLogsLink = contextlinks.PrepareLinksToLogs(start, end, newFilters)
+			} else if rule.AlertType == rules.AlertTypeTraces {
+				res.Items[idx].RelatedTracesLink = contextlinks.PrepareLinksToTraces(start, end, newFilters)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (aH *APIHandler) getRuleStateHistoryTopContributors(w http.ResponseWriter, r *http.Request) {
+	ruleID := mux.Vars(r)["id"]
+	params := model.QueryRuleStateHistory{}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	res, err := aH.reader.ReadRuleStateHistoryTopContributorsByRuleID(r.Context(), ruleID, &params)
 	if err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
@@ -1050,76 +1797,68 @@ func (aH *APIHandler) listRules(w http.ResponseWriter, r *http.Request) {
 
 func (aH *APIHandler) getDashboards(w http.ResponseWriter, r *http.Request) {
 
-	allDashboards, err := dashboards.GetDashboards(r.Context())
-	if err != nil {
-		RespondError(w, err, nil)
+	params, apiErr := parseListDashboardsParams(r)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
 		return
 	}
 
-	ic := aH.IntegrationsController
-	installedIntegrationDashboards, err := ic.GetDashboardsForInstalledIntegrations(r.Context())
-	if err != nil {
-		zap.L().Error("failed to get dashboards for installed integrations", zap.Error(err))
-	} else {
-		allDashboards = append(allDashboards, installedIntegrationDashboards...)
-	}
-
-	cloudIntegrationDashboards, err := aH.CloudIntegrationsController.AvailableDashboards(r.Context())
+	allDashboards, err := dashboards.ListDashboards(r.Context(), *params)
 	if err != nil {
-		zap.L().Error("failed to get cloud dashboards", zap.Error(err))
-	} else {
-		allDashboards = append(allDashboards, cloudIntegrationDashboards...)
-	}
-
-	tagsFromReq, ok := r.URL.Query()["tags"]
-	if !ok || len(tagsFromReq) == 0 || tagsFromReq[0] == "" {
-		aH.Respond(w, allDashboards)
+		RespondError(w, err, nil)
 		return
 	}
 
-	tags2Dash := make(map[string][]int)
-	for i := 0; i < len(allDashboards); i++ {
-		tags, ok := (allDashboards)[i].Data["tags"].([]interface{})
-		if !ok {
-			continue
-		}
-
-		tagsArray := make([]string, len(tags))
-		for i, v := range tags {
-			tagsArray[i] = v.(string)
+	// integration dashboards are not stored, they are listed on the first page
+	// only and can not be starred or archived
+	if params.Offset == 0 && !params.Favorites && !params.Archived {
+		ic := aH.IntegrationsController
+		installedIntegrationDashboards, err := ic.GetDashboardsForInstalledIntegrations(r.Context())
+		if err != nil {
+			zap.L().Error("failed to get dashboards for installed integrations", zap.Error(err))
+		} else {
+			allDashboards = append(allDashboards, dashboards.FilterDashboardsByTags(dashboards.FilterDashboardsByTitle(installedIntegrationDashboards, params.Title), params.Tags)...)
 		}
 
-		for _, tag := range tagsArray {
-			tags2Dash[tag] = append(tags2Dash[tag], i)
+		cloudIntegrationDashboards, err := aH.CloudIntegrationsController.AvailableDashboards(r.Context())
+		if err != nil {
+			zap.L().Error("failed to get cloud dashboards", zap.Error(err))
+		} else {
+			allDashboards = append(allDashboards, dashboards.FilterDashboardsByTags(dashboards.FilterDashboardsByTitle(cloudIntegrationDashboards, params.Title), params.Tags)...)
 		}
-
 	}
 
-	inter := make([]int, len(allDashboards))
-	for i := range inter {
-		inter[i] = i
-	}
-
-	for _, tag := range tagsFromReq {
-		inter = Intersection(inter, tags2Dash[tag])
+	if notModified(w, r, dashboards.ListETag(allDashboards)) {
+		return
 	}
-
-	filteredDashboards := []dashboards.Dashboard{}
-	for _, val := range inter {
-		dash := (allDashboards)[val]
-		filteredDashboards = append(filteredDashboards, dash)
+	list := make([]*dashboards.Dashboard, 0, len(allDashboards))
+	for i := range allDashboards {
+		list = append(list, &allDashboards[i])
 	}
-
-	aH.Respond(w, filteredDashboards)
-
+	resolveDashboardUsers(r.Context(), list...)
+	if params.Summary {
+		aH.Respond(w, dashboards.Summarize(allDashboards))
+		return
+	}
+	aH.Respond(w, allDashboards)
 }
 func (aH *APIHandler) deleteDashboard(w http.ResponseWriter, r *http.Request) {
 
 	uuid := mux.Vars(r)["uuid"]
-	err := dashboards.DeleteDashboard(r.Context(), uuid, aH.featureFlags)
 
+	// dashboards used by alert rules or reports are deleted with force=true
+	force := false
+	if value := r.URL.Query().Get("force"); value != "" {
+		var err error
+		if force, err = strconv.ParseBool(value); err != nil {
+			RespondError(w, model.BadRequest(fmt.Errorf("invalid force: %s", value)), nil)
+			return
+		}
+	}
+
+	err := dashboards.DeleteDashboard(r.Context(), uuid, force, aH.featureFlags)
 	if err != nil {
-		RespondError(w, err, nil)
+		RespondError(w, err, dashboardErrorData(err))
 		return
 	}
 
@@ -1140,19 +1879,8 @@ func prepareQuery(r *http.Request) (string, error) {
 		return "", fmt.Errorf("query is required")
 	}
 
-	notAllowedOps := []string{
-		"alter table",
-		"drop table",
-		"truncate table",
-		"drop database",
-		"drop view",
-		"drop function",
-	}
-
-	for _, op := range notAllowedOps {
-		if strings.Contains(strings.ToLower(query), op) {
-			return "", fmt.Errorf("operation %s is not allowed", op)
-		}
+	if err := checkDashboardVarsQuery(query); err != nil {
+		return "", err
 	}
 
 	vars := make(map[string]string)
@@ -1172,6 +1900,25 @@ func prepareQuery(r *http.Request) (string, error) {
 	return queryBuf.String(), nil
 }
 
+// checkDashboardVarsQuery rejects variable queries changing the schema
+func checkDashboardVarsQuery(query string) error {
+	notAllowedOps := []string{
+		"alter table",
+		"drop table",
+		"truncate table",
+		"drop database",
+		"drop view",
+		"drop function",
+	}
+
+	for _, op := range notAllowedOps {
+		if strings.Contains(strings.ToLower(query), op) {
+			return fmt.Errorf("operation %s is not allowed", op)
+		}
+	}
+	return nil
+}
+
 func (aH *APIHandler) queryDashboardVarsV2(w http.ResponseWriter, r *http.Request) {
 	query, err := prepareQuery(r)
 	if err != nil {
@@ -1203,12 +1950,30 @@ func (aH *APIHandler) updateDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dashboard, apiError := dashboards.UpdateDashboard(r.Context(), uuid, postData, aH.featureFlags)
+	// removing more than one panel has to be confirmed with force=true
+	force := false
+	if value := r.URL.Query().Get("force"); value != "" {
+		force, err = strconv.ParseBool(value)
+		if err != nil {
+			RespondError(w, model.BadRequest(fmt.Errorf("invalid force: %s", value)), nil)
+			return
+		}
+	}
+
+	expectedVersion, err := expectedDashboardVersion(r)
+	if err != nil {
+		RespondError(w, model.BadRequest(err), nil)
+		return
+	}
+
+	dashboard, apiError := dashboards.UpdateDashboard(r.Context(), uuid, postData, force, expectedVersion, aH.featureFlags)
 	if apiError != nil {
-		RespondError(w, apiError, nil)
+		RespondError(w, apiError, dashboardErrorData(apiError))
 		return
 	}
 
+	setDashboardETag(w, dashboard)
+	resolveDashboardUsers(r.Context(), dashboard)
 	aH.Respond(w, dashboard)
 
 }
@@ -1218,6 +1983,9 @@ func (aH *APIHandler) getDashboard(w http.ResponseWriter, r *http.Request) {
 	uuid := mux.Vars(r)["uuid"]
 
 	dashboard, apiError := dashboards.GetDashboard(r.Context(), uuid)
+	if apiError == nil {
+		dashboards.RecordDashboardView(r.Context(), uuid)
+	}
 
 	if apiError != nil {
 		if apiError.Type() != model.ErrorNotFound {
@@ -1247,6 +2015,10 @@ func (aH *APIHandler) getDashboard(w http.ResponseWriter, r *http.Request) {
 
 	}
 
+	if notModified(w, r, dashboard.ETag()) {
+		return
+	}
+	resolveDashboardUsers(r.Context(), dashboard)
 	aH.Respond(w, dashboard)
 
 }
@@ -1267,13 +2039,29 @@ func (aH *APIHandler) createDashboards(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dash, apiErr := dashboards.CreateDashboard(r.Context(), postData, aH.featureFlags)
+	// upsert=true replaces the dashboard with the uuid of the data if it
+	// exists, for tools provisioning dashboards through the api
+	upsert := false
+	if value := r.URL.Query().Get("upsert"); value != "" {
+		if upsert, err = strconv.ParseBool(value); err != nil {
+			RespondError(w, model.BadRequest(fmt.Errorf("invalid upsert: %s", value)), nil)
+			return
+		}
+	}
 
+	var dash *dashboards.Dashboard
+	var apiErr *model.ApiError
+	if upsert {
+		dash, _, apiErr = dashboards.UpsertDashboard(r.Context(), "", postData, aH.featureFlags)
+	} else {
+		dash, apiErr = dashboards.CreateDashboard(r.Context(), postData, aH.featureFlags)
+	}
 	if apiErr != nil {
-		RespondError(w, apiErr, nil)
+		RespondError(w, apiErr, dashboardErrorData(apiErr))
 		return
 	}
 
+	resolveDashboardUsers(r.Context(), dash)
 	aH.Respond(w, dash)
 
 }
@@ -1304,6 +2092,38 @@ func (aH *APIHandler) testRule(w http.ResponseWriter, r *http.Request) {
 	aH.Respond(w, response)
 }
 
+// backtestRule evaluates a candidate rule on the data of the lookback and
+// returns when it would have fired, nothing is notified
+func (aH *APIHandler) backtestRule(w http.ResponseWriter, r *http.Request) {
+
+	params := rules.BacktestParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	result, apiErr := aH.ruleManager.BacktestRule(ctx, params)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	aH.Respond(w, result)
+}
+
+// ruleApiError returns the error of the rule manager as an api error of the
+// type, the errors which are api errors, such as the rules of a team being
+// forbidden to the other users, keeping their own type
+func ruleApiError(err error, typ model.ErrorType) *model.ApiError {
+	var apiErr *model.ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return &model.ApiError{Typ: typ, Err: err}
+}
+
 func (aH *APIHandler) deleteRule(w http.ResponseWriter, r *http.Request) {
 
 	id := mux.Vars(r)["id"]
@@ -1311,7 +2131,7 @@ func (aH *APIHandler) deleteRule(w http.ResponseWriter, r *http.Request) {
 	err := aH.ruleManager.DeleteRule(r.Context(), id)
 
 	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		RespondError(w, ruleApiError(err, model.ErrorInternal), nil)
 		return
 	}
 
@@ -1333,119 +2153,381 @@ func (aH *APIHandler) patchRule(w http.ResponseWriter, r *http.Request) {
 
 	gettableRule, err := aH.ruleManager.PatchRule(r.Context(), string(body), id)
 
+	if err != nil {
+		RespondError(w, ruleApiError(err, model.ErrorInternal), nil)
+		return
+	}
+
+	aH.Respond(w, gettableRule)
+}
+
+func (aH *APIHandler) editRule(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		zap.L().Error("error in getting req body of edit rule API", zap.Error(err))
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	err = aH.ruleManager.EditRule(r.Context(), string(body), id)
+
+	if err != nil {
+		RespondError(w, ruleApiError(err, model.ErrorInternal), nil)
+		return
+	}
+
+	aH.Respond(w, "rule successfully edited")
+
+}
+
+func (aH *APIHandler) getChannel(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	channel, apiErrorObj := aH.ruleManager.RuleDB().GetChannel(id)
+	if apiErrorObj != nil {
+		RespondError(w, apiErrorObj, nil)
+		return
+	}
+	aH.Respond(w, channel)
+}
+
+func (aH *APIHandler) deleteChannel(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	apiErrorObj := aH.ruleManager.RuleDB().DeleteChannel(id)
+	if apiErrorObj != nil {
+		RespondError(w, apiErrorObj, nil)
+		return
+	}
+	aH.Respond(w, "notification channel successfully deleted")
+}
+
+func (aH *APIHandler) listChannels(w http.ResponseWriter, r *http.Request) {
+	channels, apiErrorObj := aH.ruleManager.RuleDB().GetChannels()
+	if apiErrorObj != nil {
+		RespondError(w, apiErrorObj, nil)
+		return
+	}
+	aH.Respond(w, channels)
+}
+
+// applyChannelTemplates sets the fields of the receiver left empty from the
+// templates of the channel types, as when the channel is sent to the
+// alertmanager
+func (aH *APIHandler) applyChannelTemplates(ctx context.Context, receiver *am.Receiver) *model.ApiError {
+	templates, err := aH.ruleManager.RuleDB().ChannelTemplates(ctx)
+	if err != nil {
+		return &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	if err := receiver.ApplyTemplates(templates); err != nil {
+		return &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+	return nil
+}
+
+// testChannels sends test alert to all registered channels
+func (aH *APIHandler) testChannel(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		zap.L().Error("Error in getting req body of testChannel API", zap.Error(err))
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	receiver := &am.Receiver{}
+	if err := json.Unmarshal(body, receiver); err != nil { // Parse []byte to go struct pointer
+		zap.L().Error("Error in parsing req body of testChannel API\n", zap.Error(err))
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := receiver.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if apiErr := aH.applyChannelTemplates(r.Context(), receiver); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	// send alert
+	apiErrorObj := aH.alertManager.TestReceiver(receiver)
+	if apiErrorObj != nil {
+		RespondError(w, apiErrorObj, nil)
+		return
+	}
+	aH.Respond(w, "test alert sent")
+}
+
+// previewChannel renders the notifications of the channel for the alerts, the
+// active alerts of a rule or a sample alert, without sending them
+func (aH *APIHandler) previewChannel(w http.ResponseWriter, r *http.Request) {
+	params := am.ChannelPreviewParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := params.Receiver.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	alerts := params.Alerts
+	if len(alerts) == 0 && params.RuleId != "" {
+		for _, alert := range aH.ruleManager.RuleActiveAlerts(params.RuleId) {
+			annotations := alert.Annotations.Map()
+			annotations[labels.AlertDedupKeyLabel] = alert.DedupKey()
+			alerts = append(alerts, &am.PreviewAlert{
+				Labels:       alert.Labels.Map(),
+				Annotations:  annotations,
+				StartsAt:     alert.FiredAt,
+				EndsAt:       alert.ResolvedAt,
+				GeneratorURL: alert.GeneratorURL,
+			})
+		}
+		if len(alerts) == 0 {
+			RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("rule %s has no active alerts", params.RuleId)}, nil)
+			return
+		}
+	}
+	if len(alerts) == 0 {
+		alerts = append(alerts, am.NewSampleAlert(&params.Receiver, time.Now()))
+	}
+	if apiErr := aH.applyChannelTemplates(r.Context(), &params.Receiver); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	previews, err := am.PreviewReceiver(&params.Receiver, alerts, aH.alertManager.URL())
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, previews)
+}
+
+func (aH *APIHandler) editChannel(w http.ResponseWriter, r *http.Request) {
+
+	id := mux.Vars(r)["id"]
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		zap.L().Error("Error in getting req body of editChannel API", zap.Error(err))
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	receiver := &am.Receiver{}
+	if err := json.Unmarshal(body, receiver); err != nil { // Parse []byte to go struct pointer
+		zap.L().Error("Error in parsing req body of editChannel API", zap.Error(err))
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := receiver.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	_, apiErrorObj := aH.ruleManager.RuleDB().EditChannel(receiver, id)
+
+	if apiErrorObj != nil {
+		RespondError(w, apiErrorObj, nil)
+		return
+	}
+
+	aH.Respond(w, nil)
+
+}
+
+func (aH *APIHandler) listChannelRateLimits(w http.ResponseWriter, r *http.Request) {
+	limits, err := aH.ruleManager.RuleDB().GetAllChannelRateLimits(r.Context())
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, limits)
+}
+
+// setChannelRateLimit sets the rate limit of the channel, replacing the one
+// it had
+func (aH *APIHandler) setChannelRateLimit(w http.ResponseWriter, r *http.Request) {
+	channel, apiErr := aH.ruleManager.RuleDB().GetChannel(mux.Vars(r)["id"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	var limit rules.ChannelRateLimit
+	if err := json.NewDecoder(r.Body).Decode(&limit); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	limit.Channel = channel.Name
+	if err := limit.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	if err := aH.ruleManager.RuleDB().SetChannelRateLimit(r.Context(), limit); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, nil)
+}
+
+func (aH *APIHandler) deleteChannelRateLimit(w http.ResponseWriter, r *http.Request) {
+	channel, apiErr := aH.ruleManager.RuleDB().GetChannel(mux.Vars(r)["id"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	if err := aH.ruleManager.RuleDB().DeleteChannelRateLimit(r.Context(), channel.Name); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, nil)
+}
+
+func (aH *APIHandler) listChannelResolvedNotifications(w http.ResponseWriter, r *http.Request) {
+	settings, err := aH.ruleManager.RuleDB().GetAllChannelResolvedNotifications(r.Context())
 	if err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-
-	aH.Respond(w, gettableRule)
+	aH.Respond(w, settings)
 }
 
-func (aH *APIHandler) editRule(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
+// setChannelResolvedNotification sets how the resolved alerts are notified to
+// the channel, replacing the settings it had
+func (aH *APIHandler) setChannelResolvedNotification(w http.ResponseWriter, r *http.Request) {
+	channel, apiErr := aH.ruleManager.RuleDB().GetChannel(mux.Vars(r)["id"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
 
-	defer r.Body.Close()
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		zap.L().Error("error in getting req body of edit rule API", zap.Error(err))
+	var settings rules.ChannelResolvedNotification
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	settings.Channel = channel.Name
+	if err := settings.Validate(); err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
 
-	err = aH.ruleManager.EditRule(r.Context(), string(body), id)
-
-	if err != nil {
+	if err := aH.ruleManager.RuleDB().SetChannelResolvedNotification(r.Context(), settings); err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-
-	aH.Respond(w, "rule successfully edited")
-
+	aH.Respond(w, nil)
 }
 
-func (aH *APIHandler) getChannel(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
-	channel, apiErrorObj := aH.ruleManager.RuleDB().GetChannel(id)
-	if apiErrorObj != nil {
-		RespondError(w, apiErrorObj, nil)
+func (aH *APIHandler) deleteChannelResolvedNotification(w http.ResponseWriter, r *http.Request) {
+	channel, apiErr := aH.ruleManager.RuleDB().GetChannel(mux.Vars(r)["id"])
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
 		return
 	}
-	aH.Respond(w, channel)
-}
 
-func (aH *APIHandler) deleteChannel(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
-	apiErrorObj := aH.ruleManager.RuleDB().DeleteChannel(id)
-	if apiErrorObj != nil {
-		RespondError(w, apiErrorObj, nil)
+	if err := aH.ruleManager.RuleDB().DeleteChannelResolvedNotification(r.Context(), channel.Name); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-	aH.Respond(w, "notification channel successfully deleted")
+	aH.Respond(w, nil)
 }
 
-func (aH *APIHandler) listChannels(w http.ResponseWriter, r *http.Request) {
-	channels, apiErrorObj := aH.ruleManager.RuleDB().GetChannels()
-	if apiErrorObj != nil {
-		RespondError(w, apiErrorObj, nil)
+func (aH *APIHandler) listChannelTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := aH.ruleManager.RuleDB().GetAllChannelTemplates(r.Context())
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-	aH.Respond(w, channels)
+	aH.Respond(w, templates)
 }
 
-// testChannels sends test alert to all registered channels
-func (aH *APIHandler) testChannel(w http.ResponseWriter, r *http.Request) {
-
-	defer r.Body.Close()
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		zap.L().Error("Error in getting req body of testChannel API", zap.Error(err))
+// setChannelTemplate sets the template of the notifications of the channel
+// type, replacing its default template
+func (aH *APIHandler) setChannelTemplate(w http.ResponseWriter, r *http.Request) {
+	var channelTemplate rules.ChannelTemplate
+	if err := json.NewDecoder(r.Body).Decode(&channelTemplate); err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
-
-	receiver := &am.Receiver{}
-	if err := json.Unmarshal(body, receiver); err != nil { // Parse []byte to go struct pointer
-		zap.L().Error("Error in parsing req body of testChannel API\n", zap.Error(err))
+	channelTemplate.Type = mux.Vars(r)["type"]
+	if err := channelTemplate.Validate(); err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
-	// send alert
-	apiErrorObj := aH.alertManager.TestReceiver(receiver)
-	if apiErrorObj != nil {
-		RespondError(w, apiErrorObj, nil)
+
+	if err := aH.ruleManager.RuleDB().SetChannelTemplate(r.Context(), channelTemplate); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
-	aH.Respond(w, "test alert sent")
+	aH.Respond(w, nil)
 }
 
-func (aH *APIHandler) editChannel(w http.ResponseWriter, r *http.Request) {
-
-	id := mux.Vars(r)["id"]
+// deleteChannelTemplate sets the channel type back to its default template
+func (aH *APIHandler) deleteChannelTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := aH.ruleManager.RuleDB().DeleteChannelTemplate(r.Context(), mux.Vars(r)["type"]); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, nil)
+}
 
-	defer r.Body.Close()
-	body, err := io.ReadAll(r.Body)
+// getNotificationRouting returns the routing tree of the notifications, null
+// when there is none
+func (aH *APIHandler) getNotificationRouting(w http.ResponseWriter, r *http.Request) {
+	routing, err := aH.ruleManager.RuleDB().GetNotificationRouting(r.Context())
 	if err != nil {
-		zap.L().Error("Error in getting req body of editChannel API", zap.Error(err))
-		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
 	}
+	aH.Respond(w, routing)
+}
 
-	receiver := &am.Receiver{}
-	if err := json.Unmarshal(body, receiver); err != nil { // Parse []byte to go struct pointer
-		zap.L().Error("Error in parsing req body of editChannel API", zap.Error(err))
+// setNotificationRouting replaces the routing tree of the notifications with
+// the root route in the body
+func (aH *APIHandler) setNotificationRouting(w http.ResponseWriter, r *http.Request) {
+	var route rules.NotificationRoute
+	if err := json.NewDecoder(r.Body).Decode(&route); err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
 
-	_, apiErrorObj := aH.ruleManager.RuleDB().EditChannel(receiver, id)
-
-	if apiErrorObj != nil {
-		RespondError(w, apiErrorObj, nil)
+	if err := aH.ruleManager.SetNotificationRouting(r.Context(), &route); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
-
 	aH.Respond(w, nil)
+}
+
+// routeNotification returns the channels the routing tree routes the alerts
+// with the labels in the body to, the alerts of the rules without channels
+func (aH *APIHandler) routeNotification(w http.ResponseWriter, r *http.Request) {
+	params := struct {
+		Labels map[string]string `json:"labels"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
 
+	routing, err := aH.ruleManager.RuleDB().GetNotificationRouting(r.Context())
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	channels := []string{}
+	if routing != nil && routing.Route != nil {
+		channels = append(channels, routing.Route.Route(params.Labels)...)
+	}
+	aH.Respond(w, map[string][]string{"channels": channels})
 }
 
 func (aH *APIHandler) createChannel(w http.ResponseWriter, r *http.Request) {
@@ -1464,6 +2546,10 @@ func (aH *APIHandler) createChannel(w http.ResponseWriter, r *http.Request) {
 		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
 		return
 	}
+	if err := receiver.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
 
 	_, apiErrorObj := aH.ruleManager.RuleDB().CreateChannel(receiver)
 
@@ -1507,7 +2593,7 @@ func (aH *APIHandler) createRule(w http.ResponseWriter, r *http.Request) {
 
 	rule, err := aH.ruleManager.CreateRule(r.Context(), string(body))
 	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		RespondError(w, ruleApiError(err, model.ErrorBadData), nil)
 		return
 	}
 
@@ -1515,6 +2601,67 @@ func (aH *APIHandler) createRule(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// exportRules writes the rules with the id params, or all of the rules, as a
+// bundle file in json or, with format=yaml, in yaml
+func (aH *APIHandler) exportRules(w http.ResponseWriter, r *http.Request) {
+
+	bundle, err := aH.ruleManager.ExportRules(r.Context(), r.URL.Query()["id"])
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	var data []byte
+	format := r.URL.Query().Get("format")
+	if format == "yaml" {
+		data, err = yaml.Marshal(bundle)
+		w.Header().Set("Content-Type", "application/yaml")
+	} else {
+		format = "json"
+		data, err = json.MarshalIndent(bundle, "", "  ")
+		w.Header().Set("Content-Type", "application/json")
+	}
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="alert-rules.%s"`, format))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// importRules creates the rules of a bundle, sent in yaml with a yaml content
+// type and in json otherwise
+func (aH *APIHandler) importRules(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		zap.L().Error("Error in getting req body for import rules API", zap.Error(err))
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	params := &rules.RuleBundleImport{}
+	if strings.Contains(r.Header.Get("Content-Type"), "yaml") {
+		err = yaml.Unmarshal(body, params)
+	} else {
+		err = json.Unmarshal(body, params)
+	}
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	imported, err := aH.ruleManager.ImportRules(r.Context(), params)
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, map[string]interface{}{"rules": imported})
+		return
+	}
+	aH.Respond(w, map[string]interface{}{"rules": imported, "dryRun": params.DryRun})
+}
+
 func (aH *APIHandler) queryRangeMetrics(w http.ResponseWriter, r *http.Request) {
 
 	query, apiErrorObj := parseQueryRangeRequest(r)
@@ -1612,6 +2759,7 @@ func (aH *APIHandler) queryMetrics(w http.ResponseWriter, r *http.Request) {
 			RespondError(w, &model.ApiError{Typ: model.ErrorTimeout, Err: res.Err}, nil)
 		}
 		RespondError(w, &model.ApiError{Typ: model.ErrorExec, Err: res.Err}, nil)
+		return
 	}
 
 	response_data := &model.QueryData{
@@ -1624,6 +2772,60 @@ func (aH *APIHandler) queryMetrics(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func (aH *APIHandler) promSeries(w http.ResponseWriter, r *http.Request) {
+	params, apiErr := parsePromMetadataRequest(r)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	if len(params.Matchers) == 0 {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("no match[] parameter provided")}, nil)
+		return
+	}
+
+	series, apiErr := aH.reader.GetPromSeries(r.Context(), params)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	aH.Respond(w, series)
+}
+
+func (aH *APIHandler) promLabelNames(w http.ResponseWriter, r *http.Request) {
+	params, apiErr := parsePromMetadataRequest(r)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	names, apiErr := aH.reader.GetPromLabelNames(r.Context(), params)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	aH.Respond(w, names)
+}
+
+func (aH *APIHandler) promLabelValues(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if !promModel.LabelName(name).IsValid() {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("invalid label name: %q", name)}, nil)
+		return
+	}
+	params, apiErr := parsePromMetadataRequest(r)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	values, apiErr := aH.reader.GetPromLabelValues(r.Context(), name, params)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	aH.Respond(w, values)
+}
+
 func (aH *APIHandler) submitFeedback(w http.ResponseWriter, r *http.Request) {
 
 	var postData map[string]interface{}
@@ -4660,12 +5862,17 @@ func (aH *APIHandler) CreateLogsPipeline(w http.ResponseWriter, r *http.Request)
 }
 
 func (aH *APIHandler) getSavedViews(w http.ResponseWriter, r *http.Request) {
-	// get sourcePage, name, and category from the query params
-	sourcePage := r.URL.Query().Get("sourcePage")
-	name := r.URL.Query().Get("name")
-	category := r.URL.Query().Get("category")
+	// get sourcePage, name, category, visibility, owner and tags from the query params
+	filters := explorer.ViewFilters{
+		SourcePage: r.URL.Query().Get("sourcePage"),
+		Name:       r.URL.Query().Get("name"),
+		Category:   r.URL.Query().Get("category"),
+		Visibility: r.URL.Query().Get("visibility"),
+		CreatedBy:  r.URL.Query().Get("createdBy"),
+		Tags:       r.URL.Query()["tag"],
+	}
 
-	queries, err := explorer.GetViewsForFilters(sourcePage, name, category)
+	queries, err := explorer.GetViewsForFilters(r.Context(), filters)
 	if err != nil {
 		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
 		return
@@ -4696,9 +5903,9 @@ func (aH *APIHandler) createSavedViews(w http.ResponseWriter, r *http.Request) {
 
 func (aH *APIHandler) getSavedView(w http.ResponseWriter, r *http.Request) {
 	viewID := mux.Vars(r)["viewId"]
-	view, err := explorer.GetView(viewID)
+	view, err := explorer.GetView(r.Context(), viewID)
 	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		RespondError(w, savedViewApiError(err), nil)
 		return
 	}
 
@@ -4719,9 +5926,17 @@ func (aH *APIHandler) updateSavedView(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the dashboards referencing the view can't run it once it is private
+	if view.Visibility == v3.SavedViewVisibilityPrivate {
+		if apiErr := aH.checkSavedViewUnused(r.Context(), viewID, "made private"); apiErr != nil {
+			RespondError(w, apiErr, nil)
+			return
+		}
+	}
+
 	err = explorer.UpdateView(r.Context(), viewID, view)
 	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		RespondError(w, savedViewApiError(err), nil)
 		return
 	}
 
@@ -4731,15 +5946,57 @@ func (aH *APIHandler) updateSavedView(w http.ResponseWriter, r *http.Request) {
 func (aH *APIHandler) deleteSavedView(w http.ResponseWriter, r *http.Request) {
 
 	viewID := mux.Vars(r)["viewId"]
-	err := explorer.DeleteView(viewID)
+	if apiErr := aH.checkSavedViewUnused(r.Context(), viewID, "deleted"); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	err := explorer.DeleteView(r.Context(), viewID)
 	if err != nil {
-		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		RespondError(w, savedViewApiError(err), nil)
 		return
 	}
 
 	aH.Respond(w, nil)
 }
 
+func (aH *APIHandler) getSavedViewUsage(w http.ResponseWriter, r *http.Request) {
+	viewID := mux.Vars(r)["viewId"]
+	if _, err := explorer.GetView(r.Context(), viewID); err != nil {
+		RespondError(w, savedViewApiError(err), nil)
+		return
+	}
+
+	usage, apiErr := dashboards.GetSavedViewUsage(r.Context(), viewID)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	aH.Respond(w, usage)
+}
+
+// checkSavedViewUnused returns a conflict if dashboards reference the saved
+// view, which can't be deleted or made private while they do
+func (aH *APIHandler) checkSavedViewUnused(ctx context.Context, viewID string, change string) *model.ApiError {
+	usage, apiErr := dashboards.GetSavedViewUsage(ctx, viewID)
+	if apiErr != nil {
+		return apiErr
+	}
+	if len(usage) > 0 {
+		return &model.ApiError{Typ: model.ErrorConflict, Err: fmt.Errorf("saved view is referenced by %d dashboards and can't be %s", len(usage), change)}
+	}
+	return nil
+}
+
+func savedViewApiError(err error) *model.ApiError {
+	switch {
+	case errors.Is(err, explorer.ErrViewNotFound):
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: err}
+	case errors.Is(err, explorer.ErrViewForbidden):
+		return &model.ApiError{Typ: model.ErrorForbidden, Err: err}
+	}
+	return &model.ApiError{Typ: model.ErrorInternal, Err: err}
+}
+
 func (aH *APIHandler) autocompleteAggregateAttributes(w http.ResponseWriter, r *http.Request) {
 	var response *v3.AggregateAttributeResponse
 	req, err := parseAggregateAttributeRequest(r)
@@ -4991,6 +6248,7 @@ func (aH *APIHandler) queryRangeV3(ctx context.Context, queryRangeParams *v3.Que
 
 	resp := v3.QueryRangeResponse{
 		Result: result,
+		Meta:   queryRangeMeta(queryRangeParams),
 	}
 
 	// This checks if the time for context to complete has exceeded.
@@ -5369,30 +6627,44 @@ func (aH *APIHandler) getMetricMetadata(w http.ResponseWriter, r *http.Request)
 }
 
 func (aH *APIHandler) queryRangeV4(ctx context.Context, queryRangeParams *v3.QueryRangeParamsV3, w http.ResponseWriter, r *http.Request) {
+	if wantsQueryRangeStream(r) {
+		aH.streamQueryRangeV4(ctx, queryRangeParams, w, r)
+		return
+	}
 
-	var result []*v3.Result
-	var err error
-	var errQuriesByName map[string]error
-	var spanKeys map[string]v3.AttributeKey
+	result, apiErr, errData := aH.execQueryRangeV4(ctx, queryRangeParams)
+	if apiErr != nil {
+		RespondError(w, apiErr, errData)
+		return
+	}
+	sendQueryResultEvents(r, result, queryRangeParams)
+	resp := v3.QueryRangeResponse{
+		Result: result,
+		Meta:   queryRangeMeta(queryRangeParams),
+	}
+
+	aH.Respond(w, resp)
+}
+
+// enrichQueryRangeV4 enriches the builder queries of the query range params
+// with the fields of the logs and the spans they use, and limits the time
+// range of the traces queries filtering by trace id
+func (aH *APIHandler) enrichQueryRangeV4(ctx context.Context, queryRangeParams *v3.QueryRangeParamsV3) *model.ApiError {
 	if queryRangeParams.CompositeQuery.QueryType == v3.QueryTypeBuilder {
 		// check if any enrichment is required for logs if yes then enrich them
 		if logsv3.EnrichmentRequired(queryRangeParams) {
 			// get the fields if any logs query is present
-			logsFields, err := aH.reader.GetLogFields(r.Context())
+			logsFields, err := aH.reader.GetLogFields(ctx)
 			if err != nil {
-				apiErrObj := &model.ApiError{Typ: model.ErrorInternal, Err: err}
-				RespondError(w, apiErrObj, nil)
-				return
+				return &model.ApiError{Typ: model.ErrorInternal, Err: err}
 			}
-			fields := model.GetLogFieldsV3(r.Context(), queryRangeParams, logsFields)
+			fields := model.GetLogFieldsV3(ctx, queryRangeParams, logsFields)
 			logsv3.Enrich(queryRangeParams, fields)
 		}
 
-		spanKeys, err = aH.getSpanKeysV3(ctx, queryRangeParams)
+		spanKeys, err := aH.getSpanKeysV3(ctx, queryRangeParams)
 		if err != nil {
-			apiErrObj := &model.ApiError{Typ: model.ErrorInternal, Err: err}
-			RespondError(w, apiErrObj, errQuriesByName)
-			return
+			return &model.ApiError{Typ: model.ErrorInternal, Err: err}
 		}
 		if aH.UseTraceNewSchema {
 			tracesV4.Enrich(queryRangeParams, spanKeys)
@@ -5417,6 +6689,20 @@ func (aH *APIHandler) queryRangeV4(ctx context.Context, queryRangeParams *v3.Que
 		}
 	}
 
+	return nil
+}
+
+// execQueryRangeV4 runs the prepared query range params and post processes the
+// results. On failure it returns the error and the per query error details.
+func (aH *APIHandler) execQueryRangeV4(ctx context.Context, queryRangeParams *v3.QueryRangeParamsV3) ([]*v3.Result, *model.ApiError, interface{}) {
+
+	var result []*v3.Result
+	var err error
+	var errQuriesByName map[string]error
+	if apiErr := aH.enrichQueryRangeV4(ctx, queryRangeParams); apiErr != nil {
+		return nil, apiErr, nil
+	}
+
 	result, errQuriesByName, err = aH.querierV2.QueryRange(ctx, queryRangeParams)
 
 	if err != nil {
@@ -5424,9 +6710,7 @@ func (aH *APIHandler) queryRangeV4(ctx context.Context, queryRangeParams *v3.Que
 		for name, err := range errQuriesByName {
 			queryErrors[fmt.Sprintf("Query-%s", name)] = err.Error()
 		}
-		apiErrObj := &model.ApiError{Typ: model.ErrorInternal, Err: err}
-		RespondError(w, apiErrObj, queryErrors)
-		return
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}, queryErrors
 	}
 
 	if queryRangeParams.CompositeQuery.QueryType == v3.QueryTypeBuilder {
@@ -5437,16 +6721,10 @@ func (aH *APIHandler) queryRangeV4(ctx context.Context, queryRangeParams *v3.Que
 	}
 
 	if err != nil {
-		apiErrObj := &model.ApiError{Typ: model.ErrorBadData, Err: err}
-		RespondError(w, apiErrObj, errQuriesByName)
-		return
-	}
-	sendQueryResultEvents(r, result, queryRangeParams)
-	resp := v3.QueryRangeResponse{
-		Result: result,
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}, errQuriesByName
 	}
 
-	aH.Respond(w, resp)
+	return result, nil, nil
 }
 
 func (aH *APIHandler) QueryRangeV4(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,189 @@
+// Package logql translates the LogQL queries of the Loki API to the logs
+// builder queries, so that the Loki clients can query the logs.
+package logql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+const (
+	RangeAggregationCountOverTime = "count_over_time"
+	RangeAggregationRate          = "rate"
+)
+
+// LabelMatcher matches the value of a label of the streams with =, !=, =~ or !~
+type LabelMatcher struct {
+	Name  string
+	Op    string
+	Value string
+}
+
+// LineFilter filters the log lines with |=, !=, |~ or !~
+type LineFilter struct {
+	Op    string
+	Value string
+}
+
+// LogSelector selects the log lines of the streams matching the label
+// matchers and the line filters
+type LogSelector struct {
+	Matchers    []LabelMatcher
+	LineFilters []LineFilter
+}
+
+// Expr is a LogQL query. The metric queries aggregate the selected log lines
+// over the range, the log queries return them.
+type Expr struct {
+	Selector         LogSelector
+	RangeAggregation string
+	Range            time.Duration
+	// Sum tells whether the range aggregation is summed by the labels of the
+	// grouping
+	Sum      bool
+	Grouping []string
+}
+
+func (e *Expr) IsMetric() bool {
+	return e.RangeAggregation != ""
+}
+
+// LabelName returns the label of the streams of the resource attribute. The
+// label names can't have dots, the underscores stand for them as in the Loki
+// exporter, e.g. service.name is service_name.
+func LabelName(attribute string) string {
+	return strings.ReplaceAll(attribute, ".", "_")
+}
+
+// Labels resolves the labels of the streams to the resource attributes
+type Labels map[string]string
+
+// NewLabels returns the labels of the known resource attributes. A label
+// naming an attribute as it is resolves to it before the attributes it
+// stands for, e.g. service_name is the service_name attribute when there is
+// one, and service.name otherwise.
+func NewLabels(attributes []string) Labels {
+	labels := Labels{}
+	for _, attribute := range attributes {
+		labels[LabelName(attribute)] = attribute
+	}
+	for _, attribute := range attributes {
+		labels[attribute] = attribute
+	}
+	return labels
+}
+
+// Attribute returns the resource attribute of the label, the unknown labels
+// are the attributes of the same name
+func (l Labels) Attribute(label string) string {
+	if attribute, ok := l[label]; ok {
+		return attribute
+	}
+	return label
+}
+
+var matcherOperators = map[string]v3.FilterOperator{
+	"=":  v3.FilterOperatorEqual,
+	"!=": v3.FilterOperatorNotEqual,
+	"=~": v3.FilterOperatorRegex,
+	"!~": v3.FilterOperatorNotRegex,
+}
+
+var lineFilterOperators = map[string]v3.FilterOperator{
+	"|=": v3.FilterOperatorLike,
+	"!=": v3.FilterOperatorNotLike,
+	"|~": v3.FilterOperatorRegex,
+	"!~": v3.FilterOperatorNotRegex,
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// BuilderQuery returns the logs builder query of the expression. The range
+// of the range aggregation is the step interval of the query, and the log
+// queries return the limit latest lines, or the oldest ones when forward.
+// The labels resolve the label names to the resource attributes.
+func (e *Expr) BuilderQuery(labels Labels, limit uint64, forward bool) (*v3.BuilderQuery, error) {
+	query := &v3.BuilderQuery{
+		QueryName:  "A",
+		Expression: "A",
+		DataSource: v3.DataSourceLogs,
+		Filters:    &v3.FilterSet{Operator: "AND", Items: []v3.FilterItem{}},
+	}
+
+	streamLabels := []v3.AttributeKey{}
+	for _, matcher := range e.Selector.Matchers {
+		operator, ok := matcherOperators[matcher.Op]
+		if !ok {
+			return nil, fmt.Errorf("unsupported label matcher %s", matcher.Op)
+		}
+		key := v3.AttributeKey{
+			Key:      labels.Attribute(matcher.Name),
+			DataType: v3.AttributeKeyDataTypeString,
+			Type:     v3.AttributeKeyTypeResource,
+		}
+		value := matcher.Value
+		if operator == v3.FilterOperatorRegex || operator == v3.FilterOperatorNotRegex {
+			// the label matchers match the whole value
+			value = "^(?:" + value + ")$"
+		}
+		if operator == v3.FilterOperatorEqual {
+			streamLabels = append(streamLabels, key)
+		}
+		query.Filters.Items = append(query.Filters.Items, v3.FilterItem{Key: key, Operator: operator, Value: value})
+	}
+
+	for _, filter := range e.Selector.LineFilters {
+		operator, ok := lineFilterOperators[filter.Op]
+		if !ok {
+			return nil, fmt.Errorf("unsupported line filter %s", filter.Op)
+		}
+		value := filter.Value
+		if operator == v3.FilterOperatorLike || operator == v3.FilterOperatorNotLike {
+			value = "%" + likeEscaper.Replace(value) + "%"
+		}
+		query.Filters.Items = append(query.Filters.Items, v3.FilterItem{
+			Key:      v3.AttributeKey{Key: "body", DataType: v3.AttributeKeyDataTypeString, IsColumn: true},
+			Operator: operator,
+			Value:    value,
+		})
+	}
+
+	if !e.IsMetric() {
+		order := v3.DirectionDesc
+		if forward {
+			order = v3.DirectionAsc
+		}
+		query.AggregateOperator = v3.AggregateOperatorNoOp
+		query.Limit = limit
+		query.PageSize = limit
+		query.OrderBy = []v3.OrderBy{{ColumnName: "timestamp", Order: order}}
+		return query, nil
+	}
+
+	query.StepInterval = int64(e.Range.Seconds())
+	if query.StepInterval < 1 {
+		query.StepInterval = 1
+	}
+	switch e.RangeAggregation {
+	case RangeAggregationCountOverTime:
+		query.AggregateOperator = v3.AggregateOperatorCount
+	case RangeAggregationRate:
+		query.AggregateOperator = v3.AggregateOperatorRate
+	}
+	// without the sum, there is a series for each stream of the selector
+	query.GroupBy = streamLabels
+	if e.Sum {
+		query.GroupBy = []v3.AttributeKey{}
+		for _, label := range e.Grouping {
+			query.GroupBy = append(query.GroupBy, v3.AttributeKey{
+				Key:      labels.Attribute(label),
+				DataType: v3.AttributeKeyDataTypeString,
+				Type:     v3.AttributeKeyTypeResource,
+			})
+		}
+	}
+	return query, nil
+}
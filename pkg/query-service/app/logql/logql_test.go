@@ -0,0 +1,103 @@
+package logql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func TestParse(t *testing.T) {
+	expr, err := Parse(`{service_name="cart", env=~"prod|staging"} |= "error" != "timeout" |~ ` + "`status=5\\d\\d`")
+	require.NoError(t, err)
+	assert.False(t, expr.IsMetric())
+	assert.Equal(t, LogSelector{
+		Matchers: []LabelMatcher{
+			{Name: "service_name", Op: "=", Value: "cart"},
+			{Name: "env", Op: "=~", Value: "prod|staging"},
+		},
+		LineFilters: []LineFilter{
+			{Op: "|=", Value: "error"},
+			{Op: "!=", Value: "timeout"},
+			{Op: "|~", Value: `status=5\d\d`},
+		},
+	}, expr.Selector)
+
+	expr, err = Parse(`sum by (service_name) (rate({env!="dev"} !~ "debug" [5m]))`)
+	require.NoError(t, err)
+	assert.True(t, expr.IsMetric())
+	assert.Equal(t, RangeAggregationRate, expr.RangeAggregation)
+	assert.Equal(t, 5*time.Minute, expr.Range)
+	assert.True(t, expr.Sum)
+	assert.Equal(t, []string{"service_name"}, expr.Grouping)
+
+	expr, err = Parse(`sum(count_over_time({app="cart"}[1h])) by (host_name)`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host_name"}, expr.Grouping)
+
+	for _, query := range []string{
+		``,
+		`{}`,
+		`{app="cart"`,
+		`{app=cart}`,
+		`{app="cart"} | json`,
+		`avg_over_time({app="cart"}[5m])`,
+		`count_over_time({app="cart"})`,
+		`count_over_time({app="cart"}[5x])`,
+		`rate({app="cart"}[5m]) > 1`,
+	} {
+		_, err := Parse(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestBuilderQuery(t *testing.T) {
+	labels := NewLabels([]string{"service.name", "k8s.pod_name"})
+	expr, err := Parse(`{service_name="cart", env=~"prod|staging"} |= "50%_done" !~ "debug"`)
+	require.NoError(t, err)
+	query, err := expr.BuilderQuery(labels, 50, false)
+	require.NoError(t, err)
+	assert.Equal(t, v3.AggregateOperatorNoOp, query.AggregateOperator)
+	assert.Equal(t, uint64(50), query.PageSize)
+	assert.Equal(t, []v3.OrderBy{{ColumnName: "timestamp", Order: v3.DirectionDesc}}, query.OrderBy)
+	body := v3.AttributeKey{Key: "body", DataType: v3.AttributeKeyDataTypeString, IsColumn: true}
+	assert.Equal(t, []v3.FilterItem{
+		{Key: v3.AttributeKey{Key: "service.name", DataType: v3.AttributeKeyDataTypeString, Type: v3.AttributeKeyTypeResource}, Operator: v3.FilterOperatorEqual, Value: "cart"},
+		{Key: v3.AttributeKey{Key: "env", DataType: v3.AttributeKeyDataTypeString, Type: v3.AttributeKeyTypeResource}, Operator: v3.FilterOperatorRegex, Value: "^(?:prod|staging)$"},
+		{Key: body, Operator: v3.FilterOperatorLike, Value: `%50\%\_done%`},
+		{Key: body, Operator: v3.FilterOperatorNotRegex, Value: "debug"},
+	}, query.Filters.Items)
+	require.NoError(t, query.Validate(v3.PanelTypeList))
+
+	// without the sum there is a series for each stream
+	expr, err = Parse(`count_over_time({service_name="cart"}[2m])`)
+	require.NoError(t, err)
+	query, err = expr.BuilderQuery(labels, 50, false)
+	require.NoError(t, err)
+	assert.Equal(t, v3.AggregateOperatorCount, query.AggregateOperator)
+	assert.Equal(t, int64(120), query.StepInterval)
+	assert.Equal(t, []v3.AttributeKey{{Key: "service.name", DataType: v3.AttributeKeyDataTypeString, Type: v3.AttributeKeyTypeResource}}, query.GroupBy)
+
+	expr, err = Parse(`sum(rate({service_name="cart"}[1m]))`)
+	require.NoError(t, err)
+	query, err = expr.BuilderQuery(labels, 50, false)
+	require.NoError(t, err)
+	assert.Equal(t, v3.AggregateOperatorRate, query.AggregateOperator)
+	assert.Empty(t, query.GroupBy)
+	require.NoError(t, query.Validate(v3.PanelTypeGraph))
+}
+
+func TestLabels(t *testing.T) {
+	labels := NewLabels([]string{"service.name", "k8s.pod_name", "host_name", "host.name"})
+	assert.Equal(t, "service.name", labels.Attribute("service_name"))
+	assert.Equal(t, "k8s.pod_name", labels.Attribute("k8s_pod_name"))
+	// the attributes of the label name come first
+	assert.Equal(t, "host_name", labels.Attribute("host_name"))
+	// the unknown labels are literal
+	assert.Equal(t, "deployment_environment", labels.Attribute("deployment_environment"))
+	for _, attribute := range []string{"service.name", "k8s.pod_name", "host_name"} {
+		assert.Equal(t, attribute, labels.Attribute(LabelName(attribute)))
+	}
+}
@@ -0,0 +1,327 @@
+package logql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	promModel "github.com/prometheus/common/model"
+)
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenDuration
+	tokenOperator
+	tokenPunct
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// operators are the label matchers and the line filters, the longest first
+var operators = []string{"=~", "!~", "!=", "|=", "|~", "="}
+
+// Parse parses the LogQL query. It supports the log queries made of a stream
+// selector and line filters, such as {app="cart"} |= "error", and their
+// count_over_time and rate range aggregations, optionally summed by labels,
+// such as sum by (app) (rate({app="cart"} |= "error" [5m])).
+func Parse(query string) (*Expr, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q", p.peek().value)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("unexpected end of the query")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != kind || t.value != value {
+		return fmt.Errorf("expected %q, got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *parser) expr() (*Expr, error) {
+	t := p.peek()
+	if t.kind != tokenIdent {
+		selector, err := p.logSelector()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Selector: *selector}, nil
+	}
+
+	switch t.value {
+	case "sum":
+		p.pos++
+		grouping, err := p.grouping()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenPunct, "("); err != nil {
+			return nil, err
+		}
+		expr, err := p.rangeAggregation()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenPunct, ")"); err != nil {
+			return nil, err
+		}
+		// the grouping may come before or after the aggregated expression
+		if grouping == nil {
+			if grouping, err = p.grouping(); err != nil {
+				return nil, err
+			}
+		}
+		expr.Sum = true
+		expr.Grouping = grouping
+		return expr, nil
+	case RangeAggregationCountOverTime, RangeAggregationRate:
+		return p.rangeAggregation()
+	}
+	return nil, fmt.Errorf("unsupported function %s", t.value)
+}
+
+// grouping parses the optional by clause of the sum
+func (p *parser) grouping() ([]string, error) {
+	if t := p.peek(); t.kind != tokenIdent || t.value != "by" {
+		return nil, nil
+	}
+	p.pos++
+	if err := p.expect(tokenPunct, "("); err != nil {
+		return nil, err
+	}
+	grouping := []string{}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokenPunct && t.value == ")" && len(grouping) == 0 {
+			return grouping, nil
+		}
+		if t.kind != tokenIdent {
+			return nil, fmt.Errorf("expected a label, got %q", t.value)
+		}
+		grouping = append(grouping, t.value)
+
+		t, err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokenPunct && t.value == ")" {
+			return grouping, nil
+		}
+		if t.kind != tokenPunct || t.value != "," {
+			return nil, fmt.Errorf("expected \",\" or \")\", got %q", t.value)
+		}
+	}
+}
+
+func (p *parser) rangeAggregation() (*Expr, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind != tokenIdent || (t.value != RangeAggregationCountOverTime && t.value != RangeAggregationRate) {
+		return nil, fmt.Errorf("unsupported range aggregation %q", t.value)
+	}
+	if err := p.expect(tokenPunct, "("); err != nil {
+		return nil, err
+	}
+	selector, err := p.logSelector()
+	if err != nil {
+		return nil, err
+	}
+	d, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if d.kind != tokenDuration {
+		return nil, fmt.Errorf("expected a range, got %q", d.value)
+	}
+	duration, err := promModel.ParseDuration(d.value)
+	if err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("the range must be positive")
+	}
+	if err := p.expect(tokenPunct, ")"); err != nil {
+		return nil, err
+	}
+	return &Expr{Selector: *selector, RangeAggregation: t.value, Range: time.Duration(duration)}, nil
+}
+
+func (p *parser) logSelector() (*LogSelector, error) {
+	if err := p.expect(tokenPunct, "{"); err != nil {
+		return nil, err
+	}
+	selector := &LogSelector{}
+	for {
+		name, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if name.kind != tokenIdent {
+			return nil, fmt.Errorf("expected a label, got %q", name.value)
+		}
+		op, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if op.kind != tokenOperator || op.value == "|=" || op.value == "|~" {
+			return nil, fmt.Errorf("expected a label matcher, got %q", op.value)
+		}
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if value.kind != tokenString {
+			return nil, fmt.Errorf("expected a string, got %q", value.value)
+		}
+		selector.Matchers = append(selector.Matchers, LabelMatcher{Name: name.value, Op: op.value, Value: value.value})
+
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokenPunct && t.value == "}" {
+			break
+		}
+		if t.kind != tokenPunct || t.value != "," {
+			return nil, fmt.Errorf("expected \",\" or \"}\", got %q", t.value)
+		}
+	}
+
+	for t := p.peek(); t.kind == tokenOperator && t.value != "=" && t.value != "=~"; t = p.peek() {
+		p.pos++
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if value.kind != tokenString {
+			return nil, fmt.Errorf("expected a string, got %q", value.value)
+		}
+		selector.LineFilters = append(selector.LineFilters, LineFilter{Op: t.value, Value: value.value})
+	}
+	return selector, nil
+}
+
+func tokenize(query string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			// the double quoted strings are unquoted as in Go
+			end := i + 1
+			for ; end < len(runes) && runes[end] != '"'; end++ {
+				if runes[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			value, err := strconv.Unquote(string(runes[i : end+1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", string(runes[i:end+1]))
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value})
+			i = end + 1
+		case r == '`':
+			end := i + 1
+			for end < len(runes) && runes[end] != '`' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, token{kind: tokenString, value: string(runes[i+1 : end])})
+			i = end + 1
+		case r == '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated range")
+			}
+			tokens = append(tokens, token{kind: tokenDuration, value: strings.TrimSpace(string(runes[i+1 : end]))})
+			i = end + 1
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i])})
+		case strings.ContainsRune("{}(),", r):
+			tokens = append(tokens, token{kind: tokenPunct, value: string(r)})
+			i++
+		default:
+			var operator string
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					operator = op
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: operator})
+			i += len(operator)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	return tokens, nil
+}
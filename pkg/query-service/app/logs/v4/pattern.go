@@ -0,0 +1,107 @@
+package v4
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	"go.signoz.io/signoz/pkg/query-service/app/resource"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+// logPatternMaxLength is the length of the body the pattern is taken from
+const logPatternMaxLength = 1024
+
+// logPatternMasks replace the variable parts of a log body, such as the ids
+// and the numbers, so the logs written by the same statement share a pattern.
+// The masks are applied in order, by clickhouse and by LogPattern alike.
+var logPatternMasks = []struct {
+	expr        string
+	replacement string
+}{
+	{`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`, "<uuid>"},
+	{`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}(:\d+)?\b`, "<ip>"},
+	{`\b(0x[0-9a-fA-F]+|[0-9a-fA-F]{16,})\b`, "<hex>"},
+	{`\b\d+(\.\d+)?`, "<num>"},
+}
+
+var logPatternRegexps = func() []*regexp.Regexp {
+	regexps := make([]*regexp.Regexp, 0, len(logPatternMasks))
+	for _, mask := range logPatternMasks {
+		regexps = append(regexps, regexp.MustCompile(mask.expr))
+	}
+	return regexps
+}()
+
+// LogPattern returns the pattern of a log body, as the log patterns query
+// computes it
+func LogPattern(body string) string {
+	if runes := []rune(body); len(runes) > logPatternMaxLength {
+		body = string(runes[:logPatternMaxLength])
+	}
+	for i, re := range logPatternRegexps {
+		body = re.ReplaceAllLiteralString(body, logPatternMasks[i].replacement)
+	}
+	return body
+}
+
+// LogPatternFingerprint identifies a log pattern
+func LogPatternFingerprint(pattern string) string {
+	h := fnv.New64a()
+	h.Write([]byte(pattern))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// logPatternExpr is the clickhouse expression of the pattern of the body
+func logPatternExpr() string {
+	expr := fmt.Sprintf("substringUTF8(%s, 1, %d)", BODY, logPatternMaxLength)
+	for _, mask := range logPatternMasks {
+		expr = fmt.Sprintf("replaceRegexpAll(%s, %s, %s)", expr, utils.ClickHouseFormattedValue(mask.expr), utils.ClickHouseFormattedValue(mask.replacement))
+	}
+	return expr
+}
+
+// PrepareLogPatternsQuery prepares the query counting the logs matching the
+// filters of the builder query by pattern and by the group by keys. The
+// patterns are ordered by their count.
+func PrepareLogPatternsQuery(start, end int64, mq *v3.BuilderQuery, limit uint64) (string, error) {
+	logsStart := utils.GetEpochNanoSecs(start)
+	logsEnd := utils.GetEpochNanoSecs(end)
+
+	// -1800 this is added so that the bucket start considers all the fingerprints.
+	bucketStart := logsStart/NANOSECOND - 1800
+	bucketEnd := logsEnd / NANOSECOND
+
+	timeFilter := fmt.Sprintf("(timestamp >= %d AND timestamp <= %d) AND (ts_bucket_start >= %d AND ts_bucket_start <= %d)", logsStart, logsEnd, bucketStart, bucketEnd)
+
+	filterSubQuery, err := buildLogsTimeSeriesFilterQuery(mq.Filters, mq.GroupBy, v3.AttributeKey{})
+	if err != nil {
+		return "", err
+	}
+	if filterSubQuery != "" {
+		filterSubQuery = " AND " + filterSubQuery
+	}
+
+	resourceSubQuery, err := resource.BuildResourceSubQuery(DB_NAME, DISTRIBUTED_LOGS_V2_RESOURCE, bucketStart, bucketEnd, mq.Filters, mq.GroupBy, v3.AttributeKey{}, false)
+	if err != nil {
+		return "", err
+	}
+	if resourceSubQuery != "" {
+		filterSubQuery = filterSubQuery + " AND (resource_fingerprint GLOBAL IN " + resourceSubQuery + ")"
+	}
+
+	groupBy := []string{}
+	for _, tag := range mq.GroupBy {
+		groupBy = append(groupBy, "`"+tag.Key+"`")
+	}
+	groupBy = append(groupBy, "pattern")
+
+	query := fmt.Sprintf("SELECT%s %s as pattern, toFloat64(count()) as value from %s.%s where %s%s group by %s order by value DESC",
+		getSelectLabels(v3.AggregateOperatorCount, mq.GroupBy), logPatternExpr(), DB_NAME, DISTRIBUTED_LOGS_V2, timeFilter, filterSubQuery, strings.Join(groupBy, ", "))
+	if limit > 0 {
+		query = fmt.Sprintf("%s LIMIT %d", query, limit)
+	}
+	return query, nil
+}
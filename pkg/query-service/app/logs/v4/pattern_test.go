@@ -0,0 +1,59 @@
+package v4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func TestLogPattern(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{
+			body: "user 42 logged in from 10.0.0.12:5432",
+			want: "user <num> logged in from <ip>",
+		},
+		{
+			body: "order 3f2b8c1e-9d4a-4b6e-8f2a-1c3d5e7f9a0b failed after 1.5s",
+			want: "order <uuid> failed after <num>s",
+		},
+		{
+			body: "span 0x7f3a trace 4bf92f3577b34da6a3ce929d0e0e4736",
+			want: "span <hex> trace <hex>",
+		},
+		{
+			body: "connection reset by peer",
+			want: "connection reset by peer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			assert.Equal(t, tt.want, LogPattern(tt.body))
+		})
+	}
+
+	assert.Equal(t, LogPatternFingerprint(LogPattern("user 1 logged in")), LogPatternFingerprint(LogPattern("user 2 logged in")))
+	assert.NotEqual(t, LogPatternFingerprint(LogPattern("user 1 logged in")), LogPatternFingerprint(LogPattern("user 1 logged out")))
+}
+
+func TestPrepareLogPatternsQuery(t *testing.T) {
+	query, err := PrepareLogPatternsQuery(1680066360726, 1680066458000, &v3.BuilderQuery{
+		QueryName:  "A",
+		DataSource: v3.DataSourceLogs,
+		Filters: &v3.FilterSet{Operator: "AND", Items: []v3.FilterItem{
+			{Key: v3.AttributeKey{Key: "severity_text", DataType: v3.AttributeKeyDataTypeString, Type: v3.AttributeKeyTypeUnspecified, IsColumn: true}, Value: "ERROR", Operator: "="},
+		}},
+		GroupBy: []v3.AttributeKey{{Key: "service.name", DataType: v3.AttributeKeyDataTypeString, Type: v3.AttributeKeyTypeResource}},
+	}, 100)
+	require.NoError(t, err)
+
+	assert.Contains(t, query, "SELECT resources_string['service.name'] as `service.name`, replaceRegexpAll(")
+	assert.Contains(t, query, `'\\b\\d+(\\.\\d+)?', '<num>') as pattern`)
+	assert.Contains(t, query, "where (timestamp >= 1680066360726000000 AND timestamp <= 1680066458000000000)")
+	assert.Contains(t, query, "AND severity_text = 'ERROR'")
+	assert.Contains(t, query, "group by `service.name`, pattern order by value DESC LIMIT 100")
+}
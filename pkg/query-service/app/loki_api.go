@@ -0,0 +1,241 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/app/logql"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+const (
+	lokiResultTypeStreams = "streams"
+	lokiResultTypeMatrix  = "matrix"
+
+	lokiDefaultLimit = 100
+	lokiMaxLimit     = 5000
+)
+
+// lokiQueryData is the data of the query responses of the Loki API
+type lokiQueryData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values [][2]interface{}  `json:"values"`
+}
+
+// lokiQueryRange runs the LogQL query of the query range endpoint of the Loki
+// API on the logs
+func (aH *APIHandler) lokiQueryRange(w http.ResponseWriter, r *http.Request) {
+	fields, apiErr := aH.reader.GetLogFields(r.Context())
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	expr, queryRangeParams, apiErr := parseLokiQueryRangeRequest(r, lokiLabels(fields))
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	queryRangeParams, apiErr = PrepareQueryRangeParams(r.Context(), queryRangeParams)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	result, apiErr, errData := aH.execQueryRangeV4(r.Context(), queryRangeParams)
+	if apiErr != nil {
+		RespondError(w, apiErr, errData)
+		return
+	}
+
+	if expr.IsMetric() {
+		aH.Respond(w, lokiQueryData{ResultType: lokiResultTypeMatrix, Result: lokiMatrix(result)})
+		return
+	}
+	aH.Respond(w, lokiQueryData{ResultType: lokiResultTypeStreams, Result: lokiStreams(result)})
+}
+
+// parseLokiQueryRangeRequest parses the params of the query range endpoint of
+// the Loki API into the query range params of its logs builder query. The
+// time range defaults to the last hour, and the limit is at most
+// lokiMaxLimit lines.
+func parseLokiQueryRangeRequest(r *http.Request, labels logql.Labels) (*logql.Expr, *v3.QueryRangeParamsV3, *model.ApiError) {
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+
+	expr, err := logql.Parse(r.FormValue("query"))
+	if err != nil {
+		return nil, nil, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("invalid query: %w", err)}
+	}
+
+	end := time.Now()
+	if t := r.FormValue("end"); t != "" {
+		if end, err = parseLokiTime(t); err != nil {
+			return nil, nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+		}
+	}
+	start := end.Add(-time.Hour)
+	if t := r.FormValue("start"); t != "" {
+		if start, err = parseLokiTime(t); err != nil {
+			return nil, nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+		}
+	}
+	if end.Before(start) {
+		return nil, nil, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("end timestamp must not be before start time")}
+	}
+
+	limit := uint64(lokiDefaultLimit)
+	if l := r.FormValue("limit"); l != "" {
+		if limit, err = strconv.ParseUint(l, 10, 64); err != nil {
+			return nil, nil, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("invalid limit %q", l)}
+		}
+	}
+	// no limit is all the lines in the builder queries
+	if limit == 0 || limit > lokiMaxLimit {
+		limit = lokiMaxLimit
+	}
+
+	var forward bool
+	switch direction := r.FormValue("direction"); direction {
+	case "", "backward":
+	case "forward":
+		forward = true
+	default:
+		return nil, nil, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("invalid direction %q", direction)}
+	}
+
+	query, err := expr.BuilderQuery(labels, limit, forward)
+	if err != nil {
+		return nil, nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+
+	panelType := v3.PanelTypeList
+	if expr.IsMetric() {
+		panelType = v3.PanelTypeGraph
+	}
+	return expr, &v3.QueryRangeParamsV3{
+		Start:   start.UnixMilli(),
+		End:     end.UnixMilli(),
+		Step:    query.StepInterval,
+		Version: "v4",
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType:      v3.QueryTypeBuilder,
+			PanelType:      panelType,
+			BuilderQueries: map[string]*v3.BuilderQuery{query.QueryName: query},
+		},
+	}, nil
+}
+
+// lokiLabels returns the labels of the resource attributes of the logs
+func lokiLabels(fields *model.GetFieldsResponse) logql.Labels {
+	attributes := []string{}
+	for _, field := range append(fields.Selected, fields.Interesting...) {
+		if field.Type == constants.Resources {
+			attributes = append(attributes, field.Name)
+		}
+	}
+	return logql.NewLabels(attributes)
+}
+
+// parseLokiTime parses the times of the Loki API, which are nanosecond or
+// second Unix epochs or RFC3339 times
+func parseLokiTime(s string) (time.Time, error) {
+	if !strings.Contains(s, ".") {
+		if ns, err := strconv.ParseInt(s, 10, 64); err == nil {
+			// the times up to 10 digits are in seconds
+			if len(strings.TrimPrefix(s, "-")) <= 10 {
+				return time.Unix(ns, 0), nil
+			}
+			return time.Unix(0, ns), nil
+		}
+	}
+	return parseMetricsTime(s)
+}
+
+// lokiStreams returns the log lines of the results by stream, the streams
+// are the resource attributes of the logs
+func lokiStreams(results []*v3.Result) []lokiStream {
+	streams := []lokiStream{}
+	byLabels := map[string]int{}
+	for _, result := range results {
+		for _, row := range result.List {
+			labels := map[string]string{}
+			if resources, ok := derefRowValue(row.Data["resources_string"]).(map[string]string); ok {
+				for k, v := range resources {
+					labels[logql.LabelName(k)] = v
+				}
+			}
+			key := lokiLabelsKey(labels)
+			idx, ok := byLabels[key]
+			if !ok {
+				idx = len(streams)
+				byLabels[key] = idx
+				streams = append(streams, lokiStream{Stream: labels, Values: [][2]string{}})
+			}
+			body, _ := derefRowValue(row.Data["body"]).(string)
+			streams[idx].Values = append(streams[idx].Values, [2]string{strconv.FormatInt(row.Timestamp.UnixNano(), 10), body})
+		}
+	}
+	return streams
+}
+
+// lokiMatrix returns the series of the results with the labels of the
+// streams
+func lokiMatrix(results []*v3.Result) []lokiSeries {
+	matrix := []lokiSeries{}
+	for _, result := range results {
+		for _, series := range result.Series {
+			metric := map[string]string{}
+			for k, v := range series.Labels {
+				metric[logql.LabelName(k)] = v
+			}
+			values := [][2]interface{}{}
+			for _, point := range series.Points {
+				values = append(values, [2]interface{}{
+					float64(point.Timestamp) / 1000,
+					strconv.FormatFloat(point.Value, 'f', -1, 64),
+				})
+			}
+			matrix = append(matrix, lokiSeries{Metric: metric, Values: values})
+		}
+	}
+	return matrix
+}
+
+func lokiLabelsKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+strconv.Quote(v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// derefRowValue returns the value the columns of the list results point to
+func derefRowValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *string:
+		return *v
+	case *map[string]string:
+		return *v
+	}
+	return v
+}
@@ -0,0 +1,296 @@
+package macros
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+var db *sqlx.DB
+
+var (
+	nameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// referenceRe matches the references to the macros in the queries
+	referenceRe = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+var (
+	ErrMacroNotFound = errors.New("macro not found")
+	ErrMacroExists   = errors.New("a macro with the same name exists")
+	ErrNoOrg         = errors.New("error in getting org from context")
+)
+
+// Macro is a query fragment defined by the admins of an org, its references
+// as $name are expanded in the builder and ClickHouse SQL queries of the org
+type Macro struct {
+	Id          string    `json:"id" db:"id"`
+	OrgId       string    `json:"-" db:"org_id"`
+	Name        string    `json:"name" db:"name"`
+	Expression  string    `json:"expression" db:"expression"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+	CreatedBy   string    `json:"createdBy" db:"created_by"`
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+	UpdatedBy   string    `json:"updatedBy" db:"updated_by"`
+}
+
+func (m *Macro) Validate() error {
+	if !nameRe.MatchString(m.Name) {
+		return fmt.Errorf("name must start with a letter or an underscore and only contain letters, digits and underscores")
+	}
+	if strings.TrimSpace(m.Expression) == "" {
+		return fmt.Errorf("expression is required")
+	}
+	return nil
+}
+
+// InitDB sets up the connection pool global variable.
+func InitDB(inputDB *sqlx.DB) {
+	db = inputDB
+}
+
+// orgID returns the org of the user of the context. The rules, evaluated
+// without a user, aren't scoped by org and use the macros of the only org of
+// the single org deployments.
+func orgID(ctx context.Context) (string, error) {
+	if claims, ok := authtypes.ClaimsFromContext(ctx); ok {
+		if claims.OrgID == "" {
+			return "", ErrNoOrg
+		}
+		return claims.OrgID, nil
+	}
+
+	ids := []string{}
+	if err := db.SelectContext(ctx, &ids, "SELECT id FROM organizations LIMIT 2"); err != nil {
+		return "", fmt.Errorf("error in getting orgs: %s", err.Error())
+	}
+	if len(ids) != 1 {
+		return "", ErrNoOrg
+	}
+	return ids[0], nil
+}
+
+func GetMacros(ctx context.Context) ([]Macro, error) {
+	org, err := orgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	macros := []Macro{}
+	if err := db.SelectContext(ctx, &macros, "SELECT * FROM query_macros WHERE org_id = ? ORDER BY name", org); err != nil {
+		return nil, fmt.Errorf("error in getting macros: %s", err.Error())
+	}
+	return macros, nil
+}
+
+func GetMacro(ctx context.Context, id string) (*Macro, error) {
+	org, err := orgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var macro Macro
+	err = db.GetContext(ctx, &macro, "SELECT * FROM query_macros WHERE id = ? AND org_id = ?", id, org)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMacroNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error in getting macro: %s", err.Error())
+	}
+	return &macro, nil
+}
+
+func CreateMacro(ctx context.Context, macro Macro) (*Macro, error) {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("error in getting email from context")
+	}
+	if claims.OrgID == "" {
+		return nil, ErrNoOrg
+	}
+	if err := checkNameUnused(ctx, claims.OrgID, macro.Name, ""); err != nil {
+		return nil, err
+	}
+
+	macro.Id = uuid.New().String()
+	macro.OrgId = claims.OrgID
+	macro.CreatedAt = time.Now()
+	macro.CreatedBy = claims.Email
+	macro.UpdatedAt = macro.CreatedAt
+	macro.UpdatedBy = claims.Email
+
+	_, err := db.NamedExecContext(ctx, `INSERT INTO query_macros (id, org_id, name, expression, description, created_at, created_by, updated_at, updated_by)
+		VALUES (:id, :org_id, :name, :expression, :description, :created_at, :created_by, :updated_at, :updated_by)`, macro)
+	if err != nil {
+		return nil, fmt.Errorf("error in creating macro: %s", err.Error())
+	}
+	return &macro, nil
+}
+
+func UpdateMacro(ctx context.Context, id string, macro Macro) (*Macro, error) {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("error in getting email from context")
+	}
+	existing, err := GetMacro(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNameUnused(ctx, existing.OrgId, macro.Name, id); err != nil {
+		return nil, err
+	}
+
+	existing.Name = macro.Name
+	existing.Expression = macro.Expression
+	existing.Description = macro.Description
+	existing.UpdatedAt = time.Now()
+	existing.UpdatedBy = claims.Email
+
+	_, err = db.NamedExecContext(ctx, `UPDATE query_macros SET name = :name, expression = :expression, description = :description,
+		updated_at = :updated_at, updated_by = :updated_by WHERE id = :id AND org_id = :org_id`, existing)
+	if err != nil {
+		return nil, fmt.Errorf("error in updating macro: %s", err.Error())
+	}
+	return existing, nil
+}
+
+func DeleteMacro(ctx context.Context, id string) error {
+	org, err := orgID(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := db.ExecContext(ctx, "DELETE FROM query_macros WHERE id = ? AND org_id = ?", id, org)
+	if err != nil {
+		return fmt.Errorf("error in deleting macro: %s", err.Error())
+	}
+	if deleted, err := res.RowsAffected(); err == nil && deleted == 0 {
+		return ErrMacroNotFound
+	}
+	return nil
+}
+
+func checkNameUnused(ctx context.Context, org string, name string, id string) error {
+	var count int
+	if err := db.GetContext(ctx, &count, "SELECT COUNT(*) FROM query_macros WHERE org_id = ? AND name = ? AND id != ?", org, name, id); err != nil {
+		return fmt.Errorf("error in getting macros: %s", err.Error())
+	}
+	if count > 0 {
+		return ErrMacroExists
+	}
+	return nil
+}
+
+// Expand expands the references to the macros of the org of the context in
+// the builder and ClickHouse SQL queries of the query range params. Without
+// an org no macro is expanded.
+func Expand(ctx context.Context, params *v3.QueryRangeParamsV3) error {
+	if db == nil || params.CompositeQuery == nil || !hasReferences(params.CompositeQuery) {
+		return nil
+	}
+
+	macros, err := GetMacros(ctx)
+	if errors.Is(err, ErrNoOrg) {
+		macros = []Macro{}
+	} else if err != nil {
+		return err
+	}
+	byName := make(map[string]Macro, len(macros))
+	for _, macro := range macros {
+		byName[macro.Name] = macro
+	}
+	return expand(params, byName)
+}
+
+// hasReferences tells whether the queries may reference macros, to not read
+// them for every query
+func hasReferences(compositeQuery *v3.CompositeQuery) bool {
+	for _, query := range compositeQuery.ClickHouseQueries {
+		if referenceRe.MatchString(query.Query) {
+			return true
+		}
+	}
+	for _, query := range compositeQuery.BuilderQueries {
+		if query.Filters == nil {
+			continue
+		}
+		for _, item := range query.Filters.Items {
+			if strings.HasPrefix(item.Key.Key, "$") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func expand(params *v3.QueryRangeParamsV3, macros map[string]Macro) error {
+	switch params.CompositeQuery.QueryType {
+	case v3.QueryTypeClickHouseSQL:
+		for _, query := range params.CompositeQuery.ClickHouseQueries {
+			query.Query = expandQuery(query.Query, macros, params.Variables)
+		}
+	case v3.QueryTypeBuilder:
+		for name, query := range params.CompositeQuery.BuilderQueries {
+			if query.Filters == nil {
+				continue
+			}
+			filters, err := expandFilters(query.Filters, macros)
+			if err != nil {
+				return fmt.Errorf("query %s: %w", name, err)
+			}
+			query.Filters = filters
+		}
+	}
+	return nil
+}
+
+// expandQuery replaces the references to the macros in the query with their
+// expression. The variables of the query take precedence over the macros of
+// the same name, and the references in the expressions are left to them.
+func expandQuery(query string, macros map[string]Macro, variables map[string]interface{}) string {
+	return referenceRe.ReplaceAllStringFunc(query, func(reference string) string {
+		name := reference[1:]
+		if _, ok := variables[name]; ok {
+			return reference
+		}
+		if macro, ok := macros[name]; ok {
+			return macro.Expression
+		}
+		return reference
+	})
+}
+
+// expandFilters replaces the filter items whose key references a macro with
+// the conditions of its expression
+func expandFilters(filters *v3.FilterSet, macros map[string]Macro) (*v3.FilterSet, error) {
+	expanded := &v3.FilterSet{Operator: filters.Operator, Items: []v3.FilterItem{}}
+	for _, item := range filters.Items {
+		if !strings.HasPrefix(item.Key.Key, "$") {
+			expanded.Items = append(expanded.Items, item)
+			continue
+		}
+		name := item.Key.Key[1:]
+		macro, ok := macros[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown macro $%s", name)
+		}
+		items, err := parseFilterExpression(macro.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("macro $%s can't be used in builder queries: %w", name, err)
+		}
+		if len(items) > 1 && strings.EqualFold(filters.Operator, "OR") {
+			return nil, fmt.Errorf("macro $%s has several conditions and can't be used in OR filters", name)
+		}
+		expanded.Items = append(expanded.Items, items...)
+	}
+	return expanded, nil
+}
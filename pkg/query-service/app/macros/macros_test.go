@@ -0,0 +1,146 @@
+package macros
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	items, err := parseFilterExpression(`deployment.environment = 'prod' and http.status_code >= 500 AND service.name NOT IN ('cart', 'it''s') AND body LIKE '%timeout%' AND k8s.pod.name EXISTS AND retry != -1.5`)
+	require.NoError(t, err)
+	assert.Equal(t, []v3.FilterItem{
+		{Key: v3.AttributeKey{Key: "deployment.environment"}, Operator: v3.FilterOperatorEqual, Value: "prod"},
+		{Key: v3.AttributeKey{Key: "http.status_code"}, Operator: v3.FilterOperatorGreaterThanOrEq, Value: int64(500)},
+		{Key: v3.AttributeKey{Key: "service.name"}, Operator: v3.FilterOperatorNotIn, Value: []interface{}{"cart", "it's"}},
+		{Key: v3.AttributeKey{Key: "body"}, Operator: v3.FilterOperatorLike, Value: "%timeout%"},
+		{Key: v3.AttributeKey{Key: "k8s.pod.name"}, Operator: v3.FilterOperatorExists},
+		{Key: v3.AttributeKey{Key: "retry"}, Operator: v3.FilterOperatorNotEqual, Value: -1.5},
+	}, items)
+
+	for _, expression := range []string{
+		"deployment.environment = 'prod' OR deployment.environment = 'staging'",
+		"deployment.environment = 'prod",
+		"service.name IN ('cart'",
+		"toLower(service.name) = 'cart'",
+		"",
+	} {
+		_, err := parseFilterExpression(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	macros := map[string]Macro{
+		"prod_filter": {Name: "prod_filter", Expression: "deployment.environment = 'prod'"},
+		"prod":        {Name: "prod", Expression: "'prod'"},
+		"errors":      {Name: "errors", Expression: "severity_text = 'ERROR' AND service.name = '{{.service}}'"},
+	}
+
+	params := &v3.QueryRangeParamsV3{
+		Variables: map[string]interface{}{"prod": "'production'"},
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType: v3.QueryTypeClickHouseSQL,
+			ClickHouseQueries: map[string]*v3.ClickHouseQuery{
+				"A": {Query: "SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE $prod_filter AND env = $prod AND $unknown"},
+			},
+		},
+	}
+	require.True(t, hasReferences(params.CompositeQuery))
+	require.NoError(t, expand(params, macros))
+	// the variables take precedence over the macros of the same name
+	assert.Equal(t, "SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE deployment.environment = 'prod' AND env = $prod AND $unknown", params.CompositeQuery.ClickHouseQueries["A"].Query)
+
+	params = &v3.QueryRangeParamsV3{
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType: v3.QueryTypeBuilder,
+			BuilderQueries: map[string]*v3.BuilderQuery{
+				"A": {QueryName: "A", Expression: "A", DataSource: v3.DataSourceLogs, Filters: &v3.FilterSet{Operator: "AND", Items: []v3.FilterItem{
+					{Key: v3.AttributeKey{Key: "$errors"}},
+					{Key: v3.AttributeKey{Key: "host.name"}, Operator: v3.FilterOperatorEqual, Value: "web-1"},
+				}}},
+			},
+		},
+	}
+	require.NoError(t, expand(params, macros))
+	assert.Equal(t, []v3.FilterItem{
+		{Key: v3.AttributeKey{Key: "severity_text"}, Operator: v3.FilterOperatorEqual, Value: "ERROR"},
+		{Key: v3.AttributeKey{Key: "service.name"}, Operator: v3.FilterOperatorEqual, Value: "{{.service}}"},
+		{Key: v3.AttributeKey{Key: "host.name"}, Operator: v3.FilterOperatorEqual, Value: "web-1"},
+	}, params.CompositeQuery.BuilderQueries["A"].Filters.Items)
+
+	// the macros with several conditions can't be ORed
+	params.CompositeQuery.BuilderQueries["A"].Filters = &v3.FilterSet{Operator: "OR", Items: []v3.FilterItem{{Key: v3.AttributeKey{Key: "$errors"}}}}
+	assert.Error(t, expand(params, macros))
+	params.CompositeQuery.BuilderQueries["A"].Filters = &v3.FilterSet{Operator: "AND", Items: []v3.FilterItem{{Key: v3.AttributeKey{Key: "$unknown"}}}}
+	assert.Error(t, expand(params, macros))
+	params.CompositeQuery.BuilderQueries["A"].Filters = &v3.FilterSet{Operator: "AND", Items: []v3.FilterItem{{Key: v3.AttributeKey{Key: "$prod"}}}}
+	assert.Error(t, expand(params, macros))
+}
+
+func TestMacros(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	InitDB(sqlStore.SQLxDB())
+	ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "admin@signoz.io", OrgID: "org"})
+	otherCtx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "admin@other.io", OrgID: "other"})
+
+	require.Error((&Macro{Name: "prod-filter", Expression: "env = 'prod'"}).Validate())
+	require.Error((&Macro{Name: "prod_filter"}).Validate())
+
+	macro, err := CreateMacro(ctx, Macro{Name: "prod_filter", Expression: "deployment.environment = 'prod'"})
+	require.NoError(err)
+	require.Equal("admin@signoz.io", macro.CreatedBy)
+	_, err = CreateMacro(ctx, Macro{Name: "prod_filter", Expression: "env = 'prod'"})
+	require.ErrorIs(err, ErrMacroExists)
+
+	params := &v3.QueryRangeParamsV3{
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType: v3.QueryTypeClickHouseSQL,
+			ClickHouseQueries: map[string]*v3.ClickHouseQuery{
+				"A": {Query: "SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE $prod_filter"},
+			},
+		},
+	}
+	require.NoError(Expand(ctx, params))
+	require.Equal("SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE deployment.environment = 'prod'", params.CompositeQuery.ClickHouseQueries["A"].Query)
+
+	// the macros are scoped by org
+	_, err = GetMacro(otherCtx, macro.Id)
+	require.ErrorIs(err, ErrMacroNotFound)
+	require.ErrorIs(DeleteMacro(otherCtx, macro.Id), ErrMacroNotFound)
+	_, err = CreateMacro(otherCtx, Macro{Name: "prod_filter", Expression: "env = 'prod'"})
+	require.NoError(err)
+	params.CompositeQuery.ClickHouseQueries["A"].Query = "SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE $prod_filter"
+	require.NoError(Expand(otherCtx, params))
+	require.Equal("SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE env = 'prod'", params.CompositeQuery.ClickHouseQueries["A"].Query)
+
+	// the rules evaluated without a user use the macros of the only org
+	params.CompositeQuery.ClickHouseQueries["A"].Query = "SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE $prod_filter"
+	require.NoError(Expand(context.Background(), params))
+	require.Equal("SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE $prod_filter", params.CompositeQuery.ClickHouseQueries["A"].Query)
+	_, err = sqlStore.SQLxDB().Exec("INSERT INTO organizations (id, name, created_at) VALUES ('org', 'org', 0)")
+	require.NoError(err)
+	require.NoError(Expand(context.Background(), params))
+	require.Equal("SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE deployment.environment = 'prod'", params.CompositeQuery.ClickHouseQueries["A"].Query)
+
+	updated, err := UpdateMacro(ctx, macro.Id, Macro{Name: "prod_filter", Expression: "deployment.environment = 'production'", Description: "production"})
+	require.NoError(err)
+	require.Equal("production", updated.Description)
+	stored, err := GetMacro(ctx, macro.Id)
+	require.NoError(err)
+	require.Equal("deployment.environment = 'production'", stored.Expression)
+
+	require.NoError(DeleteMacro(ctx, macro.Id))
+	require.ErrorIs(DeleteMacro(ctx, macro.Id), ErrMacroNotFound)
+	_, err = GetMacro(ctx, macro.Id)
+	require.ErrorIs(err, ErrMacroNotFound)
+	list, err := GetMacros(ctx)
+	require.NoError(err)
+	require.Empty(list)
+}
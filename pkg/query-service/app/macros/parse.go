@@ -0,0 +1,268 @@
+package macros
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenPunct
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+var comparisonOperators = map[string]v3.FilterOperator{
+	"=":  v3.FilterOperatorEqual,
+	"!=": v3.FilterOperatorNotEqual,
+	"<>": v3.FilterOperatorNotEqual,
+	"<":  v3.FilterOperatorLessThan,
+	"<=": v3.FilterOperatorLessThanOrEq,
+	">":  v3.FilterOperatorGreaterThan,
+	">=": v3.FilterOperatorGreaterThanOrEq,
+}
+
+// parseFilterExpression parses the conditions of the expression of a macro
+// joined by AND into builder filter items, such as
+// deployment.environment = 'prod' AND http.status_code >= 500. The conditions
+// compare an attribute with =, !=, <>, <, <=, >, >=, [NOT] LIKE, [NOT] IN or
+// check it with EXISTS and NOT EXISTS.
+func parseFilterExpression(expression string) ([]v3.FilterItem, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	items := []v3.FilterItem{}
+	for {
+		item, err := p.condition()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.done() {
+			return items, nil
+		}
+		if !p.keyword("AND") {
+			return nil, fmt.Errorf("expected AND, got %q", p.peek().value)
+		}
+	}
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("unexpected end of the expression")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+// keyword consumes the next token if it is the keyword
+func (p *filterParser) keyword(keyword string) bool {
+	t := p.peek()
+	if t.kind == tokenIdent && strings.EqualFold(t.value, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) punct(punct string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != tokenPunct || t.value != punct {
+		return fmt.Errorf("expected %q, got %q", punct, t.value)
+	}
+	return nil
+}
+
+func (p *filterParser) condition() (v3.FilterItem, error) {
+	key, err := p.next()
+	if err != nil {
+		return v3.FilterItem{}, err
+	}
+	if key.kind != tokenIdent {
+		return v3.FilterItem{}, fmt.Errorf("expected an attribute, got %q", key.value)
+	}
+	item := v3.FilterItem{Key: v3.AttributeKey{Key: key.value}}
+
+	if t := p.peek(); t.kind == tokenOperator {
+		p.pos++
+		item.Operator = comparisonOperators[t.value]
+		item.Value, err = p.value()
+		return item, err
+	}
+
+	not := p.keyword("NOT")
+	switch {
+	case p.keyword("LIKE"):
+		item.Operator = v3.FilterOperatorLike
+		if not {
+			item.Operator = v3.FilterOperatorNotLike
+		}
+		item.Value, err = p.value()
+	case p.keyword("IN"):
+		item.Operator = v3.FilterOperatorIn
+		if not {
+			item.Operator = v3.FilterOperatorNotIn
+		}
+		item.Value, err = p.values()
+	case p.keyword("EXISTS"):
+		item.Operator = v3.FilterOperatorExists
+		if not {
+			item.Operator = v3.FilterOperatorNotExists
+		}
+	default:
+		return v3.FilterItem{}, fmt.Errorf("expected an operator after %s, got %q", key.value, p.peek().value)
+	}
+	return item, err
+}
+
+func (p *filterParser) value() (interface{}, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch t.kind {
+	case tokenString:
+		return t.value, nil
+	case tokenNumber:
+		if i, err := strconv.ParseInt(t.value, 10, 64); err == nil {
+			return i, nil
+		}
+		return strconv.ParseFloat(t.value, 64)
+	case tokenIdent:
+		if b, err := strconv.ParseBool(t.value); err == nil {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a value, got %q", t.value)
+}
+
+func (p *filterParser) values() ([]interface{}, error) {
+	if err := p.punct("("); err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokenPunct && t.value == ")" {
+			return values, nil
+		}
+		if t.kind != tokenPunct || t.value != "," {
+			return nil, fmt.Errorf("expected \",\" or \")\", got %q", t.value)
+		}
+	}
+}
+
+func tokenize(expression string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			var value strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string")
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					value.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '\'' {
+					// a doubled quote is a quote
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						value.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value.String()})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i])})
+		case strings.ContainsRune("=!<>", r):
+			operator := string(r)
+			if i+1 < len(runes) {
+				if _, ok := comparisonOperators[string(runes[i:i+2])]; ok {
+					operator = string(runes[i : i+2])
+				}
+			}
+			if _, ok := comparisonOperators[operator]; !ok {
+				return nil, fmt.Errorf("unknown operator %q", operator)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: operator})
+			i += len(operator)
+		case strings.ContainsRune("(),", r):
+			tokens = append(tokens, token{kind: tokenPunct, value: string(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return tokens, nil
+}
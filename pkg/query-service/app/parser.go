@@ -2,6 +2,7 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -19,10 +20,13 @@ import (
 	"github.com/SigNoz/govaluate"
 	"github.com/gorilla/mux"
 	promModel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
 	"go.uber.org/multierr"
 
+	"go.signoz.io/signoz/pkg/query-service/app/macros"
 	"go.signoz.io/signoz/pkg/query-service/app/metrics"
 	"go.signoz.io/signoz/pkg/query-service/app/queryBuilder"
+	"go.signoz.io/signoz/pkg/query-service/app/traces/traceql"
 	"go.signoz.io/signoz/pkg/query-service/auth"
 	"go.signoz.io/signoz/pkg/query-service/common"
 	"go.signoz.io/signoz/pkg/query-service/constants"
@@ -162,6 +166,55 @@ func parseQueryRangeRequest(r *http.Request) (*model.QueryRangeParams, *model.Ap
 	return &queryRangeParams, nil
 }
 
+// parsePromMetadataRequest parses the params of the series, labels and label
+// values endpoints of the Prometheus HTTP API. The time range defaults to the
+// last day.
+func parsePromMetadataRequest(r *http.Request) (*model.PromMetadataParams, *model.ApiError) {
+	if err := r.ParseForm(); err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+
+	end := time.Now()
+	if t := r.FormValue("end"); t != "" {
+		var err error
+		end, err = parseMetricsTime(t)
+		if err != nil {
+			return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+		}
+	}
+	start := end.Add(-24 * time.Hour)
+	if t := r.FormValue("start"); t != "" {
+		var err error
+		start, err = parseMetricsTime(t)
+		if err != nil {
+			return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+		}
+	}
+	if end.Before(start) {
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: errors.New("end timestamp must not be before start time")}
+	}
+
+	matchers, err := parser.ParseMetricSelectors(r.Form["match[]"])
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+
+	var limit uint64
+	if l := r.FormValue("limit"); l != "" {
+		limit, err = strconv.ParseUint(l, 10, 64)
+		if err != nil {
+			return nil, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("invalid limit %q", l)}
+		}
+	}
+
+	return &model.PromMetadataParams{
+		Matchers: matchers,
+		Start:    start,
+		End:      end,
+		Limit:    limit,
+	}, nil
+}
+
 func parseGetUsageRequest(r *http.Request) (*model.GetUsageParams, error) {
 	startTime, err := parseTime("start", r)
 	if err != nil {
@@ -778,6 +831,12 @@ func validateQueryRangeParamsV3(qp *v3.QueryRangeParamsV3) error {
 		return err
 	}
 
+	for name, q := range qp.CompositeQuery.TraceQLQueries {
+		if err := traceql.Validate(q.Query); err != nil {
+			return fmt.Errorf("invalid traceql query %s: %w", name, err)
+		}
+	}
+
 	var expressions []string
 	for _, q := range qp.CompositeQuery.BuilderQueries {
 		expressions = append(expressions, q.Expression)
@@ -789,6 +848,61 @@ func validateQueryRangeParamsV3(qp *v3.QueryRangeParamsV3) error {
 	return nil
 }
 
+// downsampleQueryRangeParams coarsens the step of the builder, PromQL and
+// TraceQL queries whose series would have more points than the budget. The
+// ClickHouse SQL queries group by their own intervals and are left as they
+// are. The v3 and v4 responses report the steps in their meta.
+func downsampleQueryRangeParams(queryRangeParams *v3.QueryRangeParamsV3) {
+	panelType := queryRangeParams.CompositeQuery.PanelType
+	if panelType == v3.PanelTypeList || panelType == v3.PanelTypeTrace {
+		return
+	}
+
+	maxPoints := baseconstants.GetQueryRangeMaxPointsPerSeries()
+	start, end := queryRangeParams.Start, queryRangeParams.End
+	switch queryRangeParams.CompositeQuery.QueryType {
+	case v3.QueryTypeBuilder:
+		for _, query := range queryRangeParams.CompositeQuery.BuilderQueries {
+			if step := common.DownsampledStep(start, end, query.StepInterval, maxPoints); step != query.StepInterval {
+				query.StepInterval = step
+				queryRangeParams.Downsampled = true
+			}
+		}
+	case v3.QueryTypePromQL, v3.QueryTypeTraceQL:
+		// the value panels run instant queries
+		if panelType == v3.PanelTypeValue {
+			return
+		}
+		if step := common.DownsampledStep(start, end, queryRangeParams.Step, maxPoints); step != queryRangeParams.Step {
+			queryRangeParams.Step = step
+			queryRangeParams.Downsampled = true
+		}
+	}
+}
+
+// queryRangeMeta returns the effective resolution of the queries of the query
+// range params
+func queryRangeMeta(queryRangeParams *v3.QueryRangeParamsV3) *v3.QueryRangeMeta {
+	meta := &v3.QueryRangeMeta{
+		Downsampled:        queryRangeParams.Downsampled,
+		MaxPointsPerSeries: baseconstants.GetQueryRangeMaxPointsPerSeries(),
+		StepIntervals:      map[string]int64{},
+	}
+	for name, query := range queryRangeParams.CompositeQuery.BuilderQueries {
+		meta.StepIntervals[name] = query.StepInterval
+	}
+	for name := range queryRangeParams.CompositeQuery.PromQueries {
+		meta.StepIntervals[name] = queryRangeParams.Step
+	}
+	for name := range queryRangeParams.CompositeQuery.ClickHouseQueries {
+		meta.StepIntervals[name] = queryRangeParams.Step
+	}
+	for name := range queryRangeParams.CompositeQuery.TraceQLQueries {
+		meta.StepIntervals[name] = queryRangeParams.Step
+	}
+	return meta
+}
+
 // validateExpressions validates the math expressions using the list of
 // allowed functions.
 func validateExpressions(expressions []string, funcs map[string]govaluate.ExpressionFunction, cq *v3.CompositeQuery) []error {
@@ -815,6 +929,23 @@ func validateExpressions(expressions []string, funcs map[string]govaluate.Expres
 	return errs
 }
 
+// validateJoin validates that the queries of the formula joining on the
+// shared keys are logs or traces queries grouped by the join keys
+func validateJoin(formula *v3.BuilderQuery, variables []string, queries map[string]*v3.BuilderQuery) error {
+	for _, v := range variables {
+		query := queries[v]
+		if query.DataSource != v3.DataSourceLogs && query.DataSource != v3.DataSourceTraces {
+			return fmt.Errorf("query %s can't be joined, only logs and traces queries can be joined", v)
+		}
+		for _, key := range formula.JoinOn {
+			if _, ok := key.GroupByKey(query); !ok {
+				return fmt.Errorf("query %s must be grouped by %s to be joined", v, key)
+			}
+		}
+	}
+	return nil
+}
+
 func ParseQueryRangeParams(r *http.Request) (*v3.QueryRangeParamsV3, *model.ApiError) {
 
 	var queryRangeParams *v3.QueryRangeParamsV3
@@ -824,9 +955,20 @@ func ParseQueryRangeParams(r *http.Request) (*v3.QueryRangeParamsV3, *model.ApiE
 		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("cannot parse the request body: %v", err)}
 	}
 
+	return PrepareQueryRangeParams(r.Context(), queryRangeParams)
+}
+
+// PrepareQueryRangeParams validates the query range params, expands the
+// macros and replaces the variables in the queries with their values
+func PrepareQueryRangeParams(ctx context.Context, queryRangeParams *v3.QueryRangeParamsV3) (*v3.QueryRangeParamsV3, *model.ApiError) {
+
 	// sanitize the request body
 	queryRangeParams.CompositeQuery.Sanitize()
 
+	if err := macros.Expand(ctx, queryRangeParams); err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+
 	// validate the request body
 	if err := validateQueryRangeParamsV3(queryRangeParams); err != nil {
 		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
@@ -869,18 +1011,25 @@ func ParseQueryRangeParams(r *http.Request) (*v3.QueryRangeParamsV3, *model.ApiE
 					}
 				}
 
-				params := make(map[string]interface{})
-				for k, v := range groupKeys {
-					params[k] = v
-				}
+				if len(query.JoinOn) > 0 {
+					// the joins match the rows on the join keys instead of the group keys
+					if err := validateJoin(query, expression.Vars(), queryRangeParams.CompositeQuery.BuilderQueries); err != nil {
+						return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+					}
+				} else {
+					params := make(map[string]interface{})
+					for k, v := range groupKeys {
+						params[k] = v
+					}
 
-				can, _, err := expression.CanJoin(params)
-				if err != nil {
-					return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
-				}
+					can, _, err := expression.CanJoin(params)
+					if err != nil {
+						return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+					}
 
-				if !can {
-					return nil, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("cannot join the given group keys")}
+					if !can {
+						return nil, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("cannot join the given group keys")}
+					}
 				}
 			}
 
@@ -938,6 +1087,8 @@ func ParseQueryRangeParams(r *http.Request) (*v3.QueryRangeParamsV3, *model.ApiE
 			}
 		}
 	}
+	downsampleQueryRangeParams(queryRangeParams)
+
 	queryRangeParams.Variables = formattedVars
 
 	// prometheus instant query needs same timestamp
@@ -952,6 +1103,7 @@ func ParseQueryRangeParams(r *http.Request) (*v3.QueryRangeParamsV3, *model.ApiE
 			if chQuery.Disabled {
 				continue
 			}
+			chQuery.Template = chQuery.Query
 
 			for name, value := range queryRangeParams.Variables {
 				chQuery.Query = strings.Replace(chQuery.Query, fmt.Sprintf("{{%s}}", name), fmt.Sprint(value), -1)
@@ -1037,3 +1189,43 @@ func ParseRequestBody(r *http.Request) (*thirdPartyApi.ThirdPartyApis, *model.Ap
 	}
 	return thirdPartApis, nil
 }
+
+// parseRuleStateHistoryRequest parses the query params of the state history
+// of a rule, the start and end are in milliseconds and default to the last day
+func parseRuleStateHistoryRequest(r *http.Request) (*model.QueryRuleStateHistory, error) {
+	query := r.URL.Query()
+	params := &model.QueryRuleStateHistory{
+		End:   time.Now().UnixMilli(),
+		State: query.Get("state"),
+		Order: query.Get("order"),
+		Limit: 100,
+	}
+	if params.Order == "" {
+		params.Order = "desc"
+	}
+
+	var err error
+	for name, value := range map[string]*int64{"end": &params.End, "start": &params.Start, "limit": &params.Limit, "offset": &params.Offset} {
+		if s := query.Get(name); s != "" {
+			if *value, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid %s: %s", name, s)
+			}
+		}
+	}
+	if params.Start == 0 {
+		params.Start = params.End - (24 * time.Hour).Milliseconds()
+	}
+	if params.Start > params.End {
+		return nil, fmt.Errorf("start must be before end")
+	}
+	switch params.State {
+	case "", model.StateInactive.String(), model.StatePending.String(), model.StateFiring.String(), model.StateNoData.String():
+	default:
+		return nil, fmt.Errorf("invalid state: %s", params.State)
+	}
+
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
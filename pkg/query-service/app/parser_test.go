@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/model"
 	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
 )
 
@@ -1541,3 +1542,142 @@ func TestParseQueryRangeParamsStepIntervalAdjustment(t *testing.T) {
 		})
 	}
 }
+
+func TestParseQueryRangeParamsDownsampling(t *testing.T) {
+	t.Setenv("QUERY_RANGE_MAX_POINTS_PER_SERIES", "100")
+	end := time.Now().UnixMilli()
+
+	reqCases := []struct {
+		desc        string
+		start       int64
+		queryType   v3.QueryType
+		step        int64
+		expected    int64
+		downsampled bool
+	}{
+		{
+			desc:      "promql query under the budget",
+			start:     end - time.Hour.Milliseconds(),
+			queryType: v3.QueryTypePromQL,
+			step:      60,
+			expected:  60,
+		},
+		{
+			desc:        "promql query over the budget",
+			start:       end - 24*time.Hour.Milliseconds(),
+			queryType:   v3.QueryTypePromQL,
+			step:        60,
+			expected:    900,
+			downsampled: true,
+		},
+		{
+			desc:        "builder query over the budget",
+			start:       end - 7*24*time.Hour.Milliseconds(),
+			queryType:   v3.QueryTypeBuilder,
+			step:        60,
+			expected:    7200,
+			downsampled: true,
+		},
+	}
+
+	for _, tc := range reqCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			queryRangeParams := &v3.QueryRangeParamsV3{
+				Start: tc.start,
+				End:   end,
+				Step:  tc.step,
+				CompositeQuery: &v3.CompositeQuery{
+					PanelType: v3.PanelTypeGraph,
+					QueryType: tc.queryType,
+				},
+				Variables: map[string]interface{}{},
+			}
+			if tc.queryType == v3.QueryTypePromQL {
+				queryRangeParams.CompositeQuery.PromQueries = map[string]*v3.PromQuery{
+					"A": {Query: "sum(rate(signoz_calls_total[5m]))"},
+				}
+			} else {
+				queryRangeParams.CompositeQuery.BuilderQueries = map[string]*v3.BuilderQuery{
+					"A": {
+						QueryName:          "A",
+						DataSource:         v3.DataSourceTraces,
+						AggregateOperator:  v3.AggregateOperatorCount,
+						AggregateAttribute: v3.AttributeKey{Key: "name"},
+						Expression:         "A",
+						StepInterval:       tc.step,
+					},
+				}
+			}
+
+			body := &bytes.Buffer{}
+			require.NoError(t, json.NewEncoder(body).Encode(queryRangeParams))
+			req := httptest.NewRequest(http.MethodPost, "/api/v4/query_range", body)
+
+			p, apiErr := ParseQueryRangeParams(req)
+			require.Nil(t, apiErr)
+			meta := queryRangeMeta(p)
+			require.Equal(t, tc.downsampled, meta.Downsampled)
+			require.Equal(t, int64(100), meta.MaxPointsPerSeries)
+			require.Equal(t, map[string]int64{"A": tc.expected}, meta.StepIntervals)
+		})
+	}
+}
+
+func TestParseRuleStateHistoryRequest(t *testing.T) {
+	reqCases := []struct {
+		desc        string
+		queryString string
+		expectErr   bool
+		check       func(t *testing.T, params *model.QueryRuleStateHistory)
+	}{
+		{
+			desc:        "defaults to the last day",
+			queryString: "",
+			check: func(t *testing.T, params *model.QueryRuleStateHistory) {
+				assert.Equal(t, (24 * time.Hour).Milliseconds(), params.End-params.Start)
+				assert.Equal(t, int64(100), params.Limit)
+				assert.Equal(t, "desc", params.Order)
+			},
+		},
+		{
+			desc:        "time range and state",
+			queryString: "start=1717200000000&end=1717205987000&state=pending&order=asc&limit=10&offset=20",
+			check: func(t *testing.T, params *model.QueryRuleStateHistory) {
+				assert.Equal(t, int64(1717200000000), params.Start)
+				assert.Equal(t, int64(1717205987000), params.End)
+				assert.Equal(t, int64(10), params.Limit)
+				assert.Equal(t, int64(20), params.Offset)
+				assert.Equal(t, "pending", params.State)
+				assert.Equal(t, "asc", params.Order)
+			},
+		},
+		{
+			desc:        "invalid state",
+			queryString: "state=firing'",
+			expectErr:   true,
+		},
+		{
+			desc:        "start after end",
+			queryString: "start=1717205987000&end=1717200000000",
+			expectErr:   true,
+		},
+		{
+			desc:        "invalid order",
+			queryString: "order=random",
+			expectErr:   true,
+		},
+	}
+
+	for _, reqCase := range reqCases {
+		t.Run(reqCase.desc, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/rules/1/history?"+reqCase.queryString, nil)
+			params, err := parseRuleStateHistoryRequest(r)
+			if reqCase.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			reqCase.check(t, params)
+		})
+	}
+}
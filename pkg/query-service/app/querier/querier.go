@@ -10,6 +10,7 @@ import (
 	logsV4 "go.signoz.io/signoz/pkg/query-service/app/logs/v4"
 	metricsV3 "go.signoz.io/signoz/pkg/query-service/app/metrics/v3"
 	"go.signoz.io/signoz/pkg/query-service/app/queryBuilder"
+	"go.signoz.io/signoz/pkg/query-service/app/traces/traceql"
 	tracesV3 "go.signoz.io/signoz/pkg/query-service/app/traces/v3"
 	tracesV4 "go.signoz.io/signoz/pkg/query-service/app/traces/v4"
 	"go.signoz.io/signoz/pkg/query-service/common"
@@ -17,6 +18,7 @@ import (
 	chErrors "go.signoz.io/signoz/pkg/query-service/errors"
 	"go.signoz.io/signoz/pkg/query-service/querycache"
 	"go.signoz.io/signoz/pkg/query-service/utils"
+	querytemplate "go.signoz.io/signoz/pkg/query-service/utils/queryTemplate"
 
 	"go.signoz.io/signoz/pkg/query-service/cache"
 	"go.signoz.io/signoz/pkg/query-service/interfaces"
@@ -280,6 +282,8 @@ func (q *querier) runPromQueries(ctx context.Context, params *v3.QueryRangeParam
 func (q *querier) runClickHouseQueries(ctx context.Context, params *v3.QueryRangeParamsV3) ([]*v3.Result, map[string]error, error) {
 	channelResults := make(chan channelResult, len(params.CompositeQuery.ClickHouseQueries))
 	var wg sync.WaitGroup
+	cacheKeys := q.keyGenerator.GenerateKeys(params)
+
 	for queryName, clickHouseQuery := range params.CompositeQuery.ClickHouseQueries {
 		if clickHouseQuery.Disabled {
 			continue
@@ -287,8 +291,41 @@ func (q *querier) runClickHouseQueries(ctx context.Context, params *v3.QueryRang
 		wg.Add(1)
 		go func(queryName string, clickHouseQuery *v3.ClickHouseQuery) {
 			defer wg.Done()
-			series, err := q.execClickHouseQuery(ctx, clickHouseQuery.Query)
-			channelResults <- channelResult{Err: err, Name: queryName, Query: clickHouseQuery.Query, Series: series}
+			cacheKey, ok := cacheKeys[queryName]
+
+			if !ok || params.NoCache {
+				zap.L().Info("skipping cache for clickhouse query", zap.String("queryName", queryName), zap.Int64("start", params.Start), zap.Int64("end", params.End), zap.Int64("step", params.Step), zap.Bool("noCache", params.NoCache), zap.String("cacheKey", cacheKeys[queryName]))
+				series, err := q.execClickHouseQuery(ctx, clickHouseQuery.Query)
+				channelResults <- channelResult{Err: err, Name: queryName, Query: clickHouseQuery.Query, Series: series}
+				return
+			}
+			misses := q.queryCache.FindMissingTimeRanges(params.Start, params.End, params.Step, cacheKey)
+			zap.L().Info("cache misses for clickhouse query", zap.Any("misses", misses))
+			missedSeries := make([]querycache.CachedSeriesData, 0)
+			for _, miss := range misses {
+				// the query is run from the start of the step the miss starts
+				// in, so that the partial point at the end of the cached data
+				// is replaced by the whole one
+				start := miss.Start - miss.Start%(params.Step*1000)
+				query, err := querytemplate.RenderForTimeRange(clickHouseQuery.Template, params.Variables, start, miss.End)
+				if err != nil {
+					channelResults <- channelResult{Err: err, Name: queryName, Query: clickHouseQuery.Template, Series: nil}
+					return
+				}
+				series, err := q.execClickHouseQuery(ctx, query)
+				if err != nil {
+					channelResults <- channelResult{Err: err, Name: queryName, Query: query, Series: nil}
+					return
+				}
+				missedSeries = append(missedSeries, querycache.CachedSeriesData{
+					Data:  series,
+					Start: miss.Start,
+					End:   miss.End,
+				})
+			}
+			mergedSeries := q.queryCache.MergeWithCachedSeriesData(cacheKey, missedSeries)
+			resultSeries := common.GetSeriesFromCachedData(mergedSeries, params.Start, params.End)
+			channelResults <- channelResult{Err: nil, Name: queryName, Query: clickHouseQuery.Query, Series: resultSeries}
 		}(queryName, clickHouseQuery)
 	}
 	wg.Wait()
@@ -317,6 +354,65 @@ func (q *querier) runClickHouseQueries(ctx context.Context, params *v3.QueryRang
 	return results, errQueriesByName, err
 }
 
+// runTraceQLQueries compiles the TraceQL queries to ClickHouse SQL and runs
+// them, the list panels return the matching spans
+func (q *querier) runTraceQLQueries(ctx context.Context, params *v3.QueryRangeParamsV3) ([]*v3.Result, map[string]error, error) {
+	channelResults := make(chan channelResult, len(params.CompositeQuery.TraceQLQueries))
+	var wg sync.WaitGroup
+
+	for queryName, traceQLQuery := range params.CompositeQuery.TraceQLQueries {
+		if traceQLQuery.Disabled {
+			continue
+		}
+		wg.Add(1)
+		go func(queryName string, traceQLQuery *v3.TraceQLQuery) {
+			defer wg.Done()
+			query, err := traceql.PrepareQuery(params.Start, params.End, params.Step, params.CompositeQuery.PanelType, traceQLQuery, q.UseTraceNewSchema)
+			if err != nil {
+				channelResults <- channelResult{Err: err, Name: queryName, Query: traceQLQuery.Query}
+				return
+			}
+			if params.CompositeQuery.PanelType == v3.PanelTypeList {
+				if q.testingMode && q.reader == nil {
+					q.queriesExecuted = append(q.queriesExecuted, query)
+					channelResults <- channelResult{Name: queryName, Query: query}
+					return
+				}
+				rowList, err := q.reader.GetListResultV3(ctx, query)
+				channelResults <- channelResult{Err: err, Name: queryName, Query: query, List: rowList}
+				return
+			}
+			series, err := q.execClickHouseQuery(ctx, query)
+			channelResults <- channelResult{Err: err, Name: queryName, Query: query, Series: series}
+		}(queryName, traceQLQuery)
+	}
+	wg.Wait()
+	close(channelResults)
+
+	results := make([]*v3.Result, 0)
+	errQueriesByName := make(map[string]error)
+	var errs []error
+
+	for result := range channelResults {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+			errQueriesByName[result.Name] = result.Err
+			continue
+		}
+		results = append(results, &v3.Result{
+			QueryName: result.Name,
+			Series:    result.Series,
+			List:      result.List,
+		})
+	}
+
+	var err error
+	if len(errs) > 0 {
+		err = fmt.Errorf("error in traceql queries")
+	}
+	return results, errQueriesByName, err
+}
+
 func (q *querier) runWindowBasedListQuery(ctx context.Context, params *v3.QueryRangeParamsV3, tsRanges []utils.LogsListTsRange) ([]*v3.Result, map[string]error, error) {
 	res := make([]*v3.Result, 0)
 	qName := ""
@@ -548,6 +644,8 @@ func (q *querier) QueryRange(ctx context.Context, params *v3.QueryRangeParamsV3)
 			} else {
 				results, errQueriesByName, err = q.runClickHouseQueries(ctx, params)
 			}
+		case v3.QueryTypeTraceQL:
+			results, errQueriesByName, err = q.runTraceQLQueries(ctx, params)
 		default:
 			err = fmt.Errorf("invalid query type")
 		}
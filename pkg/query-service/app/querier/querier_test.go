@@ -1434,3 +1434,37 @@ func Test_querier_runWindowBasedListQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryRangeTraceQL(t *testing.T) {
+	params := &v3.QueryRangeParamsV3{
+		Start: 1675115596722,
+		End:   1675115596722 + 120*60*1000,
+		Step:  60,
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType: v3.QueryTypeTraceQL,
+			PanelType: v3.PanelTypeList,
+			TraceQLQueries: map[string]*v3.TraceQLQuery{
+				"A": {Query: `{ status = error }`},
+			},
+		},
+	}
+
+	for _, useTraceNewSchema := range []bool{false, true} {
+		q := NewQuerier(QuerierOptions{
+			Reader:            nil,
+			FluxInterval:      5 * time.Minute,
+			KeyGenerator:      queryBuilder.NewKeyGenerator(),
+			TestingMode:       true,
+			UseTraceNewSchema: useTraceNewSchema,
+		})
+		_, errByName, err := q.QueryRange(context.Background(), params)
+		require.NoError(t, err)
+		require.Empty(t, errByName)
+		require.Len(t, q.QueriesExecuted(), 1)
+		table := "signoz_traces.distributed_signoz_index_v2"
+		if useTraceNewSchema {
+			table = "signoz_traces.distributed_signoz_index_v3"
+		}
+		require.Contains(t, q.QueriesExecuted()[0], "FROM "+table+" WHERE")
+	}
+}
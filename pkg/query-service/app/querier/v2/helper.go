@@ -272,3 +272,27 @@ func (q *querier) runBuilderQuery(
 		Series: resultSeries,
 	}
 }
+
+// runBuilderJoin runs the formula joining the queries on the shared keys,
+// unlike the other formulas it is executed in ClickHouse
+func (q *querier) runBuilderJoin(
+	ctx context.Context,
+	builderQuery *v3.BuilderQuery,
+	params *v3.QueryRangeParamsV3,
+	ch chan channelResult,
+	wg *sync.WaitGroup,
+) {
+	defer wg.Done()
+
+	queryName := builderQuery.QueryName
+
+	queries, err := q.builder.PrepareQueries(params)
+	if err != nil {
+		ch <- channelResult{Err: err, Name: queryName, Query: "", Series: nil}
+		return
+	}
+
+	query := queries[queryName]
+	series, err := q.execClickHouseQuery(ctx, query)
+	ch <- channelResult{Err: err, Name: queryName, Query: query, Series: series}
+}
@@ -1185,6 +1185,83 @@ func TestV2QueryRangeValueTypePromQL(t *testing.T) {
 	}
 }
 
+func TestV2QueryRangeClickHouseWithCache(t *testing.T) {
+	start := int64(1675115596722)
+	template := "SELECT toStartOfInterval(timestamp, INTERVAL 1 MINUTE) AS ts, count() AS value FROM signoz_logs.distributed_logs_v2 " +
+		"WHERE timestamp BETWEEN {{.start_timestamp_ms}} AND {{.end_timestamp_ms}} AND severity_text = '{{.severity}}' GROUP BY ts"
+	params := []*v3.QueryRangeParamsV3{
+		{
+			Start:     start,
+			End:       start + 120*60*1000,
+			Step:      60,
+			Variables: map[string]interface{}{"severity": "ERROR"},
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeClickHouseSQL,
+				PanelType: v3.PanelTypeGraph,
+				ClickHouseQueries: map[string]*v3.ClickHouseQuery{
+					"A": {Query: "rendered for the whole range", Template: template},
+				},
+			},
+		},
+		{
+			Start:     start + 60*60*1000,
+			End:       start + 180*60*1000,
+			Step:      60,
+			Variables: map[string]interface{}{"severity": "ERROR"},
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeClickHouseSQL,
+				PanelType: v3.PanelTypeGraph,
+				ClickHouseQueries: map[string]*v3.ClickHouseQuery{
+					"A": {Query: "rendered for the whole range", Template: template},
+				},
+			},
+		},
+	}
+	cache := inmemory.New(&inmemory.Options{TTL: 60 * time.Minute, CleanupInterval: 10 * time.Minute})
+	opts := QuerierOptions{
+		Cache:        cache,
+		Reader:       nil,
+		FluxInterval: 5 * time.Minute,
+		KeyGenerator: queryBuilder.NewKeyGenerator(),
+
+		TestingMode: true,
+		ReturnedSeries: []*v3.Series{
+			{
+				Labels: map[string]string{},
+				Points: []v3.Point{
+					{Timestamp: start + 60*60*1000, Value: 1},
+					{Timestamp: start + 150*60*1000, Value: 2},
+				},
+			},
+		},
+	}
+	q := NewQuerier(opts)
+
+	// only the time range missing in the cache is queried, from the start of
+	// the step it starts in
+	alignedStart := func(ts int64) int64 { return ts - ts%60000 }
+	expectedQueries := []string{
+		fmt.Sprintf("BETWEEN %d AND %d AND severity_text = 'ERROR'", alignedStart(start), start+120*60*1000),
+		fmt.Sprintf("BETWEEN %d AND %d AND severity_text = 'ERROR'", alignedStart(start+120*60*1000), start+180*60*1000),
+	}
+
+	for i, param := range params {
+		res, errByName, err := q.QueryRange(context.Background(), param)
+		require.NoError(t, err)
+		require.Empty(t, errByName)
+		require.Len(t, q.QueriesExecuted(), i+1)
+		require.Contains(t, q.QueriesExecuted()[i], expectedQueries[i])
+		require.Len(t, res, 1)
+		require.Len(t, res[0].Series, 1)
+	}
+
+	// the queries which are not rendered for the time range are not cached
+	params[0].CompositeQuery.ClickHouseQueries["A"].Template = "SELECT now() AS ts, count() AS value FROM signoz_logs.distributed_logs_v2"
+	_, _, err := q.QueryRange(context.Background(), params[0])
+	require.NoError(t, err)
+	require.Equal(t, "rendered for the whole range", q.QueriesExecuted()[2])
+}
+
 type regexMatcher struct {
 }
 
@@ -0,0 +1,114 @@
+package queryBuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SigNoz/govaluate"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+// joinExpressionToQuery constructs the query for the formula joining the
+// queries on its join keys. The rows of the queries are matched on the
+// group by keys matching the join keys, and the other group by keys of the
+// queries are kept. When the formula has a group by, the joined rows are
+// aggregated by it with the aggregate operator of the formula.
+//
+// For example, the formula A * B joining on trace_id the count of the error
+// logs grouped by trace_id (A) and the average duration of the spans grouped
+// by traceID and http.route (B) and grouped by http.route gives the error
+// logs per endpoint weighted by the span duration.
+func joinExpressionToQuery(
+	qp *v3.QueryRangeParamsV3,
+	varToQuery map[string]string,
+	expression *govaluate.EvaluableExpression,
+	queryName string,
+) (string, error) {
+	formulaQuery := qp.CompositeQuery.BuilderQueries[queryName]
+	variables := unique(expression.Vars())
+	formula := valueFormula(expression)
+	withTs := qp.CompositeQuery.PanelType != v3.PanelTypeTable
+
+	first := variables[0]
+	firstKeys := map[v3.JoinKey]string{}
+	columns := []string{}
+	tags := map[string]bool{}
+	var from string
+	for idx, variable := range variables {
+		query := qp.CompositeQuery.BuilderQueries[variable]
+
+		joinGroupBy := map[string]bool{}
+		conditions := []string{}
+		for _, key := range formulaQuery.JoinOn {
+			groupByKey, ok := key.GroupByKey(query)
+			if !ok {
+				return "", fmt.Errorf("query %s must be grouped by %s to be joined", variable, key)
+			}
+			joinGroupBy[groupByKey] = true
+			if idx == 0 {
+				firstKeys[key] = groupByKey
+				columns = append(columns, fmt.Sprintf("%s.`%s` as `%s`", variable, groupByKey, key))
+				tags[string(key)] = true
+				continue
+			}
+			conditions = append(conditions, fmt.Sprintf("%s.`%s` = %s.`%s`", first, firstKeys[key], variable, groupByKey))
+		}
+
+		// the other group by keys of the queries are kept as they are
+		for _, groupBy := range query.GroupBy {
+			if joinGroupBy[groupBy.Key] || tags[groupBy.Key] {
+				continue
+			}
+			columns = append(columns, fmt.Sprintf("%s.`%s` as `%s`", variable, groupBy.Key, groupBy.Key))
+			tags[groupBy.Key] = true
+		}
+
+		if idx == 0 {
+			from = fmt.Sprintf("(%s) as %s", varToQuery[variable], variable)
+			continue
+		}
+		if withTs {
+			conditions = append(conditions, fmt.Sprintf("%s.ts = %s.ts", first, variable))
+		}
+		from += fmt.Sprintf(" INNER JOIN (%s) as %s ON %s", varToQuery[variable], variable, strings.Join(conditions, " AND "))
+	}
+	if withTs {
+		columns = append(columns, fmt.Sprintf("%s.ts as ts", first))
+	}
+
+	joinQuery := fmt.Sprintf("SELECT %s, %s as value FROM %s", strings.Join(columns, ", "), formula.ExpressionString(), from)
+
+	conditions := []string{}
+	for _, having := range formulaQuery.Having {
+		conditions = append(conditions, fmt.Sprintf("%s %s %v", "value", having.Operator, having.Value))
+	}
+
+	if len(formulaQuery.GroupBy) == 0 {
+		if len(conditions) > 0 {
+			joinQuery += " WHERE " + strings.Join(conditions, " AND ")
+		}
+		return joinQuery, nil
+	}
+
+	groupTags := []string{}
+	for _, groupBy := range formulaQuery.GroupBy {
+		if !tags[groupBy.Key] {
+			return "", fmt.Errorf("group by key %s is not a key of the joined queries", groupBy.Key)
+		}
+		groupTags = append(groupTags, fmt.Sprintf("`%s`", groupBy.Key))
+	}
+	if withTs {
+		groupTags = append(groupTags, "ts")
+	}
+
+	aggregateOperator := formulaQuery.AggregateOperator
+	if aggregateOperator == "" || aggregateOperator == v3.AggregateOperatorNoOp {
+		aggregateOperator = v3.AggregateOperatorSum
+	}
+	groupBy := strings.Join(groupTags, ", ")
+	aggregateQuery := fmt.Sprintf("SELECT %s, %s(value) as value FROM (%s) GROUP BY %s", groupBy, aggregateOperator, joinQuery, groupBy)
+	if len(conditions) > 0 {
+		aggregateQuery += " HAVING " + strings.Join(conditions, " AND ")
+	}
+	return aggregateQuery, nil
+}
@@ -0,0 +1,90 @@
+package queryBuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/featureManager"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func TestJoinLogsAndTraces(t *testing.T) {
+	qbOptions := QueryBuilderOptions{
+		BuildLogQuery: func(start, end int64, queryType v3.QueryType, panelType v3.PanelType, bq *v3.BuilderQuery, options v3.QBOptions) (string, error) {
+			return "logs query", nil
+		},
+		BuildTraceQuery: func(start, end int64, panelType v3.PanelType, bq *v3.BuilderQuery, options v3.QBOptions) (string, error) {
+			return "traces query", nil
+		},
+	}
+	qb := NewQueryBuilder(qbOptions, featureManager.StartManager())
+
+	params := func() *v3.QueryRangeParamsV3 {
+		return &v3.QueryRangeParamsV3{
+			Start: 1650991982000,
+			End:   1651078382000,
+			CompositeQuery: &v3.CompositeQuery{
+				PanelType: v3.PanelTypeGraph,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {
+						QueryName:         "A",
+						StepInterval:      60,
+						DataSource:        v3.DataSourceLogs,
+						AggregateOperator: v3.AggregateOperatorCount,
+						GroupBy:           []v3.AttributeKey{{Key: "trace_id", IsColumn: true}},
+						Expression:        "A",
+						Disabled:          true,
+					},
+					"B": {
+						QueryName:          "B",
+						StepInterval:       60,
+						DataSource:         v3.DataSourceTraces,
+						AggregateOperator:  v3.AggregateOperatorAvg,
+						AggregateAttribute: v3.AttributeKey{Key: "durationNano", IsColumn: true},
+						GroupBy:            []v3.AttributeKey{{Key: "traceID", IsColumn: true}, {Key: "http.route"}},
+						Expression:         "B",
+						Disabled:           true,
+					},
+					"C": {
+						QueryName:  "C",
+						Expression: "A * B",
+						JoinOn:     []v3.JoinKey{v3.JoinKeyTraceID},
+					},
+				},
+			},
+		}
+	}
+
+	q := params()
+	queries, err := qb.PrepareQueries(q)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT A.`trace_id` as `trace_id`, B.`http.route` as `http.route`, A.ts as ts, A.value * B.value as value "+
+		"FROM (logs query) as A INNER JOIN (traces query) as B ON A.`trace_id` = B.`traceID` AND A.ts = B.ts", queries["C"])
+	require.NotContains(t, NewKeyGenerator().GenerateKeys(q), "C")
+
+	// the joined rows are aggregated by the group by of the formula
+	q = params()
+	q.CompositeQuery.BuilderQueries["C"].GroupBy = []v3.AttributeKey{{Key: "http.route"}}
+	q.CompositeQuery.BuilderQueries["C"].Having = []v3.Having{{ColumnName: "value", Operator: ">", Value: 10}}
+	queries, err = qb.PrepareQueries(q)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT `http.route`, ts, sum(value) as value FROM (SELECT A.`trace_id` as `trace_id`, B.`http.route` as `http.route`, A.ts as ts, A.value * B.value as value "+
+		"FROM (logs query) as A INNER JOIN (traces query) as B ON A.`trace_id` = B.`traceID` AND A.ts = B.ts) GROUP BY `http.route`, ts HAVING value > 10", queries["C"])
+
+	q = params()
+	q.CompositeQuery.BuilderQueries["C"].GroupBy = []v3.AttributeKey{{Key: "k8s.pod.name"}}
+	_, err = qb.PrepareQueries(q)
+	require.Error(t, err)
+
+	q = params()
+	q.CompositeQuery.BuilderQueries["C"].JoinOn = []v3.JoinKey{v3.JoinKeyServiceName}
+	_, err = qb.PrepareQueries(q)
+	require.Error(t, err)
+
+	q = params()
+	q.CompositeQuery.BuilderQueries["C"].JoinOn = []v3.JoinKey{"span_id"}
+	require.Error(t, q.CompositeQuery.Validate())
+	q.CompositeQuery.BuilderQueries["A"].JoinOn = []v3.JoinKey{v3.JoinKeyTraceID}
+	q.CompositeQuery.BuilderQueries["C"].JoinOn = []v3.JoinKey{v3.JoinKeyTraceID}
+	require.Error(t, q.CompositeQuery.Validate())
+}
@@ -2,6 +2,7 @@ package queryBuilder
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/SigNoz/govaluate"
@@ -10,6 +11,7 @@ import (
 	"go.signoz.io/signoz/pkg/query-service/constants"
 	"go.signoz.io/signoz/pkg/query-service/interfaces"
 	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	querytemplate "go.signoz.io/signoz/pkg/query-service/utils/queryTemplate"
 	"go.uber.org/zap"
 )
 
@@ -84,16 +86,9 @@ func unique(slice []string) []string {
 	return list
 }
 
-// expressionToQuery constructs the query for the expression
-func expressionToQuery(
-	qp *v3.QueryRangeParamsV3,
-	varToQuery map[string]string,
-	expression *govaluate.EvaluableExpression,
-	queryName string,
-) (string, error) {
-	var formulaQuery string
-	variables := unique(expression.Vars())
-
+// valueFormula returns the expression with the variables replaced by the
+// value columns of their subqueries
+func valueFormula(expression *govaluate.EvaluableExpression) *govaluate.EvaluableExpression {
 	var modified []govaluate.ExpressionToken
 	tokens := expression.Tokens()
 	for idx := range tokens {
@@ -106,6 +101,19 @@ func expressionToQuery(
 	}
 	// err should be nil here since the expression is already validated
 	formula, _ := govaluate.NewEvaluableExpressionFromTokens(modified)
+	return formula
+}
+
+// expressionToQuery constructs the query for the expression
+func expressionToQuery(
+	qp *v3.QueryRangeParamsV3,
+	varToQuery map[string]string,
+	expression *govaluate.EvaluableExpression,
+	queryName string,
+) (string, error) {
+	var formulaQuery string
+	variables := unique(expression.Vars())
+	formula := valueFormula(expression)
 
 	var formulaSubQuery string
 	var joinUsing string
@@ -256,7 +264,12 @@ func (qb *QueryBuilder) PrepareQueries(params *v3.QueryRangeParamsV3) (map[strin
 					return nil, err
 				}
 
-				queryString, err := expressionToQuery(params, queries, expression, query.QueryName)
+				var queryString string
+				if len(query.JoinOn) > 0 {
+					queryString, err = joinExpressionToQuery(params, queries, expression, query.QueryName)
+				} else {
+					queryString, err = expressionToQuery(params, queries, expression, query.QueryName)
+				}
 				if err != nil {
 					return nil, err
 				}
@@ -315,6 +328,12 @@ func isLogExpression(expression *govaluate.EvaluableExpression, params *v3.Query
 	return true
 }
 
+// normalizeQuery collapses the whitespace in the query so that the queries
+// differing only in formatting share the cache
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
 func (c *cacheKeyGenerator) GenerateKeys(params *v3.QueryRangeParamsV3) map[string]string {
 	keys := make(map[string]string)
 
@@ -325,7 +344,38 @@ func (c *cacheKeyGenerator) GenerateKeys(params *v3.QueryRangeParamsV3) map[stri
 		}
 
 		for name, query := range params.CompositeQuery.PromQueries {
-			keys[name] = query.Query
+			keys[name] = fmt.Sprintf("step=%d&query=%s", params.Step, normalizeQuery(query.Query))
+		}
+		return keys
+	}
+
+	// Use the query template as the cache key for ClickHouse queries which
+	// are rendered for the time range, their results for the parts of the
+	// range put together are the result for the whole range
+	if params.CompositeQuery.QueryType == v3.QueryTypeClickHouseSQL {
+		if params.CompositeQuery.PanelType != v3.PanelTypeGraph || params.Step <= 0 {
+			return keys
+		}
+
+		var variables []string
+		for name, value := range params.Variables {
+			if querytemplate.IsReservedVar(name) {
+				continue
+			}
+			variables = append(variables, fmt.Sprintf("var-%s=%v", name, value))
+		}
+		sort.Strings(variables)
+
+		for name, query := range params.CompositeQuery.ClickHouseQueries {
+			if query.Template == "" || !querytemplate.UsesTimeRange(query.Template) {
+				continue
+			}
+			parts := []string{
+				fmt.Sprintf("source=%s", v3.QueryTypeClickHouseSQL),
+				fmt.Sprintf("step=%d", params.Step),
+				fmt.Sprintf("query=%s", normalizeQuery(query.Template)),
+			}
+			keys[name] = strings.Join(append(parts, variables...), "&")
 		}
 		return keys
 	}
@@ -441,9 +491,9 @@ func (c *cacheKeyGenerator) GenerateKeys(params *v3.QueryRangeParamsV3) map[stri
 		}
 	}
 
-	// Build keys for each expression
+	// Build keys for each expression, the joins are not cached
 	for _, query := range params.CompositeQuery.BuilderQueries {
-		if query.Expression != query.QueryName {
+		if query.Expression != query.QueryName && len(query.JoinOn) == 0 {
 			if params.Version != "v4" && params.CompositeQuery.PanelType != v3.PanelTypeGraph {
 				continue
 			}
@@ -1611,6 +1611,7 @@ func TestGenerateCacheKeysMetricsPromQL(t *testing.T) {
 		{
 			name: "panelType=graph;dataSource=metrics;queryType=promql",
 			query: &v3.QueryRangeParamsV3{
+				Step: 60,
 				CompositeQuery: &v3.CompositeQuery{
 					PanelType: v3.PanelTypeGraph,
 					QueryType: v3.QueryTypePromQL,
@@ -1622,24 +1623,25 @@ func TestGenerateCacheKeysMetricsPromQL(t *testing.T) {
 				},
 			},
 			expectedCacheKeys: map[string]string{
-				"A": "signoz_latency_bucket",
+				"A": "step=60&query=signoz_latency_bucket",
 			},
 		},
 		{
 			name: "panelType=graph;dataSource=metrics;queryType=promql",
 			query: &v3.QueryRangeParamsV3{
+				Step: 60,
 				CompositeQuery: &v3.CompositeQuery{
 					PanelType: v3.PanelTypeGraph,
 					QueryType: v3.QueryTypePromQL,
 					PromQueries: map[string]*v3.PromQuery{
 						"A": {
-							Query: "histogram_quantile(0.9, sum(rate(signoz_latency_bucket[1m])) by (le))",
+							Query: "histogram_quantile(0.9,\n  sum(rate(signoz_latency_bucket[1m])) by (le))",
 						},
 					},
 				},
 			},
 			expectedCacheKeys: map[string]string{
-				"A": "histogram_quantile(0.9, sum(rate(signoz_latency_bucket[1m])) by (le))",
+				"A": "step=60&query=histogram_quantile(0.9, sum(rate(signoz_latency_bucket[1m])) by (le))",
 			},
 		},
 		{
@@ -1667,3 +1669,55 @@ func TestGenerateCacheKeysMetricsPromQL(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateCacheKeysClickHouse(t *testing.T) {
+	query := "SELECT toStartOfInterval(timestamp, INTERVAL 1 MINUTE) AS ts, count() AS value FROM signoz_logs.distributed_logs_v2\n" +
+		"WHERE timestamp BETWEEN {{.start_timestamp_nano}} AND {{.end_timestamp_nano}} AND severity_text = '{{.severity}}' GROUP BY ts"
+	testCases := []struct {
+		name              string
+		query             *v3.QueryRangeParamsV3
+		expectedCacheKeys map[string]string
+	}{
+		{
+			name: "panelType=graph;queryType=clickhouse_sql",
+			query: &v3.QueryRangeParamsV3{
+				Step:      60,
+				Variables: map[string]interface{}{"severity": "ERROR", "start_timestamp_nano": 1675115596722000000},
+				CompositeQuery: &v3.CompositeQuery{
+					PanelType: v3.PanelTypeGraph,
+					QueryType: v3.QueryTypeClickHouseSQL,
+					ClickHouseQueries: map[string]*v3.ClickHouseQuery{
+						"A": {Template: query},
+						// the result of the query is the same for any time range
+						"B": {Template: "SELECT now() AS ts, count() AS value FROM signoz_logs.distributed_logs_v2"},
+					},
+				},
+			},
+			expectedCacheKeys: map[string]string{
+				"A": "source=clickhouse_sql&step=60&query=" + normalizeQuery(query) + "&var-severity=ERROR",
+			},
+		},
+		{
+			name: "panelType=table;queryType=clickhouse_sql",
+			query: &v3.QueryRangeParamsV3{
+				Step: 60,
+				CompositeQuery: &v3.CompositeQuery{
+					PanelType: v3.PanelTypeTable,
+					QueryType: v3.QueryTypeClickHouseSQL,
+					ClickHouseQueries: map[string]*v3.ClickHouseQuery{
+						"A": {Template: query},
+					},
+				},
+			},
+			expectedCacheKeys: map[string]string{},
+		},
+	}
+
+	keyGen := NewKeyGenerator()
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cacheKeys := keyGen.GenerateKeys(test.query)
+			require.Equal(t, test.expectedCacheKeys, cacheKeys)
+		})
+	}
+}
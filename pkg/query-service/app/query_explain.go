@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	logsv3 "go.signoz.io/signoz/pkg/query-service/app/logs/v3"
+	logsv4 "go.signoz.io/signoz/pkg/query-service/app/logs/v4"
+	metricsv4 "go.signoz.io/signoz/pkg/query-service/app/metrics/v4"
+	"go.signoz.io/signoz/pkg/query-service/app/queryBuilder"
+	"go.signoz.io/signoz/pkg/query-service/app/traces/traceql"
+	tracesV3 "go.signoz.io/signoz/pkg/query-service/app/traces/v3"
+	tracesV4 "go.signoz.io/signoz/pkg/query-service/app/traces/v4"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.uber.org/zap"
+)
+
+// timeFilterRe matches the queries filtering on any of the time columns of
+// the signoz tables
+var timeFilterRe = regexp.MustCompile(`(?is)\bwhere\b.*\b(timestamp|unix_milli|ts_bucket_start|time_unix)\b`)
+
+// explainQueryRange returns the ClickHouse SQL the query range params run,
+// with the estimate of the data it reads and the warnings about it, without
+// running it
+func (aH *APIHandler) explainQueryRange(w http.ResponseWriter, r *http.Request) {
+	queryRangeParams, apiErrorObj := ParseQueryRangeParams(r)
+	if apiErrorObj != nil {
+		zap.L().Error("error parsing query range params", zap.Error(apiErrorObj.Err))
+		RespondError(w, apiErrorObj, nil)
+		return
+	}
+	queryRangeParams.Version = "v4"
+
+	if err := aH.PopulateTemporality(r.Context(), queryRangeParams); err != nil {
+		zap.L().Error("Error while adding temporality for metrics", zap.Error(err))
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	if apiErr := aH.enrichQueryRangeV4(r.Context(), queryRangeParams); apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+
+	explanations, apiErr := aH.explainQueries(r.Context(), queryRangeParams)
+	if apiErr != nil {
+		RespondError(w, apiErr, nil)
+		return
+	}
+	aH.Respond(w, v3.QueryRangeExplainResponse{Queries: explanations})
+}
+
+// explainQueries explains each of the enabled queries of the query range
+// params, sorted by name
+func (aH *APIHandler) explainQueries(ctx context.Context, queryRangeParams *v3.QueryRangeParamsV3) ([]*v3.QueryExplanation, *model.ApiError) {
+	explanations := []*v3.QueryExplanation{}
+	queries := map[string]string{}
+
+	switch queryRangeParams.CompositeQuery.QueryType {
+	case v3.QueryTypeBuilder:
+		prepared, err := aH.queryBuilderV4().PrepareQueries(queryRangeParams)
+		if err != nil {
+			return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+		}
+		queries = prepared
+	case v3.QueryTypeClickHouseSQL:
+		for name, query := range queryRangeParams.CompositeQuery.ClickHouseQueries {
+			if !query.Disabled {
+				queries[name] = query.Query
+			}
+		}
+	case v3.QueryTypeTraceQL:
+		for name, query := range queryRangeParams.CompositeQuery.TraceQLQueries {
+			if query.Disabled {
+				continue
+			}
+			prepared, err := traceql.PrepareQuery(queryRangeParams.Start, queryRangeParams.End, queryRangeParams.Step, queryRangeParams.CompositeQuery.PanelType, query, aH.UseTraceNewSchema)
+			if err != nil {
+				return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+			}
+			queries[name] = prepared
+		}
+	case v3.QueryTypePromQL:
+		for name, query := range queryRangeParams.CompositeQuery.PromQueries {
+			if query.Disabled {
+				continue
+			}
+			explanations = append(explanations, &v3.QueryExplanation{
+				QueryName: name,
+				Query:     query.Query,
+				Estimates: []v3.QueryEstimate{},
+				Warnings:  []string{"PromQL queries are evaluated on the series read from ClickHouse, the data they read is not estimated"},
+			})
+		}
+	}
+
+	for name, query := range queries {
+		explanation := &v3.QueryExplanation{
+			QueryName: name,
+			Query:     query,
+			Estimates: []v3.QueryEstimate{},
+			Warnings:  queryWarnings(query),
+		}
+		estimates, err := aH.reader.EstimateQuery(ctx, query)
+		if err != nil {
+			zap.L().Error("error estimating the query", zap.String("queryName", name), zap.Error(err))
+			explanation.Warnings = append(explanation.Warnings, fmt.Sprintf("the data read by the query could not be estimated: %v", err))
+		} else {
+			explanation.Estimates = estimates
+			explanation.Warnings = append(explanation.Warnings, estimateWarnings(estimates)...)
+		}
+		for _, estimate := range explanation.Estimates {
+			explanation.Rows += estimate.Rows
+			explanation.Bytes += estimate.Bytes
+		}
+		explanations = append(explanations, explanation)
+	}
+
+	sort.Slice(explanations, func(i, j int) bool {
+		return explanations[i].QueryName < explanations[j].QueryName
+	})
+	return explanations, nil
+}
+
+// queryBuilderV4 returns the query builder preparing the builder queries the
+// way the v4 query range runs them
+func (aH *APIHandler) queryBuilderV4() *queryBuilder.QueryBuilder {
+	logsQueryBuilder := logsv3.PrepareLogsQuery
+	if aH.UseLogsNewSchema {
+		logsQueryBuilder = logsv4.PrepareLogsQuery
+	}
+	tracesQueryBuilder := tracesV3.PrepareTracesQuery
+	if aH.UseTraceNewSchema {
+		tracesQueryBuilder = tracesV4.PrepareTracesQuery
+	}
+
+	return queryBuilder.NewQueryBuilder(queryBuilder.QueryBuilderOptions{
+		BuildMetricQuery: metricsv4.PrepareMetricQuery,
+		BuildTraceQuery:  tracesQueryBuilder,
+		BuildLogQuery:    logsQueryBuilder,
+	}, aH.featureFlags)
+}
+
+// queryWarnings returns the warnings about the SQL of the query
+func queryWarnings(query string) []string {
+	warnings := []string{}
+	if !timeFilterRe.MatchString(query) {
+		warnings = append(warnings, "the query does not filter on the time column, it reads the data of all time")
+	}
+	return warnings
+}
+
+// estimateWarnings returns the warnings about the data the query reads
+func estimateWarnings(estimates []v3.QueryEstimate) []string {
+	warnings := []string{}
+	for _, estimate := range estimates {
+		if estimate.TotalRows > 0 && estimate.Rows >= estimate.TotalRows {
+			warnings = append(warnings, fmt.Sprintf("the query reads all the %d rows of %s.%s, the filters do not use its primary key or partitions", estimate.TotalRows, estimate.Database, estimate.Table))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func TestQueryWarnings(t *testing.T) {
+	assert.Empty(t, queryWarnings("SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE timestamp >= 1675115596722000000 AND severity_text = 'ERROR'"))
+	assert.Empty(t, queryWarnings("SELECT count() FROM signoz_traces.distributed_signoz_index_v3\nwhere ts_bucket_start >= 1675115596"))
+	assert.Len(t, queryWarnings("SELECT count() FROM signoz_logs.distributed_logs_v2 WHERE severity_text = 'ERROR'"), 1)
+	assert.Len(t, queryWarnings("SELECT max(timestamp) FROM signoz_logs.distributed_logs_v2"), 1)
+}
+
+func TestEstimateWarnings(t *testing.T) {
+	warnings := estimateWarnings([]v3.QueryEstimate{
+		{Database: "signoz_logs", Table: "logs_v2", Rows: 8192, TotalRows: 1000000},
+		{Database: "signoz_logs", Table: "logs_v2_resource", Rows: 4096, TotalRows: 4096},
+		{Database: "signoz_logs", Table: "tag_attributes", Rows: 0, TotalRows: 0},
+	})
+	assert.Equal(t, []string{"the query reads all the 4096 rows of signoz_logs.logs_v2_resource, the filters do not use its primary key or partitions"}, warnings)
+}
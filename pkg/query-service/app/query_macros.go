@@ -0,0 +1,108 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.signoz.io/signoz/pkg/query-service/app/macros"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func (aH *APIHandler) listQueryMacros(w http.ResponseWriter, r *http.Request) {
+	list, err := macros.GetMacros(r.Context())
+	if err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorInternal, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, list)
+}
+
+func (aH *APIHandler) getQueryMacro(w http.ResponseWriter, r *http.Request) {
+	macro, err := macros.GetMacro(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		RespondError(w, macroApiError(err), nil)
+		return
+	}
+	aH.Respond(w, macro)
+}
+
+func (aH *APIHandler) createQueryMacro(w http.ResponseWriter, r *http.Request) {
+	var macro macros.Macro
+	if err := json.NewDecoder(r.Body).Decode(&macro); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := macro.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	created, err := macros.CreateMacro(r.Context(), macro)
+	if err != nil {
+		RespondError(w, macroApiError(err), nil)
+		return
+	}
+	aH.Respond(w, created)
+}
+
+func (aH *APIHandler) updateQueryMacro(w http.ResponseWriter, r *http.Request) {
+	var macro macros.Macro
+	if err := json.NewDecoder(r.Body).Decode(&macro); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if err := macro.Validate(); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+
+	updated, err := macros.UpdateMacro(r.Context(), mux.Vars(r)["id"], macro)
+	if err != nil {
+		RespondError(w, macroApiError(err), nil)
+		return
+	}
+	aH.Respond(w, updated)
+}
+
+func (aH *APIHandler) deleteQueryMacro(w http.ResponseWriter, r *http.Request) {
+	if err := macros.DeleteMacro(r.Context(), mux.Vars(r)["id"]); err != nil {
+		RespondError(w, macroApiError(err), nil)
+		return
+	}
+	aH.Respond(w, nil)
+}
+
+// previewQueryMacros returns the composite query of the query range params
+// with the macros expanded, before the variables are replaced
+func (aH *APIHandler) previewQueryMacros(w http.ResponseWriter, r *http.Request) {
+	var params v3.QueryRangeParamsV3
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	if params.CompositeQuery == nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: errors.New("composite query is required")}, nil)
+		return
+	}
+
+	if err := macros.Expand(r.Context(), &params); err != nil {
+		RespondError(w, &model.ApiError{Typ: model.ErrorBadData, Err: err}, nil)
+		return
+	}
+	aH.Respond(w, params.CompositeQuery)
+}
+
+func macroApiError(err error) *model.ApiError {
+	switch {
+	case errors.Is(err, macros.ErrMacroNotFound):
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: err}
+	case errors.Is(err, macros.ErrMacroExists):
+		return &model.ApiError{Typ: model.ErrorConflict, Err: err}
+	case errors.Is(err, macros.ErrNoOrg):
+		return &model.ApiError{Typ: model.ErrorUnauthorized, Err: err}
+	}
+	return &model.ApiError{Typ: model.ErrorInternal, Err: err}
+}
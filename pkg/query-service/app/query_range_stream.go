@@ -0,0 +1,254 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/SigNoz/govaluate"
+	jsoniter "github.com/json-iterator/go"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/postprocess"
+	"go.uber.org/zap"
+)
+
+// queryRangeStreamPart is the data of the events of the streamed query range
+// results, one per group of queries run together
+type queryRangeStreamPart struct {
+	QueryNames []string     `json:"queryNames"`
+	Result     []*v3.Result `json:"result,omitempty"`
+	ErrorType  string       `json:"errorType,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	ErrorData  interface{}  `json:"errorData,omitempty"`
+	apiErr     *model.ApiError
+}
+
+// wantsQueryRangeStream tells whether the client asked for the query range
+// results as server-sent events
+func wantsQueryRangeStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// streamQueryRangeV4 runs the groups of queries of the query range params
+// concurrently and sends the results of each group as a server-sent event as
+// soon as it completes, so that the panels render progressively instead of
+// waiting for the slowest query. A result event is sent for each group that
+// succeeds and an error event for each group that fails, the done event with
+// the effective resolution of the queries ends the stream.
+func (aH *APIHandler) streamQueryRangeV4(ctx context.Context, queryRangeParams *v3.QueryRangeParamsV3, w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := model.ApiError{Typ: model.ErrorStreamingNotSupported, Err: nil}
+		RespondError(w, &err, "streaming is not supported")
+		return
+	}
+
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	groups := splitQueryRangeParams(queryRangeParams)
+	parts := make(chan queryRangeStreamPart, len(groups))
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		go func(group *v3.QueryRangeParamsV3) {
+			defer wg.Done()
+			part := queryRangeStreamPart{QueryNames: queryNames(group.CompositeQuery)}
+			part.Result, part.apiErr, part.ErrorData = aH.execQueryRangeV4(ctx, group)
+			parts <- part
+		}(group)
+	}
+	go func() {
+		wg.Wait()
+		close(parts)
+	}()
+
+	result := []*v3.Result{}
+	for part := range parts {
+		event := "result"
+		if part.apiErr != nil {
+			event = "error"
+			part.ErrorType = string(part.apiErr.Type())
+			part.Error = part.apiErr.Error()
+		} else {
+			result = append(result, part.Result...)
+		}
+		if err := writeServerSentEvent(w, event, part); err != nil {
+			// the client went away, the context cancels the remaining queries
+			zap.L().Debug("error writing the query range event", zap.Error(err))
+			return
+		}
+		flusher.Flush()
+	}
+	sendQueryResultEvents(r, result, queryRangeParams)
+
+	if err := writeServerSentEvent(w, "done", queryRangeMeta(queryRangeParams)); err != nil {
+		zap.L().Debug("error writing the query range event", zap.Error(err))
+		return
+	}
+	flusher.Flush()
+}
+
+func writeServerSentEvent(w http.ResponseWriter, event string, data interface{}) error {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	return err
+}
+
+// splitQueryRangeParams splits the query range params into the groups of
+// queries that can run on their own. The formulas run with the queries they
+// reference, and the table panels, which merge the results of their queries,
+// are not split.
+func splitQueryRangeParams(params *v3.QueryRangeParamsV3) []*v3.QueryRangeParamsV3 {
+	cq := params.CompositeQuery
+	if cq.PanelType == v3.PanelTypeTable {
+		return []*v3.QueryRangeParamsV3{params}
+	}
+
+	var groups [][]string
+	switch cq.QueryType {
+	case v3.QueryTypeBuilder:
+		groups = builderQueryGroups(cq.BuilderQueries)
+	case v3.QueryTypeClickHouseSQL:
+		for name, query := range cq.ClickHouseQueries {
+			if !query.Disabled {
+				groups = append(groups, []string{name})
+			}
+		}
+	case v3.QueryTypePromQL:
+		for name, query := range cq.PromQueries {
+			if !query.Disabled {
+				groups = append(groups, []string{name})
+			}
+		}
+	case v3.QueryTypeTraceQL:
+		for name, query := range cq.TraceQLQueries {
+			if !query.Disabled {
+				groups = append(groups, []string{name})
+			}
+		}
+	}
+	if len(groups) <= 1 {
+		return []*v3.QueryRangeParamsV3{params}
+	}
+
+	split := make([]*v3.QueryRangeParamsV3, 0, len(groups))
+	for _, names := range groups {
+		group := params.Clone()
+		keep := make(map[string]bool, len(names))
+		for _, name := range names {
+			keep[name] = true
+		}
+		for name := range group.CompositeQuery.BuilderQueries {
+			if !keep[name] {
+				delete(group.CompositeQuery.BuilderQueries, name)
+			}
+		}
+		for name := range group.CompositeQuery.ClickHouseQueries {
+			if !keep[name] {
+				delete(group.CompositeQuery.ClickHouseQueries, name)
+			}
+		}
+		for name := range group.CompositeQuery.PromQueries {
+			if !keep[name] {
+				delete(group.CompositeQuery.PromQueries, name)
+			}
+		}
+		for name := range group.CompositeQuery.TraceQLQueries {
+			if !keep[name] {
+				delete(group.CompositeQuery.TraceQLQueries, name)
+			}
+		}
+		split = append(split, group)
+	}
+	return split
+}
+
+// builderQueryGroups groups the enabled builder queries with the formulas
+// referencing them, the disabled queries are only kept in the groups of the
+// formulas referencing them
+func builderQueryGroups(queries map[string]*v3.BuilderQuery) [][]string {
+	// union find of the queries on the references of the formulas
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(name string) string {
+		if parent[name] == name {
+			return name
+		}
+		parent[name] = find(parent[name])
+		return parent[name]
+	}
+	for name := range queries {
+		parent[name] = name
+	}
+
+	referenced := map[string]bool{}
+	for name, query := range queries {
+		if query.QueryName == query.Expression {
+			continue
+		}
+		expression, err := govaluate.NewEvaluableExpressionWithFunctions(query.Expression, postprocess.EvalFuncs())
+		if err != nil {
+			continue
+		}
+		for _, v := range expression.Vars() {
+			if _, ok := queries[v]; ok {
+				parent[find(v)] = find(name)
+				referenced[v] = true
+			}
+		}
+	}
+
+	byRoot := map[string][]string{}
+	for name, query := range queries {
+		root := find(name)
+		if query.Disabled && !referenced[name] {
+			continue
+		}
+		byRoot[root] = append(byRoot[root], name)
+	}
+
+	groups := make([][]string, 0, len(byRoot))
+	for _, names := range byRoot {
+		enabled := false
+		for _, name := range names {
+			enabled = enabled || !queries[name].Disabled
+		}
+		if !enabled {
+			continue
+		}
+		sort.Strings(names)
+		groups = append(groups, names)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
+
+func queryNames(cq *v3.CompositeQuery) []string {
+	names := []string{}
+	for name := range cq.BuilderQueries {
+		names = append(names, name)
+	}
+	for name := range cq.ClickHouseQueries {
+		names = append(names, name)
+	}
+	for name := range cq.PromQueries {
+		names = append(names, name)
+	}
+	for name := range cq.TraceQLQueries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
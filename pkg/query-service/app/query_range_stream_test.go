@@ -0,0 +1,58 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func TestSplitQueryRangeParams(t *testing.T) {
+	params := &v3.QueryRangeParamsV3{
+		Start: 1000,
+		End:   2000,
+		Step:  60,
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType: v3.QueryTypeBuilder,
+			PanelType: v3.PanelTypeGraph,
+			BuilderQueries: map[string]*v3.BuilderQuery{
+				"A":  {QueryName: "A", Expression: "A", DataSource: v3.DataSourceMetrics},
+				"B":  {QueryName: "B", Expression: "B", DataSource: v3.DataSourceMetrics, Disabled: true},
+				"C":  {QueryName: "C", Expression: "C", DataSource: v3.DataSourceLogs},
+				"D":  {QueryName: "D", Expression: "D", DataSource: v3.DataSourceTraces, Disabled: true},
+				"F1": {QueryName: "F1", Expression: "A / B"},
+				"F2": {QueryName: "F2", Expression: "F1 * 100"},
+			},
+		},
+	}
+
+	groups := splitQueryRangeParams(params)
+	names := [][]string{}
+	for _, group := range groups {
+		names = append(names, queryNames(group.CompositeQuery))
+		assert.Equal(t, params.Start, group.Start)
+		assert.Equal(t, params.Step, group.Step)
+	}
+	// the formulas run with the queries they reference, and the disabled
+	// queries nothing references are dropped
+	assert.Equal(t, [][]string{{"A", "B", "F1", "F2"}, {"C"}}, names)
+	assert.Len(t, params.CompositeQuery.BuilderQueries, 6)
+
+	params.CompositeQuery.PanelType = v3.PanelTypeTable
+	assert.Equal(t, []*v3.QueryRangeParamsV3{params}, splitQueryRangeParams(params))
+
+	params.CompositeQuery = &v3.CompositeQuery{
+		QueryType: v3.QueryTypeClickHouseSQL,
+		PanelType: v3.PanelTypeGraph,
+		ClickHouseQueries: map[string]*v3.ClickHouseQuery{
+			"A": {Query: "SELECT 1"},
+			"B": {Query: "SELECT 2"},
+			"C": {Query: "SELECT 3", Disabled: true},
+		},
+	}
+	groups = splitQueryRangeParams(params)
+	assert.Len(t, groups, 2)
+	for _, group := range groups {
+		assert.Len(t, group.CompositeQuery.ClickHouseQueries, 1)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"go.signoz.io/signoz/pkg/web"
 
 	"go.signoz.io/signoz/pkg/query-service/app/explorer"
+	"go.signoz.io/signoz/pkg/query-service/app/macros"
 	"go.signoz.io/signoz/pkg/query-service/auth"
 	"go.signoz.io/signoz/pkg/query-service/cache"
 	"go.signoz.io/signoz/pkg/query-service/constants"
@@ -80,6 +81,11 @@ type Server struct {
 
 	opampServer *opamp.Server
 
+	reportScheduler *DashboardReportScheduler
+	provisioner     *dashboards.DashboardProvisioner
+	purger          *dashboards.DashboardPurger
+	thumbnails      *dashboards.ThumbnailRenderer
+
 	unavailableChannel chan healthcheck.Status
 }
 
@@ -106,6 +112,7 @@ func NewServer(serverOptions *ServerOptions) (*Server, error) {
 	if err := explorer.InitWithDSN(serverOptions.SigNoz.SQLStore.SQLxDB()); err != nil {
 		return nil, err
 	}
+	macros.InitDB(serverOptions.SigNoz.SQLStore.SQLxDB())
 
 	// initiate feature manager
 	fm := featureManager.StartManager()
@@ -206,6 +213,10 @@ func NewServer(serverOptions *ServerOptions) (*Server, error) {
 		// tracer: tracer,
 		ruleManager:        rm,
 		serverOptions:      serverOptions,
+		reportScheduler:    NewDashboardReportScheduler(apiHandler),
+		provisioner:        dashboards.NewDashboardProvisioner(constants.GetOrDefaultEnv("DASHBOARDS_PATH", "./config/dashboards"), constants.GetDashboardsProvisioningInterval(), fm),
+		purger:             dashboards.NewDashboardPurger(constants.GetDashboardsPurgeInterval()),
+		thumbnails:         dashboards.NewThumbnailRenderer(constants.GetDashboardsThumbnailInterval()),
 		unavailableChannel: make(chan healthcheck.Status),
 	}
 
@@ -289,6 +300,14 @@ func (s *Server) createPublicServer(api *APIHandler, web web.Web) (*http.Server,
 	).Wrap)
 	r.Use(middleware.NewAnalytics(zap.L()).Wrap)
 	r.Use(middleware.NewLogging(zap.L(), s.serverOptions.Config.APIServer.Logging.ExcludedRoutes).Wrap)
+	r.Use(middleware.NewAdmission(zap.L(),
+		s.serverOptions.Config.APIServer.Admission.Routes,
+		nil,
+		s.serverOptions.Config.APIServer.Admission.MaxConcurrentPerUser,
+		s.serverOptions.Config.APIServer.Admission.MaxConcurrentPerOrg,
+		s.serverOptions.Config.APIServer.Admission.MaxQueued,
+		s.serverOptions.Config.APIServer.Admission.QueueTimeout,
+	).Wrap)
 
 	// add auth middleware
 	getUserFromRequest := func(ctx context.Context) (*model.UserPayload, error) {
@@ -380,6 +399,11 @@ func (s *Server) Start() error {
 		zap.L().Info("msg: Rules disabled as rules.disable is set to TRUE")
 	}
 
+	s.reportScheduler.Start()
+	s.provisioner.Start()
+	s.purger.Start()
+	s.thumbnails.Start()
+
 	err := s.initListeners()
 	if err != nil {
 		return err
@@ -462,6 +486,22 @@ func (s *Server) Stop() error {
 		s.ruleManager.Stop()
 	}
 
+	if s.reportScheduler != nil {
+		s.reportScheduler.Stop()
+	}
+
+	if s.provisioner != nil {
+		s.provisioner.Stop()
+	}
+
+	if s.purger != nil {
+		s.purger.Stop()
+	}
+
+	if s.thumbnails != nil {
+		s.thumbnails.Stop()
+	}
+
 	return nil
 }
 
@@ -503,6 +543,7 @@ func makeRulesManager(
 		Reader:            ch,
 		Cache:             cache,
 		EvalDelay:         constants.GetEvalDelay(),
+		EvalJitter:        constants.GetEvalJitter(),
 		UseLogsNewSchema:  useLogsNewSchema,
 		UseTraceNewSchema: useTraceNewSchema,
 	}
@@ -0,0 +1,322 @@
+package traceql
+
+import (
+	"fmt"
+	"math"
+
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+const (
+	NANOSECOND = 1000000000
+
+	defaultLimit = 100
+	defaultStep  = 60
+)
+
+var statusCodes = map[string]int{"unset": 0, "ok": 1, "error": 2}
+
+var spanKinds = map[string]int{"unspecified": 0, "internal": 1, "server": 2, "client": 3, "producer": 4, "consumer": 5}
+
+// spansSchema is the table of the spans and its columns
+type spansSchema struct {
+	table string
+	// whether the spans are partitioned by ts_bucket_start
+	bucketed       bool
+	traceID        string
+	spanID         string
+	parentSpanID   string
+	serviceName    string
+	durationNano   string
+	statusCode     string
+	spanStrings    string
+	spanNumbers    string
+	spanBools      string
+	resourceValues string
+}
+
+var (
+	spansSchemaV3 = spansSchema{
+		table:          constants.SIGNOZ_TRACE_DBNAME + "." + constants.SIGNOZ_SPAN_INDEX_V3,
+		bucketed:       true,
+		traceID:        "trace_id",
+		spanID:         "span_id",
+		parentSpanID:   "parent_span_id",
+		serviceName:    "`resource_string_service$$name`",
+		durationNano:   "duration_nano",
+		statusCode:     "status_code",
+		spanStrings:    "attributes_string",
+		spanNumbers:    "attributes_number",
+		spanBools:      "attributes_bool",
+		resourceValues: "resources_string",
+	}
+	spansSchemaV2 = spansSchema{
+		table:          constants.SIGNOZ_TRACE_DBNAME + "." + constants.SIGNOZ_SPAN_INDEX_TABLENAME,
+		traceID:        "traceID",
+		spanID:         "spanID",
+		parentSpanID:   "parentSpanID",
+		serviceName:    "serviceName",
+		durationNano:   "durationNano",
+		statusCode:     "statusCode",
+		spanStrings:    "stringTagMap",
+		spanNumbers:    "numberTagMap",
+		spanBools:      "boolTagMap",
+		resourceValues: "resourceTagsMap",
+	}
+)
+
+// PrepareQuery returns the ClickHouse SQL of the query for the time range in
+// epoch milliseconds. The list panels return the latest spans matching the
+// query up to its limit, and the graph panels their count per step. The
+// spans are read from the table of the new schema when it is used.
+func PrepareQuery(start, end, step int64, panelType v3.PanelType, query *v3.TraceQLQuery, useTraceNewSchema bool) (string, error) {
+	expr, err := Parse(query.Query)
+	if err != nil {
+		return "", err
+	}
+
+	schema := spansSchemaV2
+	if useTraceNewSchema {
+		schema = spansSchemaV3
+	}
+
+	timeFilter := schema.timeFilter(start, end)
+	condition, err := schema.spansCondition(expr, timeFilter)
+	if err != nil {
+		return "", err
+	}
+	where := timeFilter
+	if condition != "" {
+		where += " AND " + condition
+	}
+
+	switch panelType {
+	case v3.PanelTypeList:
+		limit := query.Limit
+		if limit == 0 {
+			limit = defaultLimit
+		}
+		return fmt.Sprintf("SELECT timestamp as timestamp_datetime, %s as traceID, %s as spanID, %s as parentSpanID, "+
+			"name, %s as serviceName, %s as durationNano, %s as statusCode "+
+			"FROM %s WHERE %s ORDER BY timestamp DESC LIMIT %d",
+			schema.traceID, schema.spanID, schema.parentSpanID, schema.serviceName, schema.durationNano, schema.statusCode, schema.table, where, limit), nil
+	case v3.PanelTypeGraph:
+		if step <= 0 {
+			step = defaultStep
+		}
+		return fmt.Sprintf("SELECT toStartOfInterval(timestamp, INTERVAL %d SECOND) AS ts, toFloat64(count()) AS value "+
+			"FROM %s WHERE %s GROUP BY ts ORDER BY ts ASC", step, schema.table, where), nil
+	}
+	return "", fmt.Errorf("panel type %s is not supported for traceql queries", panelType)
+}
+
+func (schema spansSchema) timeFilter(start, end int64) string {
+	tracesStart := utils.GetEpochNanoSecs(start)
+	tracesEnd := utils.GetEpochNanoSecs(end)
+	filter := fmt.Sprintf("(timestamp >= '%d' AND timestamp <= '%d')", tracesStart, tracesEnd)
+	if !schema.bucketed {
+		return filter
+	}
+	// -1800 this is added so that the bucket start considers all the fingerprints.
+	bucketStart := tracesStart/NANOSECOND - 1800
+	bucketEnd := tracesEnd / NANOSECOND
+	return filter + fmt.Sprintf(" AND (ts_bucket_start >= %d AND ts_bucket_start <= %d)", bucketStart, bucketEnd)
+}
+
+// spansCondition returns the condition selecting the spans of the spanset,
+// the related spans of the structural operations are selected in the time
+// range too
+func (schema spansSchema) spansCondition(expr Expr, timeFilter string) (string, error) {
+	switch expr := expr.(type) {
+	case *SpansetFilter:
+		if expr.Condition == nil {
+			return "", nil
+		}
+		return schema.condition(expr.Condition)
+	case *Structural:
+		left, err := schema.spansCondition(expr.Left, timeFilter)
+		if err != nil {
+			return "", err
+		}
+		where := timeFilter
+		if left != "" {
+			where += " AND " + left
+		}
+		var related string
+		switch expr.Op {
+		case StructuralChild:
+			related = fmt.Sprintf("(%[1]s, %[2]s) GLOBAL IN (SELECT %[1]s, %[3]s FROM %[4]s WHERE %[5]s)", schema.traceID, schema.parentSpanID, schema.spanID, schema.table, where)
+		case StructuralParent:
+			related = fmt.Sprintf("(%[1]s, %[2]s) GLOBAL IN (SELECT %[1]s, %[3]s FROM %[4]s WHERE %[5]s)", schema.traceID, schema.spanID, schema.parentSpanID, schema.table, where)
+		default:
+			return "", fmt.Errorf("unsupported structural operator %s", expr.Op)
+		}
+		right, err := schema.spansCondition(expr.Right, timeFilter)
+		if err != nil {
+			return "", err
+		}
+		if right == "" {
+			return related, nil
+		}
+		return right + " AND " + related, nil
+	}
+	return "", fmt.Errorf("unsupported expression %T", expr)
+}
+
+func (schema spansSchema) condition(c Condition) (string, error) {
+	switch c := c.(type) {
+	case *Binary:
+		left, err := schema.condition(c.Left)
+		if err != nil {
+			return "", err
+		}
+		right, err := schema.condition(c.Right)
+		if err != nil {
+			return "", err
+		}
+		op := "AND"
+		if c.Op == "||" {
+			op = "OR"
+		}
+		return fmt.Sprintf("(%s %s %s)", left, op, right), nil
+	case *Comparison:
+		switch c.Field.Scope {
+		case ScopeIntrinsic:
+			return schema.intrinsicCondition(c)
+		case ScopeSpan:
+			return schema.attributeCondition(false, c)
+		case ScopeResource:
+			return schema.attributeCondition(true, c)
+		case ScopeUnscoped:
+			span, err := schema.attributeCondition(false, c)
+			if err != nil {
+				return "", err
+			}
+			resource, err := schema.attributeCondition(true, c)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("(%s OR %s)", span, resource), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported condition %T", c)
+}
+
+func (schema spansSchema) intrinsicCondition(c *Comparison) (string, error) {
+	switch c.Field.Name {
+	case "name":
+		if c.Value.Kind != ValueString {
+			return "", fmt.Errorf("name must be compared with a string")
+		}
+		return compare("name", c.Op, c.Value.String)
+	case "duration":
+		var nanos int64
+		switch c.Value.Kind {
+		case ValueDuration:
+			nanos = c.Value.Duration.Nanoseconds()
+		case ValueNumber:
+			nanos = int64(c.Value.Number)
+		default:
+			return "", fmt.Errorf("duration must be compared with a duration, such as 500ms")
+		}
+		return compare(schema.durationNano, c.Op, nanos)
+	case "status", "kind":
+		values := statusCodes
+		column := schema.statusCode
+		if c.Field.Name == "kind" {
+			values = spanKinds
+			column = "kind"
+		}
+		value, ok := values[c.Value.String]
+		if c.Value.Kind != ValueKeyword || !ok {
+			return "", fmt.Errorf("invalid %s %q", c.Field.Name, c.Value.String)
+		}
+		if c.Op != "=" && c.Op != "!=" {
+			return "", fmt.Errorf("%s can only be compared with = and !=", c.Field.Name)
+		}
+		return compare(column, c.Op, value)
+	}
+	return "", fmt.Errorf("unknown intrinsic %s", c.Field.Name)
+}
+
+// attributeCondition returns the condition on the span or resource
+// attribute, the spans without the attribute don't match it. The resource
+// attributes are strings, they are converted to compare them with numbers.
+func (schema spansSchema) attributeCondition(resource bool, c *Comparison) (string, error) {
+	key := utils.ClickHouseFormattedValue(c.Field.Name)
+	stringValues, numberValues, boolValues := schema.spanStrings, schema.spanNumbers, schema.spanBools
+	if resource {
+		stringValues = schema.resourceValues
+	}
+	var column, values string
+	var value interface{}
+	switch c.Value.Kind {
+	case ValueString:
+		values = stringValues
+		column = fmt.Sprintf("%s[%s]", values, key)
+		value = c.Value.String
+	case ValueNumber, ValueDuration:
+		value = c.Value.Number
+		if c.Value.Number == math.Trunc(c.Value.Number) {
+			value = int64(c.Value.Number)
+		}
+		if c.Value.Kind == ValueDuration {
+			value = c.Value.Duration.Nanoseconds()
+		}
+		if resource {
+			values = stringValues
+			column = fmt.Sprintf("toFloat64OrNull(%s[%s])", values, key)
+		} else {
+			values = numberValues
+			column = fmt.Sprintf("%s[%s]", values, key)
+		}
+	case ValueBool:
+		if c.Op != "=" && c.Op != "!=" {
+			return "", fmt.Errorf("booleans can only be compared with = and !=")
+		}
+		value = c.Value.Bool
+		if resource {
+			values = stringValues
+			column = fmt.Sprintf("%s[%s]", values, key)
+			value = fmt.Sprintf("%t", c.Value.Bool)
+		} else {
+			values = boolValues
+			column = fmt.Sprintf("%s[%s]", values, key)
+		}
+	default:
+		return "", fmt.Errorf("the value of %s must be quoted", c.Field.Name)
+	}
+
+	comparison, err := compare(column, c.Op, value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(mapContains(%s, %s) AND %s)", values, key, comparison), nil
+}
+
+func compare(column string, op string, value interface{}) (string, error) {
+	switch op {
+	case "=~", "!~":
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("regular expressions can only match strings")
+		}
+		// the regular expressions match the whole value
+		condition := fmt.Sprintf("match(%s, %s)", column, utils.ClickHouseFormattedValue("^(?:"+s+")$"))
+		if op == "!~" {
+			condition = "NOT " + condition
+		}
+		return condition, nil
+	case "=", "!=", ">", ">=", "<", "<=":
+		return fmt.Sprintf("%s %s %s", column, op, utils.ClickHouseFormattedValue(value)), nil
+	}
+	return "", fmt.Errorf("unsupported operator %s", op)
+}
+
+// Validate validates the syntax of the query
+func Validate(query string) error {
+	_, err := Parse(query)
+	return err
+}
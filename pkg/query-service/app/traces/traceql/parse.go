@@ -0,0 +1,395 @@
+package traceql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenDuration
+	tokenOperator
+	tokenPunct
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// operators are the comparison, logical and structural operators, the
+// longest first
+var operators = []string{"&&", "||", "!=", "=~", "!~", ">=", "<=", "=", ">", "<"}
+
+var comparisonOperators = map[string]bool{
+	"=": true, "!=": true, ">": true, ">=": true, "<": true, "<=": true, "=~": true, "!~": true,
+}
+
+const (
+	// StructuralChild selects the spans of the right spanset whose parent is
+	// in the left spanset
+	StructuralChild = ">"
+	// StructuralParent selects the spans of the right spanset which are the
+	// parent of a span of the left spanset
+	StructuralParent = "<"
+)
+
+// Expr is a spanset, either a spanset filter or a structural operation on
+// spansets
+type Expr interface {
+	expr()
+}
+
+// SpansetFilter selects the spans matching the condition, all the spans when
+// there is no condition, as in {}
+type SpansetFilter struct {
+	Condition Condition
+}
+
+// Structural selects the spans of the right spanset filter related to the
+// spans of the left spanset by the operator
+type Structural struct {
+	Left  Expr
+	Op    string
+	Right *SpansetFilter
+}
+
+func (*SpansetFilter) expr() {}
+func (*Structural) expr()    {}
+
+// Condition is a condition on the fields of the spans
+type Condition interface {
+	condition()
+}
+
+// Binary joins the conditions with && or ||
+type Binary struct {
+	Op    string
+	Left  Condition
+	Right Condition
+}
+
+// Comparison compares a field of the spans with a value
+type Comparison struct {
+	Field Field
+	Op    string
+	Value Value
+}
+
+func (*Binary) condition()     {}
+func (*Comparison) condition() {}
+
+const (
+	ScopeIntrinsic = "intrinsic"
+	ScopeSpan      = "span"
+	ScopeResource  = "resource"
+	// ScopeUnscoped is the scope of the attributes of either the span or the
+	// resource, as in .http.method
+	ScopeUnscoped = ""
+)
+
+// Field is an intrinsic field of the spans, such as name, status, kind and
+// duration, or an attribute of their scope
+type Field struct {
+	Scope string
+	Name  string
+}
+
+type ValueKind int
+
+const (
+	ValueString ValueKind = iota
+	ValueNumber
+	ValueDuration
+	ValueBool
+	// ValueKeyword is the values of the status and the kind, such as error
+	// and server
+	ValueKeyword
+)
+
+type Value struct {
+	Kind     ValueKind
+	String   string
+	Number   float64
+	Duration time.Duration
+	Bool     bool
+}
+
+var intrinsics = map[string]bool{"name": true, "status": true, "kind": true, "duration": true}
+
+// Parse parses the query of the trace search language. The spanset filters
+// select the spans with conditions on their fields joined by && and ||, as
+// in { resource.service.name = "cart" && duration > 500ms }, and the
+// structural operators > and < select the children and the parents of the
+// spans of a spanset, as in { name = "checkout" } > { status = error }.
+func Parse(query string) (Expr, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	first, err := p.spanset()
+	if err != nil {
+		return nil, err
+	}
+	var expr Expr = first
+	for !p.done() {
+		t, _ := p.next()
+		if t.kind != tokenOperator || (t.value != StructuralChild && t.value != StructuralParent) {
+			return nil, fmt.Errorf("expected a structural operator, got %q", t.value)
+		}
+		right, err := p.spanset()
+		if err != nil {
+			return nil, err
+		}
+		expr = &Structural{Left: expr, Op: t.value, Right: right}
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("unexpected end of the query")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *parser) expect(value string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != tokenPunct || t.value != value {
+		return fmt.Errorf("expected %q, got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *parser) spanset() (*SpansetFilter, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokenPunct && t.value == "}" {
+		p.pos++
+		return &SpansetFilter{}, nil
+	}
+	condition, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("}"); err != nil {
+		return nil, err
+	}
+	return &SpansetFilter{Condition: condition}, nil
+}
+
+func (p *parser) or() (Condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.kind == tokenOperator && t.value == "||"; t = p.peek() {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: "||", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Condition, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.kind == tokenOperator && t.value == "&&"; t = p.peek() {
+		p.pos++
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: "&&", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) primary() (Condition, error) {
+	if t := p.peek(); t.kind == tokenPunct && t.value == "(" {
+		p.pos++
+		condition, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return condition, nil
+	}
+
+	field, err := p.field()
+	if err != nil {
+		return nil, err
+	}
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if op.kind != tokenOperator || !comparisonOperators[op.value] {
+		return nil, fmt.Errorf("expected a comparison operator, got %q", op.value)
+	}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	return &Comparison{Field: field, Op: op.value, Value: value}, nil
+}
+
+func (p *parser) field() (Field, error) {
+	t, err := p.next()
+	if err != nil {
+		return Field{}, err
+	}
+	if t.kind != tokenIdent {
+		return Field{}, fmt.Errorf("expected a field, got %q", t.value)
+	}
+	switch {
+	case strings.HasPrefix(t.value, "."):
+		return Field{Scope: ScopeUnscoped, Name: t.value[1:]}, nil
+	case strings.HasPrefix(t.value, ScopeSpan+"."):
+		return Field{Scope: ScopeSpan, Name: strings.TrimPrefix(t.value, ScopeSpan+".")}, nil
+	case strings.HasPrefix(t.value, ScopeResource+"."):
+		return Field{Scope: ScopeResource, Name: strings.TrimPrefix(t.value, ScopeResource+".")}, nil
+	case intrinsics[t.value]:
+		return Field{Scope: ScopeIntrinsic, Name: t.value}, nil
+	}
+	return Field{}, fmt.Errorf("unknown field %s, the attributes are prefixed with span., resource. or .", t.value)
+}
+
+func (p *parser) value() (Value, error) {
+	t, err := p.next()
+	if err != nil {
+		return Value{}, err
+	}
+	switch t.kind {
+	case tokenString:
+		return Value{Kind: ValueString, String: t.value}, nil
+	case tokenNumber:
+		number, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return Value{}, fmt.Errorf("invalid number %s", t.value)
+		}
+		return Value{Kind: ValueNumber, Number: number}, nil
+	case tokenDuration:
+		duration, err := time.ParseDuration(t.value)
+		if err != nil {
+			return Value{}, fmt.Errorf("invalid duration %s", t.value)
+		}
+		return Value{Kind: ValueDuration, Duration: duration}, nil
+	case tokenIdent:
+		switch t.value {
+		case "true", "false":
+			return Value{Kind: ValueBool, Bool: t.value == "true"}, nil
+		}
+		if !strings.Contains(t.value, ".") {
+			return Value{Kind: ValueKeyword, String: t.value}, nil
+		}
+	}
+	return Value{}, fmt.Errorf("expected a value, got %q", t.value)
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
+
+func tokenize(query string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			end := i + 1
+			for ; end < len(runes) && runes[end] != '"'; end++ {
+				if runes[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			value, err := strconv.Unquote(string(runes[i : end+1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", string(runes[i:end+1]))
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			// the numbers followed by a unit are durations, as in 1.5s
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			kind := tokenNumber
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				kind = tokenDuration
+				i++
+			}
+			tokens = append(tokens, token{kind: kind, value: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_' || r == '.':
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i])})
+		case strings.ContainsRune("{}()", r):
+			tokens = append(tokens, token{kind: tokenPunct, value: string(r)})
+			i++
+		default:
+			var operator string
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					operator = op
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: operator})
+			i += len(operator)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	return tokens, nil
+}
@@ -0,0 +1,125 @@
+package traceql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func TestParse(t *testing.T) {
+	expr, err := Parse(`{ resource.service.name = "cart" && (duration > 500ms || status = error) } > { .http.status_code >= 500 }`)
+	require.NoError(t, err)
+	assert.Equal(t, &Structural{
+		Left: &SpansetFilter{Condition: &Binary{
+			Op:   "&&",
+			Left: &Comparison{Field: Field{Scope: ScopeResource, Name: "service.name"}, Op: "=", Value: Value{Kind: ValueString, String: "cart"}},
+			Right: &Binary{
+				Op:    "||",
+				Left:  &Comparison{Field: Field{Scope: ScopeIntrinsic, Name: "duration"}, Op: ">", Value: Value{Kind: ValueDuration, Duration: 500 * time.Millisecond}},
+				Right: &Comparison{Field: Field{Scope: ScopeIntrinsic, Name: "status"}, Op: "=", Value: Value{Kind: ValueKeyword, String: "error"}},
+			},
+		}},
+		Op:    StructuralChild,
+		Right: &SpansetFilter{Condition: &Comparison{Field: Field{Scope: ScopeUnscoped, Name: "http.status_code"}, Op: ">=", Value: Value{Kind: ValueNumber, Number: 500}}},
+	}, expr)
+
+	for _, query := range []string{
+		``,
+		`{ name = "checkout"`,
+		`{ http.method = "GET" }`,
+		`{ name = "checkout" } { name = "cart" }`,
+		`{ duration > 5xs }`,
+		`{ name = }`,
+	} {
+		_, err := Parse(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestPrepareQuery(t *testing.T) {
+	timeFilter := "(timestamp >= '1680066360000000000' AND timestamp <= '1680066420000000000') AND (ts_bucket_start >= 1680064560 AND ts_bucket_start <= 1680066420)"
+	cases := []struct {
+		name      string
+		query     string
+		panelType v3.PanelType
+		expected  string
+		wantErr   bool
+	}{
+		{
+			name:      "list of the spans",
+			query:     `{ name =~ "GET /api/.*" && span.http.status_code >= 500 && kind = server }`,
+			panelType: v3.PanelTypeList,
+			expected: "SELECT timestamp as timestamp_datetime, trace_id as traceID, span_id as spanID, parent_span_id as parentSpanID, " +
+				"name, `resource_string_service$$name` as serviceName, duration_nano as durationNano, status_code as statusCode " +
+				"FROM signoz_traces.distributed_signoz_index_v3 WHERE " + timeFilter +
+				" AND ((match(name, '^(?:GET /api/.*)$') AND (mapContains(attributes_number, 'http.status_code') AND attributes_number['http.status_code'] >= 500)) AND kind = 2)" +
+				" ORDER BY timestamp DESC LIMIT 100",
+		},
+		{
+			name:      "count of the children",
+			query:     `{ resource.service.name = "cart" } > { duration > 1s && .retry = true }`,
+			panelType: v3.PanelTypeGraph,
+			expected: "SELECT toStartOfInterval(timestamp, INTERVAL 60 SECOND) AS ts, toFloat64(count()) AS value " +
+				"FROM signoz_traces.distributed_signoz_index_v3 WHERE " + timeFilter +
+				" AND (duration_nano > 1000000000 AND ((mapContains(attributes_bool, 'retry') AND attributes_bool['retry'] = true) OR (mapContains(resources_string, 'retry') AND resources_string['retry'] = 'true')))" +
+				" AND (trace_id, parent_span_id) GLOBAL IN (SELECT trace_id, span_id FROM signoz_traces.distributed_signoz_index_v3 WHERE " + timeFilter +
+				" AND (mapContains(resources_string, 'service.name') AND resources_string['service.name'] = 'cart')) GROUP BY ts ORDER BY ts ASC",
+		},
+		{
+			name:      "parents of any span",
+			query:     `{} < { status != ok }`,
+			panelType: v3.PanelTypeGraph,
+			expected: "SELECT toStartOfInterval(timestamp, INTERVAL 60 SECOND) AS ts, toFloat64(count()) AS value " +
+				"FROM signoz_traces.distributed_signoz_index_v3 WHERE " + timeFilter +
+				" AND status_code != 1 AND (trace_id, span_id) GLOBAL IN (SELECT trace_id, parent_span_id FROM signoz_traces.distributed_signoz_index_v3 WHERE " + timeFilter +
+				") GROUP BY ts ORDER BY ts ASC",
+		},
+		{
+			name:      "invalid status",
+			query:     `{ status = failed }`,
+			panelType: v3.PanelTypeList,
+			wantErr:   true,
+		},
+		{
+			name:      "regular expression on a number",
+			query:     `{ span.retries =~ 3 }`,
+			panelType: v3.PanelTypeList,
+			wantErr:   true,
+		},
+		{
+			name:      "unsupported panel",
+			query:     `{}`,
+			panelType: v3.PanelTypeTable,
+			wantErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, err := PrepareQuery(1680066360000, 1680066420000, 60, c.panelType, &v3.TraceQLQuery{Query: c.query}, true)
+			if c.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, query)
+		})
+	}
+}
+
+func TestPrepareQueryOldSchema(t *testing.T) {
+	timeFilter := "(timestamp >= '1680066360000000000' AND timestamp <= '1680066420000000000')"
+
+	query, err := PrepareQuery(1680066360000, 1680066420000, 60, v3.PanelTypeList, &v3.TraceQLQuery{Query: `{ .http.method = "GET" } > { duration > 1s && span.retries >= 3 }`, Limit: 10}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT timestamp as timestamp_datetime, traceID as traceID, spanID as spanID, parentSpanID as parentSpanID, "+
+		"name, serviceName as serviceName, durationNano as durationNano, statusCode as statusCode "+
+		"FROM signoz_traces.distributed_signoz_index_v2 WHERE "+timeFilter+
+		" AND (durationNano > 1000000000 AND (mapContains(numberTagMap, 'retries') AND numberTagMap['retries'] >= 3))"+
+		" AND (traceID, parentSpanID) GLOBAL IN (SELECT traceID, spanID FROM signoz_traces.distributed_signoz_index_v2 WHERE "+timeFilter+
+		" AND ((mapContains(stringTagMap, 'http.method') AND stringTagMap['http.method'] = 'GET') OR (mapContains(resourceTagsMap, 'http.method') AND resourceTagsMap['http.method'] = 'GET')))"+
+		" ORDER BY timestamp DESC LIMIT 10", query)
+}
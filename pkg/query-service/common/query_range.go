@@ -52,6 +52,23 @@ func MinAllowedStepInterval(start, end int64) int64 {
 	return step - step%60
 }
 
+// DownsampledStep returns the step in seconds of the query over the time range
+// in milliseconds, coarsened when its series would have more than the max
+// points. The coarsened steps are rounded up to whole minutes, and to 5 and
+// 30 minutes over them.
+func DownsampledStep(start, end, step int64, maxPoints int64) int64 {
+	if step <= 0 || maxPoints <= 0 || (end-start)/1000/step <= maxPoints {
+		return step
+	}
+	minStep := int64(math.Ceil(float64(end-start) / 1000 / float64(maxPoints)))
+	for _, interval := range []int64{1800, 300, 60} {
+		if minStep > interval {
+			return (minStep + interval - 1) / interval * interval
+		}
+	}
+	return minStep
+}
+
 func GCD(a, b int64) int64 {
 	for b != 0 {
 		a, b = b, a%b
@@ -164,6 +164,106 @@ func GetEvalDelay() time.Duration {
 	return evalDelayDuration
 }
 
+func GetEvalJitter() time.Duration {
+	evalJitterStr := GetOrDefaultEnv("RULES_EVAL_JITTER", "0s")
+	evalJitterDuration, err := time.ParseDuration(evalJitterStr)
+	if err != nil {
+		return 0
+	}
+	return evalJitterDuration
+}
+
+// GetDashboardsTrashRetention returns how long deleted dashboards are kept in
+// the trash before being purged
+func GetDashboardsTrashRetention() time.Duration {
+	retentionStr := GetOrDefaultEnv("DASHBOARDS_TRASH_RETENTION", "720h")
+	retention, err := time.ParseDuration(retentionStr)
+	if err != nil {
+		return 720 * time.Hour
+	}
+	return retention
+}
+
+// GetDashboardsPurgeInterval returns how often the dashboards kept in the
+// trash for longer than their retention are purged, 0 purges them only on
+// start
+func GetDashboardsPurgeInterval() time.Duration {
+	intervalStr := GetOrDefaultEnv("DASHBOARDS_PURGE_INTERVAL", "1h")
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		return time.Hour
+	}
+	return interval
+}
+
+// GetDashboardsThumbnailInterval returns how often the thumbnails of the
+// changed dashboards are rendered, 0 renders them only on start
+func GetDashboardsThumbnailInterval() time.Duration {
+	intervalStr := GetOrDefaultEnv("DASHBOARDS_THUMBNAIL_INTERVAL", "1m")
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		return time.Minute
+	}
+	return interval
+}
+
+// GetDashboardsProvisioningInterval returns how often the dashboard files are
+// checked for changes, 0 provisions them only on start
+func GetDashboardsProvisioningInterval() time.Duration {
+	intervalStr := GetOrDefaultEnv("DASHBOARDS_PROVISIONING_INTERVAL", "1m")
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		return time.Minute
+	}
+	return interval
+}
+
+// GetDashboardsShareSecret returns the secret the tokens of the dashboard
+// shares are signed with, sharing dashboards is disabled when it is not set
+func GetDashboardsShareSecret() string {
+	return GetOrDefaultEnv("DASHBOARDS_SHARE_SECRET", "")
+}
+
+// GetDashboardsMaxDataSize returns the maximum size in bytes of the data of a
+// dashboard
+func GetDashboardsMaxDataSize() int {
+	size, err := strconv.Atoi(GetOrDefaultEnv("DASHBOARDS_MAX_DATA_SIZE", "4194304"))
+	if err != nil || size <= 0 {
+		return 4 << 20
+	}
+	return size
+}
+
+// GetQueryRangeMaxPointsPerSeries returns the budget of points of the series
+// of the query range results, the step of the queries over it is coarsened
+func GetQueryRangeMaxPointsPerSeries() int64 {
+	points, err := strconv.ParseInt(GetOrDefaultEnv("QUERY_RANGE_MAX_POINTS_PER_SERIES", "1500"), 10, 64)
+	if err != nil || points <= 0 {
+		return 1500
+	}
+	return points
+}
+
+// GetDashboardsPublicMaxRange returns the maximum time range of the queries
+// of the widgets of the shared dashboards
+func GetDashboardsPublicMaxRange() time.Duration {
+	duration, err := time.ParseDuration(GetOrDefaultEnv("DASHBOARDS_PUBLIC_MAX_RANGE", "168h"))
+	if err != nil || duration <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return duration
+}
+
+// GetDashboardsMaxQuerySize returns the maximum size in bytes of the query of
+// a dashboard widget
+func GetDashboardsMaxQuerySize() int {
+	size, err := strconv.Atoi(GetOrDefaultEnv("DASHBOARDS_MAX_QUERY_SIZE", "65536"))
+	if err != nil || size <= 0 {
+		return 64 << 10
+	}
+	return size
+}
+
 const (
 	TraceID                        = "traceID"
 	ServiceName                    = "serviceName"
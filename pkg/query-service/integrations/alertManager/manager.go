@@ -22,6 +22,8 @@ type Manager interface {
 	EditRoute(receiver *Receiver) *model.ApiError
 	DeleteRoute(name string) *model.ApiError
 	TestReceiver(receiver *Receiver) *model.ApiError
+	CreateSilence(silence *Silence) (string, *model.ApiError)
+	DeleteSilence(id string) *model.ApiError
 }
 
 func defaultOptions() []ManagerOptions {
@@ -198,3 +200,51 @@ func (m *manager) TestReceiver(receiver *Receiver) *model.ApiError {
 
 	return nil
 }
+
+// CreateSilence creates the silence in alertmanager and returns its id
+func (m *manager) CreateSilence(silence *Silence) (string, *model.ApiError) {
+	silenceBytes, _ := json.Marshal(silence)
+
+	amURL := fmt.Sprintf("%s%s", m.url, "v1/silences")
+	response, err := http.Post(amURL, contentType, bytes.NewBuffer(silenceBytes))
+	if err != nil {
+		zap.L().Error("Error in getting response of API call to alertmanager", zap.String("url", amURL), zap.Error(err))
+		return "", &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 {
+		zap.L().Error("Error in getting 2xx response in API call to alertmanager", zap.String("url", amURL), zap.String("status", response.Status))
+		return "", &model.ApiError{Typ: model.ErrorInternal, Err: fmt.Errorf("error in creating the silence in alertmanager: %s", response.Status)}
+	}
+
+	silenceResponse := SilenceResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&silenceResponse); err != nil {
+		return "", &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	return silenceResponse.Data.SilenceId, nil
+}
+
+// DeleteSilence expires the silence in alertmanager
+func (m *manager) DeleteSilence(id string) *model.ApiError {
+	amURL := fmt.Sprintf("%s%s%s", m.url, "v1/silence/", neturl.PathEscape(id))
+	req, err := http.NewRequest(http.MethodDelete, amURL, nil)
+	if err != nil {
+		zap.L().Error("Error in creating new delete request to alertmanager/v1/silence", zap.Error(err))
+		return &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(req)
+	if err != nil {
+		zap.L().Error("Error in getting response of API call to alertmanager", zap.String("url", amURL), zap.Error(err))
+		return &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 {
+		zap.L().Error("Error in getting 2xx response in API call to alertmanager", zap.String("url", amURL), zap.String("status", response.Status))
+		return &model.ApiError{Typ: model.ErrorInternal, Err: fmt.Errorf("error in deleting the silence in alertmanager: %s", response.Status)}
+	}
+	return nil
+}
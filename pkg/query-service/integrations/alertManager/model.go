@@ -1,7 +1,9 @@
 package alertManager
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.signoz.io/signoz/pkg/query-service/utils/labels"
@@ -29,6 +31,39 @@ type ReceiverResponse struct {
 	Data   Receiver `json:"data"`
 }
 
+// Silence mutes the notifications of the alerts matching all of its
+// matchers between its start and end
+type Silence struct {
+	Matchers  []SilenceMatcher `json:"matchers"`
+	StartsAt  time.Time        `json:"startsAt"`
+	EndsAt    time.Time        `json:"endsAt"`
+	CreatedBy string           `json:"createdBy"`
+	Comment   string           `json:"comment"`
+}
+
+type SilenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// NewSilence returns the silence of the alert with the labels
+func NewSilence(lbls map[string]string, startsAt, endsAt time.Time, createdBy, comment string) *Silence {
+	matchers := make([]SilenceMatcher, 0, len(lbls))
+	for name, value := range lbls {
+		matchers = append(matchers, SilenceMatcher{Name: name, Value: value, IsEqual: true})
+	}
+	return &Silence{Matchers: matchers, StartsAt: startsAt, EndsAt: endsAt, CreatedBy: createdBy, Comment: comment}
+}
+
+type SilenceResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		SilenceId string `json:"silenceId"`
+	} `json:"data"`
+}
+
 // Alert is a generic representation of an alert in the Prometheus eco-system.
 type Alert struct {
 	// Label value pairs for purpose of aggregation, matching, and disposition
@@ -77,3 +112,34 @@ func (a *Alert) ResolvedAt(ts time.Time) bool {
 	}
 	return !a.EndsAt.After(ts)
 }
+
+// Validate checks the configs of the receiver which are typed, filling in
+// their defaults
+func (r *Receiver) Validate() error {
+	if r.SNSConfigs != nil {
+		if err := validateSNSConfigs(r); err != nil {
+			return err
+		}
+	}
+	if r.VictorOpsConfigs != nil {
+		if err := validateVictorOpsConfigs(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeConfigs decodes the untyped configs of a receiver into their type
+func decodeConfigs(configs interface{}, typed interface{}) error {
+	data, err := json.Marshal(configs)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, typed)
+}
+
+// isTemplated reports whether the value is a template rendered by the
+// alertmanager for the notification, such as from a label of the alert
+func isTemplated(s string) bool {
+	return strings.Contains(s, "{{")
+}
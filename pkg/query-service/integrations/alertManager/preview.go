@@ -0,0 +1,274 @@
+package alertManager
+
+import (
+	"encoding/json"
+	"fmt"
+	neturl "net/url"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify/webhook"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	prommodel "github.com/prometheus/common/model"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"gopkg.in/yaml.v2"
+)
+
+// ChannelPreviewParams is a channel and the alerts to render its
+// notifications for. A sample alert is used when there are no alerts.
+type ChannelPreviewParams struct {
+	Receiver Receiver        `json:"receiver"`
+	Alerts   []*PreviewAlert `json:"alerts,omitempty"`
+	// RuleId renders the notifications for the active alerts of the rule
+	RuleId string `json:"ruleId,omitempty"`
+}
+
+// PreviewAlert is an alert as given to the templates of the notifications
+type PreviewAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt,omitempty"`
+	EndsAt       time.Time         `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// ChannelPreview is the notification sent by one config of the channel, with
+// the rendered value of each templated field. Errors has the fields whose
+// template failed to render.
+type ChannelPreview struct {
+	Type   string            `json:"type"`
+	Fields map[string]string `json:"fields"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// NewSampleAlert is the alert the notifications are previewed for when none
+// is given, the same as the one sent when testing a channel
+func NewSampleAlert(receiver *Receiver, now time.Time) *PreviewAlert {
+	return &PreviewAlert{
+		Labels: map[string]string{
+			labels.AlertNameLabel:     fmt.Sprintf("Test Alert (%s)", receiver.Name),
+			labels.AlertSeverityLabel: "critical",
+		},
+		Annotations: map[string]string{
+			"description": "Test alert fired from SigNoz",
+			"summary":     "Test alert fired from SigNoz",
+			"message":     "Test alert fired from SigNoz",
+		},
+		StartsAt: now,
+	}
+}
+
+// PreviewReceiver renders the templates of every config of the receiver for
+// the alerts, as the alertmanager would when notifying them. The configs get
+// the same defaults as in the alertmanager, so the preview of a field left
+// empty is the default template.
+func PreviewReceiver(receiver *Receiver, alerts []*PreviewAlert, externalURL *neturl.URL) ([]ChannelPreview, error) {
+	if len(alerts) == 0 {
+		return nil, fmt.Errorf("no alerts to preview the notifications for")
+	}
+
+	data, err := json.Marshal(receiver)
+	if err != nil {
+		return nil, err
+	}
+	// the defaults of the configs are only set when they are read from yaml,
+	// which the json of the receiver is
+	amReceiver := config.Receiver{}
+	if err := yaml.Unmarshal(data, &amReceiver); err != nil {
+		return nil, fmt.Errorf("invalid channel: %w", err)
+	}
+	// the payload templates of the webhooks are not in the alertmanager config
+	webhooks := struct {
+		WebhookConfigs []struct {
+			PayloadTemplate string `json:"payload_template"`
+		} `json:"webhook_configs"`
+	}{}
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return nil, fmt.Errorf("invalid channel: %w", err)
+	}
+
+	tmpl, err := template.FromGlobs(nil)
+	if err != nil {
+		return nil, err
+	}
+	if externalURL == nil {
+		externalURL = &neturl.URL{}
+	}
+	tmpl.ExternalURL = externalURL
+
+	amAlerts := make([]*types.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		amAlerts = append(amAlerts, toTemplateAlert(alert))
+	}
+	// the channels are notified for the alerts grouped by their name
+	groupLabels := prommodel.LabelSet{}
+	if name := alerts[0].Labels[labels.AlertNameLabel]; name != "" {
+		groupLabels[labels.AlertNameLabel] = prommodel.LabelValue(name)
+	}
+	r := &previewRenderer{tmpl: tmpl, data: tmpl.Data(amReceiver.Name, groupLabels, amAlerts...)}
+
+	previews := []ChannelPreview{}
+	for _, c := range amReceiver.SlackConfigs {
+		p := r.preview("slack")
+		r.text(p, "title", c.Title)
+		r.text(p, "title_link", c.TitleLink)
+		r.text(p, "pretext", c.Pretext)
+		r.text(p, "text", c.Text)
+		r.text(p, "fallback", c.Fallback)
+		r.text(p, "footer", c.Footer)
+		previews = append(previews, *p)
+	}
+	for _, c := range amReceiver.EmailConfigs {
+		p := r.preview("email")
+		r.text(p, "to", c.To)
+		r.textMap(p, "headers", c.Headers)
+		r.html(p, "html", c.HTML)
+		r.text(p, "text", c.Text)
+		previews = append(previews, *p)
+	}
+	for _, c := range amReceiver.PagerdutyConfigs {
+		p := r.preview("pagerduty")
+		r.text(p, "description", c.Description)
+		r.text(p, "client", c.Client)
+		r.text(p, "client_url", c.ClientURL)
+		r.text(p, "severity", c.Severity)
+		r.text(p, "class", c.Class)
+		r.text(p, "component", c.Component)
+		r.text(p, "group", c.Group)
+		r.textMap(p, "details", c.Details)
+		previews = append(previews, *p)
+	}
+	for _, c := range amReceiver.OpsGenieConfigs {
+		p := r.preview("opsgenie")
+		r.text(p, "message", c.Message)
+		r.text(p, "description", c.Description)
+		r.text(p, "source", c.Source)
+		r.text(p, "priority", c.Priority)
+		r.text(p, "tags", c.Tags)
+		r.text(p, "note", c.Note)
+		r.textMap(p, "details", c.Details)
+		previews = append(previews, *p)
+	}
+	for _, c := range amReceiver.MSTeamsConfigs {
+		p := r.preview("msteams")
+		r.text(p, "title", c.Title)
+		r.text(p, "summary", c.Summary)
+		r.text(p, "text", c.Text)
+		previews = append(previews, *p)
+	}
+	for _, c := range amReceiver.SNSConfigs {
+		p := r.preview("sns")
+		r.text(p, "subject", c.Subject)
+		r.text(p, "message", c.Message)
+		r.textMap(p, "attributes", c.Attributes)
+		previews = append(previews, *p)
+	}
+	for _, c := range amReceiver.VictorOpsConfigs {
+		p := r.preview("victorops")
+		r.text(p, "message_type", c.MessageType)
+		r.text(p, "entity_display_name", c.EntityDisplayName)
+		r.text(p, "state_message", c.StateMessage)
+		r.textMap(p, "custom_fields", c.CustomFields)
+		previews = append(previews, *p)
+	}
+	for _, c := range amReceiver.PushoverConfigs {
+		p := r.preview("pushover")
+		r.text(p, "title", c.Title)
+		r.text(p, "message", c.Message)
+		r.text(p, "url", c.URL)
+		previews = append(previews, *p)
+	}
+	for _, c := range amReceiver.WechatConfigs {
+		p := r.preview("wechat")
+		r.text(p, "message", c.Message)
+		previews = append(previews, *p)
+	}
+	for i := range amReceiver.WebhookConfigs {
+		p := r.preview("webhook")
+		if i < len(webhooks.WebhookConfigs) && webhooks.WebhookConfigs[i].PayloadTemplate != "" {
+			r.text(p, payloadField, webhooks.WebhookConfigs[i].PayloadTemplate)
+			previews = append(previews, *p)
+			continue
+		}
+		// webhooks without a payload template are sent the alerts as they are
+		payload, err := json.MarshalIndent(&webhook.Message{Data: r.data, Version: "4"}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		p.Fields[payloadField] = string(payload)
+		previews = append(previews, *p)
+	}
+
+	if len(previews) == 0 {
+		return nil, fmt.Errorf("channel %s has no configs to preview", receiver.Name)
+	}
+	return previews, nil
+}
+
+// toTemplateAlert converts the alert to the alert of the alertmanager
+func toTemplateAlert(alert *PreviewAlert) *types.Alert {
+	a := &types.Alert{
+		Alert: prommodel.Alert{
+			Labels:       prommodel.LabelSet{},
+			Annotations:  prommodel.LabelSet{},
+			StartsAt:     alert.StartsAt,
+			EndsAt:       alert.EndsAt,
+			GeneratorURL: alert.GeneratorURL,
+		},
+		UpdatedAt: alert.StartsAt,
+	}
+	for name, value := range alert.Labels {
+		a.Labels[prommodel.LabelName(name)] = prommodel.LabelValue(value)
+	}
+	for name, value := range alert.Annotations {
+		a.Annotations[prommodel.LabelName(name)] = prommodel.LabelValue(value)
+	}
+	return a
+}
+
+type previewRenderer struct {
+	tmpl *template.Template
+	data *template.Data
+}
+
+func (r *previewRenderer) preview(typ string) *ChannelPreview {
+	return &ChannelPreview{Type: typ, Fields: map[string]string{}}
+}
+
+func (r *previewRenderer) text(p *ChannelPreview, field, text string) {
+	r.render(p, field, text, r.tmpl.ExecuteTextString)
+}
+
+func (r *previewRenderer) html(p *ChannelPreview, field, html string) {
+	r.render(p, field, html, r.tmpl.ExecuteHTMLString)
+}
+
+// textMap renders the values of the map, such as the headers of an email, as
+// the fields field.key
+func (r *previewRenderer) textMap(p *ChannelPreview, field string, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		r.text(p, field+"."+key, m[key])
+	}
+}
+
+func (r *previewRenderer) render(p *ChannelPreview, field, text string, execute func(string, interface{}) (string, error)) {
+	if text == "" {
+		return
+	}
+	rendered, err := execute(text, r.data)
+	if err != nil {
+		if p.Errors == nil {
+			p.Errors = map[string]string{}
+		}
+		p.Errors[field] = err.Error()
+		return
+	}
+	p.Fields[field] = rendered
+}
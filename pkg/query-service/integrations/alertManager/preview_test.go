@@ -0,0 +1,44 @@
+package alertManager
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewReceiver(t *testing.T) {
+	receiver := &Receiver{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"name": "oncall",
+		"slack_configs": [{"api_url": "https://hooks.slack.com/services/xyz", "channel": "#alerts", "text": "{{ range .Alerts }}{{ .Annotations.summary }} on {{ .Labels.service }}{{ end }}"}],
+		"pagerduty_configs": [{"routing_key": "key", "description": "{{ .CommonLabels.alertname", "severity": "{{ .CommonLabels.severity }}"}],
+		"webhook_configs": [{"url": "https://example.com/hook"}]
+	}`), receiver))
+
+	alert := NewSampleAlert(receiver, time.Now())
+	alert.Labels["service"] = "checkout"
+	previews, err := PreviewReceiver(receiver, []*PreviewAlert{alert}, nil)
+	require.NoError(t, err)
+	require.Len(t, previews, 3)
+
+	slack := previews[0]
+	require.Equal(t, "slack", slack.Type)
+	require.Equal(t, "Test alert fired from SigNoz on checkout", slack.Fields["text"])
+	// the fields left empty are rendered with the default templates
+	require.Equal(t, "[FIRING:1] Test Alert (oncall) (checkout critical)", slack.Fields["title"])
+	require.Empty(t, slack.Errors)
+
+	pagerduty := previews[1]
+	require.Equal(t, "pagerduty", pagerduty.Type)
+	require.Equal(t, "critical", pagerduty.Fields["severity"])
+	require.Contains(t, pagerduty.Errors, "description")
+
+	webhook := previews[2]
+	require.Equal(t, "webhook", webhook.Type)
+	require.Contains(t, webhook.Fields["payload"], `"service": "checkout"`)
+
+	_, err = PreviewReceiver(&Receiver{Name: "empty"}, []*PreviewAlert{alert}, nil)
+	require.Error(t, err)
+}
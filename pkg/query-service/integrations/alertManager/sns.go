@@ -0,0 +1,106 @@
+package alertManager
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// SNSConfig configures the notifications sent to an AWS SNS topic, or to a
+// phone number or a platform endpoint, by the alertmanager
+type SNSConfig struct {
+	SendResolved *bool       `yaml:"send_resolved,omitempty" json:"send_resolved,omitempty"`
+	HTTPConfig   interface{} `yaml:"http_config,omitempty" json:"http_config,omitempty"`
+
+	APIUrl      string            `yaml:"api_url,omitempty" json:"api_url,omitempty"`
+	Sigv4       SigV4Config       `yaml:"sigv4" json:"sigv4"`
+	TopicARN    string            `yaml:"topic_arn,omitempty" json:"topic_arn,omitempty"`
+	PhoneNumber string            `yaml:"phone_number,omitempty" json:"phone_number,omitempty"`
+	TargetARN   string            `yaml:"target_arn,omitempty" json:"target_arn,omitempty"`
+	Subject     string            `yaml:"subject,omitempty" json:"subject,omitempty"`
+	Message     string            `yaml:"message,omitempty" json:"message,omitempty"`
+	Attributes  map[string]string `yaml:"attributes,omitempty" json:"attributes,omitempty"`
+}
+
+// SigV4Config holds the credentials the alertmanager signs the SNS requests
+// with. Without the access and secret keys the default credentials of the
+// alertmanager are used, such as the role of its service account (IRSA) or
+// of its instance.
+type SigV4Config struct {
+	Region    string `yaml:"region,omitempty" json:"region,omitempty"`
+	AccessKey string `yaml:"access_key,omitempty" json:"access_key,omitempty"`
+	SecretKey string `yaml:"secret_key,omitempty" json:"secret_key,omitempty"`
+	Profile   string `yaml:"profile,omitempty" json:"profile,omitempty"`
+	RoleARN   string `yaml:"role_arn,omitempty" json:"role_arn,omitempty"`
+}
+
+var (
+	snsTopicARNRegexp = regexp.MustCompile(`^arn:(aws[a-z-]*):sns:([a-z0-9-]+):(\d{12}):([A-Za-z0-9_-]{1,256}(\.fifo)?)$`)
+	iamRoleARNRegexp  = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]+$`)
+	phoneNumberRegexp = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+)
+
+// validateSNSConfigs checks the SNS configs of the receiver. The region of a
+// config missing it is taken from its topic, as the alertmanager would
+// otherwise use its own region.
+func validateSNSConfigs(r *Receiver) error {
+	configs := []SNSConfig{}
+	if err := decodeConfigs(r.SNSConfigs, &configs); err != nil {
+		return fmt.Errorf("invalid sns configs: %w", err)
+	}
+	if len(configs) == 0 {
+		return fmt.Errorf("missing sns configs")
+	}
+
+	for i := range configs {
+		if err := configs[i].validate(); err != nil {
+			return fmt.Errorf("invalid sns config: %w", err)
+		}
+	}
+	r.SNSConfigs = configs
+	return nil
+}
+
+func (c *SNSConfig) validate() error {
+	targets := 0
+	for _, target := range []string{c.TopicARN, c.PhoneNumber, c.TargetARN} {
+		if target != "" {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return fmt.Errorf("exactly one of topic_arn, phone_number or target_arn must be set")
+	}
+
+	if c.TopicARN != "" {
+		// the topic may be templated, such as by a label of the alert
+		if match := snsTopicARNRegexp.FindStringSubmatch(c.TopicARN); match != nil {
+			if c.Sigv4.Region == "" {
+				c.Sigv4.Region = match[2]
+			} else if c.Sigv4.Region != match[2] {
+				return fmt.Errorf("region %s does not match the region of the topic %s", c.Sigv4.Region, match[2])
+			}
+		} else if !isTemplated(c.TopicARN) {
+			return fmt.Errorf("invalid topic arn %q", c.TopicARN)
+		}
+	}
+	if c.PhoneNumber != "" && !phoneNumberRegexp.MatchString(c.PhoneNumber) && !isTemplated(c.PhoneNumber) {
+		return fmt.Errorf("invalid phone number %q, it must be in the E.164 format", c.PhoneNumber)
+	}
+	if c.Sigv4.Region == "" {
+		return fmt.Errorf("missing region")
+	}
+
+	if (c.Sigv4.AccessKey == "") != (c.Sigv4.SecretKey == "") {
+		return fmt.Errorf("access_key and secret_key must be set together")
+	}
+	if c.Sigv4.RoleARN != "" && !iamRoleARNRegexp.MatchString(c.Sigv4.RoleARN) {
+		return fmt.Errorf("invalid role arn %q", c.Sigv4.RoleARN)
+	}
+	if c.APIUrl != "" {
+		if u, err := url.Parse(c.APIUrl); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid api_url %q", c.APIUrl)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package alertManager
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiverValidateSNS(t *testing.T) {
+	cases := []struct {
+		name    string
+		configs string
+		region  string
+		wantErr bool
+	}{
+		{
+			name:    "region from the topic with irsa",
+			configs: `[{"topic_arn": "arn:aws:sns:eu-west-1:123456789012:alerts", "send_resolved": true}]`,
+			region:  "eu-west-1",
+		},
+		{
+			name:    "static credentials",
+			configs: `[{"topic_arn": "arn:aws:sns:us-east-1:123456789012:alerts.fifo", "sigv4": {"region": "us-east-1", "access_key": "AKIA", "secret_key": "secret"}}]`,
+			region:  "us-east-1",
+		},
+		{
+			name:    "templated topic",
+			configs: `[{"topic_arn": "{{ .CommonLabels.topic }}", "sigv4": {"region": "us-east-1", "role_arn": "arn:aws:iam::123456789012:role/alerts"}}]`,
+			region:  "us-east-1",
+		},
+		{
+			name:    "phone number without region",
+			configs: `[{"phone_number": "+15555550100"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "region not matching the topic",
+			configs: `[{"topic_arn": "arn:aws:sns:eu-west-1:123456789012:alerts", "sigv4": {"region": "us-east-1"}}]`,
+			wantErr: true,
+		},
+		{
+			name:    "access key without secret key",
+			configs: `[{"topic_arn": "arn:aws:sns:eu-west-1:123456789012:alerts", "sigv4": {"access_key": "AKIA"}}]`,
+			wantErr: true,
+		},
+		{
+			name:    "topic and phone number",
+			configs: `[{"topic_arn": "arn:aws:sns:eu-west-1:123456789012:alerts", "phone_number": "+15555550100"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid topic",
+			configs: `[{"topic_arn": "alerts", "sigv4": {"region": "us-east-1"}}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			receiver := &Receiver{}
+			require.NoError(t, json.Unmarshal([]byte(`{"name": "sns", "sns_configs": `+c.configs+`}`), receiver))
+
+			err := receiver.Validate()
+			if c.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			configs := receiver.SNSConfigs.([]SNSConfig)
+			require.Equal(t, c.region, configs[0].Sigv4.Region)
+		})
+	}
+}
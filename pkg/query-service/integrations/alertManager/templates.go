@@ -0,0 +1,224 @@
+package alertManager
+
+import (
+	"encoding/json"
+	"fmt"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/template"
+	prommodel "github.com/prometheus/common/model"
+)
+
+// ChannelTemplate is the template of the notifications of a channel type, the
+// templates of the fields of its configs by the name of the field. The fields
+// are named as in the previews of the channels, such as headers.Subject for
+// the subject of the emails and payload for the body of the webhooks.
+type ChannelTemplate map[string]string
+
+// payloadField is the body of the webhooks, which the alertmanager doesn't
+// template. It is set as the payload template of the webhook config.
+const payloadField = "payload"
+
+// channelTemplateFields are the fields of the configs of each channel type
+// which can be templated, the ones ending with a dot are maps templated by
+// their keys
+var channelTemplateFields = map[string][]string{
+	"slack":     {"title", "title_link", "pretext", "text", "fallback", "footer"},
+	"email":     {"html", "text", "headers."},
+	"pagerduty": {"description", "client", "client_url", "severity", "class", "component", "group", "details."},
+	"opsgenie":  {"message", "description", "source", "priority", "tags", "note", "details."},
+	"msteams":   {"title", "summary", "text"},
+	"sns":       {"subject", "message", "attributes."},
+	"victorops": {"message_type", "entity_display_name", "state_message", "custom_fields."},
+	"pushover":  {"title", "message", "url"},
+	"wechat":    {"message"},
+	"webhook":   {payloadField},
+}
+
+const defaultTitleTemplate = `[{{ .Status | toUpper }}{{ if eq .Status "firing" }}:{{ .Alerts.Firing | len }}{{ end }}] {{ .CommonLabels.alertname }}`
+
+// defaultChannelTemplates are the templates of the channel types which are not
+// set. The types without one use the default templates of the alertmanager,
+// and the webhooks are sent the message of the alertmanager.
+var defaultChannelTemplates = map[string]ChannelTemplate{
+	"slack": {
+		"title": defaultTitleTemplate,
+		"text": `{{ range .Alerts }}*{{ .Annotations.summary }}*
+{{ if .Annotations.description }}{{ .Annotations.description }}
+{{ end }}{{ range .Labels.SortedPairs }}• *{{ .Name }}:* ` + "`{{ .Value }}`" + `
+{{ end }}
+{{ end }}`,
+	},
+	"msteams": {
+		"title": defaultTitleTemplate,
+		"text": `{{ range .Alerts }}**{{ .Annotations.summary }}**
+
+{{ if .Annotations.description }}{{ .Annotations.description }}
+
+{{ end }}{{ range .Labels.SortedPairs }}- **{{ .Name }}:** {{ .Value }}
+{{ end }}
+{{ end }}`,
+	},
+	"email": {
+		"headers.Subject": defaultTitleTemplate,
+		"html": `<html>
+<body style="font-family: sans-serif;">
+<h2>{{ .CommonLabels.alertname }} is {{ .Status }}</h2>
+{{ range .Alerts }}
+<h3>{{ .Annotations.summary }}</h3>
+{{ if .Annotations.description }}<p>{{ .Annotations.description }}</p>{{ end }}
+<table>
+{{ range .Labels.SortedPairs }}<tr><td><b>{{ .Name }}</b></td><td>{{ .Value }}</td></tr>
+{{ end }}</table>
+{{ if .GeneratorURL }}<p><a href="{{ .GeneratorURL }}">View in SigNoz</a></p>{{ end }}
+{{ end }}
+</body>
+</html>`,
+	},
+}
+
+// DefaultChannelTemplates returns the default templates of the channel types
+func DefaultChannelTemplates() map[string]ChannelTemplate {
+	templates := make(map[string]ChannelTemplate, len(defaultChannelTemplates))
+	for typ, t := range defaultChannelTemplates {
+		templates[typ] = t.copy()
+	}
+	return templates
+}
+
+func (t ChannelTemplate) copy() ChannelTemplate {
+	c := make(ChannelTemplate, len(t))
+	for field, text := range t {
+		c[field] = text
+	}
+	return c
+}
+
+// Validate checks the fields of the template are templated fields of the
+// channel type, and renders them for a sample alert
+func (t ChannelTemplate) Validate(typ string) error {
+	fields, ok := channelTemplateFields[typ]
+	if !ok {
+		return fmt.Errorf("unknown channel type %q", typ)
+	}
+	if len(t) == 0 {
+		return fmt.Errorf("missing the templates of the fields")
+	}
+
+	tmpl, err := template.FromGlobs(nil)
+	if err != nil {
+		return err
+	}
+	tmpl.ExternalURL = &neturl.URL{}
+	alert := NewSampleAlert(&Receiver{Name: typ}, time.Now())
+	r := &previewRenderer{tmpl: tmpl, data: tmpl.Data(typ, prommodel.LabelSet{}, toTemplateAlert(alert))}
+	p := r.preview(typ)
+
+	names := make([]string, 0, len(t))
+	for field := range t {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+	for _, field := range names {
+		if !isTemplateField(fields, field) {
+			return fmt.Errorf("%s channels have no templated field %q", typ, field)
+		}
+		if typ == "email" && field == "html" {
+			r.html(p, field, t[field])
+		} else {
+			r.text(p, field, t[field])
+		}
+		if err, ok := p.Errors[field]; ok {
+			return fmt.Errorf("invalid template of %s: %s", field, err)
+		}
+		if field == payloadField && !json.Valid([]byte(p.Fields[field])) {
+			return fmt.Errorf("the payload template renders an invalid json body")
+		}
+	}
+	return nil
+}
+
+func isTemplateField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field || (strings.HasSuffix(f, ".") && strings.HasPrefix(field, f) && len(field) > len(f)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyTemplates sets the fields of the configs of the receiver left empty
+// from the templates of their channel type. The configs are replaced rather
+// than changed, for the receiver to be a copy of a stored one.
+func (r *Receiver) ApplyTemplates(templates map[string]ChannelTemplate) error {
+	for typ, configs := range r.configsByType() {
+		t := templates[typ]
+		if *configs == nil || len(t) == 0 {
+			continue
+		}
+		decoded := []map[string]interface{}{}
+		if err := decodeConfigs(*configs, &decoded); err != nil {
+			return fmt.Errorf("invalid %s configs: %w", typ, err)
+		}
+		for _, c := range decoded {
+			t.apply(c)
+		}
+		*configs = decoded
+	}
+	return nil
+}
+
+func (r *Receiver) configsByType() map[string]*interface{} {
+	return map[string]*interface{}{
+		"email":     &r.EmailConfigs,
+		"pagerduty": &r.PagerdutyConfigs,
+		"slack":     &r.SlackConfigs,
+		"webhook":   &r.WebhookConfigs,
+		"opsgenie":  &r.OpsGenieConfigs,
+		"wechat":    &r.WechatConfigs,
+		"pushover":  &r.PushoverConfigs,
+		"victorops": &r.VictorOpsConfigs,
+		"sns":       &r.SNSConfigs,
+		"msteams":   &r.MSTeamsConfigs,
+	}
+}
+
+// apply sets the fields of the config which are not set from the template
+func (t ChannelTemplate) apply(config map[string]interface{}) {
+	for field, text := range t {
+		if field == payloadField {
+			if v, _ := config["payload_template"].(string); v == "" {
+				config["payload_template"] = text
+			}
+			continue
+		}
+		if name, key, ok := strings.Cut(field, "."); ok {
+			m, _ := config[name].(map[string]interface{})
+			if m == nil {
+				m = map[string]interface{}{}
+			}
+			if !hasKey(m, key) {
+				m[key] = text
+			}
+			config[name] = m
+			continue
+		}
+		if v, _ := config[field].(string); v == "" {
+			config[field] = text
+		}
+	}
+}
+
+// hasKey tells if the map has the key, the keys of the headers of the emails
+// being case insensitive
+func hasKey(m map[string]interface{}, key string) bool {
+	for k := range m {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package alertManager
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelTemplateValidate(t *testing.T) {
+	require.NoError(t, ChannelTemplate{"title": "{{ .CommonLabels.alertname }}", "text": "{{ .Status }}"}.Validate("slack"))
+	require.NoError(t, ChannelTemplate{"headers.Subject": "{{ .Status }}", "html": "<b>{{ .Status }}</b>"}.Validate("email"))
+	require.NoError(t, ChannelTemplate{"payload": `{"status": "{{ .Status }}"}`}.Validate("webhook"))
+	for typ, template := range DefaultChannelTemplates() {
+		require.NoError(t, template.Validate(typ), typ)
+	}
+
+	require.Error(t, ChannelTemplate{"title": "{{ .Status }}"}.Validate("carrier-pigeon"))
+	require.Error(t, ChannelTemplate{"html": "{{ .Status }}"}.Validate("slack"))
+	require.Error(t, ChannelTemplate{"text": "{{ .Status"}.Validate("slack"))
+	require.Error(t, ChannelTemplate{"payload": `{"status": {{ .Status }}}`}.Validate("webhook"))
+	require.Error(t, ChannelTemplate{}.Validate("slack"))
+}
+
+func TestReceiverApplyTemplates(t *testing.T) {
+	receiver := &Receiver{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"name": "oncall",
+		"slack_configs": [{"api_url": "https://hooks.slack.com/services/xyz", "channel": "#alerts", "title": "{{ .CommonLabels.service }} is down"}],
+		"email_configs": [{"to": "oncall@signoz.io", "headers": {"subject": "custom"}}],
+		"webhook_configs": [{"url": "https://example.com/hook"}]
+	}`), receiver))
+	stored, err := json.Marshal(receiver)
+	require.NoError(t, err)
+
+	templates := DefaultChannelTemplates()
+	templates["webhook"] = ChannelTemplate{"payload": `{"text": "{{ .CommonLabels.alertname }} is {{ .Status }}"}`}
+	templated := *receiver
+	require.NoError(t, templated.ApplyTemplates(templates))
+
+	// the receiver is not changed
+	unchanged, err := json.Marshal(receiver)
+	require.NoError(t, err)
+	require.JSONEq(t, string(stored), string(unchanged))
+
+	alert := NewSampleAlert(&templated, time.Now())
+	previews, err := PreviewReceiver(&templated, []*PreviewAlert{alert}, nil)
+	require.NoError(t, err)
+	require.Len(t, previews, 3)
+
+	// the fields set by the channel are kept
+	slack := previews[0]
+	require.Equal(t, " is down", slack.Fields["title"])
+	require.Contains(t, slack.Fields["text"], "*Test alert fired from SigNoz*")
+
+	email := previews[1]
+	require.Equal(t, "custom", email.Fields["headers.Subject"])
+	require.Contains(t, email.Fields["html"], "<h2>Test Alert (oncall) is firing</h2>")
+
+	webhook := previews[2]
+	require.JSONEq(t, `{"text": "Test Alert (oncall) is firing"}`, webhook.Fields["payload"])
+}
@@ -0,0 +1,88 @@
+package alertManager
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+)
+
+const (
+	VictorOpsMessageTypeCritical = "CRITICAL"
+	VictorOpsMessageTypeWarning  = "WARNING"
+	VictorOpsMessageTypeInfo     = "INFO"
+	VictorOpsMessageTypeRecovery = "RECOVERY"
+)
+
+// victorOpsMessageType maps the status of the alerts to the incident
+// lifecycle of Splunk On-Call, firing alerts open a critical incident and
+// resolved ones recover it
+const victorOpsMessageType = `{{ if eq .Status "firing" }}` + VictorOpsMessageTypeCritical + `{{ else }}` + VictorOpsMessageTypeRecovery + `{{ end }}`
+
+// VictorOpsConfig configures the incidents opened in Splunk On-Call, formerly
+// VictorOps, by the alertmanager
+type VictorOpsConfig struct {
+	SendResolved *bool       `yaml:"send_resolved,omitempty" json:"send_resolved,omitempty"`
+	HTTPConfig   interface{} `yaml:"http_config,omitempty" json:"http_config,omitempty"`
+
+	APIKey            string            `yaml:"api_key,omitempty" json:"api_key,omitempty"`
+	APIURL            string            `yaml:"api_url,omitempty" json:"api_url,omitempty"`
+	RoutingKey        string            `yaml:"routing_key" json:"routing_key"`
+	MessageType       string            `yaml:"message_type,omitempty" json:"message_type,omitempty"`
+	StateMessage      string            `yaml:"state_message,omitempty" json:"state_message,omitempty"`
+	EntityDisplayName string            `yaml:"entity_display_name,omitempty" json:"entity_display_name,omitempty"`
+	MonitoringTool    string            `yaml:"monitoring_tool,omitempty" json:"monitoring_tool,omitempty"`
+	CustomFields      map[string]string `yaml:"custom_fields,omitempty" json:"custom_fields,omitempty"`
+}
+
+var victorOpsRoutingKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// validateVictorOpsConfigs checks the Splunk On-Call configs of the receiver.
+// The configs without a message type map the status of the alerts to the
+// lifecycle of the incident, and send the resolved alerts to recover it.
+func validateVictorOpsConfigs(r *Receiver) error {
+	configs := []VictorOpsConfig{}
+	if err := decodeConfigs(r.VictorOpsConfigs, &configs); err != nil {
+		return fmt.Errorf("invalid victorops configs: %w", err)
+	}
+	if len(configs) == 0 {
+		return fmt.Errorf("missing victorops configs")
+	}
+
+	for i := range configs {
+		if err := configs[i].validate(); err != nil {
+			return fmt.Errorf("invalid victorops config: %w", err)
+		}
+	}
+	r.VictorOpsConfigs = configs
+	return nil
+}
+
+func (c *VictorOpsConfig) validate() error {
+	if c.APIKey == "" {
+		return fmt.Errorf("missing api_key")
+	}
+	if c.RoutingKey == "" {
+		return fmt.Errorf("missing routing_key")
+	}
+	if !victorOpsRoutingKeyRegexp.MatchString(c.RoutingKey) && !isTemplated(c.RoutingKey) {
+		return fmt.Errorf("invalid routing_key %q", c.RoutingKey)
+	}
+
+	if c.MessageType == "" {
+		c.MessageType = victorOpsMessageType
+		if c.SendResolved == nil {
+			sendResolved := true
+			c.SendResolved = &sendResolved
+		}
+	} else if !isTemplated(c.MessageType) && !slices.Contains([]string{VictorOpsMessageTypeCritical, VictorOpsMessageTypeWarning, VictorOpsMessageTypeInfo}, c.MessageType) {
+		return fmt.Errorf("invalid message_type %q, it must be one of CRITICAL, WARNING or INFO", c.MessageType)
+	}
+
+	if c.APIURL != "" {
+		if u, err := url.Parse(c.APIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid api_url %q", c.APIURL)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package alertManager
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiverValidateVictorOps(t *testing.T) {
+	receiver := &Receiver{}
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "oncall", "victorops_configs": [{"api_key": "key", "routing_key": "checkout-team"}]}`), receiver))
+	require.NoError(t, receiver.Validate())
+
+	// the status of the alerts is mapped to the lifecycle of the incident
+	config := receiver.VictorOpsConfigs.([]VictorOpsConfig)[0]
+	require.Equal(t, victorOpsMessageType, config.MessageType)
+	require.True(t, *config.SendResolved)
+
+	data, err := json.Marshal(receiver)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"routing_key":"checkout-team"`)
+
+	receiver = &Receiver{}
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "oncall", "victorops_configs": [{"api_key": "key", "routing_key": "checkout-team", "message_type": "WARNING", "send_resolved": false}]}`), receiver))
+	require.NoError(t, receiver.Validate())
+	config = receiver.VictorOpsConfigs.([]VictorOpsConfig)[0]
+	require.Equal(t, VictorOpsMessageTypeWarning, config.MessageType)
+	require.False(t, *config.SendResolved)
+
+	for _, configs := range []string{
+		`[{"api_key": "key"}]`,
+		`[{"routing_key": "checkout-team"}]`,
+		`[{"api_key": "key", "routing_key": "checkout team"}]`,
+		`[{"api_key": "key", "routing_key": "checkout-team", "message_type": "RECOVERY"}]`,
+		`[]`,
+	} {
+		receiver = &Receiver{}
+		require.NoError(t, json.Unmarshal([]byte(`{"name": "oncall", "victorops_configs": `+configs+`}`), receiver))
+		require.Error(t, receiver.Validate(), configs)
+	}
+}
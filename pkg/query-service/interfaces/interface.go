@@ -16,6 +16,9 @@ import (
 type Reader interface {
 	GetInstantQueryMetricsResult(ctx context.Context, query *model.InstantQueryMetricsParams) (*promql.Result, *stats.QueryStats, *model.ApiError)
 	GetQueryRangeResult(ctx context.Context, query *model.QueryRangeParams) (*promql.Result, *stats.QueryStats, *model.ApiError)
+	GetPromSeries(ctx context.Context, params *model.PromMetadataParams) ([]map[string]string, *model.ApiError)
+	GetPromLabelNames(ctx context.Context, params *model.PromMetadataParams) ([]string, *model.ApiError)
+	GetPromLabelValues(ctx context.Context, name string, params *model.PromMetadataParams) ([]string, *model.ApiError)
 	GetTopLevelOperations(ctx context.Context, skipConfig *model.SkipConfig, start, end time.Time, services []string) (*map[string][]string, *model.ApiError)
 	GetServices(ctx context.Context, query *model.GetServicesParams, skipConfig *model.SkipConfig) (*[]model.ServiceItem, *model.ApiError)
 	GetTopOperations(ctx context.Context, query *model.GetTopOperationsParams) (*[]model.TopOperationsItem, *model.ApiError)
@@ -112,6 +115,7 @@ type Reader interface {
 	SubscribeToQueryProgress(queryId string) (<-chan model.QueryProgress, func(), *model.ApiError)
 
 	GetCountOfThings(ctx context.Context, query string) (uint64, error)
+	EstimateQuery(ctx context.Context, query string) ([]v3.QueryEstimate, error)
 
 	//trace
 	GetTraceFields(ctx context.Context) (*model.GetFieldsResponse, *model.ApiError)
@@ -2,6 +2,8 @@ package model
 
 import (
 	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
 )
 
 type InstantQueryMetricsParams struct {
@@ -10,6 +12,16 @@ type InstantQueryMetricsParams struct {
 	Stats string
 }
 
+// PromMetadataParams are the params of the series, labels and label values
+// endpoints of the Prometheus HTTP API. The series match any of the sets of
+// matchers.
+type PromMetadataParams struct {
+	Matchers [][]*labels.Matcher
+	Start    time.Time
+	End      time.Time
+	Limit    uint64
+}
+
 type QueryRangeParams struct {
 	Start time.Time
 	End   time.Time
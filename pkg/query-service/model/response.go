@@ -62,6 +62,7 @@ const (
 	ErrorUnauthorized             ErrorType = "unauthorized"
 	ErrorForbidden                ErrorType = "forbidden"
 	ErrorConflict                 ErrorType = "conflict"
+	ErrorTooLarge                 ErrorType = "too_large"
 	ErrorStreamingNotSupported    ErrorType = "streaming is not supported"
 	ErrorStatusServiceUnavailable ErrorType = "service unavailable"
 )
@@ -698,6 +699,8 @@ type AlertsInfo struct {
 	OpsGenieChannels             int      `json:"opsGenieChannels"`
 	EmailChannels                int      `json:"emailChannels"`
 	MSTeamsChannels              int      `json:"microsoftTeamsChannels"`
+	SNSChannels                  int      `json:"snsChannels"`
+	VictorOpsChannels            int      `json:"victorOpsChannels"`
 	MetricsBuilderQueries        int      `json:"metricsBuilderQueries"`
 	MetricsClickHouseQueries     int      `json:"metricsClickHouseQueries"`
 	MetricsPrometheusQueries     int      `json:"metricsPrometheusQueries"`
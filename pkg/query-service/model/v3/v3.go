@@ -183,11 +183,12 @@ const (
 	QueryTypeBuilder       QueryType = "builder"
 	QueryTypeClickHouseSQL QueryType = "clickhouse_sql"
 	QueryTypePromQL        QueryType = "promql"
+	QueryTypeTraceQL       QueryType = "traceql"
 )
 
 func (q QueryType) Validate() error {
 	switch q {
-	case QueryTypeBuilder, QueryTypeClickHouseSQL, QueryTypePromQL:
+	case QueryTypeBuilder, QueryTypeClickHouseSQL, QueryTypePromQL, QueryTypeTraceQL:
 		return nil
 	default:
 		return fmt.Errorf("invalid query type: %s", q)
@@ -380,6 +381,9 @@ type QueryRangeParamsV3 struct {
 	NoCache        bool                   `json:"noCache"`
 	Version        string                 `json:"-"`
 	FormatForWeb   bool                   `json:"formatForWeb,omitempty"`
+	// Downsampled tells whether the step of some queries was coarsened to
+	// keep their series under the points budget
+	Downsampled bool `json:"-"`
 }
 
 func (q *QueryRangeParamsV3) Clone() *QueryRangeParamsV3 {
@@ -395,6 +399,7 @@ func (q *QueryRangeParamsV3) Clone() *QueryRangeParamsV3 {
 		NoCache:        q.NoCache,
 		Version:        q.Version,
 		FormatForWeb:   q.FormatForWeb,
+		Downsampled:    q.Downsampled,
 	}
 }
 
@@ -433,6 +438,9 @@ type ClickHouseQuery struct {
 	Query    string `json:"query"`
 	Disabled bool   `json:"disabled"`
 	Legend   string `json:"legend,omitempty"`
+	// Template is the query before the variables are rendered in, to render
+	// it again for the time ranges missing in the cache
+	Template string `json:"-"`
 }
 
 func (c *ClickHouseQuery) Clone() *ClickHouseQuery {
@@ -443,6 +451,7 @@ func (c *ClickHouseQuery) Clone() *ClickHouseQuery {
 		Query:    c.Query,
 		Disabled: c.Disabled,
 		Legend:   c.Legend,
+		Template: c.Template,
 	}
 }
 func (c *ClickHouseQuery) Validate() error {
@@ -457,10 +466,48 @@ func (c *ClickHouseQuery) Validate() error {
 	return nil
 }
 
+// TraceQLQuery is a query of the trace search language, it returns the spans
+// matching it or their count
+type TraceQLQuery struct {
+	Query    string `json:"query"`
+	Limit    uint64 `json:"limit"`
+	Disabled bool   `json:"disabled"`
+	Legend   string `json:"legend,omitempty"`
+}
+
+func (t *TraceQLQuery) Clone() *TraceQLQuery {
+	if t == nil {
+		return nil
+	}
+	return &TraceQLQuery{
+		Query:    t.Query,
+		Limit:    t.Limit,
+		Disabled: t.Disabled,
+		Legend:   t.Legend,
+	}
+}
+
+func (t *TraceQLQuery) Validate(panelType PanelType) error {
+	if t == nil {
+		return nil
+	}
+
+	if t.Query == "" {
+		return fmt.Errorf("query is empty")
+	}
+
+	if panelType != PanelTypeList && panelType != PanelTypeGraph {
+		return fmt.Errorf("panel type %s is not supported for traceql queries", panelType)
+	}
+
+	return nil
+}
+
 type CompositeQuery struct {
 	BuilderQueries    map[string]*BuilderQuery    `json:"builderQueries,omitempty"`
 	ClickHouseQueries map[string]*ClickHouseQuery `json:"chQueries,omitempty"`
 	PromQueries       map[string]*PromQuery       `json:"promQueries,omitempty"`
+	TraceQLQueries    map[string]*TraceQLQuery    `json:"traceqlQueries,omitempty"`
 	PanelType         PanelType                   `json:"panelType"`
 	QueryType         QueryType                   `json:"queryType"`
 	// Unit for the time series data shown in the graph
@@ -495,10 +542,18 @@ func (c *CompositeQuery) Clone() *CompositeQuery {
 			promQueries[name] = query.Clone()
 		}
 	}
+	var traceQLQueries map[string]*TraceQLQuery
+	if c.TraceQLQueries != nil {
+		traceQLQueries = make(map[string]*TraceQLQuery)
+		for name, query := range c.TraceQLQueries {
+			traceQLQueries[name] = query.Clone()
+		}
+	}
 	return &CompositeQuery{
 		BuilderQueries:    builderQueries,
 		ClickHouseQueries: clickHouseQueries,
 		PromQueries:       promQueries,
+		TraceQLQueries:    traceQLQueries,
 		PanelType:         c.PanelType,
 		QueryType:         c.QueryType,
 		Unit:              c.Unit,
@@ -528,6 +583,12 @@ func (c *CompositeQuery) EnabledQueries() int {
 				count++
 			}
 		}
+	case QueryTypeTraceQL:
+		for _, query := range c.TraceQLQueries {
+			if !query.Disabled {
+				count++
+			}
+		}
 	}
 	return count
 }
@@ -549,7 +610,7 @@ func (c *CompositeQuery) Validate() error {
 		return fmt.Errorf("composite query is required")
 	}
 
-	if c.BuilderQueries == nil && c.ClickHouseQueries == nil && c.PromQueries == nil {
+	if c.BuilderQueries == nil && c.ClickHouseQueries == nil && c.PromQueries == nil && c.TraceQLQueries == nil {
 		return fmt.Errorf("composite query must contain at least one query type")
 	}
 
@@ -577,6 +638,14 @@ func (c *CompositeQuery) Validate() error {
 		}
 	}
 
+	if c.QueryType == QueryTypeTraceQL {
+		for name, query := range c.TraceQLQueries {
+			if err := query.Validate(c.PanelType); err != nil {
+				return fmt.Errorf("traceql query %s is invalid: %w", name, err)
+			}
+		}
+	}
+
 	if err := c.PanelType.Validate(); err != nil {
 		return fmt.Errorf("panel type is invalid: %w", err)
 	}
@@ -858,10 +927,54 @@ type BuilderQuery struct {
 	ShiftBy              int64
 	IsAnomaly            bool
 	QueriesUsedInFormula []string
+	JoinOn               []JoinKey          `json:"joinOn,omitempty"`
 	MetricTableHints     *MetricTableHints  `json:"-"`
 	MetricValueFilter    *MetricValueFilter `json:"-"`
 }
 
+// JoinKey is a key shared by the logs and traces. The formulas joining on
+// them are executed as a join of their queries in ClickHouse instead of
+// matching the series in the query service, so that the logs and traces
+// queries can be combined.
+type JoinKey string
+
+const (
+	JoinKeyTraceID     JoinKey = "trace_id"
+	JoinKeyServiceName JoinKey = "service.name"
+)
+
+// joinKeyGroupBy is the group by keys of the queries of each data source
+// matching the join keys
+var joinKeyGroupBy = map[JoinKey]map[DataSource][]string{
+	JoinKeyTraceID: {
+		DataSourceLogs:   {"trace_id"},
+		DataSourceTraces: {"traceID", "trace_id"},
+	},
+	JoinKeyServiceName: {
+		DataSourceLogs:   {"service.name"},
+		DataSourceTraces: {"serviceName", "service.name"},
+	},
+}
+
+func (k JoinKey) Validate() error {
+	if _, ok := joinKeyGroupBy[k]; !ok {
+		return fmt.Errorf("invalid join key: %s", k)
+	}
+	return nil
+}
+
+// GroupByKey returns the group by key of the query matching the join key
+func (k JoinKey) GroupByKey(query *BuilderQuery) (string, bool) {
+	for _, key := range joinKeyGroupBy[k][query.DataSource] {
+		for _, groupBy := range query.GroupBy {
+			if groupBy.Key == key {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
 func (b *BuilderQuery) SetShiftByFromFunc() {
 	// Remove the time shift function from the list of functions and set the shift by value
 	var timeShiftBy int64
@@ -923,6 +1036,7 @@ func (b *BuilderQuery) Clone() *BuilderQuery {
 		ShiftBy:              b.ShiftBy,
 		IsAnomaly:            b.IsAnomaly,
 		QueriesUsedInFormula: b.QueriesUsedInFormula,
+		JoinOn:               b.JoinOn,
 		MetricValueFilter:    b.MetricValueFilter.Clone(),
 	}
 }
@@ -998,6 +1112,23 @@ func (b *BuilderQuery) Validate(panelType PanelType) error {
 		}
 	}
 
+	if len(b.JoinOn) > 0 {
+		if b.QueryName == b.Expression {
+			return fmt.Errorf("join is only supported for formulas")
+		}
+		for _, key := range b.JoinOn {
+			if err := key.Validate(); err != nil {
+				return err
+			}
+		}
+		// the operator aggregates the joined rows by the group by of the formula
+		switch b.AggregateOperator {
+		case "", AggregateOperatorNoOp, AggregateOperatorSum, AggregateOperatorAvg, AggregateOperatorMin, AggregateOperatorMax:
+		default:
+			return fmt.Errorf("aggregate operator %s is not supported for joins", b.AggregateOperator)
+		}
+	}
+
 	if b.Filters != nil {
 		if err := b.Filters.Validate(); err != nil {
 			return fmt.Errorf("filters are invalid: %w", err)
@@ -1251,10 +1382,48 @@ func (h *Having) CacheKey() string {
 }
 
 type QueryRangeResponse struct {
-	ContextTimeout        bool      `json:"contextTimeout,omitempty"`
-	ContextTimeoutMessage string    `json:"contextTimeoutMessage,omitempty"`
-	ResultType            string    `json:"resultType"`
-	Result                []*Result `json:"result"`
+	ContextTimeout        bool            `json:"contextTimeout,omitempty"`
+	ContextTimeoutMessage string          `json:"contextTimeoutMessage,omitempty"`
+	ResultType            string          `json:"resultType"`
+	Result                []*Result       `json:"result"`
+	Meta                  *QueryRangeMeta `json:"meta,omitempty"`
+}
+
+// QueryRangeMeta is the effective resolution of the query range results
+type QueryRangeMeta struct {
+	// Downsampled tells whether the step of some queries was coarsened
+	Downsampled bool `json:"downsampled"`
+	// MaxPointsPerSeries is the budget of points of the series
+	MaxPointsPerSeries int64 `json:"maxPointsPerSeries"`
+	// StepIntervals is the effective step in seconds of the queries
+	StepIntervals map[string]int64 `json:"stepIntervals"`
+}
+
+// QueryEstimate is the estimate of the data a query reads from a table
+type QueryEstimate struct {
+	Database string `json:"database" ch:"database"`
+	Table    string `json:"table" ch:"table"`
+	Parts    uint64 `json:"parts" ch:"parts"`
+	Rows     uint64 `json:"rows" ch:"rows"`
+	Marks    uint64 `json:"marks" ch:"marks"`
+	// Bytes is estimated from the average compressed size of the rows of
+	// the table
+	Bytes uint64 `json:"bytes"`
+	// TotalRows is the number of rows in the active parts of the table
+	TotalRows uint64 `json:"totalRows"`
+}
+
+type QueryExplanation struct {
+	QueryName string          `json:"queryName"`
+	Query     string          `json:"query"`
+	Estimates []QueryEstimate `json:"estimates"`
+	Rows      uint64          `json:"rows"`
+	Bytes     uint64          `json:"bytes"`
+	Warnings  []string        `json:"warnings"`
+}
+
+type QueryRangeExplainResponse struct {
+	Queries []*QueryExplanation `json:"queries"`
 }
 
 type TableColumn struct {
@@ -1363,6 +1532,15 @@ func (p *Point) UnmarshalJSON(data []byte) error {
 // It is a composite query with a source page name and user defined tags
 // The source page name is used to identify the page that initiated the query
 // The source page could be "traces", "logs", "metrics".
+const (
+	// SavedViewVisibilityOrg is the visibility of the saved views shared with
+	// everyone in the org
+	SavedViewVisibilityOrg = "org"
+	// SavedViewVisibilityPrivate is the visibility of the saved views only
+	// their owner sees
+	SavedViewVisibilityPrivate = "private"
+)
+
 type SavedView struct {
 	UUID           string          `json:"uuid,omitempty"`
 	Name           string          `json:"name"`
@@ -1375,7 +1553,8 @@ type SavedView struct {
 	Tags           []string        `json:"tags"`
 	CompositeQuery *CompositeQuery `json:"compositeQuery"`
 	// ExtraData is JSON encoded data used by frontend to store additional data
-	ExtraData string `json:"extraData"`
+	ExtraData  string `json:"extraData"`
+	Visibility string `json:"visibility"`
 }
 
 func (eq *SavedView) Validate() error {
@@ -1384,6 +1563,14 @@ func (eq *SavedView) Validate() error {
 		return fmt.Errorf("composite query is required")
 	}
 
+	switch eq.Visibility {
+	case "":
+		eq.Visibility = SavedViewVisibilityOrg
+	case SavedViewVisibilityOrg, SavedViewVisibilityPrivate:
+	default:
+		return fmt.Errorf("visibility must be %s or %s", SavedViewVisibilityOrg, SavedViewVisibilityPrivate)
+	}
+
 	if eq.UUID == "" {
 		eq.UUID = uuid.New().String()
 	}
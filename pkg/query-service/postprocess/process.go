@@ -51,7 +51,8 @@ func PostProcessResult(result []*v3.Result, queryRangeParams *v3.QueryRangeParam
 		// The way we distinguish between a formula and a query is by checking if the expression
 		// is the same as the query name
 		// TODO(srikanthccv): Update the UI to send a flag to distinguish between a formula and a query
-		if query.Expression != query.QueryName {
+		// The formulas joining on the shared keys are executed in ClickHouse
+		if query.Expression != query.QueryName && len(query.JoinOn) == 0 {
 			expression, err := govaluate.NewEvaluableExpressionWithFunctions(query.Expression, EvalFuncs())
 			// This shouldn't happen here, because it should have been caught earlier in validation
 			if err != nil {
@@ -0,0 +1,184 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	am "go.signoz.io/signoz/pkg/query-service/integrations/alertManager"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+const (
+	// the annotations of the notifications of the acknowledged alerts
+	acknowledgedByAnnotation   = "acknowledged_by"
+	acknowledgedAtAnnotation   = "acknowledged_at"
+	acknowledgedNoteAnnotation = "acknowledgement_note"
+
+	// acknowledgementSilence is the longest the notifications of an
+	// acknowledged alert are muted for, in case the alert never resolves
+	acknowledgementSilence = 7 * 24 * time.Hour
+
+	// AcknowledgementReleasedBy is who took back the acknowledgements of the
+	// alerts which resolved
+	AcknowledgementReleasedBy = "system"
+)
+
+// UnacknowledgeAlerts takes back the acknowledgement of the acknowledged
+// firing alerts of the rule with all of the labels given, or of all of them
+// when there are none. The alerts are notified and escalated again, and the
+// recorded takebacks are returned.
+func (m *Manager) UnacknowledgeAlerts(ctx context.Context, ruleId string, labels map[string]string, note string) ([]AlertEscalation, error) {
+	m.mtx.RLock()
+	rule, ok := m.rules[ruleId]
+	m.mtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("rule %s is not running", ruleId)
+	}
+	activeAlerts, ok := rule.(interface{ ForEachActiveAlert(func(*Alert)) })
+	if !ok {
+		return nil, fmt.Errorf("unsupported rule %s", rule.Type())
+	}
+
+	escalations, err := m.ruleDB.GetAlertEscalations(ctx, ruleId)
+	if err != nil {
+		return nil, err
+	}
+	states := escalationStates(escalations)
+
+	unacknowledgedBy := "unknown"
+	if claims, ok := authtypes.ClaimsFromContext(ctx); ok {
+		unacknowledgedBy = claims.Email
+	}
+
+	now := time.Now()
+	unacknowledged := []AlertEscalation{}
+	silences := []string{}
+	activeAlerts.ForEachActiveAlert(func(a *Alert) {
+		if a.State != model.StateFiring {
+			return
+		}
+		for name, value := range labels {
+			if a.Labels.Get(name) != value {
+				return
+			}
+		}
+		state := states[escalationKey{fingerprint: a.DedupKey(), firedAt: a.FiredAt.Unix()}]
+		if !state.acknowledged {
+			return
+		}
+
+		alertLabels := EscalationLabels(a.Labels.Map())
+		unacknowledged = append(unacknowledged, AlertEscalation{
+			RuleId:      ruleId,
+			Fingerprint: a.DedupKey(),
+			Labels:      &alertLabels,
+			FiredAt:     a.FiredAt,
+			Kind:        AlertEscalationKindUnacknowledged,
+			CreatedBy:   unacknowledgedBy,
+			CreatedAt:   now,
+			Note:        note,
+		})
+		silences = append(silences, state.acknowledgement.SilenceId)
+	})
+
+	for i, e := range unacknowledged {
+		if err := m.ruleDB.DeleteAlertSilence(silences[i]); err != nil {
+			// the silence may have expired or been deleted in alertmanager
+			zap.L().Error("failed to delete the silence of the acknowledged alert", zap.String("rule", ruleId), zap.Error(err))
+		}
+		if err := m.ruleDB.RecordAlertEscalation(ctx, e); err != nil {
+			return nil, err
+		}
+	}
+	return unacknowledged, nil
+}
+
+// acknowledgedAlert returns the alert as notified while it is acknowledged,
+// with who acknowledged it, when and why
+func acknowledgedAlert(alert *Alert, acknowledgement *AlertEscalation) *Alert {
+	annotations := map[string]string{}
+	if alert.Annotations != nil {
+		annotations = alert.Annotations.Map()
+	}
+	annotations[acknowledgedByAnnotation] = acknowledgement.CreatedBy
+	annotations[acknowledgedAtAnnotation] = acknowledgement.CreatedAt.UTC().Format(time.RFC3339)
+	if acknowledgement.Note != "" {
+		annotations[acknowledgedNoteAnnotation] = acknowledgement.Note
+	}
+
+	anew := *alert
+	anew.Annotations = labels.FromMap(annotations)
+	return &anew
+}
+
+// notifyAcknowledged wraps the notify func to set the acknowledgement of the
+// acknowledged firing alerts of the rule on their notifications, and to take
+// back the acknowledgement of the alerts which resolved, unmuting their
+// resolved notification and their next firing
+func notifyAcknowledged(ruleDB RuleDB, ruleID string, notify NotifyFunc) NotifyFunc {
+	return func(ctx context.Context, expr string, alerts ...*Alert) {
+		if len(alerts) == 0 {
+			notify(ctx, expr, alerts...)
+			return
+		}
+		escalations, err := ruleDB.GetAlertEscalations(ctx, ruleID)
+		if err != nil {
+			zap.L().Error("failed to get the acknowledgements of the rule", zap.String("rule", ruleID), zap.Error(err))
+			notify(ctx, expr, alerts...)
+			return
+		}
+		states := escalationStates(escalations)
+
+		for i, alert := range alerts {
+			state := states[escalationKey{fingerprint: alert.DedupKey(), firedAt: alert.FiredAt.Unix()}]
+			if !state.acknowledged {
+				continue
+			}
+			if alert.ResolvedAt.IsZero() {
+				alerts[i] = acknowledgedAlert(alert, state.acknowledgement)
+				continue
+			}
+
+			if err := ruleDB.DeleteAlertSilence(state.acknowledgement.SilenceId); err != nil {
+				zap.L().Error("failed to delete the silence of the acknowledged alert", zap.String("rule", ruleID), zap.Error(err))
+			}
+			released := *state.acknowledgement
+			released.Id = 0
+			released.Kind = AlertEscalationKindUnacknowledged
+			released.CreatedBy = AcknowledgementReleasedBy
+			released.CreatedAt = time.Now()
+			released.Note = "the alert resolved"
+			released.SilenceId = ""
+			if err := ruleDB.RecordAlertEscalation(ctx, released); err != nil {
+				zap.L().Error("failed to record the release of the acknowledgement", zap.String("rule", ruleID), zap.Error(err))
+			}
+			states[escalationKey{fingerprint: alert.DedupKey(), firedAt: alert.FiredAt.Unix()}] = escalationState{step: state.step, acknowledgementSeen: true}
+		}
+		notify(ctx, expr, alerts...)
+	}
+}
+
+func (r *ruleDB) CreateAlertSilence(silence *am.Silence) (string, error) {
+	if r.alertManager == nil {
+		return "", nil
+	}
+	id, apiErr := r.alertManager.CreateSilence(silence)
+	if apiErr != nil {
+		return "", apiErr.Err
+	}
+	return id, nil
+}
+
+func (r *ruleDB) DeleteAlertSilence(id string) error {
+	if r.alertManager == nil || id == "" {
+		return nil
+	}
+	if apiErr := r.alertManager.DeleteSilence(id); apiErr != nil {
+		return apiErr.Err
+	}
+	return nil
+}
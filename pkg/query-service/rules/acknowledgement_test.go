@@ -0,0 +1,123 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/featureManager"
+	am "go.signoz.io/signoz/pkg/query-service/integrations/alertManager"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+// silencesManager is an alertmanager keeping the silences created in it
+type silencesManager struct {
+	am.Manager
+	silences map[string]*am.Silence
+}
+
+func (m *silencesManager) CreateSilence(silence *am.Silence) (string, *model.ApiError) {
+	id := fmt.Sprintf("silence-%d", len(m.silences)+1)
+	m.silences[id] = silence
+	return id, nil
+}
+
+func (m *silencesManager) DeleteSilence(id string) *model.ApiError {
+	if _, ok := m.silences[id]; !ok {
+		return &model.ApiError{Typ: model.ErrorNotFound, Err: fmt.Errorf("silence %s not found", id)}
+	}
+	delete(m.silences, id)
+	return nil
+}
+
+func TestAcknowledgeAlerts(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	alertManager := &silencesManager{silences: map[string]*am.Silence{}}
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), alertManager)
+	ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "oncall@signoz.io"})
+
+	target := float64(10)
+	rule, err := NewThresholdRule("1", &PostableRule{
+		AlertName: "high latency",
+		AlertType: AlertTypeMetric,
+		RuleType:  RuleTypeThreshold,
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {QueryName: "A", Expression: "A", DataSource: v3.DataSourceMetrics, AggregateOperator: v3.AggregateOperatorAvg},
+				},
+			},
+			CompareOp: ValueIsAbove,
+			MatchType: AtleastOnce,
+			Target:    &target,
+		},
+	}, featureManager.StartManager(), nil, true, true)
+	require.NoError(err)
+
+	firedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	checkout := labels.FromMap(map[string]string{labels.AlertNameLabel: "high latency", "service": "checkout"})
+	cart := labels.FromMap(map[string]string{labels.AlertNameLabel: "high latency", "service": "cart"})
+	rule.Active = map[uint64]*Alert{
+		checkout.Hash(): {State: model.StateFiring, Labels: checkout, FiredAt: firedAt},
+		cart.Hash():     {State: model.StateFiring, Labels: cart, FiredAt: firedAt},
+	}
+	m := &Manager{rules: map[string]Rule{"1": rule}, ruleDB: ruleDB}
+
+	var notified []*Alert
+	notify := notifyAcknowledged(ruleDB, "1", func(ctx context.Context, expr string, alerts ...*Alert) {
+		notified = append(notified, alerts...)
+	})
+
+	// the acknowledged alert is muted in alertmanager and its notifications
+	// tell who acknowledged it and why
+	acknowledged, err := m.AcknowledgeAlerts(ctx, "1", map[string]string{"service": "checkout"}, "looking into it")
+	require.NoError(err)
+	require.Len(acknowledged, 1)
+	require.Len(alertManager.silences, 1)
+	silence := alertManager.silences[acknowledged[0].SilenceId]
+	require.Len(silence.Matchers, 2)
+	require.Equal("acknowledged by oncall@signoz.io: looking into it", silence.Comment)
+
+	notify(ctx, "", rule.Active[checkout.Hash()], rule.Active[cart.Hash()])
+	require.Len(notified, 2)
+	require.Equal("oncall@signoz.io", notified[0].Annotations.Get(acknowledgedByAnnotation))
+	require.Equal("looking into it", notified[0].Annotations.Get(acknowledgedNoteAnnotation))
+	require.Nil(notified[1].Annotations)
+	require.Nil(rule.Active[checkout.Hash()].Annotations)
+
+	// taking back the acknowledgement unmutes the alert
+	unacknowledged, err := m.UnacknowledgeAlerts(ctx, "1", nil, "not mine")
+	require.NoError(err)
+	require.Len(unacknowledged, 1)
+	require.Empty(alertManager.silences)
+	notified = nil
+	notify(ctx, "", rule.Active[checkout.Hash()])
+	require.Nil(notified[0].Annotations)
+
+	// the acknowledgement is released once the alert resolves
+	_, err = m.AcknowledgeAlerts(ctx, "1", nil, "")
+	require.NoError(err)
+	require.Len(alertManager.silences, 2)
+	rule.Active[cart.Hash()].State = model.StateInactive
+	rule.Active[cart.Hash()].ResolvedAt = time.Now()
+	notify(ctx, "", rule.Active[cart.Hash()])
+	notify(ctx, "", rule.Active[cart.Hash()])
+	require.Len(alertManager.silences, 1)
+
+	escalations, err := ruleDB.GetAlertEscalations(ctx, "1")
+	require.NoError(err)
+	require.Equal(AlertEscalationKindUnacknowledged, escalations[0].Kind)
+	require.Equal(AcknowledgementReleasedBy, escalations[0].CreatedBy)
+	require.Equal("cart", (*escalations[0].Labels)["service"])
+	states := escalationStates(escalations)
+	require.True(states[escalationKey{fingerprint: rule.Active[checkout.Hash()].DedupKey(), firedAt: firedAt.Unix()}].acknowledged)
+	require.False(states[escalationKey{fingerprint: rule.Active[cart.Hash()].DedupKey(), firedAt: firedAt.Unix()}].acknowledged)
+}
@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -30,6 +31,8 @@ const (
 	RuleTypeThreshold = "threshold_rule"
 	RuleTypeProm      = "promql_rule"
 	RuleTypeAnomaly   = "anomaly_rule"
+	// RuleTypeLogPattern alerts on the patterns of the logs, see LogPatternRule
+	RuleTypeLogPattern = "log_pattern_rule"
 )
 
 type RuleHealth string
@@ -53,6 +56,9 @@ type Alert struct {
 	// list of preferred receivers, e.g. slack
 	Receivers []string
 
+	// ResolvedAnnotations are the annotations of the resolved notification
+	ResolvedAnnotations labels.BaseLabels
+
 	Value      float64
 	ActiveAt   time.Time
 	FiredAt    time.Time
@@ -63,6 +69,19 @@ type Alert struct {
 	Missing bool
 }
 
+// DedupKey identifies the alert instance, the rule and the series it fired
+// for, across its firing and resolved notifications. The labels the rule
+// sets from its definition or its escalation are left out, so the key stays
+// the same when the rule is renamed or its thresholds are edited.
+func (a *Alert) DedupKey() string {
+	return fmt.Sprintf("%016x", labels.FromMap(a.Labels.Map()).HashWithoutLabels(
+		labels.AlertNameLabel,
+		labels.RuleThresholdLabel,
+		labels.AlertSeverityLabel,
+		EscalationStepLabel,
+	))
+}
+
 func (a *Alert) needsSending(ts time.Time, resendDelay time.Duration) bool {
 	if a.State == model.StatePending {
 		return false
@@ -105,19 +124,70 @@ const (
 	Last          MatchType = "5"
 )
 
+// Sensitivity presets the z-score an anomaly rule fires at, the higher the
+// sensitivity the smaller the deviation from the baseline which alerts
+type Sensitivity string
+
+const (
+	SensitivityLow    Sensitivity = "low"
+	SensitivityMedium Sensitivity = "medium"
+	SensitivityHigh   Sensitivity = "high"
+)
+
+// ZScore returns the z-score of the sensitivity, false for unknown ones
+func (s Sensitivity) ZScore() (float64, bool) {
+	switch Sensitivity(strings.ToLower(string(s))) {
+	case SensitivityLow:
+		return 4, true
+	case SensitivityMedium:
+		return 3, true
+	case SensitivityHigh:
+		return 2, true
+	}
+	return 0, false
+}
+
+// SeverityThreshold is a threshold of a rule alerting with a severity per
+// threshold, such as warning and critical. The alerts crossing it are labelled
+// with its severity and sent to its channels, or to the channels of the rule
+// if it has none.
+type SeverityThreshold struct {
+	Severity          string   `yaml:"severity" json:"severity"`
+	Target            *float64 `yaml:"target" json:"target"`
+	PreferredChannels []string `yaml:"preferredChannels,omitempty" json:"preferredChannels,omitempty"`
+}
+
 type RuleCondition struct {
-	CompositeQuery    *v3.CompositeQuery `json:"compositeQuery,omitempty" yaml:"compositeQuery,omitempty"`
-	CompareOp         CompareOp          `yaml:"op,omitempty" json:"op,omitempty"`
-	Target            *float64           `yaml:"target,omitempty" json:"target,omitempty"`
-	AlertOnAbsent     bool               `yaml:"alertOnAbsent,omitempty" json:"alertOnAbsent,omitempty"`
-	AbsentFor         uint64             `yaml:"absentFor,omitempty" json:"absentFor,omitempty"`
-	MatchType         MatchType          `json:"matchType,omitempty"`
-	TargetUnit        string             `json:"targetUnit,omitempty"`
-	Algorithm         string             `json:"algorithm,omitempty"`
-	Seasonality       string             `json:"seasonality,omitempty"`
-	SelectedQuery     string             `json:"selectedQueryName,omitempty"`
-	RequireMinPoints  bool               `yaml:"requireMinPoints,omitempty" json:"requireMinPoints,omitempty"`
-	RequiredNumPoints int                `yaml:"requiredNumPoints,omitempty" json:"requiredNumPoints,omitempty"`
+	CompositeQuery    *v3.CompositeQuery   `json:"compositeQuery,omitempty" yaml:"compositeQuery,omitempty"`
+	CompareOp         CompareOp            `yaml:"op,omitempty" json:"op,omitempty"`
+	Target            *float64             `yaml:"target,omitempty" json:"target,omitempty"`
+	AlertOnAbsent     bool                 `yaml:"alertOnAbsent,omitempty" json:"alertOnAbsent,omitempty"`
+	AbsentFor         uint64               `yaml:"absentFor,omitempty" json:"absentFor,omitempty"`
+	MatchType         MatchType            `json:"matchType,omitempty"`
+	TargetUnit        string               `json:"targetUnit,omitempty"`
+	Algorithm         string               `json:"algorithm,omitempty"`
+	Seasonality       string               `json:"seasonality,omitempty"`
+	Sensitivity       Sensitivity          `json:"sensitivity,omitempty"`
+	Thresholds        []SeverityThreshold  `yaml:"thresholds,omitempty" json:"thresholds,omitempty"`
+	SelectedQuery     string               `json:"selectedQueryName,omitempty"`
+	RequireMinPoints  bool                 `yaml:"requireMinPoints,omitempty" json:"requireMinPoints,omitempty"`
+	RequiredNumPoints int                  `yaml:"requiredNumPoints,omitempty" json:"requiredNumPoints,omitempty"`
+	LogPattern        *LogPatternCondition `yaml:"logPattern,omitempty" json:"logPattern,omitempty"`
+	// Composite replaces the threshold of the rule with conditions on its
+	// queries combined with and and or
+	Composite *CompositeCondition `yaml:"composite,omitempty" json:"composite,omitempty"`
+
+	// NoDataAction is what the rule does when the query returns no data for
+	// AbsentFor minutes
+	NoDataAction NoDataAction `yaml:"noDataAction,omitempty" json:"noDataAction,omitempty"`
+	// AbsentSeriesAction is what the rule does with the alerts whose series
+	// disappears from the query result
+	AbsentSeriesAction NoDataAction `yaml:"absentSeriesAction,omitempty" json:"absentSeriesAction,omitempty"`
+
+	// ClearTarget is the threshold the firing alerts resolve at, past the
+	// target the other way, for the alerts of the series oscillating around
+	// the target not to flap
+	ClearTarget *float64 `yaml:"clearTarget,omitempty" json:"clearTarget,omitempty"`
 }
 
 func (rc *RuleCondition) GetSelectedQueryName() string {
@@ -167,8 +237,10 @@ func (rc *RuleCondition) IsValid() bool {
 		return false
 	}
 
-	if rc.QueryType() == v3.QueryTypeBuilder {
-		if rc.Target == nil {
+	// the rules alerting on the new log patterns or with a composite
+	// condition have no threshold
+	if rc.QueryType() == v3.QueryTypeBuilder && rc.Composite == nil && (rc.LogPattern == nil || rc.LogPattern.Mode != LogPatternModeNew) {
+		if rc.Target == nil && rc.Sensitivity == "" && len(rc.Thresholds) == 0 {
 			return false
 		}
 		if rc.CompareOp == "" {
@@ -229,6 +301,29 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	}
 }
 
+// Scan reads the duration stored as a string
+func (d *Duration) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return errors.New("invalid duration")
+	}
+	tmp, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(tmp)
+	return nil
+}
+
+func (d Duration) Value() (driver.Value, error) {
+	return time.Duration(d).String(), nil
+}
+
 // prepareRuleGeneratorURL creates an appropriate url
 // for the rule. the URL is sent in slack messages as well as
 // to other systems and allows backtracking to the rule definition
@@ -0,0 +1,38 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+)
+
+func TestAlertDedupKey(t *testing.T) {
+	alert := &Alert{Labels: labels.FromMap(map[string]string{
+		labels.AlertNameLabel:     "high latency",
+		labels.AlertRuleIdLabel:   "1",
+		labels.RuleThresholdLabel: "500",
+		labels.AlertSeverityLabel: "warning",
+		"service":                 "checkout",
+	})}
+	key := alert.DedupKey()
+	require.Len(t, key, 16)
+
+	// renaming the rule, editing its threshold or escalating the alert keeps the key
+	same := &Alert{Labels: labels.FromMap(map[string]string{
+		labels.AlertNameLabel:     "checkout latency",
+		labels.AlertRuleIdLabel:   "1",
+		labels.RuleThresholdLabel: "1000",
+		labels.AlertSeverityLabel: "critical",
+		EscalationStepLabel:       "1",
+		"service":                 "checkout",
+	})}
+	require.Equal(t, key, same.DedupKey())
+
+	for _, other := range []map[string]string{
+		{labels.AlertRuleIdLabel: "2", "service": "checkout"},
+		{labels.AlertRuleIdLabel: "1", "service": "cart"},
+	} {
+		require.NotEqual(t, key, (&Alert{Labels: labels.FromMap(other)}).DedupKey())
+	}
+}
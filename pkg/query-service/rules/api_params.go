@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -50,6 +51,9 @@ type PostableRule struct {
 	RuleType    RuleType  `yaml:"ruleType,omitempty" json:"ruleType,omitempty"`
 	EvalWindow  Duration  `yaml:"evalWindow,omitempty" json:"evalWindow,omitempty"`
 	Frequency   Duration  `yaml:"frequency,omitempty" json:"frequency,omitempty"`
+	// EvalDelay shifts the evaluation window back to wait for the data
+	// arriving late, the delay of the rule manager is used when it is zero
+	EvalDelay Duration `yaml:"evalDelay,omitempty" json:"evalDelay,omitempty"`
 
 	RuleCondition *RuleCondition    `yaml:"condition,omitempty" json:"condition,omitempty"`
 	Labels        map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
@@ -62,6 +66,25 @@ type PostableRule struct {
 
 	PreferredChannels []string `json:"preferredChannels,omitempty"`
 
+	// EscalationPolicyId is the escalation policy the unacknowledged alerts
+	// of the rule escalate by
+	EscalationPolicyId int64 `json:"escalationPolicyId,omitempty"`
+
+	// ResolvedNotification sets how the resolved alerts of the rule are
+	// notified, all of them are when it is nil
+	ResolvedNotification *ResolvedNotification `yaml:"resolvedNotification,omitempty" json:"resolvedNotification,omitempty"`
+
+	// Team is the team owning the rule, only its members and the admins can
+	// change or delete the rule
+	Team string `yaml:"team,omitempty" json:"team,omitempty"`
+
+	// Panel is the dashboard panel the rule was created from
+	Panel *RulePanel `yaml:"panel,omitempty" json:"panel,omitempty"`
+
+	// FlapDetection suppresses the notifications of the alerts of the rule
+	// changing state too often, none are when it is nil
+	FlapDetection *FlapDetection `yaml:"flapDetection,omitempty" json:"flapDetection,omitempty"`
+
 	Version string `json:"version,omitempty"`
 
 	// legacy
@@ -216,10 +239,28 @@ func (r *PostableRule) Validate() error {
 		errs = append(errs, errors.Errorf("all queries are disabled in rule condition"))
 	}
 
-	if r.RuleType == RuleTypeThreshold {
-		if r.RuleCondition.Target == nil {
+	if r.RuleCondition.Composite != nil {
+		if r.RuleType != RuleTypeThreshold {
+			errs = append(errs, errors.Errorf("composite conditions are supported only by threshold rules"))
+		}
+		if len(r.RuleCondition.Thresholds) > 0 {
+			errs = append(errs, errors.Errorf("composite conditions can't have thresholds per severity"))
+		}
+		queryNames := map[string]bool{}
+		for name := range r.RuleCondition.CompositeQuery.BuilderQueries {
+			queryNames[name] = true
+		}
+		for name := range r.RuleCondition.CompositeQuery.ClickHouseQueries {
+			queryNames[name] = true
+		}
+		if err := r.RuleCondition.Composite.Validate(queryNames); err != nil {
+			errs = append(errs, err)
+		}
+	} else if r.RuleType == RuleTypeThreshold {
+		if r.RuleCondition.Target == nil && len(r.RuleCondition.Thresholds) == 0 {
 			errs = append(errs, errors.Errorf("rule condition missing the threshold"))
 		}
+		errs = append(errs, validateSeverityThresholds(r.RuleCondition.Thresholds)...)
 		if r.RuleCondition.CompareOp == "" {
 			errs = append(errs, errors.Errorf("rule condition missing the compare op"))
 		}
@@ -228,6 +269,80 @@ func (r *PostableRule) Validate() error {
 		}
 	}
 
+	if r.EvalDelay < 0 {
+		errs = append(errs, errors.Errorf("eval delay can't be negative"))
+	}
+
+	if r.ResolvedNotification != nil {
+		if err := r.ResolvedNotification.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, validateNoDataActions(r)...)
+
+	if r.RuleCondition.ClearTarget != nil {
+		if err := validateClearTarget(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if r.FlapDetection != nil {
+		if err := r.FlapDetection.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if r.RuleType != RuleTypeThreshold && len(r.RuleCondition.Thresholds) > 0 {
+		errs = append(errs, errors.Errorf("thresholds per severity are supported only by threshold rules"))
+	}
+
+	if r.RuleType == RuleTypeAnomaly {
+		if r.RuleCondition.QueryType() != v3.QueryTypeBuilder {
+			errs = append(errs, errors.Errorf("anomaly rules support only the query builder"))
+		}
+		if r.RuleCondition.Target == nil && r.RuleCondition.Sensitivity == "" {
+			errs = append(errs, errors.Errorf("rule condition missing the threshold or the sensitivity"))
+		}
+		if _, ok := r.RuleCondition.Sensitivity.ZScore(); r.RuleCondition.Sensitivity != "" && !ok {
+			errs = append(errs, errors.Errorf("invalid sensitivity: %s", r.RuleCondition.Sensitivity))
+		}
+		switch strings.ToLower(r.RuleCondition.Seasonality) {
+		case "", "hourly", "daily", "weekly":
+		default:
+			errs = append(errs, errors.Errorf("invalid seasonality: %s", r.RuleCondition.Seasonality))
+		}
+		if r.RuleCondition.CompareOp == "" {
+			errs = append(errs, errors.Errorf("rule condition missing the compare op"))
+		}
+		if r.RuleCondition.MatchType == "" {
+			errs = append(errs, errors.Errorf("rule condition missing the match option"))
+		}
+	}
+
+	if r.RuleType == RuleTypeLogPattern {
+		if r.RuleCondition.QueryType() != v3.QueryTypeBuilder {
+			errs = append(errs, errors.Errorf("log pattern rules support only the query builder"))
+		} else if q := r.RuleCondition.CompositeQuery.BuilderQueries[r.RuleCondition.GetSelectedQueryName()]; q == nil || q.DataSource != v3.DataSourceLogs {
+			errs = append(errs, errors.Errorf("log pattern rules need a logs query"))
+		}
+		if r.RuleCondition.LogPattern == nil {
+			errs = append(errs, errors.Errorf("rule condition missing the log pattern"))
+		} else {
+			if err := r.RuleCondition.LogPattern.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+			if r.RuleCondition.LogPattern.Mode == LogPatternModeRate {
+				if r.RuleCondition.Target == nil {
+					errs = append(errs, errors.Errorf("rule condition missing the threshold"))
+				}
+				if r.RuleCondition.CompareOp == "" {
+					errs = append(errs, errors.Errorf("rule condition missing the compare op"))
+				}
+			}
+		}
+	}
+
 	for k, v := range r.Labels {
 		if !isValidLabelName(k) {
 			errs = append(errs, errors.Errorf("invalid label name: %s", k))
@@ -248,6 +363,47 @@ func (r *PostableRule) Validate() error {
 	return multierr.Combine(errs...)
 }
 
+// validateClearTarget checks the clear target of the rule is past its target
+// the other way, which only the threshold rules with a single target support
+func validateClearTarget(r *PostableRule) error {
+	rc := r.RuleCondition
+	if r.RuleType != RuleTypeThreshold || rc.Target == nil || len(rc.Thresholds) > 0 || rc.Composite != nil {
+		return errors.Errorf("clear targets are supported only by threshold rules with a single target")
+	}
+	switch rc.CompareOp {
+	case ValueIsAbove, ValueAboveOrEq:
+		if *rc.ClearTarget >= *rc.Target {
+			return errors.Errorf("the clear target must be below the target")
+		}
+	case ValueIsBelow, ValueBelowOrEq:
+		if *rc.ClearTarget <= *rc.Target {
+			return errors.Errorf("the clear target must be above the target")
+		}
+	default:
+		return errors.Errorf("clear targets are supported only by the above and below compare ops")
+	}
+	return nil
+}
+
+// validateSeverityThresholds checks the thresholds per severity, which are
+// tried in order so the most severe comes first
+func validateSeverityThresholds(thresholds []SeverityThreshold) (errs []error) {
+	seen := map[string]bool{}
+	for _, threshold := range thresholds {
+		if threshold.Severity == "" || !isValidLabelValue(threshold.Severity) {
+			errs = append(errs, errors.Errorf("invalid threshold severity: %q", threshold.Severity))
+		}
+		if seen[threshold.Severity] {
+			errs = append(errs, errors.Errorf("duplicate threshold severity: %s", threshold.Severity))
+		}
+		seen[threshold.Severity] = true
+		if threshold.Target == nil {
+			errs = append(errs, errors.Errorf("threshold %s missing the target", threshold.Severity))
+		}
+	}
+	return errs
+}
+
 func testTemplateParsing(rl *PostableRule) (errs []error) {
 	if rl.AlertName == "" {
 		// Not an alerting rule.
@@ -302,4 +458,9 @@ type GettableRule struct {
 	CreatedBy *string    `json:"createBy"`
 	UpdatedAt *time.Time `json:"updateAt"`
 	UpdatedBy *string    `json:"updateBy"`
+	// Pause is the pause of the paused rule
+	Pause *RulePause `json:"pause,omitempty"`
+	// Escalations are the escalations and acknowledgements of the alerts of
+	// the rule, newest first
+	Escalations []AlertEscalation `json:"escalations,omitempty"`
 }
@@ -84,3 +84,82 @@ func TestIsAllQueriesDisabled(t *testing.T) {
 		}
 	}
 }
+
+func TestParseAnomalyRule(t *testing.T) {
+	rule := func(condition string) string {
+		return `{
+			"alert": "anomalous latency",
+			"ruleType": "anomaly_rule",
+			"condition": {
+				"compositeQuery": {
+					"queryType": "builder",
+					"builderQueries": {"A": {"queryName": "A", "expression": "A", "dataSource": "metrics", "aggregateOperator": "avg", "aggregateAttribute": {"key": "signoz_latency"}}}
+				},
+				"op": "1",
+				"matchType": "1"` + condition + `
+			}
+		}`
+	}
+
+	cases := []struct {
+		condition string
+		valid     bool
+	}{
+		{condition: `, "sensitivity": "high", "seasonality": "weekly"`, valid: true},
+		{condition: `, "target": 2.5, "seasonality": "hourly"`, valid: true},
+		{condition: ``, valid: false},
+		{condition: `, "sensitivity": "extreme"`, valid: false},
+		{condition: `, "sensitivity": "low", "seasonality": "monthly"`, valid: false},
+	}
+
+	for idx, c := range cases {
+		_, err := ParsePostableRule([]byte(rule(c.condition)))
+		if c.valid && err != nil {
+			t.Errorf("case %d: expected the rule to be valid, got %v", idx, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("case %d: expected the rule to be invalid", idx)
+		}
+	}
+
+	if target, ok := SensitivityHigh.ZScore(); !ok || target != 2 {
+		t.Errorf("expected the high sensitivity to fire at a z-score of 2, got %v", target)
+	}
+}
+
+func TestParseSeverityThresholdsRule(t *testing.T) {
+	rule := func(thresholds string) string {
+		return `{
+			"alert": "high latency",
+			"condition": {
+				"compositeQuery": {
+					"queryType": "builder",
+					"builderQueries": {"A": {"queryName": "A", "expression": "A", "dataSource": "metrics", "aggregateOperator": "avg", "aggregateAttribute": {"key": "signoz_latency"}}}
+				},
+				"op": "1",
+				"matchType": "1",
+				"thresholds": ` + thresholds + `
+			}
+		}`
+	}
+
+	cases := []struct {
+		thresholds string
+		valid      bool
+	}{
+		{thresholds: `[{"severity": "critical", "target": 500, "preferredChannels": ["pagerduty"]}, {"severity": "warning", "target": 200}]`, valid: true},
+		{thresholds: `[{"severity": "critical"}]`, valid: false},
+		{thresholds: `[{"target": 500}]`, valid: false},
+		{thresholds: `[{"severity": "critical", "target": 500}, {"severity": "critical", "target": 200}]`, valid: false},
+	}
+
+	for idx, c := range cases {
+		_, err := ParsePostableRule([]byte(rule(c.thresholds)))
+		if c.valid && err != nil {
+			t.Errorf("case %d: expected the rule to be valid, got %v", idx, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("case %d: expected the rule to be invalid", idx)
+		}
+	}
+}
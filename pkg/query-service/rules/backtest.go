@@ -0,0 +1,156 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxBacktestLookback is the longest history a rule can be backtested on
+	maxBacktestLookback = 7 * 24 * time.Hour
+	// maxBacktestEvaluations bounds the queries run by a backtest, the step
+	// between the evaluations grows with the lookback to stay under it
+	maxBacktestEvaluations = 1440
+)
+
+// BacktestParams is a candidate rule and the history to evaluate it on
+type BacktestParams struct {
+	Rule json.RawMessage `json:"rule"`
+	// Lookback is how far back the evaluations start, 24h by default
+	Lookback Duration `json:"lookback"`
+}
+
+// BacktestResult lists when the rule would have fired over the history
+type BacktestResult struct {
+	Start       time.Time        `json:"start"`
+	End         time.Time        `json:"end"`
+	Step        Duration         `json:"step"`
+	Evaluations int              `json:"evaluations"`
+	Firings     []BacktestFiring `json:"firings"`
+}
+
+// BacktestFiring is an alert the rule would have fired, ResolvedAt is nil for
+// the alerts still firing at the end of the backtest
+type BacktestFiring struct {
+	Labels     map[string]string `json:"labels"`
+	FiredAt    time.Time         `json:"firedAt"`
+	ResolvedAt *time.Time        `json:"resolvedAt,omitempty"`
+	// Value is the value when the alert fired, LastValue the last one seen
+	// while it was firing
+	Value     float64 `json:"value"`
+	LastValue float64 `json:"lastValue"`
+	NoData    bool    `json:"noData,omitempty"`
+}
+
+// BacktestRule evaluates the candidate rule at its frequency over the
+// lookback, without notifying or recording the state history, and returns
+// the alerts it would have fired
+func (m *Manager) BacktestRule(ctx context.Context, params BacktestParams) (*BacktestResult, *model.ApiError) {
+	parsedRule, err := ParsePostableRule(params.Rule)
+	if err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	lookback := time.Duration(params.Lookback)
+	if lookback == 0 {
+		lookback = 24 * time.Hour
+	}
+	if lookback < 0 || lookback > maxBacktestLookback {
+		return nil, model.BadRequest(fmt.Errorf("lookback must be between 0 and %s", maxBacktestLookback))
+	}
+
+	step := time.Duration(parsedRule.Frequency)
+	if step <= 0 {
+		step = DefaultFrequency
+	}
+	if lookback/step > maxBacktestEvaluations {
+		step = (lookback / maxBacktestEvaluations).Truncate(time.Minute) + time.Minute
+	}
+
+	// the rules keep no history of their own, so a random id keeps the
+	// evaluations apart from the rules being run
+	id := uuid.New().String()
+	var rule Rule
+	switch parsedRule.RuleType {
+	case RuleTypeThreshold:
+		rule, err = NewThresholdRule(id, parsedRule, m.featureFlags, m.reader, m.opts.UseLogsNewSchema, m.opts.UseTraceNewSchema, WithoutStateHistory())
+	case RuleTypeProm:
+		rule, err = NewPromRule(id, parsedRule, m.logger, m.reader, m.opts.PqlEngine, WithoutStateHistory())
+	default:
+		return nil, model.BadRequest(fmt.Errorf("backtesting is supported for threshold and promql rules"))
+	}
+	if err != nil {
+		return nil, model.BadRequest(err)
+	}
+
+	end := time.Now().UTC().Truncate(time.Minute)
+	result, err := backtest(ctx, rule, end.Add(-lookback), end, step)
+	if err != nil {
+		zap.L().Error("backtesting rule failed", zap.String("rule", parsedRule.AlertName), zap.Error(err))
+		return nil, model.InternalError(fmt.Errorf("rule evaluation failed: %w", err))
+	}
+	return result, nil
+}
+
+// backtest evaluates the rule at every step after start up to end, following
+// the state of the alerts from one evaluation to the next
+func backtest(ctx context.Context, rule Rule, start, end time.Time, step time.Duration) (*BacktestResult, error) {
+	activeAlerts, ok := rule.(interface{ ForEachActiveAlert(func(*Alert)) })
+	if !ok {
+		return nil, fmt.Errorf("unsupported rule %s", rule.Type())
+	}
+
+	result := &BacktestResult{Start: start, End: end, Step: Duration(step), Firings: []BacktestFiring{}}
+	firing := map[uint64]int{}
+	for ts := start.Add(step); !ts.After(end); ts = ts.Add(step) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, err := rule.Eval(ctx, ts); err != nil {
+			return nil, err
+		}
+		result.Evaluations++
+
+		activeAlerts.ForEachActiveAlert(func(a *Alert) {
+			h := a.Labels.Hash()
+			i, open := firing[h]
+			if open && !result.Firings[i].FiredAt.Equal(a.FiredAt) {
+				// the alert resolved and fired again since the last step
+				open = false
+			}
+
+			switch a.State {
+			case model.StateFiring, model.StateNoData:
+				if !open {
+					result.Firings = append(result.Firings, BacktestFiring{
+						Labels:  a.Labels.Map(),
+						FiredAt: a.FiredAt,
+						Value:   a.Value,
+						NoData:  a.Missing,
+					})
+					i = len(result.Firings) - 1
+					firing[h] = i
+				}
+				result.Firings[i].LastValue = a.Value
+			case model.StateInactive:
+				if open {
+					resolvedAt := a.ResolvedAt
+					result.Firings[i].ResolvedAt = &resolvedAt
+					delete(firing, h)
+				}
+			}
+		})
+	}
+
+	sort.SliceStable(result.Firings, func(i, j int) bool {
+		return result.Firings[i].FiredAt.Before(result.Firings[j].FiredAt)
+	})
+	return result, nil
+}
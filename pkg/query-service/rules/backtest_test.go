@@ -0,0 +1,90 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/clickhouseReader"
+	"go.signoz.io/signoz/pkg/query-service/featureManager"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+
+	cmock "github.com/srikanthccv/ClickHouse-go-mock"
+)
+
+func TestBacktest(t *testing.T) {
+	require := require.New(t)
+
+	target := float64(10)
+	postableRule := PostableRule{
+		AlertName:  "Backtest",
+		AlertType:  AlertTypeMetric,
+		RuleType:   RuleTypeThreshold,
+		EvalWindow: Duration(5 * time.Minute),
+		Frequency:  Duration(1 * time.Minute),
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {
+						QueryName:    "A",
+						StepInterval: 60,
+						AggregateAttribute: v3.AttributeKey{
+							Key: "signoz_calls_total",
+						},
+						AggregateOperator: v3.AggregateOperatorSumRate,
+						DataSource:        v3.DataSourceMetrics,
+						Expression:        "A",
+					},
+				},
+			},
+			CompareOp: ValueIsAbove,
+			MatchType: AtleastOnce,
+			Target:    &target,
+		},
+	}
+	fm := featureManager.StartManager()
+	mock, err := cmock.NewClickHouseWithQueryMatcher(nil, &queryMatcherAny{})
+	require.NoError(err)
+
+	cols := []cmock.ColumnType{
+		{Name: "value", Type: "Float64"},
+		{Name: "service_name", Type: "String"},
+		{Name: "timestamp", Type: "String"},
+	}
+	// the value seen by every evaluation, the alert fires on the second one,
+	// resolves on the fourth and fires again on the last one
+	now := time.Now()
+	for _, value := range []float64{5, 20, 25, 5, 30} {
+		mock.ExpectQuery("SELECT any").WillReturnRows(cmock.NewRows(cols, [][]interface{}{{value, "checkout", now}}))
+	}
+
+	options := clickhouseReader.NewOptions("", "", "archiveNamespace")
+	reader := clickhouseReader.NewReaderFromClickhouseConnection(mock, options, nil, "", fm, "", true, true, time.Duration(time.Second), nil)
+	rule, err := NewThresholdRule("69", &postableRule, fm, reader, true, true, WithoutStateHistory())
+	require.NoError(err)
+	rule.TemporalityMap = map[string]map[v3.Temporality]bool{
+		"signoz_calls_total": {
+			v3.Delta: true,
+		},
+	}
+
+	start := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+	result, err := backtest(context.Background(), rule, start, start.Add(5*time.Minute), time.Minute)
+	require.NoError(err)
+	require.Equal(5, result.Evaluations)
+	require.Len(result.Firings, 2)
+
+	first := result.Firings[0]
+	require.Equal("checkout", first.Labels["service_name"])
+	require.Equal(start.Add(2*time.Minute), first.FiredAt)
+	require.Equal(float64(20), first.Value)
+	require.Equal(float64(25), first.LastValue)
+	require.NotNil(first.ResolvedAt)
+	require.Equal(start.Add(4*time.Minute), *first.ResolvedAt)
+
+	second := result.Firings[1]
+	require.Equal(start.Add(5*time.Minute), second.FiredAt)
+	require.Nil(second.ResolvedAt)
+}
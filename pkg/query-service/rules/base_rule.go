@@ -45,7 +45,16 @@ type BaseRule struct {
 	// preferredChannels is the list of channels to send the alert to
 	// if the rule is triggered
 	preferredChannels []string
-	mtx               sync.Mutex
+	// escalationPolicyId is the escalation policy of the alerts, 0 for none
+	escalationPolicyId int64
+	mtx                sync.Mutex
+
+	// resolvedNotification sets how the resolved alerts are notified
+	resolvedNotification *ResolvedNotification
+	// flapDetection suppresses the notifications of the flapping alerts,
+	// whose transitions are kept in flaps by fingerprint
+	flapDetection *FlapDetection
+	flaps         map[uint64]*flapState
 	// the time it took to evaluate the rule (most recent evaluation)
 	evaluationDuration time.Duration
 	// the timestamp of the last evaluation
@@ -73,6 +82,10 @@ type BaseRule struct {
 	// or other params
 	sendAlways bool
 
+	// skipStateHistory keeps the state changes from being recorded,
+	// for the rules which are only evaluated to see how they behave
+	skipStateHistory bool
+
 	// TemporalityMap is a map of metric name to temporality
 	// to avoid fetching temporality for the same metric multiple times
 	// querying the v4 table on low cardinal temporality column
@@ -94,6 +107,12 @@ func WithSendUnmatched() RuleOption {
 	}
 }
 
+func WithoutStateHistory() RuleOption {
+	return func(r *BaseRule) {
+		r.skipStateHistory = true
+	}
+}
+
 func WithEvalDelay(dur time.Duration) RuleOption {
 	return func(r *BaseRule) {
 		r.evalDelay = dur
@@ -112,34 +131,51 @@ func NewBaseRule(id string, p *PostableRule, reader interfaces.Reader, opts ...R
 	}
 
 	baseRule := &BaseRule{
-		id:                id,
-		name:              p.AlertName,
-		source:            p.Source,
-		typ:               p.AlertType,
-		ruleCondition:     p.RuleCondition,
-		evalWindow:        time.Duration(p.EvalWindow),
-		labels:            qslabels.FromMap(p.Labels),
-		annotations:       qslabels.FromMap(p.Annotations),
-		preferredChannels: p.PreferredChannels,
-		health:            HealthUnknown,
-		Active:            map[uint64]*Alert{},
-		reader:            reader,
-		TemporalityMap:    make(map[string]map[v3.Temporality]bool),
+		id:                 id,
+		name:               p.AlertName,
+		source:             p.Source,
+		typ:                p.AlertType,
+		ruleCondition:      p.RuleCondition,
+		evalWindow:         time.Duration(p.EvalWindow),
+		labels:             qslabels.FromMap(p.Labels),
+		annotations:        qslabels.FromMap(p.Annotations),
+		preferredChannels:  p.PreferredChannels,
+		escalationPolicyId: p.EscalationPolicyId,
+		health:             HealthUnknown,
+		Active:             map[uint64]*Alert{},
+		reader:             reader,
+		TemporalityMap:     make(map[string]map[v3.Temporality]bool),
 	}
 
 	if baseRule.evalWindow == 0 {
 		baseRule.evalWindow = 5 * time.Minute
 	}
+	baseRule.resolvedNotification = p.ResolvedNotification
+	baseRule.flapDetection = p.FlapDetection
 
 	for _, opt := range opts {
 		opt(baseRule)
 	}
 
+	// the delay of the rule takes over from the one of the rule manager
+	if p.EvalDelay > 0 {
+		baseRule.evalDelay = time.Duration(p.EvalDelay)
+	}
+
 	return baseRule, nil
 }
 
 func (r *BaseRule) targetVal() float64 {
-	if r.ruleCondition == nil || r.ruleCondition.Target == nil {
+	if r.ruleCondition == nil {
+		return 0
+	}
+	return r.convertTarget(r.ruleCondition.Target)
+}
+
+// convertTarget converts the target from the target unit to the unit of the
+// y-axis
+func (r *BaseRule) convertTarget(target *float64) float64 {
+	if target == nil {
 		return 0
 	}
 
@@ -147,13 +183,34 @@ func (r *BaseRule) targetVal() float64 {
 	unitConverter := converter.FromUnit(converter.Unit(r.ruleCondition.TargetUnit))
 	// convert the target value to the y-axis unit
 	value := unitConverter.Convert(converter.Value{
-		F: *r.ruleCondition.Target,
+		F: *target,
 		U: converter.Unit(r.ruleCondition.TargetUnit),
 	}, converter.Unit(r.Unit()))
 
 	return value.F
 }
 
+// severityTarget returns the target of the threshold of the severity, or the
+// target of the rule for the rules without thresholds per severity
+func (r *BaseRule) severityTarget(severity string) float64 {
+	for _, threshold := range r.ruleCondition.Thresholds {
+		if threshold.Severity == severity {
+			return r.convertTarget(threshold.Target)
+		}
+	}
+	return r.targetVal()
+}
+
+// severityChannels returns the channels the alerts of the severity are sent to
+func (r *BaseRule) severityChannels(severity string) []string {
+	for _, threshold := range r.ruleCondition.Thresholds {
+		if threshold.Severity == severity && len(threshold.PreferredChannels) > 0 {
+			return threshold.PreferredChannels
+		}
+	}
+	return r.preferredChannels
+}
+
 func (r *BaseRule) matchType() MatchType {
 	if r.ruleCondition == nil {
 		return AtleastOnce
@@ -213,6 +270,11 @@ func (r *BaseRule) Condition() *RuleCondition        { return r.ruleCondition }
 func (r *BaseRule) Labels() qslabels.BaseLabels      { return r.labels }
 func (r *BaseRule) Annotations() qslabels.BaseLabels { return r.annotations }
 func (r *BaseRule) PreferredChannels() []string      { return r.preferredChannels }
+func (r *BaseRule) EscalationPolicyId() int64        { return r.escalationPolicyId }
+
+func (r *BaseRule) ResolvedNotification() *ResolvedNotification {
+	return r.resolvedNotification
+}
 
 func (r *BaseRule) GeneratorURL() string {
 	return prepareRuleGeneratorURL(r.ID(), r.source)
@@ -311,6 +373,22 @@ func (r *BaseRule) ActiveAlerts() []*Alert {
 func (r *BaseRule) SendAlerts(ctx context.Context, ts time.Time, resendDelay time.Duration, interval time.Duration, notifyFunc NotifyFunc) {
 	alerts := []*Alert{}
 	r.ForEachActiveAlert(func(alert *Alert) {
+		// the flapping alerts are notified as firing until they settle, and
+		// are then notified as they are
+		if state := r.flapping(alert.Labels.Hash(), ts); state != nil {
+			if !state.sentAt.Add(resendDelay).Before(ts) {
+				return
+			}
+			state.sentAt = ts
+			delta := resendDelay
+			if interval > resendDelay {
+				delta = interval
+			}
+			anew := r.flappingAlert(alert, state)
+			anew.ValidUntil = ts.Add(4 * delta)
+			alerts = append(alerts, anew)
+			return
+		}
 		if alert.needsSending(ts, resendDelay) {
 			alert.LastSentAt = ts
 			delta := resendDelay
@@ -318,7 +396,13 @@ func (r *BaseRule) SendAlerts(ctx context.Context, ts time.Time, resendDelay tim
 				delta = interval
 			}
 			alert.ValidUntil = ts.Add(4 * delta)
+			if !alert.ResolvedAt.IsZero() && !r.resolvedNotification.notifies(alert) {
+				return
+			}
 			anew := *alert
+			if !alert.ResolvedAt.IsZero() {
+				anew.Annotations = alert.resolvedAnnotations()
+			}
 			alerts = append(alerts, &anew)
 		}
 	})
@@ -334,7 +418,52 @@ func (r *BaseRule) ForEachActiveAlert(f func(*Alert)) {
 	}
 }
 
+// ShouldAlert evaluates the series against the target of the rule, or
+// against its thresholds per severity in order, the first one crossed
+// setting the severity of the sample
 func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
+	if len(r.ruleCondition.Thresholds) == 0 {
+		return r.shouldAlert(series, r.targetVal())
+	}
+
+	for _, threshold := range r.ruleCondition.Thresholds {
+		if smpl, ok := r.shouldAlert(series, r.convertTarget(threshold.Target)); ok {
+			smpl.Severity = threshold.Severity
+			return smpl, true
+		}
+	}
+	return Sample{}, false
+}
+
+// shouldKeepFiring evaluates the series of a firing alert against the clear
+// target of the rule, the alert keeps firing until the series crosses it
+func (r *BaseRule) shouldKeepFiring(series v3.Series) (Sample, bool) {
+	if r.ruleCondition.ClearTarget == nil || !r.isFiring(seriesFingerprint(&series)) {
+		return Sample{}, false
+	}
+	return r.shouldAlert(series, r.convertTarget(r.ruleCondition.ClearTarget))
+}
+
+// isFiring tells if the alert of the series with the fingerprint is firing
+func (r *BaseRule) isFiring(fp uint64) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for _, a := range r.Active {
+		if a.State == model.StateFiring && a.QueryResultLables.Hash() == fp {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *BaseRule) shouldAlert(series v3.Series, target float64) (Sample, bool) {
+	return r.shouldAlertWith(series, target, r.compareOp(), r.matchType())
+}
+
+// shouldAlertWith checks the series against the target with the compare op
+// and match type, which are the ones of the rule unless the series is of a
+// query with a condition of its own
+func (r *BaseRule) shouldAlertWith(series v3.Series, target float64, compareOp CompareOp, matchType MatchType) (Sample, bool) {
 	var alertSmpl Sample
 	var shouldAlert bool
 	var lbls qslabels.Labels
@@ -357,44 +486,44 @@ func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
 		}
 	}
 
-	switch r.matchType() {
+	switch matchType {
 	case AtleastOnce:
 		// If any sample matches the condition, the rule is firing.
-		if r.compareOp() == ValueIsAbove {
+		if compareOp == ValueIsAbove {
 			for _, smpl := range series.Points {
-				if smpl.Value > r.targetVal() {
+				if smpl.Value > target {
 					alertSmpl = Sample{Point: Point{V: smpl.Value}, Metric: lbls}
 					shouldAlert = true
 					break
 				}
 			}
-		} else if r.compareOp() == ValueIsBelow {
+		} else if compareOp == ValueIsBelow {
 			for _, smpl := range series.Points {
-				if smpl.Value < r.targetVal() {
+				if smpl.Value < target {
 					alertSmpl = Sample{Point: Point{V: smpl.Value}, Metric: lbls}
 					shouldAlert = true
 					break
 				}
 			}
-		} else if r.compareOp() == ValueIsEq {
+		} else if compareOp == ValueIsEq {
 			for _, smpl := range series.Points {
-				if smpl.Value == r.targetVal() {
+				if smpl.Value == target {
 					alertSmpl = Sample{Point: Point{V: smpl.Value}, Metric: lbls}
 					shouldAlert = true
 					break
 				}
 			}
-		} else if r.compareOp() == ValueIsNotEq {
+		} else if compareOp == ValueIsNotEq {
 			for _, smpl := range series.Points {
-				if smpl.Value != r.targetVal() {
+				if smpl.Value != target {
 					alertSmpl = Sample{Point: Point{V: smpl.Value}, Metric: lbls}
 					shouldAlert = true
 					break
 				}
 			}
-		} else if r.compareOp() == ValueOutsideBounds {
+		} else if compareOp == ValueOutsideBounds {
 			for _, smpl := range series.Points {
-				if math.Abs(smpl.Value) >= r.targetVal() {
+				if math.Abs(smpl.Value) >= target {
 					alertSmpl = Sample{Point: Point{V: smpl.Value}, Metric: lbls}
 					shouldAlert = true
 					break
@@ -404,10 +533,10 @@ func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
 	case AllTheTimes:
 		// If all samples match the condition, the rule is firing.
 		shouldAlert = true
-		alertSmpl = Sample{Point: Point{V: r.targetVal()}, Metric: lbls}
-		if r.compareOp() == ValueIsAbove {
+		alertSmpl = Sample{Point: Point{V: target}, Metric: lbls}
+		if compareOp == ValueIsAbove {
 			for _, smpl := range series.Points {
-				if smpl.Value <= r.targetVal() {
+				if smpl.Value <= target {
 					shouldAlert = false
 					break
 				}
@@ -422,9 +551,9 @@ func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
 				}
 				alertSmpl = Sample{Point: Point{V: minValue}, Metric: lbls}
 			}
-		} else if r.compareOp() == ValueIsBelow {
+		} else if compareOp == ValueIsBelow {
 			for _, smpl := range series.Points {
-				if smpl.Value >= r.targetVal() {
+				if smpl.Value >= target {
 					shouldAlert = false
 					break
 				}
@@ -438,16 +567,16 @@ func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
 				}
 				alertSmpl = Sample{Point: Point{V: maxValue}, Metric: lbls}
 			}
-		} else if r.compareOp() == ValueIsEq {
+		} else if compareOp == ValueIsEq {
 			for _, smpl := range series.Points {
-				if smpl.Value != r.targetVal() {
+				if smpl.Value != target {
 					shouldAlert = false
 					break
 				}
 			}
-		} else if r.compareOp() == ValueIsNotEq {
+		} else if compareOp == ValueIsNotEq {
 			for _, smpl := range series.Points {
-				if smpl.Value == r.targetVal() {
+				if smpl.Value == target {
 					shouldAlert = false
 					break
 				}
@@ -461,9 +590,9 @@ func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
 					}
 				}
 			}
-		} else if r.compareOp() == ValueOutsideBounds {
+		} else if compareOp == ValueOutsideBounds {
 			for _, smpl := range series.Points {
-				if math.Abs(smpl.Value) < r.targetVal() {
+				if math.Abs(smpl.Value) < target {
 					alertSmpl = Sample{Point: Point{V: smpl.Value}, Metric: lbls}
 					shouldAlert = false
 					break
@@ -482,24 +611,24 @@ func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
 		}
 		avg := sum / count
 		alertSmpl = Sample{Point: Point{V: avg}, Metric: lbls}
-		if r.compareOp() == ValueIsAbove {
-			if avg > r.targetVal() {
+		if compareOp == ValueIsAbove {
+			if avg > target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueIsBelow {
-			if avg < r.targetVal() {
+		} else if compareOp == ValueIsBelow {
+			if avg < target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueIsEq {
-			if avg == r.targetVal() {
+		} else if compareOp == ValueIsEq {
+			if avg == target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueIsNotEq {
-			if avg != r.targetVal() {
+		} else if compareOp == ValueIsNotEq {
+			if avg != target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueOutsideBounds {
-			if math.Abs(avg) >= r.targetVal() {
+		} else if compareOp == ValueOutsideBounds {
+			if math.Abs(avg) >= target {
 				shouldAlert = true
 			}
 		}
@@ -514,24 +643,24 @@ func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
 			sum += smpl.Value
 		}
 		alertSmpl = Sample{Point: Point{V: sum}, Metric: lbls}
-		if r.compareOp() == ValueIsAbove {
-			if sum > r.targetVal() {
+		if compareOp == ValueIsAbove {
+			if sum > target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueIsBelow {
-			if sum < r.targetVal() {
+		} else if compareOp == ValueIsBelow {
+			if sum < target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueIsEq {
-			if sum == r.targetVal() {
+		} else if compareOp == ValueIsEq {
+			if sum == target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueIsNotEq {
-			if sum != r.targetVal() {
+		} else if compareOp == ValueIsNotEq {
+			if sum != target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueOutsideBounds {
-			if math.Abs(sum) >= r.targetVal() {
+		} else if compareOp == ValueOutsideBounds {
+			if math.Abs(sum) >= target {
 				shouldAlert = true
 			}
 		}
@@ -539,20 +668,20 @@ func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
 		// If the last sample matches the condition, the rule is firing.
 		shouldAlert = false
 		alertSmpl = Sample{Point: Point{V: series.Points[len(series.Points)-1].Value}, Metric: lbls}
-		if r.compareOp() == ValueIsAbove {
-			if series.Points[len(series.Points)-1].Value > r.targetVal() {
+		if compareOp == ValueIsAbove {
+			if series.Points[len(series.Points)-1].Value > target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueIsBelow {
-			if series.Points[len(series.Points)-1].Value < r.targetVal() {
+		} else if compareOp == ValueIsBelow {
+			if series.Points[len(series.Points)-1].Value < target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueIsEq {
-			if series.Points[len(series.Points)-1].Value == r.targetVal() {
+		} else if compareOp == ValueIsEq {
+			if series.Points[len(series.Points)-1].Value == target {
 				shouldAlert = true
 			}
-		} else if r.compareOp() == ValueIsNotEq {
-			if series.Points[len(series.Points)-1].Value != r.targetVal() {
+		} else if compareOp == ValueIsNotEq {
+			if series.Points[len(series.Points)-1].Value != target {
 				shouldAlert = true
 			}
 		}
@@ -561,6 +690,9 @@ func (r *BaseRule) ShouldAlert(series v3.Series) (Sample, bool) {
 }
 
 func (r *BaseRule) RecordRuleStateHistory(ctx context.Context, prevState, currentState model.AlertState, itemsToAdd []model.RuleStateHistory) error {
+	if r.skipStateHistory {
+		return nil
+	}
 	zap.L().Debug("recording rule state history", zap.String("ruleid", r.ID()), zap.Any("prevState", prevState), zap.Any("currentState", currentState), zap.Any("itemsToAdd", itemsToAdd))
 	revisedItemsToAdd := map[uint64]model.RuleStateHistory{}
 
@@ -0,0 +1,251 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// RuleBundleVersion is the version of the bundle format written by the export
+const RuleBundleVersion = 1
+
+var bundleVariableRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// RuleBundle is a set of rules with the names of the channels they notify, to
+// promote the alerting from one environment to another. The rules may refer
+// to variables as ${NAME}, which are substituted on import.
+type RuleBundle struct {
+	Version int `json:"version" yaml:"version"`
+	// Variables are the default values of the variables
+	Variables map[string]string        `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Channels  []string                 `json:"channels,omitempty" yaml:"channels,omitempty"`
+	Rules     []map[string]interface{} `json:"rules" yaml:"rules"`
+}
+
+// RuleBundleImport imports the bundle with the values of its variables for
+// the environment, such as the env label or the thresholds
+type RuleBundleImport struct {
+	Bundle    RuleBundle        `json:"bundle" yaml:"bundle"`
+	Variables map[string]string `json:"variables,omitempty" yaml:"variables,omitempty"`
+	// DryRun only checks the rules of the bundle would be imported
+	DryRun bool `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+}
+
+// ExportRules bundles the rules with the ids, or all of the rules if there
+// are none
+func (m *Manager) ExportRules(ctx context.Context, ids []string) (*RuleBundle, error) {
+	storedRules := []StoredRule{}
+	if len(ids) == 0 {
+		all, err := m.ruleDB.GetStoredRules(ctx)
+		if err != nil {
+			return nil, err
+		}
+		storedRules = all
+	} else {
+		for _, id := range ids {
+			storedRule, err := m.ruleDB.GetStoredRule(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s not found: %w", id, err)
+			}
+			storedRules = append(storedRules, *storedRule)
+		}
+	}
+
+	bundle := &RuleBundle{Version: RuleBundleVersion, Rules: []map[string]interface{}{}}
+	channels := map[string]bool{}
+	for _, storedRule := range storedRules {
+		rule := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(storedRule.Data), &rule); err != nil {
+			zap.L().Error("failed to unmarshal rule from db", zap.Int("id", storedRule.Id), zap.Error(err))
+			continue
+		}
+		bundle.Rules = append(bundle.Rules, rule)
+
+		postableRule := PostableRule{}
+		if err := json.Unmarshal([]byte(storedRule.Data), &postableRule); err != nil {
+			continue
+		}
+		for _, channel := range postableRule.channels() {
+			channels[channel] = true
+		}
+	}
+
+	for channel := range channels {
+		bundle.Channels = append(bundle.Channels, channel)
+	}
+	sort.Strings(bundle.Channels)
+	return bundle, nil
+}
+
+// ImportRules creates the rules of the bundle. The rules are all checked,
+// along with the channels they notify, before any of them is created.
+func (m *Manager) ImportRules(ctx context.Context, params *RuleBundleImport) ([]*GettableRule, error) {
+	ruleStrs, err := params.Bundle.resolve(params.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*GettableRule, 0, len(ruleStrs))
+	referenced := map[string]bool{}
+	for _, channel := range params.Bundle.Channels {
+		referenced[channel] = true
+	}
+	for i, ruleStr := range ruleStrs {
+		parsedRule, err := ParsePostableRule([]byte(ruleStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+		rules = append(rules, &GettableRule{PostableRule: *parsedRule})
+		for _, channel := range parsedRule.channels() {
+			referenced[channel] = true
+		}
+	}
+
+	channels, apiErr := m.ruleDB.GetChannels()
+	if apiErr != nil {
+		return nil, apiErr.Err
+	}
+	for _, channel := range *channels {
+		delete(referenced, channel.Name)
+	}
+	schedules, err := m.ruleDB.GetAllOnCallSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range schedules {
+		delete(referenced, OnCallChannelPrefix+schedule.Name)
+	}
+	if len(referenced) > 0 {
+		missing := make([]string, 0, len(referenced))
+		for channel := range referenced {
+			missing = append(missing, channel)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("channels missing in this environment: %s", strings.Join(missing, ", "))
+	}
+
+	if params.DryRun {
+		return rules, nil
+	}
+
+	for i, ruleStr := range ruleStrs {
+		rule, err := m.CreateRule(ctx, ruleStr)
+		if err != nil {
+			return rules[:i], fmt.Errorf("failed to create rule %s, the rules before it were created: %w", rules[i].AlertName, err)
+		}
+		rules[i] = rule
+	}
+	return rules, nil
+}
+
+// channels returns the channels the rule notifies, including the channels of
+// its thresholds per severity
+func (r *PostableRule) channels() []string {
+	channels := append([]string{}, r.PreferredChannels...)
+	if r.RuleCondition != nil {
+		for _, threshold := range r.RuleCondition.Thresholds {
+			channels = append(channels, threshold.PreferredChannels...)
+		}
+	}
+	return channels
+}
+
+// resolve substitutes the variables in the rules of the bundle, the values
+// given override the defaults of the bundle
+func (b *RuleBundle) resolve(values map[string]string) ([]string, error) {
+	if b.Version > RuleBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+	if len(b.Rules) == 0 {
+		return nil, fmt.Errorf("the bundle has no rules")
+	}
+
+	variables := map[string]string{}
+	for name, value := range b.Variables {
+		variables[name] = value
+	}
+	for name, value := range values {
+		variables[name] = value
+	}
+
+	missing := map[string]bool{}
+	ruleStrs := make([]string, 0, len(b.Rules))
+	for _, rule := range b.Rules {
+		data, err := json.Marshal(substituteVariables("", rule, variables, missing))
+		if err != nil {
+			return nil, err
+		}
+		ruleStrs = append(ruleStrs, string(data))
+	}
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("missing values for the variables: %s", strings.Join(names, ", "))
+	}
+	return ruleStrs, nil
+}
+
+// typedRuleFields are the fields of the rules which are not strings, a
+// variable making up the whole value of one takes its type
+var typedRuleFields = map[string]bool{
+	"target":            true,
+	"absentFor":         true,
+	"requiredNumPoints": true,
+	"alertOnAbsent":     true,
+	"requireMinPoints":  true,
+	"disabled":          true,
+}
+
+// substituteVariables replaces the variables in the strings of the value,
+// field is the name of the field holding it
+func substituteVariables(field string, value interface{}, variables map[string]string, missing map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		substituted := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			substituted[key] = substituteVariables(key, child, variables, missing)
+		}
+		return substituted
+	case []interface{}:
+		substituted := make([]interface{}, len(v))
+		for i, child := range v {
+			substituted[i] = substituteVariables(field, child, variables, missing)
+		}
+		return substituted
+	case string:
+		if match := bundleVariableRegexp.FindStringSubmatch(v); match != nil && match[0] == v && typedRuleFields[field] {
+			variable, ok := variables[match[1]]
+			if !ok {
+				missing[match[1]] = true
+				return v
+			}
+			if number, err := strconv.ParseFloat(variable, 64); err == nil {
+				return number
+			}
+			if variable == "true" || variable == "false" {
+				return variable == "true"
+			}
+			return variable
+		}
+		return bundleVariableRegexp.ReplaceAllStringFunc(v, func(s string) string {
+			name := bundleVariableRegexp.FindStringSubmatch(s)[1]
+			variable, ok := variables[name]
+			if !ok {
+				missing[name] = true
+				return s
+			}
+			return variable
+		})
+	}
+	return value
+}
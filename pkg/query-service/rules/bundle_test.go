@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const testRuleBundle = `
+version: 1
+variables:
+  ENV: staging
+channels:
+  - oncall
+rules:
+  - alert: "high latency in ${ENV}"
+    labels:
+      env: "${ENV}"
+      team: "${TEAM}"
+    preferredChannels:
+      - oncall
+    condition:
+      op: "1"
+      matchType: "1"
+      target: "${LATENCY_THRESHOLD}"
+      compositeQuery:
+        queryType: builder
+        builderQueries:
+          A:
+            queryName: A
+            expression: A
+            dataSource: metrics
+            aggregateOperator: avg
+            aggregateAttribute:
+              key: signoz_latency
+`
+
+func TestRuleBundleResolve(t *testing.T) {
+	require := require.New(t)
+
+	bundle := RuleBundle{}
+	require.NoError(yaml.Unmarshal([]byte(testRuleBundle), &bundle))
+
+	_, err := bundle.resolve(map[string]string{"TEAM": "checkout"})
+	require.ErrorContains(err, "LATENCY_THRESHOLD")
+
+	ruleStrs, err := bundle.resolve(map[string]string{"ENV": "prod", "TEAM": "1", "LATENCY_THRESHOLD": "500"})
+	require.NoError(err)
+	require.Len(ruleStrs, 1)
+
+	rule, err := ParsePostableRule([]byte(ruleStrs[0]))
+	require.NoError(err)
+	require.Equal("high latency in prod", rule.AlertName)
+	require.Equal("prod", rule.Labels["env"])
+	// only the fields which are not strings take the type of the variable
+	require.Equal("1", rule.Labels["team"])
+	require.Equal(float64(500), *rule.RuleCondition.Target)
+	require.Equal([]string{"oncall"}, rule.channels())
+
+	bundle.Version = RuleBundleVersion + 1
+	_, err = bundle.resolve(map[string]string{"TEAM": "checkout", "LATENCY_THRESHOLD": "500"})
+	require.Error(err)
+}
@@ -0,0 +1,179 @@
+package rules
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	am "go.signoz.io/signoz/pkg/query-service/integrations/alertManager"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+// ChannelTemplate is the template of the notifications of the channels of a
+// type. The template of a type replaces its default template, and sets the
+// fields the channels of the type leave empty.
+type ChannelTemplate struct {
+	Id        int64                 `json:"id,omitempty" db:"id"`
+	Type      string                `json:"type" db:"type"`
+	Fields    ChannelTemplateFields `json:"fields" db:"fields"`
+	UpdatedAt time.Time             `json:"updatedAt,omitempty" db:"updated_at"`
+	UpdatedBy string                `json:"updatedBy,omitempty" db:"updated_by"`
+	// Default tells the template is the default template of the type
+	Default bool `json:"default" db:"-"`
+}
+
+// ChannelTemplateFields are the templates of the fields of the configs of a
+// channel type by the name of the field
+type ChannelTemplateFields map[string]string
+
+func (f *ChannelTemplateFields) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("invalid channel template fields: %v", src)
+	}
+	return json.Unmarshal(data, f)
+}
+
+func (f ChannelTemplateFields) Value() (driver.Value, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (t *ChannelTemplate) Validate() error {
+	if t.Type == "" {
+		return fmt.Errorf("missing channel type")
+	}
+	return am.ChannelTemplate(t.Fields).Validate(t.Type)
+}
+
+// GetAllChannelTemplates returns the templates of the channel types, the
+// stored ones and the defaults of the types without one
+func (r *ruleDB) GetAllChannelTemplates(ctx context.Context) ([]ChannelTemplate, error) {
+	stored := []ChannelTemplate{}
+
+	err := r.Select(&stored, "SELECT * FROM channel_templates")
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	templates := stored
+	for typ, fields := range am.DefaultChannelTemplates() {
+		if !hasChannelTemplate(stored, typ) {
+			templates = append(templates, ChannelTemplate{Type: typ, Fields: ChannelTemplateFields(fields), Default: true})
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Type < templates[j].Type
+	})
+	return templates, nil
+}
+
+func hasChannelTemplate(templates []ChannelTemplate, typ string) bool {
+	for _, t := range templates {
+		if t.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// ChannelTemplates returns the templates of the channel types by type
+func (r *ruleDB) ChannelTemplates(ctx context.Context) (map[string]am.ChannelTemplate, error) {
+	all, err := r.GetAllChannelTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	templates := make(map[string]am.ChannelTemplate, len(all))
+	for _, t := range all {
+		templates[t.Type] = am.ChannelTemplate(t.Fields)
+	}
+	return templates, nil
+}
+
+func (r *ruleDB) SetChannelTemplate(ctx context.Context, channelTemplate ChannelTemplate) error {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("no claims found in context")
+	}
+	channelTemplate.UpdatedBy = claims.Email
+	channelTemplate.UpdatedAt = time.Now()
+
+	query := "INSERT INTO channel_templates (type, fields, updated_at, updated_by) VALUES ($1, $2, $3, $4) ON CONFLICT (type) DO UPDATE SET fields=$2, updated_at=$3, updated_by=$4"
+	_, err := r.Exec(query, channelTemplate.Type, channelTemplate.Fields, channelTemplate.UpdatedAt, channelTemplate.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return r.syncChannelTemplates(ctx, channelTemplate.Type)
+}
+
+// DeleteChannelTemplate deletes the template of the channel type, which is
+// back to its default template
+func (r *ruleDB) DeleteChannelTemplate(ctx context.Context, typ string) error {
+	_, err := r.Exec("DELETE FROM channel_templates WHERE type=$1", typ)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return r.syncChannelTemplates(ctx, typ)
+}
+
+// templatedReceiver returns a copy of the receiver with the templates of the
+// channel types, as it is sent to the alertmanager. The receiver is stored
+// without them, for the channels to follow the changes of the templates.
+func (r *ruleDB) templatedReceiver(ctx context.Context, receiver *am.Receiver) (*am.Receiver, *model.ApiError) {
+	templates, err := r.ChannelTemplates(ctx)
+	if err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+	templated := *receiver
+	if err := templated.ApplyTemplates(templates); err != nil {
+		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: err}
+	}
+	return &templated, nil
+}
+
+// syncChannelTemplates sends the channels of the type to the alertmanager
+// with the current templates
+func (r *ruleDB) syncChannelTemplates(ctx context.Context, typ string) error {
+	channels, apiErr := r.GetChannels()
+	if apiErr != nil {
+		return apiErr.Err
+	}
+
+	for _, channel := range *channels {
+		if channel.Type != typ {
+			continue
+		}
+		receiver := &am.Receiver{}
+		if err := json.Unmarshal([]byte(channel.Data), receiver); err != nil {
+			zap.L().Error("failed to parse the channel", zap.String("channel", channel.Name), zap.Error(err))
+			continue
+		}
+		templated, apiErr := r.templatedReceiver(ctx, receiver)
+		if apiErr != nil {
+			return apiErr.Err
+		}
+		if apiErr := r.alertManager.EditRoute(templated); apiErr != nil {
+			return fmt.Errorf("failed to update the channel %s with the template: %v", channel.Name, apiErr.Err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func TestChannelTemplates(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "admin@signoz.io"})
+
+	templates, err := ruleDB.GetAllChannelTemplates(ctx)
+	require.NoError(err)
+	for _, template := range templates {
+		require.True(template.Default, template.Type)
+	}
+
+	slack := ChannelTemplate{Type: "slack", Fields: ChannelTemplateFields{"title": "{{ .CommonLabels.alertname }}"}}
+	require.NoError(slack.Validate())
+	require.NoError(ruleDB.SetChannelTemplate(ctx, slack))
+	webhook := ChannelTemplate{Type: "webhook", Fields: ChannelTemplateFields{"payload": `{"status": "{{ .Status }}"}`}}
+	require.NoError(ruleDB.SetChannelTemplate(ctx, webhook))
+
+	byType, err := ruleDB.ChannelTemplates(ctx)
+	require.NoError(err)
+	// the template replaces the default template of the type
+	require.Equal("{{ .CommonLabels.alertname }}", byType["slack"]["title"])
+	require.NotContains(byType["slack"], "text")
+	require.Contains(byType["webhook"], "payload")
+	require.Contains(byType["email"], "html")
+
+	templates, err = ruleDB.GetAllChannelTemplates(ctx)
+	require.NoError(err)
+	for _, template := range templates {
+		if template.Type == "slack" {
+			require.False(template.Default)
+			require.Equal("admin@signoz.io", template.UpdatedBy)
+		}
+	}
+
+	require.NoError(ruleDB.DeleteChannelTemplate(ctx, "slack"))
+	byType, err = ruleDB.ChannelTemplates(ctx)
+	require.NoError(err)
+	require.Contains(byType["slack"], "text")
+
+	require.Error((&ChannelTemplate{Type: "slack", Fields: ChannelTemplateFields{"payload": "{}"}}).Validate())
+}
@@ -0,0 +1,176 @@
+package rules
+
+import (
+	"fmt"
+
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+)
+
+// LogicalOp combines the conditions of a composite condition
+type LogicalOp string
+
+const (
+	LogicalOpAnd LogicalOp = "and"
+	LogicalOpOr  LogicalOp = "or"
+)
+
+// CompositeCondition combines conditions on the results of the queries of a
+// rule, such as the error rate above 5% and the traffic above 100 rps. It is
+// either a condition on a query, or the conditions it combines with its op.
+//
+// The series of the queries are matched by their labels, a series matching
+// the series of another query which has all its labels. The series of a
+// query without a group by matches all the series of the others.
+type CompositeCondition struct {
+	Op         LogicalOp            `yaml:"op,omitempty" json:"op,omitempty"`
+	Conditions []CompositeCondition `yaml:"conditions,omitempty" json:"conditions,omitempty"`
+
+	QueryName string    `yaml:"queryName,omitempty" json:"queryName,omitempty"`
+	CompareOp CompareOp `yaml:"compareOp,omitempty" json:"compareOp,omitempty"`
+	MatchType MatchType `yaml:"matchType,omitempty" json:"matchType,omitempty"`
+	// Target is in the unit of the results of the query
+	Target *float64 `yaml:"target,omitempty" json:"target,omitempty"`
+}
+
+func (c *CompositeCondition) isQueryCondition() bool {
+	return c.QueryName != ""
+}
+
+// Validate checks the condition against the names of the queries of the rule
+func (c *CompositeCondition) Validate(queryNames map[string]bool) error {
+	if c.isQueryCondition() {
+		if len(c.Conditions) > 0 || c.Op != "" {
+			return fmt.Errorf("the condition on the query %s can't combine conditions", c.QueryName)
+		}
+		if !queryNames[c.QueryName] {
+			return fmt.Errorf("the condition is on the unknown query %s", c.QueryName)
+		}
+		if c.CompareOp == "" {
+			return fmt.Errorf("the condition on the query %s is missing the compare op", c.QueryName)
+		}
+		if c.MatchType == "" {
+			return fmt.Errorf("the condition on the query %s is missing the match option", c.QueryName)
+		}
+		if c.Target == nil {
+			return fmt.Errorf("the condition on the query %s is missing the threshold", c.QueryName)
+		}
+		return nil
+	}
+
+	if c.Op != LogicalOpAnd && c.Op != LogicalOpOr {
+		return fmt.Errorf("invalid condition op: %s", c.Op)
+	}
+	if len(c.Conditions) == 0 {
+		return fmt.Errorf("the %s condition has no conditions", c.Op)
+	}
+	for i := range c.Conditions {
+		if err := c.Conditions[i].Validate(queryNames); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryNames returns the queries the condition is on, in order
+func (c *CompositeCondition) queryNames() []string {
+	if c.isQueryCondition() {
+		return []string{c.QueryName}
+	}
+	var names []string
+	for i := range c.Conditions {
+		names = append(names, c.Conditions[i].queryNames()...)
+	}
+	return names
+}
+
+// eval tells if the condition holds for the series with the labels, and the
+// value of the first query condition holding for them
+func (c *CompositeCondition) eval(r *BaseRule, results map[string]*v3.Result, lbls map[string]string) (float64, bool) {
+	if c.isQueryCondition() {
+		result, ok := results[c.QueryName]
+		if !ok {
+			return 0, false
+		}
+		for _, series := range result.Series {
+			if !labelsSubset(series.Labels, lbls) {
+				continue
+			}
+			if smpl, ok := r.shouldAlertWith(*series, *c.Target, c.CompareOp, c.MatchType); ok {
+				return smpl.V, true
+			}
+		}
+		return 0, false
+	}
+
+	var value float64
+	for i := range c.Conditions {
+		v, ok := c.Conditions[i].eval(r, results, lbls)
+		switch {
+		case c.Op == LogicalOpOr && ok:
+			return v, true
+		case c.Op == LogicalOpAnd && !ok:
+			return 0, false
+		case i == 0:
+			value = v
+		}
+	}
+	return value, c.Op == LogicalOpAnd
+}
+
+// labelsSubset tells if all the labels of a are in b
+func labelsSubset(a, b map[string]string) bool {
+	for name, value := range a {
+		if v, ok := b[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// evalCompositeCondition returns the samples of the series for which the
+// composite condition holds. The series evaluated are the series of its
+// queries with the most labels, the series whose labels are all in another
+// series being evaluated as part of it.
+func (r *BaseRule) evalCompositeCondition(condition *CompositeCondition, results []*v3.Result) Vector {
+	byName := make(map[string]*v3.Result, len(results))
+	for _, result := range results {
+		byName[result.QueryName] = result
+	}
+
+	var candidates []map[string]string
+	seen := map[uint64]bool{}
+	for _, name := range condition.queryNames() {
+		result, ok := byName[name]
+		if !ok {
+			continue
+		}
+		for _, series := range result.Series {
+			h := labels.FromMap(series.Labels).Hash()
+			if seen[h] {
+				continue
+			}
+			seen[h] = true
+			candidates = append(candidates, series.Labels)
+		}
+	}
+
+	var resultVector Vector
+	for i, lbls := range candidates {
+		covered := false
+		for j, other := range candidates {
+			if i != j && len(other) > len(lbls) && labelsSubset(lbls, other) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+
+		if value, ok := condition.eval(r, byName, lbls); ok {
+			resultVector = append(resultVector, Sample{Point: Point{V: value}, Metric: labels.FromMap(lbls)})
+		}
+	}
+	return resultVector
+}
@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func TestEvalCompositeCondition(t *testing.T) {
+	errorRate, traffic := 5.0, 100.0
+	condition := &CompositeCondition{
+		Op: LogicalOpAnd,
+		Conditions: []CompositeCondition{
+			{QueryName: "A", CompareOp: ValueIsAbove, MatchType: AtleastOnce, Target: &errorRate},
+			{QueryName: "B", CompareOp: ValueIsAbove, MatchType: OnAverage, Target: &traffic},
+		},
+	}
+	require.NoError(t, condition.Validate(map[string]bool{"A": true, "B": true}))
+	require.Error(t, condition.Validate(map[string]bool{"A": true}))
+
+	series := func(service string, values ...float64) *v3.Series {
+		s := &v3.Series{Labels: map[string]string{}}
+		if service != "" {
+			s.Labels["service.name"] = service
+		}
+		for i, v := range values {
+			s.Points = append(s.Points, v3.Point{Timestamp: int64(i * 60000), Value: v})
+		}
+		return s
+	}
+	results := []*v3.Result{
+		{QueryName: "A", Series: []*v3.Series{series("frontend", 2, 8), series("cart", 6, 7), series("payment", 1, 2)}},
+		{QueryName: "B", Series: []*v3.Series{series("frontend", 150, 250), series("cart", 10, 20), series("payment", 500, 500)}},
+	}
+
+	r := &BaseRule{ruleCondition: &RuleCondition{}}
+	vector := r.evalCompositeCondition(condition, results)
+	require.Len(t, vector, 1)
+	assert.Equal(t, "frontend", vector[0].Metric.Get("service.name"))
+	assert.Equal(t, float64(8), vector[0].V)
+
+	condition.Op = LogicalOpOr
+	vector = r.evalCompositeCondition(condition, results)
+	require.Len(t, vector, 3)
+
+	// the series without a group by matches all the others
+	results[1] = &v3.Result{QueryName: "B", Series: []*v3.Series{series("", 150, 150)}}
+	condition.Op = LogicalOpAnd
+	vector = r.evalCompositeCondition(condition, results)
+	require.Len(t, vector, 2)
+	for _, smpl := range vector {
+		assert.Contains(t, []string{"frontend", "cart"}, smpl.Metric.Get("service.name"))
+	}
+}
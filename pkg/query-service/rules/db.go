@@ -42,6 +42,12 @@ type RuleDB interface {
 	// GetStoredRule for a given ID from DB
 	GetStoredRule(ctx context.Context, id string) (*StoredRule, error)
 
+	// GetRuleVersions fetches the saved versions of the rule, newest first
+	GetRuleVersions(ctx context.Context, id string) ([]RuleVersion, error)
+
+	// GetRuleVersion fetches a saved version of the rule
+	GetRuleVersion(ctx context.Context, id string, version int) (*RuleVersion, error)
+
 	// CreatePlannedMaintenance stores a given maintenance in db
 	CreatePlannedMaintenance(ctx context.Context, maintenance PlannedMaintenance) (int64, error)
 
@@ -57,6 +63,182 @@ type RuleDB interface {
 	// GetAllPlannedMaintenance fetches the maintenance definitions from db
 	GetAllPlannedMaintenance(ctx context.Context) ([]PlannedMaintenance, error)
 
+	// RecordMaintenanceSuppression adds to the counts of what the maintenance
+	// suppressed for the rule
+	RecordMaintenanceSuppression(ctx context.Context, maintenanceId int64, ruleId string, suppressedAlerts int, skippedEvaluations int) error
+
+	// GetMaintenanceSuppressions fetches the counts of what the maintenance
+	// suppressed, per rule
+	GetMaintenanceSuppressions(ctx context.Context, id string) ([]MaintenanceSuppression, error)
+
+	// GetAllEscalationPolicies fetches the escalation policies from db
+	GetAllEscalationPolicies(ctx context.Context) ([]EscalationPolicy, error)
+
+	// GetEscalationPolicyByID fetches the escalation policy from db by id
+	GetEscalationPolicyByID(ctx context.Context, id string) (*EscalationPolicy, error)
+
+	// CreateEscalationPolicy stores a given escalation policy in db
+	CreateEscalationPolicy(ctx context.Context, policy EscalationPolicy) (int64, error)
+
+	// EditEscalationPolicy updates the given escalation policy in the db
+	EditEscalationPolicy(ctx context.Context, policy EscalationPolicy, id string) error
+
+	// DeleteEscalationPolicy deletes the given escalation policy in the db
+	DeleteEscalationPolicy(ctx context.Context, id string) error
+
+	// RecordAlertEscalation stores an escalation or acknowledgement of an alert
+	RecordAlertEscalation(ctx context.Context, escalation AlertEscalation) error
+
+	// GetAlertEscalations fetches the escalations and acknowledgements of the
+	// alerts of the rule, newest first
+	GetAlertEscalations(ctx context.Context, ruleId string) ([]AlertEscalation, error)
+
+	// CreateAlertSilence creates the silence in alertmanager and returns its
+	// id, none without an alertmanager
+	CreateAlertSilence(silence *am.Silence) (string, error)
+
+	// DeleteAlertSilence deletes the silence with the id in alertmanager
+	DeleteAlertSilence(id string) error
+
+	// GetAllOnCallSchedules fetches the on-call schedules from db
+	GetAllOnCallSchedules(ctx context.Context) ([]OnCallSchedule, error)
+
+	// GetOnCallScheduleByID fetches the on-call schedule from db by id
+	GetOnCallScheduleByID(ctx context.Context, id string) (*OnCallSchedule, error)
+
+	// CreateOnCallSchedule stores a given on-call schedule in db
+	CreateOnCallSchedule(ctx context.Context, schedule OnCallSchedule) (int64, error)
+
+	// EditOnCallSchedule updates the given on-call schedule in the db
+	EditOnCallSchedule(ctx context.Context, schedule OnCallSchedule, id string) error
+
+	// DeleteOnCallSchedule deletes the given on-call schedule in the db
+	DeleteOnCallSchedule(ctx context.Context, id string) error
+
+	// GetAllChannelRateLimits fetches the rate limits of the channels from db
+	GetAllChannelRateLimits(ctx context.Context) ([]ChannelRateLimit, error)
+
+	// SetChannelRateLimit stores the rate limit of the channel in db,
+	// replacing the one it had
+	SetChannelRateLimit(ctx context.Context, limit ChannelRateLimit) error
+
+	// DeleteChannelRateLimit deletes the rate limit of the channel in db
+	DeleteChannelRateLimit(ctx context.Context, channel string) error
+
+	// GetLogPatterns fetches the log patterns seen by the rule from db
+	GetLogPatterns(ctx context.Context, ruleID string) ([]LogPattern, error)
+
+	// SaveLogPatterns stores the log patterns newly seen by a rule in db
+	SaveLogPatterns(ctx context.Context, patterns []LogPattern) error
+
+	// GetAllSLOs fetches the SLOs from db
+	GetAllSLOs(ctx context.Context) ([]SLO, error)
+
+	// GetSLOByID fetches the SLO from db by id
+	GetSLOByID(ctx context.Context, id string) (*SLO, error)
+
+	// CreateSLO stores a given SLO in db
+	CreateSLO(ctx context.Context, slo SLO) (int64, error)
+
+	// EditSLO updates the given SLO in the db
+	EditSLO(ctx context.Context, slo SLO, id string) error
+
+	// DeleteSLO deletes the given SLO in the db
+	DeleteSLO(ctx context.Context, id string) error
+
+	// GetAllChannelResolvedNotifications fetches the resolved notification
+	// settings of the channels from db
+	GetAllChannelResolvedNotifications(ctx context.Context) ([]ChannelResolvedNotification, error)
+
+	// SetChannelResolvedNotification stores the resolved notification
+	// settings of the channel in db, replacing the ones it had
+	SetChannelResolvedNotification(ctx context.Context, settings ChannelResolvedNotification) error
+
+	// DeleteChannelResolvedNotification deletes the resolved notification
+	// settings of the channel in db
+	DeleteChannelResolvedNotification(ctx context.Context, channel string) error
+
+	// GetAllChannelTemplates fetches the templates of the channel types from
+	// db, with the defaults of the types without one
+	GetAllChannelTemplates(ctx context.Context) ([]ChannelTemplate, error)
+
+	// ChannelTemplates fetches the templates of the channel types from db by
+	// type, with the defaults of the types without one
+	ChannelTemplates(ctx context.Context) (map[string]am.ChannelTemplate, error)
+
+	// SetChannelTemplate stores the template of the channel type in db,
+	// replacing the one it had, and updates the channels of the type
+	SetChannelTemplate(ctx context.Context, channelTemplate ChannelTemplate) error
+
+	// DeleteChannelTemplate deletes the template of the channel type in db,
+	// and updates the channels of the type
+	DeleteChannelTemplate(ctx context.Context, typ string) error
+
+	// GetAllTeams fetches the teams from db
+	GetAllTeams(ctx context.Context) ([]Team, error)
+
+	// GetTeamByID fetches the team from db by its id
+	GetTeamByID(ctx context.Context, id string) (*Team, error)
+
+	// GetTeamByName fetches the team from db by its name, nil if there is
+	// none
+	GetTeamByName(ctx context.Context, name string) (*Team, error)
+
+	// CreateTeam stores the team in db
+	CreateTeam(ctx context.Context, team Team) (int64, error)
+
+	// EditTeam changes the team in db
+	EditTeam(ctx context.Context, team Team, id string) error
+
+	// DeleteTeam deletes the team in db
+	DeleteTeam(ctx context.Context, id string) error
+
+	// GetAllAlertDigests fetches the digests of the teams from db
+	GetAllAlertDigests(ctx context.Context) ([]AlertDigest, error)
+
+	// GetAlertDigestByID fetches the digest from db by its id
+	GetAlertDigestByID(ctx context.Context, id string) (*AlertDigest, error)
+
+	// GetDueAlertDigests fetches the digests whose next run is at or before
+	// ts from db
+	GetDueAlertDigests(ctx context.Context, ts time.Time) ([]AlertDigest, error)
+
+	// CreateAlertDigest stores the digest in db
+	CreateAlertDigest(ctx context.Context, digest AlertDigest) (int64, error)
+
+	// EditAlertDigest changes the digest in db
+	EditAlertDigest(ctx context.Context, digest AlertDigest, id string) error
+
+	// DeleteAlertDigest deletes the digest in db
+	DeleteAlertDigest(ctx context.Context, id string) error
+
+	// MarkAlertDigestRun records a run of the digest in db
+	MarkAlertDigestRun(ctx context.Context, digest *AlertDigest, ts time.Time, runErr error) error
+
+	// GetNotificationRouting fetches the routing tree of the notifications
+	// from db, nil if there is none
+	GetNotificationRouting(ctx context.Context) (*NotificationRouting, error)
+
+	// SetNotificationRouting stores the routing tree of the notifications in
+	// db, replacing the one there was
+	SetNotificationRouting(ctx context.Context, routing NotificationRouting) error
+
+	// GetRulePauses fetches the pauses of the rule from db, newest first
+	GetRulePauses(ctx context.Context, ruleID string) ([]RulePause, error)
+
+	// GetActiveRulePauses fetches the pauses of the paused rules from db
+	GetActiveRulePauses(ctx context.Context) ([]RulePause, error)
+
+	// GetActiveRulePause fetches the pause of the rule from db, nil if the
+	// rule is not paused
+	GetActiveRulePause(ctx context.Context, ruleID string) (*RulePause, error)
+
+	// CreateRulePause stores a given pause of a rule in db
+	CreateRulePause(ctx context.Context, pause RulePause) (int64, error)
+
+	// ResumeRulePause records the resume of the pause in db
+	ResumeRulePause(ctx context.Context, id int64, resumedAt time.Time, resumedBy string) error
+
 	// used for internal telemetry
 	GetAlertsInfo(ctx context.Context) (*model.AlertsInfo, error)
 }
@@ -128,6 +310,8 @@ func (r *ruleDB) CreateRuleTx(ctx context.Context, rule string) (int64, Tx, erro
 		return lastInsertId, nil, err
 	}
 
+	recordRuleVersion(tx, lastInsertId, rule, userEmail, createdAt)
+
 	return lastInsertId, tx, nil
 }
 
@@ -167,6 +351,8 @@ func (r *ruleDB) EditRuleTx(ctx context.Context, rule string, id string) (string
 		// tx.Rollback() // return an error too, we may want to wrap them
 		return groupName, nil, err
 	}
+
+	recordRuleVersion(r, int64(idInt), rule, userEmail, updatedAt)
 	return groupName, nil, nil
 }
 
@@ -197,6 +383,26 @@ func (r *ruleDB) DeleteRuleTx(ctx context.Context, id string) (string, Tx, error
 		return groupName, nil, err
 	}
 
+	if _, err := r.Exec(`DELETE FROM rule_versions WHERE rule_id=$1;`, idInt); err != nil {
+		zap.L().Error("Error in deleting the versions of the rule", zap.Error(err))
+	}
+
+	if _, err := r.Exec(`DELETE FROM alert_escalations WHERE rule_id=$1;`, id); err != nil {
+		zap.L().Error("Error in deleting the escalations of the rule", zap.Error(err))
+	}
+
+	if _, err := r.Exec(`DELETE FROM log_patterns WHERE rule_id=$1;`, id); err != nil {
+		zap.L().Error("Error in deleting the log patterns of the rule", zap.Error(err))
+	}
+
+	if _, err := r.Exec(`DELETE FROM rule_pauses WHERE rule_id=$1;`, id); err != nil {
+		zap.L().Error("Error in deleting the pauses of the rule", zap.Error(err))
+	}
+
+	if _, err := r.Exec(`DELETE FROM dashboard_widget_alerts WHERE rule_id=$1;`, idInt); err != nil {
+		zap.L().Error("Error in deleting the panel of the rule", zap.Error(err))
+	}
+
 	return groupName, nil, nil
 }
 
@@ -240,7 +446,7 @@ func (r *ruleDB) GetStoredRule(ctx context.Context, id string) (*StoredRule, err
 func (r *ruleDB) GetAllPlannedMaintenance(ctx context.Context) ([]PlannedMaintenance, error) {
 	maintenances := []PlannedMaintenance{}
 
-	query := "SELECT id, name, description, schedule, alert_ids, created_at, created_by, updated_at, updated_by FROM planned_maintenance"
+	query := "SELECT id, name, description, schedule, alert_ids, matchers, created_at, created_by, updated_at, updated_by FROM planned_maintenance"
 
 	err := r.Select(&maintenances, query)
 
@@ -255,7 +461,7 @@ func (r *ruleDB) GetAllPlannedMaintenance(ctx context.Context) ([]PlannedMainten
 func (r *ruleDB) GetPlannedMaintenanceByID(ctx context.Context, id string) (*PlannedMaintenance, error) {
 	maintenance := &PlannedMaintenance{}
 
-	query := "SELECT id, name, description, schedule, alert_ids, created_at, created_by, updated_at, updated_by FROM planned_maintenance WHERE id=$1"
+	query := "SELECT id, name, description, schedule, alert_ids, matchers, created_at, created_by, updated_at, updated_by FROM planned_maintenance WHERE id=$1"
 	err := r.Get(maintenance, query, id)
 
 	if err != nil {
@@ -277,9 +483,9 @@ func (r *ruleDB) CreatePlannedMaintenance(ctx context.Context, maintenance Plann
 	maintenance.UpdatedBy = claims.Email
 	maintenance.UpdatedAt = time.Now()
 
-	query := "INSERT INTO planned_maintenance (name, description, schedule, alert_ids, created_at, created_by, updated_at, updated_by) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+	query := "INSERT INTO planned_maintenance (name, description, schedule, alert_ids, matchers, created_at, created_by, updated_at, updated_by) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
 
-	result, err := r.Exec(query, maintenance.Name, maintenance.Description, maintenance.Schedule, maintenance.AlertIds, maintenance.CreatedAt, maintenance.CreatedBy, maintenance.UpdatedAt, maintenance.UpdatedBy)
+	result, err := r.Exec(query, maintenance.Name, maintenance.Description, maintenance.Schedule, maintenance.AlertIds, maintenance.Matchers, maintenance.CreatedAt, maintenance.CreatedBy, maintenance.UpdatedAt, maintenance.UpdatedBy)
 
 	if err != nil {
 		zap.L().Error("Error in processing sql query", zap.Error(err))
@@ -298,6 +504,10 @@ func (r *ruleDB) DeletePlannedMaintenance(ctx context.Context, id string) (strin
 		return "", err
 	}
 
+	if _, err := r.Exec("DELETE FROM planned_maintenance_suppressions WHERE maintenance_id=$1", id); err != nil {
+		zap.L().Error("Error in deleting the suppressions of the maintenance", zap.Error(err))
+	}
+
 	return "", nil
 }
 
@@ -309,8 +519,8 @@ func (r *ruleDB) EditPlannedMaintenance(ctx context.Context, maintenance Planned
 	maintenance.UpdatedBy = claims.Email
 	maintenance.UpdatedAt = time.Now()
 
-	query := "UPDATE planned_maintenance SET name=$1, description=$2, schedule=$3, alert_ids=$4, updated_at=$5, updated_by=$6 WHERE id=$7"
-	_, err := r.Exec(query, maintenance.Name, maintenance.Description, maintenance.Schedule, maintenance.AlertIds, maintenance.UpdatedAt, maintenance.UpdatedBy, id)
+	query := "UPDATE planned_maintenance SET name=$1, description=$2, schedule=$3, alert_ids=$4, matchers=$5, updated_at=$6, updated_by=$7 WHERE id=$8"
+	_, err := r.Exec(query, maintenance.Name, maintenance.Description, maintenance.Schedule, maintenance.AlertIds, maintenance.Matchers, maintenance.UpdatedAt, maintenance.UpdatedBy, id)
 
 	if err != nil {
 		zap.L().Error("Error in processing sql query", zap.Error(err))
@@ -320,6 +530,36 @@ func (r *ruleDB) EditPlannedMaintenance(ctx context.Context, maintenance Planned
 	return "", nil
 }
 
+func (r *ruleDB) RecordMaintenanceSuppression(ctx context.Context, maintenanceId int64, ruleId string, suppressedAlerts int, skippedEvaluations int) error {
+	query := `INSERT INTO planned_maintenance_suppressions (maintenance_id, rule_id, suppressed_alerts, skipped_evaluations, last_suppressed_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT(maintenance_id, rule_id) DO UPDATE SET
+			suppressed_alerts = planned_maintenance_suppressions.suppressed_alerts + excluded.suppressed_alerts,
+			skipped_evaluations = planned_maintenance_suppressions.skipped_evaluations + excluded.skipped_evaluations,
+			last_suppressed_at = excluded.last_suppressed_at`
+	_, err := r.Exec(query, maintenanceId, ruleId, suppressedAlerts, skippedEvaluations, time.Now())
+
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleDB) GetMaintenanceSuppressions(ctx context.Context, id string) ([]MaintenanceSuppression, error) {
+	suppressions := []MaintenanceSuppression{}
+
+	query := "SELECT * FROM planned_maintenance_suppressions WHERE maintenance_id=$1 ORDER BY rule_id"
+	err := r.Select(&suppressions, query, id)
+
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return suppressions, nil
+}
+
 func getChannelType(receiver *am.Receiver) string {
 
 	if receiver.EmailConfigs != nil {
@@ -410,6 +650,12 @@ func (r *ruleDB) DeleteChannel(id string) *model.ApiError {
 		}
 	}
 
+	if _, err := tx.Exec(`DELETE FROM channel_rate_limits WHERE channel=$1;`, channelToDelete.Name); err != nil {
+		zap.L().Error("Error in deleting the rate limit of the channel", zap.Error(err))
+		tx.Rollback()
+		return &model.ApiError{Typ: model.ErrorInternal, Err: err}
+	}
+
 	apiError := r.alertManager.DeleteRoute(channelToDelete.Name)
 	if apiError != nil {
 		tx.Rollback()
@@ -458,6 +704,11 @@ func (r *ruleDB) EditChannel(receiver *am.Receiver, id string) (*am.Receiver, *m
 		return nil, &model.ApiError{Typ: model.ErrorBadData, Err: fmt.Errorf("channel name cannot be changed")}
 	}
 
+	templated, apiErrObj := r.templatedReceiver(context.Background(), receiver)
+	if apiErrObj != nil {
+		return nil, apiErrObj
+	}
+
 	tx, err := r.Begin()
 	if err != nil {
 		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
@@ -484,7 +735,7 @@ func (r *ruleDB) EditChannel(receiver *am.Receiver, id string) (*am.Receiver, *m
 		}
 	}
 
-	apiError := r.alertManager.EditRoute(receiver)
+	apiError := r.alertManager.EditRoute(templated)
 	if apiError != nil {
 		tx.Rollback()
 		return nil, apiError
@@ -506,6 +757,11 @@ func (r *ruleDB) CreateChannel(receiver *am.Receiver) (*am.Receiver, *model.ApiE
 
 	receiverString, _ := json.Marshal(receiver)
 
+	templated, apiErrObj := r.templatedReceiver(context.Background(), receiver)
+	if apiErrObj != nil {
+		return nil, apiErrObj
+	}
+
 	tx, err := r.Begin()
 	if err != nil {
 		return nil, &model.ApiError{Typ: model.ErrorInternal, Err: err}
@@ -527,7 +783,7 @@ func (r *ruleDB) CreateChannel(receiver *am.Receiver) (*am.Receiver, *model.ApiE
 		}
 	}
 
-	apiError := r.alertManager.AddRoute(receiver)
+	apiError := r.alertManager.AddRoute(templated)
 	if apiError != nil {
 		tx.Rollback()
 		return nil, apiError
@@ -651,6 +907,12 @@ func (r *ruleDB) GetAlertsInfo(ctx context.Context) (*model.AlertsInfo, error) {
 			if channel.Type == "msteams" {
 				alertsInfo.MSTeamsChannels = alertsInfo.MSTeamsChannels + 1
 			}
+			if channel.Type == "sns" {
+				alertsInfo.SNSChannels = alertsInfo.SNSChannels + 1
+			}
+			if channel.Type == "victorops" {
+				alertsInfo.VictorOpsChannels = alertsInfo.VictorOpsChannels + 1
+			}
 		}
 	}
 
@@ -0,0 +1,608 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	smtpservice "go.signoz.io/signoz/pkg/query-service/utils/smtpService"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+const (
+	// AlertDigestName is the alert name of the digests posted to the
+	// channels
+	AlertDigestName = "Alert digest"
+	// AlertDigestTeamLabel is the label of the digests posted to the
+	// channels with the name of the team
+	AlertDigestTeamLabel = "team"
+
+	// digestTopRules is the number of the noisiest rules listed in a digest
+	digestTopRules = 5
+	// digestHistoryLimit is the max number of the state changes of a rule
+	// read to sum up the firing time of its alerts
+	digestHistoryLimit = 10000
+)
+
+// AlertDigest emails or posts a summary of the alert activity of the rules
+// owned by a team on a cron schedule. Each digest covers the period
+// preceding the run.
+type AlertDigest struct {
+	Id     int64  `json:"id" db:"id"`
+	TeamId int64  `json:"teamId" db:"team_id"`
+	Cron   string `json:"cron" db:"cron"`
+	// Period is a duration such as 24h or 168h
+	Period     string        `json:"period" db:"period"`
+	Recipients DigestTargets `json:"recipients" db:"recipients"`
+	Channels   DigestTargets `json:"channels" db:"channels"`
+	NextRunAt  time.Time     `json:"nextRunAt" db:"next_run_at"`
+	LastRunAt  *time.Time    `json:"lastRunAt,omitempty" db:"last_run_at"`
+	LastError  string        `json:"lastError" db:"last_error"`
+	CreatedAt  time.Time     `json:"createdAt" db:"created_at"`
+	CreatedBy  string        `json:"createdBy" db:"created_by"`
+	UpdatedAt  time.Time     `json:"updatedAt" db:"updated_at"`
+	UpdatedBy  string        `json:"updatedBy" db:"updated_by"`
+}
+
+// DigestTargets are the emails or the channel names a digest is sent to
+type DigestTargets []string
+
+func (t *DigestTargets) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, t)
+	}
+	if data, ok := src.(string); ok {
+		return json.Unmarshal([]byte(data), t)
+	}
+	return nil
+}
+
+func (t DigestTargets) Value() (driver.Value, error) {
+	if t == nil {
+		t = DigestTargets{}
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (d *AlertDigest) Validate() (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(d.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", d.Cron, err)
+	}
+	if period, err := time.ParseDuration(d.Period); err != nil || period <= 0 {
+		return nil, fmt.Errorf("invalid period %q", d.Period)
+	}
+	if len(d.Recipients) == 0 && len(d.Channels) == 0 {
+		return nil, fmt.Errorf("at least one recipient or channel is required")
+	}
+	for _, recipient := range d.Recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return nil, fmt.Errorf("invalid recipient %q", recipient)
+		}
+	}
+	return schedule, nil
+}
+
+// Digest is the summary of the alert activity of the rules of a team over a
+// period
+type Digest struct {
+	Team  string    `json:"team"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// NoisyRules are the rules which fired the most in the period
+	NoisyRules []DigestRule `json:"noisyRules"`
+	// Services are the services by the time their alerts were firing in
+	// the period, the alerts without a service are under an empty one
+	Services []DigestService `json:"services"`
+	// Unacknowledged are the alerts firing without being acknowledged
+	Unacknowledged []DigestAlert `json:"unacknowledged"`
+}
+
+type DigestRule struct {
+	RuleId   string `json:"ruleId"`
+	Name     string `json:"name"`
+	Triggers uint64 `json:"triggers"`
+}
+
+type DigestService struct {
+	Service    string        `json:"service"`
+	FiringTime time.Duration `json:"firingTime"`
+}
+
+type DigestAlert struct {
+	RuleId  string            `json:"ruleId"`
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels"`
+	FiredAt time.Time         `json:"firedAt"`
+}
+
+// serviceLabels are the labels the service of an alert is read from
+var serviceLabels = []string{"service.name", "service_name", "serviceName"}
+
+// addFiringTime adds the time the alerts of the state changes were firing
+// between start and end to the firing time of their service. The changes
+// are in time order, the alerts whose first change resolves them were
+// firing since the start and the alerts still firing fire until the end.
+func addFiringTime(firingTime map[string]time.Duration, history []model.RuleStateHistory, start, end time.Time) {
+	type alertState struct {
+		service     string
+		firingSince *time.Time
+	}
+	alerts := map[uint64]*alertState{}
+
+	for _, item := range history {
+		alert, ok := alerts[item.Fingerprint]
+		if !ok {
+			lbls := map[string]string{}
+			_ = json.Unmarshal([]byte(item.Labels), &lbls)
+			alert = &alertState{}
+			for _, name := range serviceLabels {
+				if service, ok := lbls[name]; ok {
+					alert.service = service
+					break
+				}
+			}
+			if item.State != model.StateFiring && item.StateChanged {
+				alert.firingSince = &start
+			}
+			alerts[item.Fingerprint] = alert
+		}
+
+		ts := time.UnixMilli(item.UnixMilli)
+		if item.State == model.StateFiring {
+			if alert.firingSince == nil {
+				alert.firingSince = &ts
+			}
+			continue
+		}
+		if alert.firingSince != nil {
+			firingTime[alert.service] += ts.Sub(*alert.firingSince)
+			alert.firingSince = nil
+		}
+	}
+
+	for _, alert := range alerts {
+		if alert.firingSince != nil {
+			firingTime[alert.service] += end.Sub(*alert.firingSince)
+		}
+	}
+}
+
+// BuildDigest sums up the alert activity of the rules of the team of the
+// digest over the period ending at ts
+func (m *Manager) BuildDigest(ctx context.Context, digest *AlertDigest, ts time.Time) (*Digest, error) {
+	team, err := m.ruleDB.GetTeamByID(ctx, strconv.FormatInt(digest.TeamId, 10))
+	if err != nil {
+		return nil, err
+	}
+	period, err := time.ParseDuration(digest.Period)
+	if err != nil {
+		return nil, err
+	}
+	start, end := ts.Add(-period), ts
+
+	storedRules, err := m.ruleDB.GetStoredRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Digest{
+		Team:           team.Name,
+		Start:          start,
+		End:            end,
+		NoisyRules:     []DigestRule{},
+		Services:       []DigestService{},
+		Unacknowledged: []DigestAlert{},
+	}
+	firingTime := map[string]time.Duration{}
+	for _, storedRule := range storedRules {
+		rule := PostableRule{}
+		if err := json.Unmarshal([]byte(storedRule.Data), &rule); err != nil || rule.Team != team.Name {
+			continue
+		}
+		ruleId := strconv.Itoa(storedRule.Id)
+
+		if m.reader != nil {
+			params := &model.QueryRuleStateHistory{
+				Start: start.UnixMilli(),
+				End:   end.UnixMilli(),
+				Order: "asc",
+				Limit: digestHistoryLimit,
+			}
+			triggers, err := m.reader.GetTotalTriggers(ctx, ruleId, params)
+			if err != nil {
+				return nil, err
+			}
+			if triggers > 0 {
+				d.NoisyRules = append(d.NoisyRules, DigestRule{RuleId: ruleId, Name: rule.AlertName, Triggers: triggers})
+			}
+			timeline, err := m.reader.ReadRuleStateHistoryByRuleID(ctx, ruleId, params)
+			if err != nil {
+				return nil, err
+			}
+			addFiringTime(firingTime, timeline.Items, start, end)
+		}
+
+		unacknowledged, err := m.unacknowledgedAlerts(ctx, ruleId)
+		if err != nil {
+			return nil, err
+		}
+		d.Unacknowledged = append(d.Unacknowledged, unacknowledged...)
+	}
+
+	sort.SliceStable(d.NoisyRules, func(i, j int) bool {
+		return d.NoisyRules[i].Triggers > d.NoisyRules[j].Triggers
+	})
+	if len(d.NoisyRules) > digestTopRules {
+		d.NoisyRules = d.NoisyRules[:digestTopRules]
+	}
+	for service, duration := range firingTime {
+		d.Services = append(d.Services, DigestService{Service: service, FiringTime: duration})
+	}
+	sort.Slice(d.Services, func(i, j int) bool {
+		if d.Services[i].FiringTime != d.Services[j].FiringTime {
+			return d.Services[i].FiringTime > d.Services[j].FiringTime
+		}
+		return d.Services[i].Service < d.Services[j].Service
+	})
+	sort.SliceStable(d.Unacknowledged, func(i, j int) bool {
+		return d.Unacknowledged[i].FiredAt.Before(d.Unacknowledged[j].FiredAt)
+	})
+
+	return d, nil
+}
+
+// unacknowledgedAlerts returns the firing alerts of the rule which are not
+// acknowledged
+func (m *Manager) unacknowledgedAlerts(ctx context.Context, ruleId string) ([]DigestAlert, error) {
+	m.mtx.RLock()
+	rule, ok := m.rules[ruleId]
+	m.mtx.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	activeAlerts, ok := rule.(interface{ ForEachActiveAlert(func(*Alert)) })
+	if !ok {
+		return nil, nil
+	}
+
+	escalations, err := m.ruleDB.GetAlertEscalations(ctx, ruleId)
+	if err != nil {
+		return nil, err
+	}
+	states := escalationStates(escalations)
+
+	alerts := []DigestAlert{}
+	activeAlerts.ForEachActiveAlert(func(a *Alert) {
+		if a.State != model.StateFiring {
+			return
+		}
+		if states[escalationKey{fingerprint: a.DedupKey(), firedAt: a.FiredAt.Unix()}].acknowledged {
+			return
+		}
+		alerts = append(alerts, DigestAlert{RuleId: ruleId, Name: rule.Name(), Labels: a.Labels.Map(), FiredAt: a.FiredAt})
+	})
+	return alerts, nil
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"labels": formatDigestLabels,
+}).Parse(`<html>
+<body style="font-family: sans-serif">
+<h2>Alert digest of {{ .Team }}</h2>
+<p>{{ .Start.Format "2006-01-02 15:04 MST" }} - {{ .End.Format "2006-01-02 15:04 MST" }}</p>
+<h3>Noisiest rules</h3>
+{{ if not .NoisyRules }}<p>No rule fired</p>
+{{ else }}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Rule</th><th>Times fired</th></tr>
+{{ range .NoisyRules }}<tr><td>{{ .Name }}</td><td>{{ .Triggers }}</td></tr>
+{{ end }}</table>
+{{ end }}<h3>Firing time by service</h3>
+{{ if not .Services }}<p>No alert fired</p>
+{{ else }}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Service</th><th>Firing time</th></tr>
+{{ range .Services }}<tr><td>{{ if .Service }}{{ .Service }}{{ else }}(no service){{ end }}</td><td>{{ .FiringTime }}</td></tr>
+{{ end }}</table>
+{{ end }}<h3>Unacknowledged alerts</h3>
+{{ if not .Unacknowledged }}<p>All the firing alerts are acknowledged</p>
+{{ else }}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Rule</th><th>Labels</th><th>Firing since</th></tr>
+{{ range .Unacknowledged }}<tr><td>{{ .Name }}</td><td>{{ labels .Labels }}</td><td>{{ .FiredAt.Format "2006-01-02 15:04 MST" }}</td></tr>
+{{ end }}</table>
+{{ end }}</body>
+</html>`))
+
+// formatDigestLabels lists the labels of an alert of the digest, leaving
+// out the alert name
+func formatDigestLabels(lbls map[string]string) string {
+	pairs := []string{}
+	for name, value := range lbls {
+		if name == labels.AlertNameLabel || name == labels.AlertRuleIdLabel {
+			continue
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// renderDigest renders the digest as an html email
+func renderDigest(d *Digest) (string, string, error) {
+	var body bytes.Buffer
+	if err := digestTemplate.Execute(&body, d); err != nil {
+		return "", "", err
+	}
+	subject := fmt.Sprintf("Alert digest of %s: %d unacknowledged alerts", d.Team, len(d.Unacknowledged))
+	return subject, body.String(), nil
+}
+
+// digestAlert is the alert posting the digest to the channels, it resolves
+// on its own a few minutes later
+func digestAlert(d *Digest, channels []string, ts time.Time) *Alert {
+	lines := []string{"Noisiest rules:"}
+	for _, rule := range d.NoisyRules {
+		lines = append(lines, fmt.Sprintf("- %s fired %d times", rule.Name, rule.Triggers))
+	}
+	lines = append(lines, "Firing time by service:")
+	for _, service := range d.Services {
+		name := service.Service
+		if name == "" {
+			name = "(no service)"
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %s", name, service.FiringTime))
+	}
+	lines = append(lines, "Unacknowledged alerts:")
+	for _, alert := range d.Unacknowledged {
+		lines = append(lines, fmt.Sprintf("- %s {%s} firing since %s", alert.Name, formatDigestLabels(alert.Labels), alert.FiredAt.Format(time.RFC3339)))
+	}
+
+	return &Alert{
+		State: model.StateFiring,
+		Labels: labels.FromMap(map[string]string{
+			labels.AlertNameLabel:     AlertDigestName,
+			labels.AlertSeverityLabel: "info",
+			AlertDigestTeamLabel:      d.Team,
+		}),
+		Annotations: labels.FromMap(map[string]string{
+			labels.AlertSummaryLabel: fmt.Sprintf("Alert digest of %s from %s to %s: %d unacknowledged alerts", d.Team, d.Start.Format(time.RFC3339), d.End.Format(time.RFC3339), len(d.Unacknowledged)),
+			"description":            strings.Join(lines, "\n"),
+		}),
+		FiredAt:    ts,
+		LastSentAt: ts,
+		ValidUntil: ts.Add(5 * time.Minute),
+		Receivers:  channels,
+	}
+}
+
+// sendDigest builds the digest of the period ending at ts, emails it to the
+// recipients and posts it to the channels
+func (m *Manager) sendDigest(ctx context.Context, digest *AlertDigest, ts time.Time) error {
+	d, err := m.BuildDigest(ctx, digest, ts)
+	if err != nil {
+		return err
+	}
+
+	if len(digest.Recipients) > 0 {
+		subject, body, err := renderDigest(d)
+		if err != nil {
+			return err
+		}
+		if err := smtpservice.GetInstance().SendEmail(strings.Join(digest.Recipients, ","), subject, body); err != nil {
+			return err
+		}
+	}
+	if len(digest.Channels) > 0 {
+		m.notifier.Send(m.notifierAlert(digestAlert(d, digest.Channels, ts), digest.Channels))
+	}
+	return nil
+}
+
+// sendDueDigests sends the digests whose next run is due
+func (m *Manager) sendDueDigests() {
+	tick := time.NewTicker(time.Minute)
+	defer tick.Stop()
+
+	for now := range tick.C {
+		ctx := context.Background()
+		digests, err := m.ruleDB.GetDueAlertDigests(ctx, now)
+		if err != nil {
+			zap.L().Error("failed to get the due alert digests", zap.Error(err))
+			continue
+		}
+		for i := range digests {
+			digest := &digests[i]
+			err := m.sendDigest(ctx, digest, now)
+			if err != nil {
+				zap.L().Error("failed to send the alert digest", zap.Int64("digest", digest.Id), zap.Error(err))
+			}
+			if err := m.ruleDB.MarkAlertDigestRun(ctx, digest, now, err); err != nil {
+				zap.L().Error("failed to update the alert digest", zap.Int64("digest", digest.Id), zap.Error(err))
+			}
+		}
+	}
+}
+
+// checkDigestTeam checks the user can change the digests of the team, which
+// is left to the members of the team and the admins
+func (m *Manager) checkDigestTeam(ctx context.Context, teamId int64) error {
+	team, err := m.ruleDB.GetTeamByID(ctx, strconv.FormatInt(teamId, 10))
+	if err != nil {
+		return fmt.Errorf("team %d not found", teamId)
+	}
+	user := common.GetUserFromContext(ctx)
+	if user == nil || user.Role == constants.AdminGroup || team.HasMember(user.Email) {
+		return nil
+	}
+	return model.ForbiddenError(fmt.Errorf("only the members of the team %s or an admin can change its digests", team.Name))
+}
+
+// CreateAlertDigest stores the digest of a team
+func (m *Manager) CreateAlertDigest(ctx context.Context, digest AlertDigest) (int64, error) {
+	if err := m.checkDigestTeam(ctx, digest.TeamId); err != nil {
+		return 0, err
+	}
+	return m.ruleDB.CreateAlertDigest(ctx, digest)
+}
+
+// EditAlertDigest changes the digest, which is kept for its team
+func (m *Manager) EditAlertDigest(ctx context.Context, digest AlertDigest, id string) error {
+	stored, err := m.ruleDB.GetAlertDigestByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stored.TeamId != digest.TeamId {
+		return fmt.Errorf("the team of a digest cannot be changed")
+	}
+	if err := m.checkDigestTeam(ctx, stored.TeamId); err != nil {
+		return err
+	}
+	return m.ruleDB.EditAlertDigest(ctx, digest, id)
+}
+
+// DeleteAlertDigest deletes the digest
+func (m *Manager) DeleteAlertDigest(ctx context.Context, id string) error {
+	stored, err := m.ruleDB.GetAlertDigestByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := m.checkDigestTeam(ctx, stored.TeamId); err != nil {
+		return err
+	}
+	return m.ruleDB.DeleteAlertDigest(ctx, id)
+}
+
+func (r *ruleDB) GetAllAlertDigests(ctx context.Context) ([]AlertDigest, error) {
+	digests := []AlertDigest{}
+
+	err := r.Select(&digests, "SELECT * FROM alert_digests ORDER BY team_id, id")
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return digests, nil
+}
+
+func (r *ruleDB) GetAlertDigestByID(ctx context.Context, id string) (*AlertDigest, error) {
+	digest := &AlertDigest{}
+
+	err := r.Get(digest, "SELECT * FROM alert_digests WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return digest, nil
+}
+
+func (r *ruleDB) GetDueAlertDigests(ctx context.Context, ts time.Time) ([]AlertDigest, error) {
+	digests := []AlertDigest{}
+
+	err := r.Select(&digests, "SELECT * FROM alert_digests WHERE next_run_at <= $1 ORDER BY next_run_at", ts)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return digests, nil
+}
+
+func (r *ruleDB) CreateAlertDigest(ctx context.Context, digest AlertDigest) (int64, error) {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return 0, errors.New("no claims found in context")
+	}
+	schedule, err := digest.Validate()
+	if err != nil {
+		return 0, err
+	}
+	digest.CreatedBy = claims.Email
+	digest.CreatedAt = time.Now()
+	digest.UpdatedBy = claims.Email
+	digest.UpdatedAt = time.Now()
+	digest.NextRunAt = schedule.Next(digest.CreatedAt)
+
+	query := `INSERT INTO alert_digests (team_id, cron, period, recipients, channels, next_run_at, last_error, created_at, created_by, updated_at, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, '', $7, $8, $9, $10)`
+	result, err := r.Exec(query, digest.TeamId, digest.Cron, digest.Period, digest.Recipients, digest.Channels, digest.NextRunAt, digest.CreatedAt, digest.CreatedBy, digest.UpdatedAt, digest.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *ruleDB) EditAlertDigest(ctx context.Context, digest AlertDigest, id string) error {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("no claims found in context")
+	}
+	schedule, err := digest.Validate()
+	if err != nil {
+		return err
+	}
+	digest.UpdatedBy = claims.Email
+	digest.UpdatedAt = time.Now()
+	digest.NextRunAt = schedule.Next(digest.UpdatedAt)
+
+	query := "UPDATE alert_digests SET cron=$1, period=$2, recipients=$3, channels=$4, next_run_at=$5, updated_at=$6, updated_by=$7 WHERE id=$8"
+	_, err = r.Exec(query, digest.Cron, digest.Period, digest.Recipients, digest.Channels, digest.NextRunAt, digest.UpdatedAt, digest.UpdatedBy, id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleDB) DeleteAlertDigest(ctx context.Context, id string) error {
+	_, err := r.Exec("DELETE FROM alert_digests WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// MarkAlertDigestRun records the outcome of a run of the digest and moves
+// it to its next run after ts
+func (r *ruleDB) MarkAlertDigestRun(ctx context.Context, digest *AlertDigest, ts time.Time, runErr error) error {
+	schedule, err := cron.ParseStandard(digest.Cron)
+	if err != nil {
+		return err
+	}
+
+	lastError := ""
+	if runErr != nil {
+		lastError = runErr.Error()
+	}
+
+	_, err = r.Exec("UPDATE alert_digests SET last_run_at=$1, last_error=$2, next_run_at=$3 WHERE id=$4", ts, lastError, schedule.Next(ts), digest.Id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
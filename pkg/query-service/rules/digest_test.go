@@ -0,0 +1,168 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func TestAddFiringTime(t *testing.T) {
+	start := time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	at := func(h int) int64 { return start.Add(time.Duration(h) * time.Hour).UnixMilli() }
+
+	history := []model.RuleStateHistory{
+		// firing from 1h to 3h and from 20h until the end
+		{Fingerprint: 1, Labels: `{"service.name":"checkout"}`, State: model.StateFiring, StateChanged: true, UnixMilli: at(1)},
+		{Fingerprint: 1, Labels: `{"service.name":"checkout"}`, State: model.StateInactive, StateChanged: true, UnixMilli: at(3)},
+		{Fingerprint: 1, Labels: `{"service.name":"checkout"}`, State: model.StateFiring, StateChanged: true, UnixMilli: at(20)},
+		// firing since before the start until 2h
+		{Fingerprint: 2, Labels: `{"serviceName":"cart"}`, State: model.StateInactive, StateChanged: true, UnixMilli: at(2)},
+		{Fingerprint: 3, Labels: `{}`, State: model.StateFiring, StateChanged: true, UnixMilli: at(12)},
+		{Fingerprint: 3, Labels: `{}`, State: model.StateInactive, StateChanged: true, UnixMilli: at(13)},
+	}
+	firingTime := map[string]time.Duration{}
+	addFiringTime(firingTime, history, start, end)
+	assert.Equal(t, map[string]time.Duration{
+		"checkout": 6 * time.Hour,
+		"cart":     2 * time.Hour,
+		"":         time.Hour,
+	}, firingTime)
+}
+
+func TestAlertDigests(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	m := &Manager{opts: &ManagerOptions{DisableRules: true}, tasks: map[string]Task{}, rules: map[string]Rule{}, ruleDB: ruleDB}
+
+	userCtx := func(email, role string) context.Context {
+		ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: email})
+		return context.WithValue(ctx, constants.ContextUserKey, &model.UserPayload{
+			User: model.User{Id: email, Email: email},
+			Role: role,
+		})
+	}
+	aliceCtx := userCtx("alice@signoz.io", "EDITOR")
+	bobCtx := userCtx("bob@signoz.io", "EDITOR")
+	adminCtx := userCtx("admin@signoz.io", constants.AdminGroup)
+
+	teamID, err := ruleDB.CreateTeam(adminCtx, Team{Name: "platform", Members: TeamMembers{"alice@signoz.io"}})
+	require.NoError(err)
+
+	digest := AlertDigest{TeamId: teamID, Cron: "0 9 * * 1", Period: "168h", Recipients: DigestTargets{"oncall@signoz.io"}}
+	_, err = digest.Validate()
+	require.NoError(err)
+	_, err = (&AlertDigest{TeamId: teamID, Cron: "0 9 * * 1", Period: "168h"}).Validate()
+	require.Error(err)
+	_, err = (&AlertDigest{TeamId: teamID, Cron: "weekly", Period: "168h", Channels: DigestTargets{"slack"}}).Validate()
+	require.Error(err)
+
+	// only the members of the team and the admins manage its digests
+	_, err = m.CreateAlertDigest(bobCtx, digest)
+	require.Error(err)
+	digestID, err := m.CreateAlertDigest(aliceCtx, digest)
+	require.NoError(err)
+	id := fmt.Sprintf("%d", digestID)
+	require.Error(m.DeleteAlertDigest(bobCtx, id))
+
+	digest.Channels = DigestTargets{"slack"}
+	require.NoError(m.EditAlertDigest(adminCtx, digest, id))
+	stored, err := ruleDB.GetAlertDigestByID(aliceCtx, id)
+	require.NoError(err)
+	require.Equal(DigestTargets{"slack"}, stored.Channels)
+	require.Equal(time.Monday, stored.NextRunAt.Weekday())
+
+	due, err := ruleDB.GetDueAlertDigests(aliceCtx, time.Now())
+	require.NoError(err)
+	require.Empty(due)
+	due, err = ruleDB.GetDueAlertDigests(aliceCtx, stored.NextRunAt)
+	require.NoError(err)
+	require.Len(due, 1)
+	require.NoError(ruleDB.MarkAlertDigestRun(aliceCtx, stored, stored.NextRunAt, fmt.Errorf("smtp is not configured")))
+	stored, err = ruleDB.GetAlertDigestByID(aliceCtx, id)
+	require.NoError(err)
+	require.Equal("smtp is not configured", stored.LastError)
+	require.NotNil(stored.LastRunAt)
+	require.Equal(time.Duration(7*24*time.Hour), stored.NextRunAt.Sub(*stored.LastRunAt))
+
+	// the digest lists the unacknowledged firing alerts of the rules of the
+	// team
+	ruleStr := `{"alert": "kafka lag", "ruleType": "promql_rule", "team": "platform", "condition": {"compositeQuery": {"queryType": "promql", "panelType": "graph", "promQueries": {"A": {"query": "kafka_lag"}}}, "op": "1", "target": 100, "matchType": "1"}}`
+	rule, err := m.CreateRule(aliceCtx, ruleStr)
+	require.NoError(err)
+	target := float64(100)
+	thresholdRule, err := NewThresholdRule(rule.Id, &PostableRule{
+		AlertName:  "kafka lag",
+		AlertType:  AlertTypeMetric,
+		RuleType:   RuleTypeThreshold,
+		EvalWindow: Duration(5 * time.Minute),
+		Frequency:  Duration(time.Minute),
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {QueryName: "A", StepInterval: 60, AggregateAttribute: v3.AttributeKey{Key: "kafka_lag"}, AggregateOperator: v3.AggregateOperatorMax, DataSource: v3.DataSourceMetrics, Expression: "A"},
+				},
+			},
+			CompareOp: ValueIsAbove,
+			MatchType: AtleastOnce,
+			Target:    &target,
+		},
+	}, nil, nil, true, true, WithoutStateHistory())
+	require.NoError(err)
+	firedAt := time.Now().Add(-time.Hour)
+	alert := &Alert{
+		State:   model.StateFiring,
+		Labels:  labels.FromMap(map[string]string{labels.AlertNameLabel: "kafka lag", "topic": "orders"}),
+		FiredAt: firedAt,
+	}
+	thresholdRule.Active = map[uint64]*Alert{alert.Labels.Hash(): alert}
+	m.rules[rule.Id] = thresholdRule
+
+	summary, err := m.BuildDigest(aliceCtx, stored, time.Now())
+	require.NoError(err)
+	require.Equal("platform", summary.Team)
+	require.Len(summary.Unacknowledged, 1)
+	require.Equal("orders", summary.Unacknowledged[0].Labels["topic"])
+
+	subject, body, err := renderDigest(summary)
+	require.NoError(err)
+	assert.Equal(t, "Alert digest of platform: 1 unacknowledged alerts", subject)
+	assert.Contains(t, body, "topic=orders")
+	posted := digestAlert(summary, stored.Channels, time.Now())
+	assert.Equal(t, []string{"slack"}, posted.Receivers)
+	assert.Contains(t, posted.Annotations.Get("description"), "- kafka lag {topic=orders}")
+
+	alertLabels := EscalationLabels(alert.Labels.Map())
+	require.NoError(ruleDB.RecordAlertEscalation(aliceCtx, AlertEscalation{
+		RuleId:      rule.Id,
+		Fingerprint: alert.DedupKey(),
+		Labels:      &alertLabels,
+		FiredAt:     firedAt,
+		Kind:        AlertEscalationKindAcknowledged,
+		CreatedBy:   "alice@signoz.io",
+		CreatedAt:   time.Now(),
+	}))
+	summary, err = m.BuildDigest(aliceCtx, stored, time.Now())
+	require.NoError(err)
+	require.Empty(summary.Unacknowledged)
+
+	// the digests of a team are deleted with it
+	delete(m.rules, rule.Id)
+	require.NoError(m.DeleteRule(aliceCtx, rule.Id))
+	require.NoError(m.DeleteTeam(adminCtx, fmt.Sprintf("%d", teamID)))
+	digests, err := ruleDB.GetAllAlertDigests(adminCtx)
+	require.NoError(err)
+	require.Empty(digests)
+}
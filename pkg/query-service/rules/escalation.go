@@ -0,0 +1,469 @@
+package rules
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	am "go.signoz.io/signoz/pkg/query-service/integrations/alertManager"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	qslabels "go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+const (
+	AlertEscalationKindEscalated    = "escalated"
+	AlertEscalationKindAcknowledged = "acknowledged"
+	// AlertEscalationKindUnacknowledged takes back the acknowledgement of
+	// the alert, which escalates again
+	AlertEscalationKindUnacknowledged = "unacknowledged"
+
+	// EscalationStepLabel is set on the alerts sent to the channels of a step
+	// of the escalation, so they are kept apart from the alert of the rule
+	EscalationStepLabel = "escalation_step"
+)
+
+var ErrMissingSteps = errors.New("missing steps")
+
+// EscalationPolicy is a chain of channels notified in turn for as long as
+// the alerts of the rules assigned to it are firing and unacknowledged
+type EscalationPolicy struct {
+	Id          int64            `json:"id" db:"id"`
+	Name        string           `json:"name" db:"name"`
+	Description string           `json:"description" db:"description"`
+	Steps       *EscalationSteps `json:"steps" db:"steps"`
+	CreatedAt   time.Time        `json:"createdAt" db:"created_at"`
+	CreatedBy   string           `json:"createdBy" db:"created_by"`
+	UpdatedAt   time.Time        `json:"updatedAt" db:"updated_at"`
+	UpdatedBy   string           `json:"updatedBy" db:"updated_by"`
+}
+
+// EscalationStep notifies its channels once an alert has been firing for
+// the delay without being acknowledged
+type EscalationStep struct {
+	Delay    Duration `json:"delay"`
+	Channels []string `json:"channels"`
+}
+
+type EscalationSteps []EscalationStep
+
+func (s *EscalationSteps) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, s)
+	}
+	return nil
+}
+
+func (s *EscalationSteps) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (p *EscalationPolicy) Validate() error {
+	if p.Name == "" {
+		return ErrMissingName
+	}
+	if p.Steps == nil || len(*p.Steps) == 0 {
+		return ErrMissingSteps
+	}
+
+	var previous Duration
+	for i, step := range *p.Steps {
+		if step.Delay <= previous {
+			return fmt.Errorf("the delay of step %d must be greater than the delay of the step before it", i+1)
+		}
+		if len(step.Channels) == 0 {
+			return fmt.Errorf("step %d has no channels", i+1)
+		}
+		previous = step.Delay
+	}
+	return nil
+}
+
+// AlertEscalation is an escalation or an acknowledgement of an alert, the
+// alert being the firing at FiredAt of the alert instance with the
+// fingerprint, its dedup key
+type AlertEscalation struct {
+	Id          int64              `json:"id" db:"id"`
+	RuleId      string             `json:"ruleId" db:"rule_id"`
+	Fingerprint string             `json:"fingerprint" db:"fingerprint"`
+	Labels      *EscalationLabels  `json:"labels" db:"labels"`
+	FiredAt     time.Time          `json:"firedAt" db:"fired_at"`
+	Kind        string             `json:"kind" db:"kind"`
+	Step        int                `json:"step,omitempty" db:"step"`
+	Channels    *EscalationTargets `json:"channels,omitempty" db:"channels"`
+	CreatedBy   string             `json:"createdBy" db:"created_by"`
+	CreatedAt   time.Time          `json:"createdAt" db:"created_at"`
+
+	// Note is the note of the acknowledgement
+	Note string `json:"note,omitempty" db:"note"`
+	// SilenceId is the alertmanager silence muting the notifications of the
+	// acknowledged alert
+	SilenceId string `json:"silenceId,omitempty" db:"silence_id"`
+}
+
+type EscalationLabels map[string]string
+
+func (l *EscalationLabels) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, l)
+	}
+	return nil
+}
+
+func (l *EscalationLabels) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+type EscalationTargets []string
+
+func (t *EscalationTargets) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, t)
+	}
+	return nil
+}
+
+func (t *EscalationTargets) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+// escalationKey identifies a firing of an alert, the alert escalates again
+// from the first step when it fires after resolving
+type escalationKey struct {
+	fingerprint string
+	firedAt     int64
+}
+
+type escalationState struct {
+	step         int
+	acknowledged bool
+	// acknowledgement is the acknowledgement of the acknowledged alert
+	acknowledgement *AlertEscalation
+	// acknowledgementSeen tells the last acknowledgement or its take back
+	// was seen, the earlier ones are outdated
+	acknowledgementSeen bool
+}
+
+// escalationStates returns the states of the alerts from their escalations
+// and acknowledgements, newest first
+func escalationStates(escalations []AlertEscalation) map[escalationKey]escalationState {
+	states := map[escalationKey]escalationState{}
+	for i := range escalations {
+		e := &escalations[i]
+		key := escalationKey{fingerprint: e.Fingerprint, firedAt: e.FiredAt.Unix()}
+		state := states[key]
+		switch e.Kind {
+		case AlertEscalationKindAcknowledged, AlertEscalationKindUnacknowledged:
+			if state.acknowledgementSeen {
+				break
+			}
+			state.acknowledgementSeen = true
+			if e.Kind == AlertEscalationKindAcknowledged {
+				state.acknowledged = true
+				state.acknowledgement = e
+			}
+		case AlertEscalationKindEscalated:
+			if e.Step > state.step {
+				state.step = e.Step
+			}
+		}
+		states[key] = state
+	}
+	return states
+}
+
+// escalateAlerts notifies the steps of the escalation policy of the rule the
+// unacknowledged alerts have been firing long enough for. It runs after the
+// alerts of the rule are sent, and sends the escalated alerts along with
+// them, so they stay active and resolve with the alerts of the rule.
+func escalateAlerts(ctx context.Context, ruleDB RuleDB, rule Rule, ts time.Time, notify NotifyFunc) {
+	escalatingRule, ok := rule.(interface {
+		EscalationPolicyId() int64
+		ForEachActiveAlert(func(*Alert))
+	})
+	if !ok || escalatingRule.EscalationPolicyId() == 0 {
+		return
+	}
+
+	policy, err := ruleDB.GetEscalationPolicyByID(ctx, strconv.FormatInt(escalatingRule.EscalationPolicyId(), 10))
+	if err != nil {
+		zap.L().Error("failed to get the escalation policy of the rule", zap.String("rule", rule.ID()), zap.Error(err))
+		return
+	}
+	escalations, err := ruleDB.GetAlertEscalations(ctx, rule.ID())
+	if err != nil {
+		zap.L().Error("failed to get the escalations of the rule", zap.String("rule", rule.ID()), zap.Error(err))
+		return
+	}
+	states := escalationStates(escalations)
+	steps := *policy.Steps
+
+	escalated := []*Alert{}
+	recorded := []AlertEscalation{}
+	escalatingRule.ForEachActiveAlert(func(a *Alert) {
+		if a.FiredAt.IsZero() {
+			return
+		}
+		state := states[escalationKey{fingerprint: a.DedupKey(), firedAt: a.FiredAt.Unix()}]
+		if state.acknowledged {
+			return
+		}
+
+		step := state.step
+		if a.State == model.StateFiring {
+			for step < len(steps) && ts.Sub(a.FiredAt) >= time.Duration(steps[step].Delay) {
+				step++
+			}
+		}
+		if step == 0 || step > len(steps) {
+			return
+		}
+		if step == state.step && !a.LastSentAt.Equal(ts) {
+			return
+		}
+
+		alertLabels := a.Labels.Map()
+		alertLabels[EscalationStepLabel] = strconv.Itoa(step)
+		alert := *a
+		alert.Labels = qslabels.FromMap(alertLabels)
+		alert.Receivers = steps[step-1].Channels
+		escalated = append(escalated, &alert)
+
+		if step > state.step {
+			labels := EscalationLabels(a.Labels.Map())
+			channels := EscalationTargets(steps[step-1].Channels)
+			recorded = append(recorded, AlertEscalation{
+				RuleId:      rule.ID(),
+				Fingerprint: a.DedupKey(),
+				Labels:      &labels,
+				FiredAt:     a.FiredAt,
+				Kind:        AlertEscalationKindEscalated,
+				Step:        step,
+				Channels:    &channels,
+				CreatedBy:   "system",
+				CreatedAt:   ts,
+			})
+		}
+	})
+
+	for _, e := range recorded {
+		zap.L().Info("escalating alert", zap.String("rule", rule.ID()), zap.String("fingerprint", e.Fingerprint), zap.Int("step", e.Step))
+		if err := ruleDB.RecordAlertEscalation(ctx, e); err != nil {
+			zap.L().Error("failed to record the escalation of the alert", zap.String("rule", rule.ID()), zap.Error(err))
+		}
+	}
+	if len(escalated) > 0 {
+		notify(ctx, "", escalated...)
+	}
+}
+
+// AcknowledgeAlerts stops the escalation of the firing alerts of the rule
+// with all of the labels given, or of all of its firing alerts when there
+// are none, and mutes their notifications until they resolve or the
+// acknowledgement is taken back. It returns the acknowledgements recorded.
+func (m *Manager) AcknowledgeAlerts(ctx context.Context, ruleId string, labels map[string]string, note string) ([]AlertEscalation, error) {
+	m.mtx.RLock()
+	rule, ok := m.rules[ruleId]
+	m.mtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("rule %s is not running", ruleId)
+	}
+	activeAlerts, ok := rule.(interface{ ForEachActiveAlert(func(*Alert)) })
+	if !ok {
+		return nil, fmt.Errorf("unsupported rule %s", rule.Type())
+	}
+
+	escalations, err := m.ruleDB.GetAlertEscalations(ctx, ruleId)
+	if err != nil {
+		return nil, err
+	}
+	states := escalationStates(escalations)
+
+	acknowledgedBy := "unknown"
+	if claims, ok := authtypes.ClaimsFromContext(ctx); ok {
+		acknowledgedBy = claims.Email
+	}
+
+	now := time.Now()
+	acknowledged := []AlertEscalation{}
+	activeAlerts.ForEachActiveAlert(func(a *Alert) {
+		if a.State != model.StateFiring {
+			return
+		}
+		for name, value := range labels {
+			if a.Labels.Get(name) != value {
+				return
+			}
+		}
+		if states[escalationKey{fingerprint: a.DedupKey(), firedAt: a.FiredAt.Unix()}].acknowledged {
+			return
+		}
+
+		alertLabels := EscalationLabels(a.Labels.Map())
+		acknowledged = append(acknowledged, AlertEscalation{
+			RuleId:      ruleId,
+			Fingerprint: a.DedupKey(),
+			Labels:      &alertLabels,
+			FiredAt:     a.FiredAt,
+			Kind:        AlertEscalationKindAcknowledged,
+			CreatedBy:   acknowledgedBy,
+			CreatedAt:   now,
+			Note:        note,
+		})
+	})
+
+	for i := range acknowledged {
+		e := &acknowledged[i]
+		comment := fmt.Sprintf("acknowledged by %s", acknowledgedBy)
+		if note != "" {
+			comment += ": " + note
+		}
+		silenceId, err := m.ruleDB.CreateAlertSilence(am.NewSilence(*e.Labels, now, now.Add(acknowledgementSilence), acknowledgedBy, comment))
+		if err != nil {
+			// the alert is still acknowledged, it is only notified again
+			zap.L().Error("failed to silence the acknowledged alert", zap.String("rule", ruleId), zap.String("fingerprint", e.Fingerprint), zap.Error(err))
+		}
+		e.SilenceId = silenceId
+		if err := m.ruleDB.RecordAlertEscalation(ctx, *e); err != nil {
+			return nil, err
+		}
+	}
+	return acknowledged, nil
+}
+
+// DeleteEscalationPolicy deletes the policy unless rules are assigned to it
+func (m *Manager) DeleteEscalationPolicy(ctx context.Context, id string) error {
+	policyId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid id parameter")
+	}
+
+	storedRules, err := m.ruleDB.GetStoredRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, storedRule := range storedRules {
+		rule := PostableRule{}
+		if err := json.Unmarshal([]byte(storedRule.Data), &rule); err != nil {
+			continue
+		}
+		if rule.EscalationPolicyId == policyId {
+			return fmt.Errorf("the escalation policy is assigned to the rule %s", rule.AlertName)
+		}
+	}
+
+	return m.ruleDB.DeleteEscalationPolicy(ctx, id)
+}
+
+// checkEscalationPolicy checks the escalation policy assigned to the rule
+// exists
+func (m *Manager) checkEscalationPolicy(ctx context.Context, rule *PostableRule) error {
+	if rule.EscalationPolicyId == 0 {
+		return nil
+	}
+	if _, err := m.ruleDB.GetEscalationPolicyByID(ctx, strconv.FormatInt(rule.EscalationPolicyId, 10)); err != nil {
+		return fmt.Errorf("escalation policy %d not found", rule.EscalationPolicyId)
+	}
+	return nil
+}
+
+func (r *ruleDB) GetAllEscalationPolicies(ctx context.Context) ([]EscalationPolicy, error) {
+	policies := []EscalationPolicy{}
+
+	err := r.Select(&policies, "SELECT * FROM escalation_policies ORDER BY name")
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (r *ruleDB) GetEscalationPolicyByID(ctx context.Context, id string) (*EscalationPolicy, error) {
+	policy := &EscalationPolicy{}
+
+	err := r.Get(policy, "SELECT * FROM escalation_policies WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (r *ruleDB) CreateEscalationPolicy(ctx context.Context, policy EscalationPolicy) (int64, error) {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return 0, errors.New("no claims found in context")
+	}
+	policy.CreatedBy = claims.Email
+	policy.CreatedAt = time.Now()
+	policy.UpdatedBy = claims.Email
+	policy.UpdatedAt = time.Now()
+
+	query := "INSERT INTO escalation_policies (name, description, steps, created_at, created_by, updated_at, updated_by) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	result, err := r.Exec(query, policy.Name, policy.Description, policy.Steps, policy.CreatedAt, policy.CreatedBy, policy.UpdatedAt, policy.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *ruleDB) EditEscalationPolicy(ctx context.Context, policy EscalationPolicy, id string) error {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("no claims found in context")
+	}
+	policy.UpdatedBy = claims.Email
+	policy.UpdatedAt = time.Now()
+
+	query := "UPDATE escalation_policies SET name=$1, description=$2, steps=$3, updated_at=$4, updated_by=$5 WHERE id=$6"
+	_, err := r.Exec(query, policy.Name, policy.Description, policy.Steps, policy.UpdatedAt, policy.UpdatedBy, id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleDB) DeleteEscalationPolicy(ctx context.Context, id string) error {
+	_, err := r.Exec("DELETE FROM escalation_policies WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleDB) RecordAlertEscalation(ctx context.Context, escalation AlertEscalation) error {
+	query := `INSERT INTO alert_escalations (rule_id, fingerprint, labels, fired_at, kind, step, channels, created_by, created_at, note, silence_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.Exec(query, escalation.RuleId, escalation.Fingerprint, escalation.Labels, escalation.FiredAt, escalation.Kind, escalation.Step, escalation.Channels, escalation.CreatedBy, escalation.CreatedAt, escalation.Note, escalation.SilenceId)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleDB) GetAlertEscalations(ctx context.Context, ruleId string) ([]AlertEscalation, error) {
+	escalations := []AlertEscalation{}
+
+	err := r.Select(&escalations, "SELECT * FROM alert_escalations WHERE rule_id=$1 ORDER BY created_at DESC, id DESC", ruleId)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return escalations, nil
+}
@@ -0,0 +1,133 @@
+package rules
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/featureManager"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func TestEscalationPolicyValidate(t *testing.T) {
+	steps := EscalationSteps{
+		{Delay: Duration(10 * time.Minute), Channels: []string{"oncall"}},
+		{Delay: Duration(30 * time.Minute), Channels: []string{"managers"}},
+	}
+	policy := EscalationPolicy{Name: "checkout", Steps: &steps}
+	require.NoError(t, policy.Validate())
+
+	steps[1].Delay = Duration(5 * time.Minute)
+	require.Error(t, policy.Validate())
+
+	steps[1].Delay = Duration(30 * time.Minute)
+	steps[1].Channels = nil
+	require.Error(t, policy.Validate())
+
+	require.ErrorIs(t, (&EscalationPolicy{Name: "checkout"}).Validate(), ErrMissingSteps)
+}
+
+func TestEscalateAlerts(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "admin@signoz.io"})
+
+	steps := EscalationSteps{
+		{Delay: Duration(10 * time.Minute), Channels: []string{"oncall"}},
+		{Delay: Duration(30 * time.Minute), Channels: []string{"managers"}},
+	}
+	policyId, err := ruleDB.CreateEscalationPolicy(ctx, EscalationPolicy{Name: "checkout", Steps: &steps})
+	require.NoError(err)
+
+	target := float64(10)
+	rule, err := NewThresholdRule("1", &PostableRule{
+		AlertName:          "high latency",
+		AlertType:          AlertTypeMetric,
+		RuleType:           RuleTypeThreshold,
+		EscalationPolicyId: policyId,
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {QueryName: "A", Expression: "A", DataSource: v3.DataSourceMetrics, AggregateOperator: v3.AggregateOperatorAvg},
+				},
+			},
+			CompareOp: ValueIsAbove,
+			MatchType: AtleastOnce,
+			Target:    &target,
+		},
+	}, featureManager.StartManager(), nil, true, true)
+	require.NoError(err)
+
+	firedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	checkout := labels.FromMap(map[string]string{labels.AlertNameLabel: "high latency", "service": "checkout"})
+	cart := labels.FromMap(map[string]string{labels.AlertNameLabel: "high latency", "service": "cart"})
+	rule.Active = map[uint64]*Alert{
+		checkout.Hash(): {State: model.StateFiring, Labels: checkout, FiredAt: firedAt},
+		cart.Hash():     {State: model.StateFiring, Labels: cart, FiredAt: firedAt},
+	}
+
+	var notified []*Alert
+	notify := func(ctx context.Context, expr string, alerts ...*Alert) {
+		notified = append(notified, alerts...)
+	}
+
+	// the cart alert is acknowledged before it escalates
+	m := &Manager{rules: map[string]Rule{"1": rule}, ruleDB: ruleDB}
+	acknowledged, err := m.AcknowledgeAlerts(ctx, "1", map[string]string{"service": "cart"}, "")
+	require.NoError(err)
+	require.Len(acknowledged, 1)
+	require.Equal("admin@signoz.io", acknowledged[0].CreatedBy)
+
+	// the checkout alert skips to the last step it has been firing long enough for
+	ts := firedAt.Add(45 * time.Minute)
+	escalateAlerts(ctx, ruleDB, rule, ts, notify)
+	require.Len(notified, 1)
+	require.Equal("checkout", notified[0].Labels.Get("service"))
+	require.Equal("2", notified[0].Labels.Get(EscalationStepLabel))
+	require.Equal([]string{"managers"}, notified[0].Receivers)
+
+	// the escalated alert is only sent again along with the alert of the rule
+	notified = nil
+	escalateAlerts(ctx, ruleDB, rule, ts.Add(time.Minute), notify)
+	require.Empty(notified)
+	rule.Active[checkout.Hash()].LastSentAt = ts.Add(2 * time.Minute)
+	escalateAlerts(ctx, ruleDB, rule, ts.Add(2*time.Minute), notify)
+	require.Len(notified, 1)
+
+	escalations, err := ruleDB.GetAlertEscalations(ctx, "1")
+	require.NoError(err)
+	require.Len(escalations, 2)
+	// the acknowledgement is the newest, the escalation is at the time of the evaluation
+	require.Equal(AlertEscalationKindAcknowledged, escalations[0].Kind)
+	require.Equal(AlertEscalationKindEscalated, escalations[1].Kind)
+	require.Equal(2, escalations[1].Step)
+	require.Equal(EscalationTargets{"managers"}, *escalations[1].Channels)
+
+	// the escalations stop once the alert is acknowledged
+	_, err = m.AcknowledgeAlerts(ctx, "1", nil, "")
+	require.NoError(err)
+	notified = nil
+	escalateAlerts(ctx, ruleDB, rule, ts.Add(2*time.Minute), notify)
+	require.Empty(notified)
+
+	// the policy can't be deleted while the rule is assigned to it
+	m.ruleDB = &storedRulesDB{RuleDB: ruleDB, rules: []StoredRule{{Id: 1, Data: `{"alert": "high latency", "escalationPolicyId": ` + strconv.FormatInt(policyId, 10) + `}`}}}
+	require.Error(m.DeleteEscalationPolicy(ctx, strconv.FormatInt(policyId, 10)))
+}
+
+type storedRulesDB struct {
+	RuleDB
+	rules []StoredRule
+}
+
+func (r *storedRulesDB) GetStoredRules(ctx context.Context) ([]StoredRule, error) {
+	return r.rules, nil
+}
@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+)
+
+// flappingAnnotation is the annotation of the notifications of the flapping
+// alerts
+const flappingAnnotation = "flapping"
+
+// FlapDetection suppresses the notifications of the alerts of a rule changing
+// between firing and resolved more than MaxTransitions times within the
+// window. A flapping alert is notified once as firing, and notified as it is
+// again once it settles.
+type FlapDetection struct {
+	MaxTransitions int      `yaml:"maxTransitions" json:"maxTransitions"`
+	Window         Duration `yaml:"window" json:"window"`
+}
+
+func (d *FlapDetection) Validate() error {
+	if d.MaxTransitions < 1 {
+		return fmt.Errorf("the max transitions of the flap detection must be at least 1")
+	}
+	if d.Window <= 0 {
+		return fmt.Errorf("the window of the flap detection must be positive")
+	}
+	return nil
+}
+
+// flapState is the recent transitions of an alert between firing and
+// resolved, and the last time it was notified as flapping
+type flapState struct {
+	transitions []time.Time
+	sentAt      time.Time
+}
+
+// recordTransition records the alert with the fingerprint firing or resolving
+// at ts, for the rules detecting the flapping alerts. The transitions out of
+// the window are dropped.
+func (r *BaseRule) recordTransition(fp uint64, ts time.Time) {
+	if r.flapDetection == nil {
+		return
+	}
+	if r.flaps == nil {
+		r.flaps = map[uint64]*flapState{}
+	}
+	state, ok := r.flaps[fp]
+	if !ok {
+		state = &flapState{}
+		r.flaps[fp] = state
+	}
+	state.transitions = append(state.transitions, ts)
+
+	since := ts.Add(-time.Duration(r.flapDetection.Window))
+	for fp, state := range r.flaps {
+		kept := state.transitions[:0]
+		for _, t := range state.transitions {
+			if t.After(since) {
+				kept = append(kept, t)
+			}
+		}
+		state.transitions = kept
+		if len(kept) == 0 {
+			delete(r.flaps, fp)
+		}
+	}
+}
+
+// flapping returns the flap state of the alert with the fingerprint when it
+// changed state more than the max transitions within the window before ts
+func (r *BaseRule) flapping(fp uint64, ts time.Time) *flapState {
+	if r.flapDetection == nil {
+		return nil
+	}
+	state, ok := r.flaps[fp]
+	if !ok {
+		return nil
+	}
+	since := ts.Add(-time.Duration(r.flapDetection.Window))
+	count := 0
+	for _, t := range state.transitions {
+		if t.After(since) {
+			count++
+		}
+	}
+	if count <= r.flapDetection.MaxTransitions {
+		return nil
+	}
+	return state
+}
+
+// flappingAlert returns the alert as notified while it is flapping, firing
+// since its first transition in the window whatever its state
+func (r *BaseRule) flappingAlert(alert *Alert, state *flapState) *Alert {
+	anew := *alert
+	anew.ResolvedAt = time.Time{}
+	if anew.FiredAt.IsZero() || anew.FiredAt.After(state.transitions[0]) {
+		anew.FiredAt = state.transitions[0]
+	}
+
+	annotations := map[string]string{}
+	if alert.Annotations != nil {
+		annotations = alert.Annotations.Map()
+	}
+	annotations[flappingAnnotation] = fmt.Sprintf("the alert changed state %d times in the last %s, its notifications are suppressed until it settles", len(state.transitions), time.Duration(r.flapDetection.Window))
+	anew.Annotations = labels.FromMap(annotations)
+	return &anew
+}
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+)
+
+func TestFlapDetection(t *testing.T) {
+	target := float64(100)
+	postableRule := PostableRule{
+		AlertName:  "Flapping test",
+		AlertType:  AlertTypeMetric,
+		RuleType:   RuleTypeThreshold,
+		EvalWindow: Duration(5 * time.Minute),
+		Frequency:  Duration(1 * time.Minute),
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {QueryName: "A", StepInterval: 60, AggregateAttribute: v3.AttributeKey{Key: "signoz_calls_total"}, AggregateOperator: v3.AggregateOperatorSumRate, DataSource: v3.DataSourceMetrics, Expression: "A"},
+				},
+			},
+			CompareOp: ValueIsAbove,
+			MatchType: AtleastOnce,
+			Target:    &target,
+		},
+		FlapDetection: &FlapDetection{MaxTransitions: 2, Window: Duration(10 * time.Minute)},
+	}
+	rule, err := NewThresholdRule("69", &postableRule, nil, nil, true, true, WithoutStateHistory())
+	require.NoError(t, err)
+
+	firing := Vector{{Point: Point{V: 120}, Metric: labels.Labels{{Name: "service", Value: "checkout"}}}}
+	start := time.Now()
+	eval := func(minutes int, res Vector) []*Alert {
+		ts := start.Add(time.Duration(minutes) * time.Minute)
+		_, err := rule.evalVector(context.Background(), ts, rule.State(), res)
+		require.NoError(t, err)
+		var sent []*Alert
+		rule.SendAlerts(context.Background(), ts, 5*time.Minute, time.Minute, func(ctx context.Context, expr string, alerts ...*Alert) {
+			sent = append(sent, alerts...)
+		})
+		return sent
+	}
+
+	sent := eval(0, firing)
+	require.Len(t, sent, 1)
+	assert.True(t, sent[0].ResolvedAt.IsZero())
+	sent = eval(1, nil)
+	require.Len(t, sent, 1)
+	assert.False(t, sent[0].ResolvedAt.IsZero())
+
+	// the third transition makes the alert flapping, it is notified once as
+	// firing
+	sent = eval(2, firing)
+	require.Len(t, sent, 1)
+	assert.True(t, sent[0].ResolvedAt.IsZero())
+	assert.NotEmpty(t, sent[0].Annotations.Get(flappingAnnotation))
+	assert.Empty(t, eval(3, nil))
+	assert.Empty(t, eval(4, firing))
+	assert.Empty(t, eval(5, nil))
+	assert.Equal(t, model.StateInactive, rule.State())
+
+	// once settled, the alert is notified as it is
+	sent = eval(16, nil)
+	require.Len(t, sent, 1)
+	assert.False(t, sent[0].ResolvedAt.IsZero())
+	assert.Empty(t, sent[0].Annotations.Get(flappingAnnotation))
+
+	assert.Error(t, (&FlapDetection{MaxTransitions: 0, Window: Duration(time.Minute)}).Validate())
+	assert.Error(t, (&FlapDetection{MaxTransitions: 3}).Validate())
+}
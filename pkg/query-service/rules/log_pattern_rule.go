@@ -0,0 +1,328 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	logsv3 "go.signoz.io/signoz/pkg/query-service/app/logs/v3"
+	logsV4 "go.signoz.io/signoz/pkg/query-service/app/logs/v4"
+	"go.signoz.io/signoz/pkg/query-service/interfaces"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.uber.org/zap"
+)
+
+// LogPatternMode is what a log pattern rule alerts on
+type LogPatternMode string
+
+const (
+	// LogPatternModeRate alerts when the rate of the logs of a pattern, in
+	// logs per minute over the eval window, crosses the threshold
+	LogPatternModeRate LogPatternMode = "rate"
+	// LogPatternModeNew alerts when a pattern the rule has never seen for the
+	// group appears, until the pattern is older than the eval window
+	LogPatternModeNew LogPatternMode = "new"
+)
+
+const (
+	LogPatternLabel            = "log_pattern"
+	LogPatternFingerprintLabel = "log_pattern_fingerprint"
+
+	// logPatternLimit is the most patterns a rule evaluates at once
+	logPatternLimit = 1000
+)
+
+// LogPatternCondition is the condition of a log pattern rule. The logs of the
+// selected builder query are counted by pattern for each of its groups, the
+// service for instance.
+type LogPatternCondition struct {
+	Mode LogPatternMode `yaml:"mode" json:"mode"`
+	// Patterns limits a rate rule to the patterns, given by their fingerprint,
+	// their text or a log line of the pattern. A rate rule without patterns
+	// alerts on any pattern.
+	Patterns []string `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+}
+
+func (c *LogPatternCondition) Validate() error {
+	switch c.Mode {
+	case LogPatternModeRate, LogPatternModeNew:
+	default:
+		return fmt.Errorf("invalid log pattern mode: %s", c.Mode)
+	}
+	if c.Mode == LogPatternModeNew && len(c.Patterns) > 0 {
+		return fmt.Errorf("the patterns are supported only by the rate mode")
+	}
+	return nil
+}
+
+// matches tells if the pattern is one of the patterns of the condition
+func (c *LogPatternCondition) matches(pattern, fingerprint string) bool {
+	if len(c.Patterns) == 0 {
+		return true
+	}
+	for _, p := range c.Patterns {
+		if p == fingerprint || p == pattern || logsV4.LogPattern(p) == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// LogPattern is a pattern of the logs seen by a rule for a group
+type LogPattern struct {
+	Id          int64     `json:"id" db:"id"`
+	RuleId      string    `json:"ruleId" db:"rule_id"`
+	GroupKey    string    `json:"groupKey" db:"group_key"`
+	Fingerprint string    `json:"fingerprint" db:"fingerprint"`
+	Pattern     string    `json:"pattern" db:"pattern"`
+	FirstSeen   time.Time `json:"firstSeen" db:"first_seen"`
+}
+
+// LogPatternRule alerts on the patterns of the logs, the logs written by the
+// same statement sharing a pattern once their ids and numbers are masked. It
+// evaluates its alerts as a threshold rule does.
+type LogPatternRule struct {
+	*ThresholdRule
+
+	ruleDB RuleDB
+
+	// seen is when the rule first saw the patterns, by group and fingerprint,
+	// loaded from the db on the first evaluation
+	seen map[string]map[string]time.Time
+}
+
+func NewLogPatternRule(
+	id string,
+	p *PostableRule,
+	featureFlags interfaces.FeatureLookup,
+	reader interfaces.Reader,
+	ruleDB RuleDB,
+	opts ...RuleOption,
+) (*LogPatternRule, error) {
+
+	zap.L().Info("creating new LogPatternRule", zap.String("id", id), zap.Any("opts", opts))
+
+	if p.RuleCondition.MatchType == "" {
+		p.RuleCondition.MatchType = AtleastOnce
+	}
+
+	thresholdRule, err := NewThresholdRule(id, p, featureFlags, reader, true, true, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LogPatternRule{
+		ThresholdRule: thresholdRule,
+		ruleDB:        ruleDB,
+	}, nil
+}
+
+func (r *LogPatternRule) Type() RuleType {
+	return RuleTypeLogPattern
+}
+
+func (r *LogPatternRule) Eval(ctx context.Context, ts time.Time) (interface{}, error) {
+	prevState := r.State()
+
+	res, err := r.buildAndRunPatternQuery(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.evalVector(ctx, ts, prevState, res)
+}
+
+func (r *LogPatternRule) buildAndRunPatternQuery(ctx context.Context, ts time.Time) (Vector, error) {
+	condition := r.ruleCondition.LogPattern
+	if condition == nil {
+		return nil, fmt.Errorf("missing the log pattern condition")
+	}
+
+	q := r.ruleCondition.CompositeQuery.BuilderQueries[r.GetSelectedQuery()]
+	if q == nil || q.DataSource != v3.DataSourceLogs {
+		return nil, fmt.Errorf("log pattern rules need a logs builder query")
+	}
+
+	startTs, endTs := r.Timestamps(ts)
+	params := &v3.QueryRangeParamsV3{
+		Start: startTs.UnixMilli(),
+		End:   endTs.UnixMilli(),
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType:      v3.QueryTypeBuilder,
+			PanelType:      v3.PanelTypeTable,
+			BuilderQueries: map[string]*v3.BuilderQuery{q.QueryName: q},
+		},
+	}
+	if logsv3.EnrichmentRequired(params) {
+		logsFields, err := r.reader.GetLogFields(ctx)
+		if err != nil {
+			return nil, err
+		}
+		logsKeys := model.GetLogFieldsV3(ctx, params, logsFields)
+		r.logsKeys = logsKeys
+		logsv3.Enrich(params, logsKeys)
+	}
+
+	query, err := logsV4.PrepareLogPatternsQuery(params.Start, params.End, q, logPatternLimit)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.reader.GetListResultV3(ctx, query)
+	if err != nil {
+		zap.L().Error("failed to get the log patterns", zap.String("rule", r.Name()), zap.Error(err))
+		return nil, fmt.Errorf("internal error while querying")
+	}
+
+	var newPatterns []LogPattern
+	if condition.Mode == LogPatternModeNew {
+		newPatterns, err = r.see(ctx, q.GroupBy, rows, ts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var resultVector Vector
+	windowMinutes := endTs.Sub(startTs).Minutes()
+	for _, row := range rows {
+		pattern := rowString(row.Data["pattern"])
+		fingerprint := logsV4.LogPatternFingerprint(pattern)
+		count, _ := reflect.Indirect(reflect.ValueOf(row.Data["value"])).Interface().(float64)
+
+		lbls := groupLabels(q.GroupBy, row)
+		lbls[LogPatternLabel] = pattern
+		lbls[LogPatternFingerprintLabel] = fingerprint
+
+		switch condition.Mode {
+		case LogPatternModeRate:
+			if !condition.matches(pattern, fingerprint) {
+				continue
+			}
+			series := v3.Series{
+				Labels: lbls,
+				Points: []v3.Point{{Timestamp: endTs.UnixMilli(), Value: count / windowMinutes}},
+			}
+			if smpl, shouldAlert := r.ShouldAlert(series); shouldAlert {
+				resultVector = append(resultVector, smpl)
+			}
+		case LogPatternModeNew:
+			firstSeen, ok := r.seen[groupKey(q.GroupBy, row)][fingerprint]
+			if r.sendUnmatched || (ok && ts.Sub(firstSeen) < r.evalWindow) {
+				resultVector = append(resultVector, Sample{Point: Point{V: count}, Metric: labels.FromMap(lbls)})
+			}
+		}
+	}
+
+	if len(newPatterns) > 0 && r.ruleDB != nil {
+		if err := r.ruleDB.SaveLogPatterns(ctx, newPatterns); err != nil {
+			zap.L().Error("failed to save the log patterns", zap.String("rule", r.Name()), zap.Error(err))
+		}
+	}
+	return resultVector, nil
+}
+
+// see records the patterns of the rows the rule had not seen, and returns
+// them. The patterns of a group the rule sees for the first time are its
+// baseline, they are not new.
+func (r *LogPatternRule) see(ctx context.Context, groupBy []v3.AttributeKey, rows []*v3.Row, ts time.Time) ([]LogPattern, error) {
+	if r.seen == nil {
+		seen := map[string]map[string]time.Time{}
+		if r.ruleDB != nil {
+			patterns, err := r.ruleDB.GetLogPatterns(ctx, r.ID())
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range patterns {
+				if seen[p.GroupKey] == nil {
+					seen[p.GroupKey] = map[string]time.Time{}
+				}
+				seen[p.GroupKey][p.Fingerprint] = p.FirstSeen
+			}
+		}
+		r.seen = seen
+	}
+
+	var newPatterns []LogPattern
+	baseline := map[string]bool{}
+	for _, row := range rows {
+		key := groupKey(groupBy, row)
+		if r.seen[key] == nil {
+			r.seen[key] = map[string]time.Time{}
+			baseline[key] = true
+		}
+
+		pattern := rowString(row.Data["pattern"])
+		fingerprint := logsV4.LogPatternFingerprint(pattern)
+		if _, ok := r.seen[key][fingerprint]; ok {
+			continue
+		}
+
+		firstSeen := ts
+		if baseline[key] {
+			// the baseline is seen as if before the eval window
+			firstSeen = ts.Add(-r.evalWindow)
+		}
+		r.seen[key][fingerprint] = firstSeen
+		newPatterns = append(newPatterns, LogPattern{
+			RuleId:      r.ID(),
+			GroupKey:    key,
+			Fingerprint: fingerprint,
+			Pattern:     pattern,
+			FirstSeen:   firstSeen,
+		})
+	}
+	return newPatterns, nil
+}
+
+// groupLabels returns the values of the group by keys of the row
+func groupLabels(groupBy []v3.AttributeKey, row *v3.Row) map[string]string {
+	lbls := make(map[string]string, len(groupBy)+2)
+	for _, key := range groupBy {
+		lbls[key.Key] = rowString(row.Data[key.Key])
+	}
+	return lbls
+}
+
+// groupKey identifies the group of the row
+func groupKey(groupBy []v3.AttributeKey, row *v3.Row) string {
+	return fmt.Sprintf("%016x", labels.FromMap(groupLabels(groupBy, row)).Hash())
+}
+
+// rowString returns the value of a column of a row, which the reader returns
+// as a pointer
+func rowString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+func (r *ruleDB) GetLogPatterns(ctx context.Context, ruleID string) ([]LogPattern, error) {
+	patterns := []LogPattern{}
+
+	err := r.Select(&patterns, "SELECT * FROM log_patterns WHERE rule_id=$1", ruleID)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return patterns, nil
+}
+
+func (r *ruleDB) SaveLogPatterns(ctx context.Context, patterns []LogPattern) error {
+	query := "INSERT INTO log_patterns (rule_id, group_key, fingerprint, pattern, first_seen) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (rule_id, group_key, fingerprint) DO NOTHING"
+	for _, p := range patterns {
+		if _, err := r.Exec(query, p.RuleId, p.GroupKey, p.Fingerprint, p.Pattern, p.FirstSeen); err != nil {
+			zap.L().Error("Error in processing sql query", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
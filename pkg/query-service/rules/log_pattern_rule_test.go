@@ -0,0 +1,137 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cmock "github.com/srikanthccv/ClickHouse-go-mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/app/clickhouseReader"
+	"go.signoz.io/signoz/pkg/query-service/featureManager"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func logPatternPostableRule(condition *LogPatternCondition) *PostableRule {
+	return &PostableRule{
+		AlertName:  "Log pattern test",
+		AlertType:  AlertTypeLogs,
+		RuleType:   RuleTypeLogPattern,
+		EvalWindow: Duration(5 * time.Minute),
+		Frequency:  Duration(1 * time.Minute),
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {
+						QueryName:         "A",
+						StepInterval:      60,
+						AggregateOperator: v3.AggregateOperatorCount,
+						DataSource:        v3.DataSourceLogs,
+						GroupBy: []v3.AttributeKey{
+							{Key: "service.name", Type: v3.AttributeKeyTypeResource, DataType: v3.AttributeKeyDataTypeString},
+						},
+					},
+				},
+			},
+			LogPattern: condition,
+		},
+	}
+}
+
+func TestLogPatternRuleRate(t *testing.T) {
+	target := 100.0
+	postableRule := logPatternPostableRule(&LogPatternCondition{
+		Mode:     LogPatternModeRate,
+		Patterns: []string{"payment 1234 failed"},
+	})
+	postableRule.RuleCondition.CompareOp = ValueIsAbove
+	postableRule.RuleCondition.Target = &target
+	require.NoError(t, postableRule.Validate())
+
+	fm := featureManager.StartManager()
+	mock, err := cmock.NewClickHouseWithQueryMatcher(nil, &queryMatcherAny{})
+	require.NoError(t, err)
+
+	cols := []cmock.ColumnType{
+		{Name: "service.name", Type: "String"},
+		{Name: "pattern", Type: "String"},
+		{Name: "value", Type: "Float64"},
+	}
+	mock.ExpectQuery("SELECT").WillReturnRows(cmock.NewRows(cols, [][]interface{}{
+		{"frontend", "user <num> logged in", float64(6000)},
+		{"payment", "payment <num> failed", float64(600)},
+		{"payment", "payment <num> retried", float64(300)},
+	}))
+
+	reader := clickhouseReader.NewReaderFromClickhouseConnection(mock, clickhouseReader.NewOptions("", "", "archiveNamespace"), nil, "", fm, "", true, true, time.Second, nil)
+	rule, err := NewLogPatternRule("1", postableRule, fm, reader, nil, WithoutStateHistory())
+	require.NoError(t, err)
+
+	retVal, err := rule.Eval(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, retVal.(int))
+	for _, alert := range rule.Active {
+		assert.Equal(t, "payment", alert.Labels.Get("service.name"))
+		assert.Equal(t, "payment <num> failed", alert.Labels.Get(LogPatternLabel))
+		assert.Equal(t, float64(120), alert.Value)
+	}
+}
+
+func TestLogPatternRuleNew(t *testing.T) {
+	postableRule := logPatternPostableRule(&LogPatternCondition{Mode: LogPatternModeNew})
+	require.NoError(t, postableRule.Validate())
+
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	fm := featureManager.StartManager()
+	mock, err := cmock.NewClickHouseWithQueryMatcher(nil, &queryMatcherAny{})
+	require.NoError(t, err)
+	reader := clickhouseReader.NewReaderFromClickhouseConnection(mock, clickhouseReader.NewOptions("", "", "archiveNamespace"), nil, "", fm, "", true, true, time.Second, nil)
+
+	cols := []cmock.ColumnType{
+		{Name: "service.name", Type: "String"},
+		{Name: "pattern", Type: "String"},
+		{Name: "value", Type: "Float64"},
+	}
+	eval := func(rule *LogPatternRule, ts time.Time, values [][]interface{}) int {
+		mock.ExpectQuery("SELECT").WillReturnRows(cmock.NewRows(cols, values))
+		retVal, err := rule.Eval(context.Background(), ts)
+		require.NoError(t, err)
+		return retVal.(int)
+	}
+
+	rule, err := NewLogPatternRule("1", postableRule, fm, reader, ruleDB, WithoutStateHistory())
+	require.NoError(t, err)
+
+	// the patterns seen first are the baseline
+	ts := time.Now()
+	require.Equal(t, 0, eval(rule, ts, [][]interface{}{
+		{"frontend", "user <num> logged in", float64(10)},
+	}))
+
+	ts = ts.Add(time.Minute)
+	require.Equal(t, 1, eval(rule, ts, [][]interface{}{
+		{"frontend", "user <num> logged in", float64(10)},
+		{"frontend", "cache miss for <uuid>", float64(2)},
+	}))
+	for _, alert := range rule.Active {
+		assert.Equal(t, "cache miss for <uuid>", alert.Labels.Get(LogPatternLabel))
+	}
+
+	// the patterns seen are kept across restarts, and are no longer new
+	// once older than the eval window
+	rule, err = NewLogPatternRule("1", postableRule, fm, reader, ruleDB, WithoutStateHistory())
+	require.NoError(t, err)
+	ts = ts.Add(10 * time.Minute)
+	require.Equal(t, 0, eval(rule, ts, [][]interface{}{
+		{"frontend", "user <num> logged in", float64(10)},
+		{"frontend", "cache miss for <uuid>", float64(2)},
+	}))
+
+	patterns, err := ruleDB.GetLogPatterns(context.Background(), "1")
+	require.NoError(t, err)
+	require.Len(t, patterns, 2)
+}
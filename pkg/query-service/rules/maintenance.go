@@ -25,12 +25,15 @@ type PlannedMaintenance struct {
 	Description string    `json:"description" db:"description"`
 	Schedule    *Schedule `json:"schedule" db:"schedule"`
 	AlertIds    *AlertIds `json:"alertIds" db:"alert_ids"`
-	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
-	CreatedBy   string    `json:"createdBy" db:"created_by"`
-	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
-	UpdatedBy   string    `json:"updatedBy" db:"updated_by"`
-	Status      string    `json:"status"`
-	Kind        string    `json:"kind"`
+	// Matchers scope the maintenance to the alerts with matching labels, the
+	// rules are still evaluated and only the matching alerts are suppressed
+	Matchers  *MaintenanceMatchers `json:"matchers" db:"matchers"`
+	CreatedAt time.Time            `json:"createdAt" db:"created_at"`
+	CreatedBy string               `json:"createdBy" db:"created_by"`
+	UpdatedAt time.Time            `json:"updatedAt" db:"updated_at"`
+	UpdatedBy string               `json:"updatedBy" db:"updated_by"`
+	Status    string               `json:"status"`
+	Kind      string               `json:"kind"`
 }
 
 type AlertIds []string
@@ -90,6 +93,9 @@ type Recurrence struct {
 	Duration   Duration   `json:"duration"`
 	RepeatType RepeatType `json:"repeatType"`
 	RepeatOn   []RepeatOn `json:"repeatOn"`
+	// Rrule is an RRULE such as FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU, used in
+	// place of the repeat type when set
+	Rrule string `json:"rrule,omitempty"`
 }
 
 func (r *Recurrence) Scan(src interface{}) error {
@@ -131,6 +137,7 @@ func (s Schedule) MarshalJSON() ([]byte, error) {
 			Duration:   s.Recurrence.Duration,
 			RepeatType: s.Recurrence.RepeatType,
 			RepeatOn:   s.Recurrence.RepeatOn,
+			Rrule:      s.Recurrence.Rrule,
 		}
 	}
 
@@ -205,116 +212,135 @@ func (s *Schedule) UnmarshalJSON(data []byte) error {
 			Duration:   aux.Recurrence.Duration,
 			RepeatType: aux.Recurrence.RepeatType,
 			RepeatOn:   aux.Recurrence.RepeatOn,
+			Rrule:      aux.Recurrence.Rrule,
 		}
 	}
 	return nil
 }
 
-func (m *PlannedMaintenance) shouldSkip(ruleID string, now time.Time) bool {
-
-	found := false
-	if m.AlertIds != nil {
-		for _, alertID := range *m.AlertIds {
-			if alertID == ruleID {
-				found = true
-				break
-			}
-		}
+// appliesTo reports whether the maintenance is for the rule, a maintenance
+// without alert ids is for all the rules
+func (m *PlannedMaintenance) appliesTo(ruleID string) bool {
+	if m.AlertIds == nil || len(*m.AlertIds) == 0 {
+		return true
 	}
+	return slices.Contains(*m.AlertIds, ruleID)
+}
 
-	// If no alert ids, then skip all alerts
-	if m.AlertIds == nil || len(*m.AlertIds) == 0 {
-		found = true
+// isScoped reports whether the maintenance is scoped by label matchers
+func (m *PlannedMaintenance) isScoped() bool {
+	return m.Matchers != nil && len(*m.Matchers) > 0
+}
+
+// shouldSkip reports whether the evaluation of the rule is skipped. The rules
+// of maintenances scoped by labels are evaluated, see suppresses.
+func (m *PlannedMaintenance) shouldSkip(ruleID string, now time.Time) bool {
+	if m.isScoped() || !m.appliesTo(ruleID) {
+		return false
 	}
 
-	if found {
+	zap.L().Info("alert found in maintenance", zap.String("alert", ruleID), zap.Any("maintenance", m.Name))
+	return m.inWindow(ruleID, now)
+}
 
-		zap.L().Info("alert found in maintenance", zap.String("alert", ruleID), zap.Any("maintenance", m.Name))
+// suppresses reports whether the maintenance suppresses the alert of the rule
+// by its labels
+func (m *PlannedMaintenance) suppresses(ruleID string, alert *Alert, now time.Time) bool {
+	if !m.isScoped() || !m.appliesTo(ruleID) || !m.Matchers.matches(alert.Labels) {
+		return false
+	}
+	return m.inWindow(ruleID, now)
+}
 
-		// If alert is found, we check if it should be skipped based on the schedule
-		// If it should be skipped, we return true
-		// If it should not be skipped, we return false
+// inWindow reports whether the schedule of the maintenance covers the time
+func (m *PlannedMaintenance) inWindow(ruleID string, now time.Time) bool {
+	// fixed schedule
+	if !m.Schedule.StartTime.IsZero() && !m.Schedule.EndTime.IsZero() {
+		// if the current time in the timezone is between the start and end time
+		loc, err := time.LoadLocation(m.Schedule.Timezone)
+		if err != nil {
+			zap.L().Error("Error loading location", zap.String("timezone", m.Schedule.Timezone), zap.Error(err))
+			return false
+		}
 
-		// fixed schedule
-		if !m.Schedule.StartTime.IsZero() && !m.Schedule.EndTime.IsZero() {
-			// if the current time in the timezone is between the start and end time
-			loc, err := time.LoadLocation(m.Schedule.Timezone)
-			if err != nil {
-				zap.L().Error("Error loading location", zap.String("timezone", m.Schedule.Timezone), zap.Error(err))
-				return false
-			}
+		currentTime := now.In(loc)
+		zap.L().Info("checking fixed schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", m.Schedule.StartTime), zap.Time("endTime", m.Schedule.EndTime))
+		if currentTime.After(m.Schedule.StartTime) && currentTime.Before(m.Schedule.EndTime) {
+			return true
+		}
+	}
 
-			currentTime := now.In(loc)
-			zap.L().Info("checking fixed schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", m.Schedule.StartTime), zap.Time("endTime", m.Schedule.EndTime))
-			if currentTime.After(m.Schedule.StartTime) && currentTime.Before(m.Schedule.EndTime) {
-				return true
-			}
+	// recurring schedule
+	if m.Schedule.Recurrence != nil {
+		zap.L().Info("evaluating recurrence schedule")
+		start := m.Schedule.Recurrence.StartTime
+		end := m.Schedule.Recurrence.StartTime.Add(time.Duration(m.Schedule.Recurrence.Duration))
+		// if the current time in the timezone is between the start and end time
+		loc, err := time.LoadLocation(m.Schedule.Timezone)
+		if err != nil {
+			zap.L().Error("Error loading location", zap.String("timezone", m.Schedule.Timezone), zap.Error(err))
+			return false
 		}
+		currentTime := now.In(loc)
 
-		// recurring schedule
-		if m.Schedule.Recurrence != nil {
-			zap.L().Info("evaluating recurrence schedule")
-			start := m.Schedule.Recurrence.StartTime
-			end := m.Schedule.Recurrence.StartTime.Add(time.Duration(m.Schedule.Recurrence.Duration))
-			// if the current time in the timezone is between the start and end time
-			loc, err := time.LoadLocation(m.Schedule.Timezone)
-			if err != nil {
-				zap.L().Error("Error loading location", zap.String("timezone", m.Schedule.Timezone), zap.Error(err))
-				return false
-			}
-			currentTime := now.In(loc)
+		zap.L().Info("checking recurring schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", start), zap.Time("endTime", end))
 
-			zap.L().Info("checking recurring schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", start), zap.Time("endTime", end))
+		// make sure the start time is not after the current time
+		if currentTime.Before(start.In(loc)) {
+			zap.L().Info("current time is before start time", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", start.In(loc)))
+			return false
+		}
 
-			// make sure the start time is not after the current time
-			if currentTime.Before(start.In(loc)) {
-				zap.L().Info("current time is before start time", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", start.In(loc)))
-				return false
-			}
+		var endTime time.Time
+		if m.Schedule.Recurrence.EndTime != nil {
+			endTime = *m.Schedule.Recurrence.EndTime
+		}
+		if !endTime.IsZero() && currentTime.After(endTime.In(loc)) {
+			zap.L().Info("current time is after end time", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("endTime", end.In(loc)))
+			return false
+		}
 
-			var endTime time.Time
-			if m.Schedule.Recurrence.EndTime != nil {
-				endTime = *m.Schedule.Recurrence.EndTime
-			}
-			if !endTime.IsZero() && currentTime.After(endTime.In(loc)) {
-				zap.L().Info("current time is after end time", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("endTime", end.In(loc)))
+		if m.Schedule.Recurrence.Rrule != "" {
+			rule, err := parseRrule(m.Schedule.Recurrence.Rrule)
+			if err != nil {
+				zap.L().Error("Error parsing rrule", zap.String("rrule", m.Schedule.Recurrence.Rrule), zap.Error(err))
 				return false
 			}
+			return rule.covers(start.In(loc), time.Duration(m.Schedule.Recurrence.Duration), currentTime)
+		}
 
-			switch m.Schedule.Recurrence.RepeatType {
-			case RepeatTypeDaily:
-				// take the hours and minutes from the start time and add them to the current time
-				startTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), start.Hour(), start.Minute(), 0, 0, loc)
-				endTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), end.Hour(), end.Minute(), 0, 0, loc)
-				zap.L().Info("checking daily schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", startTime), zap.Time("endTime", endTime))
+		switch m.Schedule.Recurrence.RepeatType {
+		case RepeatTypeDaily:
+			// take the hours and minutes from the start time and add them to the current time
+			startTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+			endTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+			zap.L().Info("checking daily schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", startTime), zap.Time("endTime", endTime))
 
-				if currentTime.After(startTime) && currentTime.Before(endTime) {
+			if currentTime.After(startTime) && currentTime.Before(endTime) {
+				return true
+			}
+		case RepeatTypeWeekly:
+			// if the current time in the timezone is between the start and end time on the RepeatOn day
+			startTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+			endTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+			zap.L().Info("checking weekly schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", startTime), zap.Time("endTime", endTime))
+			if currentTime.After(startTime) && currentTime.Before(endTime) {
+				if len(m.Schedule.Recurrence.RepeatOn) == 0 {
 					return true
-				}
-			case RepeatTypeWeekly:
-				// if the current time in the timezone is between the start and end time on the RepeatOn day
-				startTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), start.Hour(), start.Minute(), 0, 0, loc)
-				endTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), end.Hour(), end.Minute(), 0, 0, loc)
-				zap.L().Info("checking weekly schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", startTime), zap.Time("endTime", endTime))
-				if currentTime.After(startTime) && currentTime.Before(endTime) {
-					if len(m.Schedule.Recurrence.RepeatOn) == 0 {
-						return true
-					} else if slices.Contains(m.Schedule.Recurrence.RepeatOn, RepeatOn(strings.ToLower(currentTime.Weekday().String()))) {
-						return true
-					}
-				}
-			case RepeatTypeMonthly:
-				// if the current time in the timezone is between the start and end time on the day of the current month
-				startTime := time.Date(currentTime.Year(), currentTime.Month(), start.Day(), start.Hour(), start.Minute(), 0, 0, loc)
-				endTime := time.Date(currentTime.Year(), currentTime.Month(), end.Day(), end.Hour(), end.Minute(), 0, 0, loc)
-				zap.L().Info("checking monthly schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", startTime), zap.Time("endTime", endTime))
-				if currentTime.After(startTime) && currentTime.Before(endTime) && currentTime.Day() == start.Day() {
+				} else if slices.Contains(m.Schedule.Recurrence.RepeatOn, RepeatOn(strings.ToLower(currentTime.Weekday().String()))) {
 					return true
 				}
 			}
+		case RepeatTypeMonthly:
+			// if the current time in the timezone is between the start and end time on the day of the current month
+			startTime := time.Date(currentTime.Year(), currentTime.Month(), start.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+			endTime := time.Date(currentTime.Year(), currentTime.Month(), end.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+			zap.L().Info("checking monthly schedule", zap.Any("rule", ruleID), zap.String("maintenance", m.Name), zap.Time("currentTime", currentTime), zap.Time("startTime", startTime), zap.Time("endTime", endTime))
+			if currentTime.After(startTime) && currentTime.Before(endTime) && currentTime.Day() == start.Day() {
+				return true
+			}
 		}
 	}
-	// If alert is not found, we return false
 	return false
 }
 
@@ -323,7 +349,7 @@ func (m *PlannedMaintenance) IsActive(now time.Time) bool {
 	if m.AlertIds != nil && len(*m.AlertIds) > 0 {
 		ruleID = (*m.AlertIds)[0]
 	}
-	return m.shouldSkip(ruleID, now)
+	return m.inWindow(ruleID, now)
 }
 
 func (m *PlannedMaintenance) IsUpcoming() bool {
@@ -364,7 +390,11 @@ func (m *PlannedMaintenance) Validate() error {
 	}
 
 	if m.Schedule.Recurrence != nil {
-		if m.Schedule.Recurrence.RepeatType == "" {
+		if m.Schedule.Recurrence.Rrule != "" {
+			if _, err := parseRrule(m.Schedule.Recurrence.Rrule); err != nil {
+				return errors.Wrap(err, "invalid rrule")
+			}
+		} else if m.Schedule.Recurrence.RepeatType == "" {
 			return ErrMissingRepeatType
 		}
 		if m.Schedule.Recurrence.Duration == 0 {
@@ -374,6 +404,14 @@ func (m *PlannedMaintenance) Validate() error {
 			return errors.New("end time cannot be before start time")
 		}
 	}
+
+	if m.Matchers != nil {
+		for _, matcher := range *m.Matchers {
+			if err := matcher.Validate(); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -396,23 +434,25 @@ func (m PlannedMaintenance) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(struct {
-		Id          int64     `json:"id" db:"id"`
-		Name        string    `json:"name" db:"name"`
-		Description string    `json:"description" db:"description"`
-		Schedule    *Schedule `json:"schedule" db:"schedule"`
-		AlertIds    *AlertIds `json:"alertIds" db:"alert_ids"`
-		CreatedAt   time.Time `json:"createdAt" db:"created_at"`
-		CreatedBy   string    `json:"createdBy" db:"created_by"`
-		UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
-		UpdatedBy   string    `json:"updatedBy" db:"updated_by"`
-		Status      string    `json:"status"`
-		Kind        string    `json:"kind"`
+		Id          int64                `json:"id" db:"id"`
+		Name        string               `json:"name" db:"name"`
+		Description string               `json:"description" db:"description"`
+		Schedule    *Schedule            `json:"schedule" db:"schedule"`
+		AlertIds    *AlertIds            `json:"alertIds" db:"alert_ids"`
+		Matchers    *MaintenanceMatchers `json:"matchers" db:"matchers"`
+		CreatedAt   time.Time            `json:"createdAt" db:"created_at"`
+		CreatedBy   string               `json:"createdBy" db:"created_by"`
+		UpdatedAt   time.Time            `json:"updatedAt" db:"updated_at"`
+		UpdatedBy   string               `json:"updatedBy" db:"updated_by"`
+		Status      string               `json:"status"`
+		Kind        string               `json:"kind"`
 	}{
 		Id:          m.Id,
 		Name:        m.Name,
 		Description: m.Description,
 		Schedule:    m.Schedule,
 		AlertIds:    m.AlertIds,
+		Matchers:    m.Matchers,
 		CreatedAt:   m.CreatedAt,
 		CreatedBy:   m.CreatedBy,
 		UpdatedAt:   m.UpdatedAt,
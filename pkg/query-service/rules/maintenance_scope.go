@@ -0,0 +1,323 @@
+package rules
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.uber.org/zap"
+)
+
+type MatcherOp string
+
+const (
+	MatcherOpEqual     MatcherOp = "="
+	MatcherOpNotEqual  MatcherOp = "!="
+	MatcherOpRegexp    MatcherOp = "=~"
+	MatcherOpNotRegexp MatcherOp = "!~"
+)
+
+// maxRruleWindowInDays bounds the days looked at for an occurrence covering
+// a time, the occurrences are not expected to last longer
+const maxRruleWindowInDays = 366
+
+// MaintenanceMatcher matches the alerts by a label, such as service, env or
+// cluster. The regexp ops match the whole value of the label, a missing label
+// has the empty value.
+type MaintenanceMatcher struct {
+	Name  string    `json:"name"`
+	Op    MatcherOp `json:"op"`
+	Value string    `json:"value"`
+}
+
+func (mm MaintenanceMatcher) Validate() error {
+	if mm.Name == "" {
+		return errors.New("missing matcher label name")
+	}
+	switch mm.Op {
+	case MatcherOpEqual, MatcherOpNotEqual:
+	case MatcherOpRegexp, MatcherOpNotRegexp:
+		if _, err := regexp.Compile("^(?:" + mm.Value + ")$"); err != nil {
+			return errors.Wrapf(err, "invalid regexp for matcher on %s", mm.Name)
+		}
+	default:
+		return fmt.Errorf("invalid matcher op %q for %s", mm.Op, mm.Name)
+	}
+	return nil
+}
+
+func (mm MaintenanceMatcher) matches(lbls labels.BaseLabels) bool {
+	value := lbls.Get(mm.Name)
+	switch mm.Op {
+	case MatcherOpEqual:
+		return value == mm.Value
+	case MatcherOpNotEqual:
+		return value != mm.Value
+	case MatcherOpRegexp, MatcherOpNotRegexp:
+		re, err := regexp.Compile("^(?:" + mm.Value + ")$")
+		if err != nil {
+			return false
+		}
+		return re.MatchString(value) == (mm.Op == MatcherOpRegexp)
+	}
+	return false
+}
+
+// MaintenanceMatchers match the alerts matched by all of the matchers
+type MaintenanceMatchers []MaintenanceMatcher
+
+func (m *MaintenanceMatchers) matches(lbls labels.BaseLabels) bool {
+	if lbls == nil {
+		return false
+	}
+	for _, matcher := range *m {
+		if !matcher.matches(lbls) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *MaintenanceMatchers) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, m)
+	case string:
+		return json.Unmarshal([]byte(data), m)
+	}
+	return nil
+}
+
+func (m *MaintenanceMatchers) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// MaintenanceSuppression counts what a maintenance suppressed for a rule, the
+// notifications of the alerts matched by the labels of a scoped maintenance
+// and the evaluations skipped by the others
+type MaintenanceSuppression struct {
+	MaintenanceId      int64     `json:"maintenanceId" db:"maintenance_id"`
+	RuleId             string    `json:"ruleId" db:"rule_id"`
+	SuppressedAlerts   int       `json:"suppressedAlerts" db:"suppressed_alerts"`
+	SkippedEvaluations int       `json:"skippedEvaluations" db:"skipped_evaluations"`
+	LastSuppressedAt   time.Time `json:"lastSuppressedAt" db:"last_suppressed_at"`
+}
+
+// skippingMaintenance returns the maintenance which skips the evaluation of
+// the rule, if any
+func skippingMaintenance(maintenance []PlannedMaintenance, ruleID string, ts time.Time) *PlannedMaintenance {
+	for i := range maintenance {
+		zap.L().Info("checking if rule should be skipped", zap.String("rule", ruleID), zap.Any("maintenance", maintenance[i]))
+		if maintenance[i].shouldSkip(ruleID, ts) {
+			return &maintenance[i]
+		}
+	}
+	return nil
+}
+
+// notifyOutsideMaintenance wraps the notify func to drop the alerts of the
+// rule suppressed by a maintenance scoped by labels, the firing ones are
+// counted for the maintenance
+func notifyOutsideMaintenance(ruleDB RuleDB, maintenance []PlannedMaintenance, ruleID string, ts time.Time, notify NotifyFunc) NotifyFunc {
+	return func(ctx context.Context, expr string, alerts ...*Alert) {
+		kept := make([]*Alert, 0, len(alerts))
+		suppressed := map[int64]int{}
+	alerts:
+		for _, alert := range alerts {
+			for i := range maintenance {
+				if maintenance[i].suppresses(ruleID, alert, ts) {
+					if alert.State == model.StateFiring {
+						suppressed[maintenance[i].Id]++
+					}
+					continue alerts
+				}
+			}
+			kept = append(kept, alert)
+		}
+
+		for id, count := range suppressed {
+			zap.L().Info("alerts suppressed by maintenance", zap.String("rule", ruleID), zap.Int64("maintenance", id), zap.Int("count", count))
+			if err := ruleDB.RecordMaintenanceSuppression(ctx, id, ruleID, count, 0); err != nil {
+				zap.L().Error("failed to record the alerts suppressed by maintenance", zap.Int64("maintenance", id), zap.Error(err))
+			}
+		}
+		notify(ctx, expr, kept...)
+	}
+}
+
+// rrule is the subset of the RFC 5545 recurrence rules supported for the
+// maintenance schedules, the occurrences start at the time of day of the
+// recurrence start
+type rrule struct {
+	freq       RepeatType
+	interval   int
+	byDay      []time.Weekday
+	byMonthDay []int
+	until      *time.Time
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRrule parses an RRULE such as FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU. The
+// FREQ, INTERVAL, BYDAY, BYMONTHDAY and UNTIL parts are supported.
+func parseRrule(s string) (*rrule, error) {
+	rule := &rrule{interval: 1}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "RRULE:"), ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch strings.ToUpper(value) {
+			case "DAILY":
+				rule.freq = RepeatTypeDaily
+			case "WEEKLY":
+				rule.freq = RepeatTypeWeekly
+			case "MONTHLY":
+				rule.freq = RepeatTypeMonthly
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			rule.interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", day)
+				}
+				rule.byDay = append(rule.byDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", day)
+				}
+				rule.byMonthDay = append(rule.byMonthDay, monthDay)
+			}
+		case "UNTIL":
+			until, err := time.Parse("20060102T150405Z", value)
+			if err != nil {
+				if until, err = time.Parse("20060102", value); err != nil {
+					return nil, fmt.Errorf("invalid UNTIL %q", value)
+				}
+				// the occurrences on the day are included
+				until = until.Add(24*time.Hour - time.Nanosecond)
+			}
+			rule.until = &until
+		case "COUNT":
+			return nil, errors.New("COUNT is not supported, use UNTIL")
+		default:
+			return nil, fmt.Errorf("unsupported part %q", key)
+		}
+	}
+
+	if rule.freq == "" {
+		return nil, errors.New("missing FREQ")
+	}
+	return rule, nil
+}
+
+// covers reports whether an occurrence of the rule, starting from start and
+// lasting for the duration, covers the time
+func (r *rrule) covers(start time.Time, duration time.Duration, t time.Time) bool {
+	if t.Before(start) || duration <= 0 {
+		return false
+	}
+	loc := start.Location()
+	t = t.In(loc)
+
+	// the occurrences which can cover the time start on the days from the
+	// duration before it
+	from := t.Add(-duration)
+	if from.Before(start) {
+		from = start
+	}
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	for i := 0; i <= maxRruleWindowInDays && !day.After(t); i++ {
+		occurrence := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+		if !occurrence.Before(start) && !occurrence.After(t) && t.Before(occurrence.Add(duration)) &&
+			(r.until == nil || !occurrence.After(*r.until)) && r.occursOn(start, day) {
+			return true
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return false
+}
+
+// occursOn reports whether the rule has an occurrence on the day
+func (r *rrule) occursOn(start time.Time, day time.Time) bool {
+	startDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	byDay, byMonthDay := r.byDay, r.byMonthDay
+	switch r.freq {
+	case RepeatTypeDaily:
+		if int(date.Sub(startDate).Hours()/24)%r.interval != 0 {
+			return false
+		}
+	case RepeatTypeWeekly:
+		// the weeks start on monday
+		startWeek := startDate.AddDate(0, 0, -((int(startDate.Weekday()) + 6) % 7))
+		week := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		if int(week.Sub(startWeek).Hours()/(24*7))%r.interval != 0 {
+			return false
+		}
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{start.Weekday()}
+		}
+	case RepeatTypeMonthly:
+		months := (date.Year()-startDate.Year())*12 + int(date.Month()) - int(startDate.Month())
+		if months%r.interval != 0 {
+			return false
+		}
+		if len(byDay) == 0 && len(byMonthDay) == 0 {
+			byMonthDay = []int{start.Day()}
+		}
+	}
+
+	if len(byDay) > 0 && !slices.Contains(byDay, date.Weekday()) {
+		return false
+	}
+	if len(byMonthDay) > 0 {
+		daysInMonth := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		matched := false
+		for _, monthDay := range byMonthDay {
+			if monthDay < 0 {
+				monthDay = daysInMonth + 1 + monthDay
+			}
+			if monthDay == date.Day() {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
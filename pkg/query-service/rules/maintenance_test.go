@@ -1,8 +1,12 @@
 package rules
 
 import (
+	"context"
 	"testing"
 	"time"
+
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
 )
 
 func TestShouldSkipMaintenance(t *testing.T) {
@@ -228,3 +232,132 @@ func TestShouldSkipMaintenance(t *testing.T) {
 		}
 	}
 }
+
+func TestRruleMaintenance(t *testing.T) {
+
+	// every other weekend from 22:00 for 4 hours, starting on saturday
+	weekends := &PlannedMaintenance{
+		Schedule: &Schedule{
+			Timezone: "UTC",
+			Recurrence: &Recurrence{
+				StartTime: time.Date(2024, 1, 6, 22, 0, 0, 0, time.UTC),
+				Duration:  Duration(time.Hour * 4),
+				Rrule:     "FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU;UNTIL=20240229",
+			},
+		},
+	}
+	// the last day of every month from 23:00 for 2 hours
+	monthEnds := &PlannedMaintenance{
+		Schedule: &Schedule{
+			Timezone: "UTC",
+			Recurrence: &Recurrence{
+				StartTime: time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC),
+				Duration:  Duration(time.Hour * 2),
+				Rrule:     "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1",
+			},
+		},
+	}
+
+	cases := []struct {
+		name        string
+		maintenance *PlannedMaintenance
+		ts          time.Time
+		expected    bool
+	}{
+		{name: "first saturday", maintenance: weekends, ts: time.Date(2024, 1, 6, 23, 0, 0, 0, time.UTC), expected: true},
+		{name: "first saturday past midnight", maintenance: weekends, ts: time.Date(2024, 1, 7, 1, 0, 0, 0, time.UTC), expected: true},
+		{name: "first sunday", maintenance: weekends, ts: time.Date(2024, 1, 7, 22, 30, 0, 0, time.UTC), expected: true},
+		{name: "first sunday before the window", maintenance: weekends, ts: time.Date(2024, 1, 7, 21, 0, 0, 0, time.UTC), expected: false},
+		{name: "skipped weekend", maintenance: weekends, ts: time.Date(2024, 1, 13, 23, 0, 0, 0, time.UTC), expected: false},
+		{name: "third weekend", maintenance: weekends, ts: time.Date(2024, 1, 20, 23, 0, 0, 0, time.UTC), expected: true},
+		{name: "weekday", maintenance: weekends, ts: time.Date(2024, 1, 17, 23, 0, 0, 0, time.UTC), expected: false},
+		{name: "after until", maintenance: weekends, ts: time.Date(2024, 3, 2, 23, 0, 0, 0, time.UTC), expected: false},
+		{name: "end of february", maintenance: monthEnds, ts: time.Date(2024, 2, 29, 23, 30, 0, 0, time.UTC), expected: true},
+		{name: "end of february past midnight", maintenance: monthEnds, ts: time.Date(2024, 3, 1, 0, 30, 0, 0, time.UTC), expected: true},
+		{name: "middle of march", maintenance: monthEnds, ts: time.Date(2024, 3, 15, 23, 30, 0, 0, time.UTC), expected: false},
+	}
+
+	for _, c := range cases {
+		result := c.maintenance.shouldSkip(c.name, c.ts)
+		if result != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, result)
+		}
+	}
+
+	for _, rrule := range []string{"FREQ=YEARLY", "FREQ=DAILY;COUNT=3", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=MONTHLY;BYMONTHDAY=32"} {
+		maintenance := &PlannedMaintenance{
+			Name: "invalid",
+			Schedule: &Schedule{
+				Timezone:   "UTC",
+				Recurrence: &Recurrence{Duration: Duration(time.Hour), Rrule: rrule, StartTime: time.Now()},
+			},
+		}
+		if maintenance.Validate() == nil {
+			t.Errorf("expected rrule %q to be invalid", rrule)
+		}
+	}
+}
+
+type recordingRuleDB struct {
+	RuleDB
+	suppressed map[int64]int
+	skipped    map[int64]int
+}
+
+func (r *recordingRuleDB) RecordMaintenanceSuppression(ctx context.Context, maintenanceId int64, ruleId string, suppressedAlerts int, skippedEvaluations int) error {
+	r.suppressed[maintenanceId] += suppressedAlerts
+	r.skipped[maintenanceId] += skippedEvaluations
+	return nil
+}
+
+func TestScopedMaintenance(t *testing.T) {
+	now := time.Now().UTC()
+	scoped := PlannedMaintenance{
+		Id: 1,
+		Schedule: &Schedule{
+			Timezone:  "UTC",
+			StartTime: now.Add(-time.Hour),
+			EndTime:   now.Add(time.Hour),
+		},
+		Matchers: &MaintenanceMatchers{
+			{Name: "service", Op: MatcherOpEqual, Value: "checkout"},
+			{Name: "env", Op: MatcherOpRegexp, Value: "staging|dev"},
+		},
+	}
+
+	if scoped.shouldSkip("1", now) {
+		t.Errorf("expected the rule to be evaluated during a scoped maintenance")
+	}
+	if !scoped.IsActive(now) {
+		t.Errorf("expected the scoped maintenance to be active")
+	}
+
+	ruleDB := &recordingRuleDB{suppressed: map[int64]int{}, skipped: map[int64]int{}}
+	var notified []*Alert
+	notify := notifyOutsideMaintenance(ruleDB, []PlannedMaintenance{scoped}, "1", now, func(ctx context.Context, expr string, alerts ...*Alert) {
+		notified = alerts
+	})
+
+	notify(context.Background(), "",
+		&Alert{State: model.StateFiring, Labels: labels.Labels{{Name: "service", Value: "checkout"}, {Name: "env", Value: "staging"}}},
+		&Alert{State: model.StateFiring, Labels: labels.Labels{{Name: "service", Value: "checkout"}, {Name: "env", Value: "production"}}},
+		&Alert{State: model.StateFiring, Labels: labels.Labels{{Name: "service", Value: "cart"}, {Name: "env", Value: "dev"}}},
+	)
+
+	if len(notified) != 2 {
+		t.Fatalf("expected 2 alerts to be notified, got %d", len(notified))
+	}
+	for _, alert := range notified {
+		if alert.Labels.Get("service") == "checkout" && alert.Labels.Get("env") == "staging" {
+			t.Errorf("expected the alert matching the maintenance to be suppressed")
+		}
+	}
+	if ruleDB.suppressed[1] != 1 {
+		t.Errorf("expected 1 suppressed alert, got %d", ruleDB.suppressed[1])
+	}
+
+	invalid := MaintenanceMatcher{Name: "env", Op: MatcherOpRegexp, Value: "("}
+	if invalid.Validate() == nil {
+		t.Errorf("expected the invalid regexp to fail validation")
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"go.signoz.io/signoz/pkg/query-service/model"
 	pqle "go.signoz.io/signoz/pkg/query-service/pqlEngine"
 	"go.signoz.io/signoz/pkg/query-service/telemetry"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
 )
 
 type PrepareTaskOptions struct {
@@ -90,6 +91,9 @@ type ManagerOptions struct {
 	Cache        cache.Cache
 
 	EvalDelay time.Duration
+	// EvalJitter is the most the evaluations of the rules are put off by at
+	// random, so the rules evaluating at the same time don't query together
+	EvalJitter time.Duration
 
 	PrepareTaskFunc func(opts PrepareTaskOptions) (Task, error)
 
@@ -107,6 +111,9 @@ type Manager struct {
 	block chan struct{}
 	// Notifier sends messages through alert manager
 	notifier *am.Notifier
+	// rateLimiter holds back the notifications of the channels over their
+	// rate limit
+	rateLimiter *channelRateLimiter
 
 	// datastore to store alert definitions
 	ruleDB RuleDB
@@ -192,8 +199,27 @@ func defaultPrepareTaskFunc(opts PrepareTaskOptions) (Task, error) {
 		// create promql rule task for evalution
 		task = newTask(TaskTypeProm, opts.TaskName, taskNamesuffix, time.Duration(opts.Rule.Frequency), rules, opts.ManagerOpts, opts.NotifyFunc, opts.RuleDB)
 
+	} else if opts.Rule.RuleType == RuleTypeLogPattern {
+		// create a log pattern rule
+		lr, err := NewLogPatternRule(
+			ruleId,
+			opts.Rule,
+			opts.FF,
+			opts.Reader,
+			opts.RuleDB,
+			WithEvalDelay(opts.ManagerOpts.EvalDelay),
+		)
+		if err != nil {
+			return task, err
+		}
+
+		rules = append(rules, lr)
+
+		// create ch rule task for evalution
+		task = newTask(TaskTypeCh, opts.TaskName, taskNamesuffix, time.Duration(opts.Rule.Frequency), rules, opts.ManagerOpts, opts.NotifyFunc, opts.RuleDB)
+
 	} else {
-		return nil, fmt.Errorf("unsupported rule type %s. Supported types: %s, %s", opts.Rule.RuleType, RuleTypeProm, RuleTypeThreshold)
+		return nil, fmt.Errorf("unsupported rule type %s. Supported types: %s, %s, %s", opts.Rule.RuleType, RuleTypeProm, RuleTypeThreshold, RuleTypeLogPattern)
 	}
 
 	return task, nil
@@ -226,6 +252,7 @@ func NewManager(o *ManagerOptions) (*Manager, error) {
 		tasks:               map[string]Task{},
 		rules:               map[string]Rule{},
 		notifier:            notifier,
+		rateLimiter:         newChannelRateLimiter(),
 		ruleDB:              db,
 		opts:                o,
 		block:               make(chan struct{}),
@@ -267,6 +294,7 @@ func (m *Manager) initiate() error {
 		return nil
 	}
 	var loadErrors []error
+	pauses := m.rulePauses(context.Background())
 
 	for _, rec := range storedRules {
 		taskName := fmt.Sprintf("%d-groupname", rec.Id)
@@ -292,7 +320,7 @@ func (m *Manager) initiate() error {
 				continue
 			}
 		}
-		if !parsedRule.Disabled {
+		if _, paused := pauses[fmt.Sprintf("%d", rec.Id)]; !parsedRule.Disabled && !paused {
 			err := m.addTask(parsedRule, taskName)
 			if err != nil {
 				zap.L().Error("failed to load the rule definition", zap.String("name", taskName), zap.Error(err))
@@ -312,6 +340,12 @@ func (m *Manager) run() {
 	// initiate notifier
 	go m.notifier.Run()
 
+	go m.sendRateLimitSummaries()
+
+	go m.resumePausedRules()
+
+	go m.sendDueDigests()
+
 	// initiate blocked tasks
 	close(m.block)
 }
@@ -339,6 +373,15 @@ func (m *Manager) EditRule(ctx context.Context, ruleStr string, id string) error
 	if err != nil {
 		return err
 	}
+	if err := m.checkEscalationPolicy(ctx, parsedRule); err != nil {
+		return err
+	}
+	if err := m.checkStoredRuleTeam(ctx, id); err != nil {
+		return err
+	}
+	if err := m.checkRuleTeam(ctx, parsedRule.Team); err != nil {
+		return err
+	}
 
 	taskName, _, err := m.ruleDB.EditRuleTx(ctx, ruleStr, id)
 	if err != nil {
@@ -417,6 +460,9 @@ func (m *Manager) DeleteRule(ctx context.Context, id string) error {
 		zap.L().Error("delete rule received an rule id in invalid format, must be a number", zap.String("id", id), zap.Error(err))
 		return fmt.Errorf("delete rule received an rule id in invalid format, must be a number")
 	}
+	if err := m.checkStoredRuleTeam(ctx, id); err != nil {
+		return err
+	}
 
 	taskName := prepareTaskName(int64(idInt))
 	if !m.opts.DisableRules {
@@ -455,6 +501,12 @@ func (m *Manager) CreateRule(ctx context.Context, ruleStr string) (*GettableRule
 	if err != nil {
 		return nil, err
 	}
+	if err := m.checkEscalationPolicy(ctx, parsedRule); err != nil {
+		return nil, err
+	}
+	if err := m.checkRuleTeam(ctx, parsedRule.Team); err != nil {
+		return nil, err
+	}
 
 	lastInsertId, tx, err := m.ruleDB.CreateRuleTx(ctx, ruleStr)
 	taskName := prepareTaskName(lastInsertId)
@@ -571,6 +623,19 @@ func (m *Manager) Rules() []Rule {
 	return rules
 }
 
+// RuleActiveAlerts returns the active alerts of the rule, none if the rule
+// is not running
+func (m *Manager) RuleActiveAlerts(id string) []*Alert {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	r, ok := m.rules[id]
+	if !ok {
+		return nil
+	}
+	return r.ActiveAlerts()
+}
+
 // TriggeredAlerts returns the list of the manager's rules.
 func (m *Manager) TriggeredAlerts() []*NamedAlert {
 	// m.mtx.RLock()
@@ -600,34 +665,114 @@ type NotifyFunc func(ctx context.Context, expr string, alerts ...*Alert)
 func (m *Manager) prepareNotifyFunc() NotifyFunc {
 	return func(ctx context.Context, expr string, alerts ...*Alert) {
 		var res []*am.Alert
+		now := time.Now()
+		limits := m.channelRateLimits(ctx)
+		routing := m.notificationRouting(ctx)
+		resolvedSettings := m.channelResolvedNotifications(ctx)
 
 		for _, alert := range alerts {
-			generatorURL := alert.GeneratorURL
-			if generatorURL == "" {
-				generatorURL = m.opts.RepoURL
+			channels := routedChannels(routing, alert)
+			receivers := resolveOnCallChannels(ctx, m.ruleDB, channels, now)
+			// the alerts without channels go to all of them, which are
+			// listed to leave some out
+			listed := len(channels) > 0
+			if !alert.ResolvedAt.IsZero() && len(resolvedSettings) > 0 {
+				if !listed {
+					receivers, listed = m.channelNames(), true
+				}
+				receivers = filterResolvedChannels(resolvedSettings, receivers, alert)
 			}
-
-			a := &am.Alert{
-				StartsAt:     alert.FiredAt,
-				Labels:       alert.Labels,
-				Annotations:  alert.Annotations,
-				GeneratorURL: generatorURL,
-				Receivers:    alert.Receivers,
+			if len(limits) > 0 && m.rateLimiter != nil {
+				if !listed {
+					receivers, listed = m.channelNames(), true
+				}
+				receivers = m.rateLimiter.filter(limits, receivers, alert, now)
 			}
-			if !alert.ResolvedAt.IsZero() {
-				a.EndsAt = alert.ResolvedAt
-			} else {
-				a.EndsAt = alert.ValidUntil
+			// an alert left without channels would be sent to all of them
+			if len(receivers) == 0 && (listed || len(limits) > 0) {
+				continue
 			}
-			res = append(res, a)
+			res = append(res, m.notifierAlert(alert, receivers))
 		}
 
-		if len(alerts) > 0 {
+		if len(res) > 0 {
 			m.notifier.Send(res...)
 		}
 	}
 }
 
+// notifierAlert converts the alert to the alert sent to the alert manager
+func (m *Manager) notifierAlert(alert *Alert, receivers []string) *am.Alert {
+	generatorURL := alert.GeneratorURL
+	if generatorURL == "" {
+		generatorURL = m.opts.RepoURL
+	}
+
+	annotations := alert.Annotations.Map()
+	annotations[labels.AlertDedupKeyLabel] = alert.DedupKey()
+
+	a := &am.Alert{
+		StartsAt:     alert.FiredAt,
+		Labels:       alert.Labels,
+		Annotations:  labels.FromMap(annotations),
+		GeneratorURL: generatorURL,
+		Receivers:    receivers,
+	}
+	if !alert.ResolvedAt.IsZero() {
+		a.EndsAt = alert.ResolvedAt
+	} else {
+		a.EndsAt = alert.ValidUntil
+	}
+	return a
+}
+
+// channelRateLimits returns the rate limits of the channels by channel name
+func (m *Manager) channelRateLimits(ctx context.Context) map[string]ChannelRateLimit {
+	all, err := m.ruleDB.GetAllChannelRateLimits(ctx)
+	if err != nil {
+		zap.L().Error("failed to get the rate limits of the channels", zap.Error(err))
+		return nil
+	}
+	limits := make(map[string]ChannelRateLimit, len(all))
+	for _, limit := range all {
+		limits[limit.Channel] = limit
+	}
+	return limits
+}
+
+// channelNames returns the names of all the channels
+func (m *Manager) channelNames() []string {
+	channels, apiErr := m.ruleDB.GetChannels()
+	if apiErr != nil {
+		zap.L().Error("failed to get the channels", zap.Error(apiErr.Err))
+		return nil
+	}
+	names := make([]string, 0, len(*channels))
+	for _, channel := range *channels {
+		names = append(names, channel.Name)
+	}
+	return names
+}
+
+// sendRateLimitSummaries sends the channels which are below their rate limit
+// again the summary of the notifications they were not sent
+func (m *Manager) sendRateLimitSummaries() {
+	tick := time.NewTicker(30 * time.Second)
+	defer tick.Stop()
+
+	for range tick.C {
+		summaries := m.rateLimiter.summaries(m.channelRateLimits(context.Background()), time.Now())
+		if len(summaries) == 0 {
+			continue
+		}
+		res := make([]*am.Alert, 0, len(summaries))
+		for _, summary := range summaries {
+			res = append(res, m.notifierAlert(summary, summary.Receivers))
+		}
+		m.notifier.Send(res...)
+	}
+}
+
 func (m *Manager) ListActiveRules() ([]Rule, error) {
 	ruleList := []Rule{}
 
@@ -646,6 +791,8 @@ func (m *Manager) ListRuleStates(ctx context.Context) (*GettableRules, error) {
 		return nil, err
 	}
 
+	pauses := m.rulePauses(ctx)
+
 	// initiate response object
 	resp := make([]*GettableRule, 0)
 
@@ -660,7 +807,10 @@ func (m *Manager) ListRuleStates(ctx context.Context) (*GettableRules, error) {
 		ruleResponse.Id = fmt.Sprintf("%d", s.Id)
 
 		// fetch state of rule from memory
-		if rm, ok := m.rules[ruleResponse.Id]; !ok {
+		if pause, ok := pauses[ruleResponse.Id]; ok {
+			ruleResponse.State = model.StatePaused
+			ruleResponse.Pause = pause
+		} else if rm, ok := m.rules[ruleResponse.Id]; !ok {
 			ruleResponse.State = model.StateDisabled
 			ruleResponse.Disabled = true
 		} else {
@@ -686,8 +836,15 @@ func (m *Manager) GetRule(ctx context.Context, id string) (*GettableRule, error)
 		return nil, err
 	}
 	r.Id = fmt.Sprintf("%d", s.Id)
+	pause, err := m.ruleDB.GetActiveRulePause(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 	// fetch state of rule from memory
-	if rm, ok := m.rules[r.Id]; !ok {
+	if pause != nil {
+		r.State = model.StatePaused
+		r.Pause = pause
+	} else if rm, ok := m.rules[r.Id]; !ok {
 		r.State = model.StateDisabled
 		r.Disabled = true
 	} else {
@@ -697,16 +854,38 @@ func (m *Manager) GetRule(ctx context.Context, id string) (*GettableRule, error)
 	r.CreatedBy = s.CreatedBy
 	r.UpdatedAt = s.UpdatedAt
 	r.UpdatedBy = s.UpdatedBy
+	if r.EscalationPolicyId != 0 {
+		escalations, err := m.ruleDB.GetAlertEscalations(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		r.Escalations = escalations
+	}
 
 	return r, nil
 }
 
+// RestoreRuleVersion edits the rule back to a saved version of it. The
+// restore is saved as a new version, so it can be undone the same way.
+func (m *Manager) RestoreRuleVersion(ctx context.Context, id string, version int) (*GettableRule, error) {
+	ruleVersion, err := m.ruleDB.GetRuleVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.EditRule(ctx, ruleVersion.Data, id); err != nil {
+		return nil, err
+	}
+
+	return m.GetRule(ctx, id)
+}
+
 // syncRuleStateWithTask ensures that the state of a stored rule matches
-// the task state. For example - if a stored rule is disabled, then
-// there is no task running against it.
+// the task state. For example - if a stored rule is disabled or paused,
+// then there is no task running against it.
 func (m *Manager) syncRuleStateWithTask(taskName string, rule *PostableRule) error {
 
-	if rule.Disabled {
+	if rule.Disabled || m.isPaused(context.Background(), RuleIdFromTaskName(taskName)) {
 		// check if rule has any task running
 		if _, ok := m.tasks[taskName]; ok {
 			// delete task from memory
@@ -757,11 +936,18 @@ func (m *Manager) PatchRule(ctx context.Context, ruleStr string, ruleId string)
 		return nil, err
 	}
 
+	if err := m.checkRuleTeam(ctx, storedRule.Team); err != nil {
+		return nil, err
+	}
+
 	// patchedRule is combo of stored rule and patch received in the request
 	patchedRule, err := parseIntoRule(storedRule, []byte(ruleStr), "json")
 	if err != nil {
 		return nil, err
 	}
+	if err := m.checkRuleTeam(ctx, patchedRule.Team); err != nil {
+		return nil, err
+	}
 
 	// deploy or un-deploy task according to patched (new) rule state
 	if err := m.syncRuleStateWithTask(taskName, patchedRule); err != nil {
@@ -794,7 +980,10 @@ func (m *Manager) PatchRule(ctx context.Context, ruleStr string, ruleId string)
 	}
 
 	// fetch state of rule from memory
-	if rm, ok := m.rules[ruleId]; !ok {
+	if pause, err := m.ruleDB.GetActiveRulePause(ctx, ruleId); err == nil && pause != nil {
+		response.State = model.StatePaused
+		response.Pause = pause
+	} else if rm, ok := m.rules[ruleId]; !ok {
 		response.State = model.StateDisabled
 		response.Disabled = true
 	} else {
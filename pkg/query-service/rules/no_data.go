@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"fmt"
+
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+)
+
+// NoDataAction is what a rule does when its query returns no data, or when
+// the series of one of its alerts is absent from the query result
+type NoDataAction string
+
+const (
+	// NoDataActionFire fires the alert as if the condition was met
+	NoDataActionFire NoDataAction = "fire"
+	// NoDataActionResolve resolves the alerts
+	NoDataActionResolve NoDataAction = "resolve"
+	// NoDataActionKeepState keeps the alerts in the state they are in until
+	// the data is back
+	NoDataActionKeepState NoDataAction = "keep_state"
+	// NoDataActionNoData fires a no data alert
+	NoDataActionNoData NoDataAction = "no_data"
+)
+
+// lastSeenLabel is the label of the no data alerts with the time the query
+// last returned data
+const lastSeenLabel = "lastSeen"
+
+func (a NoDataAction) Validate() error {
+	switch a {
+	case "", NoDataActionFire, NoDataActionResolve, NoDataActionKeepState, NoDataActionNoData:
+		return nil
+	}
+	return fmt.Errorf("invalid no data action: %s", a)
+}
+
+// noDataAction returns what the rule does when the query returns no data for
+// AbsentFor minutes. The rules without one fire a no data alert when they
+// alert on absent data, and resolve their alerts otherwise.
+func (rc *RuleCondition) noDataAction() NoDataAction {
+	if rc.NoDataAction != "" {
+		return rc.NoDataAction
+	}
+	if rc.AlertOnAbsent {
+		return NoDataActionNoData
+	}
+	return NoDataActionResolve
+}
+
+// absentSeriesAction returns what the rule does with the alerts whose series
+// is absent from the query result while the query still returns data
+func (rc *RuleCondition) absentSeriesAction() NoDataAction {
+	if rc.AbsentSeriesAction != "" {
+		return rc.AbsentSeriesAction
+	}
+	return NoDataActionResolve
+}
+
+// validateNoDataActions checks the no data actions of the rule, which only
+// the threshold rules support
+func validateNoDataActions(r *PostableRule) (errs []error) {
+	rc := r.RuleCondition
+	for _, action := range []NoDataAction{rc.NoDataAction, rc.AbsentSeriesAction} {
+		if err := action.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if rc.NoDataAction == "" && rc.AbsentSeriesAction == "" {
+		return errs
+	}
+	if r.RuleType != RuleTypeThreshold {
+		errs = append(errs, fmt.Errorf("no data actions are supported only by threshold rules"))
+	}
+	if rc.AlertOnAbsent && rc.NoDataAction != "" && rc.NoDataAction != NoDataActionNoData {
+		errs = append(errs, fmt.Errorf("alert on absent conflicts with the no data action %s", rc.NoDataAction))
+	}
+	return errs
+}
+
+// seriesFingerprint is the fingerprint of the query result labels of the
+// alerts of the series
+func seriesFingerprint(series *v3.Series) uint64 {
+	lbls := labels.Labels{}
+	for name, value := range series.Labels {
+		lbls = append(lbls, labels.Label{Name: name, Value: value})
+	}
+	return labels.NewBuilder(lbls).Del(labels.MetricNameLabel).Del(labels.TemporalityLabel).Labels().Hash()
+}
+
+// isNoDataAlert tells if the alert is the alert the rule fires when its query
+// returns no data, which has no labels of a series
+func isNoDataAlert(a *Alert) bool {
+	lbls := a.QueryResultLables.Map()
+	delete(lbls, lastSeenLabel)
+	return len(lbls) == 0
+}
+
+// absentAction returns what is done with the active alert missing from the
+// samples of the last evaluation. The alerts of the series still in the query
+// result no longer meet the condition and are resolved, as is the no data
+// alert once the data is back.
+func (r *ThresholdRule) absentAction(a *Alert) NoDataAction {
+	switch {
+	case r.noData:
+		if action := r.ruleCondition.noDataAction(); action == NoDataActionKeepState {
+			return action
+		}
+		// the no data alert replaces the alerts of the series
+		return NoDataActionResolve
+	case r.seriesFPs == nil:
+		return NoDataActionResolve
+	}
+	if _, ok := r.seriesFPs[a.QueryResultLables.Hash()]; ok || isNoDataAlert(a) {
+		return NoDataActionResolve
+	}
+	return r.ruleCondition.absentSeriesAction()
+}
@@ -0,0 +1,328 @@
+package rules
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+// OnCallChannelPrefix marks a channel of a rule or an escalation step as the
+// on-call of a schedule, oncall:<schedule name> notifies the channel of
+// whoever is on call when the alert is sent
+const OnCallChannelPrefix = "oncall:"
+
+var ErrMissingRotations = errors.New("missing rotations")
+
+// OnCallSchedule rotates the participants on call. The rotations are layers,
+// a later rotation taking over from the ones before it while it runs, and the
+// overrides take over from all of them.
+type OnCallSchedule struct {
+	Id          int64  `json:"id" db:"id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+	// Timezone is the time zone of the handoffs, the times of the rotations
+	// are wall clock times in it
+	Timezone  string           `json:"timezone" db:"timezone"`
+	Rotations *OnCallRotations `json:"rotations" db:"rotations"`
+	Overrides *OnCallOverrides `json:"overrides" db:"overrides"`
+	CreatedAt time.Time        `json:"createdAt" db:"created_at"`
+	CreatedBy string           `json:"createdBy" db:"created_by"`
+	UpdatedAt time.Time        `json:"updatedAt" db:"updated_at"`
+	UpdatedBy string           `json:"updatedBy" db:"updated_by"`
+}
+
+// OnCallRotation hands off from one participant to the next every shift,
+// starting with the first participant at the start time. The participants
+// are the channels notified while they are on call.
+type OnCallRotation struct {
+	Name         string     `json:"name"`
+	Participants []string   `json:"participants"`
+	StartTime    time.Time  `json:"startTime"`
+	EndTime      *time.Time `json:"endTime,omitempty"`
+	// ShiftLength is the length of the shifts, the shifts of whole days hand
+	// off at the same time of day across daylight saving changes
+	ShiftLength Duration `json:"shiftLength"`
+}
+
+// OnCallOverride puts the participant on call from the start to the end time
+type OnCallOverride struct {
+	Participant string    `json:"participant"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+}
+
+type OnCallRotations []OnCallRotation
+
+func (r *OnCallRotations) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, r)
+	}
+	return nil
+}
+
+func (r *OnCallRotations) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+type OnCallOverrides []OnCallOverride
+
+func (o *OnCallOverrides) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, o)
+	}
+	return nil
+}
+
+func (o *OnCallOverrides) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+// OnCallShift is a participant on call from the start to the end time, the
+// end time being the next handoff of the rotation
+type OnCallShift struct {
+	Participant string    `json:"participant"`
+	Rotation    string    `json:"rotation,omitempty"`
+	Override    bool      `json:"override,omitempty"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+}
+
+func (s *OnCallSchedule) Validate() error {
+	if s.Name == "" {
+		return ErrMissingName
+	}
+	if strings.ContainsAny(s.Name, ",:") {
+		return fmt.Errorf("the name of the schedule can't contain a comma or colon")
+	}
+	if s.Timezone == "" {
+		return ErrMissingTimezone
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	if s.Rotations == nil || len(*s.Rotations) == 0 {
+		return ErrMissingRotations
+	}
+
+	for i, rotation := range *s.Rotations {
+		if len(rotation.Participants) == 0 {
+			return fmt.Errorf("rotation %d has no participants", i+1)
+		}
+		if rotation.StartTime.IsZero() {
+			return fmt.Errorf("rotation %d has no start time", i+1)
+		}
+		if rotation.EndTime != nil && !rotation.EndTime.After(rotation.StartTime) {
+			return fmt.Errorf("rotation %d ends before it starts", i+1)
+		}
+		if time.Duration(rotation.ShiftLength) < time.Hour {
+			return fmt.Errorf("the shifts of rotation %d must be at least an hour long", i+1)
+		}
+	}
+	if s.Overrides != nil {
+		for i, override := range *s.Overrides {
+			if override.Participant == "" {
+				return fmt.Errorf("override %d has no participant", i+1)
+			}
+			if !override.EndTime.After(override.StartTime) {
+				return fmt.Errorf("override %d ends before it starts", i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// OnCallAt returns the shift on call at the time, false when no one is
+func (s *OnCallSchedule) OnCallAt(ts time.Time) (OnCallShift, bool) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return OnCallShift{}, false
+	}
+
+	if s.Overrides != nil {
+		for _, override := range *s.Overrides {
+			start, end := inLocation(override.StartTime, loc), inLocation(override.EndTime, loc)
+			if !ts.Before(start) && ts.Before(end) {
+				return OnCallShift{Participant: override.Participant, Override: true, StartTime: start, EndTime: end}, true
+			}
+		}
+	}
+
+	if s.Rotations == nil {
+		return OnCallShift{}, false
+	}
+	rotations := *s.Rotations
+	for i := len(rotations) - 1; i >= 0; i-- {
+		if shift, ok := rotations[i].shiftAt(ts, loc); ok {
+			return shift, true
+		}
+	}
+	return OnCallShift{}, false
+}
+
+// shiftAt returns the shift of the rotation at the time, false before the
+// rotation starts or after it ends
+func (r *OnCallRotation) shiftAt(ts time.Time, loc *time.Location) (OnCallShift, bool) {
+	start := inLocation(r.StartTime, loc)
+	if ts.Before(start) || len(r.Participants) == 0 || r.ShiftLength <= 0 {
+		return OnCallShift{}, false
+	}
+	var end time.Time
+	if r.EndTime != nil {
+		end = inLocation(*r.EndTime, loc)
+		if !ts.Before(end) {
+			return OnCallShift{}, false
+		}
+	}
+
+	length := time.Duration(r.ShiftLength)
+	handoff := func(n int) time.Time {
+		if length%(24*time.Hour) == 0 {
+			return start.AddDate(0, 0, n*int(length/(24*time.Hour)))
+		}
+		return start.Add(time.Duration(n) * length)
+	}
+
+	// the estimate of the shift is off by one around daylight saving changes
+	n := int(ts.Sub(start) / length)
+	for n > 0 && handoff(n).After(ts) {
+		n--
+	}
+	for !handoff(n + 1).After(ts) {
+		n++
+	}
+
+	shift := OnCallShift{
+		Participant: r.Participants[n%len(r.Participants)],
+		Rotation:    r.Name,
+		StartTime:   handoff(n),
+		EndTime:     handoff(n + 1),
+	}
+	if !end.IsZero() && shift.EndTime.After(end) {
+		shift.EndTime = end
+	}
+	return shift, true
+}
+
+// inLocation reads the wall clock of the time in the location, as the times
+// of the schedules are given
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// resolveOnCallChannels replaces the on-call channels with the channel of
+// the participant on call now. The channels of the schedules which are
+// missing or have no one on call are dropped.
+func resolveOnCallChannels(ctx context.Context, ruleDB RuleDB, channels []string, ts time.Time) []string {
+	var schedules map[string]*OnCallSchedule
+	resolved := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		name, ok := strings.CutPrefix(channel, OnCallChannelPrefix)
+		if !ok {
+			resolved = append(resolved, channel)
+			continue
+		}
+
+		if schedules == nil {
+			schedules = map[string]*OnCallSchedule{}
+			all, err := ruleDB.GetAllOnCallSchedules(ctx)
+			if err != nil {
+				zap.L().Error("failed to get the on-call schedules", zap.Error(err))
+			}
+			for i := range all {
+				schedules[all[i].Name] = &all[i]
+			}
+		}
+
+		schedule, ok := schedules[name]
+		if !ok {
+			zap.L().Warn("on-call schedule of the channel not found", zap.String("channel", channel))
+			continue
+		}
+		shift, ok := schedule.OnCallAt(ts)
+		if !ok {
+			zap.L().Warn("no one is on call in the schedule", zap.String("schedule", name))
+			continue
+		}
+		resolved = append(resolved, shift.Participant)
+	}
+	return resolved
+}
+
+func (r *ruleDB) GetAllOnCallSchedules(ctx context.Context) ([]OnCallSchedule, error) {
+	schedules := []OnCallSchedule{}
+
+	err := r.Select(&schedules, "SELECT * FROM oncall_schedules ORDER BY name")
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (r *ruleDB) GetOnCallScheduleByID(ctx context.Context, id string) (*OnCallSchedule, error) {
+	schedule := &OnCallSchedule{}
+
+	err := r.Get(schedule, "SELECT * FROM oncall_schedules WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (r *ruleDB) CreateOnCallSchedule(ctx context.Context, schedule OnCallSchedule) (int64, error) {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return 0, errors.New("no claims found in context")
+	}
+	schedule.CreatedBy = claims.Email
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedBy = claims.Email
+	schedule.UpdatedAt = time.Now()
+
+	query := "INSERT INTO oncall_schedules (name, description, timezone, rotations, overrides, created_at, created_by, updated_at, updated_by) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	result, err := r.Exec(query, schedule.Name, schedule.Description, schedule.Timezone, schedule.Rotations, schedule.Overrides, schedule.CreatedAt, schedule.CreatedBy, schedule.UpdatedAt, schedule.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *ruleDB) EditOnCallSchedule(ctx context.Context, schedule OnCallSchedule, id string) error {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("no claims found in context")
+	}
+	schedule.UpdatedBy = claims.Email
+	schedule.UpdatedAt = time.Now()
+
+	query := "UPDATE oncall_schedules SET name=$1, description=$2, timezone=$3, rotations=$4, overrides=$5, updated_at=$6, updated_by=$7 WHERE id=$8"
+	_, err := r.Exec(query, schedule.Name, schedule.Description, schedule.Timezone, schedule.Rotations, schedule.Overrides, schedule.UpdatedAt, schedule.UpdatedBy, id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleDB) DeleteOnCallSchedule(ctx context.Context, id string) error {
+	_, err := r.Exec("DELETE FROM oncall_schedules WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
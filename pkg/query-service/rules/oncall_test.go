@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnCallSchedule(t *testing.T) {
+	require := require.New(t)
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(err)
+	until := time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC)
+	schedule := OnCallSchedule{
+		Name:     "checkout",
+		Timezone: "Europe/Berlin",
+		Rotations: &OnCallRotations{
+			{
+				Name:         "weekly",
+				Participants: []string{"alice-slack", "bob-email"},
+				// the wall clock in the time zone of the schedule, 09:00 in Berlin
+				StartTime:   time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC),
+				ShiftLength: Duration(7 * 24 * time.Hour),
+			},
+			{
+				Name:         "weekend",
+				Participants: []string{"carol-slack"},
+				StartTime:    time.Date(2024, 3, 23, 0, 0, 0, 0, time.UTC),
+				EndTime:      &until,
+				ShiftLength:  Duration(24 * time.Hour),
+			},
+		},
+		Overrides: &OnCallOverrides{
+			{Participant: "dave-slack", StartTime: time.Date(2024, 4, 3, 12, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 4, 3, 18, 0, 0, 0, time.UTC)},
+		},
+	}
+	require.NoError(schedule.Validate())
+
+	_, ok := schedule.OnCallAt(time.Date(2024, 3, 18, 8, 0, 0, 0, loc))
+	require.False(ok)
+
+	shift, ok := schedule.OnCallAt(time.Date(2024, 3, 20, 12, 0, 0, 0, loc))
+	require.True(ok)
+	require.Equal("alice-slack", shift.Participant)
+	require.Equal(time.Date(2024, 3, 25, 9, 0, 0, 0, loc), shift.EndTime)
+
+	// the later rotation takes over while it runs
+	shift, ok = schedule.OnCallAt(time.Date(2024, 3, 24, 12, 0, 0, 0, loc))
+	require.True(ok)
+	require.Equal("carol-slack", shift.Participant)
+
+	// the handoff stays at 09:00 across the change to summer time on March 31
+	shift, ok = schedule.OnCallAt(time.Date(2024, 4, 1, 9, 30, 0, 0, loc))
+	require.True(ok)
+	require.Equal("alice-slack", shift.Participant)
+	require.Equal(time.Date(2024, 4, 1, 9, 0, 0, 0, loc), shift.StartTime)
+
+	shift, ok = schedule.OnCallAt(time.Date(2024, 3, 27, 9, 0, 0, 0, loc))
+	require.True(ok)
+	require.Equal("bob-email", shift.Participant)
+
+	shift, ok = schedule.OnCallAt(time.Date(2024, 4, 3, 13, 0, 0, 0, loc))
+	require.True(ok)
+	require.Equal("dave-slack", shift.Participant)
+	require.True(shift.Override)
+
+	schedule.Timezone = "Mars/Olympus"
+	require.Error(schedule.Validate())
+	schedule.Timezone = "Europe/Berlin"
+	(*schedule.Rotations)[0].ShiftLength = Duration(time.Minute)
+	require.Error(schedule.Validate())
+}
+
+type onCallSchedulesDB struct {
+	RuleDB
+	schedules []OnCallSchedule
+}
+
+func (r *onCallSchedulesDB) GetAllOnCallSchedules(ctx context.Context) ([]OnCallSchedule, error) {
+	return r.schedules, nil
+}
+
+func TestResolveOnCallChannels(t *testing.T) {
+	ruleDB := &onCallSchedulesDB{schedules: []OnCallSchedule{{
+		Name:     "checkout",
+		Timezone: "UTC",
+		Rotations: &OnCallRotations{{
+			Participants: []string{"alice-slack", "bob-email"},
+			StartTime:    time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC),
+			ShiftLength:  Duration(24 * time.Hour),
+		}},
+	}}}
+
+	ts := time.Date(2024, 3, 19, 10, 0, 0, 0, time.UTC)
+	channels := resolveOnCallChannels(context.Background(), ruleDB, []string{"ops", "oncall:checkout", "oncall:missing"}, ts)
+	require.Equal(t, []string{"ops", "bob-email"}, channels)
+}
@@ -0,0 +1,180 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+)
+
+// RulePanel is the dashboard panel a rule was created from, which traces the
+// rule back to its chart
+type RulePanel struct {
+	DashboardUuid string `yaml:"dashboardUuid" json:"dashboardUuid"`
+	WidgetId      string `yaml:"widgetId" json:"widgetId"`
+}
+
+// Panel is a dashboard panel with the queries the dashboard variables are
+// resolved in, which alert rules are created from
+type Panel struct {
+	RulePanel
+	Title          string
+	Unit           string
+	CompositeQuery *v3.CompositeQuery
+	Thresholds     []PanelThreshold
+}
+
+// PanelThreshold is a threshold of a panel. The thresholds with a severity
+// are the thresholds of the rule created from the panel.
+type PanelThreshold struct {
+	Operator string
+	Value    float64
+	Unit     string
+	Severity string
+}
+
+// PanelRuleParams are the settings of a rule created from a panel, the ones
+// left empty are set from the panel or to the defaults
+type PanelRuleParams struct {
+	AlertName         string            `json:"alert"`
+	SelectedQuery     string            `json:"selectedQueryName"`
+	MatchType         MatchType         `json:"matchType"`
+	EvalWindow        Duration          `json:"evalWindow"`
+	Frequency         Duration          `json:"frequency"`
+	PreferredChannels []string          `json:"preferredChannels"`
+	Labels            map[string]string `json:"labels"`
+	Team              string            `json:"team"`
+}
+
+// panelThresholdOps maps the operators of the panel thresholds to the compare
+// ops of the rules
+var panelThresholdOps = map[string]CompareOp{
+	">":  ValueIsAbove,
+	"<":  ValueIsBelow,
+	"=":  ValueIsEq,
+	">=": ValueAboveOrEq,
+	"<=": ValueBelowOrEq,
+}
+
+// severityRanks orders the severities of the thresholds, the most severe
+// first
+var severityRanks = map[string]int{
+	"critical": 0,
+	"error":    1,
+	"warning":  2,
+	"info":     3,
+}
+
+const defaultPanelRuleSeverity = "warning"
+
+// NewRule returns the alert rule of the panel, alerting when the panel
+// crosses its thresholds. A panel with a single threshold alerts on it, a
+// panel with several alerts on the ones with a severity.
+func (p *Panel) NewRule(params PanelRuleParams) (*PostableRule, error) {
+	if p.CompositeQuery == nil {
+		return nil, fmt.Errorf("the panel has no query")
+	}
+	switch p.CompositeQuery.PanelType {
+	case v3.PanelTypeList, v3.PanelTypeTrace:
+		return nil, fmt.Errorf("panels of type %s can't be alerted on", p.CompositeQuery.PanelType)
+	}
+
+	thresholds := []PanelThreshold{}
+	for _, threshold := range p.Thresholds {
+		if threshold.Severity != "" {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	switch {
+	case len(p.Thresholds) == 0:
+		return nil, fmt.Errorf("the panel has no thresholds to alert on")
+	case len(thresholds) == 0 && len(p.Thresholds) > 1:
+		return nil, fmt.Errorf("the panel has several thresholds, set the severity of the ones to alert on")
+	case len(thresholds) == 0:
+		thresholds = p.Thresholds
+	}
+
+	op, ok := panelThresholdOps[thresholds[0].Operator]
+	if !ok {
+		return nil, fmt.Errorf("unknown threshold operator %q", thresholds[0].Operator)
+	}
+	for _, threshold := range thresholds[1:] {
+		if threshold.Operator != thresholds[0].Operator {
+			return nil, fmt.Errorf("the thresholds to alert on must share their operator")
+		}
+		if threshold.Unit != thresholds[0].Unit {
+			return nil, fmt.Errorf("the thresholds to alert on must share their unit")
+		}
+	}
+
+	// the rules evaluate the series of the queries, whatever the panel
+	// reduces them to
+	cq := *p.CompositeQuery
+	cq.PanelType = v3.PanelTypeGraph
+	cq.Unit = p.Unit
+
+	rule := &PostableRule{
+		AlertName:  params.AlertName,
+		RuleType:   RuleTypeThreshold,
+		EvalWindow: params.EvalWindow,
+		Frequency:  params.Frequency,
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &cq,
+			CompareOp:      op,
+			MatchType:      params.MatchType,
+			TargetUnit:     thresholds[0].Unit,
+			SelectedQuery:  params.SelectedQuery,
+		},
+		Labels: map[string]string{},
+		Annotations: map[string]string{
+			labels.AlertSummaryLabel: fmt.Sprintf("The panel %s crossed its threshold", p.Title),
+			"description":            "The value of the panel is {{$value}}, crossing the threshold {{$threshold}}",
+		},
+		PreferredChannels: params.PreferredChannels,
+		Team:              params.Team,
+		Panel:             &p.RulePanel,
+		Version:           "v4",
+	}
+	if rule.AlertName == "" {
+		rule.AlertName = p.Title
+	}
+	if cq.QueryType == v3.QueryTypePromQL {
+		rule.RuleType = RuleTypeProm
+	}
+	if rule.RuleCondition.MatchType == "" {
+		rule.RuleCondition.MatchType = AtleastOnce
+	}
+	for name, value := range params.Labels {
+		rule.Labels[name] = value
+	}
+	rule.AlertType = AlertTypeMetric
+	if query, ok := cq.BuilderQueries[rule.RuleCondition.GetSelectedQueryName()]; ok {
+		switch query.DataSource {
+		case v3.DataSourceLogs:
+			rule.AlertType = AlertTypeLogs
+		case v3.DataSourceTraces:
+			rule.AlertType = AlertTypeTraces
+		}
+	}
+
+	if len(thresholds) == 1 {
+		target := thresholds[0].Value
+		rule.RuleCondition.Target = &target
+		severity := thresholds[0].Severity
+		if severity == "" {
+			severity = defaultPanelRuleSeverity
+		}
+		rule.Labels[labels.AlertSeverityLabel] = severity
+		return rule, nil
+	}
+
+	sort.SliceStable(thresholds, func(i, j int) bool {
+		return severityRanks[thresholds[i].Severity] < severityRanks[thresholds[j].Severity]
+	})
+	for _, threshold := range thresholds {
+		target := threshold.Value
+		rule.RuleCondition.Thresholds = append(rule.RuleCondition.Thresholds, SeverityThreshold{Severity: threshold.Severity, Target: &target})
+	}
+	return rule, nil
+}
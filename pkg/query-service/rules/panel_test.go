@@ -0,0 +1,79 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+func TestPanelNewRule(t *testing.T) {
+	require := require.New(t)
+
+	panel := Panel{
+		RulePanel: RulePanel{DashboardUuid: "1e5f", WidgetId: "latency"},
+		Title:     "checkout latency",
+		Unit:      "ms",
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType: v3.QueryTypeBuilder,
+			PanelType: v3.PanelTypeValue,
+			BuilderQueries: map[string]*v3.BuilderQuery{
+				"A": {
+					QueryName:          "A",
+					StepInterval:       60,
+					DataSource:         v3.DataSourceTraces,
+					AggregateOperator:  v3.AggregateOperatorP99,
+					AggregateAttribute: v3.AttributeKey{Key: "durationNano"},
+					Expression:         "A",
+				},
+			},
+		},
+		Thresholds: []PanelThreshold{{Operator: ">", Value: 500, Unit: "ms"}},
+	}
+
+	rule, err := panel.NewRule(PanelRuleParams{})
+	require.NoError(err)
+	require.Equal("checkout latency", rule.AlertName)
+	require.Equal(AlertTypeTraces, rule.AlertType)
+	require.Equal(RuleType(RuleTypeThreshold), rule.RuleType)
+	require.Equal(v3.PanelTypeGraph, rule.RuleCondition.CompositeQuery.PanelType)
+	require.Equal(ValueIsAbove, rule.RuleCondition.CompareOp)
+	require.Equal(500.0, *rule.RuleCondition.Target)
+	require.Equal("ms", rule.RuleCondition.TargetUnit)
+	require.Equal("warning", rule.Labels["severity"])
+	require.Equal("latency", rule.Panel.WidgetId)
+	// the query of the panel is not changed
+	require.Equal(v3.PanelTypeValue, panel.CompositeQuery.PanelType)
+
+	// the thresholds with a severity are alerted on, the most severe first
+	panel.Thresholds = []PanelThreshold{
+		{Operator: ">", Value: 300, Unit: "ms"},
+		{Operator: ">", Value: 500, Unit: "ms", Severity: "warning"},
+		{Operator: ">", Value: 1000, Unit: "ms", Severity: "critical"},
+	}
+	rule, err = panel.NewRule(PanelRuleParams{AlertName: "slow checkout", Labels: map[string]string{"team": "payments"}})
+	require.NoError(err)
+	require.Equal("slow checkout", rule.AlertName)
+	require.Nil(rule.RuleCondition.Target)
+	require.Len(rule.RuleCondition.Thresholds, 2)
+	require.Equal("critical", rule.RuleCondition.Thresholds[0].Severity)
+	require.Equal(1000.0, *rule.RuleCondition.Thresholds[0].Target)
+	require.Equal("payments", rule.Labels["team"])
+	data, err := json.Marshal(rule)
+	require.NoError(err)
+	_, err = ParsePostableRule(data)
+	require.NoError(err)
+
+	panel.Thresholds[2].Operator = "<"
+	_, err = panel.NewRule(PanelRuleParams{})
+	require.Error(err)
+
+	panel.Thresholds = []PanelThreshold{{Operator: ">", Value: 300}, {Operator: ">", Value: 500}}
+	_, err = panel.NewRule(PanelRuleParams{})
+	require.Error(err)
+
+	panel.Thresholds = nil
+	_, err = panel.NewRule(PanelRuleParams{})
+	require.Error(err)
+}
@@ -0,0 +1,229 @@
+package rules
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+// AutoResumedBy is who resumed the rules resumed at their resume time
+const AutoResumedBy = "auto-resume"
+
+// RulePause is a pause of a rule, the rule is not evaluated until it is
+// resumed. Unlike disabling the rule, the pause keeps who paused the rule,
+// when and why, and the rule can be resumed at a set time. The pauses resumed
+// are kept as the audit of the pauses of the rule.
+type RulePause struct {
+	Id       int64     `json:"id" db:"id"`
+	RuleId   string    `json:"ruleId" db:"rule_id"`
+	Reason   string    `json:"reason" db:"reason"`
+	PausedAt time.Time `json:"pausedAt" db:"paused_at"`
+	PausedBy string    `json:"pausedBy" db:"paused_by"`
+	// ResumeAt is when the rule is resumed, the rule is paused until it is
+	// resumed otherwise
+	ResumeAt  *time.Time `json:"resumeAt,omitempty" db:"resume_at"`
+	ResumedAt *time.Time `json:"resumedAt,omitempty" db:"resumed_at"`
+	ResumedBy string     `json:"resumedBy,omitempty" db:"resumed_by"`
+}
+
+// PauseRule pauses the rule for the reason, until the resume time when it
+// is set
+func (m *Manager) PauseRule(ctx context.Context, id string, reason string, resumeAt *time.Time) (*RulePause, error) {
+	if reason == "" {
+		return nil, fmt.Errorf("the reason for pausing the rule is required")
+	}
+	now := time.Now()
+	if resumeAt != nil && !resumeAt.After(now) {
+		return nil, fmt.Errorf("the resume time must be in the future")
+	}
+
+	if err := m.checkStoredRuleTeam(ctx, id); err != nil {
+		return nil, err
+	}
+	existing, err := m.ruleDB.GetActiveRulePause(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("the rule %s is already paused", id)
+	}
+
+	pausedBy := "unknown"
+	if claims, ok := authtypes.ClaimsFromContext(ctx); ok {
+		pausedBy = claims.Email
+	}
+	pause := RulePause{
+		RuleId:   id,
+		Reason:   reason,
+		PausedAt: now,
+		PausedBy: pausedBy,
+		ResumeAt: resumeAt,
+	}
+	pause.Id, err = m.ruleDB.CreateRulePause(ctx, pause)
+	if err != nil {
+		return nil, err
+	}
+
+	m.deleteTask(prepareTaskName(id))
+	return &pause, nil
+}
+
+// ResumeRule resumes the paused rule, the rule runs again unless it is
+// disabled
+func (m *Manager) ResumeRule(ctx context.Context, id string) (*RulePause, error) {
+	if err := m.checkStoredRuleTeam(ctx, id); err != nil {
+		return nil, err
+	}
+	resumedBy := "unknown"
+	if claims, ok := authtypes.ClaimsFromContext(ctx); ok {
+		resumedBy = claims.Email
+	}
+	return m.resumeRule(ctx, id, resumedBy)
+}
+
+func (m *Manager) resumeRule(ctx context.Context, id string, resumedBy string) (*RulePause, error) {
+	pause, err := m.ruleDB.GetActiveRulePause(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pause == nil {
+		return nil, fmt.Errorf("the rule %s is not paused", id)
+	}
+
+	now := time.Now()
+	if err := m.ruleDB.ResumeRulePause(ctx, pause.Id, now, resumedBy); err != nil {
+		return nil, err
+	}
+	pause.ResumedAt = &now
+	pause.ResumedBy = resumedBy
+
+	if m.opts.DisableRules {
+		return pause, nil
+	}
+	storedRule, err := m.ruleDB.GetStoredRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rule := PostableRule{}
+	if err := json.Unmarshal([]byte(storedRule.Data), &rule); err != nil {
+		return nil, err
+	}
+	if err := m.syncRuleStateWithTask(prepareTaskName(id), &rule); err != nil {
+		return nil, err
+	}
+	return pause, nil
+}
+
+// rulePauses returns the pauses of the paused rules by rule id
+func (m *Manager) rulePauses(ctx context.Context) map[string]*RulePause {
+	all, err := m.ruleDB.GetActiveRulePauses(ctx)
+	if err != nil {
+		zap.L().Error("failed to get the paused rules", zap.Error(err))
+		return nil
+	}
+	pauses := make(map[string]*RulePause, len(all))
+	for i := range all {
+		pauses[all[i].RuleId] = &all[i]
+	}
+	return pauses
+}
+
+// isPaused tells if the rule is paused
+func (m *Manager) isPaused(ctx context.Context, id string) bool {
+	pause, err := m.ruleDB.GetActiveRulePause(ctx, id)
+	if err != nil {
+		zap.L().Error("failed to get the pause of the rule", zap.String("rule", id), zap.Error(err))
+		return false
+	}
+	return pause != nil
+}
+
+// resumePausedRules resumes the paused rules once their resume time passed
+func (m *Manager) resumePausedRules() {
+	tick := time.NewTicker(30 * time.Second)
+	defer tick.Stop()
+
+	for range tick.C {
+		ctx := context.Background()
+		now := time.Now()
+		for id, pause := range m.rulePauses(ctx) {
+			if pause.ResumeAt == nil || pause.ResumeAt.After(now) {
+				continue
+			}
+			if _, err := m.resumeRule(ctx, id, AutoResumedBy); err != nil {
+				zap.L().Error("failed to resume the paused rule", zap.String("rule", id), zap.Error(err))
+				continue
+			}
+			zap.L().Info("resumed the paused rule", zap.String("rule", id), zap.String("reason", pause.Reason))
+		}
+	}
+}
+
+func (r *ruleDB) GetRulePauses(ctx context.Context, ruleID string) ([]RulePause, error) {
+	pauses := []RulePause{}
+
+	err := r.Select(&pauses, "SELECT * FROM rule_pauses WHERE rule_id=$1 ORDER BY paused_at DESC", ruleID)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return pauses, nil
+}
+
+func (r *ruleDB) GetActiveRulePauses(ctx context.Context) ([]RulePause, error) {
+	pauses := []RulePause{}
+
+	err := r.Select(&pauses, "SELECT * FROM rule_pauses WHERE resumed_at IS NULL")
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return pauses, nil
+}
+
+func (r *ruleDB) GetActiveRulePause(ctx context.Context, ruleID string) (*RulePause, error) {
+	pause := &RulePause{}
+
+	err := r.Get(pause, "SELECT * FROM rule_pauses WHERE rule_id=$1 AND resumed_at IS NULL", ruleID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return pause, nil
+}
+
+func (r *ruleDB) CreateRulePause(ctx context.Context, pause RulePause) (int64, error) {
+	query := "INSERT INTO rule_pauses (rule_id, reason, paused_at, paused_by, resume_at, resumed_by) VALUES ($1, $2, $3, $4, $5, $6)"
+	result, err := r.Exec(query, pause.RuleId, pause.Reason, pause.PausedAt, pause.PausedBy, pause.ResumeAt, pause.ResumedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *ruleDB) ResumeRulePause(ctx context.Context, id int64, resumedAt time.Time, resumedBy string) error {
+	result, err := r.Exec("UPDATE rule_pauses SET resumed_at=$1, resumed_by=$2 WHERE id=$3 AND resumed_at IS NULL", resumedAt, resumedBy, id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return errors.New("the pause was already resumed")
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func TestPauseRule(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "admin@signoz.io"})
+
+	ruleID, tx, err := ruleDB.CreateRuleTx(ctx, `{"alert": "high latency", "ruleType": "threshold_rule", "disabled": false}`)
+	require.NoError(err)
+	require.NoError(tx.Commit())
+	id := fmt.Sprintf("%d", ruleID)
+
+	m := &Manager{opts: &ManagerOptions{DisableRules: true}, tasks: map[string]Task{}, rules: map[string]Rule{}, ruleDB: ruleDB}
+
+	_, err = m.PauseRule(ctx, id, "", nil)
+	require.Error(err)
+	past := time.Now().Add(-time.Hour)
+	_, err = m.PauseRule(ctx, id, "migration", &past)
+	require.Error(err)
+	_, err = m.ResumeRule(ctx, id)
+	require.Error(err)
+
+	resumeAt := time.Now().Add(time.Hour)
+	pause, err := m.PauseRule(ctx, id, "migrating the checkout service", &resumeAt)
+	require.NoError(err)
+	require.Equal("admin@signoz.io", pause.PausedBy)
+	_, err = m.PauseRule(ctx, id, "again", nil)
+	require.Error(err)
+
+	rule, err := m.GetRule(ctx, id)
+	require.NoError(err)
+	require.Equal(model.StatePaused, rule.State)
+	require.Equal("migrating the checkout service", rule.Pause.Reason)
+	require.False(rule.Disabled)
+
+	resumed, err := m.resumeRule(ctx, id, AutoResumedBy)
+	require.NoError(err)
+	require.Equal(AutoResumedBy, resumed.ResumedBy)
+	require.NotNil(resumed.ResumedAt)
+
+	rule, err = m.GetRule(ctx, id)
+	require.NoError(err)
+	require.Equal(model.StateDisabled, rule.State)
+	require.Nil(rule.Pause)
+
+	// the pauses resumed are kept for the audit
+	_, err = m.PauseRule(ctx, id, "noisy during the incident", nil)
+	require.NoError(err)
+	pauses, err := ruleDB.GetRulePauses(ctx, id)
+	require.NoError(err)
+	require.Len(pauses, 2)
+	require.Equal("noisy during the incident", pauses[0].Reason)
+	require.Nil(pauses[0].ResumedAt)
+	require.Equal(AutoResumedBy, pauses[1].ResumedBy)
+}
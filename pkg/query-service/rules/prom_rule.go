@@ -97,8 +97,8 @@ func (r *PromRule) Eval(ctx context.Context, ts time.Time) (interface{}, error)
 
 	prevState := r.State()
 
-	start := ts.Add(-r.evalWindow)
-	end := ts
+	start := ts.Add(-r.evalWindow - r.evalDelay)
+	end := ts.Add(-r.evalDelay)
 	interval := 60 * time.Second // TODO(srikanthccv): this should be configurable
 
 	valueFormatter := formatter.FromUnit(r.Unit())
@@ -201,6 +201,8 @@ func (r *PromRule) Eval(ctx context.Context, ts time.Time) (interface{}, error)
 			Value:             alertSmpl.V,
 			GeneratorURL:      r.GeneratorURL(),
 			Receivers:         r.preferredChannels,
+
+			ResolvedAnnotations: r.resolvedNotification.ExpandAnnotations(expand),
 		}
 	}
 
@@ -212,6 +214,7 @@ func (r *PromRule) Eval(ctx context.Context, ts time.Time) (interface{}, error)
 		if alert, ok := r.Active[h]; ok && alert.State != model.StateInactive {
 			alert.Value = a.Value
 			alert.Annotations = a.Annotations
+			alert.ResolvedAnnotations = a.ResolvedAnnotations
 			alert.Receivers = r.preferredChannels
 			continue
 		}
@@ -237,6 +240,9 @@ func (r *PromRule) Eval(ctx context.Context, ts time.Time) (interface{}, error)
 			if a.State != model.StateInactive {
 				a.State = model.StateInactive
 				a.ResolvedAt = ts
+				if !a.FiredAt.IsZero() {
+					r.recordTransition(fp, ts)
+				}
 				itemsToAdd = append(itemsToAdd, model.RuleStateHistory{
 					RuleID:       r.ID(),
 					RuleName:     r.Name(),
@@ -253,6 +259,7 @@ func (r *PromRule) Eval(ctx context.Context, ts time.Time) (interface{}, error)
 		if a.State == model.StatePending && ts.Sub(a.ActiveAt) >= r.holdDuration {
 			a.State = model.StateFiring
 			a.FiredAt = ts
+			r.recordTransition(fp, ts)
 			state := model.StateFiring
 			if a.Missing {
 				state = model.StateNoData
@@ -267,6 +274,18 @@ func (r *PromRule) Eval(ctx context.Context, ts time.Time) (interface{}, error)
 				Fingerprint:  a.QueryResultLables.Hash(),
 				Value:        a.Value,
 			})
+		} else if a.State == model.StatePending && a.ActiveAt.Equal(ts) {
+			// the alert is held pending before it fires
+			itemsToAdd = append(itemsToAdd, model.RuleStateHistory{
+				RuleID:       r.ID(),
+				RuleName:     r.Name(),
+				State:        model.StatePending,
+				StateChanged: true,
+				UnixMilli:    ts.UnixMilli(),
+				Labels:       model.LabelsString(labelsJSON),
+				Fingerprint:  a.QueryResultLables.Hash(),
+				Value:        a.Value,
+			})
 		}
 
 	}
@@ -293,6 +312,7 @@ func (r *PromRule) String() string {
 		AlertName:         r.name,
 		RuleCondition:     r.ruleCondition,
 		EvalWindow:        Duration(r.evalWindow),
+		EvalDelay:         Duration(r.evalDelay),
 		Labels:            r.labels.Map(),
 		Annotations:       r.annotations.Map(),
 		PreferredChannels: r.preferredChannels,
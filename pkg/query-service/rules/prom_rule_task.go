@@ -103,6 +103,9 @@ func (g *PromRuleTask) Run(ctx context.Context) {
 	})
 
 	iter := func() {
+		if !waitEvalJitter(g.opts, g.frequency, g.done) {
+			return
+		}
 
 		start := time.Now()
 		g.Eval(ctx, evalTimestamp)
@@ -328,17 +331,11 @@ func (g *PromRuleTask) Eval(ctx context.Context, ts time.Time) {
 			continue
 		}
 
-		shouldSkip := false
-		for _, m := range maintenance {
-			zap.L().Info("checking if rule should be skipped", zap.String("rule", rule.ID()), zap.Any("maintenance", m))
-			if m.shouldSkip(rule.ID(), ts) {
-				shouldSkip = true
-				break
-			}
-		}
-
-		if shouldSkip {
+		if m := skippingMaintenance(maintenance, rule.ID(), ts); m != nil {
 			zap.L().Info("rule should be skipped", zap.String("rule", rule.ID()))
+			if err := g.ruleDB.RecordMaintenanceSuppression(ctx, m.Id, rule.ID(), 0, 1); err != nil {
+				zap.L().Error("failed to record the evaluation skipped by maintenance", zap.Int64("maintenance", m.Id), zap.Error(err))
+			}
 			continue
 		}
 
@@ -381,7 +378,10 @@ func (g *PromRuleTask) Eval(ctx context.Context, ts time.Time) {
 				//}
 				return
 			}
-			rule.SendAlerts(ctx, ts, g.opts.ResendDelay, g.frequency, g.notify)
+			notify := notifyOutsideMaintenance(g.ruleDB, maintenance, rule.ID(), ts, g.notify)
+			notify = notifyAcknowledged(g.ruleDB, rule.ID(), notify)
+			rule.SendAlerts(ctx, ts, g.opts.ResendDelay, g.frequency, notify)
+			escalateAlerts(ctx, g.ruleDB, rule, ts, notify)
 
 		}(i, rule)
 	}
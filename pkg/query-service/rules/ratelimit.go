@@ -0,0 +1,261 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+const (
+	// RateLimitedAlertName is the name of the alert summing up the
+	// notifications a channel was not sent over its rate limit
+	RateLimitedAlertName = "Rate limited notifications"
+	// RateLimitedChannelLabel is the label of the channel in the summary
+	RateLimitedChannelLabel = "channel"
+
+	// rateLimitKeyRetention is how long the limiter remembers an alert which
+	// is no longer sent, the firing alerts being sent again every minute
+	rateLimitKeyRetention = time.Hour
+)
+
+// ChannelRateLimit caps the notifications sent to a channel. The channel can
+// be sent up to Burst notifications at once, which are given back at the rate
+// of MaxNotifications every Period. The alerts over the limit are not sent to
+// the channel, and are summed up in one notification once it is below it.
+type ChannelRateLimit struct {
+	Id               int64     `json:"id" db:"id"`
+	Channel          string    `json:"channel" db:"channel"`
+	MaxNotifications int       `json:"maxNotifications" db:"max_notifications"`
+	Period           Duration  `json:"period" db:"period"`
+	Burst            int       `json:"burst" db:"burst"`
+	UpdatedAt        time.Time `json:"updatedAt" db:"updated_at"`
+	UpdatedBy        string    `json:"updatedBy" db:"updated_by"`
+}
+
+func (l *ChannelRateLimit) Validate() error {
+	if l.Channel == "" {
+		return fmt.Errorf("missing channel")
+	}
+	if l.MaxNotifications <= 0 {
+		return fmt.Errorf("the max notifications must be greater than 0")
+	}
+	if time.Duration(l.Period) < time.Second {
+		return fmt.Errorf("the period must be at least a second")
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("the burst can't be negative")
+	}
+	return nil
+}
+
+// capacity is the most notifications the channel can be sent at once
+func (l *ChannelRateLimit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.MaxNotifications)
+}
+
+// channelBucket is the state of the rate limit of a channel, the alerts are
+// tracked by their dedup key so the alerts sent again keep going to the
+// channel, or being held back from it, until they resolve
+type channelBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	sent      map[string]time.Time
+	held      map[string]time.Time
+	// overflow counts the alerts held back since the last summary, by name
+	overflow map[string]int
+}
+
+// channelRateLimiter limits the notifications of the channels with a rate
+// limit, the state is kept in memory
+type channelRateLimiter struct {
+	mtx     sync.Mutex
+	buckets map[string]*channelBucket
+}
+
+func newChannelRateLimiter() *channelRateLimiter {
+	return &channelRateLimiter{buckets: map[string]*channelBucket{}}
+}
+
+// bucket returns the bucket of the channel with the notifications given back
+// since it was last used
+func (l *channelRateLimiter) bucket(limit ChannelRateLimit, ts time.Time) *channelBucket {
+	b, ok := l.buckets[limit.Channel]
+	if !ok {
+		b = &channelBucket{
+			tokens:    limit.capacity(),
+			updatedAt: ts,
+			sent:      map[string]time.Time{},
+			held:      map[string]time.Time{},
+			overflow:  map[string]int{},
+		}
+		l.buckets[limit.Channel] = b
+	}
+	if elapsed := ts.Sub(b.updatedAt); elapsed > 0 {
+		rate := float64(limit.MaxNotifications) / float64(limit.Period)
+		b.tokens = min(limit.capacity(), b.tokens+rate*float64(elapsed))
+		b.updatedAt = ts
+	}
+	return b
+}
+
+// filter returns the channels the alert is sent to, leaving out the channels
+// over their limit. The resolved alerts are sent to the channels they were
+// sent to when firing without counting towards the limit.
+func (l *channelRateLimiter) filter(limits map[string]ChannelRateLimit, channels []string, alert *Alert, ts time.Time) []string {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	key := alert.DedupKey()
+	resolved := !alert.ResolvedAt.IsZero()
+	allowed := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		limit, ok := limits[channel]
+		if !ok {
+			allowed = append(allowed, channel)
+			continue
+		}
+
+		b := l.bucket(limit, ts)
+		_, sent := b.sent[key]
+		_, held := b.held[key]
+		switch {
+		case resolved:
+			delete(b.sent, key)
+			delete(b.held, key)
+			if !held {
+				allowed = append(allowed, channel)
+			}
+		case sent:
+			b.sent[key] = ts
+			allowed = append(allowed, channel)
+		case held:
+			b.held[key] = ts
+		case b.tokens >= 1:
+			b.tokens--
+			b.sent[key] = ts
+			allowed = append(allowed, channel)
+		default:
+			b.held[key] = ts
+			b.overflow[alert.Labels.Get(labels.AlertNameLabel)]++
+		}
+	}
+	return allowed
+}
+
+// summaries returns the alerts summing up the notifications held back from
+// the channels which are below their limit again, each taking a notification
+// of its channel. The channels whose limit is removed get their summary too.
+func (l *channelRateLimiter) summaries(limits map[string]ChannelRateLimit, ts time.Time) []*Alert {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	var alerts []*Alert
+	for channel, b := range l.buckets {
+		limit, ok := limits[channel]
+		if ok {
+			b = l.bucket(limit, ts)
+		}
+
+		for key, seen := range b.sent {
+			if ts.Sub(seen) > rateLimitKeyRetention {
+				delete(b.sent, key)
+			}
+		}
+		for key, seen := range b.held {
+			if ts.Sub(seen) > rateLimitKeyRetention {
+				delete(b.held, key)
+			}
+		}
+
+		if len(b.overflow) > 0 && (!ok || b.tokens >= 1) {
+			alerts = append(alerts, rateLimitSummary(channel, b.overflow, ts))
+			b.tokens--
+			b.overflow = map[string]int{}
+		}
+		if !ok {
+			delete(l.buckets, channel)
+		}
+	}
+	return alerts
+}
+
+// rateLimitSummary is the alert telling the channel how many alerts it was
+// not sent, it resolves on its own a few minutes later
+func rateLimitSummary(channel string, overflow map[string]int, ts time.Time) *Alert {
+	names := make([]string, 0, len(overflow))
+	total := 0
+	for name, count := range overflow {
+		names = append(names, fmt.Sprintf("%s (%d)", name, count))
+		total += count
+	}
+	sort.Strings(names)
+
+	return &Alert{
+		State: model.StateFiring,
+		Labels: labels.FromMap(map[string]string{
+			labels.AlertNameLabel:     RateLimitedAlertName,
+			labels.AlertSeverityLabel: "warning",
+			RateLimitedChannelLabel:   channel,
+		}),
+		Annotations: labels.FromMap(map[string]string{
+			labels.AlertSummaryLabel: fmt.Sprintf("Alerts not sent to the channel %s over its rate limit: %d", channel, total),
+			"description":            fmt.Sprintf("The alerts not sent to the channel: %s", strings.Join(names, ", ")),
+		}),
+		FiredAt:    ts,
+		LastSentAt: ts,
+		ValidUntil: ts.Add(5 * time.Minute),
+		Receivers:  []string{channel},
+	}
+}
+
+func (r *ruleDB) GetAllChannelRateLimits(ctx context.Context) ([]ChannelRateLimit, error) {
+	limits := []ChannelRateLimit{}
+
+	err := r.Select(&limits, "SELECT * FROM channel_rate_limits ORDER BY channel")
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return limits, nil
+}
+
+func (r *ruleDB) SetChannelRateLimit(ctx context.Context, limit ChannelRateLimit) error {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("no claims found in context")
+	}
+	limit.UpdatedBy = claims.Email
+	limit.UpdatedAt = time.Now()
+
+	query := "INSERT INTO channel_rate_limits (channel, max_notifications, period, burst, updated_at, updated_by) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (channel) DO UPDATE SET max_notifications=$2, period=$3, burst=$4, updated_at=$5, updated_by=$6"
+	_, err := r.Exec(query, limit.Channel, limit.MaxNotifications, limit.Period, limit.Burst, limit.UpdatedAt, limit.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleDB) DeleteChannelRateLimit(ctx context.Context, channel string) error {
+	_, err := r.Exec("DELETE FROM channel_rate_limits WHERE channel=$1", channel)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
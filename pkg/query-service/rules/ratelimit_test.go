@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func TestChannelRateLimiter(t *testing.T) {
+	limits := map[string]ChannelRateLimit{
+		"slack": {Channel: "slack", MaxNotifications: 2, Period: Duration(time.Minute)},
+	}
+	newAlert := func(i int) *Alert {
+		return &Alert{
+			State:  model.StateFiring,
+			Labels: labels.FromMap(map[string]string{labels.AlertNameLabel: "high latency", labels.AlertRuleIdLabel: "1", "pod": fmt.Sprint(i)}),
+		}
+	}
+
+	l := newChannelRateLimiter()
+	ts := time.Now()
+	alerts := []*Alert{newAlert(1), newAlert(2), newAlert(3)}
+	for _, alert := range alerts[:2] {
+		require.Equal(t, []string{"slack", "email"}, l.filter(limits, []string{"slack", "email"}, alert, ts))
+	}
+	// the channels without a limit are sent all the alerts
+	require.Equal(t, []string{"email"}, l.filter(limits, []string{"slack", "email"}, alerts[2], ts))
+
+	// the alerts sent again keep going to the channels they went to
+	ts = ts.Add(time.Second)
+	require.Equal(t, []string{"slack"}, l.filter(limits, []string{"slack"}, alerts[0], ts))
+	require.Empty(t, l.filter(limits, []string{"slack"}, alerts[2], ts))
+
+	// no summary until the channel is below its limit
+	require.Empty(t, l.summaries(limits, ts))
+	ts = ts.Add(30 * time.Second)
+	summaries := l.summaries(limits, ts)
+	require.Len(t, summaries, 1)
+	require.Equal(t, []string{"slack"}, summaries[0].Receivers)
+	require.Equal(t, "Alerts not sent to the channel slack over its rate limit: 1", summaries[0].Annotations.Get(labels.AlertSummaryLabel))
+	require.Empty(t, l.summaries(limits, ts))
+
+	// the alert held back stays held back until it resolves, and its
+	// resolution is not sent either
+	ts = ts.Add(time.Minute)
+	require.Empty(t, l.filter(limits, []string{"slack"}, alerts[2], ts))
+	alerts[2].ResolvedAt = ts
+	require.Empty(t, l.filter(limits, []string{"slack"}, alerts[2], ts))
+	alerts[0].ResolvedAt = ts
+	require.Equal(t, []string{"slack"}, l.filter(limits, []string{"slack"}, alerts[0], ts))
+	require.Equal(t, []string{"slack"}, l.filter(limits, []string{"slack"}, newAlert(4), ts))
+}
+
+func TestChannelRateLimitDB(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "admin@signoz.io"})
+
+	limit := ChannelRateLimit{Channel: "slack", MaxNotifications: 10, Period: Duration(time.Minute)}
+	require.NoError(limit.Validate())
+	require.NoError(ruleDB.SetChannelRateLimit(ctx, limit))
+
+	limit.Burst = 20
+	require.NoError(ruleDB.SetChannelRateLimit(ctx, limit))
+	limits, err := ruleDB.GetAllChannelRateLimits(ctx)
+	require.NoError(err)
+	require.Len(limits, 1)
+	require.Equal(20, limits[0].Burst)
+	require.Equal(Duration(time.Minute), limits[0].Period)
+	require.Equal("admin@signoz.io", limits[0].UpdatedBy)
+
+	require.NoError(ruleDB.DeleteChannelRateLimit(ctx, "slack"))
+	limits, err = ruleDB.GetAllChannelRateLimits(ctx)
+	require.NoError(err)
+	require.Empty(limits)
+
+	require.Error((&ChannelRateLimit{Channel: "slack", MaxNotifications: 10}).Validate())
+}
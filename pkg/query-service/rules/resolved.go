@@ -0,0 +1,166 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+// ResolvedNotification sets how the resolved alerts of a rule are notified.
+// The alerts resolving after firing for less than the min firing duration
+// are not notified as resolved, which cuts down the notifications of the
+// alerts flapping between firing and resolved.
+type ResolvedNotification struct {
+	// Disabled stops the notifications of the resolved alerts
+	Disabled          bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	MinFiringDuration Duration `yaml:"minFiringDuration,omitempty" json:"minFiringDuration,omitempty"`
+	// Annotations are the templates of the annotations of the resolved
+	// notifications, such as the summary and the description, taking over
+	// from the annotations of the rule
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+}
+
+func (n *ResolvedNotification) Validate() error {
+	if n.MinFiringDuration < 0 {
+		return fmt.Errorf("the min firing duration of the resolved notifications can't be negative")
+	}
+	return nil
+}
+
+// ExpandAnnotations expands the templates of the annotations of the resolved
+// notifications with the expand func of the alert
+func (n *ResolvedNotification) ExpandAnnotations(expand func(string) string) labels.Labels {
+	if n == nil {
+		return nil
+	}
+	annotations := make(labels.Labels, 0, len(n.Annotations))
+	for name, value := range n.Annotations {
+		annotations = append(annotations, labels.Label{Name: name, Value: expand(value)})
+	}
+	return annotations
+}
+
+// notifies tells if the resolved alert is notified
+func (n *ResolvedNotification) notifies(alert *Alert) bool {
+	if n == nil {
+		return true
+	}
+	return notifiesResolved(n.Disabled, n.MinFiringDuration, alert)
+}
+
+func notifiesResolved(disabled bool, minFiringDuration Duration, alert *Alert) bool {
+	if disabled {
+		return false
+	}
+	return alert.ResolvedAt.Sub(alert.FiredAt) >= time.Duration(minFiringDuration)
+}
+
+// resolvedAnnotations returns the annotations of the resolved notification of
+// the alert, the annotations of the alert with the resolved ones over them
+func (a *Alert) resolvedAnnotations() labels.BaseLabels {
+	if a.ResolvedAnnotations == nil || a.ResolvedAnnotations.Len() == 0 {
+		return a.Annotations
+	}
+	annotations := map[string]string{}
+	if a.Annotations != nil {
+		annotations = a.Annotations.Map()
+	}
+	for name, value := range a.ResolvedAnnotations.Map() {
+		annotations[name] = value
+	}
+	return labels.FromMap(annotations)
+}
+
+// ChannelResolvedNotification sets how the resolved alerts are notified to a
+// channel, on top of the settings of the rules
+type ChannelResolvedNotification struct {
+	Id                int64     `json:"id" db:"id"`
+	Channel           string    `json:"channel" db:"channel"`
+	Disabled          bool      `json:"disabled" db:"disabled"`
+	MinFiringDuration Duration  `json:"minFiringDuration" db:"min_firing_duration"`
+	UpdatedAt         time.Time `json:"updatedAt" db:"updated_at"`
+	UpdatedBy         string    `json:"updatedBy" db:"updated_by"`
+}
+
+func (n *ChannelResolvedNotification) Validate() error {
+	if n.Channel == "" {
+		return fmt.Errorf("missing channel")
+	}
+	if n.MinFiringDuration < 0 {
+		return fmt.Errorf("the min firing duration can't be negative")
+	}
+	return nil
+}
+
+// filterResolvedChannels returns the channels the resolved alert is notified
+// to, by the settings of the channels
+func filterResolvedChannels(settings map[string]ChannelResolvedNotification, channels []string, alert *Alert) []string {
+	kept := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		if s, ok := settings[channel]; ok && !notifiesResolved(s.Disabled, s.MinFiringDuration, alert) {
+			continue
+		}
+		kept = append(kept, channel)
+	}
+	return kept
+}
+
+// channelResolvedNotifications returns the resolved notification settings of
+// the channels by channel name
+func (m *Manager) channelResolvedNotifications(ctx context.Context) map[string]ChannelResolvedNotification {
+	all, err := m.ruleDB.GetAllChannelResolvedNotifications(ctx)
+	if err != nil {
+		zap.L().Error("failed to get the resolved notification settings of the channels", zap.Error(err))
+		return nil
+	}
+	settings := make(map[string]ChannelResolvedNotification, len(all))
+	for _, s := range all {
+		settings[s.Channel] = s
+	}
+	return settings
+}
+
+func (r *ruleDB) GetAllChannelResolvedNotifications(ctx context.Context) ([]ChannelResolvedNotification, error) {
+	settings := []ChannelResolvedNotification{}
+
+	err := r.Select(&settings, "SELECT * FROM channel_resolved_notifications ORDER BY channel")
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (r *ruleDB) SetChannelResolvedNotification(ctx context.Context, settings ChannelResolvedNotification) error {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("no claims found in context")
+	}
+	settings.UpdatedBy = claims.Email
+	settings.UpdatedAt = time.Now()
+
+	query := "INSERT INTO channel_resolved_notifications (channel, disabled, min_firing_duration, updated_at, updated_by) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (channel) DO UPDATE SET disabled=$2, min_firing_duration=$3, updated_at=$4, updated_by=$5"
+	_, err := r.Exec(query, settings.Channel, settings.Disabled, settings.MinFiringDuration, settings.UpdatedAt, settings.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleDB) DeleteChannelResolvedNotification(ctx context.Context, channel string) error {
+	_, err := r.Exec("DELETE FROM channel_resolved_notifications WHERE channel=$1", channel)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func TestSendResolvedAlerts(t *testing.T) {
+	ts := time.Now()
+	alert := func(service string, firedFor time.Duration) *Alert {
+		return &Alert{
+			State:       model.StateInactive,
+			Labels:      labels.FromMap(map[string]string{"service": service}),
+			Annotations: labels.FromMap(map[string]string{"summary": "high latency", "runbook": "https://runbooks/latency"}),
+			ResolvedAnnotations: (&ResolvedNotification{Annotations: map[string]string{"summary": "{{$labels.service}} latency is back to normal"}}).ExpandAnnotations(func(text string) string {
+				return "latency is back to normal"
+			}),
+			FiredAt:    ts.Add(-firedFor),
+			ResolvedAt: ts,
+		}
+	}
+
+	send := func(resolved *ResolvedNotification) []*Alert {
+		r := &BaseRule{resolvedNotification: resolved, Active: map[uint64]*Alert{}}
+		for i, a := range []*Alert{alert("checkout", time.Minute), alert("cart", time.Hour)} {
+			r.Active[uint64(i)] = a
+		}
+		var sent []*Alert
+		r.SendAlerts(context.Background(), ts, time.Minute, time.Minute, func(ctx context.Context, expr string, alerts ...*Alert) {
+			sent = append(sent, alerts...)
+		})
+		return sent
+	}
+
+	sent := send(nil)
+	require.Len(t, sent, 2)
+	for _, a := range sent {
+		assert.Equal(t, "latency is back to normal", a.Annotations.Get("summary"))
+		assert.Equal(t, "https://runbooks/latency", a.Annotations.Get("runbook"))
+	}
+
+	// the alert flapping is not notified as resolved
+	sent = send(&ResolvedNotification{MinFiringDuration: Duration(5 * time.Minute)})
+	require.Len(t, sent, 1)
+	assert.Equal(t, "cart", sent[0].Labels.Get("service"))
+
+	require.Empty(t, send(&ResolvedNotification{Disabled: true}))
+	require.Error(t, (&ResolvedNotification{MinFiringDuration: Duration(-time.Minute)}).Validate())
+}
+
+func TestChannelResolvedNotifications(t *testing.T) {
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "admin@signoz.io"})
+
+	require.NoError(t, ruleDB.SetChannelResolvedNotification(ctx, ChannelResolvedNotification{Channel: "pagerduty", Disabled: true}))
+	require.NoError(t, ruleDB.SetChannelResolvedNotification(ctx, ChannelResolvedNotification{Channel: "slack", MinFiringDuration: Duration(10 * time.Minute)}))
+	require.NoError(t, ruleDB.SetChannelResolvedNotification(ctx, ChannelResolvedNotification{Channel: "slack", MinFiringDuration: Duration(5 * time.Minute)}))
+
+	m := &Manager{ruleDB: ruleDB}
+	settings := m.channelResolvedNotifications(ctx)
+	require.Len(t, settings, 2)
+	require.Equal(t, Duration(5*time.Minute), settings["slack"].MinFiringDuration)
+
+	ts := time.Now()
+	channels := []string{"pagerduty", "slack", "email"}
+	flapping := &Alert{FiredAt: ts.Add(-time.Minute), ResolvedAt: ts}
+	assert.Equal(t, []string{"email"}, filterResolvedChannels(settings, channels, flapping))
+	resolved := &Alert{FiredAt: ts.Add(-time.Hour), ResolvedAt: ts}
+	assert.Equal(t, []string{"slack", "email"}, filterResolvedChannels(settings, channels, resolved))
+
+	require.NoError(t, ruleDB.DeleteChannelResolvedNotification(ctx, "pagerduty"))
+	require.Len(t, m.channelResolvedNotifications(ctx), 1)
+}
@@ -18,6 +18,10 @@ type Sample struct {
 	Metric labels.Labels
 
 	IsMissing bool
+
+	// Severity is the severity of the threshold the sample crossed, for the
+	// rules with a threshold per severity
+	Severity string
 }
 
 func (s Sample) String() string {
@@ -0,0 +1,216 @@
+package rules
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+// notificationRoutingID is the id of the one routing tree
+const notificationRoutingID = 1
+
+// NotificationRoute routes the alerts of the rules without channels to the
+// channels by their labels, the way the routes of the alertmanager do. An
+// alert matching the route goes down the first of its routes it matches, and
+// down the next ones it matches as well while they continue. The alert is
+// sent to the channels of the routes it ends in, the route itself when it
+// matches none of its routes. A route without channels has the channels of
+// its parent.
+//
+// The root route matches all the alerts, its channels being the default ones.
+type NotificationRoute struct {
+	Name     string              `json:"name,omitempty"`
+	Matchers MaintenanceMatchers `json:"matchers,omitempty"`
+	Channels []string            `json:"channels,omitempty"`
+	// Continue goes on to the next routes once the alert matched the route
+	Continue bool                `json:"continue,omitempty"`
+	Routes   []NotificationRoute `json:"routes,omitempty"`
+}
+
+func (r *NotificationRoute) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, r)
+	}
+	return nil
+}
+
+func (r *NotificationRoute) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Validate checks the route as the root of the routing tree
+func (r *NotificationRoute) Validate() error {
+	if len(r.Matchers) > 0 {
+		return fmt.Errorf("the root route matches all the alerts and can't have matchers")
+	}
+	if r.Continue {
+		return fmt.Errorf("the root route can't continue")
+	}
+	return r.validate("root")
+}
+
+func (r *NotificationRoute) validate(path string) error {
+	for _, matcher := range r.Matchers {
+		if err := matcher.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid route %s", path)
+		}
+	}
+	if len(r.Channels) == 0 && len(r.Routes) == 0 {
+		return fmt.Errorf("the route %s has no channels and no routes", path)
+	}
+	for i := range r.Routes {
+		route := &r.Routes[i]
+		name := route.Name
+		if name == "" {
+			name = fmt.Sprint(i)
+		}
+		if len(route.Matchers) == 0 && route.Continue {
+			return fmt.Errorf("the route %s/%s matches all the alerts and can't continue", path, name)
+		}
+		if err := route.validate(path + "/" + name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// channelNames returns the names of the channels of the routes, leaving out
+// the on-call schedules
+func (r *NotificationRoute) channelNames() []string {
+	var names []string
+	for _, channel := range r.Channels {
+		if !strings.HasPrefix(channel, OnCallChannelPrefix) {
+			names = append(names, channel)
+		}
+	}
+	for i := range r.Routes {
+		names = append(names, r.Routes[i].channelNames()...)
+	}
+	return names
+}
+
+// matches tells if the route matches the alert with the labels
+func (r *NotificationRoute) matches(lbls labels.BaseLabels) bool {
+	return len(r.Matchers) == 0 || r.Matchers.matches(lbls)
+}
+
+// channels returns the channels the alert with the labels, which the route
+// matches, is routed to
+func (r *NotificationRoute) channels(lbls labels.BaseLabels, parentChannels []string) []string {
+	own := r.Channels
+	if len(own) == 0 {
+		own = parentChannels
+	}
+
+	var channels []string
+	matched := false
+	for i := range r.Routes {
+		route := &r.Routes[i]
+		if !route.matches(lbls) {
+			continue
+		}
+		matched = true
+		for _, channel := range route.channels(lbls, own) {
+			if !slices.Contains(channels, channel) {
+				channels = append(channels, channel)
+			}
+		}
+		if !route.Continue {
+			break
+		}
+	}
+	if !matched {
+		return own
+	}
+	return channels
+}
+
+// Route returns the channels the routing tree routes the alert with the
+// labels to
+func (r *NotificationRoute) Route(lbls map[string]string) []string {
+	return r.channels(labels.FromMap(lbls), nil)
+}
+
+// NotificationRouting is the routing tree of the notifications
+type NotificationRouting struct {
+	Route     *NotificationRoute `json:"route" db:"route"`
+	UpdatedAt time.Time          `json:"updatedAt" db:"updated_at"`
+	UpdatedBy string             `json:"updatedBy" db:"updated_by"`
+}
+
+// SetNotificationRouting replaces the routing tree of the notifications, the
+// channels of the routes have to exist
+func (m *Manager) SetNotificationRouting(ctx context.Context, route *NotificationRoute) error {
+	if err := route.Validate(); err != nil {
+		return err
+	}
+	existing := m.channelNames()
+	for _, channel := range route.channelNames() {
+		if !slices.Contains(existing, channel) {
+			return fmt.Errorf("the channel %s of the routes doesn't exist", channel)
+		}
+	}
+	return m.ruleDB.SetNotificationRouting(ctx, NotificationRouting{Route: route})
+}
+
+// routedChannels returns the channels of the alert, the channels the routing
+// tree routes it to when the rule has none
+func routedChannels(routing *NotificationRouting, alert *Alert) []string {
+	if len(alert.Receivers) > 0 || routing == nil || routing.Route == nil {
+		return alert.Receivers
+	}
+	return routing.Route.channels(alert.Labels, nil)
+}
+
+// notificationRouting returns the routing tree, nil if there is none
+func (m *Manager) notificationRouting(ctx context.Context) *NotificationRouting {
+	routing, err := m.ruleDB.GetNotificationRouting(ctx)
+	if err != nil {
+		zap.L().Error("failed to get the notification routing", zap.Error(err))
+		return nil
+	}
+	return routing
+}
+
+func (r *ruleDB) GetNotificationRouting(ctx context.Context) (*NotificationRouting, error) {
+	routing := &NotificationRouting{}
+
+	err := r.Get(routing, "SELECT route, updated_at, updated_by FROM notification_routing WHERE id=$1", notificationRoutingID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return routing, nil
+}
+
+func (r *ruleDB) SetNotificationRouting(ctx context.Context, routing NotificationRouting) error {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("no claims found in context")
+	}
+	routing.UpdatedBy = claims.Email
+	routing.UpdatedAt = time.Now()
+
+	query := "INSERT INTO notification_routing (id, route, updated_at, updated_by) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET route=$2, updated_at=$3, updated_by=$4"
+	_, err := r.Exec(query, notificationRoutingID, routing.Route, routing.UpdatedAt, routing.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+const testRoutingTree = `{
+	"channels": ["default"],
+	"routes": [
+		{
+			"name": "payments",
+			"matchers": [{"name": "team", "op": "=", "value": "payments"}],
+			"channels": ["payments-slack"],
+			"continue": true,
+			"routes": [
+				{"matchers": [{"name": "severity", "op": "=", "value": "critical"}], "channels": ["payments-pagerduty"]}
+			]
+		},
+		{
+			"name": "prod",
+			"matchers": [{"name": "env", "op": "=~", "value": "prod.*"}],
+			"routes": [
+				{"matchers": [{"name": "severity", "op": "!=", "value": "info"}], "channels": ["oncall:sre"]}
+			]
+		}
+	]
+}`
+
+func TestNotificationRoute(t *testing.T) {
+	var route NotificationRoute
+	require.NoError(t, json.Unmarshal([]byte(testRoutingTree), &route))
+	require.NoError(t, route.Validate())
+	assert.Equal(t, []string{"default", "payments-slack", "payments-pagerduty"}, route.channelNames())
+
+	tests := []struct {
+		labels   map[string]string
+		channels []string
+	}{
+		{map[string]string{"team": "search"}, []string{"default"}},
+		{map[string]string{"team": "payments", "severity": "warning"}, []string{"payments-slack"}},
+		{map[string]string{"team": "payments", "severity": "critical"}, []string{"payments-pagerduty"}},
+		// the payments route continues on to the prod route
+		{map[string]string{"team": "payments", "severity": "critical", "env": "production"}, []string{"payments-pagerduty", "oncall:sre"}},
+		// the prod route without channels has the default ones
+		{map[string]string{"env": "prod", "severity": "info"}, []string{"default"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.channels, route.Route(tt.labels), tt.labels)
+	}
+
+	// the channels of the rule take precedence over the routes
+	routing := &NotificationRouting{Route: &route}
+	alert := &Alert{Labels: labels.FromMap(map[string]string{"team": "payments"}), Receivers: []string{"rule-channel"}}
+	assert.Equal(t, []string{"rule-channel"}, routedChannels(routing, alert))
+	alert.Receivers = nil
+	assert.Equal(t, []string{"payments-slack"}, routedChannels(routing, alert))
+	assert.Empty(t, routedChannels(nil, alert))
+
+	route.Routes[1].Matchers = nil
+	route.Routes[1].Continue = true
+	require.Error(t, route.Validate())
+	route.Routes[1].Continue = false
+	route.Routes[1].Routes = nil
+	require.Error(t, route.Validate())
+	route.Matchers = MaintenanceMatchers{{Name: "env", Op: MatcherOpEqual, Value: "prod"}}
+	require.Error(t, route.Validate())
+}
+
+func TestNotificationRoutingDB(t *testing.T) {
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "admin@signoz.io"})
+
+	routing, err := ruleDB.GetNotificationRouting(ctx)
+	require.NoError(t, err)
+	require.Nil(t, routing)
+
+	route := &NotificationRoute{Channels: []string{"default"}}
+	require.NoError(t, ruleDB.SetNotificationRouting(ctx, NotificationRouting{Route: route}))
+	route.Channels = []string{"slack"}
+	require.NoError(t, ruleDB.SetNotificationRouting(ctx, NotificationRouting{Route: route}))
+
+	routing, err = ruleDB.GetNotificationRouting(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"slack"}, routing.Route.Channels)
+	require.Equal(t, "admin@signoz.io", routing.UpdatedBy)
+}
@@ -0,0 +1,201 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	logsv3 "go.signoz.io/signoz/pkg/query-service/app/logs/v3"
+	tracesV3 "go.signoz.io/signoz/pkg/query-service/app/traces/v3"
+	tracesV4 "go.signoz.io/signoz/pkg/query-service/app/traces/v4"
+	"go.signoz.io/signoz/pkg/query-service/contextlinks"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.uber.org/zap"
+)
+
+const (
+	// the annotations of the samples of the spans and the logs behind the
+	// alerts of the trace and log based rules
+	sampleTracesAnnotation = "sample_traces"
+	sampleLogsAnnotation   = "sample_logs"
+
+	sampleTracesLimit = 3
+	sampleLogsLimit   = 5
+	// sampleLogLineLength is the length log lines are cut to in the sample
+	sampleLogLineLength = 200
+	// maxSampledAlerts is the max number of new alerts sampled by an
+	// evaluation, the queries of the samples holding up the evaluation
+	maxSampledAlerts = 10
+	sampleTimeout    = 5 * time.Second
+)
+
+// sampleQueryParams returns the params of the query of the slowest spans or
+// the last logs of the series of the alert over the evaluation window, nil
+// for the rules not on traces or logs
+func (r *ThresholdRule) sampleQueryParams(ts time.Time, lbls labels.Labels) *v3.QueryRangeParamsV3 {
+	selectedQuery := r.GetSelectedQuery()
+	q := r.ruleCondition.CompositeQuery.BuilderQueries[selectedQuery]
+	if r.ruleCondition.QueryType() != v3.QueryTypeBuilder || q == nil {
+		return nil
+	}
+	if q.DataSource != v3.DataSourceTraces && q.DataSource != v3.DataSourceLogs {
+		return nil
+	}
+
+	qr, err := r.prepareQueryRange(ts)
+	if err != nil {
+		return nil
+	}
+
+	queryFilter := []v3.FilterItem{}
+	if q.Filters != nil {
+		queryFilter = q.Filters.Items
+	}
+	keys := r.logsKeys
+	if q.DataSource == v3.DataSourceTraces {
+		keys = r.spansKeys
+	}
+
+	sq := &v3.BuilderQuery{
+		QueryName:         selectedQuery,
+		StepInterval:      q.StepInterval,
+		DataSource:        q.DataSource,
+		AggregateOperator: v3.AggregateOperatorNoOp,
+		Filters: &v3.FilterSet{
+			Operator: "AND",
+			Items:    contextlinks.PrepareFilters(lbls.Map(), queryFilter, q.GroupBy, keys),
+		},
+		Expression: selectedQuery,
+	}
+	if q.DataSource == v3.DataSourceTraces {
+		sq.Limit = sampleTracesLimit
+		sq.PageSize = sampleTracesLimit
+		sq.SelectColumns = []v3.AttributeKey{
+			{Key: "serviceName", DataType: v3.AttributeKeyDataTypeString, Type: v3.AttributeKeyTypeTag, IsColumn: true},
+			{Key: "name", DataType: v3.AttributeKeyDataTypeString, Type: v3.AttributeKeyTypeTag, IsColumn: true},
+			{Key: "durationNano", DataType: v3.AttributeKeyDataTypeFloat64, Type: v3.AttributeKeyTypeTag, IsColumn: true},
+		}
+		sq.OrderBy = []v3.OrderBy{{ColumnName: "durationNano", Order: v3.DirectionDesc, IsColumn: true}}
+	} else {
+		sq.Limit = sampleLogsLimit
+		sq.PageSize = sampleLogsLimit
+		sq.OrderBy = []v3.OrderBy{{ColumnName: "timestamp", Order: v3.DirectionDesc, IsColumn: true}}
+	}
+
+	return &v3.QueryRangeParamsV3{
+		Start: qr.Start,
+		End:   qr.End,
+		Step:  qr.Step,
+		CompositeQuery: &v3.CompositeQuery{
+			QueryType:      v3.QueryTypeBuilder,
+			PanelType:      v3.PanelTypeList,
+			BuilderQueries: map[string]*v3.BuilderQuery{selectedQuery: sq},
+		},
+	}
+}
+
+// alertSample returns the sample annotation of the alert with the
+// fingerprint, the one of the alert when it is active and a new one for the
+// new alerts, up to the max per evaluation
+func (r *ThresholdRule) alertSample(ctx context.Context, ts time.Time, fp uint64, smpl Sample, sampled *int) (labels.Label, bool) {
+	if (r.typ != AlertTypeTraces && r.typ != AlertTypeLogs) || smpl.IsMissing {
+		return labels.Label{}, false
+	}
+	if alert, ok := r.Active[fp]; ok && alert.State != model.StateInactive {
+		for _, name := range []string{sampleTracesAnnotation, sampleLogsAnnotation} {
+			if alert.Annotations != nil && alert.Annotations.Has(name) {
+				return labels.Label{Name: name, Value: alert.Annotations.Get(name)}, true
+			}
+		}
+		return labels.Label{}, false
+	}
+	if *sampled >= maxSampledAlerts {
+		return labels.Label{}, false
+	}
+	*sampled++
+	return r.sampleAnnotation(ctx, ts, smpl.Metric)
+}
+
+// sampleAnnotation returns the annotation with the sample of the spans or the
+// logs of the series of the alert, gathered at the evaluation
+func (r *ThresholdRule) sampleAnnotation(ctx context.Context, ts time.Time, lbls labels.Labels) (labels.Label, bool) {
+	params := r.sampleQueryParams(ts, lbls)
+	if params == nil || r.reader == nil {
+		return labels.Label{}, false
+	}
+	dataSource := params.CompositeQuery.BuilderQueries[r.GetSelectedQuery()].DataSource
+	if dataSource == v3.DataSourceLogs {
+		logsv3.Enrich(params, r.logsKeys)
+	} else if r.useTraceNewSchema {
+		tracesV4.Enrich(params, r.spansKeys)
+	} else {
+		tracesV3.Enrich(params, r.spansKeys)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sampleTimeout)
+	defer cancel()
+
+	querier := r.querier
+	if r.version == "v4" {
+		querier = r.querierV2
+	}
+	results, _, err := querier.QueryRange(ctx, params)
+	if err != nil {
+		zap.L().Debug("failed to sample the alert", zap.String("ruleid", r.ID()), zap.Error(err))
+		return labels.Label{}, false
+	}
+
+	var rows []*v3.Row
+	for _, result := range results {
+		rows = append(rows, result.List...)
+	}
+	if len(rows) == 0 {
+		return labels.Label{}, false
+	}
+	if dataSource == v3.DataSourceLogs {
+		return labels.Label{Name: sampleLogsAnnotation, Value: formatSampleLogs(rows)}, true
+	}
+	return labels.Label{Name: sampleTracesAnnotation, Value: formatSampleTraces(rows, r.hostFromSource())}, true
+}
+
+// formatSampleTraces lists the trace ids of the spans, with a link to the
+// trace when the host of the rule is known
+func formatSampleTraces(rows []*v3.Row, host string) string {
+	lines := []string{}
+	for i, row := range rows {
+		if i == sampleTracesLimit {
+			break
+		}
+		traceID := fmt.Sprintf("%v", row.Data["traceID"])
+		line := fmt.Sprintf("%s %v %v", traceID, row.Data["serviceName"], row.Data["name"])
+		if duration, err := strconv.ParseFloat(fmt.Sprintf("%v", row.Data["durationNano"]), 64); err == nil {
+			line += fmt.Sprintf(" (%s)", time.Duration(duration).Round(time.Microsecond))
+		}
+		if host != "" {
+			line += fmt.Sprintf(" %s/trace/%s", host, traceID)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatSampleLogs lists the log lines with their timestamp, cut to the max
+// line length
+func formatSampleLogs(rows []*v3.Row) string {
+	lines := []string{}
+	for i, row := range rows {
+		if i == sampleLogsLimit {
+			break
+		}
+		body := strings.ReplaceAll(fmt.Sprintf("%v", row.Data["body"]), "\n", " ")
+		if len(body) > sampleLogLineLength {
+			body = body[:sampleLogLineLength] + "..."
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", row.Timestamp.UTC().Format(time.RFC3339), body))
+	}
+	return strings.Join(lines, "\n")
+}
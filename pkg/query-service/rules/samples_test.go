@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/featureManager"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+)
+
+func TestSampleQueryParams(t *testing.T) {
+	postableRule := PostableRule{
+		AlertName:  "Sample test",
+		AlertType:  AlertTypeTraces,
+		RuleType:   RuleTypeThreshold,
+		EvalWindow: Duration(5 * time.Minute),
+		Frequency:  Duration(1 * time.Minute),
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {
+						QueryName:          "A",
+						StepInterval:       60,
+						AggregateAttribute: v3.AttributeKey{Key: "durationNano"},
+						AggregateOperator:  v3.AggregateOperatorP99,
+						DataSource:         v3.DataSourceTraces,
+						GroupBy:            []v3.AttributeKey{{Key: "serviceName", IsColumn: true}},
+						Expression:         "A",
+					},
+				},
+			},
+			CompareOp: ValueIsAbove,
+			MatchType: AtleastOnce,
+			Target:    &[]float64{500}[0],
+		},
+	}
+	fm := featureManager.StartManager()
+	rule, err := NewThresholdRule("69", &postableRule, fm, nil, true, true)
+	require.NoError(t, err)
+
+	ts := time.UnixMilli(1705469040000)
+	params := rule.sampleQueryParams(ts, labels.Labels{{Name: "serviceName", Value: "checkout"}})
+	require.NotNil(t, params)
+	assert.Equal(t, v3.PanelTypeList, params.CompositeQuery.PanelType)
+	q := params.CompositeQuery.BuilderQueries["A"]
+	assert.Equal(t, v3.AggregateOperatorNoOp, q.AggregateOperator)
+	assert.Equal(t, uint64(sampleTracesLimit), q.Limit)
+	assert.Equal(t, "durationNano", q.OrderBy[0].ColumnName)
+	require.Len(t, q.Filters.Items, 1)
+	assert.Equal(t, "checkout", q.Filters.Items[0].Value)
+
+	// the rules on metrics are not sampled
+	postableRule.RuleCondition.CompositeQuery.BuilderQueries["A"].DataSource = v3.DataSourceMetrics
+	rule, err = NewThresholdRule("69", &postableRule, fm, nil, true, true)
+	require.NoError(t, err)
+	assert.Nil(t, rule.sampleQueryParams(ts, labels.Labels{}))
+}
+
+func TestFormatSamples(t *testing.T) {
+	ts := time.Date(2024, 1, 17, 5, 24, 0, 0, time.UTC)
+	traces := formatSampleTraces([]*v3.Row{
+		{Timestamp: ts, Data: map[string]interface{}{"traceID": "4bf92f3577b34da6", "serviceName": "checkout", "name": "POST /pay", "durationNano": uint64(1500000000)}},
+		{Timestamp: ts, Data: map[string]interface{}{"traceID": "00f067aa0ba902b7", "serviceName": "checkout", "name": "POST /pay", "durationNano": uint64(900000000)}},
+	}, "https://signoz.example.com")
+	assert.Equal(t, "4bf92f3577b34da6 checkout POST /pay (1.5s) https://signoz.example.com/trace/4bf92f3577b34da6\n00f067aa0ba902b7 checkout POST /pay (900ms) https://signoz.example.com/trace/00f067aa0ba902b7", traces)
+
+	rows := []*v3.Row{}
+	for i := 0; i < 7; i++ {
+		rows = append(rows, &v3.Row{Timestamp: ts, Data: map[string]interface{}{"body": "payment failed:\n" + strings.Repeat("x", 300)}})
+	}
+	logs := strings.Split(formatSampleLogs(rows), "\n")
+	require.Len(t, logs, sampleLogsLimit)
+	assert.True(t, strings.HasPrefix(logs[0], "2024-01-17T05:24:00Z payment failed: xxx"))
+	assert.True(t, strings.HasSuffix(logs[0], "..."))
+}
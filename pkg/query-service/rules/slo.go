@@ -0,0 +1,478 @@
+package rules
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+const (
+	// SLOLabel is set on the alerts of the rules generated for an SLO
+	SLOLabel = "slo"
+	// SLOBurnRateLabel is the burn rate the generated rule alerts at
+	SLOBurnRateLabel = "burn_rate"
+
+	// sloErrorRatioQuery is the formula of the error ratio in the generated
+	// rules
+	sloErrorRatioQuery = "F1"
+)
+
+var ErrMissingSLI = errors.New("missing sli")
+
+// sloBurnRateAlerts are the multi-window multi-burn-rate alerts of the SRE
+// workbook. An alert fires when the error budget burns faster than its burn
+// rate over both its long and its short window, the short window resolving
+// the alert soon after the burn stops. The burn rates page at 2% and 5% of a
+// 30 day budget spent, and open a ticket at 10%.
+var sloBurnRateAlerts = []struct {
+	long     time.Duration
+	short    time.Duration
+	burnRate float64
+	severity string
+}{
+	{time.Hour, 5 * time.Minute, 14.4, "critical"},
+	{6 * time.Hour, 30 * time.Minute, 6, "critical"},
+	{24 * time.Hour, 2 * time.Hour, 3, "warning"},
+	{72 * time.Hour, 6 * time.Hour, 1, "warning"},
+}
+
+// SLO is a service level objective, the share of the events the SLI counts
+// which are good over a rolling window. The burn rate alert rules of the SLO
+// are generated from it, and are replaced when it changes.
+type SLO struct {
+	Id          int64  `json:"id" db:"id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+	SLI         *SLI   `json:"sli" db:"sli"`
+	// Target is the percentage of good events, 99.9 for instance
+	Target            float64      `json:"target" db:"target"`
+	Window            Duration     `json:"window" db:"time_window"`
+	PreferredChannels *SLOChannels `json:"preferredChannels" db:"preferred_channels"`
+	RuleIds           *SLORuleIds  `json:"ruleIds" db:"rule_ids"`
+	CreatedAt         time.Time    `json:"createdAt" db:"created_at"`
+	CreatedBy         string       `json:"createdBy" db:"created_by"`
+	UpdatedAt         time.Time    `json:"updatedAt" db:"updated_at"`
+	UpdatedBy         string       `json:"updatedBy" db:"updated_by"`
+}
+
+// SLI counts the bad and the total events with two builder queries of the
+// composite query, the errors and the requests of a service for instance.
+// The queries return counts or rates over the same steps.
+type SLI struct {
+	CompositeQuery *v3.CompositeQuery `json:"compositeQuery"`
+	BadQuery       string             `json:"badQuery"`
+	TotalQuery     string             `json:"totalQuery"`
+}
+
+func (s *SLI) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, s)
+	}
+	return nil
+}
+
+func (s *SLI) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+type SLOChannels []string
+
+func (c *SLOChannels) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, c)
+	}
+	return nil
+}
+
+func (c *SLOChannels) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+type SLORuleIds []string
+
+func (r *SLORuleIds) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, r)
+	}
+	return nil
+}
+
+func (r *SLORuleIds) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (s *SLO) Validate() error {
+	if s.Name == "" {
+		return ErrMissingName
+	}
+	if s.Target <= 0 || s.Target >= 100 {
+		return fmt.Errorf("the target must be a percentage between 0 and 100")
+	}
+	if time.Duration(s.Window) < 24*time.Hour {
+		return fmt.Errorf("the window must be at least a day")
+	}
+	if s.SLI == nil || s.SLI.CompositeQuery == nil {
+		return ErrMissingSLI
+	}
+	if s.SLI.BadQuery == "" || s.SLI.TotalQuery == "" || s.SLI.BadQuery == s.SLI.TotalQuery {
+		return fmt.Errorf("the sli needs a bad and a total query")
+	}
+	for _, name := range []string{s.SLI.BadQuery, s.SLI.TotalQuery} {
+		q, ok := s.SLI.CompositeQuery.BuilderQueries[name]
+		if !ok {
+			return fmt.Errorf("the sli is missing the builder query %s", name)
+		}
+		if q.QueryName != q.Expression || name == sloErrorRatioQuery {
+			return fmt.Errorf("the sli query %s can't be a formula", name)
+		}
+	}
+	if s.SLI.CompositeQuery.BuilderQueries[s.SLI.BadQuery].DataSource != s.SLI.CompositeQuery.BuilderQueries[s.SLI.TotalQuery].DataSource {
+		return fmt.Errorf("the sli queries must have the same data source")
+	}
+	return nil
+}
+
+// errorBudget is the share of the events which can be bad
+func (s *SLO) errorBudget() float64 {
+	return 1 - s.Target/100
+}
+
+// sliQuery returns a copy of the composite query of the SLI with only its bad
+// and total queries, with the step
+func (s *SLO) sliQuery(step time.Duration) (*v3.CompositeQuery, error) {
+	data, err := json.Marshal(s.SLI.CompositeQuery)
+	if err != nil {
+		return nil, err
+	}
+	cq := &v3.CompositeQuery{}
+	if err := json.Unmarshal(data, cq); err != nil {
+		return nil, err
+	}
+
+	for name, q := range cq.BuilderQueries {
+		if name != s.SLI.BadQuery && name != s.SLI.TotalQuery {
+			delete(cq.BuilderQueries, name)
+			continue
+		}
+		q.StepInterval = int64(step.Seconds())
+		q.Disabled = false
+	}
+	cq.QueryType = v3.QueryTypeBuilder
+	cq.PanelType = v3.PanelTypeGraph
+	cq.ClickHouseQueries = nil
+	cq.PromQueries = nil
+	return cq, nil
+}
+
+// alertType is the alert type of the data source of the SLI
+func (s *SLO) alertType() AlertType {
+	switch s.SLI.CompositeQuery.BuilderQueries[s.SLI.TotalQuery].DataSource {
+	case v3.DataSourceLogs:
+		return AlertTypeLogs
+	case v3.DataSourceTraces:
+		return AlertTypeTraces
+	}
+	return AlertTypeMetric
+}
+
+// GenerateRules returns the burn rate alert rules of the SLO. The error ratio
+// over the long window of a rule is the average of the ratios over its short
+// windows, and the error ratio over the short window is the last of them.
+func (s *SLO) GenerateRules() ([]*PostableRule, error) {
+	var rules []*PostableRule
+	for _, alert := range sloBurnRateAlerts {
+		if alert.long > time.Duration(s.Window) {
+			continue
+		}
+
+		cq, err := s.sliQuery(alert.short)
+		if err != nil {
+			return nil, err
+		}
+		cq.BuilderQueries[sloErrorRatioQuery] = &v3.BuilderQuery{
+			QueryName:  sloErrorRatioQuery,
+			Expression: fmt.Sprintf("%s/%s", s.SLI.BadQuery, s.SLI.TotalQuery),
+		}
+		for _, name := range []string{s.SLI.BadQuery, s.SLI.TotalQuery} {
+			cq.BuilderQueries[name].Disabled = true
+		}
+
+		threshold := alert.burnRate * s.errorBudget()
+		frequency := time.Minute
+		if alert.severity != "critical" {
+			frequency = 5 * time.Minute
+		}
+		burnRate := strconv.FormatFloat(alert.burnRate, 'f', -1, 64)
+
+		rule := &PostableRule{
+			AlertName:  fmt.Sprintf("%s error budget burn rate over %s", s.Name, alert.long),
+			AlertType:  s.alertType(),
+			RuleType:   RuleTypeThreshold,
+			EvalWindow: Duration(alert.long),
+			Frequency:  Duration(frequency),
+			RuleCondition: &RuleCondition{
+				CompositeQuery: cq,
+				SelectedQuery:  sloErrorRatioQuery,
+				Composite: &CompositeCondition{
+					Op: LogicalOpAnd,
+					Conditions: []CompositeCondition{
+						{QueryName: sloErrorRatioQuery, CompareOp: ValueIsAbove, MatchType: OnAverage, Target: &threshold},
+						{QueryName: sloErrorRatioQuery, CompareOp: ValueIsAbove, MatchType: Last, Target: &threshold},
+					},
+				},
+			},
+			Labels: map[string]string{
+				labels.AlertSeverityLabel: alert.severity,
+				SLOLabel:                  s.Name,
+				SLOBurnRateLabel:          burnRate,
+			},
+			Annotations: map[string]string{
+				labels.AlertSummaryLabel: fmt.Sprintf("The SLO %s is burning its error budget %sx faster than it can over the last %s and %s", s.Name, burnRate, alert.long, alert.short),
+				"description":            fmt.Sprintf("The error ratio is {{$value}}, above %s which is %sx the error budget of the %s%% target", strconv.FormatFloat(threshold, 'g', 4, 64), burnRate, strconv.FormatFloat(s.Target, 'f', -1, 64)),
+			},
+		}
+		if s.PreferredChannels != nil {
+			rule.PreferredChannels = *s.PreferredChannels
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// StatusQueryRangeParams returns the params of the query of the bad and total
+// events of the SLI over its window up to ts
+func (s *SLO) StatusQueryRangeParams(ts time.Time) (*v3.QueryRangeParamsV3, error) {
+	end := ts.Truncate(time.Minute)
+	start := end.Add(-time.Duration(s.Window))
+	cq, err := s.sliQuery(time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	return &v3.QueryRangeParamsV3{
+		Start:          start.UnixMilli(),
+		End:            end.UnixMilli(),
+		Step:           int64(time.Hour.Seconds()),
+		CompositeQuery: cq,
+		Variables:      map[string]interface{}{},
+		NoCache:        true,
+	}, nil
+}
+
+// SLOStatus is how the SLO is doing over its window
+type SLOStatus struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Bad   float64   `json:"bad"`
+	Total float64   `json:"total"`
+	// SLI is the percentage of good events
+	SLI    float64 `json:"sli"`
+	Target float64 `json:"target"`
+	// BurnRate is how fast the error budget was spent, 1 spending all of it
+	// over the window
+	BurnRate float64 `json:"burnRate"`
+	// ErrorBudgetRemaining is the percentage of the error budget left,
+	// negative once the SLO is breached
+	ErrorBudgetRemaining float64 `json:"errorBudgetRemaining"`
+}
+
+// NewSLOStatus computes the status of the SLO from the results of the query
+// of StatusQueryRangeParams
+func NewSLOStatus(s *SLO, params *v3.QueryRangeParamsV3, results []*v3.Result) *SLOStatus {
+	status := &SLOStatus{
+		Start:                time.UnixMilli(params.Start),
+		End:                  time.UnixMilli(params.End),
+		SLI:                  100,
+		Target:               s.Target,
+		ErrorBudgetRemaining: 100,
+	}
+	for _, result := range results {
+		var sum float64
+		for _, series := range result.Series {
+			for _, point := range series.Points {
+				if !math.IsNaN(point.Value) && !math.IsInf(point.Value, 0) {
+					sum += point.Value
+				}
+			}
+		}
+		switch result.QueryName {
+		case s.SLI.BadQuery:
+			status.Bad = sum
+		case s.SLI.TotalQuery:
+			status.Total = sum
+		}
+	}
+
+	if status.Total > 0 {
+		errorRatio := status.Bad / status.Total
+		status.SLI = 100 * (1 - errorRatio)
+		status.BurnRate = errorRatio / s.errorBudget()
+		status.ErrorBudgetRemaining = 100 * (1 - status.BurnRate)
+	}
+	return status
+}
+
+// CreateSLO stores the SLO and creates its rules
+func (m *Manager) CreateSLO(ctx context.Context, slo SLO) (int64, error) {
+	ruleIds, err := m.createSLORules(ctx, &slo)
+	if err != nil {
+		return 0, err
+	}
+	slo.RuleIds = &ruleIds
+
+	id, err := m.ruleDB.CreateSLO(ctx, slo)
+	if err != nil {
+		m.deleteSLORules(ctx, ruleIds)
+		return 0, err
+	}
+	return id, nil
+}
+
+// EditSLO updates the SLO and replaces its rules with the ones generated
+// from the update
+func (m *Manager) EditSLO(ctx context.Context, slo SLO, id string) error {
+	existing, err := m.ruleDB.GetSLOByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	ruleIds, err := m.createSLORules(ctx, &slo)
+	if err != nil {
+		return err
+	}
+	slo.RuleIds = &ruleIds
+
+	if err := m.ruleDB.EditSLO(ctx, slo, id); err != nil {
+		m.deleteSLORules(ctx, ruleIds)
+		return err
+	}
+	if existing.RuleIds != nil {
+		m.deleteSLORules(ctx, *existing.RuleIds)
+	}
+	return nil
+}
+
+// DeleteSLO deletes the SLO along with its rules
+func (m *Manager) DeleteSLO(ctx context.Context, id string) error {
+	slo, err := m.ruleDB.GetSLOByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if slo.RuleIds != nil {
+		m.deleteSLORules(ctx, *slo.RuleIds)
+	}
+	return m.ruleDB.DeleteSLO(ctx, id)
+}
+
+// createSLORules creates the rules of the SLO, none of them if one fails
+func (m *Manager) createSLORules(ctx context.Context, slo *SLO) (SLORuleIds, error) {
+	rules, err := slo.GenerateRules()
+	if err != nil {
+		return nil, err
+	}
+
+	ruleIds := SLORuleIds{}
+	for _, rule := range rules {
+		ruleStr, err := json.Marshal(rule)
+		if err != nil {
+			m.deleteSLORules(ctx, ruleIds)
+			return nil, err
+		}
+		created, err := m.CreateRule(ctx, string(ruleStr))
+		if err != nil {
+			m.deleteSLORules(ctx, ruleIds)
+			return nil, fmt.Errorf("failed to create the rule %s: %w", rule.AlertName, err)
+		}
+		ruleIds = append(ruleIds, created.Id)
+	}
+	return ruleIds, nil
+}
+
+func (m *Manager) deleteSLORules(ctx context.Context, ruleIds SLORuleIds) {
+	for _, id := range ruleIds {
+		if err := m.DeleteRule(ctx, id); err != nil {
+			zap.L().Error("failed to delete the rule of the slo", zap.String("rule", id), zap.Error(err))
+		}
+	}
+}
+
+func (r *ruleDB) GetAllSLOs(ctx context.Context) ([]SLO, error) {
+	slos := []SLO{}
+
+	err := r.Select(&slos, "SELECT * FROM slos ORDER BY name")
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return slos, nil
+}
+
+func (r *ruleDB) GetSLOByID(ctx context.Context, id string) (*SLO, error) {
+	slo := &SLO{}
+
+	err := r.Get(slo, "SELECT * FROM slos WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return slo, nil
+}
+
+func (r *ruleDB) CreateSLO(ctx context.Context, slo SLO) (int64, error) {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return 0, errors.New("no claims found in context")
+	}
+	slo.CreatedBy = claims.Email
+	slo.CreatedAt = time.Now()
+	slo.UpdatedBy = claims.Email
+	slo.UpdatedAt = time.Now()
+
+	query := "INSERT INTO slos (name, description, sli, target, time_window, preferred_channels, rule_ids, created_at, created_by, updated_at, updated_by) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"
+	result, err := r.Exec(query, slo.Name, slo.Description, slo.SLI, slo.Target, slo.Window, slo.PreferredChannels, slo.RuleIds, slo.CreatedAt, slo.CreatedBy, slo.UpdatedAt, slo.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *ruleDB) EditSLO(ctx context.Context, slo SLO, id string) error {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("no claims found in context")
+	}
+	slo.UpdatedBy = claims.Email
+	slo.UpdatedAt = time.Now()
+
+	query := "UPDATE slos SET name=$1, description=$2, sli=$3, target=$4, time_window=$5, preferred_channels=$6, rule_ids=$7, updated_at=$8, updated_by=$9 WHERE id=$10"
+	_, err := r.Exec(query, slo.Name, slo.Description, slo.SLI, slo.Target, slo.Window, slo.PreferredChannels, slo.RuleIds, slo.UpdatedAt, slo.UpdatedBy, id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleDB) DeleteSLO(ctx context.Context, id string) error {
+	_, err := r.Exec("DELETE FROM slos WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/query-service/utils/labels"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func testSLO() SLO {
+	errorFilter := &v3.FilterSet{Operator: "AND", Items: []v3.FilterItem{
+		{Key: v3.AttributeKey{Key: "status_code", Type: v3.AttributeKeyTypeTag, DataType: v3.AttributeKeyDataTypeString}, Operator: v3.FilterOperatorEqual, Value: "STATUS_CODE_ERROR"},
+	}}
+	return SLO{
+		Name:   "checkout availability",
+		Target: 99.9,
+		Window: Duration(30 * 24 * time.Hour),
+		SLI: &SLI{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				PanelType: v3.PanelTypeGraph,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {
+						QueryName:          "A",
+						StepInterval:       60,
+						DataSource:         v3.DataSourceMetrics,
+						AggregateAttribute: v3.AttributeKey{Key: "signoz_calls_total"},
+						AggregateOperator:  v3.AggregateOperatorSumRate,
+						Filters:            errorFilter,
+						Expression:         "A",
+					},
+					"B": {
+						QueryName:          "B",
+						StepInterval:       60,
+						DataSource:         v3.DataSourceMetrics,
+						AggregateAttribute: v3.AttributeKey{Key: "signoz_calls_total"},
+						AggregateOperator:  v3.AggregateOperatorSumRate,
+						Expression:         "B",
+					},
+				},
+			},
+			BadQuery:   "A",
+			TotalQuery: "B",
+		},
+		PreferredChannels: &SLOChannels{"pagerduty"},
+	}
+}
+
+func TestSLOGenerateRules(t *testing.T) {
+	slo := testSLO()
+	require.NoError(t, slo.Validate())
+
+	rules, err := slo.GenerateRules()
+	require.NoError(t, err)
+	require.Len(t, rules, 4)
+
+	rule := rules[0]
+	assert.Equal(t, Duration(time.Hour), rule.EvalWindow)
+	assert.Equal(t, "critical", rule.Labels[labels.AlertSeverityLabel])
+	assert.Equal(t, "14.4", rule.Labels[SLOBurnRateLabel])
+	assert.Equal(t, []string{"pagerduty"}, rule.PreferredChannels)
+	assert.Equal(t, "A/B", rule.RuleCondition.CompositeQuery.BuilderQueries[sloErrorRatioQuery].Expression)
+	assert.Equal(t, int64(300), rule.RuleCondition.CompositeQuery.BuilderQueries["A"].StepInterval)
+	assert.InDelta(t, 0.0144, *rule.RuleCondition.Composite.Conditions[0].Target, 1e-9)
+	// the query of the slo is left as is
+	assert.Equal(t, int64(60), slo.SLI.CompositeQuery.BuilderQueries["A"].StepInterval)
+
+	for _, rule := range rules {
+		data, err := json.Marshal(rule)
+		require.NoError(t, err)
+		_, err = ParsePostableRule(data)
+		require.NoError(t, err, rule.AlertName)
+	}
+
+	// the windows longer than the slo are left out
+	slo.Window = Duration(24 * time.Hour)
+	rules, err = slo.GenerateRules()
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	slo.SLI.TotalQuery = "A"
+	require.Error(t, slo.Validate())
+}
+
+func TestNewSLOStatus(t *testing.T) {
+	slo := testSLO()
+	params, err := slo.StatusQueryRangeParams(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3600), params.CompositeQuery.BuilderQueries["A"].StepInterval)
+
+	status := NewSLOStatus(&slo, params, []*v3.Result{
+		{QueryName: "A", Series: []*v3.Series{{Points: []v3.Point{{Value: 2}, {Value: 3}}}}},
+		{QueryName: "B", Series: []*v3.Series{{Points: []v3.Point{{Value: 5000}, {Value: 5000}}}}},
+	})
+	assert.InDelta(t, 99.95, status.SLI, 1e-9)
+	assert.InDelta(t, 0.5, status.BurnRate, 1e-9)
+	assert.InDelta(t, 50, status.ErrorBudgetRemaining, 1e-9)
+}
+
+func TestSLODB(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: "admin@signoz.io"})
+
+	slo := testSLO()
+	slo.RuleIds = &SLORuleIds{"1", "2"}
+	id, err := ruleDB.CreateSLO(ctx, slo)
+	require.NoError(err)
+
+	stored, err := ruleDB.GetSLOByID(ctx, strconv.FormatInt(id, 10))
+	require.NoError(err)
+	require.Equal(slo.Name, stored.Name)
+	require.Equal(slo.Window, stored.Window)
+	require.Equal("B", stored.SLI.TotalQuery)
+	require.Equal(SLORuleIds{"1", "2"}, *stored.RuleIds)
+
+	slo.Target = 99
+	require.NoError(ruleDB.EditSLO(ctx, slo, strconv.FormatInt(id, 10)))
+	slos, err := ruleDB.GetAllSLOs(ctx)
+	require.NoError(err)
+	require.Len(slos, 1)
+	require.Equal(float64(99), slos[0].Target)
+
+	require.NoError(ruleDB.DeleteSLO(ctx, strconv.FormatInt(id, 10)))
+	slos, err = ruleDB.GetAllSLOs(ctx)
+	require.NoError(err)
+	require.Empty(slos)
+}
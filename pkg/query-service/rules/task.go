@@ -2,6 +2,7 @@ package rules
 
 import (
 	"context"
+	"math/rand"
 	"time"
 )
 
@@ -35,3 +36,24 @@ func newTask(taskType TaskType, name, file string, frequency time.Duration, rule
 	}
 	return NewPromRuleTask(name, file, frequency, rules, opts, notify, ruleDB)
 }
+
+// waitEvalJitter puts off the evaluation by a random time up to the jitter of
+// the rule manager, at most half the frequency so the evaluations don't run
+// into the next ones. The evaluation time of the task stays the same. It
+// returns false when the task is stopped while waiting.
+func waitEvalJitter(opts *ManagerOptions, frequency time.Duration, done <-chan struct{}) bool {
+	if opts == nil || opts.EvalJitter <= 0 {
+		return true
+	}
+	jitter := min(opts.EvalJitter, frequency/2)
+	if jitter <= 0 {
+		return true
+	}
+
+	select {
+	case <-time.After(time.Duration(rand.Int63n(int64(jitter)))):
+		return true
+	case <-done:
+		return false
+	}
+}
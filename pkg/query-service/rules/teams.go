@@ -0,0 +1,242 @@
+package rules
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.signoz.io/signoz/pkg/query-service/common"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+	"go.uber.org/zap"
+)
+
+// Team is a group of users owning rules. The rules owned by a team can only
+// be changed or deleted by its members and the admins, the other rules by
+// all of the editors.
+type Team struct {
+	Id          int64       `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Description string      `json:"description" db:"description"`
+	Members     TeamMembers `json:"members" db:"members"`
+	CreatedAt   time.Time   `json:"createdAt" db:"created_at"`
+	CreatedBy   string      `json:"createdBy" db:"created_by"`
+	UpdatedAt   time.Time   `json:"updatedAt" db:"updated_at"`
+	UpdatedBy   string      `json:"updatedBy" db:"updated_by"`
+}
+
+// TeamMembers are the emails of the members of a team
+type TeamMembers []string
+
+func (m *TeamMembers) Scan(src interface{}) error {
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, m)
+	}
+	if data, ok := src.(string); ok {
+		return json.Unmarshal([]byte(data), m)
+	}
+	return nil
+}
+
+func (m TeamMembers) Value() (driver.Value, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (t *Team) Validate() error {
+	if t.Name == "" {
+		return ErrMissingName
+	}
+	if len(t.Members) == 0 {
+		return fmt.Errorf("the team has no members")
+	}
+	for i, member := range t.Members {
+		if !strings.Contains(member, "@") {
+			return fmt.Errorf("invalid member %q, the members are set by their email", member)
+		}
+		t.Members[i] = strings.ToLower(member)
+	}
+	return nil
+}
+
+// HasMember tells if the user with the email is a member of the team
+func (t *Team) HasMember(email string) bool {
+	return slices.Contains(t.Members, strings.ToLower(email))
+}
+
+// DeleteTeam deletes the team, unless it owns rules
+func (m *Manager) DeleteTeam(ctx context.Context, id string) error {
+	team, err := m.ruleDB.GetTeamByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	storedRules, err := m.ruleDB.GetStoredRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, storedRule := range storedRules {
+		rule := PostableRule{}
+		if err := json.Unmarshal([]byte(storedRule.Data), &rule); err != nil {
+			continue
+		}
+		if rule.Team == team.Name {
+			return fmt.Errorf("the team owns the rule %s", rule.AlertName)
+		}
+	}
+
+	return m.ruleDB.DeleteTeam(ctx, id)
+}
+
+// checkRuleTeam checks the user can change the rules of the team, which is
+// left to the members of the team and the admins. The rules without a team
+// and the changes made without a user, such as by the rule manager itself,
+// are not checked.
+func (m *Manager) checkRuleTeam(ctx context.Context, teamName string) error {
+	if teamName == "" {
+		return nil
+	}
+	user := common.GetUserFromContext(ctx)
+	if user == nil {
+		return nil
+	}
+
+	team, err := m.ruleDB.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if team == nil {
+		return fmt.Errorf("team %s not found", teamName)
+	}
+	if user.Role == constants.AdminGroup || team.HasMember(user.Email) {
+		return nil
+	}
+	return model.ForbiddenError(fmt.Errorf("the rule is owned by the team %s, only its members or an admin can change it", teamName))
+}
+
+// checkStoredRuleTeam checks the user can change the stored rule
+func (m *Manager) checkStoredRuleTeam(ctx context.Context, id string) error {
+	storedRule, err := m.ruleDB.GetStoredRule(ctx, id)
+	if err != nil {
+		return err
+	}
+	rule := PostableRule{}
+	if err := json.Unmarshal([]byte(storedRule.Data), &rule); err != nil {
+		return err
+	}
+	return m.checkRuleTeam(ctx, rule.Team)
+}
+
+func (r *ruleDB) GetAllTeams(ctx context.Context) ([]Team, error) {
+	teams := []Team{}
+
+	err := r.Select(&teams, "SELECT * FROM teams ORDER BY name")
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (r *ruleDB) GetTeamByID(ctx context.Context, id string) (*Team, error) {
+	team := &Team{}
+
+	err := r.Get(team, "SELECT * FROM teams WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (r *ruleDB) GetTeamByName(ctx context.Context, name string) (*Team, error) {
+	team := &Team{}
+
+	err := r.Get(team, "SELECT * FROM teams WHERE name=$1", name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (r *ruleDB) CreateTeam(ctx context.Context, team Team) (int64, error) {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return 0, errors.New("no claims found in context")
+	}
+	team.CreatedBy = claims.Email
+	team.CreatedAt = time.Now()
+	team.UpdatedBy = claims.Email
+	team.UpdatedAt = time.Now()
+
+	query := "INSERT INTO teams (name, description, members, created_at, created_by, updated_at, updated_by) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	result, err := r.Exec(query, team.Name, team.Description, team.Members, team.CreatedAt, team.CreatedBy, team.UpdatedAt, team.UpdatedBy)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// EditTeam changes the description and the members of the team, the name of
+// a team is kept as the rules are owned by it
+func (r *ruleDB) EditTeam(ctx context.Context, team Team, id string) error {
+	claims, ok := authtypes.ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("no claims found in context")
+	}
+	team.UpdatedBy = claims.Email
+	team.UpdatedAt = time.Now()
+
+	stored, err := r.GetTeamByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stored.Name != team.Name {
+		return fmt.Errorf("the name of a team cannot be changed")
+	}
+
+	query := "UPDATE teams SET description=$1, members=$2, updated_at=$3, updated_by=$4 WHERE id=$5"
+	_, err = r.Exec(query, team.Description, team.Members, team.UpdatedAt, team.UpdatedBy, id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTeam deletes the team along with its digests
+func (r *ruleDB) DeleteTeam(ctx context.Context, id string) error {
+	_, err := r.Exec("DELETE FROM teams WHERE id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	_, err = r.Exec("DELETE FROM alert_digests WHERE team_id=$1", id)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/constants"
+	"go.signoz.io/signoz/pkg/query-service/model"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+	"go.signoz.io/signoz/pkg/types/authtypes"
+)
+
+func TestRuleTeams(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	m := &Manager{opts: &ManagerOptions{DisableRules: true}, tasks: map[string]Task{}, rules: map[string]Rule{}, ruleDB: ruleDB}
+
+	userCtx := func(email, role string) context.Context {
+		ctx := authtypes.NewContextWithClaims(context.Background(), authtypes.Claims{Email: email})
+		return context.WithValue(ctx, constants.ContextUserKey, &model.UserPayload{
+			User: model.User{Id: email, Email: email},
+			Role: role,
+		})
+	}
+	aliceCtx := userCtx("alice@signoz.io", "EDITOR")
+	bobCtx := userCtx("bob@signoz.io", "EDITOR")
+	adminCtx := userCtx("admin@signoz.io", constants.AdminGroup)
+
+	team := Team{Name: "platform", Members: TeamMembers{"Alice@signoz.io"}}
+	require.NoError(team.Validate())
+	teamID, err := ruleDB.CreateTeam(adminCtx, team)
+	require.NoError(err)
+	stored, err := ruleDB.GetTeamByName(adminCtx, "platform")
+	require.NoError(err)
+	require.True(stored.HasMember("alice@signoz.io"))
+	require.Error((&Team{Name: "sre", Members: TeamMembers{"bob"}}).Validate())
+
+	forbidden := func(err error) {
+		var apiErr *model.ApiError
+		require.True(errors.As(err, &apiErr), err)
+		require.Equal(model.ErrorForbidden, apiErr.Type())
+	}
+
+	ruleStr := `{"alert": "kafka lag", "ruleType": "promql_rule", "team": "platform", "condition": {"compositeQuery": {"queryType": "promql", "panelType": "graph", "promQueries": {"A": {"query": "kafka_lag"}}}, "op": "1", "target": 100, "matchType": "1"}}`
+	_, err = m.CreateRule(bobCtx, ruleStr)
+	forbidden(err)
+	_, err = m.CreateRule(aliceCtx, `{"alert": "kafka lag", "team": "unknown"}`)
+	require.Error(err)
+
+	rule, err := m.CreateRule(aliceCtx, ruleStr)
+	require.NoError(err)
+
+	// the editors out of the team can't change the rule of the team, nor
+	// take it from the team
+	forbidden(m.EditRule(bobCtx, ruleStr, rule.Id))
+	_, err = m.PatchRule(bobCtx, `{"team": ""}`, rule.Id)
+	forbidden(err)
+	_, err = m.PauseRule(bobCtx, rule.Id, "noisy", nil)
+	forbidden(err)
+	forbidden(m.DeleteRule(bobCtx, rule.Id))
+
+	require.NoError(m.EditRule(aliceCtx, ruleStr, rule.Id))
+	require.Error(m.DeleteTeam(adminCtx, fmt.Sprintf("%d", teamID)))
+
+	_, err = m.PatchRule(adminCtx, `{"team": "", "disabled": true}`, rule.Id)
+	require.NoError(err)
+	require.NoError(m.DeleteRule(bobCtx, rule.Id))
+	require.NoError(m.DeleteTeam(adminCtx, fmt.Sprintf("%d", teamID)))
+}
@@ -38,7 +38,7 @@ func defaultTestNotification(opts PrepareTestRuleOptions) (int, *model.ApiError)
 	if parsedRule.RuleType == RuleTypeThreshold {
 
 		// add special labels for test alerts
-		parsedRule.Annotations[labels.AlertSummaryLabel] = fmt.Sprintf("The rule threshold is set to %.4f, and the observed metric value is {{$value}}.", *parsedRule.RuleCondition.Target)
+		parsedRule.Annotations[labels.AlertSummaryLabel] = TestAlertSummary(parsedRule.RuleCondition)
 		parsedRule.Labels[labels.RuleSourceLabel] = ""
 		parsedRule.Labels[labels.AlertRuleIdLabel] = ""
 
@@ -76,6 +76,21 @@ func defaultTestNotification(opts PrepareTestRuleOptions) (int, *model.ApiError)
 			zap.L().Error("failed to prepare a new promql rule for test", zap.String("name", rule.Name()), zap.Error(err))
 			return 0, model.BadRequest(err)
 		}
+	} else if parsedRule.RuleType == RuleTypeLogPattern {
+		// create a log pattern rule
+		rule, err = NewLogPatternRule(
+			alertname,
+			parsedRule,
+			opts.FF,
+			opts.Reader,
+			nil,
+			WithSendAlways(),
+			WithSendUnmatched(),
+		)
+		if err != nil {
+			zap.L().Error("failed to prepare a new log pattern rule for test", zap.String("name", rule.Name()), zap.Error(err))
+			return 0, model.BadRequest(err)
+		}
 	} else {
 		return 0, model.BadRequest(fmt.Errorf("failed to derive ruletype with given information"))
 	}
@@ -96,3 +111,12 @@ func defaultTestNotification(opts PrepareTestRuleOptions) (int, *model.ApiError)
 
 	return alertsFound, nil
 }
+
+// TestAlertSummary describes the threshold the test alert was sent for, which
+// depends on the severity for the rules with a threshold per severity
+func TestAlertSummary(rc *RuleCondition) string {
+	if rc.Target == nil {
+		return "The rule threshold is set to {{$threshold}}, and the observed metric value is {{$value}}."
+	}
+	return fmt.Sprintf("The rule threshold is set to %.4f, and the observed metric value is {{$value}}.", *rc.Target)
+}
@@ -16,6 +16,7 @@ import (
 	"go.signoz.io/signoz/pkg/query-service/model"
 	"go.signoz.io/signoz/pkg/query-service/postprocess"
 
+	"go.signoz.io/signoz/pkg/query-service/app/macros"
 	"go.signoz.io/signoz/pkg/query-service/app/querier"
 	querierV2 "go.signoz.io/signoz/pkg/query-service/app/querier/v2"
 	"go.signoz.io/signoz/pkg/query-service/app/queryBuilder"
@@ -53,6 +54,12 @@ type ThresholdRule struct {
 	spansKeys map[string]v3.AttributeKey
 
 	useTraceNewSchema bool
+
+	// noData tells the last query returned no data, and seriesFPs are the
+	// fingerprints of the series it returned otherwise, which tell the
+	// alerts of absent series from the ones no longer meeting the condition
+	noData    bool
+	seriesFPs map[uint64]struct{}
 }
 
 func NewThresholdRule(
@@ -267,6 +274,12 @@ func (r *ThresholdRule) buildAndRunQuery(ctx context.Context, ts time.Time) (Vec
 	if err != nil {
 		return nil, err
 	}
+	// the macros are expanded in a copy of the queries of the rule, so that
+	// the changes of the macros apply to the next evaluations
+	params.CompositeQuery = params.CompositeQuery.Clone()
+	if err := macros.Expand(ctx, params); err != nil {
+		return nil, err
+	}
 	err = r.PopulateTemporality(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("internal error while setting temporality")
@@ -343,28 +356,41 @@ func (r *ThresholdRule) buildAndRunQuery(ctx context.Context, ts time.Time) (Vec
 		}
 	}
 
-	if queryResult != nil && len(queryResult.Series) > 0 {
-		r.lastTimestampWithDatapoints = time.Now()
+	r.noData = queryResult == nil || len(queryResult.Series) == 0
+	r.seriesFPs = map[uint64]struct{}{}
+	if !r.noData {
+		r.lastTimestampWithDatapoints = ts
+		for _, series := range queryResult.Series {
+			r.seriesFPs[seriesFingerprint(series)] = struct{}{}
+		}
 	}
 
 	var resultVector Vector
 
 	// if the data is missing for `For` duration then we should send alert
-	if r.ruleCondition.AlertOnAbsent && r.lastTimestampWithDatapoints.Add(time.Duration(r.Condition().AbsentFor)*time.Minute).Before(time.Now()) {
+	noDataAction := r.ruleCondition.noDataAction()
+	if (noDataAction == NoDataActionNoData || noDataAction == NoDataActionFire) && r.lastTimestampWithDatapoints.Add(time.Duration(r.Condition().AbsentFor)*time.Minute).Before(ts) {
 		zap.L().Info("no data found for rule condition", zap.String("ruleid", r.ID()))
 		lbls := labels.NewBuilder(labels.Labels{})
-		if !r.lastTimestampWithDatapoints.IsZero() {
-			lbls.Set("lastSeen", r.lastTimestampWithDatapoints.Format(constants.AlertTimeFormat))
+		if !r.lastTimestampWithDatapoints.IsZero() && noDataAction == NoDataActionNoData {
+			lbls.Set(lastSeenLabel, r.lastTimestampWithDatapoints.Format(constants.AlertTimeFormat))
 		}
 		resultVector = append(resultVector, Sample{
 			Metric:    lbls.Labels(),
-			IsMissing: true,
+			IsMissing: noDataAction == NoDataActionNoData,
 		})
 		return resultVector, nil
 	}
 
+	if r.ruleCondition.Composite != nil {
+		return r.evalCompositeCondition(r.ruleCondition.Composite, results), nil
+	}
+
 	for _, series := range queryResult.Series {
 		smpl, shouldAlert := r.ShouldAlert(*series)
+		if !shouldAlert {
+			smpl, shouldAlert = r.shouldKeepFiring(*series)
+		}
 		if shouldAlert {
 			resultVector = append(resultVector, smpl)
 		}
@@ -376,18 +402,28 @@ func (r *ThresholdRule) Eval(ctx context.Context, ts time.Time) (interface{}, er
 
 	prevState := r.State()
 
-	valueFormatter := formatter.FromUnit(r.Unit())
 	res, err := r.buildAndRunQuery(ctx, ts)
 
 	if err != nil {
 		return nil, err
 	}
 
+	return r.evalVector(ctx, ts, prevState, res)
+}
+
+// evalVector updates the alerts of the rule with the samples of the query
+// evaluated at ts, recording the alerts changing state
+func (r *ThresholdRule) evalVector(ctx context.Context, ts time.Time, prevState model.AlertState, res Vector) (interface{}, error) {
+	valueFormatter := formatter.FromUnit(r.Unit())
+
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
+	var err error
 	resultFPs := map[uint64]struct{}{}
 	var alerts = make(map[uint64]*Alert, len(res))
+	// the number of new alerts sampled by the evaluation
+	sampled := 0
 
 	for _, smpl := range res {
 		l := make(map[string]string, len(smpl.Metric))
@@ -396,7 +432,7 @@ func (r *ThresholdRule) Eval(ctx context.Context, ts time.Time) (interface{}, er
 		}
 
 		value := valueFormatter.Format(smpl.V, r.Unit())
-		threshold := valueFormatter.Format(r.targetVal(), r.Unit())
+		threshold := valueFormatter.Format(r.severityTarget(smpl.Severity), r.Unit())
 		zap.L().Debug("Alert template data for rule", zap.String("name", r.Name()), zap.String("formatter", valueFormatter.Name()), zap.String("value", value), zap.String("threshold", threshold))
 
 		tmplData := AlertTemplateData(l, value, threshold)
@@ -430,6 +466,10 @@ func (r *ThresholdRule) Eval(ctx context.Context, ts time.Time) (interface{}, er
 			lb.Set(name, expand(value))
 		}
 
+		if smpl.Severity != "" {
+			lb.Set(labels.AlertSeverityLabel, smpl.Severity)
+		}
+
 		lb.Set(labels.AlertNameLabel, r.Name())
 		lb.Set(labels.AlertRuleIdLabel, r.ID())
 		lb.Set(labels.RuleSourceLabel, r.GeneratorURL())
@@ -469,6 +509,10 @@ func (r *ThresholdRule) Eval(ctx context.Context, ts time.Time) (interface{}, er
 			return nil, err
 		}
 
+		if sample, ok := r.alertSample(ctx, ts, h, smpl, &sampled); ok {
+			annotations = append(annotations, sample)
+		}
+
 		alerts[h] = &Alert{
 			Labels:            lbs,
 			QueryResultLables: resultLabels,
@@ -477,8 +521,10 @@ func (r *ThresholdRule) Eval(ctx context.Context, ts time.Time) (interface{}, er
 			State:             model.StatePending,
 			Value:             smpl.V,
 			GeneratorURL:      r.GeneratorURL(),
-			Receivers:         r.preferredChannels,
+			Receivers:         r.severityChannels(smpl.Severity),
 			Missing:           smpl.IsMissing,
+
+			ResolvedAnnotations: r.resolvedNotification.ExpandAnnotations(expand),
 		}
 	}
 
@@ -492,7 +538,9 @@ func (r *ThresholdRule) Eval(ctx context.Context, ts time.Time) (interface{}, er
 
 			alert.Value = a.Value
 			alert.Annotations = a.Annotations
-			alert.Receivers = r.preferredChannels
+			alert.ResolvedAnnotations = a.ResolvedAnnotations
+			alert.Receivers = a.Receivers
+			alert.Missing = a.Missing
 			continue
 		}
 
@@ -508,31 +556,63 @@ func (r *ThresholdRule) Eval(ctx context.Context, ts time.Time) (interface{}, er
 			zap.L().Error("error marshaling labels", zap.Error(err), zap.Any("labels", a.Labels))
 		}
 		if _, ok := resultFPs[fp]; !ok {
-			// If the alert was previously firing, keep it around for a given
-			// retention time so it is reported as resolved to the AlertManager.
-			if a.State == model.StatePending || (!a.ResolvedAt.IsZero() && ts.Sub(a.ResolvedAt) > ResolvedRetention) {
-				delete(r.Active, fp)
-			}
+			// the alerts of the series absent from the query result follow
+			// the no data actions of the rule, the resolved ones are kept
+			// for the retention time
+			action := NoDataActionResolve
 			if a.State != model.StateInactive {
-				a.State = model.StateInactive
-				a.ResolvedAt = ts
-				itemsToAdd = append(itemsToAdd, model.RuleStateHistory{
-					RuleID:       r.ID(),
-					RuleName:     r.Name(),
-					State:        model.StateInactive,
-					StateChanged: true,
-					UnixMilli:    ts.UnixMilli(),
-					Labels:       model.LabelsString(labelsJSON),
-					Fingerprint:  a.QueryResultLables.Hash(),
-					Value:        a.Value,
-				})
+				action = r.absentAction(a)
+			}
+			switch action {
+			case NoDataActionKeepState:
+				continue
+			case NoDataActionNoData:
+				if !a.Missing {
+					a.Missing = true
+					if a.State == model.StateFiring {
+						itemsToAdd = append(itemsToAdd, model.RuleStateHistory{
+							RuleID:       r.ID(),
+							RuleName:     r.Name(),
+							State:        model.StateNoData,
+							StateChanged: true,
+							UnixMilli:    ts.UnixMilli(),
+							Labels:       model.LabelsString(labelsJSON),
+							Fingerprint:  a.QueryResultLables.Hash(),
+							Value:        a.Value,
+						})
+					}
+				}
+			case NoDataActionResolve:
+				// If the alert was previously firing, keep it around for a given
+				// retention time so it is reported as resolved to the AlertManager.
+				if a.State == model.StatePending || (!a.ResolvedAt.IsZero() && ts.Sub(a.ResolvedAt) > ResolvedRetention) {
+					delete(r.Active, fp)
+				}
+				if a.State != model.StateInactive {
+					a.State = model.StateInactive
+					a.ResolvedAt = ts
+					if !a.FiredAt.IsZero() {
+						r.recordTransition(fp, ts)
+					}
+					itemsToAdd = append(itemsToAdd, model.RuleStateHistory{
+						RuleID:       r.ID(),
+						RuleName:     r.Name(),
+						State:        model.StateInactive,
+						StateChanged: true,
+						UnixMilli:    ts.UnixMilli(),
+						Labels:       model.LabelsString(labelsJSON),
+						Fingerprint:  a.QueryResultLables.Hash(),
+						Value:        a.Value,
+					})
+				}
+				continue
 			}
-			continue
 		}
 
 		if a.State == model.StatePending && ts.Sub(a.ActiveAt) >= r.holdDuration {
 			a.State = model.StateFiring
 			a.FiredAt = ts
+			r.recordTransition(fp, ts)
 			state := model.StateFiring
 			if a.Missing {
 				state = model.StateNoData
@@ -547,6 +627,18 @@ func (r *ThresholdRule) Eval(ctx context.Context, ts time.Time) (interface{}, er
 				Fingerprint:  a.QueryResultLables.Hash(),
 				Value:        a.Value,
 			})
+		} else if a.State == model.StatePending && a.ActiveAt.Equal(ts) {
+			// the alert is held pending before it fires
+			itemsToAdd = append(itemsToAdd, model.RuleStateHistory{
+				RuleID:       r.ID(),
+				RuleName:     r.Name(),
+				State:        model.StatePending,
+				StateChanged: true,
+				UnixMilli:    ts.UnixMilli(),
+				Labels:       model.LabelsString(labelsJSON),
+				Fingerprint:  a.QueryResultLables.Hash(),
+				Value:        a.Value,
+			})
 		}
 	}
 
@@ -573,6 +665,7 @@ func (r *ThresholdRule) String() string {
 		AlertName:         r.name,
 		RuleCondition:     r.ruleCondition,
 		EvalWindow:        Duration(r.evalWindow),
+		EvalDelay:         Duration(r.evalDelay),
 		Labels:            r.labels.Map(),
 		Annotations:       r.annotations.Map(),
 		PreferredChannels: r.preferredChannels,
@@ -10,6 +10,7 @@ import (
 	"go.signoz.io/signoz/pkg/query-service/app/clickhouseReader"
 	"go.signoz.io/signoz/pkg/query-service/common"
 	"go.signoz.io/signoz/pkg/query-service/featureManager"
+	"go.signoz.io/signoz/pkg/query-service/model"
 	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
 	"go.signoz.io/signoz/pkg/query-service/utils/labels"
 
@@ -1064,6 +1065,15 @@ func TestThresholdRuleEvalDelay(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, c.expectedQuery, secondTimeParams.CompositeQuery.ClickHouseQueries["A"].Query, "Test case %d", idx)
 	}
+
+	// the delay of the rule takes over from the one of the rule manager
+	postableRule.EvalDelay = Duration(10 * time.Minute)
+	rule, err := NewThresholdRule("69", &postableRule, fm, nil, true, true, WithEvalDelay(2*time.Minute))
+	assert.NoError(t, err)
+	params, err := rule.prepareQueryRange(ts)
+	assert.NoError(t, err)
+	// 01:24:00 - 01:29:00
+	assert.Equal(t, "SELECT 1 >= 1717205040000 AND 1 <= 1717205340000", params.CompositeQuery.ClickHouseQueries["A"].Query)
 }
 
 func TestThresholdRuleClickHouseTmpl(t *testing.T) {
@@ -1668,3 +1678,280 @@ func TestThresholdRuleShiftBy(t *testing.T) {
 
 	assert.Equal(t, int64(10), params.CompositeQuery.BuilderQueries["A"].ShiftBy)
 }
+
+func TestThresholdRuleSeverityThresholds(t *testing.T) {
+	critical, warning := float64(100), float64(50)
+	postableRule := PostableRule{
+		AlertName:         "Severity thresholds test",
+		AlertType:         AlertTypeMetric,
+		RuleType:          RuleTypeThreshold,
+		EvalWindow:        Duration(5 * time.Minute),
+		Frequency:         Duration(1 * time.Minute),
+		Labels:            map[string]string{"severity": "info"},
+		PreferredChannels: []string{"slack"},
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {
+						QueryName:    "A",
+						StepInterval: 60,
+						AggregateAttribute: v3.AttributeKey{
+							Key: "signoz_calls_total",
+						},
+						AggregateOperator: v3.AggregateOperatorSumRate,
+						DataSource:        v3.DataSourceMetrics,
+						Expression:        "A",
+					},
+				},
+			},
+			CompareOp: ValueIsAbove,
+			MatchType: AtleastOnce,
+			Thresholds: []SeverityThreshold{
+				{Severity: "critical", Target: &critical, PreferredChannels: []string{"pagerduty"}},
+				{Severity: "warning", Target: &warning},
+			},
+		},
+	}
+	fm := featureManager.StartManager()
+	mock, err := cmock.NewClickHouseWithQueryMatcher(nil, &queryMatcherAny{})
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	cols := make([]cmock.ColumnType, 0)
+	cols = append(cols, cmock.ColumnType{Name: "value", Type: "Float64"})
+	cols = append(cols, cmock.ColumnType{Name: "service_name", Type: "String"})
+	cols = append(cols, cmock.ColumnType{Name: "timestamp", Type: "String"})
+
+	now := time.Now()
+	rows := cmock.NewRows(cols, [][]interface{}{
+		{float64(120), "checkout", now},
+		{float64(70), "payments", now},
+		{float64(10), "cart", now},
+	})
+	mock.ExpectQuery("SELECT any").WillReturnRows(rows)
+
+	options := clickhouseReader.NewOptions("", "", "archiveNamespace")
+	reader := clickhouseReader.NewReaderFromClickhouseConnection(mock, options, nil, "", fm, "", true, true, time.Duration(time.Second), nil)
+
+	rule, err := NewThresholdRule("69", &postableRule, fm, reader, true, true, WithoutStateHistory())
+	assert.NoError(t, err)
+	rule.TemporalityMap = map[string]map[v3.Temporality]bool{
+		"signoz_calls_total": {
+			v3.Delta: true,
+		},
+	}
+
+	retVal, err := rule.Eval(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, retVal.(int))
+
+	for _, item := range rule.Active {
+		switch item.Labels.Get("service_name") {
+		case "checkout":
+			assert.Equal(t, "critical", item.Labels.Get(labels.AlertSeverityLabel))
+			assert.Equal(t, []string{"pagerduty"}, item.Receivers)
+		case "payments":
+			assert.Equal(t, "warning", item.Labels.Get(labels.AlertSeverityLabel))
+			assert.Equal(t, []string{"slack"}, item.Receivers)
+		default:
+			t.Errorf("unexpected alert for %s", item.Labels.Get("service_name"))
+		}
+	}
+}
+
+func TestThresholdRuleNoDataActions(t *testing.T) {
+	target := float64(100)
+	postableRule := PostableRule{
+		AlertName:  "No data actions test",
+		AlertType:  AlertTypeMetric,
+		RuleType:   RuleTypeThreshold,
+		EvalWindow: Duration(5 * time.Minute),
+		Frequency:  Duration(1 * time.Minute),
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {
+						QueryName:    "A",
+						StepInterval: 60,
+						AggregateAttribute: v3.AttributeKey{
+							Key: "signoz_calls_total",
+						},
+						AggregateOperator: v3.AggregateOperatorSumRate,
+						DataSource:        v3.DataSourceMetrics,
+						Expression:        "A",
+					},
+				},
+			},
+			CompareOp: ValueIsAbove,
+			MatchType: AtleastOnce,
+			Target:    &target,
+		},
+	}
+
+	cols := make([]cmock.ColumnType, 0)
+	cols = append(cols, cmock.ColumnType{Name: "value", Type: "Float64"})
+	cols = append(cols, cmock.ColumnType{Name: "service_name", Type: "String"})
+	cols = append(cols, cmock.ColumnType{Name: "timestamp", Type: "String"})
+
+	// the mock returns the timestamp as a label, the rows of both
+	// evaluations share it for the series to match
+	now := time.Now()
+	cases := []struct {
+		noDataAction       NoDataAction
+		absentSeriesAction NoDataAction
+		// the rows of the second evaluation, the first returns the two
+		// services above the target
+		values [][]interface{}
+		// the services with an active alert after the second evaluation,
+		// with whether it is missing
+		expectActive map[string]bool
+	}{
+		{
+			// the alert of the absent series is resolved by default
+			values:       [][]interface{}{{float64(10), "checkout", now}},
+			expectActive: map[string]bool{},
+		},
+		{
+			absentSeriesAction: NoDataActionKeepState,
+			values:             [][]interface{}{{float64(10), "checkout", now}},
+			expectActive:       map[string]bool{"payments": false},
+		},
+		{
+			absentSeriesAction: NoDataActionNoData,
+			values:             [][]interface{}{{float64(10), "checkout", now}},
+			expectActive:       map[string]bool{"payments": true},
+		},
+		{
+			noDataAction: NoDataActionKeepState,
+			values:       [][]interface{}{},
+			expectActive: map[string]bool{"checkout": false, "payments": false},
+		},
+		{
+			noDataAction: NoDataActionFire,
+			values:       [][]interface{}{},
+			expectActive: map[string]bool{"": false},
+		},
+	}
+
+	for idx, c := range cases {
+		fm := featureManager.StartManager()
+		mock, err := cmock.NewClickHouseWithQueryMatcher(nil, &queryMatcherAny{})
+		assert.NoError(t, err)
+		mock.ExpectQuery("SELECT any").WillReturnRows(cmock.NewRows(cols, [][]interface{}{
+			{float64(120), "checkout", now},
+			{float64(120), "payments", now},
+		}))
+		mock.ExpectQuery("SELECT any").WillReturnRows(cmock.NewRows(cols, c.values))
+
+		postableRule.RuleCondition.NoDataAction = c.noDataAction
+		postableRule.RuleCondition.AbsentSeriesAction = c.absentSeriesAction
+		assert.Empty(t, validateNoDataActions(&postableRule), "case %d", idx)
+
+		options := clickhouseReader.NewOptions("", "", "archiveNamespace")
+		reader := clickhouseReader.NewReaderFromClickhouseConnection(mock, options, nil, "", fm, "", true, true, time.Duration(time.Second), nil)
+
+		rule, err := NewThresholdRule("69", &postableRule, fm, reader, true, true, WithoutStateHistory())
+		assert.NoError(t, err)
+		rule.TemporalityMap = map[string]map[v3.Temporality]bool{
+			"signoz_calls_total": {
+				v3.Delta: true,
+			},
+		}
+
+		_, err = rule.Eval(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Len(t, rule.Active, 2, "case %d", idx)
+		_, err = rule.Eval(context.Background(), now.Add(time.Minute))
+		assert.NoError(t, err)
+
+		active := map[string]bool{}
+		for _, item := range rule.Active {
+			if item.State != model.StateInactive {
+				active[item.Labels.Get("service_name")] = item.Missing
+			}
+		}
+		assert.Equal(t, c.expectActive, active, "case %d", idx)
+	}
+
+	postableRule.RuleCondition.NoDataAction = "page"
+	assert.NotEmpty(t, validateNoDataActions(&postableRule))
+	postableRule.RuleCondition.NoDataAction = NoDataActionResolve
+	postableRule.RuleCondition.AlertOnAbsent = true
+	assert.NotEmpty(t, validateNoDataActions(&postableRule))
+}
+
+func TestThresholdRuleClearTarget(t *testing.T) {
+	target, clearTarget := float64(100), float64(80)
+	postableRule := PostableRule{
+		AlertName:  "Clear target test",
+		AlertType:  AlertTypeMetric,
+		RuleType:   RuleTypeThreshold,
+		EvalWindow: Duration(5 * time.Minute),
+		Frequency:  Duration(1 * time.Minute),
+		RuleCondition: &RuleCondition{
+			CompositeQuery: &v3.CompositeQuery{
+				QueryType: v3.QueryTypeBuilder,
+				BuilderQueries: map[string]*v3.BuilderQuery{
+					"A": {
+						QueryName:    "A",
+						StepInterval: 60,
+						AggregateAttribute: v3.AttributeKey{
+							Key: "signoz_calls_total",
+						},
+						AggregateOperator: v3.AggregateOperatorSumRate,
+						DataSource:        v3.DataSourceMetrics,
+						Expression:        "A",
+					},
+				},
+			},
+			CompareOp:   ValueIsAbove,
+			MatchType:   AtleastOnce,
+			Target:      &target,
+			ClearTarget: &clearTarget,
+		},
+	}
+	assert.NoError(t, validateClearTarget(&postableRule))
+
+	fm := featureManager.StartManager()
+	mock, err := cmock.NewClickHouseWithQueryMatcher(nil, &queryMatcherAny{})
+	assert.NoError(t, err)
+
+	cols := make([]cmock.ColumnType, 0)
+	cols = append(cols, cmock.ColumnType{Name: "value", Type: "Float64"})
+	cols = append(cols, cmock.ColumnType{Name: "service_name", Type: "String"})
+	cols = append(cols, cmock.ColumnType{Name: "timestamp", Type: "String"})
+
+	// the alert fires above the target, and resolves only below the clear
+	// target
+	now := time.Now()
+	values := []float64{120, 90, 90, 70, 90}
+	expectFiring := []bool{true, true, true, false, false}
+	for _, value := range values {
+		mock.ExpectQuery("SELECT any").WillReturnRows(cmock.NewRows(cols, [][]interface{}{{value, "checkout", now}}))
+	}
+
+	options := clickhouseReader.NewOptions("", "", "archiveNamespace")
+	reader := clickhouseReader.NewReaderFromClickhouseConnection(mock, options, nil, "", fm, "", true, true, time.Duration(time.Second), nil)
+
+	rule, err := NewThresholdRule("69", &postableRule, fm, reader, true, true, WithoutStateHistory())
+	assert.NoError(t, err)
+	rule.TemporalityMap = map[string]map[v3.Temporality]bool{
+		"signoz_calls_total": {
+			v3.Delta: true,
+		},
+	}
+
+	for idx := range values {
+		_, err := rule.Eval(context.Background(), now.Add(time.Duration(idx)*time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, expectFiring[idx], rule.State() == model.StateFiring, "eval %d", idx)
+	}
+
+	clearTarget = 120
+	assert.Error(t, validateClearTarget(&postableRule))
+	postableRule.RuleCondition.CompareOp = ValueIsBelow
+	assert.NoError(t, validateClearTarget(&postableRule))
+}
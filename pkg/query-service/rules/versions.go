@@ -0,0 +1,145 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// RuleVersion is the definition of a rule as saved by an edit, the versions
+// of a rule make up its history
+type RuleVersion struct {
+	Id        int       `json:"-" db:"id"`
+	RuleId    int       `json:"rule_id" db:"rule_id"`
+	Version   int       `json:"version" db:"version"`
+	Data      string    `json:"data" db:"data"`
+	CreatedBy string    `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// RuleVersionChange is a field of the rule definition which differs between
+// two versions, Before is nil for added fields and After for removed ones
+type RuleVersionChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// recordRuleVersion saves the rule definition as the next version of the
+// rule. Failing to record it is logged and does not fail the edit itself.
+func recordRuleVersion(e sqlx.Execer, ruleId int64, rule string, userEmail string, createdAt time.Time) {
+	_, err := e.Exec(`INSERT INTO rule_versions (rule_id, version, data, created_by, created_at)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4 FROM rule_versions WHERE rule_id=$5;`,
+		ruleId, rule, userEmail, createdAt, ruleId)
+	if err != nil {
+		zap.L().Error("Error in recording rule version", zap.Int64("id", ruleId), zap.Error(err))
+	}
+}
+
+// GetRuleVersions returns the versions of the rule, newest first
+func (r *ruleDB) GetRuleVersions(ctx context.Context, id string) ([]RuleVersion, error) {
+	intId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id parameter")
+	}
+
+	versions := []RuleVersion{}
+	err = r.Select(&versions, `SELECT * FROM rule_versions WHERE rule_id=$1 ORDER BY version DESC`, intId)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// GetRuleVersion returns the version of the rule
+func (r *ruleDB) GetRuleVersion(ctx context.Context, id string, version int) (*RuleVersion, error) {
+	intId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id parameter")
+	}
+
+	ruleVersion := &RuleVersion{}
+	err = r.Get(ruleVersion, `SELECT * FROM rule_versions WHERE rule_id=$1 AND version=$2`, intId, version)
+	if err != nil {
+		zap.L().Error("Error in processing sql query", zap.Error(err))
+		return nil, err
+	}
+
+	return ruleVersion, nil
+}
+
+// DiffRuleVersions returns the fields of the rule definition which changed
+// from one version to another, sorted by field. Nested fields are named by
+// their path, such as condition.target or preferredChannels[0].
+func DiffRuleVersions(before, after *RuleVersion) ([]RuleVersionChange, error) {
+	beforeFields, err := flattenRuleDefinition(before.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %d: %w", before.Version, err)
+	}
+	afterFields, err := flattenRuleDefinition(after.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %d: %w", after.Version, err)
+	}
+
+	changes := []RuleVersionChange{}
+	for field, value := range beforeFields {
+		if afterValue, ok := afterFields[field]; !ok || !reflect.DeepEqual(value, afterValue) {
+			changes = append(changes, RuleVersionChange{Field: field, Before: value, After: afterValue})
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes = append(changes, RuleVersionChange{Field: field, After: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+func flattenRuleDefinition(data string) (map[string]interface{}, error) {
+	var definition interface{}
+	if err := json.Unmarshal([]byte(data), &definition); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	flattenRuleField("", definition, fields)
+	return fields, nil
+}
+
+func flattenRuleField(path string, value interface{}, fields map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && path != "" {
+			fields[path] = v
+		}
+		for key, child := range v {
+			if path == "" {
+				flattenRuleField(key, child, fields)
+			} else {
+				flattenRuleField(path+"."+key, child, fields)
+			}
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			fields[path] = v
+		}
+		for i, child := range v {
+			flattenRuleField(fmt.Sprintf("%s[%d]", path, i), child, fields)
+		}
+	default:
+		fields[path] = v
+	}
+}
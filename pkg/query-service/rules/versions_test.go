@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.signoz.io/signoz/pkg/query-service/utils"
+)
+
+func TestDiffRuleVersions(t *testing.T) {
+	require := require.New(t)
+
+	before := &RuleVersion{Version: 1, Data: `{"alert": "high latency", "condition": {"target": 500, "op": "1"}, "preferredChannels": ["oncall"]}`}
+	after := &RuleVersion{Version: 2, Data: `{"alert": "high latency", "condition": {"target": 5000, "op": "1"}, "preferredChannels": ["oncall", "slack"], "disabled": true}`}
+
+	changes, err := DiffRuleVersions(before, after)
+	require.NoError(err)
+	require.Equal([]RuleVersionChange{
+		{Field: "condition.target", Before: float64(500), After: float64(5000)},
+		{Field: "disabled", After: true},
+		{Field: "preferredChannels[1]", After: "slack"},
+	}, changes)
+
+	changes, err = DiffRuleVersions(after, before)
+	require.NoError(err)
+	require.Len(changes, 3)
+	require.Equal(RuleVersionChange{Field: "disabled", Before: true}, changes[1])
+
+	_, err = DiffRuleVersions(before, &RuleVersion{Version: 3, Data: "{"})
+	require.Error(err)
+}
+
+func TestRuleVersions(t *testing.T) {
+	require := require.New(t)
+	sqlStore := utils.NewQueryServiceDBForTests(t)
+	ruleDB := NewRuleDB(sqlStore.SQLxDB(), nil)
+	ctx := context.Background()
+
+	id, tx, err := ruleDB.CreateRuleTx(ctx, `{"alert": "high latency", "condition": {"target": 500}}`)
+	require.NoError(err)
+	require.NoError(tx.Commit())
+	ruleId := fmt.Sprintf("%d", id)
+
+	_, _, err = ruleDB.EditRuleTx(ctx, `{"alert": "high latency", "condition": {"target": 5000}}`, ruleId)
+	require.NoError(err)
+
+	versions, err := ruleDB.GetRuleVersions(ctx, ruleId)
+	require.NoError(err)
+	require.Len(versions, 2)
+	require.Equal(2, versions[0].Version)
+	require.Contains(versions[0].Data, "5000")
+	require.Equal(1, versions[1].Version)
+
+	first, err := ruleDB.GetRuleVersion(ctx, ruleId, 1)
+	require.NoError(err)
+	require.Contains(first.Data, "500}")
+
+	// the versions are deleted with the rule
+	_, _, err = ruleDB.DeleteRuleTx(ctx, ruleId)
+	require.NoError(err)
+	versions, err = ruleDB.GetRuleVersions(ctx, ruleId)
+	require.NoError(err)
+	require.Empty(versions)
+}
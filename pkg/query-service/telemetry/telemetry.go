@@ -48,6 +48,7 @@ const (
 	TELEMETRY_EVENT_USER_INVITATION_ACCEPTED         = "User Invitation Accepted"
 	TELEMETRY_EVENT_SUCCESSFUL_DASHBOARD_PANEL_QUERY = "Successful Dashboard Panel Query"
 	TELEMETRY_EVENT_SUCCESSFUL_ALERT_QUERY           = "Successful Alert Query"
+	TELEMETRY_EVENT_DASHBOARDS_PURGED                = "Dashboards Purged"
 	DEFAULT_CLOUD_EMAIL                              = "admin@signoz.cloud"
 )
 
@@ -66,6 +67,7 @@ var SAAS_EVENTS_LIST = map[string]struct{}{
 	TELEMETRY_EVENT_MAX_SPANS_ALLOWED_LIMIT_REACHED:  {},
 	TELEMETRY_EVENT_LARGE_TRACE_OPENED:               {},
 	TELEMETRY_EVENT_TRACE_DETAIL_API:                 {},
+	TELEMETRY_EVENT_DASHBOARDS_PURGED:                {},
 }
 
 var OSS_EVENTS_LIST = map[string]struct{}{
@@ -74,6 +76,7 @@ var OSS_EVENTS_LIST = map[string]struct{}{
 	TELEMETRY_EVENT_LANGUAGE:           {},
 	TELEMETRY_EVENT_ENVIRONMENT:        {},
 	TELEMETRY_EVENT_DASHBOARDS_ALERTS:  {},
+	TELEMETRY_EVENT_DASHBOARDS_PURGED:  {},
 	TELEMETRY_EVENT_ACTIVE_USER:        {},
 	TELEMETRY_EVENT_PATH:               {},
 	TELEMETRY_EVENT_ORG_SETTINGS:       {},
@@ -391,6 +394,8 @@ func createTelemetry() {
 						"opsGenieChannels":                alertsInfo.OpsGenieChannels,
 						"emailChannels":                   alertsInfo.EmailChannels,
 						"msteamsChannels":                 alertsInfo.MSTeamsChannels,
+						"snsChannels":                     alertsInfo.SNSChannels,
+						"victorOpsChannels":               alertsInfo.VictorOpsChannels,
 						"metricsBuilderQueries":           alertsInfo.MetricsBuilderQueries,
 						"metricsClickHouseQueries":        alertsInfo.MetricsClickHouseQueries,
 						"metricsPrometheusQueries":        alertsInfo.MetricsPrometheusQueries,
@@ -25,8 +25,13 @@ const (
 	RuleSourceLabel  = "ruleSource"
 
 	RuleThresholdLabel    = "threshold"
+	AlertSeverityLabel    = "severity"
 	AlertSummaryLabel     = "summary"
 	AlertDescriptionLabel = "description"
+
+	// AlertDedupKeyLabel is the annotation with the key of the alert instance,
+	// the same in all of its notifications
+	AlertDedupKeyLabel = "dedupKey"
 )
 
 // Label is a key/value pair of strings.
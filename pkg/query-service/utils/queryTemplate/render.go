@@ -0,0 +1,70 @@
+package querytemplate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	v3 "go.signoz.io/signoz/pkg/query-service/model/v3"
+)
+
+// reservedVars are the go template vars assigned from the time range of the
+// query
+var reservedVars = []string{
+	"start_timestamp", "end_timestamp",
+	"start_timestamp_ms", "end_timestamp_ms",
+	"SIGNOZ_START_TIME", "SIGNOZ_END_TIME",
+	"start_timestamp_nano", "end_timestamp_nano",
+	"start_datetime", "end_datetime",
+}
+
+// IsReservedVar tells whether the variable is assigned from the time range of
+// the query
+func IsReservedVar(name string) bool {
+	for _, v := range reservedVars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesTimeRange tells whether the query references the time range of the
+// query through the reserved vars, so that its result for a time range can
+// be put together from the results of the parts of the range
+func UsesTimeRange(query string) bool {
+	for _, v := range reservedVars {
+		if strings.Contains(query, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderForTimeRange renders the variables in the query the way the query
+// range params are parsed, with the reserved vars assigned for the time range
+// given in milliseconds. The variables given are not modified.
+func RenderForTimeRange(query string, variables map[string]interface{}, start, end int64) (string, error) {
+	params := &v3.QueryRangeParamsV3{Start: start, End: end, Variables: make(map[string]interface{}, len(variables))}
+	for name, value := range variables {
+		params.Variables[name] = value
+	}
+	AssignReservedVarsV3(params)
+
+	for name, value := range params.Variables {
+		query = strings.Replace(query, fmt.Sprintf("{{%s}}", name), fmt.Sprint(value), -1)
+		query = strings.Replace(query, fmt.Sprintf("[[%s]]", name), fmt.Sprint(value), -1)
+		query = strings.Replace(query, fmt.Sprintf("$%s", name), fmt.Sprint(value), -1)
+	}
+
+	tmpl, err := template.New("query").Parse(query)
+	if err != nil {
+		return "", err
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, params.Variables); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
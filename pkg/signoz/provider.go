@@ -62,6 +62,56 @@ func NewProviderConfig() ProviderConfig {
 			sqlmigration.NewAddPatsFactory(),
 			sqlmigration.NewModifyDatetimeFactory(),
 			sqlmigration.NewModifyOrgDomainFactory(),
+			sqlmigration.NewAddDashboardOwnerFactory(),
+			sqlmigration.NewAddDashboardFoldersFactory(),
+			sqlmigration.NewAddDashboardDeletedAtFactory(),
+			sqlmigration.NewAddDashboardSearchIndexFactory(),
+			sqlmigration.NewAddDashboardSharesFactory(),
+			sqlmigration.NewAddDashboardSnapshotsFactory(),
+			sqlmigration.NewAddDashboardReportSchedulesFactory(),
+			sqlmigration.NewAddDashboardVersionFactory(),
+			sqlmigration.NewAddDashboardAuditFactory(),
+			sqlmigration.NewAddDashboardFavoritesFactory(),
+			sqlmigration.NewAddDashboardViewsFactory(),
+			sqlmigration.NewAddDashboardMetricIndexFactory(),
+			sqlmigration.NewAddDashboardProvisionedFromFactory(),
+			sqlmigration.NewAddDashboardWebhooksFactory(),
+			sqlmigration.NewAddDashboardLockOwnerFactory(),
+			sqlmigration.NewAddDashboardOrgFactory(),
+			sqlmigration.NewAddDashboardSlugFactory(),
+			sqlmigration.NewAddDashboardDataColumnsFactory(),
+			sqlmigration.NewAddDashboardArchivedAtFactory(),
+			sqlmigration.NewAddDashboardMetadataColumnsFactory(),
+			sqlmigration.NewAddDashboardAuditMessageFactory(),
+			sqlmigration.NewAddDashboardServicesFactory(),
+			sqlmigration.NewAddDashboardTimeSettingsFactory(),
+			sqlmigration.NewAddHomeDashboardsFactory(),
+			sqlmigration.NewMapDashboardUsersToIdsFactory(),
+			sqlmigration.NewAddDashboardTrashSettingsFactory(),
+			sqlmigration.NewAddDashboardUsageCountsFactory(),
+			sqlmigration.NewAddAnnotationsFactory(),
+			sqlmigration.NewAddDashboardThumbnailsFactory(),
+			sqlmigration.NewAddRuleVersionsFactory(),
+			sqlmigration.NewAddMaintenanceMatchersFactory(),
+			sqlmigration.NewAddEscalationPoliciesFactory(),
+			sqlmigration.NewAddOnCallSchedulesFactory(),
+			sqlmigration.NewAddChannelRateLimitsFactory(),
+			sqlmigration.NewAddLogPatternsFactory(),
+			sqlmigration.NewAddSLOsFactory(),
+			sqlmigration.NewAddNotificationRoutingFactory(),
+			sqlmigration.NewAddRulePausesFactory(),
+			sqlmigration.NewAddChannelResolvedNotificationsFactory(),
+			sqlmigration.NewAddChannelTemplatesFactory(),
+			sqlmigration.NewAddTeamsFactory(),
+			sqlmigration.NewAddDashboardWidgetAlertsFactory(),
+			sqlmigration.NewAddAlertDigestsFactory(),
+			sqlmigration.NewAddAlertAcknowledgementNoteFactory(),
+			sqlmigration.NewAddSavedViewVisibilityFactory(),
+			sqlmigration.NewAddQueryMacrosFactory(),
+			sqlmigration.NewAddDashboardAuditOrgFactory(),
+			sqlmigration.NewAddFolderWebhookOrgFactory(),
+			sqlmigration.NewBackfillDashboardOrgFactory(),
+			sqlmigration.NewAddQueryMacroOrgFactory(),
 		),
 		TelemetryStoreProviderFactories: factory.MustNewNamedMap(
 			clickhousetelemetrystore.NewFactory(telemetrystorehook.NewFactory()),
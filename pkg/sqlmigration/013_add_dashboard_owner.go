@@ -0,0 +1,68 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardOwner struct{}
+
+func NewAddDashboardOwnerFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_owner"), newAddDashboardOwner)
+}
+
+func newAddDashboardOwner(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardOwner{}, nil
+}
+
+func (migration *addDashboardOwner) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardOwner) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("owner TEXT").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "owner")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	// existing dashboards are owned by whoever created them
+	if _, err := db.ExecContext(ctx, `UPDATE dashboards SET owner = created_by WHERE owner IS NULL`); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboards").
+		Column("created_by").
+		Index("idx_dashboards_created_by").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboards").
+		Column("owner").
+		Index("idx_dashboards_owner").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardOwner) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardFolders struct{}
+
+func NewAddDashboardFoldersFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_folders"), newAddDashboardFolders)
+}
+
+func newAddDashboardFolders(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardFolders{}, nil
+}
+
+func (migration *addDashboardFolders) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardFolders) Up(ctx context.Context, db *bun.DB) error {
+	// table:folders
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:folders"`
+			ID            int       `bun:"id,pk,autoincrement"`
+			UUID          string    `bun:"uuid,type:text,notnull,unique"`
+			Name          string    `bun:"name,type:text,notnull"`
+			ParentID      string    `bun:"parent_id,type:text"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("folder_id TEXT").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "folder_id")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboards").
+		Column("folder_id").
+		Index("idx_dashboards_folder_id").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardFolders) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardDeletedAt struct{}
+
+func NewAddDashboardDeletedAtFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_deleted_at"), newAddDashboardDeletedAt)
+}
+
+func newAddDashboardDeletedAt(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardDeletedAt{}, nil
+}
+
+func (migration *addDashboardDeletedAt) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardDeletedAt) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("deleted_at TIMESTAMP").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "deleted_at")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboards").
+		Column("deleted_at").
+		Index("idx_dashboards_deleted_at").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardDeletedAt) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,49 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardSearchIndex struct{}
+
+func NewAddDashboardSearchIndexFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_search_index"), newAddDashboardSearchIndex)
+}
+
+func newAddDashboardSearchIndex(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardSearchIndex{}, nil
+}
+
+func (migration *addDashboardSearchIndex) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardSearchIndex) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_search_index
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_search_index"`
+			DashboardUUID string    `bun:"dashboard_uuid,type:text,pk"`
+			Content       string    `bun:"content,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardSearchIndex) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardShares struct{}
+
+func NewAddDashboardSharesFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_shares"), newAddDashboardShares)
+}
+
+func newAddDashboardShares(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardShares{}, nil
+}
+
+func (migration *addDashboardShares) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardShares) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_shares
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_shares"`
+			ID            string     `bun:"id,type:text,pk"`
+			DashboardUUID string     `bun:"dashboard_uuid,type:text,notnull"`
+			CreatedAt     time.Time  `bun:"created_at,notnull"`
+			CreatedBy     string     `bun:"created_by,type:text,notnull"`
+			ExpiresAt     *time.Time `bun:"expires_at"`
+			RevokedAt     *time.Time `bun:"revoked_at"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboard_shares").
+		Column("dashboard_uuid").
+		Index("idx_dashboard_shares_dashboard_uuid").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardShares) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
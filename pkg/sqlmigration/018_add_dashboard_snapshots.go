@@ -0,0 +1,64 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardSnapshots struct{}
+
+func NewAddDashboardSnapshotsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_snapshots"), newAddDashboardSnapshots)
+}
+
+func newAddDashboardSnapshots(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardSnapshots{}, nil
+}
+
+func (migration *addDashboardSnapshots) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardSnapshots) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_snapshots
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_snapshots"`
+			ID            string    `bun:"id,type:text,pk"`
+			DashboardUUID string    `bun:"dashboard_uuid,type:text,notnull"`
+			Title         string    `bun:"title,type:text,notnull"`
+			StartTime     int64     `bun:"start_time,notnull"`
+			EndTime       int64     `bun:"end_time,notnull"`
+			Data          string    `bun:"data,type:text,notnull"`
+			Results       string    `bun:"results,type:text,notnull"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboard_snapshots").
+		Column("dashboard_uuid").
+		Index("idx_dashboard_snapshots_dashboard_uuid").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardSnapshots) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,67 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardReportSchedules struct{}
+
+func NewAddDashboardReportSchedulesFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_report_schedules"), newAddDashboardReportSchedules)
+}
+
+func newAddDashboardReportSchedules(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardReportSchedules{}, nil
+}
+
+func (migration *addDashboardReportSchedules) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardReportSchedules) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_report_schedules
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_report_schedules"`
+			ID            string     `bun:"id,type:text,pk"`
+			DashboardUUID string     `bun:"dashboard_uuid,type:text,notnull"`
+			Cron          string     `bun:"cron,type:text,notnull"`
+			Recipients    string     `bun:"recipients,type:text,notnull"`
+			TimeRange     string     `bun:"time_range,type:text,notnull"`
+			NextRunAt     time.Time  `bun:"next_run_at,notnull"`
+			LastRunAt     *time.Time `bun:"last_run_at"`
+			LastError     string     `bun:"last_error,type:text"`
+			CreatedAt     time.Time  `bun:"created_at,notnull"`
+			CreatedBy     string     `bun:"created_by,type:text,notnull"`
+			UpdatedAt     time.Time  `bun:"updated_at,notnull"`
+			UpdatedBy     string     `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboard_report_schedules").
+		Column("next_run_at").
+		Index("idx_dashboard_report_schedules_next_run_at").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardReportSchedules) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardVersion struct{}
+
+func NewAddDashboardVersionFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_version"), newAddDashboardVersion)
+}
+
+func newAddDashboardVersion(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardVersion{}, nil
+}
+
+func (migration *addDashboardVersion) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardVersion) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("version INTEGER NOT NULL DEFAULT 1").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "version")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardVersion) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
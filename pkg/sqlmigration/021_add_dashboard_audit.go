@@ -0,0 +1,61 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardAudit struct{}
+
+func NewAddDashboardAuditFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_audit"), newAddDashboardAudit)
+}
+
+func newAddDashboardAudit(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardAudit{}, nil
+}
+
+func (migration *addDashboardAudit) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardAudit) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_audit
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_audit"`
+			ID            string    `bun:"id,type:text,pk"`
+			DashboardUUID string    `bun:"dashboard_uuid,type:text,notnull"`
+			Actor         string    `bun:"actor,type:text,notnull"`
+			Action        string    `bun:"action,type:text,notnull"`
+			Summary       string    `bun:"summary,type:text,notnull"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboard_audit").
+		Column("dashboard_uuid", "created_at").
+		Index("idx_dashboard_audit_dashboard_uuid_created_at").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardAudit) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
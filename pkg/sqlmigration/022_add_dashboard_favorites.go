@@ -0,0 +1,49 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardFavorites struct{}
+
+func NewAddDashboardFavoritesFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_favorites"), newAddDashboardFavorites)
+}
+
+func newAddDashboardFavorites(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardFavorites{}, nil
+}
+
+func (migration *addDashboardFavorites) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardFavorites) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_favorites
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_favorites"`
+			UserID        string    `bun:"user_id,type:text,pk"`
+			DashboardUUID string    `bun:"dashboard_uuid,type:text,pk"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardFavorites) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardViews struct{}
+
+func NewAddDashboardViewsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_views"), newAddDashboardViews)
+}
+
+func newAddDashboardViews(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardViews{}, nil
+}
+
+func (migration *addDashboardViews) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardViews) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("view_count INTEGER NOT NULL DEFAULT 0").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "view_count")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("last_viewed_at TIMESTAMP").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "last_viewed_at")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardViews) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
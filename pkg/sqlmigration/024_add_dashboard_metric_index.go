@@ -0,0 +1,68 @@
+package sqlmigration
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardMetricIndex struct{}
+
+func NewAddDashboardMetricIndexFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_metric_index"), newAddDashboardMetricIndex)
+}
+
+func newAddDashboardMetricIndex(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardMetricIndex{}, nil
+}
+
+func (migration *addDashboardMetricIndex) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardMetricIndex) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_metric_index
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel  `bun:"table:dashboard_metric_index"`
+			MetricName     string `bun:"metric_name,type:text,notnull"`
+			DashboardUUID  string `bun:"dashboard_uuid,type:text,notnull"`
+			DashboardTitle string `bun:"dashboard_title,type:text,notnull"`
+			WidgetID       string `bun:"widget_id,type:text,notnull"`
+			WidgetTitle    string `bun:"widget_title,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboard_metric_index").
+		Column("metric_name").
+		Index("idx_dashboard_metric_index_metric_name").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboard_metric_index").
+		Column("dashboard_uuid").
+		Index("idx_dashboard_metric_index_dashboard_uuid").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardMetricIndex) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardProvisionedFrom struct{}
+
+func NewAddDashboardProvisionedFromFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_provisioned_from"), newAddDashboardProvisionedFrom)
+}
+
+func newAddDashboardProvisionedFrom(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardProvisionedFrom{}, nil
+}
+
+func (migration *addDashboardProvisionedFrom) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardProvisionedFrom) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("provisioned_from TEXT").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "provisioned_from")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardProvisionedFrom) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
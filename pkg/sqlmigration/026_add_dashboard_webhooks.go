@@ -0,0 +1,52 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardWebhooks struct{}
+
+func NewAddDashboardWebhooksFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_webhooks"), newAddDashboardWebhooks)
+}
+
+func newAddDashboardWebhooks(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardWebhooks{}, nil
+}
+
+func (migration *addDashboardWebhooks) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardWebhooks) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_webhooks
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_webhooks"`
+			ID            string    `bun:"id,type:text,pk"`
+			URL           string    `bun:"url,type:text,notnull"`
+			Secret        string    `bun:"secret,type:text,notnull"`
+			Events        string    `bun:"events,type:text,notnull"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardWebhooks) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardLockOwner struct{}
+
+func NewAddDashboardLockOwnerFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_lock_owner"), newAddDashboardLockOwner)
+}
+
+func newAddDashboardLockOwner(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardLockOwner{}, nil
+}
+
+func (migration *addDashboardLockOwner) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardLockOwner) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("locked_by TEXT").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "locked_by")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("locked_at TIMESTAMP").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "locked_at")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardLockOwner) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
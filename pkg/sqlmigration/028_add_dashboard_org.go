@@ -0,0 +1,59 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardOrg struct{}
+
+func NewAddDashboardOrgFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_org"), newAddDashboardOrg)
+}
+
+func newAddDashboardOrg(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardOrg{}, nil
+}
+
+func (migration *addDashboardOrg) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardOrg) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("org_id TEXT").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "org_id")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboards").
+		Column("org_id").
+		Index("idx_dashboards_org_id").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	// single org deployments, which all existing ones are, own all the dashboards
+	if _, err := db.ExecContext(ctx, `UPDATE dashboards SET org_id = (SELECT id FROM organizations) WHERE org_id IS NULL AND (SELECT COUNT(*) FROM organizations) = 1`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardOrg) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
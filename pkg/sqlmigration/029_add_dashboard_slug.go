@@ -0,0 +1,51 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardSlug struct{}
+
+func NewAddDashboardSlugFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_slug"), newAddDashboardSlug)
+}
+
+func newAddDashboardSlug(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardSlug{}, nil
+}
+
+func (migration *addDashboardSlug) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardSlug) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("slug TEXT NOT NULL DEFAULT ''").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "slug")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	// the slugs of the dashboards in the trash can be taken by new dashboards,
+	// existing dashboards get their slug when the query service starts
+	if _, err := db.ExecContext(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS idx_dashboards_slug ON dashboards (slug) WHERE deleted_at IS NULL AND slug != ''`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardSlug) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
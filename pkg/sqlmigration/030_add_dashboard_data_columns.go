@@ -0,0 +1,66 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardDataColumns struct{}
+
+func NewAddDashboardDataColumnsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_data_columns"), newAddDashboardDataColumns)
+}
+
+func newAddDashboardDataColumns(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardDataColumns{}, nil
+}
+
+func (migration *addDashboardDataColumns) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardDataColumns) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("title TEXT NOT NULL DEFAULT ''").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "title")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("schema_version INTEGER NOT NULL DEFAULT 0").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "schema_version")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	// the data is compressed by the query service once the fields it is
+	// filtered and sorted by are copied out of it, it is never compressed in
+	// postgres
+	query := `UPDATE dashboards SET title = COALESCE(json_extract(data, '$.title'), ''), schema_version = COALESCE(json_extract(data, '$.schemaVersion'), 0) WHERE hex(substr(data, 1, 2)) != '1F8B'`
+	if db.Dialect().Name() == dialect.PG {
+		query = `UPDATE dashboards SET title = COALESCE(data::json->>'title', ''), schema_version = COALESCE((data::json->>'schemaVersion')::integer, 0)`
+	}
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardDataColumns) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
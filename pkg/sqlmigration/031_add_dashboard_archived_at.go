@@ -0,0 +1,45 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardArchivedAt struct{}
+
+func NewAddDashboardArchivedAtFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_archived_at"), newAddDashboardArchivedAt)
+}
+
+func newAddDashboardArchivedAt(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardArchivedAt{}, nil
+}
+
+func (migration *addDashboardArchivedAt) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardArchivedAt) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("archived_at TIMESTAMP").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "archived_at")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardArchivedAt) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,65 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardMetadataColumns struct{}
+
+func NewAddDashboardMetadataColumnsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_metadata_columns"), newAddDashboardMetadataColumns)
+}
+
+func newAddDashboardMetadataColumns(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardMetadataColumns{}, nil
+}
+
+func (migration *addDashboardMetadataColumns) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardMetadataColumns) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("description TEXT NOT NULL DEFAULT ''").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "description")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("tags TEXT NOT NULL DEFAULT '[]'").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "tags")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	// the panel count is left null until the query service has copied the
+	// metadata out of the data, which may be compressed
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("panel_count INTEGER").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "panel_count")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardMetadataColumns) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardAuditMessage struct{}
+
+func NewAddDashboardAuditMessageFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_audit_message"), newAddDashboardAuditMessage)
+}
+
+func newAddDashboardAuditMessage(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardAuditMessage{}, nil
+}
+
+func (migration *addDashboardAuditMessage) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardAuditMessage) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboard_audit").
+		ColumnExpr("version INTEGER NOT NULL DEFAULT 0").
+		Apply(WrapIfNotExists(ctx, db, "dashboard_audit", "version")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboard_audit").
+		ColumnExpr("message TEXT NOT NULL DEFAULT ''").
+		Apply(WrapIfNotExists(ctx, db, "dashboard_audit", "message")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardAuditMessage) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
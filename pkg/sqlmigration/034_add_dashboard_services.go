@@ -0,0 +1,70 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardServices struct{}
+
+func NewAddDashboardServicesFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_services"), newAddDashboardServices)
+}
+
+func newAddDashboardServices(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardServices{}, nil
+}
+
+func (migration *addDashboardServices) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardServices) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_services
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_services"`
+			ServiceName   string    `bun:"service_name,type:text,notnull"`
+			DashboardUUID string    `bun:"dashboard_uuid,type:text,notnull"`
+			Source        string    `bun:"source,type:text,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Unique().
+		Table("dashboard_services").
+		Column("service_name", "dashboard_uuid", "source").
+		Index("idx_dashboard_services_service_name_dashboard_uuid_source").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("dashboard_services").
+		Column("dashboard_uuid").
+		Index("idx_dashboard_services_dashboard_uuid").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardServices) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
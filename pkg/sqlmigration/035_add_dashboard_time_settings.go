@@ -0,0 +1,54 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardTimeSettings struct{}
+
+func NewAddDashboardTimeSettingsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_time_settings"), newAddDashboardTimeSettings)
+}
+
+func newAddDashboardTimeSettings(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardTimeSettings{}, nil
+}
+
+func (migration *addDashboardTimeSettings) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardTimeSettings) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("default_time_range TEXT NOT NULL DEFAULT ''").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "default_time_range")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("refresh_interval TEXT NOT NULL DEFAULT ''").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "refresh_interval")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardTimeSettings) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addHomeDashboards struct{}
+
+func NewAddHomeDashboardsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_home_dashboards"), newAddHomeDashboards)
+}
+
+func newAddHomeDashboards(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addHomeDashboards{}, nil
+}
+
+func (migration *addHomeDashboards) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addHomeDashboards) Up(ctx context.Context, db *bun.DB) error {
+	// table:home_dashboards
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:home_dashboards"`
+			OrgID         string    `bun:"org_id,type:text,notnull"`
+			Role          string    `bun:"role,type:text,notnull"`
+			DashboardUUID string    `bun:"dashboard_uuid,type:text,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Unique().
+		Table("home_dashboards").
+		Column("org_id", "role").
+		Index("idx_home_dashboards_org_id_role").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addHomeDashboards) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
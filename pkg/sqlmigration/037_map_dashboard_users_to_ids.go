@@ -0,0 +1,42 @@
+package sqlmigration
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type mapDashboardUsersToIds struct{}
+
+func NewMapDashboardUsersToIdsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("map_dashboard_users_to_ids"), newMapDashboardUsersToIds)
+}
+
+func newMapDashboardUsersToIds(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &mapDashboardUsersToIds{}, nil
+}
+
+func (migration *mapDashboardUsersToIds) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *mapDashboardUsersToIds) Up(ctx context.Context, db *bun.DB) error {
+	// dashboards saved by users who were since deleted keep their email
+	for _, column := range []string{"created_by", "updated_by"} {
+		if _, err := db.ExecContext(ctx, `UPDATE dashboards SET `+column+` = (SELECT id FROM users WHERE users.email = dashboards.`+column+`) WHERE `+column+` IN (SELECT email FROM users)`); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (migration *mapDashboardUsersToIds) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardTrashSettings struct{}
+
+func NewAddDashboardTrashSettingsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_trash_settings"), newAddDashboardTrashSettings)
+}
+
+func newAddDashboardTrashSettings(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardTrashSettings{}, nil
+}
+
+func (migration *addDashboardTrashSettings) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardTrashSettings) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_trash_settings
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_trash_settings"`
+			OrgID         string    `bun:"org_id,pk,type:text"`
+			RetentionDays int       `bun:"retention_days,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardTrashSettings) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
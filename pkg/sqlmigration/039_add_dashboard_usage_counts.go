@@ -0,0 +1,47 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardUsageCounts struct{}
+
+func NewAddDashboardUsageCountsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_usage_counts"), newAddDashboardUsageCounts)
+}
+
+func newAddDashboardUsageCounts(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardUsageCounts{}, nil
+}
+
+func (migration *addDashboardUsageCounts) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardUsageCounts) Up(ctx context.Context, db *bun.DB) error {
+	// the counts are left null until the query service has counted them in
+	// the data, which may be compressed
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboards").
+		ColumnExpr("usage_counts TEXT").
+		Apply(WrapIfNotExists(ctx, db, "dashboards", "usage_counts")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardUsageCounts) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addAnnotations struct{}
+
+func NewAddAnnotationsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_annotations"), newAddAnnotations)
+}
+
+func newAddAnnotations(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addAnnotations{}, nil
+}
+
+func (migration *addAnnotations) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addAnnotations) Up(ctx context.Context, db *bun.DB) error {
+	// table:annotations
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:annotations"`
+			ID            string    `bun:"id,pk,type:text"`
+			OrgID         string    `bun:"org_id,type:text,notnull"`
+			Time          int64     `bun:"time,notnull"`
+			EndTime       int64     `bun:"end_time,notnull"`
+			Title         string    `bun:"title,type:text,notnull"`
+			Text          string    `bun:"text,type:text"`
+			Tags          string    `bun:"tags,type:text"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("annotations").
+		Column("org_id", "time").
+		Index("idx_annotations_org_id_time").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addAnnotations) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
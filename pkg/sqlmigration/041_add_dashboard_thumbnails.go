@@ -0,0 +1,50 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardThumbnails struct{}
+
+func NewAddDashboardThumbnailsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_thumbnails"), newAddDashboardThumbnails)
+}
+
+func newAddDashboardThumbnails(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardThumbnails{}, nil
+}
+
+func (migration *addDashboardThumbnails) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardThumbnails) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_thumbnails
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_thumbnails"`
+			DashboardUUID string    `bun:"dashboard_uuid,pk,type:text"`
+			Version       int       `bun:"version,notnull"`
+			Content       string    `bun:"content,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardThumbnails) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
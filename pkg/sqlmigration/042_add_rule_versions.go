@@ -0,0 +1,69 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addRuleVersions struct{}
+
+func NewAddRuleVersionsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_rule_versions"), newAddRuleVersions)
+}
+
+func newAddRuleVersions(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addRuleVersions{}, nil
+}
+
+func (migration *addRuleVersions) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addRuleVersions) Up(ctx context.Context, db *bun.DB) error {
+	// table:rule_versions
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:rule_versions"`
+			ID            int       `bun:"id,pk,autoincrement"`
+			RuleID        int       `bun:"rule_id,notnull"`
+			Version       int       `bun:"version,notnull"`
+			Data          string    `bun:"data,type:text,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("rule_versions").
+		Column("rule_id", "version").
+		Index("idx_rule_versions_rule_id_version").
+		Unique().
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	// the history of the existing rules starts at their current definition
+	if _, err := db.ExecContext(ctx, `INSERT INTO rule_versions (rule_id, version, data, created_by, created_at)
+		SELECT id, 1, data, updated_by, updated_at FROM rules
+		WHERE id NOT IN (SELECT rule_id FROM rule_versions)`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addRuleVersions) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addMaintenanceMatchers struct{}
+
+func NewAddMaintenanceMatchersFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_maintenance_matchers"), newAddMaintenanceMatchers)
+}
+
+func newAddMaintenanceMatchers(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addMaintenanceMatchers{}, nil
+}
+
+func (migration *addMaintenanceMatchers) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addMaintenanceMatchers) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("planned_maintenance").
+		ColumnExpr("matchers TEXT").
+		Apply(WrapIfNotExists(ctx, db, "planned_maintenance", "matchers")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	// table:planned_maintenance_suppressions
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel      `bun:"table:planned_maintenance_suppressions"`
+			MaintenanceID      int       `bun:"maintenance_id,pk"`
+			RuleID             string    `bun:"rule_id,pk,type:text"`
+			SuppressedAlerts   int       `bun:"suppressed_alerts,notnull,default:0"`
+			SkippedEvaluations int       `bun:"skipped_evaluations,notnull,default:0"`
+			LastSuppressedAt   time.Time `bun:"last_suppressed_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addMaintenanceMatchers) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
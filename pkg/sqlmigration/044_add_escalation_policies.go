@@ -0,0 +1,83 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addEscalationPolicies struct{}
+
+func NewAddEscalationPoliciesFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_escalation_policies"), newAddEscalationPolicies)
+}
+
+func newAddEscalationPolicies(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addEscalationPolicies{}, nil
+}
+
+func (migration *addEscalationPolicies) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addEscalationPolicies) Up(ctx context.Context, db *bun.DB) error {
+	// table:escalation_policies
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:escalation_policies"`
+			ID            int       `bun:"id,pk,autoincrement"`
+			Name          string    `bun:"name,type:text,notnull"`
+			Description   string    `bun:"description,type:text"`
+			Steps         string    `bun:"steps,type:text,notnull"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	// table:alert_escalations
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:alert_escalations"`
+			ID            int       `bun:"id,pk,autoincrement"`
+			RuleID        string    `bun:"rule_id,type:text,notnull"`
+			Fingerprint   string    `bun:"fingerprint,type:text,notnull"`
+			Labels        string    `bun:"labels,type:text,notnull"`
+			FiredAt       time.Time `bun:"fired_at,notnull"`
+			Kind          string    `bun:"kind,type:text,notnull"`
+			Step          int       `bun:"step,notnull,default:0"`
+			Channels      string    `bun:"channels,type:text"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("alert_escalations").
+		Column("rule_id", "fingerprint").
+		Index("idx_alert_escalations_rule_id_fingerprint").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addEscalationPolicies) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,56 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addOnCallSchedules struct{}
+
+func NewAddOnCallSchedulesFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_oncall_schedules"), newAddOnCallSchedules)
+}
+
+func newAddOnCallSchedules(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addOnCallSchedules{}, nil
+}
+
+func (migration *addOnCallSchedules) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addOnCallSchedules) Up(ctx context.Context, db *bun.DB) error {
+	// table:oncall_schedules
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:oncall_schedules"`
+			ID            int       `bun:"id,pk,autoincrement"`
+			Name          string    `bun:"name,type:text,notnull,unique"`
+			Description   string    `bun:"description,type:text"`
+			Timezone      string    `bun:"timezone,type:text,notnull"`
+			Rotations     string    `bun:"rotations,type:text,notnull"`
+			Overrides     string    `bun:"overrides,type:text"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addOnCallSchedules) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
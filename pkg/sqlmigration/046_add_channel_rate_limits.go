@@ -0,0 +1,53 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addChannelRateLimits struct{}
+
+func NewAddChannelRateLimitsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_channel_rate_limits"), newAddChannelRateLimits)
+}
+
+func newAddChannelRateLimits(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addChannelRateLimits{}, nil
+}
+
+func (migration *addChannelRateLimits) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addChannelRateLimits) Up(ctx context.Context, db *bun.DB) error {
+	// table:channel_rate_limits
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel    `bun:"table:channel_rate_limits"`
+			ID               int       `bun:"id,pk,autoincrement"`
+			Channel          string    `bun:"channel,type:text,notnull,unique"`
+			MaxNotifications int       `bun:"max_notifications,notnull"`
+			Period           string    `bun:"period,type:text,notnull"`
+			Burst            int       `bun:"burst,notnull"`
+			UpdatedAt        time.Time `bun:"updated_at,notnull"`
+			UpdatedBy        string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addChannelRateLimits) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
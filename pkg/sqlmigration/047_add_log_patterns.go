@@ -0,0 +1,52 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addLogPatterns struct{}
+
+func NewAddLogPatternsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_log_patterns"), newAddLogPatterns)
+}
+
+func newAddLogPatterns(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addLogPatterns{}, nil
+}
+
+func (migration *addLogPatterns) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addLogPatterns) Up(ctx context.Context, db *bun.DB) error {
+	// table:log_patterns
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:log_patterns"`
+			ID            int       `bun:"id,pk,autoincrement"`
+			RuleID        string    `bun:"rule_id,type:text,notnull,unique:rule_group_fingerprint"`
+			GroupKey      string    `bun:"group_key,type:text,notnull,unique:rule_group_fingerprint"`
+			Fingerprint   string    `bun:"fingerprint,type:text,notnull,unique:rule_group_fingerprint"`
+			Pattern       string    `bun:"pattern,type:text,notnull"`
+			FirstSeen     time.Time `bun:"first_seen,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addLogPatterns) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,58 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addSLOs struct{}
+
+func NewAddSLOsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_slos"), newAddSLOs)
+}
+
+func newAddSLOs(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addSLOs{}, nil
+}
+
+func (migration *addSLOs) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addSLOs) Up(ctx context.Context, db *bun.DB) error {
+	// table:slos
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel     `bun:"table:slos"`
+			ID                int       `bun:"id,pk,autoincrement"`
+			Name              string    `bun:"name,type:text,notnull,unique"`
+			Description       string    `bun:"description,type:text"`
+			SLI               string    `bun:"sli,type:text,notnull"`
+			Target            float64   `bun:"target,notnull"`
+			TimeWindow        string    `bun:"time_window,type:text,notnull"`
+			PreferredChannels string    `bun:"preferred_channels,type:text"`
+			RuleIds           string    `bun:"rule_ids,type:text"`
+			CreatedAt         time.Time `bun:"created_at,notnull"`
+			CreatedBy         string    `bun:"created_by,type:text,notnull"`
+			UpdatedAt         time.Time `bun:"updated_at,notnull"`
+			UpdatedBy         string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addSLOs) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
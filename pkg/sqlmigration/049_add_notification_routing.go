@@ -0,0 +1,50 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addNotificationRouting struct{}
+
+func NewAddNotificationRoutingFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_notification_routing"), newAddNotificationRouting)
+}
+
+func newAddNotificationRouting(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addNotificationRouting{}, nil
+}
+
+func (migration *addNotificationRouting) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addNotificationRouting) Up(ctx context.Context, db *bun.DB) error {
+	// table:notification_routing
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:notification_routing"`
+			ID            int       `bun:"id,pk"`
+			Route         string    `bun:"route,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addNotificationRouting) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
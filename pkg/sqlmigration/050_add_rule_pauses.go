@@ -0,0 +1,63 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addRulePauses struct{}
+
+func NewAddRulePausesFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_rule_pauses"), newAddRulePauses)
+}
+
+func newAddRulePauses(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addRulePauses{}, nil
+}
+
+func (migration *addRulePauses) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addRulePauses) Up(ctx context.Context, db *bun.DB) error {
+	// table:rule_pauses
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:rule_pauses"`
+			ID            int        `bun:"id,pk,autoincrement"`
+			RuleID        string     `bun:"rule_id,type:text,notnull"`
+			Reason        string     `bun:"reason,type:text"`
+			PausedAt      time.Time  `bun:"paused_at,notnull"`
+			PausedBy      string     `bun:"paused_by,type:text,notnull"`
+			ResumeAt      *time.Time `bun:"resume_at"`
+			ResumedAt     *time.Time `bun:"resumed_at"`
+			ResumedBy     string     `bun:"resumed_by,type:text"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewCreateIndex().
+		Table("rule_pauses").
+		Column("rule_id").
+		Index("idx_rule_pauses_rule_id").
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addRulePauses) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,52 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addChannelResolvedNotifications struct{}
+
+func NewAddChannelResolvedNotificationsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_channel_resolved_notifs"), newAddChannelResolvedNotifications)
+}
+
+func newAddChannelResolvedNotifications(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addChannelResolvedNotifications{}, nil
+}
+
+func (migration *addChannelResolvedNotifications) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addChannelResolvedNotifications) Up(ctx context.Context, db *bun.DB) error {
+	// table:channel_resolved_notifications
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel     `bun:"table:channel_resolved_notifications"`
+			ID                int       `bun:"id,pk,autoincrement"`
+			Channel           string    `bun:"channel,type:text,notnull,unique"`
+			Disabled          bool      `bun:"disabled,notnull"`
+			MinFiringDuration string    `bun:"min_firing_duration,type:text,notnull"`
+			UpdatedAt         time.Time `bun:"updated_at,notnull"`
+			UpdatedBy         string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addChannelResolvedNotifications) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,51 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addChannelTemplates struct{}
+
+func NewAddChannelTemplatesFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_channel_templates"), newAddChannelTemplates)
+}
+
+func newAddChannelTemplates(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addChannelTemplates{}, nil
+}
+
+func (migration *addChannelTemplates) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addChannelTemplates) Up(ctx context.Context, db *bun.DB) error {
+	// table:channel_templates
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:channel_templates"`
+			ID            int       `bun:"id,pk,autoincrement"`
+			Type          string    `bun:"type,type:text,notnull,unique"`
+			Fields        string    `bun:"fields,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addChannelTemplates) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
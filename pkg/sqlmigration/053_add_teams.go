@@ -0,0 +1,54 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addTeams struct{}
+
+func NewAddTeamsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_teams"), newAddTeams)
+}
+
+func newAddTeams(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addTeams{}, nil
+}
+
+func (migration *addTeams) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addTeams) Up(ctx context.Context, db *bun.DB) error {
+	// table:teams
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:teams"`
+			ID            int       `bun:"id,pk,autoincrement"`
+			Name          string    `bun:"name,type:text,notnull,unique"`
+			Description   string    `bun:"description,type:text"`
+			Members       string    `bun:"members,type:text,notnull"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addTeams) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,51 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardWidgetAlerts struct{}
+
+func NewAddDashboardWidgetAlertsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_widget_alerts"), newAddDashboardWidgetAlerts)
+}
+
+func newAddDashboardWidgetAlerts(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardWidgetAlerts{}, nil
+}
+
+func (migration *addDashboardWidgetAlerts) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardWidgetAlerts) Up(ctx context.Context, db *bun.DB) error {
+	// table:dashboard_widget_alerts
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:dashboard_widget_alerts"`
+			DashboardUUID string    `bun:"dashboard_uuid,type:text,pk"`
+			WidgetID      string    `bun:"widget_id,type:text,pk"`
+			RuleID        int       `bun:"rule_id,pk"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardWidgetAlerts) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,59 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addAlertDigests struct{}
+
+func NewAddAlertDigestsFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_alert_digests"), newAddAlertDigests)
+}
+
+func newAddAlertDigests(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addAlertDigests{}, nil
+}
+
+func (migration *addAlertDigests) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addAlertDigests) Up(ctx context.Context, db *bun.DB) error {
+	// table:alert_digests
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:alert_digests"`
+			ID            int        `bun:"id,pk,autoincrement"`
+			TeamID        int        `bun:"team_id,notnull"`
+			Cron          string     `bun:"cron,type:text,notnull"`
+			Period        string     `bun:"period,type:text,notnull"`
+			Recipients    string     `bun:"recipients,type:text,notnull"`
+			Channels      string     `bun:"channels,type:text,notnull"`
+			NextRunAt     time.Time  `bun:"next_run_at,notnull"`
+			LastRunAt     *time.Time `bun:"last_run_at"`
+			LastError     string     `bun:"last_error,type:text"`
+			CreatedAt     time.Time  `bun:"created_at,notnull"`
+			CreatedBy     string     `bun:"created_by,type:text,notnull"`
+			UpdatedAt     time.Time  `bun:"updated_at,notnull"`
+			UpdatedBy     string     `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addAlertDigests) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addAlertAcknowledgementNote struct{}
+
+func NewAddAlertAcknowledgementNoteFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_alert_acknowledgement_note"), newAddAlertAcknowledgementNote)
+}
+
+func newAddAlertAcknowledgementNote(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addAlertAcknowledgementNote{}, nil
+}
+
+func (migration *addAlertAcknowledgementNote) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addAlertAcknowledgementNote) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("alert_escalations").
+		ColumnExpr("note TEXT NOT NULL DEFAULT ''").
+		Apply(WrapIfNotExists(ctx, db, "alert_escalations", "note")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	if _, err := db.
+		NewAddColumn().
+		Table("alert_escalations").
+		ColumnExpr("silence_id TEXT NOT NULL DEFAULT ''").
+		Apply(WrapIfNotExists(ctx, db, "alert_escalations", "silence_id")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addAlertAcknowledgementNote) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
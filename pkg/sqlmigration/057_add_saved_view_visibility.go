@@ -0,0 +1,45 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addSavedViewVisibility struct{}
+
+func NewAddSavedViewVisibilityFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_saved_view_visibility"), newAddSavedViewVisibility)
+}
+
+func newAddSavedViewVisibility(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addSavedViewVisibility{}, nil
+}
+
+func (migration *addSavedViewVisibility) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addSavedViewVisibility) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("saved_views").
+		ColumnExpr("visibility TEXT NOT NULL DEFAULT 'org'").
+		Apply(WrapIfNotExists(ctx, db, "saved_views", "visibility")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addSavedViewVisibility) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addQueryMacros struct{}
+
+func NewAddQueryMacrosFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_query_macros"), newAddQueryMacros)
+}
+
+func newAddQueryMacros(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addQueryMacros{}, nil
+}
+
+func (migration *addQueryMacros) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addQueryMacros) Up(ctx context.Context, db *bun.DB) error {
+	// table:query_macros
+	if _, err := db.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:query_macros"`
+			ID            string    `bun:"id,pk,type:text"`
+			Name          string    `bun:"name,type:text,notnull,unique"`
+			Expression    string    `bun:"expression,type:text,notnull"`
+			Description   string    `bun:"description,type:text"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addQueryMacros) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
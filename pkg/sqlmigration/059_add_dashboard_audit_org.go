@@ -0,0 +1,54 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addDashboardAuditOrg struct{}
+
+func NewAddDashboardAuditOrgFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_dashboard_audit_org"), newAddDashboardAuditOrg)
+}
+
+func newAddDashboardAuditOrg(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addDashboardAuditOrg{}, nil
+}
+
+func (migration *addDashboardAuditOrg) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardAuditOrg) Up(ctx context.Context, db *bun.DB) error {
+	if _, err := db.
+		NewAddColumn().
+		Table("dashboard_audit").
+		ColumnExpr("org_id TEXT").
+		Apply(WrapIfNotExists(ctx, db, "dashboard_audit", "org_id")).
+		Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+		return err
+	}
+
+	// the entries of purged dashboards keep no org unless there is a single one
+	if _, err := db.ExecContext(ctx, `UPDATE dashboard_audit SET org_id = (SELECT org_id FROM dashboards WHERE dashboards.uuid = dashboard_audit.dashboard_uuid) WHERE org_id IS NULL`); err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, `UPDATE dashboard_audit SET org_id = (SELECT id FROM organizations) WHERE org_id IS NULL AND (SELECT COUNT(*) FROM organizations) = 1`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addDashboardAuditOrg) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
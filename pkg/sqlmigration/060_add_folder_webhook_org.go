@@ -0,0 +1,52 @@
+package sqlmigration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addFolderWebhookOrg struct{}
+
+func NewAddFolderWebhookOrgFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_folder_webhook_org"), newAddFolderWebhookOrg)
+}
+
+func newAddFolderWebhookOrg(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addFolderWebhookOrg{}, nil
+}
+
+func (migration *addFolderWebhookOrg) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addFolderWebhookOrg) Up(ctx context.Context, db *bun.DB) error {
+	for _, table := range []string{"folders", "dashboard_webhooks"} {
+		if _, err := db.
+			NewAddColumn().
+			Table(table).
+			ColumnExpr("org_id TEXT").
+			Apply(WrapIfNotExists(ctx, db, table, "org_id")).
+			Exec(ctx); err != nil && !errors.Is(err, ErrNoExecute) {
+			return err
+		}
+
+		// single org deployments, which all existing ones are, own all of them
+		if _, err := db.ExecContext(ctx, `UPDATE `+table+` SET org_id = (SELECT id FROM organizations) WHERE org_id IS NULL AND (SELECT COUNT(*) FROM organizations) = 1`); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (migration *addFolderWebhookOrg) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
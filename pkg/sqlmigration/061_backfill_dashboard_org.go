@@ -0,0 +1,49 @@
+package sqlmigration
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type backfillDashboardOrg struct{}
+
+func NewBackfillDashboardOrgFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("backfill_dashboard_org"), newBackfillDashboardOrg)
+}
+
+func newBackfillDashboardOrg(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &backfillDashboardOrg{}, nil
+}
+
+func (migration *backfillDashboardOrg) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *backfillDashboardOrg) Up(ctx context.Context, db *bun.DB) error {
+	// the rows are given the org of the user who created them, matched by id
+	// or by email as older rows recorded either
+	for _, backfill := range []string{
+		`UPDATE dashboards SET org_id = (SELECT users.org_id FROM users WHERE users.id = dashboards.created_by OR users.email = dashboards.created_by OR users.email = dashboards.owner LIMIT 1) WHERE org_id IS NULL`,
+		`UPDATE folders SET org_id = (SELECT users.org_id FROM users WHERE users.id = folders.created_by OR users.email = folders.created_by LIMIT 1) WHERE org_id IS NULL`,
+		`UPDATE dashboard_webhooks SET org_id = (SELECT users.org_id FROM users WHERE users.id = dashboard_webhooks.created_by OR users.email = dashboard_webhooks.created_by LIMIT 1) WHERE org_id IS NULL`,
+		`UPDATE dashboard_audit SET org_id = (SELECT dashboards.org_id FROM dashboards WHERE dashboards.uuid = dashboard_audit.dashboard_uuid) WHERE org_id IS NULL`,
+		`UPDATE dashboard_audit SET org_id = (SELECT users.org_id FROM users WHERE users.email = dashboard_audit.actor LIMIT 1) WHERE org_id IS NULL`,
+	} {
+		if _, err := db.ExecContext(ctx, backfill); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (migration *backfillDashboardOrg) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
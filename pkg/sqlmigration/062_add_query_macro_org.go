@@ -0,0 +1,80 @@
+package sqlmigration
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"go.signoz.io/signoz/pkg/factory"
+)
+
+type addQueryMacroOrg struct{}
+
+func NewAddQueryMacroOrgFactory() factory.ProviderFactory[SQLMigration, Config] {
+	return factory.NewProviderFactory(factory.MustNewName("add_query_macro_org"), newAddQueryMacroOrg)
+}
+
+func newAddQueryMacroOrg(_ context.Context, _ factory.ProviderSettings, _ Config) (SQLMigration, error) {
+	return &addQueryMacroOrg{}, nil
+}
+
+func (migration *addQueryMacroOrg) Register(migrations *migrate.Migrations) error {
+	if err := migrations.Register(migration.Up, migration.Down); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addQueryMacroOrg) Up(ctx context.Context, db *bun.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the table is rebuilt as the names of the macros are unique in their org
+	if _, err := tx.NewCreateTable().
+		Model(&struct {
+			bun.BaseModel `bun:"table:query_macros_org"`
+			ID            string    `bun:"id,pk,type:text"`
+			OrgID         string    `bun:"org_id,type:text,unique:org_name"`
+			Name          string    `bun:"name,type:text,notnull,unique:org_name"`
+			Expression    string    `bun:"expression,type:text,notnull"`
+			Description   string    `bun:"description,type:text"`
+			CreatedAt     time.Time `bun:"created_at,notnull"`
+			CreatedBy     string    `bun:"created_by,type:text,notnull"`
+			UpdatedAt     time.Time `bun:"updated_at,notnull"`
+			UpdatedBy     string    `bun:"updated_by,type:text,notnull"`
+		}{}).
+		IfNotExists().
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	// the macros are given the org of the user who created them
+	if _, err := tx.ExecContext(ctx, `INSERT INTO query_macros_org (id, org_id, name, expression, description, created_at, created_by, updated_at, updated_by)
+		SELECT id, (SELECT users.org_id FROM users WHERE users.email = query_macros.created_by LIMIT 1), name, expression, description, created_at, created_by, updated_at, updated_by
+		FROM query_macros`); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DROP TABLE query_macros`); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE query_macros_org RENAME TO query_macros`); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (migration *addQueryMacroOrg) Down(ctx context.Context, db *bun.DB) error {
+	return nil
+}
@@ -39,7 +39,7 @@ type Channel struct {
 
 // NewChannelFromReceiver creates a new Channel from a Receiver.
 // It can return nil if the receiver is the default receiver.
-func NewChannelFromReceiver(receiver config.Receiver, orgID string) *Channel {
+func NewChannelFromReceiver(receiver Receiver, orgID string) *Channel {
 	if receiver.Name == DefaultReceiverName {
 		return nil
 	}
@@ -53,8 +53,8 @@ func NewChannelFromReceiver(receiver config.Receiver, orgID string) *Channel {
 	}
 
 	// Use reflection to examine receiver struct fields
-	receiverType := reflect.TypeOf(receiver)
-	receiverVal := reflect.ValueOf(receiver)
+	receiverType := reflect.TypeOf(receiver.Receiver)
+	receiverVal := reflect.ValueOf(receiver.AlertmanagerReceiver())
 
 	// Iterate through fields looking for *Config fields
 	for i := 0; i < receiverType.NumField(); i++ {
@@ -105,8 +105,17 @@ func NewReceiverFromChannel(channel *Channel) (Receiver, error) {
 }
 
 func NewChannelsFromConfig(c *config.Config, orgID string) Channels {
-	channels := Channels{}
+	receivers := make([]Receiver, 0, len(c.Receivers))
 	for _, receiver := range c.Receivers {
+		receivers = append(receivers, newReceiver(receiver, nil))
+	}
+
+	return newChannelsFromReceivers(receivers, orgID)
+}
+
+func newChannelsFromReceivers(receivers []Receiver, orgID string) Channels {
+	channels := Channels{}
+	for _, receiver := range receivers {
 		channel := NewChannelFromReceiver(receiver, orgID)
 		if channel == nil {
 			continue
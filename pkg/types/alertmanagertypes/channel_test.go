@@ -194,3 +194,50 @@ func TestNewConfigFromChannels(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigWebhookSigningSecret(t *testing.T) {
+	c, err := NewDefaultConfig(GlobalConfig{}, RouteConfig{}, "1")
+	assert.NoError(t, err)
+
+	receiver, err := NewReceiver(`{"name":"signed-receiver","webhook_configs":[{"url":"https://example.com/hook","signing_secret":"s3cret"}]}`)
+	assert.NoError(t, err)
+	assert.NoError(t, c.CreateReceiver(NewRouteFromReceiver(receiver), receiver))
+
+	// the alertmanager configuration does not hold the secret
+	receivers, err := json.Marshal(c.AlertmanagerConfig().Receivers)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(receivers), "s3cret")
+	assert.Contains(t, c.Channels()["signed-receiver"].Data, `"signing_secret":"s3cret"`)
+
+	// the secret is kept in the store
+	stored, err := NewConfigFromStoreableConfig(c.StoreableConfig())
+	assert.NoError(t, err)
+	for _, receiver := range stored.Receivers() {
+		if receiver.Name == "signed-receiver" {
+			assert.Equal(t, config.Secret("s3cret"), receiver.WebhookConfigs[0].SigningSecret)
+		}
+	}
+
+	receiver.WebhookConfigs[0].SigningSecret = ""
+	assert.NoError(t, c.UpdateReceiver(NewRouteFromReceiver(receiver), receiver))
+	assert.NotContains(t, c.StoreableConfig().Config, "s3cret")
+}
+
+func TestConfigWebhookPayloadTemplate(t *testing.T) {
+	c, err := NewDefaultConfig(GlobalConfig{}, RouteConfig{}, "1")
+	assert.NoError(t, err)
+
+	receiver, err := NewReceiver(`{"name":"templated-receiver","webhook_configs":[{"url":"https://example.com/hook"},{"url":"https://example.com/templated","payload_template":"{\"status\": \"{{ .Status }}\"}"}]}`)
+	assert.NoError(t, err)
+	assert.NoError(t, c.CreateReceiver(NewRouteFromReceiver(receiver), receiver))
+
+	// the template is kept in the store with its webhook
+	stored, err := NewConfigFromStoreableConfig(c.StoreableConfig())
+	assert.NoError(t, err)
+	for _, receiver := range stored.Receivers() {
+		if receiver.Name == "templated-receiver" {
+			assert.Empty(t, receiver.WebhookConfigs[0].PayloadTemplate)
+			assert.Equal(t, `{"status": "{{ .Status }}"}`, receiver.WebhookConfigs[1].PayloadTemplate)
+		}
+	}
+}
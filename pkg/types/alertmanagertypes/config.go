@@ -57,6 +57,11 @@ type Config struct {
 	// channels is the list of channels
 	channels Channels
 
+	// webhookFields are the signing secrets and the payload templates of the
+	// webhooks of the receivers by receiver name, in the order of their
+	// webhooks. The alertmanager configuration has no place for them.
+	webhookFields map[string][]webhookFields
+
 	// orgID is the organization ID
 	orgID string
 }
@@ -66,29 +71,31 @@ func NewConfig(c *config.Config, orgID string) *Config {
 	return &Config{
 		alertmanagerConfig: c,
 		storeableConfig: &StoreableConfig{
-			Config:    string(newRawFromConfig(c)),
+			Config:    string(newRawFromConfig(c, nil)),
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 			OrgID:     orgID,
 		},
-		channels: channels,
+		channels:      channels,
+		webhookFields: map[string][]webhookFields{},
 	}
 }
 
 func NewConfigFromStoreableConfig(sc *StoreableConfig) (*Config, error) {
-	alertmanagerConfig, err := newConfigFromString(sc.Config)
+	alertmanagerConfig, webhookFields, err := newConfigFromString(sc.Config)
 	if err != nil {
 		return nil, err
 	}
 
-	channels := NewChannelsFromConfig(alertmanagerConfig, sc.OrgID)
-
-	return &Config{
+	c := &Config{
 		alertmanagerConfig: alertmanagerConfig,
 		storeableConfig:    sc,
-		channels:           channels,
 		orgID:              sc.OrgID,
-	}, nil
+		webhookFields:      webhookFields,
+	}
+	c.channels = newChannelsFromReceivers(c.Receivers(), sc.OrgID)
+
+	return c, nil
 }
 
 func NewRouteFromReceiver(receiver Receiver) *config.Route {
@@ -114,18 +121,41 @@ func NewDefaultConfig(globalConfig GlobalConfig, routeConfig RouteConfig, orgID
 	}, orgID), nil
 }
 
-func newConfigFromString(s string) (*config.Config, error) {
-	config := new(config.Config)
-	err := json.Unmarshal([]byte(s), config)
+// storeableAlertmanagerConfig is the alertmanager configuration as stored,
+// its receivers hold the signing secrets and the payload templates of their
+// webhooks
+type storeableAlertmanagerConfig struct {
+	*config.Config
+
+	Receivers []Receiver `json:"receivers,omitempty"`
+}
+
+func newConfigFromString(s string) (*config.Config, map[string][]webhookFields, error) {
+	sc := storeableAlertmanagerConfig{Config: new(config.Config)}
+	err := json.Unmarshal([]byte(s), &sc)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return config, nil
+	webhookFields := map[string][]webhookFields{}
+	sc.Config.Receivers = make([]config.Receiver, 0, len(sc.Receivers))
+	for _, receiver := range sc.Receivers {
+		sc.Config.Receivers = append(sc.Config.Receivers, receiver.AlertmanagerReceiver())
+		if fields := receiver.webhookFields(); fields != nil {
+			webhookFields[receiver.Name] = fields
+		}
+	}
+
+	return sc.Config, webhookFields, nil
 }
 
-func newRawFromConfig(c *config.Config) []byte {
-	b, err := json.Marshal(c)
+func newRawFromConfig(c *config.Config, webhookFields map[string][]webhookFields) []byte {
+	sc := storeableAlertmanagerConfig{Config: c}
+	for _, receiver := range c.Receivers {
+		sc.Receivers = append(sc.Receivers, newReceiver(receiver, webhookFields[receiver.Name]))
+	}
+
+	b, err := json.Marshal(sc)
 	if err != nil {
 		// Taking inspiration from the upstream. This is never expected to happen.
 		return []byte(fmt.Sprintf("<error creating config string: %s>", err))
@@ -142,6 +172,17 @@ func (c *Config) StoreableConfig() *StoreableConfig {
 	return c.storeableConfig
 }
 
+// Receivers returns the receivers of the alertmanager configuration with the
+// signing secrets and the payload templates of their webhooks
+func (c *Config) Receivers() []Receiver {
+	receivers := make([]Receiver, 0, len(c.alertmanagerConfig.Receivers))
+	for _, receiver := range c.alertmanagerConfig.Receivers {
+		receivers = append(receivers, newReceiver(receiver, c.webhookFields[receiver.Name]))
+	}
+
+	return receivers
+}
+
 func (c *Config) Channels() Channels {
 	return c.channels
 }
@@ -151,14 +192,14 @@ func (c *Config) OrgID() string {
 }
 
 func (c *Config) Raw() []byte {
-	return newRawFromConfig(c.alertmanagerConfig)
+	return newRawFromConfig(c.alertmanagerConfig, c.webhookFields)
 }
 
 func (c *Config) Hash() [16]byte {
-	return md5.Sum(newRawFromConfig(c.alertmanagerConfig))
+	return md5.Sum(newRawFromConfig(c.alertmanagerConfig, c.webhookFields))
 }
 
-func (c *Config) CreateReceiver(route *config.Route, receiver config.Receiver) error {
+func (c *Config) CreateReceiver(route *config.Route, receiver Receiver) error {
 	if route == nil {
 		return errors.New(errors.TypeInvalidInput, ErrCodeAlertmanagerConfigInvalid, "route is nil")
 	}
@@ -179,7 +220,8 @@ func (c *Config) CreateReceiver(route *config.Route, receiver config.Receiver) e
 	route.Continue = true
 
 	c.alertmanagerConfig.Route.Routes = append(c.alertmanagerConfig.Route.Routes, route)
-	c.alertmanagerConfig.Receivers = append(c.alertmanagerConfig.Receivers, receiver)
+	c.alertmanagerConfig.Receivers = append(c.alertmanagerConfig.Receivers, receiver.AlertmanagerReceiver())
+	c.setWebhookFields(receiver)
 
 	if err := c.alertmanagerConfig.UnmarshalYAML(func(i interface{}) error { return nil }); err != nil {
 		return err
@@ -190,12 +232,12 @@ func (c *Config) CreateReceiver(route *config.Route, receiver config.Receiver) e
 		c.channels[channel.Name] = channel
 	}
 
-	c.storeableConfig.Config = string(newRawFromConfig(c.alertmanagerConfig))
+	c.storeableConfig.Config = string(newRawFromConfig(c.alertmanagerConfig, c.webhookFields))
 	c.storeableConfig.UpdatedAt = time.Now()
 	return nil
 }
 
-func (c *Config) UpdateReceiver(route *config.Route, receiver config.Receiver) error {
+func (c *Config) UpdateReceiver(route *config.Route, receiver Receiver) error {
 	if route == nil {
 		return errors.New(errors.TypeInvalidInput, ErrCodeAlertmanagerConfigInvalid, "route is nil")
 	}
@@ -207,7 +249,8 @@ func (c *Config) UpdateReceiver(route *config.Route, receiver config.Receiver) e
 	// find and update receiver
 	for i, existingReceiver := range c.alertmanagerConfig.Receivers {
 		if existingReceiver.Name == receiver.Name {
-			c.alertmanagerConfig.Receivers[i] = receiver
+			c.alertmanagerConfig.Receivers[i] = receiver.AlertmanagerReceiver()
+			c.setWebhookFields(receiver)
 			channel := NewChannelFromReceiver(receiver, c.orgID)
 			if channel != nil {
 				c.channels[channel.Name] = channel
@@ -225,7 +268,7 @@ func (c *Config) UpdateReceiver(route *config.Route, receiver config.Receiver) e
 		}
 	}
 
-	c.storeableConfig.Config = string(newRawFromConfig(c.alertmanagerConfig))
+	c.storeableConfig.Config = string(newRawFromConfig(c.alertmanagerConfig, c.webhookFields))
 	c.storeableConfig.UpdatedAt = time.Now()
 
 	return nil
@@ -248,16 +291,25 @@ func (c *Config) DeleteReceiver(name string) error {
 		if existingReceiver.Name == name {
 			c.alertmanagerConfig.Receivers = append(c.alertmanagerConfig.Receivers[:i], c.alertmanagerConfig.Receivers[i+1:]...)
 			delete(c.channels, name)
+			delete(c.webhookFields, name)
 			break
 		}
 	}
 
-	c.storeableConfig.Config = string(newRawFromConfig(c.alertmanagerConfig))
+	c.storeableConfig.Config = string(newRawFromConfig(c.alertmanagerConfig, c.webhookFields))
 	c.storeableConfig.UpdatedAt = time.Now()
 
 	return nil
 }
 
+func (c *Config) setWebhookFields(receiver Receiver) {
+	if fields := receiver.webhookFields(); fields != nil {
+		c.webhookFields[receiver.Name] = fields
+		return
+	}
+	delete(c.webhookFields, receiver.Name)
+}
+
 type ConfigStore interface {
 	// Set creates or updates a config.
 	Set(context.Context, *Config, func(context.Context) error) error
@@ -12,12 +12,71 @@ import (
 
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/config/receiver"
+	"github.com/prometheus/common/promslog"
 )
 
-type (
-	// Receiver is the type for the receiver configuration.
-	Receiver = config.Receiver
-)
+// Receiver is the type for the receiver configuration, the one of the
+// alertmanager with its webhooks extended with their signing secret and
+// payload template
+type Receiver struct {
+	config.Receiver
+
+	WebhookConfigs []*WebhookConfig `yaml:"webhook_configs,omitempty" json:"webhook_configs,omitempty"`
+}
+
+// webhookFields are the fields of a webhook the alertmanager configuration
+// has no place for
+type webhookFields struct {
+	signingSecret   config.Secret
+	payloadTemplate string
+}
+
+// newReceiver returns the receiver of the alertmanager config with the fields
+// of its webhooks, given in the order of the webhooks
+func newReceiver(receiver config.Receiver, fields []webhookFields) Receiver {
+	var webhookConfigs []*WebhookConfig
+	for i, c := range receiver.WebhookConfigs {
+		webhookConfig := &WebhookConfig{WebhookConfig: *c}
+		if i < len(fields) {
+			webhookConfig.SigningSecret = fields[i].signingSecret
+			webhookConfig.PayloadTemplate = fields[i].payloadTemplate
+		}
+		webhookConfigs = append(webhookConfigs, webhookConfig)
+	}
+	receiver.WebhookConfigs = nil
+
+	return Receiver{Receiver: receiver, WebhookConfigs: webhookConfigs}
+}
+
+// AlertmanagerReceiver returns the receiver as configured in the
+// alertmanager, which has no place for the signing secrets and the payload
+// templates of the webhooks
+func (receiver Receiver) AlertmanagerReceiver() config.Receiver {
+	amReceiver := receiver.Receiver
+	amReceiver.WebhookConfigs = nil
+	for _, c := range receiver.WebhookConfigs {
+		webhookConfig := c.WebhookConfig
+		amReceiver.WebhookConfigs = append(amReceiver.WebhookConfigs, &webhookConfig)
+	}
+
+	return amReceiver
+}
+
+// webhookFields returns the signing secrets and the payload templates of the
+// webhooks in their order, nil when none of them has one
+func (receiver Receiver) webhookFields() []webhookFields {
+	fields := make([]webhookFields, len(receiver.WebhookConfigs))
+	var extended bool
+	for i, c := range receiver.WebhookConfigs {
+		fields[i] = webhookFields{signingSecret: c.SigningSecret, payloadTemplate: c.PayloadTemplate}
+		extended = extended || c.SigningSecret != "" || c.PayloadTemplate != ""
+	}
+	if !extended {
+		return nil
+	}
+
+	return fields
+}
 
 func NewReceiver(input string) (Receiver, error) {
 	receiver := Receiver{}
@@ -29,8 +88,40 @@ func NewReceiver(input string) (Receiver, error) {
 	return receiver, nil
 }
 
+// NewReceiverIntegrations builds the integrations of the receiver, the
+// webhooks with a signing secret sign their notifications and the ones with a
+// payload template render their body from it
 func NewReceiverIntegrations(nc Receiver, tmpl *template.Template, logger *slog.Logger) ([]notify.Integration, error) {
-	return receiver.BuildReceiverIntegrations(nc, tmpl, logger)
+	if logger == nil {
+		logger = promslog.NewNopLogger()
+	}
+
+	var extended []*signozWebhookNotifier
+	amReceiver := nc.Receiver
+	amReceiver.WebhookConfigs = make([]*config.WebhookConfig, 0, len(nc.WebhookConfigs))
+	for _, c := range nc.WebhookConfigs {
+		secret, payload, stripped, ok := webhookExtensions(c)
+		if ok {
+			n, err := newSignozWebhookNotifier(stripped, secret, payload, tmpl, logger.With("integration", "webhook"))
+			if err != nil {
+				return nil, err
+			}
+			extended = append(extended, n)
+			continue
+		}
+		amReceiver.WebhookConfigs = append(amReceiver.WebhookConfigs, stripped)
+	}
+
+	integrations, err := receiver.BuildReceiverIntegrations(amReceiver, tmpl, logger)
+	if err != nil {
+		return nil, err
+	}
+	// the signed and templated webhooks are numbered after the other webhooks
+	// of the receiver
+	for i, n := range extended {
+		integrations = append(integrations, notify.NewIntegration(n, n.conf, "webhook", len(amReceiver.WebhookConfigs)+i, nc.Name))
+	}
+	return integrations, nil
 }
 
 func TestReceiver(ctx context.Context, receiver Receiver, tmpl *template.Template, logger *slog.Logger) error {
@@ -0,0 +1,182 @@
+package alertmanagertypes
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/notify/webhook"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	commoncfg "github.com/prometheus/common/config"
+)
+
+const (
+	// WebhookSignatureHeader is the signature of the notification, sha256=
+	// followed by the hex of the HMAC-SHA256 of the timestamp, a dot and the
+	// body of the notification
+	WebhookSignatureHeader = "X-Signoz-Signature"
+	// WebhookTimestampHeader is the unix time the notification is signed at,
+	// for the receivers to reject the notifications replayed later
+	WebhookTimestampHeader = "X-Signoz-Timestamp"
+)
+
+// SignWebhook returns the signature of the body of a webhook notification
+// sent at the timestamp
+func SignWebhook(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookConfig is the config of a webhook of a receiver, the one of the
+// alertmanager with the secret its notifications are signed with and the
+// template of their body
+type WebhookConfig struct {
+	config.WebhookConfig
+
+	SigningSecret config.Secret `yaml:"signing_secret,omitempty" json:"signing_secret,omitempty"`
+	// PayloadTemplate is rendered with the data of the notification as its
+	// body instead of the message of the alertmanager
+	PayloadTemplate string `yaml:"payload_template,omitempty" json:"payload_template,omitempty"`
+}
+
+// webhookExtensions returns the signing secret and the payload template of
+// the webhook config and the config of the alertmanager, false when the
+// webhook has neither
+func webhookExtensions(conf *WebhookConfig) ([]byte, string, *config.WebhookConfig, bool) {
+	amConfig := conf.WebhookConfig
+	secret := []byte(conf.SigningSecret)
+	if len(secret) == 0 && conf.PayloadTemplate == "" {
+		return nil, "", &amConfig, false
+	}
+
+	// the configs not read from yaml have no defaults
+	if amConfig.HTTPConfig == nil {
+		httpConfig := commoncfg.DefaultHTTPClientConfig
+		amConfig.HTTPConfig = &httpConfig
+	}
+
+	return secret, conf.PayloadTemplate, &amConfig, true
+}
+
+// signozWebhookNotifier sends the notifications of a webhook as the webhook
+// notifier of the alertmanager does, signed with the secret of the webhook
+// when it has one and with the body rendered from its payload template when
+// it has one
+type signozWebhookNotifier struct {
+	conf    *config.WebhookConfig
+	tmpl    *template.Template
+	logger  *slog.Logger
+	client  *http.Client
+	retrier *notify.Retrier
+	secret  []byte
+	payload string
+}
+
+func newSignozWebhookNotifier(conf *config.WebhookConfig, secret []byte, payload string, tmpl *template.Template, logger *slog.Logger) (*signozWebhookNotifier, error) {
+	client, err := commoncfg.NewClientFromConfig(*conf.HTTPConfig, "webhook")
+	if err != nil {
+		return nil, err
+	}
+
+	return &signozWebhookNotifier{
+		conf:    conf,
+		tmpl:    tmpl,
+		logger:  logger,
+		client:  client,
+		retrier: &notify.Retrier{},
+		secret:  secret,
+		payload: payload,
+	}, nil
+}
+
+func (n *signozWebhookNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	var numTruncated uint64
+	if n.conf.MaxAlerts != 0 && uint64(len(alerts)) > n.conf.MaxAlerts {
+		numTruncated = uint64(len(alerts)) - n.conf.MaxAlerts
+		alerts = alerts[:n.conf.MaxAlerts]
+	}
+	data := notify.GetTemplateData(ctx, n.tmpl, alerts, n.logger)
+
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "error extracting group key", "err", err)
+	}
+
+	body, err := n.body(data, groupKey.String(), numTruncated)
+	if err != nil {
+		return false, err
+	}
+
+	if n.conf.URL == nil {
+		return false, fmt.Errorf("the webhooks need a url")
+	}
+
+	if n.conf.Timeout > 0 {
+		postCtx, cancel := context.WithTimeoutCause(ctx, n.conf.Timeout, fmt.Errorf("configured webhook timeout reached (%s)", n.conf.Timeout))
+		defer cancel()
+		ctx = postCtx
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.conf.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		return false, notify.RedactURL(err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", notify.UserAgentHeader)
+	if len(n.secret) > 0 {
+		req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(n.secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%w: %w", err, context.Cause(ctx))
+		}
+		return true, notify.RedactURL(err)
+	}
+	defer notify.Drain(resp)
+
+	shouldRetry, err := n.retrier.Check(resp.StatusCode, resp.Body)
+	if err != nil {
+		return shouldRetry, notify.NewErrorWithReason(notify.GetFailureReasonFromStatusCode(resp.StatusCode), err)
+	}
+	return shouldRetry, err
+}
+
+// body returns the body of the notification, the payload template rendered
+// with the data of the notification or else the message of the alertmanager
+func (n *signozWebhookNotifier) body(data *template.Data, groupKey string, numTruncated uint64) ([]byte, error) {
+	if n.payload == "" {
+		return json.Marshal(&webhook.Message{
+			Version:         "4",
+			Data:            data,
+			GroupKey:        groupKey,
+			TruncatedAlerts: numTruncated,
+		})
+	}
+
+	body, err := n.tmpl.ExecuteTextString(n.payload, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the payload template: %w", err)
+	}
+	if !json.Valid([]byte(body)) {
+		return nil, fmt.Errorf("the payload template rendered an invalid json body")
+	}
+	return []byte(body), nil
+}
@@ -20,4 +20,5 @@ type SavedView struct {
 	Tags       string    `bun:"tags,type:text"`
 	Data       string    `bun:"data,type:text,notnull"`
 	ExtraData  string    `bun:"extra_data,type:text"`
+	Visibility string    `bun:"visibility,type:text,notnull,default:'org'"`
 }